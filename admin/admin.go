@@ -0,0 +1,222 @@
+// Package admin serves the debugging endpoints of a service on a separate
+// port, kept off the public listener and guarded by a bearer token:
+//
+//   - /debug/pprof/ serves the runtime profiles of net/http/pprof, e.g.
+//     go tool pprof -http=: "http://localhost:6060/debug/pprof/heap", with
+//     the token in the Authorization header.
+//   - /debug/vars serves the expvar variables, memstats and cmdline
+//     included.
+//   - /buildinfo serves the module path, versions and VCS settings the
+//     binary was built with.
+//
+// A typical setup registers the server with the shutdown manager:
+//
+//	server, err := admin.NewServer(admin.Config{Token: os.Getenv("ADMIN_TOKEN")})
+//	shutdown.Register("admin server", server.Shutdown)
+//	go func() { _ = server.ListenAndServe() }()
+//
+// Never serve http.DefaultServeMux, e.g. with a nil handler, in a service
+// importing this package: net/http/pprof and expvar register /debug/pprof/
+// and /debug/vars on it when imported, where they are reachable without
+// the token. CheckHandler reports such a handler before it is served:
+//
+//	if err := admin.CheckHandler(public.Handler); err != nil {
+//		log.Fatal(err)
+//	}
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+)
+
+const (
+	defaultAddr              = "localhost:6060"
+	defaultReadHeaderTimeout = 5 * time.Second
+)
+
+var (
+	// ErrDefaultServeMux is returned by CheckHandler for a handler
+	// serving the debugging endpoints of http.DefaultServeMux.
+	ErrDefaultServeMux = errors.New("admin: http.DefaultServeMux serves /debug/pprof/ and /debug/vars without a token")
+
+	errTokenRequired = errors.New("admin: token is required")
+)
+
+// debugPaths are the paths net/http/pprof and expvar register on
+// http.DefaultServeMux.
+var debugPaths = []string{"/debug/pprof/", "/debug/vars"}
+
+type Config struct {
+	// Addr is the address of the admin listener, localhost:6060 by default
+	// so that it is only reachable from the host or the pod. Set it to
+	// ":6060" to reach it through a port-forward or a private network.
+	Addr string
+	// Token is the bearer token required on every request. Required.
+	Token string
+
+	// ReadHeaderTimeout bounds reading the request headers, 5 seconds by
+	// default. No write timeout is set since CPU profiles and traces stream
+	// for the requested duration.
+	ReadHeaderTimeout time.Duration
+
+	// Logger receives the rejected requests. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Server is the admin HTTP server. More endpoints are added with Handle
+// before ListenAndServe.
+type Server struct {
+	mux    *http.ServeMux
+	server *http.Server
+}
+
+// NewServer creates a Server serving the pprof, expvar and build info
+// endpoints.
+func NewServer(config Config) (*Server, error) {
+	if config.Token == "" {
+		return nil, errTokenRequired
+	}
+	if config.Addr == "" {
+		config.Addr = defaultAddr
+	}
+	if config.ReadHeaderTimeout <= 0 {
+		config.ReadHeaderTimeout = defaultReadHeaderTimeout
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/buildinfo", serveBuildInfo)
+
+	return &Server{
+		mux: mux,
+		server: &http.Server{
+			Addr:              config.Addr,
+			Handler:           requireToken(config.Token, logger.WithLevel(config.Logger, logger.InfoLevel), mux),
+			ReadHeaderTimeout: config.ReadHeaderTimeout,
+		},
+	}, nil
+}
+
+// Handle adds an endpoint, guarded by the token like the others.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// Handler returns the handler of the server, to be served on a listener
+// of the caller.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+// ListenAndServe listens on the address of the config and serves until
+// Shutdown, after which it returns nil.
+func (s *Server) ListenAndServe() error {
+	if err := s.server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Serve serves on lis until Shutdown, after which it returns nil.
+func (s *Server) Serve(lis net.Listener) error {
+	if err := s.server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops the server gracefully. The requests still running when
+// ctx is done, such as long CPU profiles, are cut.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		_ = s.server.Close()
+	}
+	return err
+}
+
+// CheckHandler returns ErrDefaultServeMux when handler, the handler of a
+// public server, is nil or http.DefaultServeMux and the latter routes the
+// debugging endpoints, which it does once this package is imported.
+func CheckHandler(handler http.Handler) error {
+	if handler != nil && handler != http.DefaultServeMux {
+		return nil
+	}
+	for _, path := range debugPaths {
+		if _, pattern := http.DefaultServeMux.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: path}}); pattern != "" {
+			return ErrDefaultServeMux
+		}
+	}
+	return nil
+}
+
+// requireToken rejects the requests without the bearer token. Tokens are
+// compared through their hashes, in constant time.
+func requireToken(token string, log logger.Logger, next http.Handler) http.Handler {
+	want := sha256.Sum256([]byte(token))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scheme, got, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		gotSum := sha256.Sum256([]byte(got))
+		if !ok || !strings.EqualFold(scheme, "Bearer") || subtle.ConstantTimeCompare(gotSum[:], want[:]) != 1 {
+			log.With(logger.Fields{
+				"path":     r.URL.Path,
+				"remoteIP": r.RemoteAddr,
+			}).Warn("Rejected admin request")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BuildInfo is the body of /buildinfo.
+type BuildInfo struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings,omitempty"`
+	Deps      map[string]string `json:"deps,omitempty"`
+}
+
+func serveBuildInfo(w http.ResponseWriter, _ *http.Request) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		http.Error(w, "build info unavailable", http.StatusNotImplemented)
+		return
+	}
+	body := BuildInfo{
+		GoVersion: info.GoVersion,
+		Path:      info.Main.Path,
+		Version:   info.Main.Version,
+		Settings:  map[string]string{},
+		Deps:      map[string]string{},
+	}
+	for _, setting := range info.Settings {
+		body.Settings[setting.Key] = setting.Value
+	}
+	for _, dep := range info.Deps {
+		body.Deps[dep.Path] = dep.Version
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(body)
+}
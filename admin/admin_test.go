@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"context"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServer(t *testing.T) {
+	_, err := NewServer(Config{})
+	assert.ErrorIs(t, err, errTokenRequired)
+}
+
+func TestCheckHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.Handler
+		wantErr error
+	}{
+		{name: "nil handler", handler: nil, wantErr: ErrDefaultServeMux},
+		{name: "default mux", handler: http.DefaultServeMux, wantErr: ErrDefaultServeMux},
+		{name: "own mux", handler: http.NewServeMux()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, CheckHandler(tt.handler), tt.wantErr)
+		})
+	}
+}
+
+func TestServerEndpoints(t *testing.T) {
+	expvar.NewString("admin_test").Set("value")
+	server, err := NewServer(Config{Token: "secret-token"})
+	require.NoError(t, err)
+	server.Handle("/custom", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantBody      string
+	}{
+		{name: "missing token", path: "/debug/pprof/", wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", path: "/debug/pprof/", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", path: "/debug/pprof/", authorization: "Basic secret-token", wantStatus: http.StatusUnauthorized},
+		{name: "pprof index", path: "/debug/pprof/", authorization: "Bearer secret-token", wantStatus: http.StatusOK, wantBody: "goroutine"},
+		{name: "heap profile", path: "/debug/pprof/heap?debug=1", authorization: "Bearer secret-token", wantStatus: http.StatusOK, wantBody: "heap profile"},
+		{name: "expvar", path: "/debug/vars", authorization: "bearer secret-token", wantStatus: http.StatusOK, wantBody: `"admin_test": "value"`},
+		{name: "custom handler is guarded", path: "/custom", wantStatus: http.StatusUnauthorized},
+		{name: "custom handler", path: "/custom", authorization: "Bearer secret-token", wantStatus: http.StatusTeapot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			server.Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+			if tt.wantStatus == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="admin"`, rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestServerBuildInfo(t *testing.T) {
+	server, err := NewServer(Config{Token: "secret-token"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/buildinfo", nil)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var info BuildInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &info))
+	assert.NotEmpty(t, info.GoVersion)
+	assert.Contains(t, info.Deps, "github.com/stretchr/testify")
+}
+
+func TestServerServeAndShutdown(t *testing.T) {
+	server, err := NewServer(Config{Token: "secret-token"})
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(lis) }()
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+lis.Addr().String()+"/debug/pprof/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.NoError(t, <-served)
+}
@@ -0,0 +1,246 @@
+// Package audit records who did what to which resource, with the changes
+// made and the request it came from, to a Sink such as an OpenSearch index,
+// a Kafka topic or a file.
+//
+// Every entry carries the hash of the previous one in its own hash, so that
+// altering, removing or reordering stored entries breaks the chain, which
+// Verify reports:
+//
+//	recorder, err := audit.NewRecorder(audit.Config{Sink: audit.NewOpenSearchSink(client, "audit")})
+//	_, err = recorder.Record(ctx, audit.Event{
+//		Actor:    audit.Actor{ID: userID, Type: "user"},
+//		Action:   "order.update",
+//		Resource: audit.Resource{Type: "order", ID: order.ID},
+//		Before:   before,
+//		After:    order,
+//	})
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTampered is returned by Verify for a chain of entries that was
+	// altered.
+	ErrTampered = errors.New("audit: entries were tampered with")
+
+	errSinkRequired   = errors.New("audit: sink is required")
+	errActionRequired = errors.New("audit: action is required")
+)
+
+// Actor is who performed an action: a user, a service or a system job.
+type Actor struct {
+	ID   string `json:"id"`
+	Type string `json:"type,omitempty"`
+	IP   string `json:"ip,omitempty"`
+}
+
+// Resource is what an action was performed on.
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// Change is a field whose value differs between the before and after states
+// of a resource. Nested fields are named by their dotted JSON path, such as
+// address.city.
+type Change struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Event describes an action to record.
+type Event struct {
+	Actor    Actor
+	Action   string
+	Resource Resource
+	// Before and After are the states of the resource around the action,
+	// nil for creations and deletions. Their sensitive fields are masked
+	// with logger.Mask.
+	Before any
+	After  any
+	// Metadata holds any other context, such as the user agent or the
+	// reason given for the action.
+	Metadata map[string]string
+}
+
+// Entry is a recorded event. The chain of entries is verified from their
+// PrevHash and Hash.
+type Entry struct {
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Actor     Actor             `json:"actor"`
+	Action    string            `json:"action"`
+	Resource  Resource          `json:"resource"`
+	Before    json.RawMessage   `json:"before,omitempty"`
+	After     json.RawMessage   `json:"after,omitempty"`
+	Changes   []Change          `json:"changes,omitempty"`
+	RequestID string            `json:"requestId,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	PrevHash  string            `json:"prevHash"`
+	Hash      string            `json:"hash"`
+}
+
+// GetID implements platigo.IndexModel.
+func (e *Entry) GetID() string {
+	return e.ID
+}
+
+// computeHash returns the SHA-256 of the entry without its hash, which
+// covers PrevHash and thus chains the entry to the previous one.
+func (e *Entry) computeHash() (string, error) {
+	unhashed := *e
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Sink stores the entries.
+type Sink interface {
+	Write(ctx context.Context, entry *Entry) error
+}
+
+type Config struct {
+	// Sink stores the entries. Required.
+	Sink Sink
+	// LastHash is the hash of the last entry already stored, continuing
+	// its chain after a restart. A new chain starts when empty.
+	LastHash string
+	// Masker masks the sensitive fields of the before and after states.
+	// Defaults to the keys of logger.DefaultMaskKeys.
+	Masker *logger.Masker
+	// Logger receives the errors of the sink. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Recorder records events to a sink. Its methods are safe for concurrent
+// use; entries are chained in the order they are recorded.
+type Recorder struct {
+	config Config
+	log    logger.Logger
+	now    func() time.Time
+	newID  func() string
+
+	mu       sync.Mutex
+	lastHash string
+}
+
+// NewRecorder creates a Recorder.
+func NewRecorder(config Config) (*Recorder, error) {
+	if config.Sink == nil {
+		return nil, errSinkRequired
+	}
+	if config.Masker == nil {
+		config.Masker = logger.NewMasker()
+	}
+	return &Recorder{
+		config:   config,
+		log:      logger.WithLevel(config.Logger, logger.InfoLevel),
+		now:      time.Now,
+		newID:    uuid.NewString,
+		lastHash: config.LastHash,
+	}, nil
+}
+
+// Record writes an entry for event to the sink and returns it. The request
+// ID of ctx is recorded along. The chain is left as is when the sink
+// fails, so the event may be recorded again.
+func (r *Recorder) Record(ctx context.Context, event Event) (*Entry, error) {
+	if event.Action == "" {
+		return nil, errActionRequired
+	}
+
+	entry := &Entry{
+		ID:        r.newID(),
+		Time:      r.now().UTC(),
+		Actor:     event.Actor,
+		Action:    event.Action,
+		Resource:  event.Resource,
+		RequestID: platigo.RequestIDFromContext(ctx),
+		Metadata:  event.Metadata,
+	}
+	var err error
+	if entry.Before, err = r.marshalState(event.Before); err != nil {
+		return nil, err
+	}
+	if entry.After, err = r.marshalState(event.After); err != nil {
+		return nil, err
+	}
+	if entry.Changes, err = diff(entry.Before, entry.After); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.PrevHash = r.lastHash
+	if entry.Hash, err = entry.computeHash(); err != nil {
+		return nil, err
+	}
+	if err := r.config.Sink.Write(ctx, entry); err != nil {
+		logger.WithContext(ctx, r.log).With(logger.Fields{
+			"action":  entry.Action,
+			"entryID": entry.ID,
+			"error":   err.Error(),
+		}).Error("Failed to write audit entry")
+		return nil, fmt.Errorf("audit: write entry: %w", err)
+	}
+	r.lastHash = entry.Hash
+	return entry, nil
+}
+
+// LastHash returns the hash of the last recorded entry.
+func (r *Recorder) LastHash() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastHash
+}
+
+func (r *Recorder) marshalState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(r.config.Masker.Mask(state))
+	if err != nil {
+		return nil, fmt.Errorf("audit: marshal state: %w", err)
+	}
+	return data, nil
+}
+
+// Verify checks that entries, in the order they were recorded, form an
+// unaltered chain starting after prevHash, empty for the start of the
+// chain. It returns an error wrapping ErrTampered and naming the first
+// entry that does not match.
+func Verify(prevHash string, entries []*Entry) error {
+	for i, entry := range entries {
+		if entry.PrevHash != prevHash {
+			return fmt.Errorf("%w: entry %d (%s) does not follow the previous one", ErrTampered, i, entry.ID)
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return err
+		}
+		if hash != entry.Hash {
+			return fmt.Errorf("%w: entry %d (%s) does not match its hash", ErrTampered, i, entry.ID)
+		}
+		prevHash = entry.Hash
+	}
+	return nil
+}
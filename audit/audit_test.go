@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo"
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memorySink struct {
+	entries []*Entry
+	err     error
+}
+
+func (s *memorySink) Write(_ context.Context, entry *Entry) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+type order struct {
+	ID       string  `json:"id"`
+	Status   string  `json:"status"`
+	Total    float64 `json:"total"`
+	Address  address `json:"address"`
+	Password string  `json:"password,omitempty"`
+}
+
+type address struct {
+	City string `json:"city"`
+}
+
+func newTestRecorder(t *testing.T, sink Sink) *Recorder {
+	recorder, err := NewRecorder(Config{Sink: sink})
+	require.NoError(t, err)
+	ids := 0
+	recorder.newID = func() string {
+		ids++
+		return fmt.Sprintf("entry-%d", ids)
+	}
+	recorder.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return recorder
+}
+
+func TestRecorderRecord(t *testing.T) {
+	sink := &memorySink{}
+	recorder := newTestRecorder(t, sink)
+	ctx := platigo.ContextWithRequestID(context.Background(), "req-1")
+
+	created, err := recorder.Record(ctx, Event{
+		Actor:    Actor{ID: "user-1", Type: "user"},
+		Action:   "order.create",
+		Resource: Resource{Type: "order", ID: "order-1"},
+		After:    order{ID: "order-1", Status: "pending", Total: 10, Address: address{City: "Jakarta"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.Empty(t, created.PrevHash)
+	assert.Len(t, created.Changes, 4)
+
+	updated, err := recorder.Record(ctx, Event{
+		Actor:    Actor{ID: "user-1", Type: "user"},
+		Action:   "order.update",
+		Resource: Resource{Type: "order", ID: "order-1"},
+		Before:   order{ID: "order-1", Status: "pending", Total: 10, Address: address{City: "Jakarta"}},
+		After:    order{ID: "order-1", Status: "paid", Total: 10, Address: address{City: "Bandung"}, Password: "hunter2"},
+		Metadata: map[string]string{"reason": "payment received"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.Hash, updated.PrevHash)
+	assert.Equal(t, []Change{
+		{Field: "address.city", Before: json.RawMessage(`"Jakarta"`), After: json.RawMessage(`"Bandung"`)},
+		{Field: "password", After: json.RawMessage(`"[REDACTED]"`)},
+		{Field: "status", Before: json.RawMessage(`"pending"`), After: json.RawMessage(`"paid"`)},
+	}, updated.Changes)
+	assert.NotContains(t, string(updated.After), "hunter2")
+
+	assert.Equal(t, []*Entry{created, updated}, sink.entries)
+	assert.Equal(t, updated.Hash, recorder.LastHash())
+	assert.NoError(t, Verify("", sink.entries))
+}
+
+func TestRecorderErrors(t *testing.T) {
+	_, err := NewRecorder(Config{})
+	assert.ErrorIs(t, err, errSinkRequired)
+
+	errDown := errors.New("sink down")
+	sink := &memorySink{err: errDown}
+	recorder := newTestRecorder(t, sink)
+
+	_, err = recorder.Record(context.Background(), Event{})
+	assert.ErrorIs(t, err, errActionRequired)
+
+	_, err = recorder.Record(context.Background(), Event{Action: "order.delete"})
+	assert.ErrorIs(t, err, errDown)
+	assert.Empty(t, recorder.LastHash(), "the chain does not advance")
+}
+
+func TestVerify(t *testing.T) {
+	record := func(t *testing.T) []*Entry {
+		sink := &memorySink{}
+		recorder := newTestRecorder(t, sink)
+		for _, status := range []string{"pending", "paid", "shipped"} {
+			_, err := recorder.Record(context.Background(), Event{
+				Action:   "order.update",
+				Resource: Resource{Type: "order", ID: "order-1"},
+				After:    order{ID: "order-1", Status: status},
+			})
+			require.NoError(t, err)
+		}
+		return sink.entries
+	}
+
+	tests := []struct {
+		name    string
+		tamper  func(entries []*Entry) []*Entry
+		wantErr string
+	}{
+		{
+			name:   "intact",
+			tamper: func(entries []*Entry) []*Entry { return entries },
+		},
+		{
+			name: "altered entry",
+			tamper: func(entries []*Entry) []*Entry {
+				entries[1].Actor.ID = "someone-else"
+				return entries
+			},
+			wantErr: "audit: entries were tampered with: entry 1 (entry-2) does not match its hash",
+		},
+		{
+			name: "removed entry",
+			tamper: func(entries []*Entry) []*Entry {
+				return append(entries[:1], entries[2:]...)
+			},
+			wantErr: "audit: entries were tampered with: entry 1 (entry-3) does not follow the previous one",
+		},
+		{
+			name: "reordered entries",
+			tamper: func(entries []*Entry) []*Entry {
+				entries[0], entries[1] = entries[1], entries[0]
+				return entries
+			},
+			wantErr: "audit: entries were tampered with: entry 0 (entry-2) does not follow the previous one",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify("", tt.tamper(record(t)))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrTampered)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyAfterRoundTrip(t *testing.T) {
+	sink := &memorySink{}
+	recorder := newTestRecorder(t, sink)
+	_, err := recorder.Record(context.Background(), Event{
+		Action:   "order.update",
+		Resource: Resource{Type: "order", ID: "order-1"},
+		Before:   map[string]any{"status": "pending", "items": []int{1, 2}},
+		After:    map[string]any{"status": "paid", "items": []int{1}},
+	})
+	require.NoError(t, err)
+
+	data, err := json.Marshal(sink.entries)
+	require.NoError(t, err)
+	var stored []*Entry
+	require.NoError(t, json.Unmarshal(data, &stored))
+	assert.NoError(t, Verify("", stored))
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/goccy/go-json"
+)
+
+// diff returns the changes between two JSON states, sorted by field. Objects
+// are compared field by field, and any other value, arrays included, as a
+// whole. Either state may be empty.
+func diff(before, after json.RawMessage) ([]Change, error) {
+	if len(before) == 0 && len(after) == 0 {
+		return nil, nil
+	}
+	beforeFields, err := flatten(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := flatten(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for field, value := range beforeFields {
+		if other, ok := afterFields[field]; !ok || !bytes.Equal(value, other) {
+			changes = append(changes, Change{Field: field, Before: value, After: other})
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes = append(changes, Change{Field: field, After: value})
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return bytes.Compare([]byte(a.Field), []byte(b.Field))
+	})
+	return changes, nil
+}
+
+// flatten returns the leaf values of a JSON document by dotted path, the
+// root being named "" when it is not an object.
+func flatten(data json.RawMessage) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if len(data) == 0 {
+		return fields, nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return fields, flattenValue("", v, fields)
+}
+
+func flattenValue(path string, v any, fields map[string]json.RawMessage) error {
+	if object, ok := v.(map[string]any); ok && len(object) > 0 {
+		for key, value := range object {
+			name := key
+			if path != "" {
+				name = path + "." + key
+			}
+			if err := flattenValue(name, value, fields); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	fields[path] = data
+	return nil
+}
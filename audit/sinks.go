@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+)
+
+type openSearchSink struct {
+	client platigo.OpenSearchClient
+	index  string
+}
+
+// NewOpenSearchSink returns a Sink indexing the entries in index, by ID.
+func NewOpenSearchSink(client platigo.OpenSearchClient, index string) Sink {
+	return &openSearchSink{client: client, index: index}
+}
+
+func (s *openSearchSink) Write(ctx context.Context, entry *Entry) error {
+	res, err := s.client.Index(ctx, s.index, entry)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.IsError() {
+		return fmt.Errorf("index %s: %s", s.index, res.Status())
+	}
+	return nil
+}
+
+type publisherSink struct {
+	publisher messaging.Publisher
+	topic     string
+}
+
+// NewPublisherSink returns a Sink publishing the entries as JSON to topic,
+// keyed by resource so that the entries of a resource stay in order on
+// partitioned brokers. Any messaging.Publisher works, such as the Kafka
+// producer.
+func NewPublisherSink(publisher messaging.Publisher, topic string) Sink {
+	return &publisherSink{publisher: publisher, topic: topic}
+}
+
+func (s *publisherSink) Write(ctx context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	msg := &messaging.Message{
+		Topic: s.topic,
+		Key:   []byte(entry.Resource.Type + ":" + entry.Resource.ID),
+		Value: data,
+	}
+	msg.SetHeader(messaging.HeaderMessageID, entry.ID)
+	msg.SetHeader(messaging.HeaderContentType, "application/json")
+	return s.publisher.Publish(ctx, msg)
+}
+
+// FileSink appends the entries to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink opens path for appending, creating it readable by its owner
+// only.
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{file: file}, nil
+}
+
+// Write appends entry and syncs the file, so that recorded entries survive
+// a crash.
+func (s *FileSink) Write(_ context.Context, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
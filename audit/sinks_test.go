@@ -0,0 +1,98 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOpenSearchClient struct {
+	platigo.OpenSearchClient
+	status  int
+	index   string
+	indexed platigo.IndexModel
+}
+
+func (c *fakeOpenSearchClient) Index(_ context.Context, indexName string, model platigo.IndexModel, _ ...platigo.RequestOption) (*opensearchapi.Response, error) {
+	c.index, c.indexed = indexName, model
+	return &opensearchapi.Response{StatusCode: c.status, Body: io.NopCloser(bytes.NewReader(nil))}, nil
+}
+
+type fakePublisher struct {
+	msgs []*messaging.Message
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg *messaging.Message) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func TestOpenSearchSink(t *testing.T) {
+	entry := &Entry{ID: "entry-1", Action: "order.create"}
+
+	client := &fakeOpenSearchClient{status: http.StatusCreated}
+	require.NoError(t, NewOpenSearchSink(client, "audit").Write(context.Background(), entry))
+	assert.Equal(t, "audit", client.index)
+	assert.Equal(t, entry, client.indexed)
+
+	client = &fakeOpenSearchClient{status: http.StatusForbidden}
+	assert.EqualError(t, NewOpenSearchSink(client, "audit").Write(context.Background(), entry), "index audit: 403 Forbidden")
+}
+
+func TestPublisherSink(t *testing.T) {
+	publisher := &fakePublisher{}
+	entry := &Entry{ID: "entry-1", Action: "order.create", Resource: Resource{Type: "order", ID: "order-1"}}
+	require.NoError(t, NewPublisherSink(publisher, "audit.entries").Write(context.Background(), entry))
+
+	require.Len(t, publisher.msgs, 1)
+	msg := publisher.msgs[0]
+	assert.Equal(t, "audit.entries", msg.Topic)
+	assert.Equal(t, "order:order-1", string(msg.Key))
+	assert.Equal(t, "entry-1", msg.Header(messaging.HeaderMessageID))
+	var published Entry
+	require.NoError(t, json.Unmarshal(msg.Value, &published))
+	assert.Equal(t, *entry, published)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	recorder := newTestRecorder(t, sink)
+	for _, action := range []string{"order.create", "order.update"} {
+		_, err := recorder.Record(context.Background(), Event{Action: action, After: map[string]string{"status": "paid"}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, sink.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var entries []*Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, &entry)
+	}
+	require.Len(t, entries, 2)
+	assert.NoError(t, Verify("", entries))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	assert.ErrorIs(t, sink.Write(context.Background(), entries[0]), os.ErrClosed)
+}
@@ -0,0 +1,187 @@
+// Package bind decodes requests into structs and validates them with the
+// validate tags of go-playground/validator. Path parameters are read from
+// the path tags, query parameters from the query tags and the JSON body
+// from the json tags:
+//
+//	type CreateOrder struct {
+//		Tenant string `path:"tenant" validate:"required"`
+//		DryRun bool   `query:"dry_run"`
+//		Email  string `json:"email" validate:"required,email"`
+//		Status string `json:"status" validate:"omitempty,oneof=draft placed"`
+//		Items  []Item `json:"items" validate:"required,min=1,dive"`
+//	}
+//
+//	var req CreateOrder
+//	if err := bind.Request(r, &req); err != nil {
+//		return respond.Error(w, err)
+//	}
+//
+// Invalid values fail with a respond.ValidationError listing the fields,
+// answered with a 422, and malformed bodies with a 400.
+package bind
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/bagastri07/platigo/respond"
+	"github.com/goccy/go-json"
+)
+
+var (
+	errMalformedBody    = respond.NewError(http.StatusBadRequest, respond.CodeBadRequest, "malformed JSON body")
+	errUnsupportedMedia = respond.NewError(http.StatusUnsupportedMediaType, "unsupported_media_type", "request body must be JSON")
+)
+
+type options struct {
+	pathParam             func(name string) string
+	disallowUnknownFields bool
+}
+
+// Option configures the binding of a request.
+type Option func(*options)
+
+// WithPathParams reads the path parameters with param instead of
+// http.Request.PathValue, such as c.Param with Echo and Gin.
+func WithPathParams(param func(name string) string) Option {
+	return func(o *options) {
+		o.pathParam = param
+	}
+}
+
+// DisallowUnknownFields rejects the bodies with fields missing from the
+// struct.
+func DisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// Request decodes the path parameters, the query parameters and the JSON
+// body of r into v, a pointer to a struct, then validates it.
+func Request(r *http.Request, v any, opts ...Option) error {
+	o := newOptions(r, opts)
+
+	fields := decodeParams(v, "path", func(name string) []string {
+		if value := o.pathParam(name); value != "" {
+			return []string{value}
+		}
+		return nil
+	})
+	query := r.URL.Query()
+	fields = append(fields, decodeParams(v, "query", func(name string) []string {
+		return query[name]
+	})...)
+	if len(fields) > 0 {
+		return &respond.ValidationError{Fields: fields}
+	}
+
+	if err := decodeBody(r, v, o); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// JSON decodes the JSON body of r into v, then validates it.
+func JSON(r *http.Request, v any, opts ...Option) error {
+	if err := decodeBody(r, v, newOptions(r, opts)); err != nil {
+		return err
+	}
+	return Validate(v)
+}
+
+// Query decodes the query parameters of r into v, then validates it.
+func Query(r *http.Request, v any) error {
+	query := r.URL.Query()
+	fields := decodeParams(v, "query", func(name string) []string {
+		return query[name]
+	})
+	if len(fields) > 0 {
+		return &respond.ValidationError{Fields: fields}
+	}
+	return Validate(v)
+}
+
+func newOptions(r *http.Request, opts []Option) *options {
+	o := &options{pathParam: r.PathValue}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// decodeBody decodes the JSON body of r, if any, into v.
+func decodeBody(r *http.Request, v any, o *options) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+			return errUnsupportedMedia
+		}
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	if o.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(v)
+	if err == nil || errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return err
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &respond.ValidationError{Fields: []respond.FieldError{{
+			Field:   jsonPath(reflect.TypeOf(v), typeErr.Field),
+			Code:    "type",
+			Message: typeMessage(typeErr.Type),
+		}}}
+	}
+	return &respond.APIError{
+		Status:  errMalformedBody.Status,
+		Code:    errMalformedBody.Code,
+		Message: errMalformedBody.Message,
+		Err:     err,
+	}
+}
+
+// jsonPath converts a path of Go field names, such as Items[0].Quantity,
+// to the names of the fields in the request, such as items[0].quantity.
+// Embedded structs are left out, like in the JSON documents.
+func jsonPath(t reflect.Type, path string) string {
+	segments := strings.Split(path, ".")
+	out := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+			t = t.Elem()
+		}
+		name, index, _ := strings.Cut(segment, "[")
+		var field reflect.StructField
+		ok := t != nil && t.Kind() == reflect.Struct
+		if ok {
+			field, ok = t.FieldByName(name)
+		}
+		if !ok {
+			return strings.Join(append(out, segments[i:]...), ".")
+		}
+		t = field.Type
+		if field.Anonymous && fieldName(field) == field.Name {
+			continue
+		}
+		if index != "" {
+			index = "[" + index
+		}
+		out = append(out, fieldName(field)+index)
+	}
+	return strings.Join(out, ".")
+}
@@ -0,0 +1,177 @@
+package bind
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/respond"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	SKU      string `json:"sku" validate:"required,sku"`
+	Quantity int    `json:"quantity" validate:"min=1,max=100"`
+}
+
+type pagination struct {
+	Limit int `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type createOrder struct {
+	pagination
+	Tenant  string        `path:"tenant" validate:"required"`
+	DryRun  bool          `query:"dry_run"`
+	Tags    []string      `query:"tag"`
+	Wait    time.Duration `query:"wait"`
+	Email   string        `json:"email" validate:"required,email"`
+	Status  string        `json:"status" validate:"omitempty,oneof=draft placed"`
+	Items   []item        `json:"items" validate:"required,min=1,dive"`
+	Comment *string       `json:"comment" validate:"omitempty,max=5"`
+}
+
+func init() {
+	if err := RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU-")
+	}, "must be a valid SKU"); err != nil {
+		panic(err)
+	}
+}
+
+func TestRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		headers    map[string]string
+		wantStatus int
+		wantFields []respond.FieldError
+		check      func(t *testing.T, req createOrder)
+	}{
+		{
+			name:   "valid",
+			target: "/tenants/acme/orders?dry_run=true&tag=a&tag=b&wait=2s&limit=10",
+			body:   `{"email":"jane@example.com","status":"draft","items":[{"sku":"SKU-1","quantity":2}]}`,
+			check: func(t *testing.T, req createOrder) {
+				assert.Equal(t, "acme", req.Tenant)
+				assert.True(t, req.DryRun)
+				assert.Equal(t, []string{"a", "b"}, req.Tags)
+				assert.Equal(t, 2*time.Second, req.Wait)
+				assert.Equal(t, 10, req.Limit)
+				assert.Equal(t, "jane@example.com", req.Email)
+				assert.Equal(t, []item{{SKU: "SKU-1", Quantity: 2}}, req.Items)
+			},
+		},
+		{
+			name:       "invalid fields",
+			target:     "/tenants/acme/orders?limit=500",
+			body:       `{"email":"jane","status":"shipped","items":[{"sku":"1","quantity":0}],"comment":"too long"}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []respond.FieldError{
+				{Field: "limit", Code: "max", Message: "must be at most 100"},
+				{Field: "email", Code: "email", Message: "must be a valid email address"},
+				{Field: "status", Code: "oneof", Message: "must be one of draft, placed"},
+				{Field: "items[0].sku", Code: "sku", Message: "must be a valid SKU"},
+				{Field: "items[0].quantity", Code: "min", Message: "must be at least 1"},
+				{Field: "comment", Code: "max", Message: "must be at most 5 characters"},
+			},
+		},
+		{
+			name:       "missing fields",
+			target:     "/tenants/acme/orders",
+			body:       `{}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []respond.FieldError{
+				{Field: "email", Code: "required", Message: "is required"},
+				{Field: "items", Code: "required", Message: "is required"},
+			},
+		},
+		{
+			name:       "invalid query parameters",
+			target:     "/tenants/acme/orders?dry_run=maybe&wait=soon",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []respond.FieldError{
+				{Field: "dry_run", Code: "type", Message: "must be a boolean"},
+				{Field: "wait", Code: "type", Message: "must be a duration"},
+			},
+		},
+		{
+			name:       "wrong JSON type",
+			target:     "/tenants/acme/orders",
+			body:       `{"email":42}`,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantFields: []respond.FieldError{
+				{Field: "email", Code: "type", Message: "must be a string"},
+			},
+		},
+		{
+			name:       "malformed JSON",
+			target:     "/tenants/acme/orders",
+			body:       `{"email":`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported media type",
+			target:     "/tenants/acme/orders",
+			body:       `email=jane@example.com`,
+			headers:    map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			wantStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got createOrder
+			var err error
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /tenants/{tenant}/orders", func(_ http.ResponseWriter, r *http.Request) {
+				err = Request(r, &got)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.body == "" {
+				req.Body = http.NoBody
+			}
+			req.Header.Set("Content-Type", "application/json")
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			mux.ServeHTTP(httptest.NewRecorder(), req)
+
+			if tt.wantStatus == 0 {
+				require.NoError(t, err)
+				tt.check(t, got)
+				return
+			}
+			require.Error(t, err)
+			apiErr := respond.FromError(err)
+			assert.Equal(t, tt.wantStatus, apiErr.Status)
+			assert.Equal(t, tt.wantFields, apiErr.Fields)
+		})
+	}
+}
+
+func TestRequestWithPathParams(t *testing.T) {
+	var got struct {
+		ID int64 `path:"id" validate:"gt=0"`
+	}
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	params := map[string]string{"id": "7"}
+
+	require.NoError(t, Request(req, &got, WithPathParams(func(name string) string { return params[name] })))
+	assert.Equal(t, int64(7), got.ID)
+}
+
+func TestJSONDisallowUnknownFields(t *testing.T) {
+	var got struct {
+		Name string `json:"name"`
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"a","extra":true}`))
+
+	err := JSON(req, &got, DisallowUnknownFields())
+	assert.Equal(t, http.StatusBadRequest, respond.FromError(err).Status)
+}
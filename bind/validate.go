@@ -0,0 +1,110 @@
+package bind
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/bagastri07/platigo/respond"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate = newValidator()
+	// messages holds the messages of the custom validations.
+	messages = map[string]string{}
+)
+
+func newValidator() *validator.Validate {
+	return validator.New(validator.WithRequiredStructEnabled())
+}
+
+// fieldName names a field after its json, query or path tag, in that
+// order, or its Go name.
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "query", "path"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// RegisterValidation adds a custom validation for the tag, failing with
+// message. Validations are registered before any request is bound,
+// typically from an init function:
+//
+//	bind.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+//		return skuPattern.MatchString(fl.Field().String())
+//	}, "must be a valid SKU")
+func RegisterValidation(tag string, fn validator.Func, message string) error {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		return err
+	}
+	messages[tag] = message
+	return nil
+}
+
+// Validate validates v with its validate tags. Invalid values fail with a
+// respond.ValidationError listing the fields.
+func Validate(v any) error {
+	err := validate.Struct(v)
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	t := reflect.TypeOf(v)
+	fields := make([]respond.FieldError, len(validationErrs))
+	for i, fieldErr := range validationErrs {
+		// The namespace starts with the name of the struct type.
+		_, path, _ := strings.Cut(fieldErr.StructNamespace(), ".")
+		fields[i] = respond.FieldError{
+			Field:   jsonPath(t, path),
+			Code:    fieldErr.Tag(),
+			Message: message(fieldErr),
+		}
+	}
+	return &respond.ValidationError{Fields: fields}
+}
+
+// message describes the rule broken by err.
+func message(err validator.FieldError) string {
+	if msg, ok := messages[err.Tag()]; ok {
+		return msg
+	}
+
+	param := err.Param()
+	unit := ""
+	switch err.Kind() {
+	case reflect.String:
+		unit = " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unit = " items"
+	}
+
+	switch err.Tag() {
+	case "required", "required_if", "required_unless", "required_with", "required_without":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + param + unit
+	case "max", "lte":
+		return "must be at most " + param + unit
+	case "gt":
+		return "must be greater than " + param + unit
+	case "lt":
+		return "must be less than " + param + unit
+	case "len":
+		return "must be exactly " + param + unit
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(param), ", ")
+	case "email":
+		return "must be a valid email address"
+	case "url", "http_url":
+		return "must be a valid URL"
+	case "uuid", "uuid4":
+		return "must be a valid UUID"
+	}
+	return "is invalid"
+}
@@ -0,0 +1,96 @@
+// Package cache provides a typed cache abstraction with Redis and in-memory
+// implementations.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the key is missing or expired.
+var ErrNotFound = errors.New("cache: not found")
+
+// Loader produces the value of a key missing from the cache.
+type Loader[T any] func(ctx context.Context) (T, error)
+
+// Cache stores values of type T by key.
+type Cache[T any] interface {
+	// Get returns the value of key, or ErrNotFound.
+	Get(ctx context.Context, key string) (T, error)
+
+	// Set stores value under key. A zero ttl keeps the value until deleted.
+	Set(ctx context.Context, key string, value T, ttl time.Duration) error
+
+	// Delete removes keys. Missing keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+
+	// GetOrSet returns the value of key, calling loader and storing its
+	// result when the key is missing. Loader errors are returned as is and
+	// nothing is stored. Concurrent misses of a key share a single loader
+	// call, and entries about to expire are refreshed ahead of time, see
+	// WithEarlyRefresh.
+	GetOrSet(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, error)
+}
+
+// Option customizes a Cache.
+type Option func(*options)
+
+type options struct {
+	prefix           string
+	maxEntries       int
+	earlyRefreshBeta float64
+	codec            Codec
+	compressAbove    int
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{earlyRefreshBeta: defaultEarlyRefreshBeta}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPrefix prepends prefix to every key, so caches of different types can
+// share a Redis database without colliding.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithMaxEntries bounds the number of entries of a memory cache, evicting the
+// least recently used ones. It is ignored by the Redis cache.
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithCodec sets the serialization of a Redis cache, JSONCodec by default.
+// The memory cache stores values as is and ignores it.
+func WithCodec(codec Codec) Option {
+	return func(o *options) {
+		o.codec = codec
+	}
+}
+
+// WithCompression gzips the values of a Redis cache whose encoding is larger
+// than threshold bytes. Values written with and without compression are not
+// readable by each other, so enabling it on an existing cache should come
+// with a new prefix.
+func WithCompression(threshold int) Option {
+	return func(o *options) {
+		o.compressAbove = threshold
+	}
+}
+
+// WithEarlyRefresh tunes how eagerly GetOrSet refreshes entries before they
+// expire, by a background call to the loader while the current value is still
+// served. Higher values refresh earlier; the default is 1 and 0 disables it.
+func WithEarlyRefresh(beta float64) Option {
+	return func(o *options) {
+		o.earlyRefreshBeta = beta
+	}
+}
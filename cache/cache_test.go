@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newTestRedis(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client, srv
+}
+
+// implementations returns every Cache implementation so the behaviour shared
+// by all of them is tested once.
+func implementations(t *testing.T) map[string]Cache[user] {
+	client, _ := newTestRedis(t)
+	return map[string]Cache[user]{
+		"memory": NewMemory[user](WithPrefix("users:")),
+		"redis":  NewRedis[user](client, WithPrefix("users:")),
+	}
+}
+
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := c.Get(ctx, "1")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			want := user{ID: "1", Name: "Ana"}
+			require.NoError(t, c.Set(ctx, "1", want, time.Minute))
+
+			got, err := c.Get(ctx, "1")
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			require.NoError(t, c.Delete(ctx, "1", "missing"))
+			_, err = c.Get(ctx, "1")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestCacheGetOrSet(t *testing.T) {
+	ctx := context.Background()
+	errLoad := errors.New("load failed")
+
+	for name, c := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			loader := func(context.Context) (user, error) {
+				calls++
+				return user{ID: "2", Name: "Budi"}, nil
+			}
+
+			for i := 0; i < 2; i++ {
+				got, err := c.GetOrSet(ctx, "2", time.Minute, loader)
+				require.NoError(t, err)
+				assert.Equal(t, user{ID: "2", Name: "Budi"}, got)
+			}
+			assert.Equal(t, 1, calls)
+
+			_, err := c.GetOrSet(ctx, "3", time.Minute, func(context.Context) (user, error) {
+				return user{}, errLoad
+			})
+			assert.ErrorIs(t, err, errLoad)
+			_, err = c.Get(ctx, "3")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	c := NewMemory[string]().(*memoryCache[string])
+	c.now = func() time.Time { return now }
+
+	require.NoError(t, c.Set(ctx, "short", "a", time.Second))
+	require.NoError(t, c.Set(ctx, "forever", "b", 0))
+
+	now = now.Add(2 * time.Second)
+
+	_, err := c.Get(ctx, "short")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotContains(t, c.entries, "short")
+
+	got, err := c.Get(ctx, "forever")
+	require.NoError(t, err)
+	assert.Equal(t, "b", got)
+}
+
+func TestRedisCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestRedis(t)
+	c := NewRedis[user](client, WithPrefix("users:"))
+
+	require.NoError(t, c.Set(ctx, "1", user{ID: "1"}, time.Minute))
+	assert.Equal(t, time.Minute, srv.TTL("users:1"))
+
+	srv.FastForward(2 * time.Minute)
+	_, err := c.Get(ctx, "1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryCacheMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory[int](WithMaxEntries(2))
+
+	require.NoError(t, c.Set(ctx, "a", 1, 0))
+	require.NoError(t, c.Set(ctx, "b", 2, 0))
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.NoError(t, c.Set(ctx, "c", 3, 0))
+
+	_, err = c.Get(ctx, "b")
+	assert.ErrorIs(t, err, ErrNotFound, "least recently used entry is evicted")
+	for _, key := range []string{"a", "c"} {
+		_, err = c.Get(ctx, key)
+		assert.NoError(t, err, key)
+	}
+}
+
+func TestTieredCache(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestRedis(t)
+
+	newInstance := func() (*Tiered[user], Cache[user]) {
+		local := NewMemory[user]()
+		return NewTiered[user](local, NewRedis[user](client), TieredConfig{Client: client}), local
+	}
+	first, _ := newInstance()
+	second, secondLocal := newInstance()
+
+	runCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go func() { _ = second.Run(runCtx) }()
+	require.Eventually(t, func() bool {
+		counts, err := client.PubSubNumSub(ctx, defaultInvalidationChannel).Result()
+		return err == nil && counts[defaultInvalidationChannel] > 0
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, first.Set(ctx, "1", user{ID: "1", Name: "Ana"}, time.Hour))
+
+	got, err := second.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", got.Name)
+	_, err = secondLocal.Get(ctx, "1")
+	require.NoError(t, err, "remote hits are back-filled")
+
+	// The local tier keeps serving without Redis.
+	srv.SetError("down")
+	got, err = second.Get(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "Ana", got.Name)
+	srv.SetError("")
+
+	require.NoError(t, first.Set(ctx, "1", user{ID: "1", Name: "Ani"}, time.Hour))
+	require.Eventually(t, func() bool {
+		got, err := second.Get(ctx, "1")
+		return err == nil && got.Name == "Ani"
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, first.Delete(ctx, "1"))
+	require.Eventually(t, func() bool {
+		_, err := second.Get(ctx, "1")
+		return errors.Is(err, ErrNotFound)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestTieredCacheRunWithoutClient(t *testing.T) {
+	c := NewTiered[user](NewMemory[user](), NewMemory[user](), TieredConfig{})
+	assert.ErrorIs(t, c.Run(context.Background()), errInvalidationDisabled)
+}
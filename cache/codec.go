@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"io"
+
+	"github.com/goccy/go-json"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec serializes the values of a Redis cache.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+var (
+	// JSONCodec encodes values as JSON. It is the default.
+	JSONCodec Codec = jsonCodec{}
+	// MsgpackCodec encodes values as MessagePack, more compact and faster
+	// than JSON for large payloads. It honors msgpack struct tags and falls
+	// back to json ones.
+	MsgpackCodec Codec = msgpackCodec{}
+	// GobCodec encodes values with encoding/gob, for Go-only consumers of
+	// types JSON cannot represent.
+	GobCodec Codec = gobCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// With compression enabled, stored values start with a byte telling whether
+// the rest is compressed.
+const (
+	flagRaw  byte = 0
+	flagGzip byte = 1
+)
+
+var errCorruptValue = errors.New("cache: corrupt value")
+
+// serializer encodes values with a codec, compressing the ones larger than
+// compressAbove bytes when it is positive.
+type serializer struct {
+	codec         Codec
+	compressAbove int
+}
+
+func newSerializer(o *options) serializer {
+	s := serializer{codec: o.codec, compressAbove: o.compressAbove}
+	if s.codec == nil {
+		s.codec = JSONCodec
+	}
+	return s
+}
+
+func (s serializer) marshal(v any) ([]byte, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil || s.compressAbove <= 0 {
+		return data, err
+	}
+
+	if len(data) <= s.compressAbove {
+		return append([]byte{flagRaw}, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(flagGzip)
+	zw := gzip.NewWriter(&buf)
+	if _, err = zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err = zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s serializer) unmarshal(data []byte, v any) error {
+	if s.compressAbove <= 0 {
+		return s.codec.Unmarshal(data, v)
+	}
+	if len(data) == 0 {
+		return errCorruptValue
+	}
+
+	switch data[0] {
+	case flagRaw:
+		return s.codec.Unmarshal(data[1:], v)
+	case flagGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		raw, err := io.ReadAll(zr)
+		if err != nil {
+			return err
+		}
+		return s.codec.Unmarshal(raw, v)
+	default:
+		return errCorruptValue
+	}
+}
@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisCacheCodecs(t *testing.T) {
+	ctx := context.Background()
+	large := user{ID: "1", Name: strings.Repeat("a", 10000)}
+
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{name: "json", opts: []Option{WithCodec(JSONCodec)}},
+		{name: "msgpack", opts: []Option{WithCodec(MsgpackCodec)}},
+		{name: "gob", opts: []Option{WithCodec(GobCodec)}},
+		{name: "json compressed", opts: []Option{WithCompression(1024)}},
+		{name: "msgpack compressed", opts: []Option{WithCodec(MsgpackCodec), WithCompression(1024)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, srv := newTestRedis(t)
+			c := NewRedis[user](client, tt.opts...)
+
+			for _, want := range []user{{ID: "2", Name: "Ana"}, large} {
+				require.NoError(t, c.Set(ctx, want.ID, want, time.Minute))
+				got, err := c.Get(ctx, want.ID)
+				require.NoError(t, err)
+				assert.Equal(t, want, got)
+			}
+
+			stored, err := srv.Get(large.ID)
+			require.NoError(t, err)
+			if newOptions(tt.opts).compressAbove > 0 {
+				assert.Equal(t, flagGzip, stored[0])
+				assert.Less(t, len(stored), 1024)
+			} else {
+				assert.Greater(t, len(stored), 10000)
+			}
+		})
+	}
+}
+
+func TestSerializerCompressionThreshold(t *testing.T) {
+	s := newSerializer(&options{compressAbove: 16})
+
+	small, err := s.marshal("short")
+	require.NoError(t, err)
+	assert.Equal(t, flagRaw, small[0])
+
+	big, err := s.marshal(strings.Repeat("x", 100))
+	require.NoError(t, err)
+	assert.Equal(t, flagGzip, big[0])
+
+	var got string
+	require.NoError(t, s.unmarshal(big, &got))
+	assert.Equal(t, strings.Repeat("x", 100), got)
+
+	assert.ErrorIs(t, s.unmarshal([]byte{9, 'x'}, &got), errCorruptValue)
+	assert.ErrorIs(t, s.unmarshal(nil, &got), errCorruptValue)
+}
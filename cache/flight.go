@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const defaultEarlyRefreshBeta = 1.0
+
+// ttlGetter is implemented by the caches able to report the remaining time to
+// live of an entry, which early refresh is based on. A zero ttl means the
+// entry never expires.
+type ttlGetter[T any] interface {
+	getWithTTL(ctx context.Context, key string) (T, time.Duration, error)
+}
+
+// flight implements GetOrSet with stampede protection:
+//
+//   - concurrent misses of a key share a single loader call;
+//   - hits close to expiry refresh the entry in the background with a
+//     probability growing as expiry approaches (XFetch), so a hot key is
+//     reloaded once before it expires instead of by every caller after.
+type flight[T any] struct {
+	group singleflight.Group
+	beta  float64
+	// delta is the moving average of the loader duration in nanoseconds,
+	// shared by every key.
+	delta  atomic.Int64
+	random func() float64
+}
+
+func newFlight[T any](o *options) *flight[T] {
+	return &flight[T]{
+		beta:   o.earlyRefreshBeta,
+		random: rand.Float64,
+	}
+}
+
+func (f *flight[T]) getOrSet(ctx context.Context, c Cache[T], key string, ttl time.Duration, loader Loader[T]) (T, error) {
+	value, remaining, err := f.get(ctx, c, key)
+	if err == nil {
+		if f.shouldRefresh(remaining) {
+			go func() {
+				_, _ = f.load(context.WithoutCancel(ctx), c, key, ttl, loader)
+			}()
+		}
+		return value, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return value, err
+	}
+
+	return f.load(ctx, c, key, ttl, loader)
+}
+
+func (f *flight[T]) get(ctx context.Context, c Cache[T], key string) (T, time.Duration, error) {
+	if getter, ok := c.(ttlGetter[T]); ok && f.beta > 0 {
+		return getter.getWithTTL(ctx, key)
+	}
+	value, err := c.Get(ctx, key)
+	return value, 0, err
+}
+
+// load calls loader and stores its result. Concurrent calls for the same key
+// wait for the first one and share its result.
+func (f *flight[T]) load(ctx context.Context, c Cache[T], key string, ttl time.Duration, loader Loader[T]) (T, error) {
+	result, err, _ := f.group.Do(key, func() (any, error) {
+		started := time.Now()
+		value, err := loader(ctx)
+		if err != nil {
+			return value, err
+		}
+		f.observe(time.Since(started))
+		return value, c.Set(ctx, key, value, ttl)
+	})
+
+	value, _ := result.(T)
+	return value, err
+}
+
+func (f *flight[T]) observe(elapsed time.Duration) {
+	previous := f.delta.Load()
+	if previous == 0 {
+		f.delta.Store(int64(elapsed))
+		return
+	}
+	// Exponential moving average weighting the last load by 1/8.
+	f.delta.Store(previous + (int64(elapsed)-previous)/8)
+}
+
+// shouldRefresh reports whether an entry expiring in remaining is refreshed
+// early: with delta the loader duration, the probability exceeds 1/e once
+// remaining drops below delta*beta and grows quickly from there.
+func (f *flight[T]) shouldRefresh(remaining time.Duration) bool {
+	delta := f.delta.Load()
+	if f.beta <= 0 || remaining <= 0 || delta == 0 {
+		return false
+	}
+	return float64(delta)*f.beta*-math.Log(f.random()) >= float64(remaining)
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrSetCoalescesMisses(t *testing.T) {
+	ctx := context.Background()
+
+	for name, c := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			release := make(chan struct{})
+			loader := func(context.Context) (user, error) {
+				calls.Add(1)
+				<-release
+				return user{ID: "1", Name: "Ana"}, nil
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					got, err := c.GetOrSet(ctx, "1", time.Minute, loader)
+					assert.NoError(t, err)
+					assert.Equal(t, "Ana", got.Name)
+				}()
+			}
+
+			// Let the callers pile up on the loader before releasing it.
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+func TestFlightShouldRefresh(t *testing.T) {
+	tests := []struct {
+		name      string
+		beta      float64
+		delta     time.Duration
+		random    float64
+		remaining time.Duration
+		want      bool
+	}{
+		{
+			name:      "far from expiry",
+			beta:      1,
+			delta:     100 * time.Millisecond,
+			random:    0.5,
+			remaining: time.Minute,
+		},
+		{
+			name:      "close to expiry",
+			beta:      1,
+			delta:     100 * time.Millisecond,
+			random:    0.5,
+			remaining: 50 * time.Millisecond,
+			want:      true,
+		},
+		{
+			name:      "disabled",
+			beta:      0,
+			delta:     100 * time.Millisecond,
+			random:    0.5,
+			remaining: 50 * time.Millisecond,
+		},
+		{
+			name:      "no expiry",
+			beta:      1,
+			delta:     100 * time.Millisecond,
+			random:    0.5,
+			remaining: 0,
+		},
+		{
+			name:      "loader never observed",
+			beta:      1,
+			random:    0.5,
+			remaining: 50 * time.Millisecond,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFlight[user](&options{earlyRefreshBeta: tt.beta})
+			f.delta.Store(int64(tt.delta))
+			f.random = func() float64 { return tt.random }
+
+			assert.Equal(t, tt.want, f.shouldRefresh(tt.remaining))
+		})
+	}
+}
+
+func TestGetOrSetEarlyRefresh(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory[int]().(*memoryCache[int])
+	// Always refresh once the loader duration is known.
+	c.flight.random = func() float64 { return 0 }
+
+	var calls atomic.Int32
+	loader := func(context.Context) (int, error) {
+		time.Sleep(time.Millisecond)
+		return int(calls.Add(1)), nil
+	}
+
+	got, err := c.GetOrSet(ctx, "counter", time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got)
+
+	// The hit serves the current value and refreshes it in the background.
+	got, err = c.GetOrSet(ctx, "counter", time.Minute, loader)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got)
+
+	require.Eventually(t, func() bool {
+		got, err := c.Get(ctx, "counter")
+		return err == nil && got == 2
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry[T any] struct {
+	key       string
+	value     T
+	expiresAt time.Time
+}
+
+func (e *memoryEntry[T]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+type memoryCache[T any] struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	prefix     string
+	now        func() time.Time
+	flight     *flight[T]
+}
+
+// NewMemory creates a process-local Cache. Values are stored as is, so
+// pointers and slices are shared with the callers. Expired entries are
+// dropped when read, and the least recently used ones are evicted once
+// WithMaxEntries is reached.
+func NewMemory[T any](opts ...Option) Cache[T] {
+	o := newOptions(opts)
+	return &memoryCache[T]{
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+		maxEntries: o.maxEntries,
+		prefix:     o.prefix,
+		now:        time.Now,
+		flight:     newFlight[T](o),
+	}
+}
+
+func (c *memoryCache[T]) Get(ctx context.Context, key string) (T, error) {
+	value, _, err := c.getWithTTL(ctx, key)
+	return value, err
+}
+
+func (c *memoryCache[T]) getWithTTL(_ context.Context, key string) (value T, ttl time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[c.prefix+key]
+	if !ok {
+		return value, 0, ErrNotFound
+	}
+	entry := elem.Value.(*memoryEntry[T])
+	now := c.now()
+	if entry.expired(now) {
+		c.remove(elem)
+		return value, 0, ErrNotFound
+	}
+
+	c.lru.MoveToFront(elem)
+	if !entry.expiresAt.IsZero() {
+		ttl = entry.expiresAt.Sub(now)
+	}
+	return entry.value, ttl, nil
+}
+
+func (c *memoryCache[T]) Set(_ context.Context, key string, value T, ttl time.Duration) error {
+	entry := &memoryEntry[T]{key: c.prefix + key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[entry.key]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+
+	return nil
+}
+
+func (c *memoryCache[T]) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[c.prefix+key]; ok {
+			c.remove(elem)
+		}
+	}
+
+	return nil
+}
+
+func (c *memoryCache[T]) GetOrSet(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, error) {
+	return c.flight.getOrSet(ctx, c, key, ttl, loader)
+}
+
+// remove drops elem. The lock must be held.
+func (c *memoryCache[T]) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryEntry[T]).key)
+}
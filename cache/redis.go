@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisCache[T any] struct {
+	client redis.UniversalClient
+	prefix string
+	flight *flight[T]
+	serial serializer
+}
+
+// NewRedis creates a Cache storing values in Redis, JSON encoded unless
+// WithCodec says otherwise.
+func NewRedis[T any](client redis.UniversalClient, opts ...Option) Cache[T] {
+	o := newOptions(opts)
+	return &redisCache[T]{
+		client: client,
+		prefix: o.prefix,
+		flight: newFlight[T](o),
+		serial: newSerializer(o),
+	}
+}
+
+func (c *redisCache[T]) Get(ctx context.Context, key string) (value T, err error) {
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return value, ErrNotFound
+	}
+	if err != nil {
+		return value, err
+	}
+
+	err = c.serial.unmarshal(data, &value)
+	return value, err
+}
+
+func (c *redisCache[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	data, err := c.serial.marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, c.prefix+key, data, ttl).Err()
+}
+
+func (c *redisCache[T]) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, prefixed...).Err()
+}
+
+func (c *redisCache[T]) GetOrSet(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, error) {
+	return c.flight.getOrSet(ctx, c, key, ttl, loader)
+}
+
+func (c *redisCache[T]) getWithTTL(ctx context.Context, key string) (value T, ttl time.Duration, err error) {
+	var get *redis.StringCmd
+	var pttl *redis.DurationCmd
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, c.prefix+key)
+		pttl = pipe.PTTL(ctx, c.prefix+key)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return value, 0, ErrNotFound
+	}
+	if err != nil {
+		return value, 0, err
+	}
+
+	if err = c.serial.unmarshal([]byte(get.Val()), &value); err != nil {
+		return value, 0, err
+	}
+
+	// PTTL is negative for keys without expiry.
+	return value, max(pttl.Val(), 0), nil
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/pubsub"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultLocalTTL            = time.Minute
+	defaultInvalidationChannel = "cache:invalidate"
+)
+
+var errInvalidationDisabled = errors.New("cache: invalidation requires TieredConfig.Client")
+
+// TieredConfig configures a Tiered cache.
+type TieredConfig struct {
+	// LocalTTL caps how long an entry stays in the local tier, one minute by
+	// default. It bounds staleness when an invalidation is missed.
+	LocalTTL time.Duration
+
+	// Client enables invalidation across instances: writes publish the keys
+	// they change and Run drops them from the local tier of every other
+	// instance.
+	Client redis.UniversalClient
+	// Channel is the invalidation channel, "cache:invalidate" by default.
+	// Caches of different types should use different channels.
+	Channel string
+
+	// Logger receives invalidation failures. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+type invalidation struct {
+	Source string   `json:"source"`
+	Keys   []string `json:"keys"`
+}
+
+// Tiered is a Cache checking a process-local tier, typically a memory cache
+// bounded with WithMaxEntries, before a shared remote one such as Redis.
+// Remote hits are back-filled into the local tier.
+type Tiered[T any] struct {
+	local    Cache[T]
+	remote   Cache[T]
+	localTTL time.Duration
+
+	client  redis.UniversalClient
+	channel string
+	log     logger.Logger
+	id      string
+	flight  *flight[T]
+}
+
+// NewTiered creates a Tiered cache. Call Run to receive the invalidations of
+// the other instances.
+func NewTiered[T any](local, remote Cache[T], config TieredConfig) *Tiered[T] {
+	c := &Tiered[T]{
+		local:    local,
+		remote:   remote,
+		localTTL: config.LocalTTL,
+		client:   config.Client,
+		channel:  config.Channel,
+		log:      logger.WithLevel(config.Logger, logger.InfoLevel),
+		id:       newInstanceID(),
+		// The remaining TTL is not known across tiers, so only concurrent
+		// misses are coalesced.
+		flight: newFlight[T](&options{}),
+	}
+	if c.localTTL <= 0 {
+		c.localTTL = defaultLocalTTL
+	}
+	if c.channel == "" {
+		c.channel = defaultInvalidationChannel
+	}
+	return c
+}
+
+func (c *Tiered[T]) Get(ctx context.Context, key string) (T, error) {
+	if value, err := c.local.Get(ctx, key); err == nil {
+		return value, nil
+	}
+
+	value, err := c.remote.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+
+	_ = c.local.Set(ctx, key, value, c.localTTL)
+	return value, nil
+}
+
+func (c *Tiered[T]) Set(ctx context.Context, key string, value T, ttl time.Duration) error {
+	if err := c.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	localTTL := c.localTTL
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	_ = c.local.Set(ctx, key, value, localTTL)
+
+	return c.invalidate(ctx, key)
+}
+
+func (c *Tiered[T]) Delete(ctx context.Context, keys ...string) error {
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		return err
+	}
+	_ = c.local.Delete(ctx, keys...)
+
+	return c.invalidate(ctx, keys...)
+}
+
+func (c *Tiered[T]) GetOrSet(ctx context.Context, key string, ttl time.Duration, loader Loader[T]) (T, error) {
+	return c.flight.getOrSet(ctx, c, key, ttl, loader)
+}
+
+// Run drops the keys changed by other instances from the local tier until ctx
+// is done.
+func (c *Tiered[T]) Run(ctx context.Context) error {
+	if c.client == nil {
+		return errInvalidationDisabled
+	}
+
+	sub := pubsub.NewSubscriber(c.client, pubsub.WithLogger(c.log))
+	pubsub.Handle(sub, c.channel, func(ctx context.Context, _ string, msg invalidation) error {
+		if msg.Source == c.id {
+			return nil
+		}
+		return c.local.Delete(ctx, msg.Keys...)
+	})
+
+	return sub.Run(ctx)
+}
+
+func (c *Tiered[T]) invalidate(ctx context.Context, keys ...string) error {
+	if c.client == nil || len(keys) == 0 {
+		return nil
+	}
+	return pubsub.NewPublisher(c.client).Publish(ctx, c.channel, invalidation{Source: c.id, Keys: keys})
+}
+
+func newInstanceID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
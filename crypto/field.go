@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/goccy/go-json"
+)
+
+var errNoKeyring = errors.New("crypto: no keyring set, call SetFieldKeyring")
+
+var fieldKeyring atomic.Pointer[Keyring]
+
+// SetFieldKeyring sets the keyring of EncryptedString, EncryptedJSON and
+// BlindIndex. Call it at startup, before the first query.
+func SetFieldKeyring(k *Keyring) {
+	fieldKeyring.Store(k)
+}
+
+func currentKeyring() (*Keyring, error) {
+	k := fieldKeyring.Load()
+	if k == nil {
+		return nil, errNoKeyring
+	}
+	return k, nil
+}
+
+// EncryptedString is a string column encrypted on write and decrypted on
+// read, with sqlx and GORM alike. It is stored as base64 text.
+//
+//	type User struct {
+//		Email      crypto.EncryptedString `db:"email"`
+//		EmailIndex string                 `db:"email_index"`
+//	}
+type EncryptedString string
+
+// Value implements driver.Valuer.
+func (s EncryptedString) Value() (driver.Value, error) {
+	return encryptField([]byte(s))
+}
+
+// Scan implements sql.Scanner.
+func (s *EncryptedString) Scan(src any) error {
+	plaintext, err := decryptField(src)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// GormDataType sets the column type of GORM migrations.
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
+
+// EncryptedJSON is a column holding V as encrypted JSON.
+type EncryptedJSON[T any] struct {
+	V T
+}
+
+// Value implements driver.Valuer.
+func (j EncryptedJSON[T]) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+	return encryptField(b)
+}
+
+// Scan implements sql.Scanner.
+func (j *EncryptedJSON[T]) Scan(src any) error {
+	plaintext, err := decryptField(src)
+	if err != nil {
+		return err
+	}
+	var v T
+	if plaintext != nil {
+		if err := json.Unmarshal(plaintext, &v); err != nil {
+			return err
+		}
+	}
+	j.V = v
+	return nil
+}
+
+// GormDataType sets the column type of GORM migrations.
+func (EncryptedJSON[T]) GormDataType() string {
+	return "text"
+}
+
+// BlindIndex returns the blind index of value with the keyring set by
+// SetFieldKeyring, the value of the companion column used for lookups:
+//
+//	index, err := crypto.BlindIndex(strings.ToLower(email))
+//	err = db.GetContext(ctx, &user, "SELECT * FROM users WHERE email_index = $1", index)
+func BlindIndex(value string) (string, error) {
+	k, err := currentKeyring()
+	if err != nil {
+		return "", err
+	}
+	return k.BlindIndex(value)
+}
+
+func encryptField(plaintext []byte) (driver.Value, error) {
+	k, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := k.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptField decrypts a scanned column, returning nil for NULL.
+func decryptField(src any) ([]byte, error) {
+	var encoded string
+	switch v := src.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		encoded = v
+	case []byte:
+		encoded = string(v)
+	default:
+		return nil, fmt.Errorf("crypto: cannot scan %T into an encrypted field", src)
+	}
+
+	k, err := currentKeyring()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCiphertext
+	}
+	return k.Decrypt(ciphertext)
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptedString(t *testing.T) {
+	SetFieldKeyring(nil)
+	_, err := EncryptedString("jane@example.com").Value()
+	assert.ErrorIs(t, err, errNoKeyring)
+
+	SetFieldKeyring(newTestKeyring(t, "2024"))
+	defer SetFieldKeyring(nil)
+
+	value, err := EncryptedString("jane@example.com").Value()
+	require.NoError(t, err)
+	assert.NotContains(t, value, "jane")
+
+	for name, src := range map[string]any{"text": value, "bytes": []byte(value.(string))} {
+		t.Run(name, func(t *testing.T) {
+			var got EncryptedString
+			require.NoError(t, got.Scan(src))
+			assert.Equal(t, EncryptedString("jane@example.com"), got)
+		})
+	}
+
+	var null EncryptedString = "stale"
+	require.NoError(t, null.Scan(nil))
+	assert.Empty(t, null)
+
+	var got EncryptedString
+	assert.Error(t, got.Scan(42))
+	assert.ErrorIs(t, got.Scan("not base64!"), errInvalidCiphertext)
+}
+
+func TestEncryptedJSON(t *testing.T) {
+	SetFieldKeyring(newTestKeyring(t, "2024"))
+	defer SetFieldKeyring(nil)
+
+	type address struct {
+		Street string `json:"street"`
+		City   string `json:"city"`
+	}
+	value, err := EncryptedJSON[address]{V: address{Street: "Main St 1", City: "Springfield"}}.Value()
+	require.NoError(t, err)
+
+	var got EncryptedJSON[address]
+	require.NoError(t, got.Scan(value))
+	assert.Equal(t, address{Street: "Main St 1", City: "Springfield"}, got.V)
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := newTestKeyring(t, "2024")
+	SetFieldKeyring(k)
+	defer SetFieldKeyring(nil)
+
+	got, err := BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	want, err := k.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
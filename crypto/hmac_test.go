@@ -0,0 +1,38 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignPayload(t *testing.T) {
+	// Reference value from RFC 4231, test case 2.
+	got := SignPayload([]byte("Jefe"), []byte("what do ya want for nothing?"))
+	assert.Equal(t, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", got)
+}
+
+func TestVerifyPayload(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"event":"order.created"}`)
+	signature := SignPayload(secret, payload)
+
+	tests := []struct {
+		name      string
+		secret    []byte
+		payload   []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, payload: payload, signature: signature, want: true},
+		{name: "tampered payload", secret: secret, payload: []byte(`{"event":"order.paid"}`), signature: signature},
+		{name: "wrong secret", secret: []byte("other"), payload: payload, signature: signature},
+		{name: "malformed signature", secret: secret, payload: payload, signature: "not-hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VerifyPayload(tt.secret, tt.payload, tt.signature))
+		})
+	}
+}
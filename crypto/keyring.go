@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const ciphertextVersion = 1
+
+var (
+	ErrUnknownKey = errors.New("crypto: ciphertext encrypted with an unknown key")
+
+	errKeyringPrimary    = errors.New("crypto: keyring primary key is missing")
+	errKeyringIndexKey   = errors.New("crypto: keyring has no index key")
+	errInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+)
+
+type KeyringConfig struct {
+	// Keys maps key IDs to AES keys of 16, 24 or 32 bytes. Values are
+	// encrypted with PrimaryKeyID and decrypted with the key they were
+	// encrypted with, so keys can be rotated by adding a new primary key
+	// and keeping the old ones until every value is re-encrypted.
+	Keys         map[string][]byte
+	PrimaryKeyID string
+
+	// IndexKey keys the HMAC of BlindIndex. It cannot be rotated without
+	// recomputing every index.
+	IndexKey []byte
+}
+
+// Keyring encrypts values with AES-GCM under rotating keys.
+type Keyring struct {
+	primary  string
+	aeads    map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring creates a Keyring from the keys of config.
+func NewKeyring(config KeyringConfig) (*Keyring, error) {
+	if _, ok := config.Keys[config.PrimaryKeyID]; !ok {
+		return nil, errKeyringPrimary
+	}
+
+	k := &Keyring{
+		primary:  config.PrimaryKeyID,
+		aeads:    make(map[string]cipher.AEAD, len(config.Keys)),
+		indexKey: config.IndexKey,
+	}
+	for id, key := range config.Keys {
+		if len(id) == 0 || len(id) > 255 {
+			return nil, fmt.Errorf("crypto: key ID %q must have 1 to 255 bytes", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("crypto: key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// Encrypt encrypts plaintext with the primary key. The ciphertext carries
+// the key ID and a random nonce.
+func (k *Keyring) Encrypt(plaintext []byte) ([]byte, error) {
+	aead := k.aeads[k.primary]
+	header := append([]byte{ciphertextVersion, byte(len(k.primary))}, k.primary...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	// The header is authenticated so the key ID cannot be swapped.
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+// Decrypt decrypts a ciphertext of Encrypt, with ErrUnknownKey when its key
+// is no longer in the keyring.
+func (k *Keyring) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != ciphertextVersion {
+		return nil, errInvalidCiphertext
+	}
+	end := 2 + int(ciphertext[1])
+	if len(ciphertext) < end {
+		return nil, errInvalidCiphertext
+	}
+	aead, ok := k.aeads[string(ciphertext[2:end])]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if len(ciphertext) < end+aead.NonceSize() {
+		return nil, errInvalidCiphertext
+	}
+
+	header, nonce := ciphertext[:end], ciphertext[end:end+aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[end+aead.NonceSize():], header)
+	if err != nil {
+		return nil, errInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// NeedsRotation reports whether ciphertext was encrypted with another key
+// than the primary one and should be re-encrypted.
+func (k *Keyring) NeedsRotation(ciphertext []byte) bool {
+	if len(ciphertext) < 2 || len(ciphertext) < 2+int(ciphertext[1]) {
+		return false
+	}
+	return string(ciphertext[2:2+int(ciphertext[1])]) != k.primary
+}
+
+// BlindIndex returns the URL safe base64 HMAC-SHA256 of value keyed with the
+// index key, to be stored next to the encrypted value and looked up by
+// equality. Normalize value first, e.g. lowercase emails, for lookups to
+// ignore the differences.
+func (k *Keyring) BlindIndex(value string) (string, error) {
+	if len(k.indexKey) == 0 {
+		return "", errKeyringIndexKey
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
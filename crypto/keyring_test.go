@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, primary string) *Keyring {
+	t.Helper()
+	k, err := NewKeyring(KeyringConfig{
+		Keys: map[string][]byte{
+			"2023": bytes.Repeat([]byte{1}, 32),
+			"2024": bytes.Repeat([]byte{2}, 32),
+		},
+		PrimaryKeyID: primary,
+		IndexKey:     []byte("index-key"),
+	})
+	require.NoError(t, err)
+	return k
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old := newTestKeyring(t, "2023")
+	ciphertext, err := old.Encrypt([]byte("4111 1111 1111 1111"))
+	require.NoError(t, err)
+
+	rotated := newTestKeyring(t, "2024")
+	assert.True(t, rotated.NeedsRotation(ciphertext))
+	plaintext, err := rotated.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "4111 1111 1111 1111", string(plaintext))
+
+	reencrypted, err := rotated.Encrypt(plaintext)
+	require.NoError(t, err)
+	assert.False(t, rotated.NeedsRotation(reencrypted))
+
+	_, err = old.Decrypt(reencrypted)
+	assert.NoError(t, err)
+	removed, err := NewKeyring(KeyringConfig{Keys: map[string][]byte{"2025": bytes.Repeat([]byte{3}, 32)}, PrimaryKeyID: "2025"})
+	require.NoError(t, err)
+	_, err = removed.Decrypt(reencrypted)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestKeyringDecryptInvalid(t *testing.T) {
+	k := newTestKeyring(t, "2024")
+	ciphertext, err := k.Encrypt([]byte("secret"))
+	require.NoError(t, err)
+
+	tampered := bytes.Clone(ciphertext)
+	tampered[len(tampered)-1] ^= 1
+	// Swapping the key ID fails authentication even with a known key.
+	swapped := bytes.Clone(ciphertext)
+	copy(swapped[2:], "2023")
+
+	for name, ciphertext := range map[string][]byte{
+		"empty":     nil,
+		"truncated": ciphertext[:8],
+		"tampered":  tampered,
+		"swapped":   swapped,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := k.Decrypt(ciphertext)
+			assert.ErrorIs(t, err, errInvalidCiphertext)
+		})
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring(KeyringConfig{Keys: map[string][]byte{"a": make([]byte, 32)}, PrimaryKeyID: "b"})
+	assert.ErrorIs(t, err, errKeyringPrimary)
+
+	_, err = NewKeyring(KeyringConfig{Keys: map[string][]byte{"a": make([]byte, 7)}, PrimaryKeyID: "a"})
+	assert.Error(t, err)
+}
+
+func TestKeyringBlindIndex(t *testing.T) {
+	k := newTestKeyring(t, "2024")
+	a, err := k.BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	b, err := newTestKeyring(t, "2023").BlindIndex("jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, a, b, "the index does not depend on the encryption keys")
+
+	other, err := k.BlindIndex("john@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, other)
+
+	noIndex, err := NewKeyring(KeyringConfig{Keys: map[string][]byte{"a": make([]byte, 32)}, PrimaryKeyID: "a"})
+	require.NoError(t, err)
+	_, err = noIndex.BlindIndex("jane@example.com")
+	assert.ErrorIs(t, err, errKeyringIndexKey)
+}
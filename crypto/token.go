@@ -0,0 +1,15 @@
+package crypto
+
+import "encoding/base64"
+
+// GenerateToken returns n cryptographically random bytes encoded with the URL
+// safe base64 alphabet, without padding. 32 bytes are enough for session IDs
+// and API keys.
+func GenerateToken(n uint32) (string, error) {
+	b, err := generateRandomBytes(n)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,34 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/agiledragon/gomonkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateToken(t *testing.T) {
+	token, err := GenerateToken(32)
+	require.NoError(t, err)
+
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	assert.Len(t, decoded, 32)
+
+	other, err := GenerateToken(32)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestGenerateTokenError(t *testing.T) {
+	patches := gomonkey.ApplyFunc(generateRandomBytes, func(uint32) ([]byte, error) {
+		return nil, errors.New("entropy exhausted")
+	})
+	defer patches.Reset()
+
+	_, err := GenerateToken(32)
+	assert.Error(t, err)
+}
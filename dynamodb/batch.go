@@ -0,0 +1,102 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bagastri07/platigo/logger"
+)
+
+// Limits of a single BatchGetItem and BatchWriteItem request.
+const (
+	maxBatchGet   = 100
+	maxBatchWrite = 25
+)
+
+// BatchGet reads the items of keys into out, a pointer to a slice. Missing
+// items are left out, and the order of the items is not the one of keys.
+// Requests are split by 100 keys, and the keys DynamoDB leaves unprocessed
+// under load are requested again with backoff.
+func (t *Table) BatchGet(ctx context.Context, keys []Key, out any) error {
+	var items []map[string]types.AttributeValue
+	for start := 0; start < len(keys); start += maxBatchGet {
+		chunk := keys[start:min(start+maxBatchGet, len(keys))]
+		request := &types.KeysAndAttributes{}
+		for _, key := range chunk {
+			request.Keys = append(request.Keys, t.key(key))
+		}
+
+		pending := map[string]types.KeysAndAttributes{t.config.Table: *request}
+		err := t.retryUnprocessed(ctx, func() (int, error) {
+			res, err := t.client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: pending})
+			if err != nil {
+				return 0, err
+			}
+			items = append(items, res.Responses[t.config.Table]...)
+			pending = res.UnprocessedKeys
+			return len(pending[t.config.Table].Keys), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
+
+// BatchWrite puts the items of puts and deletes the items of deletes, split
+// in requests of 25 writes. The writes DynamoDB leaves unprocessed under load
+// are sent again with backoff. The batch is not atomic: on error some writes
+// may be applied.
+func (t *Table) BatchWrite(ctx context.Context, puts []any, deletes []Key) error {
+	writes := make([]types.WriteRequest, 0, len(puts)+len(deletes))
+	for _, item := range puts {
+		av, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+	for _, key := range deletes {
+		writes = append(writes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: t.key(key)}})
+	}
+
+	for start := 0; start < len(writes); start += maxBatchWrite {
+		pending := map[string][]types.WriteRequest{t.config.Table: writes[start:min(start+maxBatchWrite, len(writes))]}
+		err := t.retryUnprocessed(ctx, func() (int, error) {
+			res, err := t.client.BatchWriteItem(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+			if err != nil {
+				return 0, err
+			}
+			pending = res.UnprocessedItems
+			return len(pending[t.config.Table]), nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryUnprocessed calls send until it reports no unprocessed item, up to
+// MaxAttempts times.
+func (t *Table) retryUnprocessed(ctx context.Context, send func() (int, error)) error {
+	for attempt := 1; ; attempt++ {
+		unprocessed, err := send()
+		if err != nil || unprocessed == 0 {
+			return err
+		}
+		if attempt == t.config.MaxAttempts {
+			return fmt.Errorf("dynamodb: %d items still unprocessed after %d attempts", unprocessed, attempt)
+		}
+		t.log.With(logger.Fields{
+			"unprocessed": unprocessed,
+			"attempt":     attempt,
+		}).Debug("Retrying unprocessed DynamoDB items")
+		if err := t.sleep(ctx, t.config.Backoff(attempt+1)); err != nil {
+			return err
+		}
+	}
+}
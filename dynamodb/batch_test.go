@@ -0,0 +1,71 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableBatchGet(t *testing.T) {
+	var requests []int
+	api := &fakeAPI{batchGetItem: func(in *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+		keys := in.RequestItems["app"].Keys
+		requests = append(requests, len(keys))
+
+		// The first request of each chunk leaves its last key unprocessed.
+		out := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+		processed := keys
+		if len(keys) > 1 {
+			processed = keys[:len(keys)-1]
+			out.UnprocessedKeys = map[string]types.KeysAndAttributes{"app": {Keys: keys[len(keys)-1:]}}
+		}
+		for _, key := range processed {
+			out.Responses["app"] = append(out.Responses["app"], orderItem(key["sk"].(*types.AttributeValueMemberS).Value, "paid"))
+		}
+		return out, nil
+	}}
+	table := newTestTable(t, api)
+
+	keys := make([]Key, 150)
+	for i := range keys {
+		keys[i] = Key{PK: "USER#42", SK: fmt.Sprintf("ORDER#%d", i)}
+	}
+	var got []order
+	require.NoError(t, table.BatchGet(context.Background(), keys, &got))
+	assert.Len(t, got, 150)
+	assert.Equal(t, []int{100, 1, 50, 1}, requests)
+}
+
+func TestTableBatchWrite(t *testing.T) {
+	var requests []int
+	unprocessed := 0
+	api := &fakeAPI{batchWriteItem: func(in *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		writes := in.RequestItems["app"]
+		requests = append(requests, len(writes))
+		if unprocessed > 0 {
+			unprocessed--
+			return &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]types.WriteRequest{"app": writes[:min(2, len(writes))]}}, nil
+		}
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}}
+	table := newTestTable(t, api)
+
+	puts := make([]any, 30)
+	for i := range puts {
+		puts[i] = order{PK: "USER#42", SK: fmt.Sprintf("ORDER#%d", i)}
+	}
+	unprocessed = 1
+	require.NoError(t, table.BatchWrite(context.Background(), puts, []Key{{PK: "USER#42", SK: "CART"}}))
+	assert.Equal(t, []int{25, 2, 6}, requests)
+
+	// Items still unprocessed after MaxAttempts fail the batch.
+	requests, unprocessed = nil, 10
+	err := table.BatchWrite(context.Background(), puts[:1], nil)
+	assert.ErrorContains(t, err, "still unprocessed after 5 attempts")
+	assert.Len(t, requests, 5)
+}
@@ -0,0 +1,146 @@
+// Package dynamodb wraps a DynamoDB table laid out for single-table design,
+// with items keyed by a generic partition and sort key:
+//
+//	table, err := dynamodb.NewTable(awsdynamodb.NewFromConfig(cfg), dynamodb.Config{Table: "app"})
+//	err = table.Put(ctx, order, dynamodb.IfNotExists())
+//	err = table.Get(ctx, dynamodb.Key{PK: "USER#42", SK: "ORDER#1"}, &order)
+//
+//	it := table.Query("USER#42", dynamodb.SortKeyBeginsWith("ORDER#"))
+//	for it.Next(ctx) {
+//		var order Order
+//		if err := it.Scan(&order); err != nil { ... }
+//	}
+//	err = it.Err()
+//
+// Items are marshaled with the dynamodbav struct tags of attributevalue.
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/logger"
+)
+
+const (
+	defaultPartitionKey = "pk"
+	defaultSortKey      = "sk"
+	defaultMaxAttempts  = 5
+)
+
+var (
+	// ErrNotFound is returned by Get when the item does not exist.
+	ErrNotFound = errors.New("dynamodb: item not found")
+	// ErrConditionFailed is returned by writes whose condition does not
+	// hold, such as IfNotExists on an existing item.
+	ErrConditionFailed = errors.New("dynamodb: condition failed")
+
+	errTableRequired   = errors.New("dynamodb: table is required")
+	errNothingToUpdate = errors.New("dynamodb: update has no attribute to set")
+)
+
+// API is the part of the DynamoDB client used by Table.
+type API interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error)
+	BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+// Config configures a Table.
+type Config struct {
+	Table string
+
+	// PartitionKey and SortKey are the names of the key attributes, "pk"
+	// and "sk" by default.
+	PartitionKey string
+	SortKey      string
+
+	// MaxAttempts is the number of times a batch is sent while DynamoDB
+	// leaves items unprocessed, 5 by default. Backoff returns the delay
+	// before the given attempt, starting at 2, an exponential backoff from
+	// 50ms up to 2s by default.
+	MaxAttempts int
+	Backoff     func(attempt int) time.Duration
+
+	// Logger receives the table logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Table reads and writes the items of a table.
+type Table struct {
+	client API
+	config Config
+	log    logger.Logger
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// Key is the key of an item. SK is left out for tables without sort key.
+type Key struct {
+	PK string
+	SK string
+}
+
+// NewTable creates a Table on config.Table.
+func NewTable(client API, config Config) (*Table, error) {
+	if config.Table == "" {
+		return nil, errTableRequired
+	}
+	if config.PartitionKey == "" {
+		config.PartitionKey = defaultPartitionKey
+	}
+	if config.SortKey == "" {
+		config.SortKey = defaultSortKey
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = platigo.ExponentialBackoff(50*time.Millisecond, 2*time.Second)
+	}
+
+	return &Table{
+		client: client,
+		config: config,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"table": config.Table}),
+		sleep:  sleep,
+	}, nil
+}
+
+// key returns the attribute values of k.
+func (t *Table) key(k Key) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{
+		t.config.PartitionKey: &types.AttributeValueMemberS{Value: k.PK},
+	}
+	if k.SK != "" {
+		key[t.config.SortKey] = &types.AttributeValueMemberS{Value: k.SK}
+	}
+	return key
+}
+
+// conditionError maps a failed condition check to ErrConditionFailed.
+func conditionError(err error) error {
+	var failed *types.ConditionalCheckFailedException
+	if errors.As(err, &failed) {
+		return errors.Join(ErrConditionFailed, err)
+	}
+	return err
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
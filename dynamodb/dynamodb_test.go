@@ -0,0 +1,150 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI records the requests and answers them with the configured
+// functions.
+type fakeAPI struct {
+	API
+
+	getItem        func(*dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error)
+	putItem        func(*dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error)
+	updateItem     func(*dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
+	deleteItem     func(*dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+	query          func(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	batchGetItem   func(*dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+	batchWriteItem func(*dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+}
+
+func (f *fakeAPI) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return f.getItem(in)
+}
+
+func (f *fakeAPI) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	return f.putItem(in)
+}
+
+func (f *fakeAPI) UpdateItem(_ context.Context, in *dynamodb.UpdateItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	return f.updateItem(in)
+}
+
+func (f *fakeAPI) DeleteItem(_ context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	return f.deleteItem(in)
+}
+
+func (f *fakeAPI) Query(_ context.Context, in *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return f.query(in)
+}
+
+func (f *fakeAPI) BatchGetItem(_ context.Context, in *dynamodb.BatchGetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	return f.batchGetItem(in)
+}
+
+func (f *fakeAPI) BatchWriteItem(_ context.Context, in *dynamodb.BatchWriteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	return f.batchWriteItem(in)
+}
+
+type order struct {
+	PK     string `dynamodbav:"pk"`
+	SK     string `dynamodbav:"sk"`
+	Status string `dynamodbav:"status"`
+	Total  int    `dynamodbav:"total"`
+}
+
+func newTestTable(t *testing.T, api *fakeAPI) *Table {
+	t.Helper()
+	table, err := NewTable(api, Config{Table: "app"})
+	require.NoError(t, err)
+	table.sleep = func(context.Context, time.Duration) error { return nil }
+	return table
+}
+
+func s(value string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: value}
+}
+
+func orderItem(sk, status string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk":     s("USER#42"),
+		"sk":     s(sk),
+		"status": s(status),
+		"total":  &types.AttributeValueMemberN{Value: "10"},
+	}
+}
+
+func TestNewTable(t *testing.T) {
+	_, err := NewTable(&fakeAPI{}, Config{})
+	assert.ErrorIs(t, err, errTableRequired)
+}
+
+func TestTableGet(t *testing.T) {
+	api := &fakeAPI{getItem: func(in *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+		assert.Equal(t, "app", *in.TableName)
+		if in.Key["sk"].(*types.AttributeValueMemberS).Value == "ORDER#1" {
+			return &dynamodb.GetItemOutput{Item: orderItem("ORDER#1", "paid")}, nil
+		}
+		return &dynamodb.GetItemOutput{}, nil
+	}}
+	table := newTestTable(t, api)
+
+	var got order
+	require.NoError(t, table.Get(context.Background(), Key{PK: "USER#42", SK: "ORDER#1"}, &got))
+	assert.Equal(t, order{PK: "USER#42", SK: "ORDER#1", Status: "paid", Total: 10}, got)
+
+	err := table.Get(context.Background(), Key{PK: "USER#42", SK: "ORDER#2"}, &got)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestTablePutIfNotExists(t *testing.T) {
+	api := &fakeAPI{putItem: func(in *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+		assert.Equal(t, "attribute_not_exists(#pk)", *in.ConditionExpression)
+		assert.Equal(t, map[string]string{"#pk": "pk"}, in.ExpressionAttributeNames)
+		assert.Nil(t, in.ExpressionAttributeValues)
+		assert.Equal(t, orderItem("ORDER#1", "pending"), in.Item)
+		return nil, &types.ConditionalCheckFailedException{}
+	}}
+	table := newTestTable(t, api)
+
+	err := table.Put(context.Background(), order{PK: "USER#42", SK: "ORDER#1", Status: "pending", Total: 10}, IfNotExists())
+	assert.ErrorIs(t, err, ErrConditionFailed)
+}
+
+func TestTableUpdate(t *testing.T) {
+	api := &fakeAPI{updateItem: func(in *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+		assert.Equal(t, "SET #u0 = :u0, #u1 = :u1", *in.UpdateExpression)
+		assert.Equal(t, "#status = :pending", *in.ConditionExpression)
+		assert.Equal(t, map[string]string{"#status": "status", "#u0": "status", "#u1": "total"}, in.ExpressionAttributeNames)
+		assert.Equal(t, map[string]types.AttributeValue{
+			":pending": s("pending"),
+			":u0":      s("paid"),
+			":u1":      &types.AttributeValueMemberN{Value: "12"},
+		}, in.ExpressionAttributeValues)
+		return &dynamodb.UpdateItemOutput{}, nil
+	}}
+	table := newTestTable(t, api)
+
+	err := table.Update(context.Background(), Key{PK: "USER#42", SK: "ORDER#1"},
+		map[string]any{"status": "paid", "total": 12},
+		WithCondition("#status = :pending", map[string]string{"#status": "status"}, map[string]any{":pending": "pending"}))
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, table.Update(context.Background(), Key{PK: "USER#42"}, nil), errNothingToUpdate)
+}
+
+func TestTableDelete(t *testing.T) {
+	api := &fakeAPI{deleteItem: func(in *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+		assert.Equal(t, map[string]types.AttributeValue{"pk": s("USER#42")}, in.Key, "the sort key is left out when empty")
+		assert.Nil(t, in.ConditionExpression)
+		return &dynamodb.DeleteItemOutput{}, nil
+	}}
+	require.NoError(t, newTestTable(t, api).Delete(context.Background(), Key{PK: "USER#42"}))
+}
@@ -0,0 +1,176 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WriteOption customizes a Put, Update or Delete.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	condition string
+	names     map[string]string
+	values    map[string]any
+}
+
+// IfNotExists only writes when no item has the key, so a Put creates
+// without overwriting.
+func IfNotExists() WriteOption {
+	return func(o *writeOptions) {
+		o.condition = "attribute_not_exists(#pk)"
+	}
+}
+
+// IfExists only writes when an item has the key, so an Update does not
+// create the item.
+func IfExists() WriteOption {
+	return func(o *writeOptions) {
+		o.condition = "attribute_exists(#pk)"
+	}
+}
+
+// WithCondition only writes when expression holds on the current item. The
+// expression refers to the values as ":name" and to the attribute names as
+// "#name" when they are reserved words:
+//
+//	dynamodb.WithCondition("#status = :pending", map[string]string{"#status": "status"}, map[string]any{":pending": "pending"})
+func WithCondition(expression string, names map[string]string, values map[string]any) WriteOption {
+	return func(o *writeOptions) {
+		o.condition = expression
+		o.names = names
+		o.values = values
+	}
+}
+
+// condition returns the condition expression of the options with its
+// attribute names and values.
+func (t *Table) condition(opts []WriteOption) (*string, map[string]string, map[string]types.AttributeValue, error) {
+	o := &writeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.condition == "" {
+		return nil, nil, nil, nil
+	}
+
+	names := map[string]string{}
+	if strings.Contains(o.condition, "#pk") {
+		names["#pk"] = t.config.PartitionKey
+	}
+	maps.Copy(names, o.names)
+	values, err := attributevalue.MarshalMap(o.values)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(names) == 0 {
+		names = nil
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	return aws.String(o.condition), names, values, nil
+}
+
+// Get reads the item of key into out, or returns ErrNotFound.
+func (t *Table) Get(ctx context.Context, key Key, out any) error {
+	res, err := t.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName: aws.String(t.config.Table),
+		Key:       t.key(key),
+	})
+	if err != nil {
+		return err
+	}
+	if res.Item == nil {
+		return ErrNotFound
+	}
+	return attributevalue.UnmarshalMap(res.Item, out)
+}
+
+// Put writes item, which carries its key attributes, replacing the item
+// with the same key unless a condition prevents it.
+func (t *Table) Put(ctx context.Context, item any, opts ...WriteOption) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+	condition, names, values, err := t.condition(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(t.config.Table),
+		Item:                      av,
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	return conditionError(err)
+}
+
+// Update sets the attributes of set on the item of key, creating it unless
+// IfExists is given.
+func (t *Table) Update(ctx context.Context, key Key, set map[string]any, opts ...WriteOption) error {
+	if len(set) == 0 {
+		return errNothingToUpdate
+	}
+	condition, names, values, err := t.condition(opts)
+	if err != nil {
+		return err
+	}
+	if names == nil {
+		names = map[string]string{}
+	}
+	if values == nil {
+		values = map[string]types.AttributeValue{}
+	}
+
+	assignments := make([]string, 0, len(set))
+	for i, attribute := range slices.Sorted(maps.Keys(set)) {
+		name, value := "#u"+strconv.Itoa(i), ":u"+strconv.Itoa(i)
+		av, err := attributevalue.Marshal(set[attribute])
+		if err != nil {
+			return fmt.Errorf("dynamodb: attribute %s: %w", attribute, err)
+		}
+		names[name], values[value] = attribute, av
+		assignments = append(assignments, name+" = "+value)
+	}
+
+	_, err = t.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(t.config.Table),
+		Key:                       t.key(key),
+		UpdateExpression:          aws.String("SET " + strings.Join(assignments, ", ")),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	return conditionError(err)
+}
+
+// Delete deletes the item of key. Deleting a missing item is not an error
+// unless a condition says otherwise.
+func (t *Table) Delete(ctx context.Context, key Key, opts ...WriteOption) error {
+	condition, names, values, err := t.condition(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(t.config.Table),
+		Key:                       t.key(key),
+		ConditionExpression:       condition,
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	return conditionError(err)
+}
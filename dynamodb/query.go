@@ -0,0 +1,160 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+var errNoItem = errors.New("dynamodb: Scan called without a successful Next")
+
+// QueryOption customizes a Query.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	sortCondition string
+	sortValues    []string
+	index         string
+	pageSize      int32
+	descending    bool
+	partitionKey  string
+	sortKey       string
+}
+
+// SortKeyBeginsWith only returns the items whose sort key starts with
+// prefix, the usual way to read one kind of item of a partition.
+func SortKeyBeginsWith(prefix string) QueryOption {
+	return func(o *queryOptions) {
+		o.sortCondition = "begins_with(#sk, :sk0)"
+		o.sortValues = []string{prefix}
+	}
+}
+
+// SortKeyBetween only returns the items whose sort key is between from and
+// to, included.
+func SortKeyBetween(from, to string) QueryOption {
+	return func(o *queryOptions) {
+		o.sortCondition = "#sk BETWEEN :sk0 AND :sk1"
+		o.sortValues = []string{from, to}
+	}
+}
+
+// UseIndex queries a secondary index keyed by the given attributes, such as
+// "gsi1pk" and "gsi1sk" for an overloaded GSI.
+func UseIndex(name, partitionKey, sortKey string) QueryOption {
+	return func(o *queryOptions) {
+		o.index = name
+		o.partitionKey = partitionKey
+		o.sortKey = sortKey
+	}
+}
+
+// PageSize sets the number of items read per request. DynamoDB reads up to
+// 1MB per request by default.
+func PageSize(n int32) QueryOption {
+	return func(o *queryOptions) {
+		o.pageSize = n
+	}
+}
+
+// Descending returns the items in descending sort key order.
+func Descending() QueryOption {
+	return func(o *queryOptions) {
+		o.descending = true
+	}
+}
+
+// Iterator iterates over the items of a query, reading the pages as needed.
+type Iterator struct {
+	client  API
+	input   *dynamodb.QueryInput
+	items   []map[string]types.AttributeValue
+	current map[string]types.AttributeValue
+	done    bool
+	err     error
+}
+
+// Query returns an iterator over the items of the partition pk.
+func (t *Table) Query(pk string, opts ...QueryOption) *Iterator {
+	o := &queryOptions{partitionKey: t.config.PartitionKey, sortKey: t.config.SortKey}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	condition := "#pk = :pk"
+	names := map[string]string{"#pk": o.partitionKey}
+	values := map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: pk}}
+	if o.sortCondition != "" {
+		condition += " AND " + o.sortCondition
+		names["#sk"] = o.sortKey
+		for i, value := range o.sortValues {
+			values[":sk"+strconv.Itoa(i)] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String(t.config.Table),
+		KeyConditionExpression:    aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(!o.descending),
+	}
+	if o.index != "" {
+		input.IndexName = aws.String(o.index)
+	}
+	if o.pageSize > 0 {
+		input.Limit = aws.Int32(o.pageSize)
+	}
+	return &Iterator{client: t.client, input: input}
+}
+
+// Next moves to the next item, reading the next page when needed. It
+// returns false at the end of the results or on error, see Err.
+func (it *Iterator) Next(ctx context.Context) bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+		res, err := it.client.Query(ctx, it.input)
+		if err != nil {
+			it.err = err
+			continue
+		}
+		it.items = res.Items
+		it.input.ExclusiveStartKey = res.LastEvaluatedKey
+		it.done = len(res.LastEvaluatedKey) == 0
+	}
+	it.current, it.items = it.items[0], it.items[1:]
+	return true
+}
+
+// Scan unmarshals the current item into out.
+func (it *Iterator) Scan(out any) error {
+	if it.current == nil {
+		return errNoItem
+	}
+	return attributevalue.UnmarshalMap(it.current, out)
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// All reads the remaining items into out, a pointer to a slice.
+func (it *Iterator) All(ctx context.Context, out any) error {
+	var items []map[string]types.AttributeValue
+	for it.Next(ctx) {
+		items = append(items, it.current)
+	}
+	if it.err != nil {
+		return it.err
+	}
+	return attributevalue.UnmarshalListOfMaps(items, out)
+}
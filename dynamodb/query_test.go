@@ -0,0 +1,74 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableQuery(t *testing.T) {
+	pages := [][]map[string]types.AttributeValue{
+		{orderItem("ORDER#3", "paid"), orderItem("ORDER#2", "paid")},
+		{orderItem("ORDER#1", "pending")},
+	}
+	var inputs []dynamodb.QueryInput
+	api := &fakeAPI{query: func(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		inputs = append(inputs, *in)
+		page := len(inputs) - 1
+		out := &dynamodb.QueryOutput{Items: pages[page]}
+		if page == 0 {
+			out.LastEvaluatedKey = orderItem("ORDER#2", "")
+		}
+		return out, nil
+	}}
+	table := newTestTable(t, api)
+
+	it := table.Query("USER#42", SortKeyBeginsWith("ORDER#"), Descending(), PageSize(2))
+	var got []string
+	for it.Next(context.Background()) {
+		var o order
+		require.NoError(t, it.Scan(&o))
+		got = append(got, o.SK)
+	}
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"ORDER#3", "ORDER#2", "ORDER#1"}, got)
+
+	require.Len(t, inputs, 2)
+	assert.Equal(t, "#pk = :pk AND begins_with(#sk, :sk0)", *inputs[0].KeyConditionExpression)
+	assert.Equal(t, map[string]string{"#pk": "pk", "#sk": "sk"}, inputs[0].ExpressionAttributeNames)
+	assert.False(t, *inputs[0].ScanIndexForward)
+	assert.Equal(t, int32(2), *inputs[0].Limit)
+	assert.Nil(t, inputs[0].ExclusiveStartKey)
+	assert.Equal(t, orderItem("ORDER#2", ""), inputs[1].ExclusiveStartKey)
+
+	assert.ErrorIs(t, it.Scan(&order{}), errNoItem)
+}
+
+func TestTableQueryIndex(t *testing.T) {
+	api := &fakeAPI{query: func(in *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		assert.Equal(t, "gsi1", *in.IndexName)
+		assert.Equal(t, "#pk = :pk AND #sk BETWEEN :sk0 AND :sk1", *in.KeyConditionExpression)
+		assert.Equal(t, map[string]string{"#pk": "gsi1pk", "#sk": "gsi1sk"}, in.ExpressionAttributeNames)
+		return &dynamodb.QueryOutput{Items: []map[string]types.AttributeValue{orderItem("ORDER#1", "paid")}}, nil
+	}}
+	table := newTestTable(t, api)
+
+	var got []order
+	err := table.Query("STATUS#paid", UseIndex("gsi1", "gsi1pk", "gsi1sk"), SortKeyBetween("2024-01", "2024-02")).All(context.Background(), &got)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestTableQueryError(t *testing.T) {
+	api := &fakeAPI{query: func(*dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+		return nil, errors.New("throttled")
+	}}
+	it := newTestTable(t, api).Query("USER#42")
+	assert.False(t, it.Next(context.Background()))
+	assert.EqualError(t, it.Err(), "throttled")
+}
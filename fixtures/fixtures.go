@@ -0,0 +1,349 @@
+// Package fixtures loads seed data into SQL tables, and optionally
+// OpenSearch indices, for integration tests.
+//
+// Every file of the fixtures directory holds the rows of the table named
+// after it, in YAML or JSON:
+//
+//	# fixtures/orders.yml
+//	depends_on: [users]
+//	rows:
+//	  - id: 1
+//	    user_id: 1
+//	    total: 42.5
+//
+// A file may also be a bare list of rows. Tables are filled after the ones
+// they depend on and emptied in the reverse order, so foreign keys hold:
+//
+//	//go:embed testdata/fixtures
+//	var seeds embed.FS
+//
+//	loader, err := fixtures.New(db.DB, seeds, fixtures.Config{Dir: "testdata/fixtures"})
+//	err = loader.Load(ctx) // in each test, or its setup
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+	"github.com/jmoiron/sqlx"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	errMissingID = errors.New("fixtures: document has no _id")
+
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+	extensions = []string{".yml", ".yaml", ".json"}
+)
+
+// Indexer indexes the documents of the index fixtures. OpenSearchClient
+// implements it.
+type Indexer interface {
+	BulkIndex(ctx context.Context, indexName string, models []platigo.IndexModel, opts ...platigo.RequestOption) (*platigo.BulkStats, error)
+}
+
+// Config configures a Loader.
+type Config struct {
+	// Dir is the directory of the table fixtures in the file system, its
+	// root by default.
+	Dir string
+
+	// IndexDir is the directory of the index fixtures, one file per index
+	// holding a list of documents with their "_id". They are loaded with
+	// Search when both are set. Indices are not emptied, documents with
+	// the same ID are overwritten.
+	IndexDir string
+	Search   Indexer
+
+	// Logger receives the loader logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Loader loads fixtures into a database.
+type Loader struct {
+	db      *sqlx.DB
+	search  Indexer
+	log     logger.Logger
+	tables  []*table
+	indices []*index
+}
+
+type table struct {
+	Name      string           `yaml:"-"`
+	DependsOn []string         `yaml:"depends_on"`
+	Rows      []map[string]any `yaml:"rows"`
+}
+
+type index struct {
+	name string
+	docs []platigo.IndexModel
+}
+
+// document is an index fixture, indexed without its _id.
+type document map[string]any
+
+func (d document) GetID() string {
+	return fmt.Sprint(d["_id"])
+}
+
+func (d document) MarshalJSON() ([]byte, error) {
+	source := make(map[string]any, len(d))
+	for key, value := range d {
+		if key != "_id" {
+			source[key] = value
+		}
+	}
+	return json.Marshal(source)
+}
+
+// New reads the fixtures of fsys and orders the tables by dependency.
+func New(db *sqlx.DB, fsys fs.FS, config Config) (*Loader, error) {
+	l := &Loader{
+		db:     db,
+		search: config.Search,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel),
+	}
+
+	dir := config.Dir
+	if dir == "" {
+		dir = "."
+	}
+	tables := map[string]*table{}
+	err := readFixtures(fsys, dir, func(name string, data []byte) error {
+		t, err := parseTable(name, data)
+		if err != nil {
+			return err
+		}
+		tables[name] = t
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if l.tables, err = sortTables(tables); err != nil {
+		return nil, err
+	}
+
+	if config.IndexDir != "" && config.Search != nil {
+		err = readFixtures(fsys, config.IndexDir, func(name string, data []byte) error {
+			var docs []document
+			if err := yaml.Unmarshal(data, &docs); err != nil {
+				return fmt.Errorf("fixtures: index %s: %w", name, err)
+			}
+			idx := &index{name: name}
+			for _, doc := range docs {
+				if doc["_id"] == nil {
+					return fmt.Errorf("fixtures: index %s: %w", name, errMissingID)
+				}
+				idx.docs = append(idx.docs, doc)
+			}
+			l.indices = append(l.indices, idx)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// Tables returns the tables in loading order.
+func (l *Loader) Tables() []string {
+	names := make([]string, len(l.tables))
+	for i, t := range l.tables {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Load empties the tables and inserts their rows in a single transaction,
+// then indexes the documents, refreshing the indices so searches see them.
+func (l *Loader) Load(ctx context.Context) error {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := l.truncate(ctx, tx); err != nil {
+		return err
+	}
+	for _, t := range l.tables {
+		for _, row := range t.Rows {
+			if err := insert(ctx, tx, t.Name, row); err != nil {
+				return fmt.Errorf("fixtures: table %s: %w", t.Name, err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, idx := range l.indices {
+		stats, err := l.search.BulkIndex(ctx, idx.name, idx.docs, platigo.WithRefresh(platigo.RefreshTrue))
+		if err != nil {
+			return fmt.Errorf("fixtures: index %s: %w", idx.name, err)
+		}
+		if stats.NumFailed > 0 {
+			return fmt.Errorf("fixtures: index %s: %d documents failed", idx.name, stats.NumFailed)
+		}
+	}
+
+	l.log.With(logger.Fields{
+		"tables":  len(l.tables),
+		"indices": len(l.indices),
+	}).Debug("Fixtures loaded")
+	return nil
+}
+
+// Truncate deletes the rows of every fixture table, dependents first, so a
+// test can clean up after itself.
+func (l *Loader) Truncate(ctx context.Context) error {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := l.truncate(ctx, tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// truncate uses DELETE rather than TRUNCATE, which MySQL refuses on tables
+// referenced by foreign keys and commits implicitly. Sequences are kept.
+func (l *Loader) truncate(ctx context.Context, tx *sqlx.Tx) error {
+	for _, t := range slices.Backward(l.tables) {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+t.Name); err != nil {
+			return fmt.Errorf("fixtures: table %s: %w", t.Name, err)
+		}
+	}
+	return nil
+}
+
+func insert(ctx context.Context, tx *sqlx.Tx, name string, row map[string]any) error {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if !identifier.MatchString(column) {
+			return fmt.Errorf("invalid column %q", column)
+		}
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+
+	args := make([]any, len(columns))
+	for i, column := range columns {
+		args[i] = row[column]
+		// Nested objects and lists are JSON columns.
+		switch args[i].(type) {
+		case map[string]any, []any:
+			b, err := json.Marshal(args[i])
+			if err != nil {
+				return err
+			}
+			args[i] = string(b)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", name, strings.Join(columns, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+	_, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
+	return err
+}
+
+// readFixtures calls fn with the name and content of every fixture file of
+// dir, in name order.
+func readFixtures(fsys fs.FS, dir string, fn func(name string, data []byte) error) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || !slices.Contains(extensions, ext) {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := fn(strings.TrimSuffix(entry.Name(), ext), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTable parses a table fixture, a list of rows or a mapping with
+// depends_on and rows. JSON being YAML, both go through the YAML decoder.
+func parseTable(name string, data []byte) (*table, error) {
+	if !identifier.MatchString(name) {
+		return nil, fmt.Errorf("fixtures: invalid table name %q", name)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("fixtures: table %s: %w", name, err)
+	}
+	t := &table{Name: name}
+	var err error
+	if len(node.Content) > 0 && node.Content[0].Kind == yaml.SequenceNode {
+		err = node.Decode(&t.Rows)
+	} else {
+		err = node.Decode(t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fixtures: table %s: %w", name, err)
+	}
+	return t, nil
+}
+
+// sortTables orders the tables after their dependencies.
+func sortTables(tables map[string]*table) ([]*table, error) {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var sorted []*table
+	state := map[string]int{} // 1 while visiting, 2 once sorted
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("fixtures: dependency cycle %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		t, ok := tables[name]
+		if !ok {
+			return fmt.Errorf("fixtures: table %s depends on %s which has no fixture", path[len(path)-1], name)
+		}
+		state[name] = 1
+		for _, dep := range t.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		sorted = append(sorted, t)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
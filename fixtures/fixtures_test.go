@@ -0,0 +1,123 @@
+package fixtures
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bagastri07/platigo"
+	"github.com/goccy/go-json"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeIndexer struct {
+	index string
+	docs  []platigo.IndexModel
+}
+
+func (f *fakeIndexer) BulkIndex(_ context.Context, indexName string, models []platigo.IndexModel, _ ...platigo.RequestOption) (*platigo.BulkStats, error) {
+	f.index, f.docs = indexName, models
+	return &platigo.BulkStats{NumIndexed: uint64(len(models))}, nil
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/orders.yml": {Data: []byte(`
+depends_on: [users]
+rows:
+  - id: 10
+    user_id: 1
+    items: [{sku: A1, qty: 2}]
+`)},
+		"fixtures/users.json":        {Data: []byte(`[{"id": 1, "email": "jane@example.com"}]`)},
+		"fixtures/README.md":         {Data: []byte("ignored")},
+		"search/products.yml":        {Data: []byte("- _id: A1\n  name: Lamp\n")},
+		"fixtures/audit/ignored.yml": {Data: []byte("- id: 1\n")},
+	}
+
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM orders").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM users").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (email, id) VALUES ($1, $2)")).
+		WithArgs("jane@example.com", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO orders (id, items, user_id) VALUES ($1, $2, $3)")).
+		WithArgs(10, `[{"qty":2,"sku":"A1"}]`, 1).WillReturnResult(sqlmock.NewResult(10, 1))
+	mock.ExpectCommit()
+
+	search := &fakeIndexer{}
+	loader, err := New(sqlx.NewDb(conn, "pgx"), fsys, Config{Dir: "fixtures", IndexDir: "search", Search: search})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"users", "orders"}, loader.Tables())
+
+	require.NoError(t, loader.Load(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, "products", search.index)
+	require.Len(t, search.docs, 1)
+	assert.Equal(t, "A1", search.docs[0].GetID())
+	source, err := json.Marshal(search.docs[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Lamp"}`, string(source))
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			fsys: fstest.MapFS{
+				"a.yml": {Data: []byte("depends_on: [b]\nrows: []\n")},
+				"b.yml": {Data: []byte("depends_on: [a]\nrows: []\n")},
+			},
+			wantErr: "fixtures: dependency cycle a -> b -> a",
+		},
+		{
+			name:    "missing dependency",
+			fsys:    fstest.MapFS{"orders.yml": {Data: []byte("depends_on: [users]\nrows: []\n")}},
+			wantErr: "fixtures: table orders depends on users which has no fixture",
+		},
+		{
+			name:    "invalid table name",
+			fsys:    fstest.MapFS{"users;drop.yml": {Data: []byte("[]")}},
+			wantErr: `fixtures: invalid table name "users;drop"`,
+		},
+		{
+			name:    "invalid yaml",
+			fsys:    fstest.MapFS{"users.yml": {Data: []byte("rows: [")}},
+			wantErr: "fixtures: table users:",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, tt.fsys, Config{})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM users").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	loader, err := New(sqlx.NewDb(conn, "pgx"), fstest.MapFS{"users.yml": {Data: []byte("[]")}}, Config{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, loader.Truncate(context.Background()), assert.AnError)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
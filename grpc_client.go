@@ -0,0 +1,309 @@
+package platigo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/goccy/go-json"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // client-side health checking
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultGRPCMaxRetries       = 2
+	defaultGRPCRetryBackoff     = 100 * time.Millisecond
+	defaultGRPCMaxRetryBackoff  = 5 * time.Second
+	defaultGRPCKeepaliveTime    = 30 * time.Second
+	defaultGRPCKeepaliveTimeout = 10 * time.Second
+)
+
+var errGRPCTargetRequired = errors.New("grpc: target is required")
+
+type GRPCConfig struct {
+	// Target is the address of the server, such as "orders:50051" or
+	// "dns:///orders.internal:50051" to balance over every resolved address.
+	Target string
+
+	// TLSConfig configures the TLS connections, with the system roots by
+	// default. Insecure uses plaintext instead, for service meshes
+	// encrypting the traffic themselves.
+	TLSConfig *tls.Config
+	Insecure  bool
+
+	// Timeout bounds the unary calls whose context has no deadline. Not
+	// set by default.
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries of a call failing with one of
+	// RetryOnCodes, Unavailable by default. It defaults to 2, -1 disables
+	// retries and gRPC caps it at 4. The delay between attempts grows from
+	// RetryBackoff up to MaxRetryBackoff, 100ms and 5s by default.
+	MaxRetries      int
+	RetryOnCodes    []codes.Code
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+
+	// KeepaliveTime is the interval of the keepalive pings on idle
+	// connections, 30 seconds by default, and KeepaliveTimeout how long an
+	// unanswered ping waits before closing the connection, 10 seconds by
+	// default.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+
+	// HealthCheck enables client-side health checking: every backend is
+	// watched through the standard health service for HealthCheckService,
+	// the whole server by default, and skipped while not serving. Calls
+	// are balanced round robin over the healthy backends.
+	HealthCheck        bool
+	HealthCheckService string
+
+	// TokenSource authenticates every call with its bearer token, such as
+	// the one of NewOAuth2TokenSource.
+	TokenSource oauth2.TokenSource
+
+	// TracerProvider creates a span for every call and propagates it to
+	// the server. Defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// Metrics records call and latency metrics when set.
+	Metrics *GRPCMetrics
+
+	// UnaryInterceptors and StreamInterceptors run after the standard
+	// ones, and DialOptions are applied last.
+	UnaryInterceptors  []grpc.UnaryClientInterceptor
+	StreamInterceptors []grpc.StreamClientInterceptor
+	DialOptions        []grpc.DialOption
+}
+
+// NewGRPCClientConn creates a client connection wired with the retry,
+// timeout, keepalive, tracing, metrics and authentication settings of
+// config. Like grpc.NewClient, it connects lazily on the first call.
+func NewGRPCClientConn(config GRPCConfig) (*grpc.ClientConn, error) {
+	if config.Target == "" {
+		return nil, errGRPCTargetRequired
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultGRPCMaxRetries
+	}
+	if config.RetryOnCodes == nil {
+		config.RetryOnCodes = []codes.Code{codes.Unavailable}
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaultGRPCRetryBackoff
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = defaultGRPCMaxRetryBackoff
+	}
+	if config.KeepaliveTime <= 0 {
+		config.KeepaliveTime = defaultGRPCKeepaliveTime
+	}
+	if config.KeepaliveTimeout <= 0 {
+		config.KeepaliveTimeout = defaultGRPCKeepaliveTimeout
+	}
+
+	serviceConfig, err := grpcServiceConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	transportCreds := insecure.NewCredentials()
+	if !config.Insecure {
+		transportCreds = credentials.NewTLS(config.TLSConfig)
+	}
+
+	interceptor := &grpcClientInterceptor{
+		tracer:  newTracer(config.TracerProvider),
+		metrics: config.Metrics,
+		timeout: config.Timeout,
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(transportCreds),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                config.KeepaliveTime,
+			Timeout:             config.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}),
+		grpc.WithChainUnaryInterceptor(append([]grpc.UnaryClientInterceptor{interceptor.unary}, config.UnaryInterceptors...)...),
+		grpc.WithChainStreamInterceptor(append([]grpc.StreamClientInterceptor{interceptor.stream}, config.StreamInterceptors...)...),
+	}
+	if config.TokenSource != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(&grpcTokenCredentials{
+			source:     config.TokenSource,
+			requireTLS: !config.Insecure,
+		}))
+	}
+	opts = append(opts, config.DialOptions...)
+
+	return grpc.NewClient(config.Target, opts...)
+}
+
+// grpcServiceConfig returns the default service config of a connection,
+// which carries the retry policy and the health checking settings.
+func grpcServiceConfig(config GRPCConfig) (string, error) {
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name        []struct{}  `json:"name"`
+		RetryPolicy retryPolicy `json:"retryPolicy"`
+	}
+
+	serviceConfig := map[string]any{}
+	if config.MaxRetries > 0 {
+		retryCodes := make([]string, len(config.RetryOnCodes))
+		for i, code := range config.RetryOnCodes {
+			retryCodes[i] = grpcCodeName(code)
+		}
+		serviceConfig["methodConfig"] = []methodConfig{{
+			// An empty name applies the policy to every method.
+			Name: []struct{}{{}},
+			RetryPolicy: retryPolicy{
+				MaxAttempts:          config.MaxRetries + 1,
+				InitialBackoff:       fmt.Sprintf("%gs", config.RetryBackoff.Seconds()),
+				MaxBackoff:           fmt.Sprintf("%gs", config.MaxRetryBackoff.Seconds()),
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: retryCodes,
+			},
+		}}
+	}
+	if config.HealthCheck {
+		serviceConfig["healthCheckConfig"] = map[string]string{"serviceName": config.HealthCheckService}
+		serviceConfig["loadBalancingConfig"] = []map[string]any{{"round_robin": map[string]any{}}}
+	}
+
+	data, err := json.Marshal(serviceConfig)
+	return string(data), err
+}
+
+// grpcCodeName returns the upper snake case name of code used by service
+// configs, such as UNAVAILABLE.
+func grpcCodeName(code codes.Code) string {
+	name := code.String()
+	out := make([]byte, 0, len(name)+4)
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c >= 'A' && c <= 'Z' && i > 0 && name[i-1] >= 'a' && name[i-1] <= 'z' {
+			out = append(out, '_')
+		}
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// grpcClientInterceptor traces, measures and bounds the client calls.
+type grpcClientInterceptor struct {
+	tracer  trace.Tracer
+	metrics *GRPCMetrics
+	timeout time.Duration
+}
+
+func (i *grpcClientInterceptor) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Deadline(); !ok && i.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.timeout)
+		defer cancel()
+	}
+
+	ctx, span := i.startSpan(ctx, method)
+	started := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	i.metrics.observe("client", method, status.Code(err), time.Since(started))
+	endGRPCSpan(span, err)
+	return err
+}
+
+func (i *grpcClientInterceptor) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := i.startSpan(ctx, method)
+	started := time.Now()
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		i.metrics.observe("client", method, status.Code(err), time.Since(started))
+		endGRPCSpan(span, err)
+		return nil, err
+	}
+	return &tracedClientStream{ClientStream: stream, end: func(err error) {
+		i.metrics.observe("client", method, status.Code(err), time.Since(started))
+		endGRPCSpan(span, err)
+	}}, nil
+}
+
+// startSpan starts the span of a call and propagates it in the outgoing
+// metadata, along with the request ID of ctx.
+func (i *grpcClientInterceptor) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	name, attrs := grpcSpanName(method)
+	ctx, span := i.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	if id := RequestIDFromContext(ctx); id != "" && len(md.Get(grpcRequestIDKey)) == 0 {
+		md.Set(grpcRequestIDKey, id)
+	}
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// tracedClientStream ends the span of a stream once it is over, when a
+// receive fails or reaches the end of the stream.
+type tracedClientStream struct {
+	grpc.ClientStream
+	end   func(err error)
+	ended bool
+}
+
+func (s *tracedClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil && !s.ended {
+		s.ended = true
+		if errors.Is(err, io.EOF) {
+			s.end(nil)
+		} else {
+			s.end(err)
+		}
+	}
+	return err
+}
+
+// grpcTokenCredentials sends the bearer token of an oauth2.TokenSource.
+// Unlike the credentials of the grpc oauth package it can be used over
+// plaintext connections of a service mesh.
+type grpcTokenCredentials struct {
+	source     oauth2.TokenSource
+	requireTLS bool
+}
+
+func (c *grpcTokenCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.source.Token()
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "grpc: fetch token: %v", err)
+	}
+	return map[string]string{"authorization": token.Type() + " " + token.AccessToken}, nil
+}
+
+func (c *grpcTokenCredentials) RequireTransportSecurity() bool {
+	return c.requireTLS
+}
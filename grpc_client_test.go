@@ -0,0 +1,155 @@
+package platigo
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// flakyHealthServer fails the first calls with Unavailable and records the
+// metadata and deadline of the last one.
+type flakyHealthServer struct {
+	healthpb.UnimplementedHealthServer
+	failures    int32
+	calls       atomic.Int32
+	md          metadata.MD
+	hasDeadline bool
+}
+
+func (s *flakyHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	_, s.hasDeadline = ctx.Deadline()
+	if s.calls.Add(1) <= s.failures {
+		return nil, status.Error(codes.Unavailable, "warming up")
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func startTestGRPCServer(t *testing.T, srv healthpb.HealthServer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, srv)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestNewGRPCClientConn(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       GRPCConfig
+		failures     int32
+		wantCode     codes.Code
+		wantCalls    int32
+		wantDeadline bool
+	}{
+		{
+			name:      "success",
+			wantCode:  codes.OK,
+			wantCalls: 1,
+		},
+		{
+			name:      "unavailable is retried",
+			failures:  2,
+			wantCode:  codes.OK,
+			wantCalls: 3,
+		},
+		{
+			name:      "gives up after max retries",
+			failures:  5,
+			wantCode:  codes.Unavailable,
+			wantCalls: 3,
+		},
+		{
+			name:      "retries disabled",
+			config:    GRPCConfig{MaxRetries: -1},
+			failures:  1,
+			wantCode:  codes.Unavailable,
+			wantCalls: 1,
+		},
+		{
+			name:      "health checking",
+			config:    GRPCConfig{HealthCheck: true},
+			wantCode:  codes.OK,
+			wantCalls: 1,
+		},
+		{
+			name:         "default timeout",
+			config:       GRPCConfig{Timeout: time.Second},
+			wantCode:     codes.OK,
+			wantCalls:    1,
+			wantDeadline: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &flakyHealthServer{failures: tt.failures}
+			recorder := tracetest.NewSpanRecorder()
+			metrics := NewGRPCMetrics("test")
+
+			tt.config.Target = startTestGRPCServer(t, srv)
+			tt.config.Insecure = true
+			tt.config.RetryBackoff = time.Millisecond
+			tt.config.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tt.config.Metrics = metrics
+			tt.config.TokenSource = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token-1"})
+			conn, err := NewGRPCClientConn(tt.config)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCalls, srv.calls.Load())
+			assert.Equal(t, []string{"Bearer token-1"}, srv.md.Get("authorization"))
+			assert.Equal(t, tt.wantDeadline, srv.hasDeadline)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "grpc.health.v1.Health/Check", spans[0].Name())
+			assert.Contains(t, spans[0].Attributes(), attribute.String("rpc.method", "Check"))
+			assert.Contains(t, spans[0].Attributes(), attribute.Int("rpc.grpc.status_code", int(tt.wantCode)))
+
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.calls.WithLabelValues("client", "/grpc.health.v1.Health/Check", tt.wantCode.String())))
+		})
+	}
+}
+
+func TestGRPCServiceConfig(t *testing.T) {
+	got, err := grpcServiceConfig(GRPCConfig{
+		MaxRetries:      2,
+		RetryOnCodes:    []codes.Code{codes.Unavailable, codes.ResourceExhausted},
+		RetryBackoff:    100 * time.Millisecond,
+		MaxRetryBackoff: 5 * time.Second,
+		HealthCheck:     true,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"methodConfig": [{"name": [{}], "retryPolicy": {
+			"maxAttempts": 3, "initialBackoff": "0.1s", "maxBackoff": "5s", "backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE", "RESOURCE_EXHAUSTED"]
+		}}],
+		"healthCheckConfig": {"serviceName": ""},
+		"loadBalancingConfig": [{"round_robin": {}}]
+	}`, got)
+
+	_, err = NewGRPCClientConn(GRPCConfig{})
+	assert.ErrorIs(t, err, errGRPCTargetRequired)
+}
@@ -0,0 +1,66 @@
+package platigo
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	attrRPCSystem         = attribute.Key("rpc.system")
+	attrRPCService        = attribute.Key("rpc.service")
+	attrRPCMethod         = attribute.Key("rpc.method")
+	attrRPCGRPCStatusCode = attribute.Key("rpc.grpc.status_code")
+	grpcRPCSystem         = attrRPCSystem.String("grpc")
+)
+
+// grpcSpanName returns the span name and attributes of a full method name
+// such as /orders.v1.Orders/Get.
+func grpcSpanName(fullMethod string) (string, []attribute.KeyValue) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	attrs := []attribute.KeyValue{grpcRPCSystem}
+	if service, method, ok := strings.Cut(name, "/"); ok {
+		attrs = append(attrs, attrRPCService.String(service), attrRPCMethod.String(method))
+	}
+	return name, attrs
+}
+
+// endGRPCSpan records the status of a call on span and ends it.
+func endGRPCSpan(span trace.Span, err error) {
+	defer span.End()
+
+	s, _ := status.FromError(err)
+	span.SetAttributes(attrRPCGRPCStatusCode.Int(int(s.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, s.Message())
+	}
+}
+
+// metadataCarrier adapts gRPC metadata to the propagation.TextMapCarrier
+// interface.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
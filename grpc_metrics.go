@@ -0,0 +1,62 @@
+package platigo
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+)
+
+// GRPCMetrics holds the Prometheus metrics of gRPC calls, labeled by side
+// (client or server), full method name and status code. It implements
+// prometheus.Collector:
+//
+//	metrics := platigo.NewGRPCMetrics("myservice")
+//	prometheus.MustRegister(metrics)
+//	conn, err := platigo.NewGRPCClientConn(platigo.GRPCConfig{Metrics: metrics})
+type GRPCMetrics struct {
+	calls   *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewGRPCMetrics creates the gRPC metrics under the given namespace.
+func NewGRPCMetrics(namespace string) *GRPCMetrics {
+	return &GRPCMetrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "calls_total",
+			Help:      "Total number of gRPC calls.",
+		}, []string{"side", "method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of gRPC calls.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"side", "method"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *GRPCMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.calls.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *GRPCMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.calls.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// observe records one call. It is a no-op on a nil receiver so the
+// connections work without metrics configured.
+func (m *GRPCMetrics) observe(side, method string, code codes.Code, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.calls.WithLabelValues(side, method, code.String()).Inc()
+	m.latency.WithLabelValues(side, method).Observe(elapsed.Seconds())
+}
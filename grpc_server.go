@@ -0,0 +1,301 @@
+package platigo
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultGRPCServerKeepaliveTime = 2 * time.Minute
+	defaultGRPCMinPingInterval     = 10 * time.Second
+)
+
+// GRPCAuthFunc authenticates a call to fullMethod, typically from the
+// authorization metadata of ctx. It returns the context passed to the
+// handler, carrying the identity of the caller, or an error such as
+// codes.Unauthenticated which rejects the call.
+type GRPCAuthFunc func(ctx context.Context, fullMethod string) (context.Context, error)
+
+// GRPCValidator is implemented by the request messages able to validate
+// themselves, like the ones generated by protoc-gen-validate.
+type GRPCValidator interface {
+	Validate() error
+}
+
+type GRPCServerConfig struct {
+	// TLSConfig serves TLS when set, plaintext otherwise.
+	TLSConfig *tls.Config
+
+	// Authenticate runs before every call but the ones of PublicMethods,
+	// given as full method names such as /orders.v1.Orders/List, and of
+	// the health service.
+	Authenticate  GRPCAuthFunc
+	PublicMethods []string
+
+	// EnableReflection registers the server reflection service, which lets
+	// tools such as grpcurl list and call the services. Its calls are
+	// authenticated like the others.
+	EnableReflection bool
+
+	// KeepaliveTime is the interval of the keepalive pings on idle
+	// connections, 2 minutes by default. Clients may ping every
+	// MinPingInterval, 10 seconds by default, and are closed if they ping
+	// more often.
+	KeepaliveTime   time.Duration
+	MinPingInterval time.Duration
+
+	// TracerProvider creates a span for every call, continuing the trace
+	// of the client. Defaults to the global provider.
+	TracerProvider trace.TracerProvider
+	// Metrics records call and latency metrics when set.
+	Metrics *GRPCMetrics
+
+	// UnaryInterceptors and StreamInterceptors run after the standard
+	// ones, right before the handlers, and ServerOptions are applied last.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+	ServerOptions      []grpc.ServerOption
+
+	// Logger receives the server logs. Defaults to a no-op logger.
+	Logger logger.Logger
+	// LogLevel is the minimum level passed to Logger. Successful calls are
+	// logged at DebugLevel and failed ones at WarnLevel, or ErrorLevel for
+	// the Internal, Unknown and DataLoss codes, panics included.
+	LogLevel logger.Level
+}
+
+// GRPCServer is a grpc.Server with the health service, and optionally the
+// reflection service, registered. Services are registered on it as usual before Serve:
+//
+//	server := platigo.NewGRPCServer(platigo.GRPCServerConfig{Logger: log})
+//	ordersv1.RegisterOrdersServer(server, orders)
+//	shutdown.Register("grpc server", server.Shutdown)
+//	err := server.Serve(lis)
+type GRPCServer struct {
+	*grpc.Server
+	// Health reports the status of the server and its services. The
+	// server is SERVING until Shutdown.
+	Health *health.Server
+}
+
+// NewGRPCServer creates a GRPCServer recovering panics, tracing, measuring
+// and logging every call, then authenticating and validating the requests.
+// The request ID of the x-request-id metadata, or a new one, is set on the
+// context of the handlers and sent back in the response header.
+func NewGRPCServer(config GRPCServerConfig) *GRPCServer {
+	if config.KeepaliveTime <= 0 {
+		config.KeepaliveTime = defaultGRPCServerKeepaliveTime
+	}
+	if config.MinPingInterval <= 0 {
+		config.MinPingInterval = defaultGRPCMinPingInterval
+	}
+
+	interceptor := &grpcServerInterceptor{
+		config: config,
+		tracer: newTracer(config.TracerProvider),
+		log:    logger.WithLevel(config.Logger, config.LogLevel),
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{Time: config.KeepaliveTime}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             config.MinPingInterval,
+			PermitWithoutStream: true,
+		}),
+		grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{interceptor.unary}, config.UnaryInterceptors...)...),
+		grpc.ChainStreamInterceptor(append([]grpc.StreamServerInterceptor{interceptor.stream}, config.StreamInterceptors...)...),
+	}
+	if config.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(config.TLSConfig)))
+	}
+	opts = append(opts, config.ServerOptions...)
+
+	server := &GRPCServer{
+		Server: grpc.NewServer(opts...),
+		Health: health.NewServer(),
+	}
+	healthpb.RegisterHealthServer(server.Server, server.Health)
+	if config.EnableReflection {
+		reflection.Register(server.Server)
+	}
+	return server
+}
+
+// Shutdown reports the server as NOT_SERVING, so that load balancers stop
+// sending calls, then stops it gracefully. The calls still running when ctx
+// is done are canceled.
+func (s *GRPCServer) Shutdown(ctx context.Context) error {
+	s.Health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.Stop()
+		return ctx.Err()
+	}
+}
+
+// grpcServerInterceptor holds the standard interceptors of GRPCServer.
+type grpcServerInterceptor struct {
+	config GRPCServerConfig
+	tracer trace.Tracer
+	log    logger.Logger
+}
+
+func (i *grpcServerInterceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+	ctx, span := i.startSpan(ctx, info.FullMethod)
+	ctx = grpcRequestID(ctx)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(grpcRequestIDKey, RequestIDFromContext(ctx)))
+	started, callCtx := time.Now(), ctx
+	defer func() {
+		i.observe(callCtx, info.FullMethod, err, time.Since(started))
+		endGRPCSpan(span, err)
+	}()
+	defer i.recover(ctx, info.FullMethod, &err)
+
+	if ctx, err = i.authenticate(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	if err := validateGRPCRequest(req); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (i *grpcServerInterceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := i.startSpan(ss.Context(), info.FullMethod)
+	ctx = grpcRequestID(ctx)
+	_ = ss.SetHeader(metadata.Pairs(grpcRequestIDKey, RequestIDFromContext(ctx)))
+	started, callCtx := time.Now(), ctx
+	defer func() {
+		i.observe(callCtx, info.FullMethod, err, time.Since(started))
+		endGRPCSpan(span, err)
+	}()
+	defer i.recover(ctx, info.FullMethod, &err)
+
+	if ctx, err = i.authenticate(ctx, info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, &validatingServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// startSpan continues the trace propagated in the incoming metadata.
+func (i *grpcServerInterceptor) startSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	name, attrs := grpcSpanName(method)
+	return i.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// recover turns a panic of the handler into an Internal error, so that it
+// fails the call instead of crashing the server.
+func (i *grpcServerInterceptor) recover(ctx context.Context, method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	logger.WithContext(ctx, i.log).With(logger.Fields{
+		"method": method,
+		"panic":  fmt.Sprint(r),
+		"stack":  string(debug.Stack()),
+	}).Error("Recovered panic in gRPC handler")
+	*err = status.Error(codes.Internal, "internal error")
+}
+
+func (i *grpcServerInterceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if i.config.Authenticate == nil || isHealthMethod(method) || slices.Contains(i.config.PublicMethods, method) {
+		return ctx, nil
+	}
+	return i.config.Authenticate(ctx, method)
+}
+
+func (i *grpcServerInterceptor) observe(ctx context.Context, method string, err error, elapsed time.Duration) {
+	code := status.Code(err)
+	i.config.Metrics.observe("server", method, code, elapsed)
+
+	log := logger.WithContext(ctx, i.log).With(logger.Fields{
+		"method":   method,
+		"code":     code.String(),
+		"duration": elapsed.String(),
+	})
+	switch code {
+	case codes.OK:
+		log.Debug("gRPC call handled")
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		log.With(logger.Fields{"error": err.Error()}).Error("gRPC call failed")
+	default:
+		log.With(logger.Fields{"error": err.Error()}).Warn("gRPC call failed")
+	}
+}
+
+// grpcRequestID returns ctx carrying the request ID of the incoming
+// metadata, or a new one.
+func grpcRequestID(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, grpcRequestIDKey); len(values) > 0 {
+		id = values[0]
+	}
+	return ContextWithRequestID(ctx, EnsureRequestID(id))
+}
+
+// isHealthMethod reports whether method belongs to the health service,
+// which is never authenticated so that load balancers can probe it.
+func isHealthMethod(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.")
+}
+
+// validateGRPCRequest validates the requests implementing GRPCValidator.
+func validateGRPCRequest(req any) error {
+	validator, ok := req.(GRPCValidator)
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
+
+// validatingServerStream validates the received messages and carries the
+// context returned by the authentication.
+type validatingServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *validatingServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *validatingServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateGRPCRequest(m)
+}
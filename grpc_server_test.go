@@ -0,0 +1,250 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+// echoRequest is a proto message validating that Service is set.
+type echoRequest struct {
+	*healthpb.HealthCheckRequest
+}
+
+func (r echoRequest) Validate() error {
+	if r.Service == "" {
+		return errors.New("service is required")
+	}
+	return nil
+}
+
+type callerKey struct{}
+
+// newEchoServiceDesc describes a service with a single method panicking
+// when asked to, and recording the caller set by the authentication.
+func newEchoServiceDesc(caller *string) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: "test.v1.Echo",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Echo",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				req := echoRequest{&healthpb.HealthCheckRequest{}}
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				handler := func(ctx context.Context, req any) (any, error) {
+					if req.(echoRequest).Service == "panic" {
+						panic("boom")
+					}
+					*caller, _ = ctx.Value(callerKey{}).(string)
+					return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+				}
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.v1.Echo/Echo"}, handler)
+			},
+		}},
+	}
+}
+
+func TestGRPCServer(t *testing.T) {
+	authenticate := func(ctx context.Context, _ string) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("authorization"); len(values) == 1 && values[0] == "Bearer token-1" {
+			return context.WithValue(ctx, callerKey{}, "orders"), nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	tests := []struct {
+		name       string
+		config     GRPCServerConfig
+		service    string
+		token      string
+		wantCode   codes.Code
+		wantCaller string
+		wantLogs   []string
+	}{
+		{
+			name:       "authenticated call",
+			config:     GRPCServerConfig{Authenticate: authenticate},
+			service:    "orders",
+			token:      "Bearer token-1",
+			wantCode:   codes.OK,
+			wantCaller: "orders",
+		},
+		{
+			name:     "unauthenticated call",
+			config:   GRPCServerConfig{Authenticate: authenticate},
+			service:  "orders",
+			wantCode: codes.Unauthenticated,
+			wantLogs: []string{"warn:gRPC call failed"},
+		},
+		{
+			name:     "public method",
+			config:   GRPCServerConfig{Authenticate: authenticate, PublicMethods: []string{"/test.v1.Echo/Echo"}},
+			service:  "orders",
+			wantCode: codes.OK,
+		},
+		{
+			name:     "invalid request",
+			service:  "",
+			wantCode: codes.InvalidArgument,
+			wantLogs: []string{"warn:gRPC call failed"},
+		},
+		{
+			name:     "panic is recovered",
+			service:  "panic",
+			wantCode: codes.Internal,
+			wantLogs: []string{"error:Recovered panic in gRPC handler", "error:gRPC call failed"},
+		},
+		{
+			name:     "debug logging",
+			config:   GRPCServerConfig{LogLevel: logger.DebugLevel},
+			service:  "orders",
+			wantCode: codes.OK,
+			wantLogs: []string{"debug:gRPC call handled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newRecordLogger()
+			recorder := tracetest.NewSpanRecorder()
+			metrics := NewGRPCMetrics("test")
+			tt.config.Logger = log
+			tt.config.TracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tt.config.Metrics = metrics
+
+			var caller string
+			server := NewGRPCServer(tt.config)
+			server.RegisterService(newEchoServiceDesc(&caller), nil)
+			lis, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+			go func() { _ = server.Serve(lis) }()
+			defer server.Stop()
+
+			conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", tt.token)
+			}
+			err = conn.Invoke(ctx, "/test.v1.Echo/Echo", &healthpb.HealthCheckRequest{Service: tt.service}, &healthpb.HealthCheckResponse{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCaller, caller)
+			assert.Equal(t, tt.wantLogs, log.Entries())
+			assert.Equal(t, 1.0, testutil.ToFloat64(metrics.calls.WithLabelValues("server", "/test.v1.Echo/Echo", tt.wantCode.String())))
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			assert.Equal(t, "test.v1.Echo/Echo", spans[0].Name())
+		})
+	}
+}
+
+func TestGRPCServerHealthAndShutdown(t *testing.T) {
+	server := NewGRPCServer(GRPCServerConfig{
+		Authenticate: func(context.Context, string) (context.Context, error) {
+			return nil, status.Error(codes.Unauthenticated, "no token")
+		},
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "the health service is not authenticated")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	_, ok := server.GetServiceInfo()["grpc.reflection.v1.ServerReflection"]
+	assert.False(t, ok, "reflection is opt-in")
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Error(t, err)
+}
+
+func TestGRPCServerReflectionAuthenticated(t *testing.T) {
+	server := NewGRPCServer(GRPCServerConfig{
+		EnableReflection: true,
+		Authenticate: func(context.Context, string) (context.Context, error) {
+			return nil, status.Error(codes.Unauthenticated, "no token")
+		},
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRPCRequestID(t *testing.T) {
+	var caller string
+	server := NewGRPCServer(GRPCServerConfig{})
+	server.RegisterService(newEchoServiceDesc(&caller), nil)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := NewGRPCClientConn(GRPCConfig{Target: lis.Addr().String(), Insecure: true})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		wantID string
+	}{
+		{name: "propagated", ctx: ContextWithRequestID(context.Background(), "req-1"), wantID: "req-1"},
+		{name: "generated", ctx: context.Background()},
+		{name: "invalid is replaced", ctx: metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "bad id")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header metadata.MD
+			err := conn.Invoke(tt.ctx, "/test.v1.Echo/Echo", &healthpb.HealthCheckRequest{Service: "orders"}, &healthpb.HealthCheckResponse{}, grpc.Header(&header))
+			require.NoError(t, err)
+
+			values := header.Get("x-request-id")
+			require.Len(t, values, 1)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, values[0])
+			} else {
+				assert.Len(t, values[0], 36)
+			}
+		})
+	}
+}
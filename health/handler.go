@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const defaultCheckTimeout = 2 * time.Second
+
+var errShuttingDown = errors.New("health: shutting down")
+
+type HandlerConfig struct {
+	// Timeout bounds every check, 2 seconds by default. The checks not
+	// returning in time are down.
+	Timeout time.Duration
+	// Registry adds the checks registered by the clients: its liveness
+	// checks to /livez and the others to /readyz, with the timeouts and
+	// caching of the registry.
+	Registry *Registry
+}
+
+// Handler serves the health endpoints of a service, reporting the status,
+// error, latency and details of every check in a JSON body:
+//
+//   - /livez runs the liveness checks, which fail when the process cannot
+//     recover by itself and must be restarted, such as a deadlock.
+//   - /readyz runs the readiness checks, which fail while the service
+//     cannot serve, such as when its database is unreachable, so that it
+//     is removed from the load balancers but not restarted.
+//   - /healthz runs every check, for operators.
+//
+// An endpoint answers 503 when one of its checks is down, 200 otherwise:
+//
+//	checks := health.NewHandler(health.HandlerConfig{})
+//	checks.AddReadinessCheck("db", db.PingCheck())
+//	checks.AddReadinessCheck("redis", platigo.RedisPingCheck(redisClient))
+//	shutdown.Register("health", checks.Shutdown)
+//	mux.Handle("/", checks)
+type Handler struct {
+	timeout  time.Duration
+	registry *Registry
+
+	mu           sync.RWMutex
+	liveness     []namedCheck
+	readiness    []namedCheck
+	shuttingDown atomic.Bool
+}
+
+type namedCheck struct {
+	name string
+	run  func(ctx context.Context) CheckReport
+}
+
+// Report is the body of the health endpoints.
+type Report struct {
+	Status Status                 `json:"status"`
+	Checks map[string]CheckReport `json:"checks,omitempty"`
+}
+
+// CheckReport is the result of a check and its latency.
+type CheckReport struct {
+	Result
+	LatencyMs int64 `json:"latency_ms"`
+}
+
+// NewHandler creates a Handler without checks.
+func NewHandler(config HandlerConfig) *Handler {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultCheckTimeout
+	}
+	return &Handler{timeout: config.Timeout, registry: config.Registry}
+}
+
+// AddLivenessCheck adds a check to /livez and /healthz.
+func (h *Handler) AddLivenessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, h.named(name, check))
+}
+
+// AddReadinessCheck adds a check to /readyz and /healthz.
+func (h *Handler) AddReadinessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, h.named(name, check))
+}
+
+func (h *Handler) named(name string, check Check) namedCheck {
+	return namedCheck{name: name, run: func(ctx context.Context) CheckReport {
+		return runCheck(ctx, check, h.timeout)
+	}}
+}
+
+// Shutdown makes /readyz fail, so that the load balancers stop sending
+// requests while the service drains. It is meant to be the first
+// shutdown hook run, hence registered last.
+func (h *Handler) Shutdown(context.Context) error {
+	h.shuttingDown.Store(true)
+	return nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	var checks []namedCheck
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/livez"):
+		checks = append(slices.Clip(h.liveness), h.registry.checks(true, false)...)
+	case strings.HasSuffix(r.URL.Path, "/readyz"):
+		checks = append(slices.Clip(h.readiness), h.registry.checks(false, true)...)
+		if h.shuttingDown.Load() {
+			checks = append([]namedCheck{{name: "shutdown", run: shutdownCheck}}, checks...)
+		}
+	case strings.HasSuffix(r.URL.Path, "/healthz"):
+		checks = slices.Concat(h.liveness, h.readiness, h.registry.checks(true, true))
+	default:
+		h.mu.RUnlock()
+		http.NotFound(w, r)
+		return
+	}
+	h.mu.RUnlock()
+
+	report := runAll(r.Context(), checks)
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+// runAll runs checks in parallel and aggregates their status: down when one
+// of them is down, degraded when one is degraded, up otherwise.
+func runAll(ctx context.Context, checks []namedCheck) Report {
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckReport, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[c.name] = result
+			report.Status = worst(report.Status, result.Status)
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+// runCheck runs check, giving up once timeout elapsed.
+func runCheck(ctx context.Context, check Check, timeout time.Duration) CheckReport {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	done := make(chan Result, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var result Result
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = Down(ctx.Err(), nil)
+	}
+	return CheckReport{Result: result, LatencyMs: time.Since(started).Milliseconds()}
+}
+
+func shutdownCheck(context.Context) CheckReport {
+	return CheckReport{Result: Down(errShuttingDown, nil)}
+}
+
+// worst returns the most severe of a and b.
+func worst(a, b Status) Status {
+	switch {
+	case a == StatusDown || b == StatusDown:
+		return StatusDown
+	case a == StatusDegraded || b == StatusDegraded:
+		return StatusDegraded
+	}
+	return StatusUp
+}
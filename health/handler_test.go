@@ -0,0 +1,134 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	up := func(context.Context) Result { return Up(map[string]any{"pool": 3}) }
+	degraded := func(context.Context) Result { return Degraded("replica lagging", nil) }
+	down := func(context.Context) Result { return Down(errors.New("connection refused"), nil) }
+	slow := func(ctx context.Context) Result {
+		<-ctx.Done()
+		time.Sleep(time.Second)
+		return Up(nil)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		liveness   map[string]Check
+		readiness  map[string]Check
+		wantStatus int
+		wantReport Status
+		wantChecks map[string]Status
+	}{
+		{
+			name:       "live without checks",
+			path:       "/livez",
+			readiness:  map[string]Check{"db": down},
+			wantStatus: http.StatusOK,
+			wantReport: StatusUp,
+			wantChecks: map[string]Status{},
+		},
+		{
+			name:       "ready",
+			path:       "/readyz",
+			readiness:  map[string]Check{"db": up, "replica": degraded},
+			wantStatus: http.StatusOK,
+			wantReport: StatusDegraded,
+			wantChecks: map[string]Status{"db": StatusUp, "replica": StatusDegraded},
+		},
+		{
+			name:       "not ready",
+			path:       "/readyz",
+			readiness:  map[string]Check{"db": up, "redis": down},
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: StatusDown,
+			wantChecks: map[string]Status{"db": StatusUp, "redis": StatusDown},
+		},
+		{
+			name:       "check timeout",
+			path:       "/readyz",
+			readiness:  map[string]Check{"search": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			wantReport: StatusDown,
+			wantChecks: map[string]Status{"search": StatusDown},
+		},
+		{
+			name:       "every check",
+			path:       "/healthz",
+			liveness:   map[string]Check{"loop": up},
+			readiness:  map[string]Check{"db": up},
+			wantStatus: http.StatusOK,
+			wantReport: StatusUp,
+			wantChecks: map[string]Status{"loop": StatusUp, "db": StatusUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHandler(HandlerConfig{Timeout: 20 * time.Millisecond})
+			for name, check := range tt.liveness {
+				h.AddLivenessCheck(name, check)
+			}
+			for name, check := range tt.readiness {
+				h.AddReadinessCheck(name, check)
+			}
+
+			started := time.Now()
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			assert.Less(t, time.Since(started), 500*time.Millisecond)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			var report Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			assert.Equal(t, tt.wantReport, report.Status)
+			checks := map[string]Status{}
+			for name, check := range report.Checks {
+				checks[name] = check.Status
+			}
+			assert.Equal(t, tt.wantChecks, checks)
+		})
+	}
+}
+
+func TestHandlerReportsDetails(t *testing.T) {
+	h := NewHandler(HandlerConfig{})
+	h.AddReadinessCheck("db", func(context.Context) Result {
+		return Down(errors.New("connection refused"), map[string]any{"host": "db:5432"})
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.JSONEq(t, `{"status":"down","checks":{"db":{"status":"down","error":"connection refused","details":{"host":"db:5432"},"latency_ms":0}}}`, rec.Body.String())
+}
+
+func TestHandlerShutdown(t *testing.T) {
+	h := NewHandler(HandlerConfig{})
+	h.AddReadinessCheck("db", func(context.Context) Result { return Up(nil) })
+	require.NoError(t, h.Shutdown(context.Background()))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,44 @@
+// Package health defines the checks reporting whether a service and its
+// dependencies are able to serve, with details for operators.
+package health
+
+import "context"
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusUp is a working dependency.
+	StatusUp Status = "up"
+	// StatusDegraded is a dependency still serving, slower or with less
+	// redundancy, e.g. a lagging replica or a saturated pool.
+	StatusDegraded Status = "degraded"
+	// StatusDown is a dependency not serving.
+	StatusDown Status = "down"
+)
+
+// Result is the outcome of a check and its details, such as latencies or
+// pool usage.
+type Result struct {
+	Status  Status         `json:"status"`
+	Error   string         `json:"error,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// Check checks a dependency. It returns within the deadline of ctx.
+type Check func(ctx context.Context) Result
+
+// Up returns a StatusUp result with details.
+func Up(details map[string]any) Result {
+	return Result{Status: StatusUp, Details: details}
+}
+
+// Degraded returns a StatusDegraded result for reason.
+func Degraded(reason string, details map[string]any) Result {
+	return Result{Status: StatusDegraded, Error: reason, Details: details}
+}
+
+// Down returns a StatusDown result for err.
+func Down(err error, details map[string]any) Result {
+	return Result{Status: StatusDown, Error: err.Error(), Details: details}
+}
@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const defaultCacheTTL = time.Second
+
+type RegistryConfig struct {
+	// Timeout bounds the checks registered without their own timeout, 2
+	// seconds by default. The checks not returning in time are down.
+	Timeout time.Duration
+	// CacheTTL is how long the result of a check is reused, 1 second by
+	// default, so that the probes of several load balancers do not pile up
+	// on the dependencies. Negative disables the cache.
+	CacheTTL time.Duration
+}
+
+// Registry holds the checks of the dependencies of a service, registered
+// by their clients on construction, e.g. when OSConfig.Health or
+// RedisConfig.Health is set. The checks run in parallel, each within its
+// timeout, and their results are cached for CacheTTL:
+//
+//	registry := health.NewRegistry(health.RegistryConfig{})
+//	client, err := platigo.NewRedisClient(&platigo.RedisConfig{Addresses: addrs, Health: registry})
+//	mux.Handle("/", health.NewHandler(health.HandlerConfig{Registry: registry}))
+type Registry struct {
+	config RegistryConfig
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	entries []*registryEntry
+}
+
+// Option configures a registered check.
+type Option func(*registryEntry)
+
+// WithTimeout bounds the check with timeout instead of the one of the
+// registry.
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *registryEntry) {
+		e.timeout = timeout
+	}
+}
+
+// Liveness registers a liveness check, run on /livez, instead of a
+// readiness one.
+func Liveness() Option {
+	return func(e *registryEntry) {
+		e.liveness = true
+	}
+}
+
+type registryEntry struct {
+	registry *Registry
+	name     string
+	check    Check
+	timeout  time.Duration
+	liveness bool
+
+	mu        sync.Mutex
+	last      CheckReport
+	checkedAt time.Time
+}
+
+// NewRegistry creates a Registry without checks.
+func NewRegistry(config RegistryConfig) *Registry {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultCheckTimeout
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = defaultCacheTTL
+	}
+	return &Registry{config: config, now: time.Now}
+}
+
+// Register adds a readiness check named name, replacing the check already
+// registered under that name.
+func (r *Registry) Register(name string, check Check, opts ...Option) {
+	entry := &registryEntry{registry: r, name: name, check: check, timeout: r.config.Timeout}
+	for _, opt := range opts {
+		opt(entry)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.name == name {
+			r.entries[i] = entry
+			return
+		}
+	}
+	r.entries = append(r.entries, entry)
+}
+
+// Report runs every check and aggregates their status.
+func (r *Registry) Report(ctx context.Context) Report {
+	return runAll(ctx, r.checks(true, true))
+}
+
+// checks returns the liveness checks, the readiness ones or both. A nil
+// registry has none.
+func (r *Registry) checks(liveness, readiness bool) []namedCheck {
+	if r == nil {
+		return nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var checks []namedCheck
+	for _, e := range r.entries {
+		if e.liveness && liveness || !e.liveness && readiness {
+			checks = append(checks, namedCheck{name: e.name, run: e.run})
+		}
+	}
+	return checks
+}
+
+// run returns the cached result of the check, or runs it. Concurrent runs
+// wait for the same result.
+func (e *registryEntry) run(ctx context.Context) CheckReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ttl := e.registry.config.CacheTTL
+	if !e.checkedAt.IsZero() && e.registry.now().Sub(e.checkedAt) < ttl {
+		return e.last
+	}
+	report := runCheck(ctx, e.check, e.timeout)
+	// A result cut short by the caller going away says nothing of the
+	// dependency.
+	if ctx.Err() == nil {
+		e.last, e.checkedAt = report, e.registry.now()
+	}
+	return report
+}
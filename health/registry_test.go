@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryReport(t *testing.T) {
+	up := func(context.Context) Result { return Up(nil) }
+	down := func(context.Context) Result { return Down(errors.New("connection refused"), nil) }
+	slow := func(ctx context.Context) Result {
+		<-ctx.Done()
+		return Up(nil)
+	}
+
+	tests := []struct {
+		name       string
+		register   func(r *Registry)
+		wantStatus Status
+		wantChecks map[string]Status
+	}{
+		{
+			name:       "empty",
+			register:   func(*Registry) {},
+			wantStatus: StatusUp,
+			wantChecks: map[string]Status{},
+		},
+		{
+			name: "down",
+			register: func(r *Registry) {
+				r.Register("redis", up)
+				r.Register("db", down, Liveness())
+			},
+			wantStatus: StatusDown,
+			wantChecks: map[string]Status{"redis": StatusUp, "db": StatusDown},
+		},
+		{
+			name: "check timeout",
+			register: func(r *Registry) {
+				r.Register("search", slow, WithTimeout(10*time.Millisecond))
+			},
+			wantStatus: StatusDown,
+			wantChecks: map[string]Status{"search": StatusDown},
+		},
+		{
+			name: "replaced",
+			register: func(r *Registry) {
+				r.Register("db", down)
+				r.Register("db", up)
+			},
+			wantStatus: StatusUp,
+			wantChecks: map[string]Status{"db": StatusUp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(RegistryConfig{Timeout: time.Second})
+			tt.register(r)
+
+			report := r.Report(context.Background())
+			assert.Equal(t, tt.wantStatus, report.Status)
+			checks := map[string]Status{}
+			for name, check := range report.Checks {
+				checks[name] = check.Status
+			}
+			assert.Equal(t, tt.wantChecks, checks)
+		})
+	}
+}
+
+func TestRegistryCache(t *testing.T) {
+	var calls atomic.Int32
+	now := time.Now()
+	r := NewRegistry(RegistryConfig{CacheTTL: time.Minute})
+	r.now = func() time.Time { return now }
+	r.Register("db", func(context.Context) Result {
+		calls.Add(1)
+		return Up(nil)
+	})
+
+	r.Report(context.Background())
+	r.Report(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+
+	now = now.Add(time.Minute)
+	r.Report(context.Background())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRegistryCacheSkipsCanceled(t *testing.T) {
+	r := NewRegistry(RegistryConfig{CacheTTL: time.Minute})
+	r.Register("db", func(ctx context.Context) Result {
+		if err := ctx.Err(); err != nil {
+			return Down(err, nil)
+		}
+		return Up(nil)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, StatusDown, r.Report(ctx).Status)
+	assert.Equal(t, StatusUp, r.Report(context.Background()).Status)
+}
+
+func TestHandlerRegistry(t *testing.T) {
+	r := NewRegistry(RegistryConfig{})
+	r.Register("loop", func(context.Context) Result { return Up(nil) }, Liveness())
+	r.Register("db", func(context.Context) Result { return Down(errors.New("connection refused"), nil) })
+
+	h := NewHandler(HandlerConfig{Registry: r})
+	h.AddReadinessCheck("redis", func(context.Context) Result { return Up(nil) })
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantChecks []string
+	}{
+		{path: "/livez", wantStatus: http.StatusOK, wantChecks: []string{"loop"}},
+		{path: "/readyz", wantStatus: http.StatusServiceUnavailable, wantChecks: []string{"db", "redis"}},
+		{path: "/healthz", wantStatus: http.StatusServiceUnavailable, wantChecks: []string{"db", "loop", "redis"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			var report Report
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			var names []string
+			for name := range report.Checks {
+				names = append(names, name)
+			}
+			assert.ElementsMatch(t, tt.wantChecks, names)
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package platigo
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/ratelimit"
+)
+
+const (
+	defaultHTTPMaxRetries    = 2
+	defaultHTTPMaxRetryAfter = 30 * time.Second
+	// minRateLimitWait bounds the polling of a limiter denying a request
+	// without a delay.
+	minRateLimitWait = 10 * time.Millisecond
+)
+
+var defaultHTTPRetryOnStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type HTTPConfig struct {
+	// Transport sends the requests. Defaults to NewHTTPTransport, tuned by
+	// the pool, timeout, TLS and proxy settings below which are ignored
+	// when Transport is set.
+	Transport http.RoundTripper
+
+	// MaxIdleConns and MaxIdleConnsPerHost bound the idle connections kept
+	// for reuse, 512 and 64 by default instead of the 2 per host of the
+	// standard transport. MaxConnsPerHost bounds all the connections to a
+	// host, without limit by default.
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	// IdleConnTimeout closes the connections idle for longer, 90 seconds by
+	// default.
+	IdleConnTimeout time.Duration
+
+	// DialTimeout and TLSHandshakeTimeout bound establishing a connection,
+	// 5 seconds each by default. KeepAlive is the TCP keep-alive period,
+	// 30 seconds by default, and -1 disables it. ResponseHeaderTimeout bounds the wait for the
+	// response headers once the request is written, not set by default.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	KeepAlive             time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// TLSConfig configures the TLS connections. HTTP/2 is negotiated over
+	// TLS unless DisableHTTP2 is set.
+	TLSConfig    *tls.Config
+	DisableHTTP2 bool
+
+	// Proxy returns the proxy of a request, or nil for none. Defaults to
+	// http.ProxyFromEnvironment, which reads HTTP_PROXY, HTTPS_PROXY and
+	// NO_PROXY.
+	Proxy func(req *http.Request) (*url.URL, error)
+
+	// Timeout bounds a whole call, retries and reading the body included.
+	// AttemptTimeout bounds every attempt, so a hung attempt is retried
+	// instead of using up the Timeout. Neither is set by default.
+	Timeout        time.Duration
+	AttemptTimeout time.Duration
+
+	// MaxRetries is the number of retries after the first attempt, 2 by
+	// default, and -1 disables them. Requests are retried on network
+	// errors and RetryOnStatus, 429, 502, 503 and 504 by default.
+	MaxRetries    int
+	RetryOnStatus []int
+	// RetryNonIdempotent also retries POST and PATCH requests, which are
+	// otherwise only retried with an Idempotency-Key header.
+	RetryNonIdempotent bool
+
+	// Backoff returns the delay before the given attempt, starting at 2.
+	// Defaults to an exponential backoff from 100ms up to 5s with jitter.
+	// A longer Retry-After response header is honored up to MaxRetryAfter,
+	// 30 seconds by default; the response is returned as is beyond it.
+	Backoff       func(attempt int) time.Duration
+	MaxRetryAfter time.Duration
+
+	// RateLimiter holds every attempt until it is allowed, so partner quotas
+	// are not exceeded. Use ratelimit.NewLocalTokenBucket for a quota per
+	// replica or ratelimit.NewTokenBucket to share it through Redis.
+	// RateLimitKey derives the limiter key, the request host by default;
+	// requests with an empty key are not limited.
+	RateLimiter  ratelimit.Limiter
+	RateLimitKey func(req *http.Request) string
+
+	// HedgeDelay enables hedging of GET and HEAD requests: when an attempt
+	// has no response after HedgeDelay, or fails, up to MaxHedges more are
+	// sent, 1 by default. The first response below 500 wins and the other
+	// attempts are canceled. Hedging trades extra load for lower tail
+	// latency, so it suits dependencies with a slow p99.
+	HedgeDelay time.Duration
+	MaxHedges  int
+
+	// SlowThreshold logs a warning for every request whose response takes
+	// longer, retries included, whatever the sampling of its trace. Not
+	// set by default.
+	SlowThreshold time.Duration
+
+	// Logging logs every attempt with NewHTTPLogTransport when set. Its
+	// Logger defaults to Logger.
+	Logging *HTTPLogConfig
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// NewHTTPClient creates an http.Client retrying the failed requests with
+// backoff. Request bodies are replayed with Request.GetBody, which
+// http.NewRequest sets for in-memory bodies; requests with other bodies
+// are not retried. The request ID of the request context is sent in the
+// X-Request-Id header, unless the request sets its own.
+func NewHTTPClient(config HTTPConfig) *http.Client {
+	if config.Transport == nil {
+		config.Transport = NewHTTPTransport(config)
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultHTTPMaxRetries
+	}
+	if config.RetryOnStatus == nil {
+		config.RetryOnStatus = defaultHTTPRetryOnStatus
+	}
+	if config.Backoff == nil {
+		config.Backoff = ExponentialBackoff(100*time.Millisecond, 5*time.Second)
+	}
+	if config.MaxRetryAfter <= 0 {
+		config.MaxRetryAfter = defaultHTTPMaxRetryAfter
+	}
+	if config.RateLimitKey == nil {
+		config.RateLimitKey = func(req *http.Request) string { return req.URL.Host }
+	}
+
+	log := logger.WithLevel(config.Logger, logger.InfoLevel)
+	next := config.Transport
+	if config.Logging != nil {
+		logging := *config.Logging
+		if logging.Logger == nil {
+			logging.Logger = config.Logger
+		}
+		next = NewHTTPLogTransport(next, logging)
+	}
+	if config.RateLimiter != nil {
+		next = &rateLimitTransport{
+			next:    next,
+			limiter: config.RateLimiter,
+			key:     config.RateLimitKey,
+			log:     log,
+			sleep:   sleepContext,
+		}
+	}
+
+	if config.HedgeDelay > 0 {
+		if config.MaxHedges <= 0 {
+			config.MaxHedges = 1
+		}
+		next = &hedgeTransport{
+			next:      next,
+			delay:     config.HedgeDelay,
+			maxHedges: config.MaxHedges,
+			log:       log,
+		}
+	}
+
+	return &http.Client{
+		Timeout: config.Timeout,
+		Transport: &retryTransport{
+			next:   next,
+			config: config,
+			log:    log,
+			slow:   newSlowLog(log, config.SlowThreshold),
+			now:    time.Now,
+			sleep:  sleepContext,
+		},
+	}
+}
+
+type retryTransport struct {
+	next   http.RoundTripper
+	config HTTPConfig
+	log    logger.Logger
+	slow   slowLog
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if id := RequestIDFromContext(ctx); id != "" && req.Header.Get(HeaderRequestID) == "" {
+		req = req.Clone(ctx)
+		req.Header.Set(HeaderRequestID, id)
+	}
+
+	started := t.now()
+	res, err := t.roundTrip(req)
+
+	fields := logger.Fields{"method": req.Method, "url": req.URL.Scheme + "://" + req.URL.Host + req.URL.Path}
+	if err != nil {
+		fields["error"] = err.Error()
+	} else {
+		fields["status"] = res.StatusCode
+	}
+	t.slow.observe(ctx, t.now().Sub(started), "Slow HTTP request", fields)
+	return res, err
+}
+
+// roundTrip sends req, and retries it as configured.
+func (t *retryTransport) roundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := t.retryable(req)
+
+	for attempt := 1; ; attempt++ {
+		res, err := t.attempt(req, attempt)
+
+		if !retryable || attempt > t.config.MaxRetries || ctx.Err() != nil {
+			return res, err
+		}
+		delay := t.config.Backoff(attempt + 1)
+		if err == nil {
+			if !slices.Contains(t.config.RetryOnStatus, res.StatusCode) {
+				return res, nil
+			}
+			if retryAfter, ok := t.retryAfter(res); ok {
+				if retryAfter > t.config.MaxRetryAfter {
+					return res, nil
+				}
+				delay = max(delay, retryAfter)
+			}
+			// Drain the body so the connection is reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			_ = res.Body.Close()
+		}
+
+		fields := logger.Fields{
+			"method":  req.Method,
+			"host":    req.URL.Host,
+			"attempt": attempt,
+			"delay":   delay.String(),
+		}
+		if err != nil {
+			fields["error"] = err.Error()
+		} else {
+			fields["status"] = res.StatusCode
+		}
+		logger.WithContext(ctx, t.log).With(fields).Warn("Retrying HTTP request")
+
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attempt sends a copy of req, with a fresh body for the retries and the
+// attempt timeout. The timeout is released when the body is closed.
+func (t *retryTransport) attempt(req *http.Request, attempt int) (*http.Response, error) {
+	if attempt > 1 {
+		req = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+	if t.config.AttemptTimeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.config.AttemptTimeout)
+	res, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// retryable reports whether req may be sent again.
+func (t *retryTransport) retryable(req *http.Request) bool {
+	if t.config.MaxRetries < 0 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return t.config.RetryNonIdempotent || req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter parses the Retry-After header, as seconds or as a date.
+func (t *retryTransport) retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(t.now()), 0), true
+	}
+	return 0, false
+}
+
+// cancelBody cancels the context of an attempt once its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// rateLimitTransport waits for the limiter to allow a request before sending
+// it. Limiter errors let the request through rather than failing it. The
+// waits last at least minRateLimitWait, however short the RetryAfter.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	limiter ratelimit.Limiter
+	key     func(req *http.Request) string
+	log     logger.Logger
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := t.key(req)
+	if key == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	log := logger.WithContext(ctx, t.log)
+	for {
+		result, err := t.limiter.Allow(ctx, key)
+		if err != nil {
+			log.With(logger.Fields{
+				"key":   key,
+				"error": err.Error(),
+			}).Warn("Rate limiter unavailable")
+			break
+		}
+		if result.Allowed {
+			break
+		}
+		delay := max(result.RetryAfter, minRateLimitWait)
+		log.With(logger.Fields{
+			"key":   key,
+			"delay": delay.String(),
+		}).Debug("Waiting for HTTP rate limit")
+		if err := t.sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,277 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHTTPClient(t *testing.T, config HTTPConfig) (*http.Client, *[]time.Duration) {
+	t.Helper()
+	client := NewHTTPClient(config)
+	var delays []time.Duration
+	transport := client.Transport.(*retryTransport)
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	transport.config.Backoff = func(attempt int) time.Duration { return time.Duration(attempt) * time.Millisecond }
+	return client, &delays
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		header       http.Header
+		config       HTTPConfig
+		statuses     []int
+		retryAfter   string
+		wantStatus   int
+		wantRequests int
+		wantDelays   []time.Duration
+	}{
+		{
+			name:         "retried until success",
+			method:       http.MethodGet,
+			statuses:     []int{503, 502, 200},
+			wantStatus:   200,
+			wantRequests: 3,
+			wantDelays:   []time.Duration{2 * time.Millisecond, 3 * time.Millisecond},
+		},
+		{
+			name:         "gives up after max retries",
+			method:       http.MethodGet,
+			statuses:     []int{503, 503, 503, 503},
+			wantStatus:   503,
+			wantRequests: 3,
+			wantDelays:   []time.Duration{2 * time.Millisecond, 3 * time.Millisecond},
+		},
+		{
+			name:         "client errors are not retried",
+			method:       http.MethodGet,
+			statuses:     []int{404},
+			wantStatus:   404,
+			wantRequests: 1,
+		},
+		{
+			name:         "post is not retried",
+			method:       http.MethodPost,
+			statuses:     []int{503, 200},
+			wantStatus:   503,
+			wantRequests: 1,
+		},
+		{
+			name:         "post with idempotency key is retried",
+			method:       http.MethodPost,
+			header:       http.Header{"Idempotency-Key": {"order-1"}},
+			statuses:     []int{503, 200},
+			wantStatus:   200,
+			wantRequests: 2,
+			wantDelays:   []time.Duration{2 * time.Millisecond},
+		},
+		{
+			name:         "post retried when configured",
+			method:       http.MethodPost,
+			config:       HTTPConfig{RetryNonIdempotent: true},
+			statuses:     []int{503, 200},
+			wantStatus:   200,
+			wantRequests: 2,
+			wantDelays:   []time.Duration{2 * time.Millisecond},
+		},
+		{
+			name:         "retry after honored",
+			method:       http.MethodGet,
+			statuses:     []int{429, 200},
+			retryAfter:   "3",
+			wantStatus:   200,
+			wantRequests: 2,
+			wantDelays:   []time.Duration{3 * time.Second},
+		},
+		{
+			name:         "retry after beyond the limit",
+			method:       http.MethodGet,
+			statuses:     []int{429, 200},
+			retryAfter:   "120",
+			wantStatus:   429,
+			wantRequests: 1,
+		},
+		{
+			name:         "retries disabled",
+			method:       http.MethodGet,
+			config:       HTTPConfig{MaxRetries: -1},
+			statuses:     []int{503, 200},
+			wantStatus:   503,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(requests.Add(1))
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost {
+					assert.Equal(t, `{"id":1}`, string(body), "the body is replayed")
+				}
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			client, delays := newTestHTTPClient(t, tt.config)
+			req, err := http.NewRequest(tt.method, srv.URL, strings.NewReader(`{"id":1}`))
+			require.NoError(t, err)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantRequests, int(requests.Load()))
+			assert.Equal(t, tt.wantDelays, *delays)
+		})
+	}
+}
+
+func TestHTTPClientAttemptTimeout(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, _ := newTestHTTPClient(t, HTTPConfig{AttemptTimeout: 50 * time.Millisecond})
+	res, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	// The body is still readable after the attempt returned.
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestHTTPClientRetryAfterDate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	transport := &retryTransport{now: func() time.Time { return now }}
+
+	res := &http.Response{Header: http.Header{"Retry-After": {now.Add(5 * time.Second).Format(http.TimeFormat)}}}
+	delay, ok := transport.retryAfter(res)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	_, ok = transport.retryAfter(&http.Response{Header: http.Header{"Retry-After": {"soon"}}})
+	assert.False(t, ok)
+}
+
+func TestHTTPClientRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	limiter := ratelimit.NewLocalTokenBucket(ratelimit.Limit{Rate: 1, Period: time.Second, Burst: 2})
+	client := NewHTTPClient(HTTPConfig{RateLimiter: limiter, MaxRetries: -1})
+	var waits []time.Duration
+	client.Transport.(*retryTransport).next.(*rateLimitTransport).sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		if len(waits) > 1 {
+			return context.Canceled
+		}
+		return nil
+	}
+
+	for range 2 {
+		res, err := client.Get(server.URL)
+		require.NoError(t, err)
+		_ = res.Body.Close()
+	}
+	assert.Empty(t, waits)
+
+	_, err := client.Get(server.URL)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, waits, 2)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+type denyingLimiter struct {
+	retryAfter time.Duration
+}
+
+func (l denyingLimiter) Allow(context.Context, string) (ratelimit.Result, error) {
+	return ratelimit.Result{RetryAfter: l.retryAfter}, nil
+}
+
+func TestHTTPClientRateLimitMinimumWait(t *testing.T) {
+	for _, retryAfter := range []time.Duration{0, -time.Second, time.Millisecond, time.Second} {
+		t.Run(retryAfter.String(), func(t *testing.T) {
+			client := NewHTTPClient(HTTPConfig{RateLimiter: denyingLimiter{retryAfter: retryAfter}, MaxRetries: -1})
+			var waits []time.Duration
+			client.Transport.(*retryTransport).next.(*rateLimitTransport).sleep = func(_ context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				if len(waits) == 3 {
+					return context.Canceled
+				}
+				return nil
+			}
+
+			_, err := client.Get("http://example.com")
+			require.ErrorIs(t, err, context.Canceled)
+			want := max(retryAfter, minRateLimitWait)
+			assert.Equal(t, []time.Duration{want, want, want}, waits)
+		})
+	}
+}
+
+func TestHTTPClientRateLimitKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	limiter := ratelimit.NewLocalTokenBucket(ratelimit.Limit{Rate: 1, Period: time.Hour})
+	client := NewHTTPClient(HTTPConfig{
+		MaxRetries:  -1,
+		RateLimiter: limiter,
+		RateLimitKey: func(req *http.Request) string {
+			if req.URL.Path == "/health" {
+				return ""
+			}
+			return req.URL.Host + req.URL.Path
+		},
+	})
+	client.Transport.(*retryTransport).next.(*rateLimitTransport).sleep = func(context.Context, time.Duration) error {
+		return context.DeadlineExceeded
+	}
+
+	for _, path := range []string{"/orders", "/users", "/health", "/health"} {
+		res, err := client.Get(server.URL + path)
+		require.NoError(t, err, path)
+		_ = res.Body.Close()
+	}
+	_, err := client.Get(server.URL + "/orders")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,116 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+// hedgeTransport sends extra attempts of slow or failed idempotent requests
+// and returns the first good response.
+type hedgeTransport struct {
+	next      http.RoundTripper
+	delay     time.Duration
+	maxHedges int
+	log       logger.Logger
+}
+
+type hedgeResult struct {
+	res    *http.Response
+	err    error
+	index  int
+	cancel context.CancelFunc
+}
+
+func (r hedgeResult) ok() bool {
+	return r.err == nil && r.res.StatusCode < http.StatusInternalServerError
+}
+
+// discard releases an attempt that lost.
+func (r hedgeResult) discard() {
+	r.cancel()
+	if r.res != nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(r.res.Body, 64<<10))
+		_ = r.res.Body.Close()
+	}
+}
+
+func (t *hedgeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead ||
+		req.Body != nil && req.Body != http.NoBody {
+		return t.next.RoundTrip(req)
+	}
+
+	results := make(chan hedgeResult, t.maxHedges+1)
+	var cancels []context.CancelFunc
+	launch := func() {
+		ctx, cancel := context.WithCancel(req.Context())
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		attempt := req.Clone(ctx)
+		go func() {
+			res, err := t.next.RoundTrip(attempt)
+			results <- hedgeResult{res: res, err: err, index: index, cancel: cancel}
+		}()
+	}
+	hedge := func(reason string) {
+		logger.WithContext(req.Context(), t.log).With(logger.Fields{
+			"method":  req.Method,
+			"host":    req.URL.Host,
+			"attempt": len(cancels) + 1,
+			"reason":  reason,
+		}).Debug("Hedging HTTP request")
+		launch()
+	}
+
+	launch()
+	timer := time.NewTimer(t.delay)
+	defer timer.Stop()
+
+	pending := 1
+	var last hedgeResult
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) <= t.maxHedges {
+				hedge("slow")
+				pending++
+				timer.Reset(t.delay)
+			}
+		case result := <-results:
+			pending--
+			if result.ok() {
+				for i, cancel := range cancels {
+					if i != result.index {
+						cancel()
+					}
+				}
+				go func(pending int) {
+					for range pending {
+						(<-results).discard()
+					}
+				}(pending)
+				result.res.Body = &cancelBody{ReadCloser: result.res.Body, cancel: result.cancel}
+				return result.res, nil
+			}
+			if last.cancel != nil {
+				last.discard()
+			}
+			last = result
+			if len(cancels) <= t.maxHedges {
+				hedge("failed")
+				pending++
+			}
+		}
+	}
+
+	if last.err != nil {
+		last.cancel()
+		return nil, last.err
+	}
+	last.res.Body = &cancelBody{ReadCloser: last.res.Body, cancel: last.cancel}
+	return last.res, nil
+}
@@ -0,0 +1,113 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPClientHedging(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		config       HTTPConfig
+		handler      func(n int32, w http.ResponseWriter, r *http.Request)
+		wantBody     string
+		wantStatus   int
+		wantRequests int32
+	}{
+		{
+			name:   "slow attempt is hedged",
+			method: http.MethodGet,
+			config: HTTPConfig{HedgeDelay: 10 * time.Millisecond},
+			handler: func(n int32, w http.ResponseWriter, r *http.Request) {
+				if n == 1 {
+					<-r.Context().Done()
+					return
+				}
+				_, _ = io.WriteString(w, "hedge")
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     "hedge",
+			wantRequests: 2,
+		},
+		{
+			name:   "fast attempt is not hedged",
+			method: http.MethodGet,
+			config: HTTPConfig{HedgeDelay: time.Second},
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				_, _ = io.WriteString(w, "first")
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     "first",
+			wantRequests: 1,
+		},
+		{
+			name:   "failed attempt is hedged right away",
+			method: http.MethodGet,
+			config: HTTPConfig{HedgeDelay: time.Hour, MaxRetries: -1},
+			handler: func(n int32, w http.ResponseWriter, _ *http.Request) {
+				if n == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, _ = io.WriteString(w, "hedge")
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     "hedge",
+			wantRequests: 2,
+		},
+		{
+			name:   "last failure is returned",
+			method: http.MethodGet,
+			config: HTTPConfig{HedgeDelay: time.Hour, MaxHedges: 2, MaxRetries: -1},
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantStatus:   http.StatusBadGateway,
+			wantRequests: 3,
+		},
+		{
+			name:   "post is not hedged",
+			method: http.MethodPost,
+			config: HTTPConfig{HedgeDelay: time.Millisecond},
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				time.Sleep(20 * time.Millisecond)
+				_, _ = io.WriteString(w, "first")
+			},
+			wantStatus:   http.StatusOK,
+			wantBody:     "first",
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(requests.Add(1), w, r)
+			}))
+			defer server.Close()
+
+			client := NewHTTPClient(tt.config)
+			req, err := http.NewRequestWithContext(context.Background(), tt.method, server.URL, nil)
+			require.NoError(t, err)
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Equal(t, tt.wantBody, string(body))
+			assert.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package platigo
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+)
+
+const (
+	defaultHTTPMaxLogBodySize = 4 << 10
+	redactedValue             = "[REDACTED]"
+)
+
+var (
+	defaultHTTPRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	defaultHTTPRedactFields  = []string{"password", "secret", "token", "access_token", "refresh_token", "client_secret", "api_key"}
+)
+
+type HTTPLogConfig struct {
+	// Logger receives an entry per request. Defaults to a no-op logger.
+	Logger logger.Logger
+	// LogLevel is the minimum level passed to Logger. Every request is
+	// logged at DebugLevel, so the default InfoLevel only keeps transport
+	// errors and 5xx responses.
+	LogLevel logger.Level
+
+	// LogBodies adds the request and response bodies to the debug entries,
+	// up to MaxBodySize bytes each, 4KiB by default.
+	LogBodies   bool
+	MaxBodySize int
+
+	// RedactHeaders and RedactFields are redacted on top of the defaults:
+	// the Authorization, Proxy-Authorization, Cookie, Set-Cookie and
+	// X-Api-Key headers, and the password, secret, token, access_token,
+	// refresh_token, client_secret and api_key query parameters, JSON
+	// fields and form values. Names are case insensitive.
+	RedactHeaders []string
+	RedactFields  []string
+}
+
+// NewHTTPLogTransport creates a RoundTripper logging the method, URL,
+// status and latency of every request sent through next.
+func NewHTTPLogTransport(next http.RoundTripper, config HTTPLogConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultHTTPMaxLogBodySize
+	}
+
+	t := &logTransport{
+		next:          next,
+		config:        config,
+		log:           logger.WithLevel(config.Logger, config.LogLevel),
+		redactHeaders: map[string]bool{},
+		redactFields:  map[string]bool{},
+		now:           time.Now,
+	}
+	for _, name := range append(defaultHTTPRedactHeaders, config.RedactHeaders...) {
+		t.redactHeaders[http.CanonicalHeaderKey(name)] = true
+	}
+	for _, name := range append(defaultHTTPRedactFields, config.RedactFields...) {
+		t.redactFields[strings.ToLower(name)] = true
+	}
+	return t
+}
+
+type logTransport struct {
+	next          http.RoundTripper
+	config        HTTPLogConfig
+	log           logger.Logger
+	redactHeaders map[string]bool
+	redactFields  map[string]bool
+	now           func() time.Time
+}
+
+func (t *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	debug := t.config.LogLevel <= logger.DebugLevel
+	var reqBody string
+	if debug && t.config.LogBodies {
+		var err error
+		if reqBody, err = t.requestBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	started := t.now()
+	res, err := t.next.RoundTrip(req)
+	elapsed := t.now().Sub(started)
+
+	if err == nil && res.StatusCode < http.StatusInternalServerError && !debug {
+		return res, nil
+	}
+
+	log := logger.WithContext(req.Context(), t.log)
+	fields := logger.Fields{
+		"method":   req.Method,
+		"url":      t.redactURL(req.URL),
+		"duration": elapsed.String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+		log.With(fields).Error("HTTP request failed")
+		return nil, err
+	}
+	fields["status"] = res.StatusCode
+	if debug {
+		fields["requestHeaders"] = t.headers(req.Header)
+		fields["responseHeaders"] = t.headers(res.Header)
+		if t.config.LogBodies {
+			if reqBody != "" {
+				fields["requestBody"] = reqBody
+			}
+			if body := t.responseBody(res); body != "" {
+				fields["responseBody"] = body
+			}
+		}
+	}
+
+	if res.StatusCode >= http.StatusInternalServerError {
+		log.With(fields).Warn("HTTP request completed")
+	} else {
+		log.With(fields).Debug("HTTP request completed")
+	}
+	return res, nil
+}
+
+// requestBody returns the redacted request body. The body is read from
+// GetBody when set, and otherwise buffered and put back.
+func (t *logTransport) requestBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+	var data []byte
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		data, err = io.ReadAll(io.LimitReader(body, int64(t.config.MaxBodySize)+1))
+		_ = body.Close()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		var err error
+		data, err = io.ReadAll(io.LimitReader(req.Body, int64(t.config.MaxBodySize)+1))
+		if err != nil {
+			return "", err
+		}
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), req.Body), Closer: req.Body}
+	}
+	return t.redactBody(req.Header.Get("Content-Type"), data), nil
+}
+
+// responseBody returns the redacted start of the response body, which is
+// put back in front of the rest for the caller.
+func (t *logTransport) responseBody(res *http.Response) string {
+	data, err := io.ReadAll(io.LimitReader(res.Body, int64(t.config.MaxBodySize)+1))
+	res.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), res.Body), Closer: res.Body}
+	if err != nil {
+		return ""
+	}
+	return t.redactBody(res.Header.Get("Content-Type"), data)
+}
+
+// redactBody redacts the fields of JSON and form bodies. JSON bodies that
+// cannot be parsed, truncated ones included, are left out since they
+// cannot be redacted.
+func (t *logTransport) redactBody(contentType string, data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	truncated := len(data) > t.config.MaxBodySize
+	data = data[:min(len(data), t.config.MaxBodySize)]
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var v any
+		if truncated || json.Unmarshal(data, &v) != nil {
+			return "[unparsable JSON body omitted]"
+		}
+		redacted, err := json.Marshal(t.redactJSON(v))
+		if err != nil {
+			return ""
+		}
+		return string(redacted)
+	case mediaType == "application/x-www-form-urlencoded":
+		values, err := url.ParseQuery(string(data))
+		if err != nil {
+			return "[unparsable form body omitted]"
+		}
+		return t.redactValues(values).Encode()
+	}
+	if truncated {
+		return string(data) + "..."
+	}
+	return string(data)
+}
+
+func (t *logTransport) redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if t.redactFields[strings.ToLower(key)] {
+				v[key] = redactedValue
+			} else {
+				v[key] = t.redactJSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = t.redactJSON(value)
+		}
+	}
+	return v
+}
+
+func (t *logTransport) redactValues(values url.Values) url.Values {
+	for key := range values {
+		if t.redactFields[strings.ToLower(key)] {
+			values[key] = []string{redactedValue}
+		}
+	}
+	return values
+}
+
+func (t *logTransport) redactURL(u *url.URL) string {
+	redacted := *u
+	if u.RawQuery != "" {
+		redacted.RawQuery = t.redactValues(u.Query()).Encode()
+	}
+	return redacted.Redacted()
+}
+
+func (t *logTransport) headers(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if t.redactHeaders[http.CanonicalHeaderKey(name)] {
+			headers[name] = redactedValue
+		} else {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+// readCloser reads a buffered prefix and the rest of a body, and closes
+// the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
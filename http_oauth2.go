@@ -0,0 +1,126 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/bagastri07/platigo/logger"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"golang.org/x/oauth2/jwt"
+)
+
+var (
+	errOAuth2TokenURLRequired    = errors.New("oauth2: token url is required")
+	errOAuth2CredentialsRequired = errors.New("oauth2: client id or private key is required")
+)
+
+type OAuth2Config struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+	Scopes   []string
+
+	// ClientID and ClientSecret authenticate with the client credentials
+	// grant. EndpointParams are added to its token requests, such as the
+	// audience required by some servers.
+	ClientID       string
+	ClientSecret   string
+	EndpointParams url.Values
+
+	// PrivateKey switches to the JWT bearer grant: the token requests carry
+	// an assertion signed with this PEM encoded RSA key, issued by Issuer
+	// on behalf of Subject for Audience, which defaults to TokenURL.
+	PrivateKey   []byte
+	PrivateKeyID string
+	Issuer       string
+	Subject      string
+	Audience     string
+
+	// Transport sends the token requests, http.DefaultTransport by default.
+	Transport http.RoundTripper
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// NewOAuth2TokenSource creates a TokenSource fetching tokens with the grant
+// of config. Tokens are cached and refreshed shortly before they expire.
+func NewOAuth2TokenSource(config OAuth2Config) (oauth2.TokenSource, error) {
+	if config.TokenURL == "" {
+		return nil, errOAuth2TokenURLRequired
+	}
+
+	ctx := context.Background()
+	if config.Transport != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: config.Transport})
+	}
+
+	var source oauth2.TokenSource
+	grant := "client_credentials"
+	switch {
+	case len(config.PrivateKey) > 0:
+		grant = "jwt_bearer"
+		source = (&jwt.Config{
+			Email:        config.Issuer,
+			PrivateKey:   config.PrivateKey,
+			PrivateKeyID: config.PrivateKeyID,
+			Subject:      config.Subject,
+			Scopes:       config.Scopes,
+			TokenURL:     config.TokenURL,
+			Audience:     config.Audience,
+		}).TokenSource(ctx)
+	case config.ClientID != "":
+		source = (&clientcredentials.Config{
+			ClientID:       config.ClientID,
+			ClientSecret:   config.ClientSecret,
+			TokenURL:       config.TokenURL,
+			Scopes:         config.Scopes,
+			EndpointParams: config.EndpointParams,
+		}).TokenSource(ctx)
+	default:
+		return nil, errOAuth2CredentialsRequired
+	}
+
+	log := logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{
+		"tokenURL": config.TokenURL,
+		"grant":    grant,
+	})
+	return &loggedTokenSource{source: source, log: log}, nil
+}
+
+// NewOAuth2Transport creates a RoundTripper authenticating the requests sent
+// through next with a bearer token from NewOAuth2TokenSource. It is meant to
+// be the Transport of an HTTPConfig:
+//
+//	transport, err := platigo.NewOAuth2Transport(nil, platigo.OAuth2Config{
+//		TokenURL:     "https://auth.internal/oauth2/token",
+//		ClientID:     "orders",
+//		ClientSecret: secret,
+//	})
+//	client := platigo.NewHTTPClient(platigo.HTTPConfig{Transport: transport})
+func NewOAuth2Transport(next http.RoundTripper, config OAuth2Config) (http.RoundTripper, error) {
+	source, err := NewOAuth2TokenSource(config)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &oauth2.Transport{Source: source, Base: next}, nil
+}
+
+// loggedTokenSource logs the failed token requests.
+type loggedTokenSource struct {
+	source oauth2.TokenSource
+	log    logger.Logger
+}
+
+func (s *loggedTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		s.log.With(logger.Fields{"error": err.Error()}).Error("Failed to fetch OAuth2 token")
+	}
+	return token, err
+}
@@ -0,0 +1,103 @@
+package platigo
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuth2Transport(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tests := []struct {
+		name      string
+		config    OAuth2Config
+		wantGrant string
+		wantForm  map[string]string
+	}{
+		{
+			name:      "client credentials",
+			config:    OAuth2Config{ClientID: "orders", ClientSecret: "s3cret", Scopes: []string{"payments"}},
+			wantGrant: "client_credentials",
+			wantForm:  map[string]string{"scope": "payments"},
+		},
+		{
+			name:      "jwt bearer",
+			config:    OAuth2Config{PrivateKey: privateKey, Issuer: "orders@internal", Subject: "orders"},
+			wantGrant: "urn:ietf:params:oauth:grant-type:jwt-bearer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokenRequests atomic.Int32
+			auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tokenRequests.Add(1)
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, tt.wantGrant, r.PostForm.Get("grant_type"))
+				for key, want := range tt.wantForm {
+					assert.Equal(t, want, r.PostForm.Get(key), key)
+				}
+				if tt.config.ClientID != "" {
+					user, pass, ok := r.BasicAuth()
+					assert.True(t, ok)
+					assert.Equal(t, tt.config.ClientID, user)
+					assert.Equal(t, tt.config.ClientSecret, pass)
+				} else {
+					assert.NotEmpty(t, r.PostForm.Get("assertion"))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token":"token-1","token_type":"Bearer","expires_in":3600}`))
+			}))
+			defer auth.Close()
+
+			api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+			}))
+			defer api.Close()
+
+			tt.config.TokenURL = auth.URL
+			transport, err := NewOAuth2Transport(nil, tt.config)
+			require.NoError(t, err)
+			client := NewHTTPClient(HTTPConfig{Transport: transport})
+
+			for range 3 {
+				res, err := client.Get(api.URL)
+				require.NoError(t, err)
+				_ = res.Body.Close()
+			}
+			assert.Equal(t, int32(1), tokenRequests.Load())
+		})
+	}
+}
+
+func TestOAuth2TokenSourceErrors(t *testing.T) {
+	_, err := NewOAuth2TokenSource(OAuth2Config{ClientID: "orders"})
+	assert.ErrorIs(t, err, errOAuth2TokenURLRequired)
+
+	_, err = NewOAuth2TokenSource(OAuth2Config{TokenURL: "http://auth"})
+	assert.ErrorIs(t, err, errOAuth2CredentialsRequired)
+
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer auth.Close()
+
+	log := newRecordLogger()
+	source, err := NewOAuth2TokenSource(OAuth2Config{TokenURL: auth.URL, ClientID: "orders", Logger: log})
+	require.NoError(t, err)
+	_, err = source.Token()
+	require.Error(t, err)
+	assert.Equal(t, []string{"error:Failed to fetch OAuth2 token"}, log.Entries())
+}
@@ -0,0 +1,255 @@
+package platigo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/crypto"
+	"github.com/bagastri07/platigo/logger"
+)
+
+// Headers of a signed request.
+const (
+	HeaderSignatureKeyID     = "X-Signature-Key-Id"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	// HeaderSignature holds "hmac-sha256=" followed by the hex HMAC-SHA256
+	// of the canonical request, keyed with the secret of the key ID.
+	HeaderSignature = "X-Signature"
+)
+
+const (
+	requestSignaturePrefix      = "hmac-sha256="
+	defaultSignatureTolerance   = 5 * time.Minute
+	defaultSignatureMaxBodySize = 10 << 20
+)
+
+var (
+	// ErrInvalidRequestSignature is returned by HTTPVerifier.Verify for
+	// requests not signed, or not signed with a known key.
+	ErrInvalidRequestSignature = errors.New("http: invalid request signature")
+	// ErrExpiredRequestSignature is returned by HTTPVerifier.Verify for
+	// requests signed more than the tolerance away from now, possibly
+	// replayed.
+	ErrExpiredRequestSignature = errors.New("http: request signature outside tolerance")
+
+	errSigningKeyRequired = errors.New("http: signing key id and secret are required")
+	errSignedBodyTooLarge = errors.New("http: signed request body too large")
+)
+
+type HTTPSignerConfig struct {
+	// KeyID identifies Secret to the verifiers, typically the name of the
+	// calling service.
+	KeyID  string
+	Secret []byte
+	// SignedHeaders are covered by the signature on top of the method,
+	// host, path, query, timestamp and body, such as Content-Type.
+	SignedHeaders []string
+}
+
+// NewHTTPSigningTransport creates a RoundTripper signing every request sent
+// through next, for the services verifying them with NewHTTPVerifier.
+// Bodies are read with Request.GetBody when set, and buffered otherwise.
+func NewHTTPSigningTransport(next http.RoundTripper, config HTTPSignerConfig) (http.RoundTripper, error) {
+	if config.KeyID == "" || len(config.Secret) == 0 {
+		return nil, errSigningKeyRequired
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &signingTransport{next: next, config: config, now: time.Now}, nil
+}
+
+type signingTransport struct {
+	next   http.RoundTripper
+	config HTTPSignerConfig
+	now    func() time.Time
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBodyBytes(req, defaultSignatureMaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the request they were given.
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	timestamp := strconv.FormatInt(t.now().Unix(), 10)
+	req.Header.Set(HeaderSignatureKeyID, t.config.KeyID)
+	req.Header.Set(HeaderSignatureTimestamp, timestamp)
+
+	canonical := canonicalRequest(req.Method, host, req.URL, req.Header, t.config.SignedHeaders, body)
+	req.Header.Set(HeaderSignature, requestSignaturePrefix+crypto.SignPayload(t.config.Secret, canonical))
+	return t.next.RoundTrip(req)
+}
+
+type HTTPVerifierConfig struct {
+	// Secrets maps the key IDs accepted to their secret. Keeping the old
+	// and new secret of a caller for a while rotates it without downtime.
+	Secrets map[string][]byte
+	// SignedHeaders must match the SignedHeaders of the callers.
+	SignedHeaders []string
+	// Tolerance is the accepted clock difference with the callers, 5
+	// minutes by default, which is also how long a captured request can
+	// be replayed.
+	Tolerance time.Duration
+	// MaxBodySize bounds the bodies buffered for verification, 10MiB by
+	// default.
+	MaxBodySize int64
+
+	// Logger receives the rejected requests. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// HTTPVerifier checks the signature of the requests signed by
+// NewHTTPSigningTransport.
+type HTTPVerifier struct {
+	config HTTPVerifierConfig
+	log    logger.Logger
+	now    func() time.Time
+}
+
+// NewHTTPVerifier creates an HTTPVerifier for the keys of config.
+func NewHTTPVerifier(config HTTPVerifierConfig) *HTTPVerifier {
+	if config.Tolerance <= 0 {
+		config.Tolerance = defaultSignatureTolerance
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultSignatureMaxBodySize
+	}
+	return &HTTPVerifier{
+		config: config,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel),
+		now:    time.Now,
+	}
+}
+
+// Verify checks the signature of r and returns the key ID it was signed
+// with. The body is buffered and put back for the handler.
+func (v *HTTPVerifier) Verify(r *http.Request) (string, error) {
+	keyID := r.Header.Get(HeaderSignatureKeyID)
+	secret, ok := v.config.Secrets[keyID]
+	if !ok {
+		return "", ErrInvalidRequestSignature
+	}
+	unix, err := strconv.ParseInt(r.Header.Get(HeaderSignatureTimestamp), 10, 64)
+	if err != nil {
+		return "", ErrInvalidRequestSignature
+	}
+	if age := v.now().Sub(time.Unix(unix, 0)); age > v.config.Tolerance || age < -v.config.Tolerance {
+		return "", ErrExpiredRequestSignature
+	}
+	signature, ok := strings.CutPrefix(r.Header.Get(HeaderSignature), requestSignaturePrefix)
+	if !ok {
+		return "", ErrInvalidRequestSignature
+	}
+
+	body, err := requestBodyBytes(r, v.config.MaxBodySize)
+	if err != nil {
+		return "", err
+	}
+	if body != nil {
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	canonical := canonicalRequest(r.Method, r.Host, r.URL, r.Header, v.config.SignedHeaders, body)
+	if !crypto.VerifyPayload(secret, canonical, signature) {
+		return "", ErrInvalidRequestSignature
+	}
+	return keyID, nil
+}
+
+// Middleware rejects the requests failing Verify with 401 Unauthorized
+// before they reach next.
+func (v *HTTPVerifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := v.Verify(r); err != nil {
+			v.log.With(logger.Fields{
+				"keyID":  r.Header.Get(HeaderSignatureKeyID),
+				"method": r.Method,
+				"path":   r.URL.Path,
+				"error":  err.Error(),
+			}).Warn("Rejected unsigned HTTP request")
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// canonicalRequest returns the string covered by the signature: the
+// method, host, escaped path, sorted query, timestamp, key ID, signed
+// headers and the hex SHA-256 of the body, one per line.
+func canonicalRequest(method, host string, u *url.URL, header http.Header, signedHeaders []string, body []byte) []byte {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(method))
+	b.WriteByte('\n')
+	b.WriteString(strings.ToLower(host))
+	b.WriteByte('\n')
+	b.WriteString(u.EscapedPath())
+	b.WriteByte('\n')
+	// Encode sorts the query by key.
+	b.WriteString(u.Query().Encode())
+	b.WriteByte('\n')
+	b.WriteString(header.Get(HeaderSignatureTimestamp))
+	b.WriteByte('\n')
+	b.WriteString(header.Get(HeaderSignatureKeyID))
+	b.WriteByte('\n')
+
+	names := make([]string, len(signedHeaders))
+	for i, name := range signedHeaders {
+		names[i] = strings.ToLower(name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.TrimSpace(strings.Join(header.Values(name), ",")))
+		b.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256(body)
+	b.WriteString(hex.EncodeToString(sum[:]))
+	return []byte(b.String())
+}
+
+// requestBodyBytes reads and closes the body of req, reading it from
+// GetBody when set. Nil means no body.
+func requestBodyBytes(req *http.Request, limit int64) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	body := req.Body
+	if req.GetBody != nil {
+		var err error
+		if body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+		defer body.Close()
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errSignedBodyTooLarge
+	}
+	return data, nil
+}
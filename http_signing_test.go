@@ -0,0 +1,121 @@
+package platigo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSigning(t *testing.T) {
+	secret := []byte("s3cret")
+	tests := []struct {
+		name       string
+		signer     HTTPSignerConfig
+		tamper     func(r *http.Request)
+		clockSkew  time.Duration
+		wantStatus int
+	}{
+		{
+			name:       "valid signature",
+			signer:     HTTPSignerConfig{KeyID: "orders", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown key",
+			signer:     HTTPSignerConfig{KeyID: "billing", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong secret",
+			signer:     HTTPSignerConfig{KeyID: "orders", Secret: []byte("other"), SignedHeaders: []string{"Content-Type"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "tampered signed header",
+			signer: HTTPSignerConfig{KeyID: "orders", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			tamper: func(r *http.Request) {
+				r.Header.Set("Content-Type", "text/plain")
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "tampered query",
+			signer: HTTPSignerConfig{KeyID: "orders", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			tamper: func(r *http.Request) {
+				r.URL.RawQuery = "dry_run=false"
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:   "tampered body",
+			signer: HTTPSignerConfig{KeyID: "orders", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			tamper: func(r *http.Request) {
+				r.Body = io.NopCloser(strings.NewReader(`{"total":1}`))
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "expired timestamp",
+			signer:     HTTPSignerConfig{KeyID: "orders", Secret: secret, SignedHeaders: []string{"Content-Type"}},
+			clockSkew:  -10 * time.Minute,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewHTTPVerifier(HTTPVerifierConfig{
+				Secrets:       map[string][]byte{"orders": secret},
+				SignedHeaders: []string{"content-type"},
+			})
+			handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, `{"total":42}`, string(body))
+			}))
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.tamper != nil {
+					tt.tamper(r)
+				}
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			transport, err := NewHTTPSigningTransport(nil, tt.signer)
+			require.NoError(t, err)
+			transport.(*signingTransport).now = func() time.Time { return time.Now().Add(tt.clockSkew) }
+			client := &http.Client{Transport: transport}
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/orders?dry_run=true", strings.NewReader(`{"total":42}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			res, err := client.Do(req)
+			require.NoError(t, err)
+			_ = res.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			assert.Empty(t, req.Header.Get(HeaderSignature), "the request of the caller is not modified")
+		})
+	}
+}
+
+func TestHTTPVerifierErrors(t *testing.T) {
+	verifier := NewHTTPVerifier(HTTPVerifierConfig{Secrets: map[string][]byte{"orders": []byte("s3cret")}})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	_, err := verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrInvalidRequestSignature)
+
+	req.Header.Set(HeaderSignatureKeyID, "orders")
+	req.Header.Set(HeaderSignatureTimestamp, "1")
+	_, err = verifier.Verify(req)
+	assert.ErrorIs(t, err, ErrExpiredRequestSignature)
+
+	_, err = NewHTTPSigningTransport(nil, HTTPSignerConfig{KeyID: "orders"})
+	assert.ErrorIs(t, err, errSigningKeyRequired)
+}
@@ -0,0 +1,67 @@
+package platigo
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultHTTPMaxIdleConns        = 512
+	defaultHTTPMaxIdleConnsPerHost = 64
+	defaultHTTPIdleConnTimeout     = 90 * time.Second
+	defaultHTTPDialTimeout         = 5 * time.Second
+	defaultHTTPTLSHandshakeTimeout = 5 * time.Second
+	defaultHTTPKeepAlive           = 30 * time.Second
+)
+
+// NewHTTPTransport creates the http.Transport of NewHTTPClient from the
+// pool, timeout, TLS and proxy settings of config, with defaults suited
+// to many concurrent calls to the same hosts.
+func NewHTTPTransport(config HTTPConfig) *http.Transport {
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = defaultHTTPMaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost <= 0 {
+		config.MaxIdleConnsPerHost = defaultHTTPMaxIdleConnsPerHost
+	}
+	if config.IdleConnTimeout <= 0 {
+		config.IdleConnTimeout = defaultHTTPIdleConnTimeout
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultHTTPDialTimeout
+	}
+	if config.TLSHandshakeTimeout <= 0 {
+		config.TLSHandshakeTimeout = defaultHTTPTLSHandshakeTimeout
+	}
+	if config.KeepAlive == 0 {
+		config.KeepAlive = defaultHTTPKeepAlive
+	}
+	if config.Proxy == nil {
+		config.Proxy = http.ProxyFromEnvironment
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   config.DialTimeout,
+		KeepAlive: config.KeepAlive,
+	}
+	transport := &http.Transport{
+		Proxy:                 config.Proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       config.TLSConfig,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     !config.DisableHTTP2,
+	}
+	if config.DisableHTTP2 {
+		// A non-nil empty map keeps the transport from upgrading to HTTP/2.
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
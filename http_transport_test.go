@@ -0,0 +1,49 @@
+package platigo
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPTransport(t *testing.T) {
+	transport := NewHTTPTransport(HTTPConfig{})
+	assert.Equal(t, 512, transport.MaxIdleConns)
+	assert.Equal(t, 64, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 0, transport.MaxConnsPerHost)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, 5*time.Second, transport.TLSHandshakeTimeout)
+	assert.True(t, transport.ForceAttemptHTTP2)
+	assert.Nil(t, transport.TLSNextProto)
+	assert.NotNil(t, transport.Proxy)
+
+	proxy, err := url.Parse("http://proxy.internal:3128")
+	require.NoError(t, err)
+	transport = NewHTTPTransport(HTTPConfig{
+		MaxIdleConnsPerHost:   8,
+		MaxConnsPerHost:       16,
+		ResponseHeaderTimeout: 2 * time.Second,
+		DisableHTTP2:          true,
+		Proxy:                 http.ProxyURL(proxy),
+	})
+	assert.Equal(t, 8, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 16, transport.MaxConnsPerHost)
+	assert.Equal(t, 2*time.Second, transport.ResponseHeaderTimeout)
+	assert.False(t, transport.ForceAttemptHTTP2)
+	assert.NotNil(t, transport.TLSNextProto)
+
+	got, err := transport.Proxy(&http.Request{URL: &url.URL{Scheme: "https", Host: "partner.example"}})
+	require.NoError(t, err)
+	assert.Equal(t, proxy, got)
+}
+
+func TestNewHTTPClientTransport(t *testing.T) {
+	client := NewHTTPClient(HTTPConfig{MaxConnsPerHost: 4})
+	transport, ok := client.Transport.(*retryTransport).next.(*http.Transport)
+	require.True(t, ok)
+	assert.Equal(t, 4, transport.MaxConnsPerHost)
+}
@@ -0,0 +1,424 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/cache"
+	"github.com/bagastri07/platigo/logger"
+)
+
+const (
+	defaultCacheMaxBodySize  = 1 << 20
+	defaultCacheValidatorTTL = time.Hour
+)
+
+// HeaderCache is set to HIT, STALE or REVALIDATED on the responses served
+// by the caching transport.
+const HeaderCache = "X-Cache"
+
+// CachedResponse is a response stored by the caching transport.
+type CachedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	// Vary holds the request headers named by the Vary response header,
+	// which a request must match to be served the response.
+	Vary http.Header `json:"vary,omitempty"`
+	// StoredAt is when the response was received, and FreshUntil and
+	// StaleUntil when it stops being fresh and usable while revalidated.
+	StoredAt   time.Time `json:"storedAt"`
+	FreshUntil time.Time `json:"freshUntil"`
+	StaleUntil time.Time `json:"staleUntil"`
+}
+
+type CacheConfig struct {
+	// Store keeps the responses, such as cache.NewMemory or cache.NewRedis
+	// to share them across replicas.
+	Store cache.Cache[CachedResponse]
+	// Private makes a cache for a single user: responses marked private
+	// are stored and s-maxage is ignored. Otherwise the cache is shared and
+	// the responses to requests with an Authorization header are only
+	// stored when marked public.
+	Private bool
+	// MaxBodySize bounds the bodies stored, 1MiB by default.
+	MaxBodySize int
+	// ValidatorTTL is how long responses with an ETag or Last-Modified
+	// header are kept once stale, to revalidate them with a conditional
+	// request. 1 hour by default.
+	ValidatorTTL time.Duration
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// NewCacheTransport creates a RoundTripper caching the GET responses of
+// next following their Cache-Control, Expires and Vary headers. Stale
+// responses are revalidated with If-None-Match and If-Modified-Since, and
+// served while revalidated in the background within their
+// stale-while-revalidate window. Successful unsafe requests invalidate the
+// stored response of their URL.
+//
+// Wrapping the transport of a platigo.NewHTTPClient serves the hits
+// without going through its retries and rate limiting:
+//
+//	client := platigo.NewHTTPClient(config)
+//	client.Transport = httpclient.NewCacheTransport(client.Transport, httpclient.CacheConfig{
+//		Store: cache.NewRedis[httpclient.CachedResponse](redis.Client(), cache.WithPrefix("http:")),
+//	})
+func NewCacheTransport(next http.RoundTripper, config CacheConfig) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = defaultCacheMaxBodySize
+	}
+	if config.ValidatorTTL <= 0 {
+		config.ValidatorTTL = defaultCacheValidatorTTL
+	}
+	return &cacheTransport{
+		next:         next,
+		config:       config,
+		log:          logger.WithLevel(config.Logger, logger.InfoLevel),
+		now:          time.Now,
+		revalidating: map[string]bool{},
+	}
+}
+
+type cacheTransport struct {
+	next   http.RoundTripper
+	config CacheConfig
+	log    logger.Logger
+	now    func() time.Time
+
+	mu           sync.Mutex
+	revalidating map[string]bool
+	background   sync.WaitGroup
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := req.URL.String()
+	if req.Method != http.MethodGet {
+		res, err := t.next.RoundTrip(req)
+		if err == nil && !isSafeMethod(req.Method) && res.StatusCode < http.StatusBadRequest {
+			t.delete(req.Context(), key)
+		}
+		return res, err
+	}
+
+	reqControl := parseCacheControl(req.Header)
+	if reqControl.has("no-store") || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	entry, ok := t.get(req.Context(), key)
+	if ok && !entry.matches(req) {
+		ok = false
+	}
+	if !ok {
+		return t.fetch(req, key, nil)
+	}
+
+	now := t.now()
+	if !reqControl.has("no-cache") {
+		if now.Before(entry.FreshUntil) {
+			return entry.response(req, now, "HIT"), nil
+		}
+		if now.Before(entry.StaleUntil) {
+			t.revalidate(req, key, entry)
+			return entry.response(req, now, "STALE"), nil
+		}
+	}
+	return t.fetch(req, key, entry)
+}
+
+// fetch sends req, conditional on the validators of entry when set, and
+// stores the response when cacheable.
+func (t *cacheTransport) fetch(req *http.Request, key string, entry *CachedResponse) (*http.Response, error) {
+	conditional := entry != nil && entry.hasValidator() &&
+		req.Header.Get("If-None-Match") == "" && req.Header.Get("If-Modified-Since") == ""
+	outgoing := req
+	if conditional {
+		outgoing = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			outgoing.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			outgoing.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	res, err := t.next.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if conditional && res.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		updated := entry.revalidated(res.Header, t.now(), t.config)
+		t.set(req.Context(), key, updated)
+		return updated.response(req, t.now(), "REVALIDATED"), nil
+	}
+	return t.store(req, key, res), nil
+}
+
+// store stores res when cacheable and returns it with its body intact.
+func (t *cacheTransport) store(req *http.Request, key string, res *http.Response) *http.Response {
+	entry, ok := newCachedResponse(req, res, t.now(), t.config)
+	if !ok {
+		return res
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, int64(t.config.MaxBodySize)+1))
+	res.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), res.Body), Closer: res.Body}
+	if err != nil || len(body) > t.config.MaxBodySize {
+		return res
+	}
+	entry.Body = body
+	t.set(req.Context(), key, entry)
+	return res
+}
+
+// revalidate refreshes entry in the background, once per key at a time.
+func (t *cacheTransport) revalidate(req *http.Request, key string, entry *CachedResponse) {
+	t.mu.Lock()
+	if t.revalidating[key] {
+		t.mu.Unlock()
+		return
+	}
+	t.revalidating[key] = true
+	t.mu.Unlock()
+
+	req = req.Clone(context.WithoutCancel(req.Context()))
+	t.background.Add(1)
+	go func() {
+		defer t.background.Done()
+		defer func() {
+			t.mu.Lock()
+			delete(t.revalidating, key)
+			t.mu.Unlock()
+		}()
+
+		res, err := t.fetch(req, key, entry)
+		if err != nil {
+			logger.WithContext(req.Context(), t.log).With(logger.Fields{
+				"url":   req.URL.Redacted(),
+				"error": err.Error(),
+			}).Warn("Failed to revalidate cached HTTP response")
+			return
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+	}()
+}
+
+func (t *cacheTransport) get(ctx context.Context, key string) (*CachedResponse, bool) {
+	entry, err := t.config.Store.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			logger.WithContext(ctx, t.log).With(logger.Fields{"error": err.Error()}).Warn("Failed to read HTTP cache")
+		}
+		return nil, false
+	}
+	return &entry, true
+}
+
+func (t *cacheTransport) set(ctx context.Context, key string, entry *CachedResponse) {
+	ttl := entry.StaleUntil.Sub(t.now())
+	if entry.hasValidator() {
+		ttl = max(ttl, entry.FreshUntil.Sub(t.now())+t.config.ValidatorTTL)
+	}
+	if ttl <= 0 {
+		return
+	}
+	if err := t.config.Store.Set(ctx, key, *entry, ttl); err != nil {
+		logger.WithContext(ctx, t.log).With(logger.Fields{"error": err.Error()}).Warn("Failed to write HTTP cache")
+	}
+}
+
+func (t *cacheTransport) delete(ctx context.Context, key string) {
+	if err := t.config.Store.Delete(ctx, key); err != nil {
+		logger.WithContext(ctx, t.log).With(logger.Fields{"error": err.Error()}).Warn("Failed to invalidate HTTP cache")
+	}
+}
+
+// cacheableStatus lists the status codes cacheable by default.
+var cacheableStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+// newCachedResponse returns the entry of res without its body, or false
+// when res may not be stored.
+func newCachedResponse(req *http.Request, res *http.Response, now time.Time, config CacheConfig) (*CachedResponse, bool) {
+	if !cacheableStatus[res.StatusCode] {
+		return nil, false
+	}
+	control := parseCacheControl(res.Header)
+	if control.has("no-store") || (!config.Private && control.has("private")) {
+		return nil, false
+	}
+	if !config.Private && req.Header.Get("Authorization") != "" &&
+		!control.has("public") && !control.has("s-maxage") && !control.has("must-revalidate") {
+		return nil, false
+	}
+
+	vary := http.Header{}
+	for _, name := range res.Header.Values("Vary") {
+		for _, name := range strings.Split(name, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				vary[name] = req.Header.Values(name)
+			}
+		}
+	}
+
+	entry := &CachedResponse{
+		StatusCode: res.StatusCode,
+		Header:     res.Header.Clone(),
+		Vary:       vary,
+		StoredAt:   now,
+	}
+	entry.setFreshness(control, now, config)
+	if !entry.FreshUntil.After(now) && !entry.hasValidator() {
+		return nil, false
+	}
+	return entry, true
+}
+
+// setFreshness computes FreshUntil and StaleUntil from the response
+// headers. Responses without explicit freshness are stale right away.
+func (e *CachedResponse) setFreshness(control cacheControl, now time.Time, config CacheConfig) {
+	var lifetime time.Duration
+	maxAge, ok := control.duration("s-maxage")
+	if !ok || config.Private {
+		maxAge, ok = control.duration("max-age")
+	}
+	switch {
+	case control.has("no-cache"):
+	case ok:
+		lifetime = maxAge
+	case e.Header.Get("Expires") != "":
+		expires, err := http.ParseTime(e.Header.Get("Expires"))
+		date, dateErr := http.ParseTime(e.Header.Get("Date"))
+		if dateErr != nil {
+			date = now
+		}
+		if err == nil {
+			lifetime = expires.Sub(date)
+		}
+	}
+	if age, err := strconv.Atoi(e.Header.Get("Age")); err == nil && age > 0 {
+		lifetime -= time.Duration(age) * time.Second
+	}
+	lifetime = max(lifetime, 0)
+
+	e.FreshUntil = now.Add(lifetime)
+	e.StaleUntil = e.FreshUntil
+	if swr, ok := control.duration("stale-while-revalidate"); ok && !control.has("must-revalidate") {
+		e.StaleUntil = e.FreshUntil.Add(swr)
+	}
+}
+
+// revalidated returns a copy of e refreshed by the headers of a 304 response.
+func (e *CachedResponse) revalidated(header http.Header, now time.Time, config CacheConfig) *CachedResponse {
+	updated := *e
+	updated.Header = e.Header.Clone()
+	for name, values := range header {
+		updated.Header[name] = values
+	}
+	updated.StoredAt = now
+	updated.setFreshness(parseCacheControl(updated.Header), now, config)
+	return &updated
+}
+
+func (e *CachedResponse) hasValidator() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// matches reports whether req has the request headers named by Vary.
+func (e *CachedResponse) matches(req *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(values, ",") != strings.Join(req.Header.Values(name), ",") {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *CachedResponse) response(req *http.Request, now time.Time, status string) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(now.Sub(e.StoredAt)/time.Second)))
+	header.Set(HeaderCache, status)
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheControl holds the directives of a Cache-Control header.
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	control := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				control[strings.ToLower(name)] = strings.Trim(arg, `"`)
+			}
+		}
+	}
+	return control
+}
+
+func (c cacheControl) has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+func (c cacheControl) duration(name string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(c[name])
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// readCloser reads a buffered prefix and the rest of a body, and closes
+// the original body.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,180 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type cacheStep struct {
+	advance    time.Duration
+	method     string
+	header     http.Header
+	wantCache  string
+	wantBody   string
+	wantOrigin int32
+}
+
+func TestCacheTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CacheConfig
+		handler func(n int32, w http.ResponseWriter, r *http.Request)
+		steps   []cacheStep
+	}{
+		{
+			name: "fresh responses are served from the cache",
+			handler: func(n int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				_, _ = io.WriteString(w, "v"+string('0'+n))
+			},
+			steps: []cacheStep{
+				{wantBody: "v1", wantOrigin: 1},
+				{advance: 30 * time.Second, wantCache: "HIT", wantBody: "v1", wantOrigin: 1},
+				{advance: 31 * time.Second, wantBody: "v2", wantOrigin: 2},
+			},
+		},
+		{
+			name: "stale responses are revalidated with the etag",
+			handler: func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=10")
+				w.Header().Set("ETag", `"abc"`)
+				if r.Header.Get("If-None-Match") == `"abc"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				_, _ = io.WriteString(w, "body")
+			},
+			steps: []cacheStep{
+				{wantBody: "body", wantOrigin: 1},
+				{advance: 20 * time.Second, wantCache: "REVALIDATED", wantBody: "body", wantOrigin: 2},
+				{advance: 5 * time.Second, wantCache: "HIT", wantBody: "body", wantOrigin: 2},
+			},
+		},
+		{
+			name: "stale while revalidate serves the stale response",
+			handler: func(n int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+				_, _ = io.WriteString(w, "v"+string('0'+n))
+			},
+			steps: []cacheStep{
+				{wantBody: "v1", wantOrigin: 1},
+				{advance: 20 * time.Second, wantCache: "STALE", wantBody: "v1", wantOrigin: 2},
+				{wantCache: "HIT", wantBody: "v2", wantOrigin: 2},
+			},
+		},
+		{
+			name: "no-store responses are not cached",
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "no-store, max-age=60")
+			},
+			steps: []cacheStep{
+				{wantOrigin: 1},
+				{wantOrigin: 2},
+			},
+		},
+		{
+			name: "private responses are only cached by private caches",
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "private, max-age=60")
+			},
+			steps: []cacheStep{
+				{wantOrigin: 1},
+				{wantOrigin: 2},
+			},
+		},
+		{
+			name:   "private cache",
+			config: CacheConfig{Private: true},
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "private, max-age=60")
+			},
+			steps: []cacheStep{
+				{wantOrigin: 1},
+				{wantCache: "HIT", wantOrigin: 1},
+			},
+		},
+		{
+			name: "request no-cache revalidates",
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+			},
+			steps: []cacheStep{
+				{wantOrigin: 1},
+				{header: http.Header{"Cache-Control": {"no-cache"}}, wantOrigin: 2},
+			},
+		},
+		{
+			name: "vary on request headers",
+			handler: func(_ int32, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+				w.Header().Set("Vary", "Accept-Language")
+				_, _ = io.WriteString(w, r.Header.Get("Accept-Language"))
+			},
+			steps: []cacheStep{
+				{header: http.Header{"Accept-Language": {"en"}}, wantBody: "en", wantOrigin: 1},
+				{header: http.Header{"Accept-Language": {"en"}}, wantCache: "HIT", wantBody: "en", wantOrigin: 1},
+				{header: http.Header{"Accept-Language": {"id"}}, wantBody: "id", wantOrigin: 2},
+			},
+		},
+		{
+			name: "unsafe requests invalidate the url",
+			handler: func(_ int32, w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Cache-Control", "max-age=60")
+			},
+			steps: []cacheStep{
+				{wantOrigin: 1},
+				{method: http.MethodDelete, wantOrigin: 2},
+				{wantOrigin: 3},
+				{wantCache: "HIT", wantOrigin: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(requests.Add(1), w, r)
+			}))
+			defer server.Close()
+
+			now := time.Unix(1700000000, 0)
+			tt.config.Store = cache.NewMemory[CachedResponse]()
+			transport := NewCacheTransport(nil, tt.config).(*cacheTransport)
+			transport.now = func() time.Time { return now }
+			client := &http.Client{Transport: transport}
+
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+				method := step.method
+				if method == "" {
+					method = http.MethodGet
+				}
+				req, err := http.NewRequest(method, server.URL+"/countries", nil)
+				require.NoError(t, err)
+				for key, values := range step.header {
+					req.Header[key] = values
+				}
+
+				res, err := client.Do(req)
+				require.NoError(t, err)
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				_ = res.Body.Close()
+				transport.background.Wait()
+
+				assert.Equal(t, step.wantCache, res.Header.Get(HeaderCache), "step %d", i)
+				assert.Equal(t, step.wantBody, string(body), "step %d", i)
+				assert.Equal(t, step.wantOrigin, requests.Load(), "step %d", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package httpclient provides typed helpers for JSON REST calls and a
+// caching transport.
+//
+//	order, err := httpclient.GetJSON[Order](ctx, "https://orders.internal/orders/1",
+//		httpclient.WithClient(client))
+//	if errors.Is(err, httpclient.ErrNotFound) {
+//		...
+//	}
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// maxErrorBodySize bounds the body kept by a StatusError.
+const maxErrorBodySize = 64 << 10
+
+var (
+	// ErrBadRequest is matched by errors.Is on 400 and 422 responses.
+	ErrBadRequest = errors.New("httpclient: bad request")
+	// ErrUnauthorized is matched by errors.Is on 401 responses.
+	ErrUnauthorized = errors.New("httpclient: unauthorized")
+	// ErrForbidden is matched by errors.Is on 403 responses.
+	ErrForbidden = errors.New("httpclient: forbidden")
+	// ErrNotFound is matched by errors.Is on 404 and 410 responses.
+	ErrNotFound = errors.New("httpclient: not found")
+	// ErrConflict is matched by errors.Is on 409 and 412 responses.
+	ErrConflict = errors.New("httpclient: conflict")
+	// ErrRateLimited is matched by errors.Is on 429 responses.
+	ErrRateLimited = errors.New("httpclient: rate limited")
+	// ErrServer is matched by errors.Is on 5xx responses.
+	ErrServer = errors.New("httpclient: server error")
+)
+
+// StatusError is returned for the responses outside of the 2xx range.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// Body is the start of the response body, often carrying the reason.
+	Body []byte
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("httpclient: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if body := strings.TrimSpace(string(e.Body)); body != "" && len(body) <= 512 {
+		msg += ": " + body
+	}
+	return msg
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusPreconditionFailed
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	default:
+		return false
+	}
+}
+
+// Option customizes a single call.
+type Option func(*options)
+
+type options struct {
+	client *http.Client
+	header http.Header
+}
+
+// WithClient sends the request with client instead of http.DefaultClient,
+// typically one created by platigo.NewHTTPClient.
+func WithClient(client *http.Client) Option {
+	return func(o *options) {
+		o.client = client
+	}
+}
+
+// WithHeader adds a header to the request.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		o.header.Add(key, value)
+	}
+}
+
+// GetJSON sends a GET request to url and decodes the JSON response into a T.
+// Responses outside of the 2xx range are returned as a *StatusError.
+func GetJSON[T any](ctx context.Context, url string, opts ...Option) (T, error) {
+	var resp T
+	err := do(ctx, http.MethodGet, url, nil, &resp, opts)
+	return resp, err
+}
+
+// PostJSON sends body as JSON in a POST request to url and decodes the JSON
+// response into a Resp. An empty response body leaves the zero Resp.
+func PostJSON[Req, Resp any](ctx context.Context, url string, body Req, opts ...Option) (Resp, error) {
+	return SendJSON[Req, Resp](ctx, http.MethodPost, url, body, opts...)
+}
+
+// SendJSON is PostJSON with another method, such as PUT or PATCH.
+func SendJSON[Req, Resp any](ctx context.Context, method, url string, body Req, opts ...Option) (Resp, error) {
+	var resp Resp
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return resp, err
+	}
+	err = do(ctx, method, url, payload, &resp, opts)
+	return resp, err
+}
+
+func do(ctx context.Context, method, url string, payload []byte, resp any, opts []Option) error {
+	o := &options{client: http.DefaultClient, header: http.Header{}}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, values := range o.header {
+		req.Header[key] = values
+	}
+
+	res, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+		return &StatusError{Method: method, URL: req.URL.Redacted(), StatusCode: res.StatusCode, Body: data}
+	}
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("httpclient: decode %s %s response: %w", method, req.URL.Redacted(), err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type order struct {
+	ID    string `json:"id"`
+	Total int    `json:"total"`
+}
+
+func TestGetJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    order
+		wantErr error
+	}{
+		{
+			name:   "decodes the response",
+			status: http.StatusOK,
+			body:   `{"id":"o-1","total":42}`,
+			want:   order{ID: "o-1", Total: 42},
+		},
+		{
+			name:   "empty body",
+			status: http.StatusNoContent,
+		},
+		{name: "bad request", status: http.StatusUnprocessableEntity, body: `{"error":"invalid"}`, wantErr: ErrBadRequest},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: ErrUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden, wantErr: ErrForbidden},
+		{name: "not found", status: http.StatusNotFound, wantErr: ErrNotFound},
+		{name: "conflict", status: http.StatusConflict, wantErr: ErrConflict},
+		{name: "rate limited", status: http.StatusTooManyRequests, wantErr: ErrRateLimited},
+		{name: "server error", status: http.StatusBadGateway, wantErr: ErrServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodGet, r.Method)
+				assert.Equal(t, "application/json", r.Header.Get("Accept"))
+				assert.Equal(t, "abc", r.Header.Get("X-Request-Id"))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := GetJSON[order](context.Background(), server.URL+"/orders/o-1",
+				WithClient(server.Client()), WithHeader("X-Request-Id", "abc"))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				var statusErr *StatusError
+				require.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, tt.status, statusErr.StatusCode)
+				assert.Equal(t, tt.body, string(statusErr.Body))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetJSONDecodeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<html>`))
+	}))
+	defer server.Close()
+
+	_, err := GetJSON[order](context.Background(), server.URL)
+	assert.ErrorContains(t, err, "httpclient: decode GET")
+}
+
+func TestPostJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"id":"o-1","total":42}`, string(body))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"o-1","total":42}`))
+	}))
+	defer server.Close()
+
+	got, err := PostJSON[order, order](context.Background(), server.URL+"/orders", order{ID: "o-1", Total: 42})
+	require.NoError(t, err)
+	assert.Equal(t, order{ID: "o-1", Total: 42}, got)
+}
@@ -0,0 +1,185 @@
+// Package idempotency makes retried operations run once: the first call with
+// a key reserves it, and once completed its response is replayed to every
+// repeat call until it expires.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultTTL     = 24 * time.Hour
+	defaultLockTTL = time.Minute
+	defaultPrefix  = "idempotency:"
+
+	// Stored values are a state marker, followed by the token of the
+	// reservation while pending, or by the response once completed.
+	statePending   = "p"
+	stateCompleted = "c"
+)
+
+var (
+	// ErrInProgress is returned by Begin while another call holds the key,
+	// and by Complete once another call took over the expired reservation.
+	ErrInProgress = errors.New("idempotency: request in progress")
+	// ErrAlreadyCompleted is returned by Complete when the key already has a
+	// response.
+	ErrAlreadyCompleted = errors.New("idempotency: already completed")
+)
+
+// beginScript returns the stored value of the key, or reserves it and returns
+// nil.
+var beginScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value then
+	return value
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return false
+`)
+
+// completeScript stores the response unless one is stored already, or
+// another reservation replaced the one of ARGV[4]. A missing key, whose
+// reservation expired, is completed all the same.
+var completeScript = redis.NewScript(`
+local value = redis.call("GET", KEYS[1])
+if value and value ~= ARGV[4] then
+	if string.sub(value, 1, 1) == ARGV[1] then
+		return 0
+	end
+	return -1
+end
+redis.call("SET", KEYS[1], ARGV[1] .. ARGV[2], "PX", ARGV[3])
+return 1
+`)
+
+// releaseScript drops the reservation of ARGV[1], leaving completed keys
+// and the reservations of other calls alone.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Config configures a Store.
+type Config struct {
+	// TTL is how long a completed response is replayed, 24 hours by default.
+	TTL time.Duration
+	// LockTTL is how long a reservation is held before another call may take
+	// over, one minute by default. It must exceed the time the operation
+	// takes.
+	LockTTL time.Duration
+	// Prefix is the prefix of the Redis keys, "idempotency:" by default.
+	Prefix string
+}
+
+// Store records idempotency keys in Redis.
+type Store struct {
+	client  redis.UniversalClient
+	ttl     time.Duration
+	lockTTL time.Duration
+	prefix  string
+}
+
+// NewStore creates a Store.
+func NewStore(client redis.UniversalClient, config Config) *Store {
+	s := &Store{
+		client:  client,
+		ttl:     config.TTL,
+		lockTTL: config.LockTTL,
+		prefix:  config.Prefix,
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultTTL
+	}
+	if s.lockTTL <= 0 {
+		s.lockTTL = defaultLockTTL
+	}
+	if s.prefix == "" {
+		s.prefix = defaultPrefix
+	}
+	return s
+}
+
+// Begin reserves key. It returns a nil response and the token of the
+// reservation when the caller holds it and must run the operation, then call
+// Complete or Release with the token. A non-nil response is the one stored
+// by the call that completed the key. Concurrent calls get ErrInProgress.
+func (s *Store) Begin(ctx context.Context, key string) (response []byte, token string, err error) {
+	token = uuid.NewString()
+	value, err := beginScript.Run(ctx, s.client, []string{s.prefix + key},
+		statePending+token,
+		s.lockTTL.Milliseconds(),
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, token, nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !strings.HasPrefix(value, stateCompleted) {
+		return nil, "", ErrInProgress
+	}
+	// An empty response is still a response.
+	return []byte(value[len(stateCompleted):]), "", nil
+}
+
+// Complete stores the response of key, replayed by Begin from now on. It
+// fails with ErrInProgress when the reservation of token expired and another
+// call took over the key.
+func (s *Store) Complete(ctx context.Context, key, token string, response []byte) error {
+	stored, err := completeScript.Run(ctx, s.client, []string{s.prefix + key},
+		stateCompleted,
+		response,
+		s.ttl.Milliseconds(),
+		statePending+token,
+	).Int()
+	if err != nil {
+		return err
+	}
+	switch stored {
+	case 0:
+		return ErrAlreadyCompleted
+	case -1:
+		return ErrInProgress
+	}
+	return nil
+}
+
+// Release drops the reservation of token on key so the operation can be
+// retried, e.g. after it failed. The reservation another call took over
+// since is left alone.
+func (s *Store) Release(ctx context.Context, key, token string) error {
+	return releaseScript.Run(ctx, s.client, []string{s.prefix + key}, statePending+token).Err()
+}
+
+// Do runs fn once per key and returns its response, or the stored response
+// of a previous call. The reservation is released when fn fails, so the
+// error is not replayed.
+func (s *Store) Do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	response, token, err := s.Begin(ctx, key)
+	if err != nil || response != nil {
+		return response, err
+	}
+
+	response, err = fn(ctx)
+	if err != nil {
+		if releaseErr := s.Release(ctx, key, token); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+	if response == nil {
+		response = []byte{}
+	}
+
+	return response, s.Complete(ctx, key, token, response)
+}
@@ -0,0 +1,133 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewStore(client, Config{TTL: time.Hour, LockTTL: time.Minute}), srv
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	response, token, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Nil(t, response, "first call holds the reservation")
+	assert.NotEmpty(t, token)
+	assert.Equal(t, time.Minute, srv.TTL("idempotency:charge-1"))
+
+	_, _, err = store.Begin(ctx, "charge-1")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, store.Complete(ctx, "charge-1", token, []byte(`{"id":"ch_1"}`)))
+	assert.Equal(t, time.Hour, srv.TTL("idempotency:charge-1"))
+	assert.ErrorIs(t, store.Complete(ctx, "charge-1", token, []byte(`{"id":"ch_2"}`)), ErrAlreadyCompleted)
+
+	response, token, err = store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"ch_1"}`, string(response))
+	assert.Empty(t, token)
+
+	require.NoError(t, store.Release(ctx, "charge-1", token))
+	response, _, err = store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":"ch_1"}`, string(response), "completed keys are not released")
+}
+
+func TestStoreReservationExpires(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	_, _, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+
+	srv.FastForward(2 * time.Minute)
+	response, _, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Nil(t, response)
+}
+
+func TestStoreReservationTakenOver(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	_, expired, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	srv.FastForward(2 * time.Minute)
+	_, token, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Release(ctx, "charge-1", expired))
+	_, _, err = store.Begin(ctx, "charge-1")
+	assert.ErrorIs(t, err, ErrInProgress, "the expired call does not release the new reservation")
+
+	assert.ErrorIs(t, store.Complete(ctx, "charge-1", expired, []byte("stale")), ErrInProgress)
+	require.NoError(t, store.Complete(ctx, "charge-1", token, []byte("ok")))
+	response, _, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(response))
+}
+
+func TestStoreCompleteExpiredReservation(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	_, token, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	srv.FastForward(2 * time.Minute)
+
+	require.NoError(t, store.Complete(ctx, "charge-1", token, []byte("ok")))
+	response, _, err := store.Begin(ctx, "charge-1")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(response))
+}
+
+func TestStoreDo(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+	errCharge := errors.New("card declined")
+
+	calls := 0
+	fail := true
+	fn := func(context.Context) ([]byte, error) {
+		calls++
+		if fail {
+			return nil, errCharge
+		}
+		return []byte("ok"), nil
+	}
+
+	_, err := store.Do(ctx, "charge-1", fn)
+	assert.ErrorIs(t, err, errCharge)
+
+	fail = false
+	for i := 0; i < 2; i++ {
+		response, err := store.Do(ctx, "charge-1", fn)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(response))
+	}
+	assert.Equal(t, 2, calls, "failures are retried, successes replayed")
+
+	response, err := store.Do(ctx, "empty", func(context.Context) ([]byte, error) { return nil, nil })
+	require.NoError(t, err)
+	assert.Empty(t, response)
+	response, _, err = store.Begin(ctx, "empty")
+	require.NoError(t, err)
+	assert.NotNil(t, response, "empty responses are replayed too")
+}
@@ -0,0 +1,192 @@
+// Package leaderboard implements leaderboards on Redis sorted sets.
+package leaderboard
+
+import (
+	"context"
+	"errors"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrMemberNotFound is returned when a member has no score.
+var ErrMemberNotFound = errors.New("leaderboard: member not found")
+
+// Entry is a ranked member.
+type Entry[M any] struct {
+	Member M
+	Score  float64
+	// Rank is 1 for the best score.
+	Rank int64
+}
+
+// Option customizes a Leaderboard.
+type Option func(*options)
+
+type options struct {
+	ascending bool
+}
+
+// Ascending ranks the lowest scores first, e.g. for lap times.
+func Ascending() Option {
+	return func(o *options) {
+		o.ascending = true
+	}
+}
+
+// Leaderboard ranks members of type M, by default highest score first.
+// String members are stored as is, other types JSON encoded, so struct
+// members must always encode to the same JSON.
+type Leaderboard[M any] struct {
+	client    redis.UniversalClient
+	key       string
+	ascending bool
+}
+
+// New creates a Leaderboard stored under key.
+func New[M any](client redis.UniversalClient, key string, opts ...Option) *Leaderboard[M] {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &Leaderboard[M]{
+		client:    client,
+		key:       key,
+		ascending: o.ascending,
+	}
+}
+
+// AddScore adds delta to the score of member, creating it when missing, and
+// returns the new score.
+func (l *Leaderboard[M]) AddScore(ctx context.Context, member M, delta float64) (float64, error) {
+	encoded, err := encodeMember(member)
+	if err != nil {
+		return 0, err
+	}
+	return l.client.ZIncrBy(ctx, l.key, delta, encoded).Result()
+}
+
+// SetScore sets the score of member.
+func (l *Leaderboard[M]) SetScore(ctx context.Context, member M, score float64) error {
+	encoded, err := encodeMember(member)
+	if err != nil {
+		return err
+	}
+	return l.client.ZAdd(ctx, l.key, redis.Z{Score: score, Member: encoded}).Err()
+}
+
+// Remove removes members from the leaderboard.
+func (l *Leaderboard[M]) Remove(ctx context.Context, members ...M) error {
+	if len(members) == 0 {
+		return nil
+	}
+	encoded := make([]any, len(members))
+	for i, member := range members {
+		m, err := encodeMember(member)
+		if err != nil {
+			return err
+		}
+		encoded[i] = m
+	}
+	return l.client.ZRem(ctx, l.key, encoded...).Err()
+}
+
+// Count returns the number of ranked members.
+func (l *Leaderboard[M]) Count(ctx context.Context) (int64, error) {
+	return l.client.ZCard(ctx, l.key).Result()
+}
+
+// TopN returns the n best ranked members.
+func (l *Leaderboard[M]) TopN(ctx context.Context, n int) ([]Entry[M], error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return l.rangeByRank(ctx, 0, int64(n)-1)
+}
+
+// RankOf returns the entry of member, or ErrMemberNotFound.
+func (l *Leaderboard[M]) RankOf(ctx context.Context, member M) (Entry[M], error) {
+	encoded, err := encodeMember(member)
+	if err != nil {
+		return Entry[M]{}, err
+	}
+
+	var rank *redis.IntCmd
+	var score *redis.FloatCmd
+	_, err = l.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		if l.ascending {
+			rank = pipe.ZRank(ctx, l.key, encoded)
+		} else {
+			rank = pipe.ZRevRank(ctx, l.key, encoded)
+		}
+		score = pipe.ZScore(ctx, l.key, encoded)
+		return nil
+	})
+	if errors.Is(err, redis.Nil) {
+		return Entry[M]{}, ErrMemberNotFound
+	}
+	if err != nil {
+		return Entry[M]{}, err
+	}
+
+	return Entry[M]{Member: member, Score: score.Val(), Rank: rank.Val() + 1}, nil
+}
+
+// AroundMember returns member with up to radius members ranked right above
+// and below it, e.g. to show a player where they stand.
+func (l *Leaderboard[M]) AroundMember(ctx context.Context, member M, radius int) ([]Entry[M], error) {
+	entry, err := l.RankOf(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+
+	start := max(entry.Rank-1-int64(radius), 0)
+	return l.rangeByRank(ctx, start, entry.Rank-1+int64(radius))
+}
+
+func (l *Leaderboard[M]) rangeByRank(ctx context.Context, start, stop int64) ([]Entry[M], error) {
+	var (
+		items []redis.Z
+		err   error
+	)
+	if l.ascending {
+		items, err = l.client.ZRangeWithScores(ctx, l.key, start, stop).Result()
+	} else {
+		items, err = l.client.ZRevRangeWithScores(ctx, l.key, start, stop).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry[M], 0, len(items))
+	for i, item := range items {
+		member, err := decodeMember[M](item.Member.(string))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry[M]{
+			Member: member,
+			Score:  item.Score,
+			Rank:   start + int64(i) + 1,
+		})
+	}
+
+	return entries, nil
+}
+
+func encodeMember[M any](member M) (string, error) {
+	if s, ok := any(member).(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(member)
+	return string(data), err
+}
+
+func decodeMember[M any](encoded string) (member M, err error) {
+	if s, ok := any(&member).(*string); ok {
+		*s = encoded
+		return member, nil
+	}
+	err = json.Unmarshal([]byte(encoded), &member)
+	return member, err
+}
@@ -0,0 +1,106 @@
+package leaderboard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type player struct {
+	ID     string `json:"id"`
+	Region string `json:"region"`
+}
+
+func newTestRedis(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func seed[M any](t *testing.T, board *Leaderboard[M], scores map[float64]M) {
+	t.Helper()
+	for score, member := range scores {
+		require.NoError(t, board.SetScore(context.Background(), member, score))
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	board := New[string](newTestRedis(t), "weekly")
+	seed(t, board, map[float64]string{10: "ana", 30: "budi", 20: "citra", 40: "dewi", 50: "eka"})
+
+	score, err := board.AddScore(ctx, "ana", 5)
+	require.NoError(t, err)
+	assert.Equal(t, float64(15), score)
+
+	top, err := board.TopN(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry[string]{
+		{Member: "eka", Score: 50, Rank: 1},
+		{Member: "dewi", Score: 40, Rank: 2},
+	}, top)
+
+	entry, err := board.RankOf(ctx, "citra")
+	require.NoError(t, err)
+	assert.Equal(t, Entry[string]{Member: "citra", Score: 20, Rank: 4}, entry)
+
+	around, err := board.AroundMember(ctx, "citra", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry[string]{
+		{Member: "budi", Score: 30, Rank: 3},
+		{Member: "citra", Score: 20, Rank: 4},
+		{Member: "ana", Score: 15, Rank: 5},
+	}, around)
+
+	around, err = board.AroundMember(ctx, "eka", 1)
+	require.NoError(t, err)
+	assert.Len(t, around, 2, "the window is clipped at the top")
+
+	require.NoError(t, board.Remove(ctx, "citra"))
+	_, err = board.RankOf(ctx, "citra")
+	assert.ErrorIs(t, err, ErrMemberNotFound)
+
+	count, err := board.Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), count)
+}
+
+func TestLeaderboardAscending(t *testing.T) {
+	ctx := context.Background()
+	board := New[string](newTestRedis(t), "lap-times", Ascending())
+	seed(t, board, map[float64]string{61.2: "ana", 59.8: "budi", 60.5: "citra"})
+
+	top, err := board.TopN(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry[string]{{Member: "budi", Score: 59.8, Rank: 1}}, top)
+
+	entry, err := board.RankOf(ctx, "ana")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), entry.Rank)
+}
+
+func TestLeaderboardTypedMembers(t *testing.T) {
+	ctx := context.Background()
+	board := New[player](newTestRedis(t), "regional")
+	seed(t, board, map[float64]player{
+		100: {ID: "1", Region: "id"},
+		200: {ID: "2", Region: "sg"},
+	})
+
+	top, err := board.TopN(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, top, 2)
+	assert.Equal(t, player{ID: "2", Region: "sg"}, top[0].Member)
+
+	entry, err := board.RankOf(ctx, player{ID: "1", Region: "id"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), entry.Rank)
+}
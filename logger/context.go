@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	fieldsContextKey
+)
+
+// ContextWithLogger returns a context carrying l, returned by FromContext.
+func ContextWithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// ContextWithFields returns a context whose loggers attach fields to every
+// entry, on top of the fields already set on ctx. platigo.ContextWithRequestID
+// sets the requestID field this way.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+	for k, v := range fieldsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+// ContextWithTenantID returns a context whose loggers attach the tenantID
+// field.
+func ContextWithTenantID(ctx context.Context, id string) context.Context {
+	return ContextWithFields(ctx, Fields{"tenantID": id})
+}
+
+// ContextWithUserID returns a context whose loggers attach the userID field.
+func ContextWithUserID(ctx context.Context, id string) context.Context {
+	return ContextWithFields(ctx, Fields{"userID": id})
+}
+
+// FieldsFromContext returns the fields set with ContextWithFields, along
+// with the traceID and spanID of the span of ctx.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields := Fields{}
+	for k, v := range fieldsFromContext(ctx) {
+		fields[k] = v
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		fields["traceID"] = sc.TraceID().String()
+		if sc.HasSpanID() {
+			fields["spanID"] = sc.SpanID().String()
+		}
+	}
+	return fields
+}
+
+func fieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsContextKey).(Fields)
+	return fields
+}
+
+// FromContext returns the logger set with ContextWithLogger, or a no-op
+// logger, attaching the fields of FieldsFromContext:
+//
+//	ctx = logger.ContextWithLogger(ctx, log)
+//	ctx = logger.ContextWithUserID(ctx, claims.Subject)
+//	logger.FromContext(ctx).Info("Order created")
+func FromContext(ctx context.Context) Logger {
+	l, _ := ctx.Value(loggerContextKey).(Logger)
+	if l == nil {
+		l = Nop()
+	}
+	return WithContext(ctx, l)
+}
+
+// WithContext returns l attaching the fields of FieldsFromContext. The
+// platigo clients log the calls made with a context through it.
+func WithContext(ctx context.Context, l Logger) Logger {
+	if fields := FieldsFromContext(ctx); len(fields) > 0 {
+		return l.With(fields)
+	}
+	return l
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestFieldsFromContext(t *testing.T) {
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want Fields
+	}{
+		{
+			name: "empty",
+			ctx:  context.Background(),
+			want: Fields{},
+		},
+		{
+			name: "tenant and user",
+			ctx:  ContextWithUserID(ContextWithTenantID(context.Background(), "acme"), "alice"),
+			want: Fields{"tenantID": "acme", "userID": "alice"},
+		},
+		{
+			name: "fields override",
+			ctx:  ContextWithFields(ContextWithFields(context.Background(), Fields{"a": 1, "b": 1}), Fields{"b": 2}),
+			want: Fields{"a": 1, "b": 2},
+		},
+		{
+			name: "span",
+			ctx:  ContextWithUserID(spanCtx, "alice"),
+			want: Fields{
+				"userID":  "alice",
+				"traceID": "4bf92f3577b34da6a3ce929d0e0e4736",
+				"spanID":  "00f067aa0ba902b7",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FieldsFromContext(tt.ctx))
+		})
+	}
+}
+
+func TestContextWithFieldsDoesNotAlterParent(t *testing.T) {
+	parent := ContextWithFields(context.Background(), Fields{"a": 1})
+	_ = ContextWithFields(parent, Fields{"a": 2})
+
+	assert.Equal(t, Fields{"a": 1}, FieldsFromContext(parent))
+}
+
+func TestFromContext(t *testing.T) {
+	log := newRecordLogger()
+	ctx := ContextWithTenantID(ContextWithLogger(context.Background(), log), "acme")
+
+	l := FromContext(ctx)
+	l.Info("hello")
+
+	assert.Equal(t, []string{"info:hello"}, *log.entries)
+	assert.Equal(t, Fields{"tenantID": "acme"}, l.(*recordLogger).fields)
+}
+
+func TestFromContextWithoutLogger(t *testing.T) {
+	l := FromContext(ContextWithUserID(context.Background(), "alice"))
+
+	assert.NotPanics(t, func() { l.Info("hello") })
+}
+
+func TestWithContext(t *testing.T) {
+	log := newRecordLogger()
+
+	assert.Same(t, log, WithContext(context.Background(), log))
+
+	l := WithContext(ContextWithUserID(context.Background(), "alice"), log)
+	assert.Equal(t, Fields{"userID": "alice"}, l.(*recordLogger).fields)
+}
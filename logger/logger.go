@@ -0,0 +1,114 @@
+package logger
+
+import "strings"
+
+// Fields is a set of structured key/value pairs attached to a log entry.
+type Fields map[string]any
+
+// Level is the severity of a log entry. The zero value is InfoLevel.
+type Level int8
+
+const (
+	DebugLevel Level = iota - 1
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// String returns the lower-case name of the level.
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a Level.
+// Unknown names fall back to InfoLevel.
+func ParseLevel(name string) Level {
+	switch strings.ToLower(name) {
+	case "debug":
+		return DebugLevel
+	case "warn", "warning":
+		return WarnLevel
+	case "error":
+		return ErrorLevel
+	default:
+		return InfoLevel
+	}
+}
+
+// Logger is the logging abstraction used by platigo. Adapters for concrete
+// logging libraries live in the sub packages of this package.
+type Logger interface {
+	// With returns a Logger that attaches fields to every entry.
+	With(fields Fields) Logger
+
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+}
+
+type nopLogger struct{}
+
+// Nop returns a Logger that discards every entry.
+func Nop() Logger {
+	return nopLogger{}
+}
+
+func (n nopLogger) With(Fields) Logger { return n }
+func (nopLogger) Debug(string)         {}
+func (nopLogger) Info(string)          {}
+func (nopLogger) Warn(string)          {}
+func (nopLogger) Error(string)         {}
+
+type leveledLogger struct {
+	next  Logger
+	level Level
+}
+
+// WithLevel returns a Logger that drops entries below level before they
+// reach l, and masks the sensitive fields with the default Masker.
+func WithLevel(l Logger, level Level) Logger {
+	if l == nil {
+		return Nop()
+	}
+	return &leveledLogger{next: l, level: level}
+}
+
+func (l *leveledLogger) With(fields Fields) Logger {
+	return &leveledLogger{next: l.next.With(defaultMasker.Fields(fields)), level: l.level}
+}
+
+func (l *leveledLogger) Debug(msg string) {
+	if l.level <= DebugLevel {
+		l.next.Debug(msg)
+	}
+}
+
+func (l *leveledLogger) Info(msg string) {
+	if l.level <= InfoLevel {
+		l.next.Info(msg)
+	}
+}
+
+func (l *leveledLogger) Warn(msg string) {
+	if l.level <= WarnLevel {
+		l.next.Warn(msg)
+	}
+}
+
+func (l *leveledLogger) Error(msg string) {
+	if l.level <= ErrorLevel {
+		l.next.Error(msg)
+	}
+}
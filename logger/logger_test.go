@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordLogger struct {
+	entries *[]string
+	fields  Fields
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{entries: &[]string{}, fields: Fields{}}
+}
+
+func (r *recordLogger) With(fields Fields) Logger {
+	merged := Fields{}
+	for k, v := range r.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &recordLogger{entries: r.entries, fields: merged}
+}
+
+func (r *recordLogger) Debug(msg string) { *r.entries = append(*r.entries, "debug:"+msg) }
+func (r *recordLogger) Info(msg string)  { *r.entries = append(*r.entries, "info:"+msg) }
+func (r *recordLogger) Warn(msg string)  { *r.entries = append(*r.entries, "warn:"+msg) }
+func (r *recordLogger) Error(msg string) { *r.entries = append(*r.entries, "error:"+msg) }
+
+func TestWithLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+		want  []string
+	}{
+		{
+			name:  "debug",
+			level: DebugLevel,
+			want:  []string{"debug:d", "info:i", "warn:w", "error:e"},
+		},
+		{
+			name:  "default info",
+			level: InfoLevel,
+			want:  []string{"info:i", "warn:w", "error:e"},
+		},
+		{
+			name:  "error only",
+			level: ErrorLevel,
+			want:  []string{"error:e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecordLogger()
+			l := WithLevel(rec, tt.level).With(Fields{"k": "v"})
+
+			l.Debug("d")
+			l.Info("i")
+			l.Warn("w")
+			l.Error("e")
+
+			assert.Equal(t, tt.want, *rec.entries)
+		})
+	}
+}
+
+func TestWithLevelNil(t *testing.T) {
+	l := WithLevel(nil, DebugLevel)
+	assert.NotNil(t, l)
+	assert.NotPanics(t, func() { l.With(Fields{"k": "v"}).Info("msg") })
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, DebugLevel, ParseLevel("DEBUG"))
+	assert.Equal(t, WarnLevel, ParseLevel("warning"))
+	assert.Equal(t, ErrorLevel, ParseLevel("error"))
+	assert.Equal(t, InfoLevel, ParseLevel("unknown"))
+	assert.Equal(t, "warn", WarnLevel.String())
+}
@@ -0,0 +1,28 @@
+package logrusadapter
+
+import (
+	"github.com/bagastri07/platigo/logger"
+	"github.com/sirupsen/logrus"
+)
+
+type logrusLogger struct {
+	entry logrus.FieldLogger
+}
+
+// New wraps a logrus logger or entry as a logger.Logger. A nil value uses
+// the logrus standard logger.
+func New(l logrus.FieldLogger) logger.Logger {
+	if l == nil {
+		l = logrus.StandardLogger()
+	}
+	return &logrusLogger{entry: l}
+}
+
+func (l *logrusLogger) With(fields logger.Fields) logger.Logger {
+	return &logrusLogger{entry: l.entry.WithFields(logrus.Fields(fields))}
+}
+
+func (l *logrusLogger) Debug(msg string) { l.entry.Debug(msg) }
+func (l *logrusLogger) Info(msg string)  { l.entry.Info(msg) }
+func (l *logrusLogger) Warn(msg string)  { l.entry.Warn(msg) }
+func (l *logrusLogger) Error(msg string) { l.entry.Error(msg) }
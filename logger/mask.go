@@ -0,0 +1,269 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MaskedValue replaces the masked values.
+const MaskedValue = "[REDACTED]"
+
+// maxMaskDepth bounds the nesting walked by Mask, which cuts cycles.
+const maxMaskDepth = 32
+
+// DefaultMaskKeys are the keys masked by every Masker. A key is masked when
+// its name ends with one of them, ignoring case, underscores and dashes, so
+// that "token" also masks accessToken and refresh_token but not tokenURL.
+var DefaultMaskKeys = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie", "credential"}
+
+var (
+	defaultMasker = NewMasker()
+
+	errorType         = reflect.TypeFor[error]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// Masker hides the sensitive values of the data written to the logs: the
+// struct fields tagged mask:"true", and the struct fields and map entries
+// whose name matches one of its keys.
+type Masker struct {
+	keys []string
+}
+
+// NewMasker creates a Masker masking keys on top of DefaultMaskKeys.
+func NewMasker(keys ...string) *Masker {
+	m := &Masker{}
+	for _, key := range append(DefaultMaskKeys, keys...) {
+		if key = normalizeMaskKey(key); key != "" {
+			m.keys = append(m.keys, key)
+		}
+	}
+	return m
+}
+
+// Mask returns a copy of v with the sensitive values replaced by
+// MaskedValue, marshaling to the same JSON as v otherwise. Structs become
+// maps keyed by their JSON names, and errors and values implementing
+// json.Marshaler or encoding.TextMarshaler are kept as is.
+func (m *Masker) Mask(v any) any {
+	return m.mask(reflect.ValueOf(v), 0)
+}
+
+// Fields returns a copy of fields with the sensitive values masked.
+func (m *Masker) Fields(fields Fields) Fields {
+	masked := make(Fields, len(fields))
+	for key, value := range fields {
+		if m.sensitive(key) {
+			masked[key] = MaskedValue
+		} else {
+			masked[key] = m.Mask(value)
+		}
+	}
+	return masked
+}
+
+// Mask masks v with the default Masker.
+func Mask(v any) any {
+	return defaultMasker.Mask(v)
+}
+
+func (m *Masker) sensitive(name string) bool {
+	name = normalizeMaskKey(name)
+	for _, key := range m.keys {
+		if strings.HasSuffix(name, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeMaskKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key))
+}
+
+func (m *Masker) mask(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > maxMaskDepth {
+		return nil
+	}
+	if t := v.Type(); t.Implements(errorType) || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return m.mask(v.Elem(), depth+1)
+	case reflect.Struct:
+		masked := map[string]any{}
+		m.maskStruct(v, masked, depth)
+		return masked
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		return m.maskMap(v, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface()
+		}
+		return m.maskSlice(v, depth)
+	}
+	if v.CanInterface() {
+		return v.Interface()
+	}
+	return nil
+}
+
+// maskMap masks the entries of a map keyed by strings. The map keeps its
+// type when the masked values fit in it, like a map[string]string, and
+// becomes a map[string]any otherwise.
+func (m *Masker) maskMap(v reflect.Value, depth int) any {
+	elem := v.Type().Elem()
+	typed := reflect.MakeMapWithSize(v.Type(), v.Len())
+	untyped := make(map[string]any, v.Len())
+	fits := true
+	for iter := v.MapRange(); iter.Next(); {
+		key := iter.Key().String()
+		var masked any = MaskedValue
+		if !m.sensitive(key) {
+			masked = m.mask(iter.Value(), depth+1)
+		}
+		untyped[key] = masked
+		if fits {
+			value, ok := fitValue(masked, elem)
+			fits = ok
+			if ok {
+				typed.SetMapIndex(iter.Key(), value)
+			}
+		}
+	}
+	if fits {
+		return typed.Interface()
+	}
+	return untyped
+}
+
+// maskSlice masks the elements of a slice or array, which keeps its type
+// when the masked values fit in it and becomes a []any otherwise.
+func (m *Masker) maskSlice(v reflect.Value, depth int) any {
+	elem := v.Type().Elem()
+	typed := reflect.MakeSlice(reflect.SliceOf(elem), v.Len(), v.Len())
+	untyped := make([]any, v.Len())
+	fits := true
+	for i := range untyped {
+		untyped[i] = m.mask(v.Index(i), depth+1)
+		if fits {
+			value, ok := fitValue(untyped[i], elem)
+			fits = ok
+			if ok {
+				typed.Index(i).Set(value)
+			}
+		}
+	}
+	if fits {
+		return typed.Interface()
+	}
+	return untyped
+}
+
+// fitValue converts a masked value to t, masked strings included.
+func fitValue(masked any, t reflect.Type) (reflect.Value, bool) {
+	if masked == nil {
+		return reflect.Zero(t), canBeNil(t)
+	}
+	value := reflect.ValueOf(masked)
+	if value.Type().AssignableTo(t) {
+		return value, true
+	}
+	if value.Kind() == reflect.String && t.Kind() == reflect.String {
+		return value.Convert(t), true
+	}
+	return reflect.Value{}, false
+}
+
+func canBeNil(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// maskStruct adds the exported fields of v to masked under their JSON
+// names, flattening the embedded structs like encoding/json.
+func (m *Masker) maskStruct(v reflect.Value, masked map[string]any, depth int) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		value := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			if value.Kind() == reflect.Pointer {
+				if value.IsNil() {
+					continue
+				}
+				value = value.Elem()
+			}
+			if value.Kind() == reflect.Struct {
+				m.maskStruct(value, masked, depth+1)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if strings.Contains(opts, "omitempty") && value.IsZero() {
+			continue
+		}
+
+		if field.Tag.Get("mask") == "true" || m.sensitive(name) {
+			masked[name] = MaskedValue
+		} else {
+			masked[name] = m.mask(value, depth+1)
+		}
+	}
+}
+
+type maskedLogger struct {
+	next   Logger
+	masker *Masker
+}
+
+// WithMasking returns a Logger masking the sensitive fields with masker
+// before they reach l. A nil masker uses the default keys.
+func WithMasking(l Logger, masker *Masker) Logger {
+	if l == nil {
+		return Nop()
+	}
+	if masker == nil {
+		masker = defaultMasker
+	}
+	return &maskedLogger{next: l, masker: masker}
+}
+
+func (l *maskedLogger) With(fields Fields) Logger {
+	return &maskedLogger{next: l.next.With(l.masker.Fields(fields)), masker: l.masker}
+}
+
+func (l *maskedLogger) Debug(msg string) { l.next.Debug(msg) }
+func (l *maskedLogger) Info(msg string)  { l.next.Info(msg) }
+func (l *maskedLogger) Warn(msg string)  { l.next.Warn(msg) }
+func (l *maskedLogger) Error(msg string) { l.next.Error(msg) }
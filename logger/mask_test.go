@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maskAudit struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type maskCredentials struct {
+	maskAudit
+	Username string            `json:"username"`
+	Password string            `json:"password"`
+	PIN      string            `json:"pin" mask:"true"`
+	Notes    string            `json:"notes,omitempty"`
+	Ignored  string            `json:"-"`
+	Headers  map[string]string `json:"headers"`
+	Tags     []string          `json:"tags"`
+	Nested   *maskCredentials  `json:"nested,omitempty"`
+	internal string
+}
+
+func TestMask(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		masker *Masker
+		value  any
+		want   string
+	}{
+		{
+			name:   "scalar",
+			masker: NewMasker(),
+			value:  42,
+			want:   `42`,
+		},
+		{
+			name:   "nil",
+			masker: NewMasker(),
+			value:  nil,
+			want:   `null`,
+		},
+		{
+			name:   "struct tags and default keys",
+			masker: NewMasker(),
+			value: &maskCredentials{
+				maskAudit: maskAudit{CreatedAt: createdAt},
+				Username:  "alice",
+				Password:  "hunter2",
+				PIN:       "1234",
+				Ignored:   "ignored",
+				Headers:   map[string]string{"Authorization": "Bearer abc", "Accept": "application/json"},
+				Tags:      []string{"a", "b"},
+				Nested:    &maskCredentials{Username: "bob", Password: "secret"},
+				internal:  "internal",
+			},
+			want: `{"createdAt":"2024-01-02T03:04:05Z","username":"alice","password":"[REDACTED]","pin":"[REDACTED]",` +
+				`"headers":{"Accept":"application/json","Authorization":"[REDACTED]"},"tags":["a","b"],` +
+				`"nested":{"createdAt":"0001-01-01T00:00:00Z","username":"bob","password":"[REDACTED]","pin":"[REDACTED]","headers":null,"tags":null}}`,
+		},
+		{
+			name:   "map keys",
+			masker: NewMasker(),
+			value:  map[string]any{"access_token": "abc", "client-secret": "def", "tokenURL": "https://auth", "items": []any{map[string]any{"apiKey": "k"}}},
+			want:   `{"access_token":"[REDACTED]","client-secret":"[REDACTED]","items":[{"apiKey":"[REDACTED]"}],"tokenURL":"https://auth"}`,
+		},
+		{
+			name:   "custom keys",
+			masker: NewMasker("ssn"),
+			value:  map[string]string{"userSSN": "123-45-6789", "name": "alice"},
+			want:   `{"name":"alice","userSSN":"[REDACTED]"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.masker.Mask(tt.value))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
+
+func TestMaskKeepsTypes(t *testing.T) {
+	errFailed := errors.New("failed")
+	headers := map[string]string{"Accept": "application/json"}
+
+	assert.Equal(t, headers, Mask(headers))
+	assert.Equal(t, []int{1, 2}, Mask([]int{1, 2}))
+	assert.Equal(t, errFailed, Mask(errFailed))
+	assert.Equal(t, time.Second, Mask(time.Second))
+}
+
+func TestMaskCycle(t *testing.T) {
+	value := map[string]any{}
+	value["self"] = value
+	assert.NotPanics(t, func() { _, _ = json.Marshal(Mask(value)) })
+}
+
+func TestWithMasking(t *testing.T) {
+	log := newRecordLogger()
+	masked := WithMasking(log, NewMasker("ssn")).With(Fields{"ssn": "123", "password": "p", "user": "alice"})
+	masked.Info("signed in")
+
+	assert.Equal(t, []string{"info:signed in"}, *log.entries)
+	assert.Equal(t, Fields{"ssn": MaskedValue, "password": MaskedValue, "user": "alice"}, masked.(*maskedLogger).next.(*recordLogger).fields)
+
+	leveled := WithLevel(log, InfoLevel).With(Fields{"refreshToken": "r", "user": "alice"})
+	assert.Equal(t, Fields{"refreshToken": MaskedValue, "user": "alice"}, leveled.(*leveledLogger).next.(*recordLogger).fields)
+}
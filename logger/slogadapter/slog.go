@@ -0,0 +1,34 @@
+package slogadapter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// New wraps a slog logger as a logger.Logger. A nil value uses
+// slog.Default().
+func New(l *slog.Logger) logger.Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) With(fields logger.Fields) logger.Logger {
+	args := make([]any, 0, len(fields))
+	for k, v := range fields {
+		args = append(args, slog.Any(k, v))
+	}
+	return &slogLogger{l: s.l.With(args...)}
+}
+
+func (s *slogLogger) Debug(msg string) { s.l.Log(context.Background(), slog.LevelDebug, msg) }
+func (s *slogLogger) Info(msg string)  { s.l.Log(context.Background(), slog.LevelInfo, msg) }
+func (s *slogLogger) Warn(msg string)  { s.l.Log(context.Background(), slog.LevelWarn, msg) }
+func (s *slogLogger) Error(msg string) { s.l.Log(context.Background(), slog.LevelError, msg) }
@@ -0,0 +1,31 @@
+package zapadapter
+
+import (
+	"github.com/bagastri07/platigo/logger"
+	"go.uber.org/zap"
+)
+
+type zapLogger struct {
+	l *zap.Logger
+}
+
+// New wraps a zap logger as a logger.Logger. A nil value uses zap.L().
+func New(l *zap.Logger) logger.Logger {
+	if l == nil {
+		l = zap.L()
+	}
+	return &zapLogger{l: l}
+}
+
+func (z *zapLogger) With(fields logger.Fields) logger.Logger {
+	zf := make([]zap.Field, 0, len(fields))
+	for k, v := range fields {
+		zf = append(zf, zap.Any(k, v))
+	}
+	return &zapLogger{l: z.l.With(zf...)}
+}
+
+func (z *zapLogger) Debug(msg string) { z.l.Debug(msg) }
+func (z *zapLogger) Info(msg string)  { z.l.Info(msg) }
+func (z *zapLogger) Warn(msg string)  { z.l.Warn(msg) }
+func (z *zapLogger) Error(msg string) { z.l.Error(msg) }
@@ -0,0 +1,29 @@
+package zerologadapter
+
+import (
+	"github.com/bagastri07/platigo/logger"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+type zerologLogger struct {
+	l zerolog.Logger
+}
+
+// New wraps a zerolog logger as a logger.Logger. A nil value uses the
+// global logger of zerolog/log.
+func New(l *zerolog.Logger) logger.Logger {
+	if l == nil {
+		l = &log.Logger
+	}
+	return &zerologLogger{l: *l}
+}
+
+func (z *zerologLogger) With(fields logger.Fields) logger.Logger {
+	return &zerologLogger{l: z.l.With().Fields(map[string]any(fields)).Logger()}
+}
+
+func (z *zerologLogger) Debug(msg string) { z.l.Debug().Msg(msg) }
+func (z *zerologLogger) Info(msg string)  { z.l.Info().Msg(msg) }
+func (z *zerologLogger) Warn(msg string)  { z.l.Warn().Msg(msg) }
+func (z *zerologLogger) Error(msg string) { z.l.Error().Msg(msg) }
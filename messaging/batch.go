@@ -0,0 +1,208 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+const (
+	defaultBatchMaxSize    = 100
+	defaultBatchMaxLatency = 100 * time.Millisecond
+)
+
+var errBatcherClosed = errors.New("messaging: batcher is closed")
+
+// BatchPublisher publishes several messages at once, in fewer round trips
+// than as many Publish calls. Messages failing on their own are reported
+// with a *BatchError, the others are published all the same.
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, msgs []*Message) error
+}
+
+// MessageError is the failure of one message of a batch, identified by its
+// index in the batch.
+type MessageError struct {
+	Index int
+	Err   error
+}
+
+// BatchError reports the messages of a batch that failed to publish.
+//
+//	var batchErr *messaging.BatchError
+//	if errors.As(err, &batchErr) {
+//		retry = batchErr.Failed(msgs)
+//	}
+type BatchError struct {
+	Errors []MessageError
+}
+
+func (e *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "messaging: %d messages of the batch failed", len(e.Errors))
+	for _, err := range e.Errors {
+		fmt.Fprintf(&b, "; message %d: %v", err.Index, err.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failed messages, for errors.Is and
+// errors.As.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err.Err
+	}
+	return errs
+}
+
+// Failed returns the messages of msgs, the batch given to PublishBatch,
+// that failed.
+func (e *BatchError) Failed(msgs []*Message) []*Message {
+	failed := make([]*Message, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if err.Index >= 0 && err.Index < len(msgs) {
+			failed = append(failed, msgs[err.Index])
+		}
+	}
+	return failed
+}
+
+// NewBatchError returns a *BatchError for errs, or nil when there are none.
+func NewBatchError(errs []MessageError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BatchError{Errors: errs}
+}
+
+// BatcherConfig configures a Batcher.
+type BatcherConfig struct {
+	// MaxSize is the number of buffered messages triggering a publish, 100
+	// by default.
+	MaxSize int
+	// MaxLatency is the longest a message stays buffered, 100ms by default.
+	MaxLatency time.Duration
+	// OnError receives the batches failing to publish in the background,
+	// with the error of PublishBatch. Failed messages are only logged by
+	// default.
+	OnError func(msgs []*Message, err error)
+
+	// Logger receives the batcher logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Batcher buffers published messages and publishes them in batches, once
+// MaxSize messages are buffered or the oldest one waited MaxLatency. It
+// implements Publisher, for code publishing one message at a time such as
+// backfill jobs. Batches are published in order, one at a time.
+//
+// Publish only fails once the batcher is closed: publish failures are
+// reported to OnError. Close the batcher to publish the last messages.
+type Batcher struct {
+	publisher BatchPublisher
+	config    BatcherConfig
+	log       logger.Logger
+
+	// flushMu orders the batches, mu guards the buffer.
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	pending []*Message
+	timer   *time.Timer
+	closed  bool
+}
+
+// NewBatcher creates a Batcher publishing with publisher.
+func NewBatcher(publisher BatchPublisher, config BatcherConfig) *Batcher {
+	if config.MaxSize <= 0 {
+		config.MaxSize = defaultBatchMaxSize
+	}
+	if config.MaxLatency <= 0 {
+		config.MaxLatency = defaultBatchMaxLatency
+	}
+	return &Batcher{
+		publisher: publisher,
+		config:    config,
+		log:       logger.WithLevel(config.Logger, logger.InfoLevel),
+	}
+}
+
+// Publish buffers msg. The trace context of ctx is injected right away, as
+// the batch is published later without it. A full buffer is published
+// before Publish returns, which slows callers down to the publishing rate.
+func (b *Batcher) Publish(ctx context.Context, msg *Message) error {
+	InjectTraceContext(ctx, msg)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errBatcherClosed
+	}
+	b.pending = append(b.pending, msg)
+	if len(b.pending) == 1 {
+		b.timer = time.AfterFunc(b.config.MaxLatency, func() {
+			b.flushReporting(context.Background())
+		})
+	}
+	full := len(b.pending) >= b.config.MaxSize
+	b.mu.Unlock()
+
+	if full {
+		b.flushReporting(context.WithoutCancel(ctx))
+	}
+	return nil
+}
+
+// Flush publishes the buffered messages and returns the error of
+// PublishBatch.
+func (b *Batcher) Flush(ctx context.Context) error {
+	_, err := b.flush(ctx)
+	return err
+}
+
+// Close publishes the buffered messages and makes the next Publish calls
+// fail.
+func (b *Batcher) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.Flush(ctx)
+}
+
+func (b *Batcher) flushReporting(ctx context.Context) {
+	batch, err := b.flush(ctx)
+	if err == nil {
+		return
+	}
+	if b.config.OnError != nil {
+		b.config.OnError(batch, err)
+		return
+	}
+	b.log.With(logger.Fields{"messages": len(batch)}).Error(err.Error())
+}
+
+// flush publishes the buffered messages, taken while holding flushMu so
+// that batches are published in the order they were filled.
+func (b *Batcher) flush(ctx context.Context) ([]*Message, error) {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = nil
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil, nil
+	}
+	return batch, b.publisher.PublishBatch(ctx, batch)
+}
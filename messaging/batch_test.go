@@ -0,0 +1,109 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// batchRecorder records the published batches and fails the messages with
+// the value "fail".
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches [][]*Message
+}
+
+func (r *batchRecorder) PublishBatch(_ context.Context, msgs []*Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, msgs)
+
+	var errs []MessageError
+	for i, msg := range msgs {
+		if string(msg.Value) == "fail" {
+			errs = append(errs, MessageError{Index: i, Err: errors.New("rejected")})
+		}
+	}
+	return NewBatchError(errs)
+}
+
+func (r *batchRecorder) sizes() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sizes := make([]int, len(r.batches))
+	for i, batch := range r.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestBatchError(t *testing.T) {
+	rejected := errors.New("rejected")
+	msgs := []*Message{{Value: []byte("a")}, {Value: []byte("b")}, {Value: []byte("c")}}
+
+	assert.NoError(t, NewBatchError(nil))
+
+	err := NewBatchError([]MessageError{{Index: 0, Err: rejected}, {Index: 2, Err: rejected}})
+	var batchErr *BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []*Message{msgs[0], msgs[2]}, batchErr.Failed(msgs))
+	assert.ErrorIs(t, err, rejected)
+	assert.Equal(t, "messaging: 2 messages of the batch failed; message 0: rejected; message 2: rejected", err.Error())
+}
+
+func TestBatcherFlushesWhenFull(t *testing.T) {
+	recorder := &batchRecorder{}
+	batcher := NewBatcher(recorder, BatcherConfig{MaxSize: 2, MaxLatency: time.Hour})
+
+	for range 5 {
+		require.NoError(t, batcher.Publish(context.Background(), &Message{Value: []byte("a")}))
+	}
+	assert.Equal(t, []int{2, 2}, recorder.sizes())
+
+	require.NoError(t, batcher.Close(context.Background()))
+	assert.Equal(t, []int{2, 2, 1}, recorder.sizes())
+	assert.ErrorIs(t, batcher.Publish(context.Background(), &Message{}), errBatcherClosed)
+}
+
+func TestBatcherFlushesAfterMaxLatency(t *testing.T) {
+	recorder := &batchRecorder{}
+	failed := make(chan []*Message, 1)
+	batcher := NewBatcher(recorder, BatcherConfig{
+		MaxLatency: 10 * time.Millisecond,
+		OnError: func(msgs []*Message, err error) {
+			var batchErr *BatchError
+			if errors.As(err, &batchErr) {
+				failed <- batchErr.Failed(msgs)
+			}
+		},
+	})
+
+	ok, fail := &Message{Value: []byte("ok")}, &Message{Value: []byte("fail")}
+	require.NoError(t, batcher.Publish(context.Background(), ok))
+	require.NoError(t, batcher.Publish(context.Background(), fail))
+
+	select {
+	case msgs := <-failed:
+		assert.Equal(t, []*Message{fail}, msgs)
+	case <-time.After(time.Second):
+		t.Fatal("batch not published")
+	}
+	assert.Equal(t, []int{2}, recorder.sizes())
+}
+
+func TestBatcherInjectsTraceContext(t *testing.T) {
+	useTraceContextPropagator(t)
+	recorder := &batchRecorder{}
+	batcher := NewBatcher(recorder, BatcherConfig{})
+
+	msg := &Message{}
+	require.NoError(t, batcher.Publish(trace.ContextWithSpanContext(context.Background(), testSpanContext()), msg))
+	assert.NotEmpty(t, msg.Header("traceparent"))
+	require.NoError(t, batcher.Flush(context.Background()))
+}
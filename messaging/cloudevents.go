@@ -0,0 +1,391 @@
+package messaging
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// CloudEventsSpecVersion is the version of the CloudEvents specification
+// emitted and accepted.
+const CloudEventsSpecVersion = "1.0"
+
+// Headers and content types of the CloudEvents Kafka binding, used for every
+// broker.
+const (
+	// HeaderContentType is the content type of the message value: the one of
+	// the data in binary mode, ContentTypeCloudEvents in structured mode.
+	HeaderContentType = "content-type"
+	// CloudEventsHeaderPrefix prefixes the attributes of an event in binary
+	// mode, e.g. "ce_type".
+	CloudEventsHeaderPrefix = "ce_"
+
+	ContentTypeCloudEvents = "application/cloudevents+json"
+	ContentTypeJSON        = "application/json"
+)
+
+// ContentMode is how a CloudEvent is carried by a message.
+type ContentMode int
+
+const (
+	// StructuredMode carries the whole event as JSON in the value.
+	StructuredMode ContentMode = iota
+	// BinaryMode carries the data in the value and the attributes in the
+	// headers, so consumers unaware of CloudEvents still read the data.
+	BinaryMode
+)
+
+// Context attributes with a dedicated CloudEvent field. The other attributes
+// are extensions.
+const (
+	attrID              = "id"
+	attrSource          = "source"
+	attrSpecVersion     = "specversion"
+	attrType            = "type"
+	attrDataContentType = "datacontenttype"
+	attrDataSchema      = "dataschema"
+	attrSubject         = "subject"
+	attrTime            = "time"
+	attrData            = "data"
+	attrDataBase64      = "data_base64"
+
+	// Extensions written from an Envelope. partitionkey is the Kafka
+	// partitioning extension.
+	extTenant        = "tenant"
+	extCorrelationID = "correlationid"
+	extPartitionKey  = "partitionkey"
+)
+
+var (
+	errCloudEventRequired = errors.New("messaging: cloudevent requires id, source, specversion and type")
+	errCloudEventVersion  = errors.New("messaging: unsupported cloudevents specversion")
+	errCloudEventData     = errors.New("messaging: cloudevent data is not JSON")
+	errNotCloudEvent      = errors.New("messaging: message is not a cloudevent")
+)
+
+// CloudEvent is an event in the CloudEvents 1.0 format, for exchanging
+// events with systems outside the platform.
+//
+//	event := env.CloudEvent("/orders")
+//	msg, err := event.Message("orders", messaging.BinaryMode)
+//
+// and on the consumer side:
+//
+//	event, err := messaging.ParseCloudEvent(msg)
+//	env, err := event.Envelope()
+type CloudEvent struct {
+	ID          string
+	Source      string
+	SpecVersion string
+	Type        string
+
+	// DataContentType is the media type of Data, ContentTypeJSON when
+	// empty. In structured mode JSON data is embedded as is, text as a
+	// string and other data base64 encoded.
+	DataContentType string
+	DataSchema      string
+	Subject         string
+	Time            time.Time
+
+	Data []byte
+
+	// Extensions are the other attributes. Their names are lowercase
+	// letters and digits.
+	Extensions map[string]string
+}
+
+// CloudEvent returns the envelope as a CloudEvent from source. The tenant,
+// correlation ID and trace context become extensions, the latter following
+// the distributed tracing extension.
+func (e *Envelope) CloudEvent(source string) *CloudEvent {
+	c := &CloudEvent{
+		ID:              e.ID,
+		Source:          source,
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            e.Type,
+		DataContentType: ContentTypeJSON,
+		Time:            e.OccurredAt,
+		Data:            e.Payload,
+		Extensions:      map[string]string{},
+	}
+	for key, value := range e.Trace {
+		if validExtension(key) {
+			c.Extensions[key] = value
+		}
+	}
+	if e.Tenant != "" {
+		c.Extensions[extTenant] = e.Tenant
+	}
+	if e.CorrelationID != "" {
+		c.Extensions[extCorrelationID] = e.CorrelationID
+	}
+	return c
+}
+
+// Envelope returns the event as an Envelope. Its data must be JSON.
+func (c *CloudEvent) Envelope() (*Envelope, error) {
+	if !isJSON(c.DataContentType) || (len(c.Data) > 0 && !json.Valid(c.Data)) {
+		return nil, errCloudEventData
+	}
+
+	e := &Envelope{
+		ID:            c.ID,
+		Type:          c.Type,
+		OccurredAt:    c.Time,
+		Tenant:        c.Extensions[extTenant],
+		CorrelationID: c.Extensions[extCorrelationID],
+		Payload:       c.Data,
+	}
+	for _, key := range []string{"traceparent", "tracestate", "baggage"} {
+		if value, ok := c.Extensions[key]; ok {
+			if e.Trace == nil {
+				e.Trace = map[string]string{}
+			}
+			e.Trace[key] = value
+		}
+	}
+	return e, nil
+}
+
+// Attributes returns the context attributes as strings, as carried by the
+// headers in binary mode. The data content type is left out since bindings
+// carry it in their content type header.
+func (c *CloudEvent) Attributes() map[string]string {
+	attrs := make(map[string]string, len(c.Extensions)+7)
+	for key, value := range c.Extensions {
+		attrs[key] = value
+	}
+	attrs[attrID] = c.ID
+	attrs[attrSource] = c.Source
+	attrs[attrSpecVersion] = c.SpecVersion
+	attrs[attrType] = c.Type
+	if c.DataSchema != "" {
+		attrs[attrDataSchema] = c.DataSchema
+	}
+	if c.Subject != "" {
+		attrs[attrSubject] = c.Subject
+	}
+	if !c.Time.IsZero() {
+		attrs[attrTime] = c.Time.Format(time.RFC3339Nano)
+	}
+	return attrs
+}
+
+// CloudEventFromAttributes returns the event received in binary mode with
+// the given context attributes, data content type and data.
+func CloudEventFromAttributes(attrs map[string]string, contentType string, data []byte) (*CloudEvent, error) {
+	c := &CloudEvent{DataContentType: contentType, Data: data}
+	for key, value := range attrs {
+		if err := c.setAttribute(key, value); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Message returns the message carrying the event to topic in the given
+// content mode. The partitionkey extension becomes the message key.
+func (c *CloudEvent) Message(topic string, mode ContentMode) (*Message, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Topic:     topic,
+		Timestamp: c.Time,
+	}
+	if key := c.Extensions[extPartitionKey]; key != "" {
+		msg.Key = []byte(key)
+	}
+
+	if mode == BinaryMode {
+		for key, value := range c.Attributes() {
+			msg.SetHeader(CloudEventsHeaderPrefix+key, value)
+		}
+		msg.SetHeader(HeaderContentType, c.contentType())
+		msg.Value = c.Data
+	} else {
+		data, err := json.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		msg.SetHeader(HeaderContentType, ContentTypeCloudEvents)
+		msg.Value = data
+	}
+	msg.SetHeader(HeaderMessageID, c.ID)
+	return msg, nil
+}
+
+// ParseCloudEvent decodes the event carried by msg in either content mode.
+func ParseCloudEvent(msg *Message) (*CloudEvent, error) {
+	contentType := msg.Header(HeaderContentType)
+	if IsStructuredCloudEvent(contentType) {
+		var c CloudEvent
+		if err := json.Unmarshal(msg.Value, &c); err != nil {
+			return nil, err
+		}
+		return &c, nil
+	}
+
+	if msg.Header(CloudEventsHeaderPrefix+attrSpecVersion) == "" {
+		return nil, errNotCloudEvent
+	}
+	attrs := map[string]string{}
+	for key, value := range msg.Headers {
+		if name, ok := strings.CutPrefix(key, CloudEventsHeaderPrefix); ok {
+			attrs[name] = value
+		}
+	}
+	return CloudEventFromAttributes(attrs, contentType, msg.Value)
+}
+
+// IsStructuredCloudEvent reports whether contentType is the one of a
+// CloudEvent in structured mode.
+func IsStructuredCloudEvent(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "application/cloudevents")
+}
+
+// MarshalJSON encodes the event in the JSON event format.
+func (c *CloudEvent) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, len(c.Extensions)+9)
+	for key, value := range c.Attributes() {
+		doc[key] = value
+	}
+	if c.DataContentType != "" {
+		doc[attrDataContentType] = c.DataContentType
+	}
+	switch {
+	case len(c.Data) == 0:
+	case isJSON(c.DataContentType):
+		doc[attrData] = json.RawMessage(c.Data)
+	case isText(c.DataContentType):
+		doc[attrData] = string(c.Data)
+	default:
+		doc[attrDataBase64] = c.Data
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes an event in the JSON event format.
+func (c *CloudEvent) UnmarshalJSON(data []byte) error {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*c = CloudEvent{}
+	var rawData json.RawMessage
+	for key, raw := range doc {
+		switch key {
+		case attrData:
+			rawData = raw
+		case attrDataBase64:
+			if err := json.Unmarshal(raw, &c.Data); err != nil {
+				return err
+			}
+		default:
+			var value string
+			if err := json.Unmarshal(raw, &value); err != nil {
+				// Extensions may be booleans or integers.
+				value = string(raw)
+			}
+			if err := c.setAttribute(key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	if rawData != nil {
+		var text string
+		if !isJSON(c.DataContentType) && json.Unmarshal(rawData, &text) == nil {
+			c.Data = []byte(text)
+		} else {
+			c.Data = rawData
+		}
+	}
+	return c.validate()
+}
+
+func (c *CloudEvent) setAttribute(key, value string) error {
+	switch key {
+	case attrID:
+		c.ID = value
+	case attrSource:
+		c.Source = value
+	case attrSpecVersion:
+		c.SpecVersion = value
+	case attrType:
+		c.Type = value
+	case attrDataContentType:
+		c.DataContentType = value
+	case attrDataSchema:
+		c.DataSchema = value
+	case attrSubject:
+		c.Subject = value
+	case attrTime:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return err
+		}
+		c.Time = t
+	default:
+		if c.Extensions == nil {
+			c.Extensions = map[string]string{}
+		}
+		c.Extensions[key] = value
+	}
+	return nil
+}
+
+func (c *CloudEvent) validate() error {
+	if c.ID == "" || c.Source == "" || c.SpecVersion == "" || c.Type == "" {
+		return errCloudEventRequired
+	}
+	if c.SpecVersion != CloudEventsSpecVersion {
+		return errCloudEventVersion
+	}
+	return nil
+}
+
+// contentType returns the content type of the data, JSON by default.
+func (c *CloudEvent) contentType() string {
+	if c.DataContentType == "" {
+		return ContentTypeJSON
+	}
+	return c.DataContentType
+}
+
+// isJSON reports whether contentType is JSON, which an empty one defaults
+// to.
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json")
+}
+
+// isText reports whether contentType is a text media type.
+func isText(contentType string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "text/")
+}
+
+// validExtension reports whether name is a valid extension name: lowercase
+// ASCII letters and digits.
+func validExtension(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package messaging
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestCloudEventMessageRoundTrip(t *testing.T) {
+	useTraceContextPropagator(t)
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext())
+
+	env, err := NewEnvelope(ctx, "order.created", testOrder{ID: 1, Total: "10.00"},
+		WithTenant("acme"), WithCorrelationID("req-1"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		mode            ContentMode
+		wantContentType string
+	}{
+		{name: "structured", mode: StructuredMode, wantContentType: ContentTypeCloudEvents},
+		{name: "binary", mode: BinaryMode, wantContentType: ContentTypeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := env.CloudEvent("/orders").Message("orders", tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContentType, msg.Header(HeaderContentType))
+			assert.Equal(t, env.ID, msg.Header(HeaderMessageID))
+			if tt.mode == BinaryMode {
+				assert.Equal(t, "order.created", msg.Header("ce_type"))
+				assert.JSONEq(t, `{"id":1,"total":"10.00"}`, string(msg.Value))
+			}
+
+			event, err := ParseCloudEvent(msg)
+			require.NoError(t, err)
+			assert.Equal(t, "/orders", event.Source)
+			assert.Equal(t, "acme", event.Extensions["tenant"])
+
+			parsed, err := event.Envelope()
+			require.NoError(t, err)
+			assert.Equal(t, env.ID, parsed.ID)
+			assert.Equal(t, "order.created", parsed.Type)
+			assert.Equal(t, "acme", parsed.Tenant)
+			assert.Equal(t, "req-1", parsed.CorrelationID)
+			assert.True(t, env.OccurredAt.Equal(parsed.OccurredAt))
+			assert.Equal(t, testSpanContext().TraceID(), trace.SpanContextFromContext(parsed.Context(context.Background())).TraceID())
+
+			var order testOrder
+			require.NoError(t, parsed.Decode(&order))
+			assert.Equal(t, testOrder{ID: 1, Total: "10.00"}, order)
+		})
+	}
+}
+
+func TestCloudEventJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		wantData []byte
+		wantExt  map[string]string
+		wantErr  error
+	}{
+		{
+			name:     "json data",
+			json:     `{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","time":"2026-01-02T03:04:05Z","data":{"id":1}}`,
+			wantData: []byte(`{"id":1}`),
+		},
+		{
+			name:     "base64 data",
+			json:     `{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","datacontenttype":"application/octet-stream","data_base64":"AQID"}`,
+			wantData: []byte{1, 2, 3},
+		},
+		{
+			name:     "text data",
+			json:     `{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","datacontenttype":"text/plain","data":"hello"}`,
+			wantData: []byte("hello"),
+		},
+		{
+			name:    "extensions",
+			json:    `{"specversion":"1.0","id":"1","source":"/orders","type":"order.created","tenant":"acme","priority":3,"urgent":true}`,
+			wantExt: map[string]string{"tenant": "acme", "priority": "3", "urgent": "true"},
+		},
+		{
+			name:    "missing source",
+			json:    `{"specversion":"1.0","id":"1","type":"order.created"}`,
+			wantErr: errCloudEventRequired,
+		},
+		{
+			name:    "unsupported version",
+			json:    `{"specversion":"0.3","id":"1","source":"/orders","type":"order.created"}`,
+			wantErr: errCloudEventVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := &Message{Value: []byte(tt.json)}
+			msg.SetHeader(HeaderContentType, ContentTypeCloudEvents+"; charset=utf-8")
+
+			event, err := ParseCloudEvent(msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, event.Data)
+			assert.Equal(t, tt.wantExt, event.Extensions)
+
+			// Encoding it again gives back an equivalent document.
+			data, err := event.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, normalizeExtensions(tt.json), string(data))
+		})
+	}
+}
+
+// normalizeExtensions turns the non-string extensions of TestCloudEventJSON
+// into the strings they are decoded as.
+func normalizeExtensions(doc string) string {
+	return strings.NewReplacer(`"priority":3`, `"priority":"3"`, `"urgent":true`, `"urgent":"true"`).Replace(doc)
+}
+
+func TestParseCloudEventBinary(t *testing.T) {
+	msg := &Message{
+		Value: []byte("hello"),
+		Headers: map[string]string{
+			HeaderContentType: "text/plain",
+			"ce_specversion":  "1.0",
+			"ce_id":           "1",
+			"ce_source":       "/orders",
+			"ce_type":         "order.created",
+			"ce_time":         "2026-01-02T03:04:05Z",
+			"ce_partitionkey": "order-1",
+			"x-other":         "ignored",
+		},
+	}
+
+	event, err := ParseCloudEvent(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", event.DataContentType)
+	assert.Equal(t, []byte("hello"), event.Data)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), event.Time)
+	assert.Equal(t, map[string]string{"partitionkey": "order-1"}, event.Extensions)
+
+	_, err = event.Envelope()
+	assert.ErrorIs(t, err, errCloudEventData)
+
+	out, err := event.Message("orders", BinaryMode)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("order-1"), out.Key)
+}
+
+func TestParseCloudEventNotCloudEvent(t *testing.T) {
+	_, err := ParseCloudEvent(&Message{Value: []byte(`{}`)})
+	assert.ErrorIs(t, err, errNotCloudEvent)
+}
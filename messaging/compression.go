@@ -0,0 +1,169 @@
+package messaging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// HeaderContentEncoding is the header naming the compression of a payload.
+// Messages without it are not compressed.
+const HeaderContentEncoding = "content-encoding"
+
+// Payload compressions, as set in HeaderContentEncoding. The base64 ones
+// encode the compressed payload as text, for the brokers accepting only
+// text bodies, such as SQS and SNS.
+const (
+	EncodingGzip       = "gzip"
+	EncodingZstd       = "zstd"
+	EncodingGzipBase64 = EncodingGzip + base64Suffix
+	EncodingZstdBase64 = EncodingZstd + base64Suffix
+)
+
+const base64Suffix = "+base64"
+
+const defaultCompressionThreshold = 1024
+
+var errUnknownEncoding = errors.New("messaging: unknown content encoding")
+
+// The zstd encoder and decoder are safe for concurrent use through
+// EncodeAll and DecodeAll, and costly to create.
+var (
+	zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) { return zstd.NewWriter(nil) })
+	zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) { return zstd.NewReader(nil) })
+)
+
+// CompressionConfig configures the compression of published payloads.
+type CompressionConfig struct {
+	// Encoding is EncodingGzip, the default, or EncodingZstd, faster and
+	// smaller but not understood by every tool. Publishers to SQS and SNS,
+	// which reject binary bodies, need EncodingGzipBase64 or
+	// EncodingZstdBase64.
+	Encoding string
+	// Threshold is the payload size above which payloads are compressed,
+	// 1024 bytes by default. Smaller payloads gain little, if anything.
+	Threshold int
+}
+
+type compressingPublisher struct {
+	publisher Publisher
+	config    CompressionConfig
+}
+
+// Compress returns a Publisher compressing the payloads above the threshold
+// before publishing them with publisher. The consumers must decompress them
+// with the Decompress middleware. The published messages are left as is,
+// the compressed payload is published on a copy.
+func Compress(publisher Publisher, config CompressionConfig) Publisher {
+	if config.Encoding == "" {
+		config.Encoding = EncodingGzip
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = defaultCompressionThreshold
+	}
+	return &compressingPublisher{publisher: publisher, config: config}
+}
+
+func (p *compressingPublisher) Publish(ctx context.Context, msg *Message) error {
+	if len(msg.Value) <= p.config.Threshold || msg.Header(HeaderContentEncoding) != "" {
+		return p.publisher.Publish(ctx, msg)
+	}
+
+	value, err := compress(p.config.Encoding, msg.Value)
+	if err != nil {
+		return err
+	}
+	compressed := *msg
+	compressed.Value = value
+	compressed.Headers = maps.Clone(msg.Headers)
+	compressed.SetHeader(HeaderContentEncoding, p.config.Encoding)
+	return p.publisher.Publish(ctx, &compressed)
+}
+
+// Decompress returns a middleware decompressing the payloads compressed by
+// Compress, so handlers only see plain payloads. Messages with an unknown
+// encoding fail.
+func Decompress() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			encoding := msg.Header(HeaderContentEncoding)
+			if encoding == "" {
+				return next(ctx, msg)
+			}
+
+			value, err := decompress(encoding, msg.Value)
+			if err != nil {
+				return err
+			}
+			msg.Value = value
+			delete(msg.Headers, HeaderContentEncoding)
+			return next(ctx, msg)
+		}
+	}
+}
+
+func compress(encoding string, data []byte) ([]byte, error) {
+	if encoding, ok := strings.CutSuffix(encoding, base64Suffix); ok {
+		compressed, err := compress(encoding, data)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.AppendEncode(nil, compressed), nil
+	}
+	switch encoding {
+	case EncodingGzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case EncodingZstd:
+		enc, err := zstdEncoder()
+		if err != nil {
+			return nil, err
+		}
+		return enc.EncodeAll(data, nil), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownEncoding, encoding)
+	}
+}
+
+func decompress(encoding string, data []byte) ([]byte, error) {
+	if encoding, ok := strings.CutSuffix(encoding, base64Suffix); ok {
+		compressed, err := base64.StdEncoding.AppendDecode(nil, data)
+		if err != nil {
+			return nil, err
+		}
+		return decompress(encoding, compressed)
+	}
+	switch encoding {
+	case EncodingGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	case EncodingZstd:
+		dec, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return dec.DecodeAll(data, nil)
+	default:
+		return nil, fmt.Errorf("%w: %q", errUnknownEncoding, encoding)
+	}
+}
@@ -0,0 +1,75 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordPublisher records the published messages.
+type recordPublisher struct {
+	published []*Message
+}
+
+func (p *recordPublisher) Publish(_ context.Context, msg *Message) error {
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func TestCompressRoundTrip(t *testing.T) {
+	large := bytes.Repeat([]byte(`{"id":1,"name":"order"}`), 100)
+
+	tests := []struct {
+		name         string
+		config       CompressionConfig
+		value        []byte
+		wantEncoding string
+	}{
+		{name: "gzip by default", value: large, wantEncoding: EncodingGzip},
+		{name: "zstd", config: CompressionConfig{Encoding: EncodingZstd}, value: large, wantEncoding: EncodingZstd},
+		{name: "gzip base64", config: CompressionConfig{Encoding: EncodingGzipBase64}, value: large, wantEncoding: EncodingGzipBase64},
+		{name: "zstd base64", config: CompressionConfig{Encoding: EncodingZstdBase64}, value: large, wantEncoding: EncodingZstdBase64},
+		{name: "below threshold", value: []byte(`{"id":1}`)},
+		{name: "custom threshold", config: CompressionConfig{Threshold: 4}, value: []byte(`{"id":1}`), wantEncoding: EncodingGzip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &recordPublisher{}
+			msg := &Message{Topic: "orders", Value: tt.value, Headers: map[string]string{"tenant": "acme"}}
+			require.NoError(t, Compress(recorder, tt.config).Publish(context.Background(), msg))
+
+			require.Len(t, recorder.published, 1)
+			published := recorder.published[0]
+			assert.Equal(t, tt.wantEncoding, published.Header(HeaderContentEncoding))
+			assert.Equal(t, "acme", published.Header("tenant"))
+			assert.Equal(t, tt.value, msg.Value, "the published message is left as is")
+			assert.NotContains(t, msg.Headers, HeaderContentEncoding)
+			if tt.wantEncoding != "" && len(tt.value) > 100 {
+				assert.Less(t, len(published.Value), len(tt.value))
+			}
+
+			var got []byte
+			handler := Chain(func(_ context.Context, msg *Message) error {
+				got = msg.Value
+				assert.Empty(t, msg.Header(HeaderContentEncoding))
+				return nil
+			}, Decompress())
+			require.NoError(t, handler(context.Background(), published))
+			assert.Equal(t, tt.value, got)
+		})
+	}
+}
+
+func TestDecompressUnknownEncoding(t *testing.T) {
+	handler := Decompress()(func(context.Context, *Message) error {
+		t.Fatal("handler called")
+		return nil
+	})
+
+	msg := &Message{Value: []byte("data"), Headers: map[string]string{HeaderContentEncoding: "br"}}
+	assert.ErrorIs(t, handler(context.Background(), msg), errUnknownEncoding)
+}
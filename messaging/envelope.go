@@ -0,0 +1,152 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Headers set on the messages built from an Envelope.
+const (
+	HeaderMessageType = "x-message-type"
+	HeaderTenant      = "x-tenant"
+)
+
+var errEnvelopeType = errors.New("messaging: envelope type is required")
+
+// Envelope is the standard format of the events exchanged between services:
+// the payload with the metadata every consumer needs, serialized as JSON.
+//
+//	env, err := messaging.NewEnvelope(ctx, "order.created", order, messaging.WithTenant("acme"))
+//	msg, err := env.Message("orders")
+//	err = producer.Publish(ctx, msg)
+//
+// and on the consumer side:
+//
+//	env, err := messaging.ParseEnvelope(msg)
+//	var order Order
+//	err = env.Decode(&order)
+type Envelope struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Trace is the trace context of the producer, as written by the OTel
+	// propagator. It survives brokers and bridges dropping headers.
+	Trace map[string]string `json:"trace,omitempty"`
+
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Tenant        string `json:"tenant,omitempty"`
+
+	Payload json.RawMessage `json:"payload"`
+}
+
+// EnvelopeOption customizes NewEnvelope.
+type EnvelopeOption func(*Envelope)
+
+// WithTenant sets the tenant of the envelope.
+func WithTenant(tenant string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.Tenant = tenant
+	}
+}
+
+// WithCorrelationID sets the ID correlating the envelope with the request or
+// the message that caused it.
+func WithCorrelationID(id string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.CorrelationID = id
+	}
+}
+
+// WithEnvelopeID replaces the random ID of the envelope, e.g. by one derived
+// from the event so retries of the producer are deduplicated.
+func WithEnvelopeID(id string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.ID = id
+	}
+}
+
+// NewEnvelope encodes payload as JSON into a new envelope of eventType, with
+// a random ID, the current time and the trace context of ctx.
+func NewEnvelope(ctx context.Context, eventType string, payload any, opts ...EnvelopeOption) (*Envelope, error) {
+	if eventType == "" {
+		return nil, errEnvelopeType
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Envelope{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	}
+
+	trace := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, trace)
+	if len(trace) > 0 {
+		e.Trace = trace
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e, nil
+}
+
+// Message returns the message carrying the envelope to topic. The ID, type
+// and tenant are copied to the headers, for brokers and tools routing on
+// them.
+func (e *Envelope) Message(topic string) (*Message, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Topic:     topic,
+		Value:     data,
+		Timestamp: e.OccurredAt,
+	}
+	msg.SetHeader(HeaderMessageID, e.ID)
+	msg.SetHeader(HeaderMessageType, e.Type)
+	if e.Tenant != "" {
+		msg.SetHeader(HeaderTenant, e.Tenant)
+	}
+	return msg, nil
+}
+
+// ParseEnvelope decodes the envelope carried by msg.
+func ParseEnvelope(msg *Message) (*Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(msg.Value, &e); err != nil {
+		return nil, err
+	}
+	if e.Type == "" {
+		return nil, errEnvelopeType
+	}
+	return &e, nil
+}
+
+// Decode decodes the payload into v.
+func (e *Envelope) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Context returns ctx with the trace context of the envelope, for consumers
+// receiving it through a broker or bridge that dropped the headers.
+func (e *Envelope) Context(ctx context.Context) context.Context {
+	if len(e.Trace) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(e.Trace))
+}
@@ -0,0 +1,74 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type testOrder struct {
+	ID    int    `json:"id"`
+	Total string `json:"total"`
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	useTraceContextPropagator(t)
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext())
+
+	env, err := NewEnvelope(ctx, "order.created", testOrder{ID: 1, Total: "10.00"},
+		WithTenant("acme"), WithCorrelationID("req-1"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, env.ID)
+	assert.False(t, env.OccurredAt.IsZero())
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", env.Trace["traceparent"])
+
+	msg, err := env.Message("orders")
+	require.NoError(t, err)
+	assert.Equal(t, "orders", msg.Topic)
+	assert.Equal(t, env.ID, msg.Header(HeaderMessageID))
+	assert.Equal(t, "order.created", msg.Header(HeaderMessageType))
+	assert.Equal(t, "acme", msg.Header(HeaderTenant))
+
+	parsed, err := ParseEnvelope(msg)
+	require.NoError(t, err)
+	assert.Equal(t, env.ID, parsed.ID)
+	assert.Equal(t, "req-1", parsed.CorrelationID)
+	assert.True(t, env.OccurredAt.Equal(parsed.OccurredAt))
+
+	var order testOrder
+	require.NoError(t, parsed.Decode(&order))
+	assert.Equal(t, testOrder{ID: 1, Total: "10.00"}, order)
+
+	got := trace.SpanContextFromContext(parsed.Context(context.Background()))
+	assert.Equal(t, testSpanContext().TraceID(), got.TraceID())
+}
+
+func TestNewEnvelopeWithID(t *testing.T) {
+	env, err := NewEnvelope(context.Background(), "order.created", nil, WithEnvelopeID("order-1-created"))
+	require.NoError(t, err)
+	assert.Equal(t, "order-1-created", env.ID)
+	assert.Nil(t, env.Trace, "no trace context without propagator")
+}
+
+func TestParseEnvelopeInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "not json", value: "hello"},
+		{name: "no type", value: `{"id":"1","payload":{}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnvelope(&Message{Value: []byte(tt.value)})
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewEnvelope(context.Background(), "", nil)
+	assert.ErrorIs(t, err, errEnvelopeType)
+}
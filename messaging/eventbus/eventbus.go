@@ -0,0 +1,254 @@
+// Package eventbus publishes and subscribes to domain events without tying
+// the domain code to a broker: the backend, Kafka, NATS JetStream, SQS or
+// in-memory, is chosen by configuration.
+//
+//	bus, err := eventbus.New(ctx, eventbus.Config{Backend: eventbus.BackendKafka, ...})
+//	eventbus.Handle(bus, func(ctx context.Context, e OrderCreated) error { ... })
+//	go bus.Run(ctx)
+//	err = bus.Publish(ctx, OrderCreated{ID: 1})
+//
+// Events travel in a messaging.Envelope on a single topic, and are
+// dispatched to the handlers of their type.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/bagastri07/platigo/messaging/jetstream"
+	"github.com/bagastri07/platigo/messaging/kafka"
+	"github.com/bagastri07/platigo/messaging/sqs"
+)
+
+// Backend selects the broker of a Bus.
+type Backend string
+
+const (
+	// BackendMemory delivers the events within the process, for tests and
+	// single-process deployments.
+	BackendMemory Backend = "memory"
+	BackendKafka  Backend = "kafka"
+	BackendNATS   Backend = "nats"
+	BackendSQS    Backend = "sqs"
+)
+
+const defaultTopic = "events"
+
+var (
+	errUnknownBackend = errors.New("eventbus: unknown backend")
+	errSQSClient      = errors.New("eventbus: SQS backend needs a client")
+	errBusStarted     = errors.New("eventbus: handlers must be subscribed before Run")
+	errNoSubscriber   = errors.New("eventbus: backend is configured to publish only")
+)
+
+// Event is a domain event, published under its type.
+type Event interface {
+	EventType() string
+}
+
+// KeyedEvent is an event with a partition key, keeping the order of the
+// events sharing it on Kafka and on SQS FIFO queues.
+type KeyedEvent interface {
+	Event
+	EventKey() string
+}
+
+// Handler handles the envelope of an event.
+type Handler func(ctx context.Context, envelope *messaging.Envelope) error
+
+// Transport carries the encoded events of a Bus. The backends of New are
+// transports, and NewWithTransport takes others. Transports with a
+// Drain(ctx) error method are drained by Bus.Drain.
+type Transport interface {
+	messaging.Publisher
+	// Consume passes the received messages to handler until ctx is done.
+	Consume(ctx context.Context, handler messaging.Handler) error
+	Close() error
+}
+
+// Config configures a Bus. Only the section of the backend is used.
+type Config struct {
+	// Backend defaults to BackendMemory.
+	Backend Backend
+	// Topic is the Kafka topic or NATS subject carrying the events, "events"
+	// by default. SQS uses the queue URL instead.
+	Topic string
+
+	// Kafka configures the consumer group, and with Brokers and Sarama the
+	// producer. Without GroupID the bus only publishes.
+	Kafka kafka.ConsumerConfig
+	// NATS configures the connection. The stream of NATSConsumer must
+	// capture Topic. Without NATSConsumer.Durable the bus only publishes.
+	NATS         jetstream.Config
+	NATSConsumer jetstream.ConsumerConfig
+	// SQSClient and SQS configure the queue, used to both publish and
+	// consume.
+	SQSClient sqs.API
+	SQS       sqs.ConsumerConfig
+
+	// Logger receives the bus logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Bus publishes events and dispatches the received ones to the handlers
+// subscribed to their type.
+type Bus struct {
+	transport Transport
+	topic     string
+	log       logger.Logger
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	started  bool
+}
+
+// New creates a Bus on the backend of config.
+func New(ctx context.Context, config Config) (*Bus, error) {
+	if config.Topic == "" {
+		config.Topic = defaultTopic
+	}
+	log := logger.WithLevel(config.Logger, logger.InfoLevel)
+
+	bus := newBus(config.Topic, log)
+	var err error
+	switch config.Backend {
+	case "", BackendMemory:
+		bus.transport = newMemoryTransport(bus.dispatch)
+	case BackendKafka:
+		bus.transport, err = newKafkaTransport(config)
+	case BackendNATS:
+		bus.transport, err = newNATSTransport(ctx, config)
+	case BackendSQS:
+		bus.transport, err = newSQSTransport(config)
+	default:
+		err = fmt.Errorf("%w: %q", errUnknownBackend, config.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bus, nil
+}
+
+// NewWithTransport creates a Bus on a custom transport.
+func NewWithTransport(transport Transport, config Config) *Bus {
+	if config.Topic == "" {
+		config.Topic = defaultTopic
+	}
+	bus := newBus(config.Topic, logger.WithLevel(config.Logger, logger.InfoLevel))
+	bus.transport = transport
+	return bus
+}
+
+func newBus(topic string, log logger.Logger) *Bus {
+	return &Bus{
+		topic:    topic,
+		log:      log.With(logger.Fields{"topic": topic}),
+		handlers: map[string][]Handler{},
+	}
+}
+
+// Publish publishes event in a new envelope, with the trace context of ctx.
+func (b *Bus) Publish(ctx context.Context, event Event, opts ...messaging.EnvelopeOption) error {
+	envelope, err := messaging.NewEnvelope(ctx, event.EventType(), event, opts...)
+	if err != nil {
+		return err
+	}
+	msg, err := envelope.Message(b.topic)
+	if err != nil {
+		return err
+	}
+	if keyed, ok := event.(KeyedEvent); ok {
+		msg.Key = []byte(keyed.EventKey())
+	}
+	// Brokers deduplicating on the producer side drop the republished
+	// copies of the envelope.
+	msg.SetHeader(messaging.HeaderDeduplicationID, envelope.ID)
+	return b.transport.Publish(ctx, msg)
+}
+
+// Subscribe registers handler for the events of eventType. An event type
+// may have several handlers, all called. Handlers must be subscribed before
+// Run.
+func (b *Bus) Subscribe(eventType string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.started {
+		return errBusStarted
+	}
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+	return nil
+}
+
+// Handle subscribes handler to the events of type T, decoded from their
+// envelope.
+func Handle[T Event](bus *Bus, handler func(ctx context.Context, event T) error) error {
+	var zero T
+	return bus.Subscribe(zero.EventType(), func(ctx context.Context, envelope *messaging.Envelope) error {
+		var event T
+		if err := envelope.Decode(&event); err != nil {
+			return err
+		}
+		return handler(ctx, event)
+	})
+}
+
+// Run consumes the events until ctx is done. Events whose handler fails are
+// redelivered by the broker.
+func (b *Bus) Run(ctx context.Context) error {
+	b.mu.Lock()
+	b.started = true
+	b.mu.Unlock()
+
+	return b.transport.Consume(ctx, b.dispatch)
+}
+
+// Drain stops consuming and waits for the events being handled, on the
+// transports supporting it.
+func (b *Bus) Drain(ctx context.Context) error {
+	if drainer, ok := b.transport.(interface{ Drain(context.Context) error }); ok {
+		return drainer.Drain(ctx)
+	}
+	return nil
+}
+
+// Close closes the connections of the backend.
+func (b *Bus) Close() error {
+	return b.transport.Close()
+}
+
+// dispatch passes a received message to the handlers of its event type.
+// Messages that are not envelopes, and events without handlers, are dropped.
+func (b *Bus) dispatch(ctx context.Context, msg *messaging.Message) error {
+	envelope, err := messaging.ParseEnvelope(msg)
+	if err != nil {
+		b.log.Error(err.Error())
+		return nil
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[envelope.Type]
+	b.mu.RUnlock()
+
+	log := logger.WithContext(ctx, b.log).With(logger.Fields{"type": envelope.Type, "id": envelope.ID})
+	if len(handlers) == 0 {
+		log.Debug("No handler for event")
+		return nil
+	}
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, envelope); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	err = errors.Join(errs...)
+	if err != nil {
+		log.Error(err.Error())
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+}
+
+func (orderCreated) EventType() string  { return "order.created" }
+func (e orderCreated) EventKey() string { return e.OrderID }
+
+type orderCancelled struct {
+	OrderID string `json:"order_id"`
+}
+
+func (orderCancelled) EventType() string { return "order.cancelled" }
+
+// recordTransport records the published messages.
+type recordTransport struct {
+	published []*messaging.Message
+}
+
+func (t *recordTransport) Publish(_ context.Context, msg *messaging.Message) error {
+	t.published = append(t.published, msg)
+	return nil
+}
+
+func (t *recordTransport) Consume(ctx context.Context, _ messaging.Handler) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (t *recordTransport) Close() error { return nil }
+
+func TestNewValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr error
+	}{
+		{name: "unknown backend", config: Config{Backend: "carrier-pigeon"}, wantErr: errUnknownBackend},
+		{name: "sqs without client", config: Config{Backend: BackendSQS}, wantErr: errSQSClient},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(context.Background(), tt.config)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMemoryBus(t *testing.T) {
+	ctx := context.Background()
+	bus, err := New(ctx, Config{})
+	require.NoError(t, err)
+	defer bus.Close()
+
+	var created []orderCreated
+	require.NoError(t, Handle(bus, func(_ context.Context, e orderCreated) error {
+		created = append(created, e)
+		return nil
+	}))
+	var audited []string
+	require.NoError(t, bus.Subscribe("order.created", func(_ context.Context, envelope *messaging.Envelope) error {
+		audited = append(audited, envelope.Tenant)
+		return nil
+	}))
+	require.NoError(t, Handle(bus, func(context.Context, orderCancelled) error {
+		return errors.New("refund failed")
+	}))
+
+	require.NoError(t, bus.Publish(ctx, orderCreated{OrderID: "1"}, messaging.WithTenant("acme")))
+	assert.Equal(t, []orderCreated{{OrderID: "1"}}, created)
+	assert.Equal(t, []string{"acme"}, audited)
+
+	assert.EqualError(t, bus.Publish(ctx, orderCancelled{OrderID: "1"}), "refund failed")
+}
+
+func TestBusPublishMessage(t *testing.T) {
+	transport := &recordTransport{}
+	bus := NewWithTransport(transport, Config{Topic: "orders"})
+
+	require.NoError(t, bus.Publish(context.Background(), orderCreated{OrderID: "1"}, messaging.WithEnvelopeID("event-1")))
+	require.NoError(t, bus.Publish(context.Background(), orderCancelled{OrderID: "1"}))
+
+	require.Len(t, transport.published, 2)
+	msg := transport.published[0]
+	assert.Equal(t, "orders", msg.Topic)
+	assert.Equal(t, []byte("1"), msg.Key)
+	assert.Equal(t, "order.created", msg.Header(messaging.HeaderMessageType))
+	assert.Equal(t, "event-1", msg.Header(messaging.HeaderDeduplicationID))
+	assert.Nil(t, transport.published[1].Key, "events without key")
+}
+
+func TestBusDispatch(t *testing.T) {
+	bus := NewWithTransport(&recordTransport{}, Config{})
+	var got []string
+	require.NoError(t, Handle(bus, func(_ context.Context, e orderCreated) error {
+		got = append(got, e.OrderID)
+		return nil
+	}))
+
+	envelope, err := messaging.NewEnvelope(context.Background(), "order.created", orderCreated{OrderID: "1"})
+	require.NoError(t, err)
+	msg, err := envelope.Message("events")
+	require.NoError(t, err)
+	require.NoError(t, bus.dispatch(context.Background(), msg))
+	assert.Equal(t, []string{"1"}, got)
+
+	assert.NoError(t, bus.dispatch(context.Background(), &messaging.Message{Value: []byte("not an envelope")}), "invalid messages are dropped")
+	unknown, err := messaging.NewEnvelope(context.Background(), "order.shipped", struct{}{})
+	require.NoError(t, err)
+	msg, err = unknown.Message("events")
+	require.NoError(t, err)
+	assert.NoError(t, bus.dispatch(context.Background(), msg), "events without handler are dropped")
+}
+
+func TestBusSubscribeAfterRun(t *testing.T) {
+	bus := NewWithTransport(&recordTransport{}, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- bus.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		return errors.Is(bus.Subscribe("order.created", nil), errBusStarted)
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,184 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/bagastri07/platigo/messaging/jetstream"
+	"github.com/bagastri07/platigo/messaging/kafka"
+	"github.com/bagastri07/platigo/messaging/sqs"
+)
+
+// memoryTransport delivers the events synchronously within Publish, which
+// returns the handler errors, so tests observe the effects of an event as
+// soon as it is published.
+type memoryTransport struct {
+	dispatch messaging.Handler
+}
+
+func newMemoryTransport(dispatch messaging.Handler) *memoryTransport {
+	return &memoryTransport{dispatch: dispatch}
+}
+
+func (t *memoryTransport) Publish(ctx context.Context, msg *messaging.Message) error {
+	return t.dispatch(ctx, msg)
+}
+
+func (t *memoryTransport) Consume(ctx context.Context, _ messaging.Handler) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (t *memoryTransport) Close() error {
+	return nil
+}
+
+type kafkaTransport struct {
+	producer *kafka.Producer
+	consumer *kafka.Consumer
+	topic    string
+}
+
+func newKafkaTransport(config Config) (*kafkaTransport, error) {
+	producer, err := kafka.NewProducer(kafka.ProducerConfig{
+		Brokers: config.Kafka.Brokers,
+		Sarama:  config.Kafka.Sarama,
+		Logger:  config.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := &kafkaTransport{producer: producer, topic: config.Topic}
+	if config.Kafka.GroupID != "" {
+		if config.Kafka.Logger == nil {
+			config.Kafka.Logger = config.Logger
+		}
+		t.consumer, err = kafka.NewConsumer(config.Kafka)
+		if err != nil {
+			_ = producer.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *kafkaTransport) Publish(ctx context.Context, msg *messaging.Message) error {
+	return t.producer.Publish(ctx, msg)
+}
+
+func (t *kafkaTransport) Consume(ctx context.Context, handler messaging.Handler) error {
+	if t.consumer == nil {
+		return errNoSubscriber
+	}
+	if err := t.consumer.Handle(t.topic, handler); err != nil {
+		return err
+	}
+	return t.consumer.Run(ctx)
+}
+
+func (t *kafkaTransport) Drain(ctx context.Context) error {
+	if t.consumer == nil {
+		return nil
+	}
+	return t.consumer.Drain(ctx)
+}
+
+func (t *kafkaTransport) Close() error {
+	err := t.producer.Close()
+	if t.consumer != nil {
+		if consumerErr := t.consumer.Close(); err == nil {
+			err = consumerErr
+		}
+	}
+	return err
+}
+
+type natsTransport struct {
+	client   *jetstream.Client
+	consumer *jetstream.Consumer
+}
+
+func newNATSTransport(ctx context.Context, config Config) (*natsTransport, error) {
+	if config.NATS.Logger == nil {
+		config.NATS.Logger = config.Logger
+	}
+	client, err := jetstream.Connect(ctx, config.NATS)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &natsTransport{client: client}
+	if config.NATSConsumer.Durable != "" {
+		t.consumer, err = jetstream.NewConsumer(ctx, client, config.NATSConsumer)
+		if err != nil {
+			_ = client.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func (t *natsTransport) Publish(ctx context.Context, msg *messaging.Message) error {
+	return t.client.Publish(ctx, msg)
+}
+
+func (t *natsTransport) Consume(ctx context.Context, handler messaging.Handler) error {
+	if t.consumer == nil {
+		return errNoSubscriber
+	}
+	return t.consumer.Run(ctx, handler)
+}
+
+func (t *natsTransport) Drain(ctx context.Context) error {
+	if t.consumer == nil {
+		return nil
+	}
+	return t.consumer.Drain(ctx)
+}
+
+func (t *natsTransport) Close() error {
+	return t.client.Close()
+}
+
+type sqsTransport struct {
+	producer *sqs.Producer
+	consumer *sqs.Consumer
+}
+
+func newSQSTransport(config Config) (*sqsTransport, error) {
+	if config.SQSClient == nil {
+		return nil, errSQSClient
+	}
+	if config.SQS.Logger == nil {
+		config.SQS.Logger = config.Logger
+	}
+	producer, err := sqs.NewProducer(config.SQSClient, sqs.ProducerConfig{
+		QueueURL: config.SQS.QueueURL,
+		Logger:   config.SQS.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := sqs.NewConsumer(config.SQSClient, config.SQS)
+	if err != nil {
+		return nil, err
+	}
+	return &sqsTransport{producer: producer, consumer: consumer}, nil
+}
+
+func (t *sqsTransport) Publish(ctx context.Context, msg *messaging.Message) error {
+	return t.producer.Publish(ctx, msg)
+}
+
+func (t *sqsTransport) Consume(ctx context.Context, handler messaging.Handler) error {
+	return t.consumer.Run(ctx, handler)
+}
+
+func (t *sqsTransport) Drain(ctx context.Context) error {
+	return t.consumer.Drain(ctx)
+}
+
+func (t *sqsTransport) Close() error {
+	return nil
+}
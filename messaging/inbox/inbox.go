@@ -0,0 +1,111 @@
+// Package inbox deduplicates received messages: the ID of every message
+// handled successfully is recorded, and redeliveries of it are skipped, so
+// at-least-once brokers do not run side effects twice.
+package inbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// ErrInProgress is returned by Store.Claim, and by the handler, while the
+// message is being handled elsewhere. The message is then redelivered, and
+// skipped or handled depending on how the other handling went.
+var ErrInProgress = errors.New("inbox: message in progress")
+
+// Store records the processed message IDs.
+type Store interface {
+	// Claim reserves id for the caller. It reports false when id was
+	// processed already, and fails with ErrInProgress while another caller
+	// holds the reservation.
+	Claim(ctx context.Context, id string) (bool, error)
+	// MarkProcessed records id as processed.
+	MarkProcessed(ctx context.Context, id string) error
+	// Release drops the reservation of id so it can be processed again.
+	Release(ctx context.Context, id string) error
+}
+
+// Option customizes Handler.
+type Option func(*options)
+
+type options struct {
+	id func(msg *messaging.Message) string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		id: func(msg *messaging.Message) string {
+			return msg.Header(messaging.HeaderMessageID)
+		},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithID sets how the ID of a message is read, the messaging.HeaderMessageID
+// header by default. Messages with an empty ID are handled without
+// deduplication.
+func WithID(id func(msg *messaging.Message) string) Option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
+// Handler wraps handler to skip the messages already processed according to
+// store. A message failing is released, so its redelivery is handled again.
+//
+//	consumer.Run(ctx, inbox.Handler(inbox.NewRedisStore(client, inbox.RedisConfig{}), handleOrder))
+func Handler(store Store, handler messaging.Handler, opts ...Option) messaging.Handler {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, msg *messaging.Message) error {
+		id := o.id(msg)
+		if id == "" {
+			return handler(ctx, msg)
+		}
+
+		claimed, err := store.Claim(ctx, id)
+		if err != nil || !claimed {
+			return err
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			if releaseErr := store.Release(ctx, id); releaseErr != nil {
+				return errors.Join(err, releaseErr)
+			}
+			return err
+		}
+		return store.MarkProcessed(ctx, id)
+	}
+}
+
+// claimTokens holds the tokens of the claims of a store until they are
+// marked processed or released, so that a consumer whose claim expired
+// leaves alone the one another consumer took since.
+type claimTokens struct {
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+func (c *claimTokens) set(id, token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[id] = token
+}
+
+// take returns and forgets the token of the claim of id.
+func (c *claimTokens) take(id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token := c.tokens[id]
+	delete(c.tokens, id)
+	return token
+}
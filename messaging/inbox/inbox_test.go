@@ -0,0 +1,112 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisStore(client, RedisConfig{TTL: time.Hour}), srv
+}
+
+func testMessage(id string) *messaging.Message {
+	msg := &messaging.Message{Topic: "orders", Value: []byte(`{"id":1}`)}
+	if id != "" {
+		msg.SetHeader(messaging.HeaderMessageID, id)
+	}
+	return msg
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestRedisStore(t)
+
+	calls := 0
+	fail := true
+	handler := Handler(store, func(context.Context, *messaging.Message) error {
+		calls++
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+
+	assert.Error(t, handler(ctx, testMessage("msg-1")))
+	assert.False(t, srv.Exists("inbox:msg-1"), "a failed message is released")
+
+	fail = false
+	require.NoError(t, handler(ctx, testMessage("msg-1")))
+	require.NoError(t, handler(ctx, testMessage("msg-1")))
+	assert.Equal(t, 2, calls, "the redelivery of a processed message is skipped")
+	assert.Equal(t, time.Hour, srv.TTL("inbox:msg-1"))
+
+	require.NoError(t, handler(ctx, testMessage("")))
+	require.NoError(t, handler(ctx, testMessage("")))
+	assert.Equal(t, 4, calls, "messages without ID are not deduplicated")
+}
+
+func TestHandlerInProgress(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestRedisStore(t)
+
+	claimed, err := store.Claim(ctx, "msg-1")
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	handler := Handler(store, func(context.Context, *messaging.Message) error {
+		t.Fatal("handler must not run")
+		return nil
+	})
+	assert.ErrorIs(t, handler(ctx, testMessage("msg-1")), ErrInProgress)
+}
+
+func TestRedisStoreReservationTakenOver(t *testing.T) {
+	ctx := context.Background()
+	srv := miniredis.RunT(t)
+	newStore := func() *RedisStore {
+		client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return NewRedisStore(client, RedisConfig{TTL: time.Hour})
+	}
+	expired, current := newStore(), newStore()
+
+	claimed, err := expired.Claim(ctx, "msg-1")
+	require.NoError(t, err)
+	require.True(t, claimed)
+	srv.FastForward(2 * time.Minute)
+	claimed, err = current.Claim(ctx, "msg-1")
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	require.NoError(t, expired.Release(ctx, "msg-1"))
+	assert.ErrorIs(t, expired.MarkProcessed(ctx, "msg-1"), ErrInProgress)
+	require.NoError(t, current.MarkProcessed(ctx, "msg-1"))
+	assert.Equal(t, time.Hour, srv.TTL("inbox:msg-1"))
+}
+
+func TestHandlerWithID(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestRedisStore(t)
+
+	handler := Handler(store, func(context.Context, *messaging.Message) error { return nil },
+		WithID(func(msg *messaging.Message) string { return msg.Header("event-id") }))
+
+	msg := testMessage("")
+	msg.SetHeader("event-id", "evt-1")
+	require.NoError(t, handler(ctx, msg))
+	assert.True(t, srv.Exists("inbox:evt-1"))
+}
@@ -0,0 +1,75 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bagastri07/platigo/idempotency"
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultRedisPrefix = "inbox:"
+
+// RedisConfig configures a RedisStore.
+type RedisConfig struct {
+	// TTL is how long processed IDs are remembered, 24 hours by default. It
+	// must exceed the time the broker may redeliver a message.
+	TTL time.Duration
+	// LockTTL is how long a message may be handled before another consumer
+	// may take over, one minute by default.
+	LockTTL time.Duration
+	// Prefix is the prefix of the Redis keys, "inbox:" by default.
+	Prefix string
+}
+
+// RedisStore records the processed message IDs in Redis, as idempotency
+// keys without response.
+type RedisStore struct {
+	store  *idempotency.Store
+	tokens claimTokens
+}
+
+// NewRedisStore creates a RedisStore.
+func NewRedisStore(client redis.UniversalClient, config RedisConfig) *RedisStore {
+	if config.Prefix == "" {
+		config.Prefix = defaultRedisPrefix
+	}
+	return &RedisStore{
+		store: idempotency.NewStore(client, idempotency.Config{
+			TTL:     config.TTL,
+			LockTTL: config.LockTTL,
+			Prefix:  config.Prefix,
+		}),
+	}
+}
+
+func (s *RedisStore) Claim(ctx context.Context, id string) (bool, error) {
+	response, token, err := s.store.Begin(ctx, id)
+	if errors.Is(err, idempotency.ErrInProgress) {
+		return false, ErrInProgress
+	}
+	if err != nil {
+		return false, err
+	}
+	if response != nil {
+		return false, nil
+	}
+	s.tokens.set(id, token)
+	return true, nil
+}
+
+func (s *RedisStore) MarkProcessed(ctx context.Context, id string) error {
+	err := s.store.Complete(ctx, id, s.tokens.take(id), []byte{})
+	switch {
+	case errors.Is(err, idempotency.ErrAlreadyCompleted):
+		return nil
+	case errors.Is(err, idempotency.ErrInProgress):
+		return ErrInProgress
+	}
+	return err
+}
+
+func (s *RedisStore) Release(ctx context.Context, id string) error {
+	return s.store.Release(ctx, id, s.tokens.take(id))
+}
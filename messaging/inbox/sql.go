@@ -0,0 +1,165 @@
+package inbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTable = "inbox_messages"
+
+	statusProcessing = "processing"
+	statusProcessed  = "processed"
+)
+
+var errInvalidTable = errors.New("inbox: invalid table name")
+
+// SQLConfig configures a SQLStore.
+type SQLConfig struct {
+	// Table is the table of the message IDs, "inbox_messages" by default:
+	//
+	//	CREATE TABLE inbox_messages (
+	//		id         TEXT PRIMARY KEY,
+	//		status     TEXT NOT NULL,
+	//		token      TEXT NOT NULL,
+	//		updated_at TIMESTAMPTZ NOT NULL
+	//	);
+	Table string
+	// LockTTL is how long a message may be handled before another consumer
+	// may take over, one minute by default.
+	LockTTL time.Duration
+}
+
+// SQLStore records the processed message IDs in a table, using the
+// PostgreSQL dialect, also understood by CockroachDB and SQLite. Recording
+// the ID in the transaction of the side effects of the handler is the only
+// way to make them exactly once; SQLStore records it afterwards, like
+// RedisStore. Every claim stores a token, matched when the message is
+// marked processed or released.
+type SQLStore struct {
+	db       *sql.DB
+	lockTTL  time.Duration
+	now      func() time.Time
+	newToken func() string
+	tokens   claimTokens
+
+	claimQuery     string
+	statusQuery    string
+	processedQuery string
+	releaseQuery   string
+	purgeQuery     string
+}
+
+// NewSQLStore creates a SQLStore on db, whose table must exist.
+func NewSQLStore(db *sql.DB, config SQLConfig) (*SQLStore, error) {
+	table := config.Table
+	if table == "" {
+		table = defaultTable
+	}
+	if !validIdentifier(table) {
+		return nil, errInvalidTable
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = time.Minute
+	}
+
+	return &SQLStore{
+		db:       db,
+		lockTTL:  config.LockTTL,
+		now:      time.Now,
+		newToken: uuid.NewString,
+
+		// The conflicting row is only taken over when its reservation
+		// expired.
+		claimQuery: fmt.Sprintf(`INSERT INTO %[1]s (id, status, token, updated_at) VALUES ($1, '%[2]s', $2, $3)
+ON CONFLICT (id) DO UPDATE SET token = EXCLUDED.token, updated_at = EXCLUDED.updated_at
+WHERE %[1]s.status = '%[2]s' AND %[1]s.updated_at < $4`, table, statusProcessing),
+		statusQuery:    fmt.Sprintf(`SELECT status FROM %s WHERE id = $1`, table),
+		processedQuery: fmt.Sprintf(`UPDATE %s SET status = '%s', updated_at = $3 WHERE id = $1 AND token = $2`, table, statusProcessed),
+		releaseQuery:   fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND token = $2 AND status = '%s'`, table, statusProcessing),
+		purgeQuery:     fmt.Sprintf(`DELETE FROM %s WHERE status = '%s' AND updated_at < $1`, table, statusProcessed),
+	}, nil
+}
+
+func (s *SQLStore) Claim(ctx context.Context, id string) (bool, error) {
+	now := s.now()
+	token := s.newToken()
+	res, err := s.db.ExecContext(ctx, s.claimQuery, id, token, now, now.Add(-s.lockTTL))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		s.tokens.set(id, token)
+		return true, nil
+	}
+	return false, s.checkStatus(ctx, id)
+}
+
+// MarkProcessed records id as processed. It fails with ErrInProgress once
+// another consumer took over the expired claim.
+func (s *SQLStore) MarkProcessed(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, s.processedQuery, id, s.tokens.take(id), s.now())
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return err
+	}
+	err = s.checkStatus(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Released by the consumer that took over: nothing to record.
+		return nil
+	}
+	return err
+}
+
+// Release drops the claim of id, unless another consumer took it over.
+func (s *SQLStore) Release(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.releaseQuery, id, s.tokens.take(id))
+	return err
+}
+
+// checkStatus fails with ErrInProgress while id is claimed.
+func (s *SQLStore) checkStatus(ctx context.Context, id string) error {
+	var status string
+	if err := s.db.QueryRowContext(ctx, s.statusQuery, id).Scan(&status); err != nil {
+		return err
+	}
+	if status == statusProcessing {
+		return ErrInProgress
+	}
+	return nil
+}
+
+// Purge deletes the IDs processed before olderThan and returns how many
+// there were. Run it periodically, the table otherwise grows forever.
+func (s *SQLStore) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.purgeQuery, s.now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// validIdentifier reports whether name is safe to use as table name, with an
+// optional schema.
+func validIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == '.':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
@@ -0,0 +1,154 @@
+package inbox
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSQLStore(t *testing.T) (*SQLStore, sqlmock.Sqlmock, time.Time) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+
+	store, err := NewSQLStore(db, SQLConfig{})
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	store.newToken = func() string { return "token-1" }
+	return store, mock, now
+}
+
+func TestNewSQLStoreInvalidTable(t *testing.T) {
+	_, err := NewSQLStore(nil, SQLConfig{Table: "inbox; DROP TABLE users"})
+	assert.ErrorIs(t, err, errInvalidTable)
+}
+
+func TestSQLStoreClaim(t *testing.T) {
+	tests := []struct {
+		name        string
+		affected    int64
+		status      string
+		wantClaimed bool
+		wantErr     error
+	}{
+		{name: "new message", affected: 1, wantClaimed: true},
+		{name: "processed", status: statusProcessed},
+		{name: "in progress", status: statusProcessing, wantErr: ErrInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, mock, now := newTestSQLStore(t)
+
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO inbox_messages (id, status, token, updated_at) VALUES ($1, 'processing', $2, $3)")).
+				WithArgs("msg-1", "token-1", now, now.Add(-time.Minute)).
+				WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			if tt.affected == 0 {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM inbox_messages WHERE id = $1")).
+					WithArgs("msg-1").
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(tt.status))
+			}
+
+			claimed, err := store.Claim(context.Background(), "msg-1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantClaimed, claimed)
+		})
+	}
+}
+
+// expectClaim expects the claim of id to succeed.
+func expectClaim(mock sqlmock.Sqlmock, id string, now time.Time) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO inbox_messages")).
+		WithArgs(id, "token-1", now, now.Add(-time.Minute)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+func TestSQLStoreMarkProcessedReleasePurge(t *testing.T) {
+	store, mock, now := newTestSQLStore(t)
+	ctx := context.Background()
+
+	expectClaim(mock, "msg-1", now)
+	claimed, err := store.Claim(ctx, "msg-1")
+	require.NoError(t, err)
+	require.True(t, claimed)
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE inbox_messages SET status = 'processed', updated_at = $3 WHERE id = $1 AND token = $2")).
+		WithArgs("msg-1", "token-1", now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.MarkProcessed(ctx, "msg-1"))
+
+	expectClaim(mock, "msg-2", now)
+	claimed, err = store.Claim(ctx, "msg-2")
+	require.NoError(t, err)
+	require.True(t, claimed)
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM inbox_messages WHERE id = $1 AND token = $2 AND status = 'processing'")).
+		WithArgs("msg-2", "token-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.Release(ctx, "msg-2"))
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM inbox_messages WHERE status = 'processed' AND updated_at < $1")).
+		WithArgs(now.Add(-7 * 24 * time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+	purged, err := store.Purge(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), purged)
+}
+
+func TestSQLStoreClaimTakenOver(t *testing.T) {
+	tests := []struct {
+		name string
+		// status is the status of the row, missing when empty.
+		status  string
+		wantErr error
+	}{
+		{name: "still in progress", status: statusProcessing, wantErr: ErrInProgress},
+		{name: "processed by the other consumer", status: statusProcessed},
+		{name: "released by the other consumer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, mock, now := newTestSQLStore(t)
+			ctx := context.Background()
+
+			expectClaim(mock, "msg-1", now)
+			claimed, err := store.Claim(ctx, "msg-1")
+			require.NoError(t, err)
+			require.True(t, claimed)
+
+			// Another consumer took over the expired claim, replacing the token.
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE inbox_messages SET status = 'processed'")).
+				WithArgs("msg-1", "token-1", now).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			rows := sqlmock.NewRows([]string{"status"})
+			if tt.status != "" {
+				rows.AddRow(tt.status)
+			}
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM inbox_messages WHERE id = $1")).
+				WithArgs("msg-1").
+				WillReturnRows(rows)
+
+			err = store.MarkProcessed(ctx, "msg-1")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
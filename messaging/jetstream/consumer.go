@@ -0,0 +1,206 @@
+package jetstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	natsjs "github.com/nats-io/nats.go/jetstream"
+)
+
+const defaultBatchSize = 100
+
+// ErrTerminate marks a handler error as permanent: the message is
+// terminated instead of redelivered.
+//
+//	return fmt.Errorf("decode order: %w", jetstream.ErrTerminate)
+var ErrTerminate = errors.New("jetstream: terminate message")
+
+var (
+	errStreamRequired  = errors.New("jetstream: stream is required")
+	errDurableRequired = errors.New("jetstream: durable name is required")
+)
+
+// ConsumerConfig configures a durable pull Consumer.
+type ConsumerConfig struct {
+	Stream  string
+	Durable string
+
+	// FilterSubjects restricts the consumer to the messages of these
+	// subjects. All the subjects of the stream by default.
+	FilterSubjects []string
+
+	// AckWait is the time a message may be handled before it is
+	// redelivered, 30s by default. MaxDeliver caps the deliveries of a
+	// message, unlimited by default.
+	AckWait    time.Duration
+	MaxDeliver int
+
+	// NakDelay is the delay before a failed message is redelivered. Failed
+	// messages are redelivered right away by default.
+	NakDelay time.Duration
+
+	// BatchSize is the number of messages pulled ahead, 100 by default.
+	BatchSize int
+
+	// Concurrency is the number of messages handled at once, 1 by default.
+	Concurrency int
+
+	// Hooks are called with the stream when Run starts and stops.
+	Hooks messaging.Hooks
+}
+
+// Consumer is a durable pull consumer.
+type Consumer struct {
+	consumer  natsjs.Consumer
+	config    ConsumerConfig
+	log       logger.Logger
+	lifecycle *messaging.Lifecycle
+}
+
+// NewConsumer creates the consumer on the stream, or updates it to match
+// config. Consumers with the same durable name share the messages.
+func NewConsumer(ctx context.Context, client *Client, config ConsumerConfig) (*Consumer, error) {
+	if config.Stream == "" {
+		return nil, errStreamRequired
+	}
+	if config.Durable == "" {
+		return nil, errDurableRequired
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+
+	consumer, err := client.js.CreateOrUpdateConsumer(ctx, config.Stream, natsjs.ConsumerConfig{
+		Durable:        config.Durable,
+		AckPolicy:      natsjs.AckExplicitPolicy,
+		AckWait:        config.AckWait,
+		MaxDeliver:     config.MaxDeliver,
+		FilterSubjects: config.FilterSubjects,
+		MaxAckPending:  max(config.BatchSize, config.Concurrency),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		consumer:  consumer,
+		config:    config,
+		log:       client.log.With(logger.Fields{"stream": config.Stream, "consumer": config.Durable}),
+		lifecycle: messaging.NewLifecycle(),
+	}, nil
+}
+
+// Run passes the messages of the consumer to handler until ctx is done. A
+// message is acked once handler succeeds, terminated when the error wraps
+// ErrTerminate and nacked otherwise. Run waits for the messages being
+// handled before returning, and returns once Drain is called.
+func (c *Consumer) Run(ctx context.Context, handler messaging.Handler) error {
+	if !c.lifecycle.Start() {
+		return nil
+	}
+	defer c.lifecycle.Stop()
+
+	messages, err := c.consumer.Messages(natsjs.PullMaxMessages(c.config.BatchSize))
+	if err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, messages.Stop)
+	defer stop()
+	// Draining hands out the messages pulled already before closing.
+	returned := make(chan struct{})
+	defer close(returned)
+	go func() {
+		select {
+		case <-c.lifecycle.Draining():
+			messages.Drain()
+		case <-returned:
+		}
+	}()
+
+	assignment := messaging.Assignment{c.config.Stream: nil}
+	c.config.Hooks.Assign(ctx, assignment)
+	defer c.config.Hooks.Revoke(context.WithoutCancel(ctx), assignment)
+
+	sem := make(chan struct{}, c.config.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		msg, err := messages.Next()
+		if errors.Is(err, natsjs.ErrMsgIteratorClosed) {
+			return nil
+		}
+		if err != nil {
+			c.log.Error(err.Error())
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.handle(ctx, handler, msg)
+		}()
+	}
+}
+
+// Drain stops pulling messages, waits for those pulled already to be handled
+// and returns once Run has returned or ctx is done.
+func (c *Consumer) Drain(ctx context.Context) error {
+	return c.lifecycle.Drain(ctx)
+}
+
+func (c *Consumer) handle(ctx context.Context, handler messaging.Handler, msg natsjs.Msg) {
+	m := fromJetStream(msg)
+	msgCtx := messaging.ExtractTraceContext(ctx, m)
+	err := handler(msgCtx, m)
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			c.log.Error(err.Error())
+		}
+		return
+	}
+
+	log := logger.WithContext(msgCtx, c.log).With(logger.Fields{"subject": msg.Subject()})
+	log.Error(fmt.Sprintf("jetstream: handle message: %s", err))
+
+	var ackErr error
+	switch {
+	case errors.Is(err, ErrTerminate):
+		ackErr = msg.TermWithReason(err.Error())
+	case c.config.NakDelay > 0:
+		ackErr = msg.NakWithDelay(c.config.NakDelay)
+	default:
+		ackErr = msg.Nak()
+	}
+	if ackErr != nil {
+		log.Error(ackErr.Error())
+	}
+}
+
+func fromJetStream(msg natsjs.Msg) *messaging.Message {
+	out := &messaging.Message{
+		Topic: msg.Subject(),
+		Value: msg.Data(),
+	}
+	for key := range msg.Headers() {
+		out.SetHeader(key, msg.Headers().Get(key))
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		out.Offset = int64(meta.Sequence.Stream)
+		out.Timestamp = meta.Timestamp
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+// Package jetstream integrates NATS JetStream through nats.go, with stream
+// provisioning, deduplicated publishing and pull consumers.
+package jetstream
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/nats-io/nats.go"
+	natsjs "github.com/nats-io/nats.go/jetstream"
+)
+
+// HeaderMsgID is the header JetStream deduplicates published messages on,
+// within the duplicate window of the stream.
+const HeaderMsgID = natsjs.MsgIDHeader
+
+var errURLRequired = errors.New("jetstream: URL is required")
+
+// Config configures a Client.
+type Config struct {
+	// URL is the NATS server URL, or a comma separated list of them.
+	URL string
+
+	// Streams are created, or updated to match, on Connect.
+	Streams []natsjs.StreamConfig
+
+	// Options are extra nats.go connection options, e.g. credentials. The
+	// client always reconnects forever.
+	Options []nats.Option
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Client publishes to JetStream and creates its consumers. It implements
+// messaging.Publisher.
+type Client struct {
+	conn *nats.Conn
+	js   natsjs.JetStream
+	log  logger.Logger
+}
+
+// Connect connects to NATS and provisions the streams of config.
+func Connect(ctx context.Context, config Config) (*Client, error) {
+	if config.URL == "" {
+		return nil, errURLRequired
+	}
+
+	log := logger.WithLevel(config.Logger, logger.InfoLevel)
+	opts := append([]nats.Option{
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Warn(err.Error())
+			}
+		}),
+		nats.ReconnectHandler(func(*nats.Conn) {
+			log.Info("NATS connection reestablished")
+		}),
+	}, config.Options...)
+
+	conn, err := nats.Connect(config.URL, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := natsjs.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	for _, stream := range config.Streams {
+		if _, err := js.CreateOrUpdateStream(ctx, stream); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	return &Client{conn: conn, js: js, log: log}, nil
+}
+
+// Publish publishes msg to the msg.Topic subject and sets its offset to the
+// stream sequence. Messages with a HeaderMsgID already seen by the stream
+// are acknowledged without being stored again.
+func (c *Client) Publish(ctx context.Context, msg *messaging.Message) error {
+	messaging.InjectTraceContext(ctx, msg)
+	out := nats.NewMsg(msg.Topic)
+	out.Data = msg.Value
+	for key, value := range msg.Headers {
+		out.Header.Set(key, value)
+	}
+
+	ack, err := c.js.PublishMsg(ctx, out)
+	if err != nil {
+		c.log.With(logger.Fields{"subject": msg.Topic}).Error(err.Error())
+		return err
+	}
+	if ack.Duplicate {
+		c.log.With(logger.Fields{"subject": msg.Topic, "msgID": msg.Header(HeaderMsgID)}).Debug("Duplicate message ignored")
+	}
+	msg.Offset = int64(ack.Sequence)
+	return nil
+}
+
+// JetStream returns the underlying JetStream context for the operations not
+// covered by Client.
+func (c *Client) JetStream() natsjs.JetStream {
+	return c.js
+}
+
+// Close drains the connection.
+func (c *Client) Close() error {
+	return c.conn.Drain()
+}
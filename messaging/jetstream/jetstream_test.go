@@ -0,0 +1,163 @@
+package jetstream
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/nats-io/nats-server/v2/server"
+	natstest "github.com/nats-io/nats-server/v2/test"
+	natsjs "github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	opts := natstest.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	srv := natstest.RunServer(&opts)
+	t.Cleanup(srv.Shutdown)
+
+	client, err := Connect(context.Background(), Config{
+		URL: srv.ClientURL(),
+		Streams: []natsjs.StreamConfig{{
+			Name:       "ORDERS",
+			Subjects:   []string{"orders.>"},
+			Duplicates: time.Minute,
+		}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestConnectValidation(t *testing.T) {
+	_, err := Connect(context.Background(), Config{})
+	assert.ErrorIs(t, err, errURLRequired)
+}
+
+func TestClientPublishDeduplicates(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	for range 2 {
+		msg := &messaging.Message{Topic: "orders.created", Value: []byte(`{"id":1}`)}
+		msg.SetHeader(HeaderMsgID, "order-1")
+		require.NoError(t, client.Publish(ctx, msg))
+		assert.Equal(t, int64(1), msg.Offset)
+	}
+
+	stream, err := client.JetStream().Stream(ctx, "ORDERS")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), stream.CachedInfo().State.Msgs)
+}
+
+func TestNewConsumerValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ConsumerConfig
+		wantErr error
+	}{
+		{name: "missing stream", config: ConsumerConfig{Durable: "billing"}, wantErr: errStreamRequired},
+		{name: "missing durable", config: ConsumerConfig{Stream: "ORDERS"}, wantErr: errDurableRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConsumer(context.Background(), &Client{}, tt.config)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestConsumerRun(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, value := range []string{"ok", "retry", "poison"} {
+		msg := &messaging.Message{Topic: "orders.created", Value: []byte(value)}
+		msg.SetHeader("tenant", "acme")
+		require.NoError(t, client.Publish(ctx, msg))
+	}
+
+	consumer, err := NewConsumer(ctx, client, ConsumerConfig{Stream: "ORDERS", Durable: "billing", AckWait: time.Minute})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	deliveries := map[string]int{}
+	runCtx, stop := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(runCtx, func(_ context.Context, msg *messaging.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, "orders.created", msg.Topic)
+			assert.Equal(t, "acme", msg.Header("tenant"))
+
+			value := string(msg.Value)
+			deliveries[value]++
+			switch {
+			case value == "poison":
+				return fmt.Errorf("decode order: %w", ErrTerminate)
+			case value == "retry" && deliveries[value] == 1:
+				return fmt.Errorf("database unavailable")
+			}
+			return nil
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		info, err := consumer.consumer.Info(ctx)
+		require.NoError(t, err)
+		return info.NumAckPending == 0 && info.NumPending == 0 && info.Delivered.Consumer >= 4
+	}, 3*time.Second, 10*time.Millisecond)
+
+	stop()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]int{"ok": 1, "retry": 2, "poison": 1}, deliveries)
+}
+
+func TestConsumerDrain(t *testing.T) {
+	client := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, client.Publish(ctx, &messaging.Message{Topic: "orders.created", Value: []byte("slow")}))
+
+	consumer, err := NewConsumer(ctx, client, ConsumerConfig{Stream: "ORDERS", Durable: "billing", AckWait: time.Minute})
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx, func(context.Context, *messaging.Message) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	drained := make(chan error)
+	go func() { drained <- consumer.Drain(ctx) }()
+	close(release)
+
+	require.NoError(t, <-drained)
+	require.NoError(t, <-done)
+
+	info, err := consumer.consumer.Info(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, info.NumAckPending, "the in-flight message is acked before Run returns")
+}
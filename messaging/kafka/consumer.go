@@ -0,0 +1,522 @@
+// Package kafka integrates Kafka through IBM/sarama.
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo/health"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// rejoinDelay is the pause before rejoining the group after an error.
+const rejoinDelay = time.Second
+
+var defaultKafkaVersion = sarama.V2_8_0_0
+
+var (
+	errBrokersRequired = errors.New("kafka: at least one broker is required")
+	errGroupIDRequired = errors.New("kafka: group ID is required")
+	errNoHandlers      = errors.New("kafka: no handlers registered")
+	errConsumerStarted = errors.New("kafka: handlers must be registered before Run")
+)
+
+// ConsumerConfig configures a Consumer.
+type ConsumerConfig struct {
+	Brokers []string
+	GroupID string
+
+	// Concurrency is the number of messages of a partition handled at once,
+	// 1 by default. Messages with the same key are always handled in order,
+	// and offsets are only committed once every message before them is done.
+	Concurrency int
+
+	// MaxAttempts is the number of times a message is handled before it is
+	// given up on, 1 by default. RetryBackoff is the pause before the second
+	// attempt, doubled for every following one.
+	MaxAttempts  int
+	RetryBackoff time.Duration
+
+	// DeadLetter publishes the messages given up on to a dead-letter topic
+	// and moves on. Without it, a message given up on stops its partition
+	// until the group rejoins and it is consumed again.
+	DeadLetter *DeadLetterConfig
+
+	// Hooks are called with the partitions assigned and revoked at every
+	// rebalance.
+	Hooks messaging.Hooks
+
+	// Sarama is the base sarama configuration. When nil, the defaults are
+	// used with Kafka 2.8 and new groups starting from the oldest offset.
+	Sarama *sarama.Config
+
+	// Health registers a readiness check refreshing the metadata of the
+	// cluster, named HealthName, "kafka" by default. The consumer group
+	// then shares the client of the check.
+	Health     *health.Registry
+	HealthName string
+
+	// Logger receives the consumer logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Consumer runs the handlers of a consumer group, one per topic.
+type Consumer struct {
+	config   ConsumerConfig
+	group    sarama.ConsumerGroup
+	client   sarama.Client
+	log      logger.Logger
+	mu       sync.Mutex
+	handlers map[string]messaging.Handler
+	started  bool
+
+	lifecycle *messaging.Lifecycle
+}
+
+// NewConsumer creates a Consumer and joins no group until Run is called.
+func NewConsumer(config ConsumerConfig) (*Consumer, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errBrokersRequired
+	}
+	if config.GroupID == "" {
+		return nil, errGroupIDRequired
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 1
+	}
+
+	saramaConfig := config.Sarama
+	if saramaConfig == nil {
+		saramaConfig = sarama.NewConfig()
+		saramaConfig.Version = defaultKafkaVersion
+		saramaConfig.Consumer.Offsets.Initial = sarama.OffsetOldest
+	}
+
+	group, client, err := newConsumerGroup(config, saramaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Consumer{
+		config:   config,
+		group:    group,
+		client:   client,
+		log:      logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"groupID": config.GroupID}),
+		handlers: map[string]messaging.Handler{},
+
+		lifecycle: messaging.NewLifecycle(),
+	}, nil
+}
+
+// newConsumerGroup creates the group of config, sharing the client of its
+// health check when config.Health is set.
+func newConsumerGroup(config ConsumerConfig, saramaConfig *sarama.Config) (sarama.ConsumerGroup, sarama.Client, error) {
+	if config.Health == nil {
+		group, err := sarama.NewConsumerGroup(config.Brokers, config.GroupID, saramaConfig)
+		return group, nil, err
+	}
+	client, err := newRegisteredClient(config.Brokers, saramaConfig, config.Health, config.HealthName)
+	if err != nil {
+		return nil, nil, err
+	}
+	group, err := sarama.NewConsumerGroupFromClient(config.GroupID, client)
+	if err != nil {
+		_ = client.Close()
+		return nil, nil, err
+	}
+	return group, client, nil
+}
+
+// Handle registers the handler of topic.
+func (c *Consumer) Handle(topic string, handler messaging.Handler) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.started {
+		return errConsumerStarted
+	}
+	c.handlers[topic] = handler
+	return nil
+}
+
+// Run consumes the registered topics until ctx is done, Drain is called or
+// the process receives SIGTERM or SIGINT. On a drain or signal, fetching
+// stops and the in-flight messages are finished and their offsets committed
+// before it returns. Once ctx is done, the handlers see their context
+// cancelled.
+func (c *Consumer) Run(ctx context.Context) error {
+	c.mu.Lock()
+	if len(c.handlers) == 0 {
+		c.mu.Unlock()
+		return errNoHandlers
+	}
+	if !c.lifecycle.Start() {
+		c.mu.Unlock()
+		return nil
+	}
+	defer c.lifecycle.Stop()
+	c.started = true
+	topics := make([]string, 0, len(c.handlers))
+	for topic := range c.handlers {
+		topics = append(topics, topic)
+	}
+	c.mu.Unlock()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer c.group.Close()
+
+	handler := &groupHandler{
+		handlers:     c.handlers,
+		concurrency:  c.config.Concurrency,
+		maxAttempts:  c.config.MaxAttempts,
+		retryBackoff: c.config.RetryBackoff,
+		deadLetter:   c.config.DeadLetter,
+		hooks:        c.config.Hooks,
+		log:          c.log,
+		now:          time.Now,
+		stopping:     make(chan struct{}),
+	}
+
+	// Draining ends the session only once the claims are done, so that the
+	// offsets of their last messages are committed.
+	go func() {
+		select {
+		case <-c.lifecycle.Draining():
+		case <-signals.Done():
+		case <-ctx.Done():
+			return
+		}
+		handler.drain()
+		cancel()
+	}()
+
+	for {
+		// Consume returns at every rebalance and must be called again.
+		err := c.group.Consume(ctx, topics, handler)
+		if ctx.Err() != nil || errors.Is(err, sarama.ErrClosedConsumerGroup) {
+			return nil
+		}
+		if err != nil {
+			c.log.Error(err.Error())
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(rejoinDelay):
+			}
+		}
+	}
+}
+
+// Drain stops fetching, waits for the in-flight messages and their offset
+// commits, and returns once Run has returned or ctx is done.
+func (c *Consumer) Drain(ctx context.Context) error {
+	return c.lifecycle.Drain(ctx)
+}
+
+// Close leaves the group. Run returns once the current session ended.
+func (c *Consumer) Close() error {
+	err := c.group.Close()
+	if c.client != nil {
+		err = errors.Join(err, c.client.Close())
+	}
+	return err
+}
+
+// groupHandler implements sarama.ConsumerGroupHandler.
+type groupHandler struct {
+	handlers     map[string]messaging.Handler
+	concurrency  int
+	maxAttempts  int
+	retryBackoff time.Duration
+	deadLetter   *DeadLetterConfig
+	hooks        messaging.Hooks
+	log          logger.Logger
+	now          func() time.Time
+
+	// stopping is closed on drain, and claims tracks the claims running.
+	mu       sync.Mutex
+	stopping chan struct{}
+	drained  bool
+	claims   sync.WaitGroup
+}
+
+func (h *groupHandler) Setup(sess sarama.ConsumerGroupSession) error {
+	h.hooks.Assign(sess.Context(), sess.Claims())
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sess sarama.ConsumerGroupSession) error {
+	h.hooks.Revoke(context.WithoutCancel(sess.Context()), sess.Claims())
+	return nil
+}
+
+// startClaim registers a claim, unless the handler is drained.
+func (h *groupHandler) startClaim() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.drained {
+		return false
+	}
+	h.claims.Add(1)
+	return true
+}
+
+// drain stops the claims from fetching and waits for them to finish their
+// in-flight messages.
+func (h *groupHandler) drain() {
+	h.mu.Lock()
+	if !h.drained {
+		h.drained = true
+		if h.stopping != nil {
+			close(h.stopping)
+		}
+	}
+	h.mu.Unlock()
+	h.claims.Wait()
+}
+
+// isStopping reports whether the handler is drained, checked before
+// fetching so that a drain wins over ready messages.
+func (h *groupHandler) isStopping() bool {
+	select {
+	case <-h.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConsumeClaim handles the messages of a partition. A message failing every
+// attempt is sent to the dead-letter topic when there is one, or else stops
+// the partition without committing it until the session ends, so it is
+// consumed again once the group rejoins. The claim is kept open meanwhile:
+// returning would end the session of every partition and redeliver the
+// message at once.
+func (h *groupHandler) ConsumeClaim(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if !h.startClaim() {
+		return nil
+	}
+	defer h.claims.Done()
+
+	handler := h.handlers[claim.Topic()]
+	log := h.log.With(logger.Fields{"topic": claim.Topic(), "partition": claim.Partition()})
+
+	if h.concurrency <= 1 {
+		return h.consumeSequential(sess, claim, handler, log)
+	}
+	return h.consumeConcurrent(sess, claim, handler, log)
+}
+
+func (h *groupHandler) consumeSequential(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, handler messaging.Handler, log logger.Logger) error {
+	ctx := sess.Context()
+	for !h.isStopping() {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-h.stopping:
+			return nil
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if err := h.handle(ctx, handler, msg, log); err != nil {
+				log.Error(err.Error())
+				h.park(ctx)
+				return nil
+			}
+			sess.MarkMessage(msg, "")
+		}
+	}
+	return nil
+}
+
+func (h *groupHandler) consumeConcurrent(sess sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim, handler messaging.Handler, log logger.Logger) error {
+	ctx, cancel := context.WithCancelCause(sess.Context())
+	defer cancel(nil)
+
+	tracker := &offsetTracker{}
+	workers := make([]chan *sarama.ConsumerMessage, h.concurrency)
+	var wg sync.WaitGroup
+	for i := range workers {
+		workers[i] = make(chan *sarama.ConsumerMessage)
+		wg.Add(1)
+		go func(messages <-chan *sarama.ConsumerMessage) {
+			defer wg.Done()
+			for msg := range messages {
+				if ctx.Err() != nil {
+					continue
+				}
+				if err := h.handle(ctx, handler, msg, log); err != nil {
+					cancel(err)
+					continue
+				}
+				if next, ok := tracker.done(msg.Offset); ok {
+					sess.MarkOffset(msg.Topic, msg.Partition, next, "")
+				}
+			}
+		}(workers[i])
+	}
+
+	dispatch := func() {
+		for !h.isStopping() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.stopping:
+				return
+			case msg, ok := <-claim.Messages():
+				if !ok {
+					return
+				}
+				tracker.add(msg.Offset)
+				select {
+				case workers[h.worker(msg)] <- msg:
+				case <-ctx.Done():
+					return
+				case <-h.stopping:
+					return
+				}
+			}
+		}
+	}
+	dispatch()
+
+	for _, worker := range workers {
+		close(worker)
+	}
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil && sess.Context().Err() == nil {
+		log.Error(err.Error())
+		h.park(sess.Context())
+	}
+	return nil
+}
+
+// park waits for the session to end or the handler to be drained, keeping
+// the claim of a failed message open without consuming its partition.
+func (h *groupHandler) park(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-h.stopping:
+	}
+}
+
+// handle runs handler on msg up to maxAttempts times, then publishes it to
+// the dead-letter topic. It only fails when the message could be neither
+// handled nor dead-lettered.
+func (h *groupHandler) handle(ctx context.Context, handler messaging.Handler, msg *sarama.ConsumerMessage, log logger.Logger) error {
+	attempts := max(h.maxAttempts, 1)
+	backoff := h.retryBackoff
+	m := fromSarama(msg)
+	msgCtx := messaging.ExtractTraceContext(ctx, m)
+	log = logger.WithContext(msgCtx, log)
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			log.With(logger.Fields{"offset": msg.Offset, "attempt": attempt}).Warn(err.Error())
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = handler(msgCtx, fromSarama(msg)); err == nil {
+			return nil
+		}
+	}
+	err = fmt.Errorf("kafka: handle offset %d: %w", msg.Offset, err)
+
+	if h.deadLetter == nil {
+		return err
+	}
+	letter := h.deadLetter.deadLetter(fromSarama(msg), attempts, err, h.now())
+	if dlqErr := h.deadLetter.Publisher.Publish(ctx, letter); dlqErr != nil {
+		return errors.Join(err, fmt.Errorf("kafka: publish dead letter: %w", dlqErr))
+	}
+	log.With(logger.Fields{"offset": msg.Offset, "deadLetterTopic": letter.Topic}).Warn(err.Error())
+	return nil
+}
+
+// worker picks the worker of msg, by key so messages with the same key keep
+// their order.
+func (h *groupHandler) worker(msg *sarama.ConsumerMessage) int {
+	if len(msg.Key) == 0 {
+		return int(msg.Offset % int64(h.concurrency))
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write(msg.Key)
+	return int(hash.Sum32() % uint32(h.concurrency))
+}
+
+// offsetTracker computes the offset to commit when messages of a partition
+// complete out of order: the one following the last message all of whose
+// predecessors are done.
+type offsetTracker struct {
+	mu       sync.Mutex
+	inflight []int64
+	finished map[int64]bool
+}
+
+func (t *offsetTracker) add(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.inflight = append(t.inflight, offset)
+}
+
+// done records offset as handled and returns the offset to commit, if it
+// moved.
+func (t *offsetTracker) done(offset int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished == nil {
+		t.finished = map[int64]bool{}
+	}
+	t.finished[offset] = true
+
+	var last int64 = -1
+	for len(t.inflight) > 0 && t.finished[t.inflight[0]] {
+		last = t.inflight[0]
+		delete(t.finished, last)
+		t.inflight = t.inflight[1:]
+	}
+	if last < 0 {
+		return 0, false
+	}
+	return last + 1, true
+}
+
+func fromSarama(msg *sarama.ConsumerMessage) *messaging.Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+	return &messaging.Message{
+		Topic:     msg.Topic,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Headers:   headers,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp,
+	}
+}
@@ -0,0 +1,337 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeSession records the offsets marked by a claim.
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	ctx    context.Context
+	mu     sync.Mutex
+	marked []int64
+}
+
+func newFakeSession(ctx context.Context) *fakeSession {
+	return &fakeSession{ctx: ctx}
+}
+
+func (s *fakeSession) Context() context.Context { return s.ctx }
+
+func (s *fakeSession) Claims() map[string][]int32 { return map[string][]int32{"orders": {0, 1}} }
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, "")
+}
+
+func (s *fakeSession) MarkOffset(_ string, _ int32, offset int64, _ string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.marked = append(s.marked, offset)
+}
+
+func (s *fakeSession) Marked() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.marked...)
+}
+
+// fakeClaim serves a fixed list of messages and then closes.
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	messages chan *sarama.ConsumerMessage
+}
+
+func newFakeClaim(msgs ...*sarama.ConsumerMessage) *fakeClaim {
+	ch := make(chan *sarama.ConsumerMessage, len(msgs))
+	for _, msg := range msgs {
+		ch <- msg
+	}
+	close(ch)
+	return &fakeClaim{messages: ch}
+}
+
+func (c *fakeClaim) Topic() string                            { return "orders" }
+func (c *fakeClaim) Partition() int32                         { return 0 }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+func testMessages(n int) []*sarama.ConsumerMessage {
+	msgs := make([]*sarama.ConsumerMessage, n)
+	for i := range msgs {
+		msgs[i] = &sarama.ConsumerMessage{
+			Topic:   "orders",
+			Offset:  int64(i),
+			Key:     []byte{byte('a' + i%3)},
+			Value:   []byte("payload"),
+			Headers: []*sarama.RecordHeader{{Key: []byte("type"), Value: []byte("order.created")}},
+		}
+	}
+	return msgs
+}
+
+func TestNewConsumerValidation(t *testing.T) {
+	_, err := NewConsumer(ConsumerConfig{GroupID: "billing"})
+	assert.ErrorIs(t, err, errBrokersRequired)
+
+	_, err = NewConsumer(ConsumerConfig{Brokers: []string{"localhost:9092"}})
+	assert.ErrorIs(t, err, errGroupIDRequired)
+}
+
+func TestConsumeClaimSequential(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name       string
+		failAt     int64
+		wantMarked []int64
+	}{
+		{name: "all handled", failAt: -1, wantMarked: []int64{1, 2, 3, 4}},
+		{name: "stops at failure", failAt: 2, wantMarked: []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled []*messaging.Message
+			h := &groupHandler{
+				concurrency: 1,
+				log:         logger.Nop(),
+				handlers: map[string]messaging.Handler{
+					"orders": func(_ context.Context, msg *messaging.Message) error {
+						if msg.Offset == tt.failAt {
+							return errHandler
+						}
+						handled = append(handled, msg)
+						return nil
+					},
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			sess := newFakeSession(ctx)
+			require.NoError(t, h.ConsumeClaim(sess, newFakeClaim(testMessages(4)...)))
+
+			assert.Equal(t, tt.wantMarked, sess.Marked())
+			require.NotEmpty(t, handled)
+			assert.Equal(t, "order.created", handled[0].Header("type"))
+		})
+	}
+}
+
+func TestConsumeClaimConcurrent(t *testing.T) {
+	var mu sync.Mutex
+	lastByKey := map[string]int64{}
+
+	h := &groupHandler{
+		concurrency: 3,
+		log:         logger.Nop(),
+		handlers: map[string]messaging.Handler{
+			"orders": func(_ context.Context, msg *messaging.Message) error {
+				// Early messages are slower so they complete out of order.
+				time.Sleep(time.Duration(10-msg.Offset) * time.Millisecond)
+
+				mu.Lock()
+				defer mu.Unlock()
+				if last, ok := lastByKey[string(msg.Key)]; ok {
+					assert.Less(t, last, msg.Offset, "messages of a key are handled in order")
+				}
+				lastByKey[string(msg.Key)] = msg.Offset
+				return nil
+			},
+		},
+	}
+
+	sess := newFakeSession(context.Background())
+	require.NoError(t, h.ConsumeClaim(sess, newFakeClaim(testMessages(9)...)))
+
+	marked := sess.Marked()
+	require.NotEmpty(t, marked)
+	assert.IsIncreasing(t, marked)
+	assert.Equal(t, int64(9), marked[len(marked)-1])
+}
+
+func TestConsumeClaimConcurrentFailure(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	h := &groupHandler{
+		concurrency: 2,
+		log:         logger.Nop(),
+		handlers: map[string]messaging.Handler{
+			"orders": func(_ context.Context, msg *messaging.Message) error {
+				if msg.Offset == 3 {
+					return errHandler
+				}
+				return nil
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sess := newFakeSession(ctx)
+	require.NoError(t, h.ConsumeClaim(sess, newFakeClaim(testMessages(8)...)))
+
+	for _, offset := range sess.Marked() {
+		assert.LessOrEqual(t, offset, int64(3), "nothing past the failed message is committed")
+	}
+}
+
+func TestConsumeClaimFailingHandlerParksClaim(t *testing.T) {
+	for _, concurrency := range []int{1, 3} {
+		var calls atomic.Int32
+		h := &groupHandler{
+			concurrency: concurrency,
+			log:         logger.Nop(),
+			stopping:    make(chan struct{}),
+			handlers: map[string]messaging.Handler{
+				"orders": func(context.Context, *messaging.Message) error {
+					calls.Add(1)
+					return errors.New("handler failed")
+				},
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		sess := newFakeSession(ctx)
+		done := make(chan error, 1)
+		go func() { done <- h.ConsumeClaim(sess, newFakeClaim(testMessages(1)...)) }()
+
+		select {
+		case err := <-done:
+			t.Fatalf("concurrency %d: claim ended before the session: %v", concurrency, err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		assert.Equal(t, int32(1), calls.Load(), "the message is not redelivered within the session")
+		assert.Empty(t, sess.Marked())
+
+		cancel()
+		require.NoError(t, <-done)
+	}
+}
+
+func TestConsumeClaimFailingHandlerDrain(t *testing.T) {
+	h := &groupHandler{
+		concurrency: 1,
+		log:         logger.Nop(),
+		stopping:    make(chan struct{}),
+		handlers: map[string]messaging.Handler{
+			"orders": func(context.Context, *messaging.Message) error { return errors.New("handler failed") },
+		},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- h.ConsumeClaim(newFakeSession(context.Background()), newFakeClaim(testMessages(1)...)) }()
+	time.Sleep(10 * time.Millisecond)
+
+	h.drain()
+	require.NoError(t, <-done)
+}
+
+func TestOffsetTracker(t *testing.T) {
+	tracker := &offsetTracker{}
+	for _, offset := range []int64{10, 11, 13} {
+		tracker.add(offset)
+	}
+
+	_, ok := tracker.done(11)
+	assert.False(t, ok)
+
+	next, ok := tracker.done(10)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), next)
+
+	next, ok = tracker.done(13)
+	assert.True(t, ok)
+	assert.Equal(t, int64(14), next, "gaps in offsets, e.g. after compaction, are skipped")
+}
+
+func TestConsumeClaimExtractsTraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	var got trace.SpanContext
+	h := &groupHandler{
+		log: logger.Nop(),
+		handlers: map[string]messaging.Handler{
+			"orders": func(ctx context.Context, _ *messaging.Message) error {
+				got = trace.SpanContextFromContext(ctx)
+				return nil
+			},
+		},
+	}
+
+	msg := testMessages(1)[0]
+	msg.Headers = append(msg.Headers, &sarama.RecordHeader{
+		Key:   []byte("traceparent"),
+		Value: []byte("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+	})
+	require.NoError(t, h.ConsumeClaim(newFakeSession(context.Background()), newFakeClaim(msg)))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID().String())
+}
+
+func TestConsumeClaimDrain(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h := &groupHandler{
+		concurrency: 1,
+		log:         logger.Nop(),
+		stopping:    make(chan struct{}),
+		handlers: map[string]messaging.Handler{
+			"orders": func(ctx context.Context, _ *messaging.Message) error {
+				started <- struct{}{}
+				<-release
+				return ctx.Err()
+			},
+		},
+	}
+
+	// The claim stays open, as on a live partition.
+	messages := make(chan *sarama.ConsumerMessage, 3)
+	for _, msg := range testMessages(3) {
+		messages <- msg
+	}
+	sess := newFakeSession(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- h.ConsumeClaim(sess, &fakeClaim{messages: messages}) }()
+
+	<-started
+	drained := make(chan struct{})
+	go func() {
+		h.drain()
+		close(drained)
+	}()
+	require.Eventually(t, h.isStopping, time.Second, time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-done)
+	<-drained
+	assert.Equal(t, []int64{1}, sess.Marked(), "the in-flight message is committed, the next ones are left")
+	assert.NoError(t, h.ConsumeClaim(sess, &fakeClaim{messages: messages}), "claims of a drained handler return right away")
+}
+
+func TestGroupHandlerHooks(t *testing.T) {
+	var assigned, revoked messaging.Assignment
+	h := &groupHandler{hooks: messaging.Hooks{
+		OnAssign: func(_ context.Context, a messaging.Assignment) { assigned = a },
+		OnRevoke: func(_ context.Context, a messaging.Assignment) { revoked = a },
+	}}
+
+	sess := newFakeSession(context.Background())
+	require.NoError(t, h.Setup(sess))
+	assert.Equal(t, messaging.Assignment{"orders": {0, 1}}, assigned)
+	require.NoError(t, h.Cleanup(sess))
+	assert.Equal(t, messaging.Assignment{"orders": {0, 1}}, revoked)
+}
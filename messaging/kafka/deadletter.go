@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// Headers set on the messages published to a dead-letter topic.
+const (
+	HeaderDeadLetterError     = "x-dlq-error"
+	HeaderDeadLetterAttempts  = "x-dlq-attempts"
+	HeaderDeadLetterTopic     = "x-dlq-original-topic"
+	HeaderDeadLetterPartition = "x-dlq-original-partition"
+	HeaderDeadLetterOffset    = "x-dlq-original-offset"
+	HeaderDeadLetterFailedAt  = "x-dlq-failed-at"
+)
+
+const defaultDeadLetterSuffix = ".dlq"
+
+var errNotDeadLetter = errors.New("kafka: message has no original topic header")
+
+// DeadLetterConfig sends the messages still failing after every attempt to a
+// dead-letter topic instead of blocking their partition.
+type DeadLetterConfig struct {
+	// Publisher publishes the dead letters, usually a Producer.
+	Publisher messaging.Publisher
+
+	// Suffix is appended to the topic of a message to get its dead-letter
+	// topic, ".dlq" by default.
+	Suffix string
+}
+
+func (c *DeadLetterConfig) topic(topic string) string {
+	if c.Suffix == "" {
+		return topic + defaultDeadLetterSuffix
+	}
+	return topic + c.Suffix
+}
+
+// deadLetter returns the copy of msg published to the dead-letter topic,
+// with the failure recorded in its headers.
+func (c *DeadLetterConfig) deadLetter(msg *messaging.Message, attempts int, cause error, now time.Time) *messaging.Message {
+	letter := &messaging.Message{
+		Topic:     c.topic(msg.Topic),
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Timestamp: msg.Timestamp,
+	}
+	for key, value := range msg.Headers {
+		letter.SetHeader(key, value)
+	}
+	letter.SetHeader(HeaderDeadLetterError, cause.Error())
+	letter.SetHeader(HeaderDeadLetterAttempts, strconv.Itoa(attempts))
+	letter.SetHeader(HeaderDeadLetterTopic, msg.Topic)
+	letter.SetHeader(HeaderDeadLetterPartition, strconv.Itoa(int(msg.Partition)))
+	letter.SetHeader(HeaderDeadLetterOffset, strconv.FormatInt(msg.Offset, 10))
+	letter.SetHeader(HeaderDeadLetterFailedAt, now.UTC().Format(time.RFC3339))
+	return letter
+}
+
+// Redrive returns a handler publishing dead letters back to their original
+// topic, without the dead-letter headers. Register it on the dead-letter
+// topic once the cause of the failures is fixed:
+//
+//	redrive, _ := kafka.NewConsumer(kafka.ConsumerConfig{Brokers: brokers, GroupID: "orders-redrive"})
+//	redrive.Handle("orders.dlq", kafka.Redrive(producer))
+func Redrive(publisher messaging.Publisher) messaging.Handler {
+	return func(ctx context.Context, msg *messaging.Message) error {
+		topic := msg.Header(HeaderDeadLetterTopic)
+		if topic == "" {
+			return errNotDeadLetter
+		}
+
+		out := &messaging.Message{
+			Topic:     topic,
+			Key:       msg.Key,
+			Value:     msg.Value,
+			Timestamp: msg.Timestamp,
+		}
+		for key, value := range msg.Headers {
+			if !strings.HasPrefix(key, "x-dlq-") {
+				out.SetHeader(key, value)
+			}
+		}
+		return publisher.Publish(ctx, out)
+	}
+}
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordPublisher struct {
+	mu       sync.Mutex
+	messages []*messaging.Message
+	err      error
+}
+
+func (p *recordPublisher) Publish(_ context.Context, msg *messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func TestConsumeClaimRetryAndDeadLetter(t *testing.T) {
+	errHandler := errors.New("invalid payload")
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		failures     int
+		publishErr   error
+		wantCalls    int
+		wantLetters  int
+		wantParked   bool
+		wantAttempts string
+	}{
+		{name: "succeeds on retry", failures: 2, wantCalls: 3},
+		{name: "dead-lettered after every attempt", failures: 10, wantCalls: 3, wantLetters: 1, wantAttempts: "3"},
+		{name: "dead letter publish fails", failures: 10, publishErr: errors.New("broker down"), wantCalls: 3, wantParked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			publisher := &recordPublisher{err: tt.publishErr}
+			h := &groupHandler{
+				concurrency:  1,
+				maxAttempts:  3,
+				retryBackoff: time.Millisecond,
+				deadLetter:   &DeadLetterConfig{Publisher: publisher},
+				log:          logger.Nop(),
+				now:          func() time.Time { return now },
+				handlers: map[string]messaging.Handler{
+					"orders": func(context.Context, *messaging.Message) error {
+						calls++
+						if calls <= tt.failures {
+							return errHandler
+						}
+						return nil
+					},
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			sess := newFakeSession(ctx)
+			err := h.ConsumeClaim(sess, newFakeClaim(testMessages(1)...))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantParked {
+				assert.Empty(t, sess.Marked())
+				return
+			}
+			assert.Equal(t, []int64{1}, sess.Marked())
+
+			require.Len(t, publisher.messages, tt.wantLetters)
+			if tt.wantLetters == 0 {
+				return
+			}
+			letter := publisher.messages[0]
+			assert.Equal(t, "orders.dlq", letter.Topic)
+			assert.Equal(t, []byte("payload"), letter.Value)
+			assert.Equal(t, "order.created", letter.Header("type"))
+			assert.Contains(t, letter.Header(HeaderDeadLetterError), errHandler.Error())
+			assert.Equal(t, tt.wantAttempts, letter.Header(HeaderDeadLetterAttempts))
+			assert.Equal(t, "orders", letter.Header(HeaderDeadLetterTopic))
+			assert.Equal(t, "0", letter.Header(HeaderDeadLetterOffset))
+			assert.Equal(t, "2024-05-01T10:00:00Z", letter.Header(HeaderDeadLetterFailedAt))
+		})
+	}
+}
+
+func TestDeadLetterConfigTopic(t *testing.T) {
+	assert.Equal(t, "orders.dlq", (&DeadLetterConfig{}).topic("orders"))
+	assert.Equal(t, "orders-failed", (&DeadLetterConfig{Suffix: "-failed"}).topic("orders"))
+}
+
+func TestRedrive(t *testing.T) {
+	publisher := &recordPublisher{}
+	letter := (&DeadLetterConfig{}).deadLetter(
+		fromSarama(&sarama.ConsumerMessage{
+			Topic:   "orders",
+			Key:     []byte("a"),
+			Value:   []byte("payload"),
+			Headers: []*sarama.RecordHeader{{Key: []byte("type"), Value: []byte("order.created")}},
+		}),
+		3, errors.New("invalid payload"), time.Now(),
+	)
+
+	require.NoError(t, Redrive(publisher)(context.Background(), letter))
+	require.Len(t, publisher.messages, 1)
+	assert.Equal(t, &messaging.Message{
+		Topic:   "orders",
+		Key:     []byte("a"),
+		Value:   []byte("payload"),
+		Headers: map[string]string{"type": "order.created"},
+	}, publisher.messages[0])
+
+	err := Redrive(publisher)(context.Background(), &messaging.Message{Topic: "orders.dlq"})
+	assert.ErrorIs(t, err, errNotDeadLetter)
+}
@@ -0,0 +1,62 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo/health"
+)
+
+var errNoBrokers = errors.New("kafka: no broker available")
+
+// ClusterCheck returns a check refreshing the metadata of the cluster
+// through client, reporting its latency and the number of brokers:
+//
+//	client, err := sarama.NewClient(brokers, sarama.NewConfig())
+//	checks.AddReadinessCheck("kafka", kafka.ClusterCheck(client))
+func ClusterCheck(client sarama.Client) health.Check {
+	return func(ctx context.Context) health.Result {
+		started := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			// Sarama takes no context, the refresh continues in the
+			// background after a timeout.
+			done <- client.RefreshMetadata()
+		}()
+
+		var err error
+		select {
+		case err = <-done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		details := map[string]any{"latency_ms": time.Since(started).Milliseconds()}
+		if err != nil {
+			return health.Down(err, details)
+		}
+
+		brokers := len(client.Brokers())
+		details["brokers"] = brokers
+		if brokers == 0 {
+			return health.Down(errNoBrokers, details)
+		}
+		return health.Up(details)
+	}
+}
+
+// newRegisteredClient creates a client of brokers, shared with a producer or
+// consumer, and registers its ClusterCheck on registry as name, "kafka" by
+// default.
+func newRegisteredClient(brokers []string, config *sarama.Config, registry *health.Registry, name string) (sarama.Client, error) {
+	client, err := sarama.NewClient(brokers, config)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = "kafka"
+	}
+	registry.Register(name, ClusterCheck(client))
+	return client, nil
+}
@@ -0,0 +1,166 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo/health"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// ProducerConfig configures a Producer.
+type ProducerConfig struct {
+	Brokers []string
+
+	// Sarama is the base sarama configuration. When nil, the defaults are
+	// used with Kafka 2.8 and acknowledgements from all in-sync replicas.
+	// Producer.Return.Successes is always enabled, as the producer is
+	// synchronous.
+	Sarama *sarama.Config
+
+	// Health registers a readiness check refreshing the metadata of the
+	// cluster, named HealthName, "kafka" by default. The producer then
+	// shares the client of the check.
+	Health     *health.Registry
+	HealthName string
+
+	// Logger receives the producer logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Producer publishes messages synchronously. It implements
+// messaging.Publisher and messaging.BatchPublisher.
+type Producer struct {
+	producer sarama.SyncProducer
+	// client is the client shared with the health check, if any.
+	client sarama.Client
+	log    logger.Logger
+}
+
+// NewProducer creates a Producer connected to the brokers.
+func NewProducer(config ProducerConfig) (*Producer, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errBrokersRequired
+	}
+
+	saramaConfig := config.Sarama
+	if saramaConfig == nil {
+		saramaConfig = sarama.NewConfig()
+		saramaConfig.Version = defaultKafkaVersion
+		saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+	}
+	saramaConfig.Producer.Return.Successes = true
+
+	if config.Health == nil {
+		producer, err := sarama.NewSyncProducer(config.Brokers, saramaConfig)
+		if err != nil {
+			return nil, err
+		}
+		return newProducer(producer, config.Logger), nil
+	}
+
+	client, err := newRegisteredClient(config.Brokers, saramaConfig, config.Health, config.HealthName)
+	if err != nil {
+		return nil, err
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	p := newProducer(producer, config.Logger)
+	p.client = client
+	return p, nil
+}
+
+func newProducer(producer sarama.SyncProducer, log logger.Logger) *Producer {
+	return &Producer{
+		producer: producer,
+		log:      logger.WithLevel(log, logger.InfoLevel),
+	}
+}
+
+// Publish sends msg to msg.Topic and sets its partition and offset.
+func (p *Producer) Publish(ctx context.Context, msg *messaging.Message) error {
+	messaging.InjectTraceContext(ctx, msg)
+	partition, offset, err := p.producer.SendMessage(toSarama(msg))
+	if err != nil {
+		p.log.With(logger.Fields{"topic": msg.Topic}).Error(err.Error())
+		return err
+	}
+	msg.Partition, msg.Offset = partition, offset
+	return nil
+}
+
+// PublishBatch sends msgs, which may go to different topics, and sets the
+// partition and offset of the published ones. The failed messages are
+// reported with a *messaging.BatchError.
+//
+// Sarama sends the messages concurrently, so their order is only kept
+// within a partition when Net.MaxOpenRequests is 1.
+func (p *Producer) PublishBatch(ctx context.Context, msgs []*messaging.Message) error {
+	out := make([]*sarama.ProducerMessage, len(msgs))
+	index := make(map[*sarama.ProducerMessage]int, len(msgs))
+	for i, msg := range msgs {
+		messaging.InjectTraceContext(ctx, msg)
+		out[i] = toSarama(msg)
+		index[out[i]] = i
+	}
+
+	err := p.producer.SendMessages(out)
+	var errs []messaging.MessageError
+	var producerErrs sarama.ProducerErrors
+	switch {
+	case err == nil:
+	case errors.As(err, &producerErrs):
+		for _, producerErr := range producerErrs {
+			errs = append(errs, messaging.MessageError{Index: index[producerErr.Msg], Err: producerErr.Err})
+		}
+	default:
+		for i := range msgs {
+			errs = append(errs, messaging.MessageError{Index: i, Err: err})
+		}
+	}
+
+	failed := make(map[int]bool, len(errs))
+	for _, err := range errs {
+		failed[err.Index] = true
+	}
+	for i, msg := range msgs {
+		if !failed[i] {
+			msg.Partition, msg.Offset = out[i].Partition, out[i].Offset
+		}
+	}
+
+	err = messaging.NewBatchError(errs)
+	if err != nil {
+		p.log.Error(err.Error())
+	}
+	return err
+}
+
+// Close flushes and closes the producer.
+func (p *Producer) Close() error {
+	err := p.producer.Close()
+	if p.client != nil {
+		err = errors.Join(err, p.client.Close())
+	}
+	return err
+}
+
+func toSarama(msg *messaging.Message) *sarama.ProducerMessage {
+	out := &sarama.ProducerMessage{
+		Topic:     msg.Topic,
+		Value:     sarama.ByteEncoder(msg.Value),
+		Timestamp: msg.Timestamp,
+	}
+	if len(msg.Key) > 0 {
+		out.Key = sarama.ByteEncoder(msg.Key)
+	}
+	for key, value := range msg.Headers {
+		out.Headers = append(out.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+	}
+	return out
+}
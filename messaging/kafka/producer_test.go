@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProducerPublish(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	mock.ExpectSendMessageWithMessageCheckerFunctionAndSucceed(func(msg *sarama.ProducerMessage) error {
+		assert.Equal(t, "orders", msg.Topic)
+		key, _ := msg.Key.Encode()
+		assert.Equal(t, []byte("a"), key)
+		require.Len(t, msg.Headers, 1)
+		assert.Equal(t, "type", string(msg.Headers[0].Key))
+		return nil
+	})
+	mock.ExpectSendMessageAndFail(errors.New("broker down"))
+
+	producer := newProducer(mock, nil)
+	defer producer.Close()
+
+	msg := &messaging.Message{Topic: "orders", Key: []byte("a"), Value: []byte("payload")}
+	msg.SetHeader("type", "order.created")
+	require.NoError(t, producer.Publish(context.Background(), msg))
+	assert.Equal(t, int64(1), msg.Offset)
+
+	assert.Error(t, producer.Publish(context.Background(), &messaging.Message{Topic: "orders"}))
+}
+
+func TestNewProducerValidation(t *testing.T) {
+	_, err := NewProducer(ProducerConfig{})
+	assert.ErrorIs(t, err, errBrokersRequired)
+}
+
+// partialProducer fails the messages with a given value, the way a sarama
+// SyncProducer reports partial batch failures.
+type partialProducer struct {
+	sarama.SyncProducer
+	fail string
+}
+
+func (p *partialProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for i, msg := range msgs {
+		value, _ := msg.Value.Encode()
+		if string(value) == p.fail {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: sarama.ErrMessageSizeTooLarge})
+			continue
+		}
+		msg.Partition, msg.Offset = 0, int64(i+1)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestProducerPublishBatch(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	mock.ExpectSendMessageAndSucceed()
+	mock.ExpectSendMessageAndSucceed()
+
+	producer := newProducer(mock, nil)
+	defer producer.Close()
+
+	msgs := []*messaging.Message{{Topic: "orders", Value: []byte("a")}, {Topic: "payments", Value: []byte("b")}}
+	require.NoError(t, producer.PublishBatch(context.Background(), msgs))
+	assert.Equal(t, int64(1), msgs[0].Offset)
+	assert.Equal(t, int64(2), msgs[1].Offset)
+}
+
+func TestProducerPublishBatchPartialFailure(t *testing.T) {
+	producer := newProducer(&partialProducer{fail: "b"}, nil)
+
+	msgs := []*messaging.Message{{Topic: "orders", Value: []byte("a")}, {Topic: "orders", Value: []byte("b")}, {Topic: "orders", Value: []byte("c")}}
+	err := producer.PublishBatch(context.Background(), msgs)
+
+	var batchErr *messaging.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.Equal(t, []*messaging.Message{msgs[1]}, batchErr.Failed(msgs))
+	assert.ErrorIs(t, err, sarama.ErrMessageSizeTooLarge)
+	assert.Equal(t, int64(3), msgs[2].Offset, "published messages get their offset")
+}
@@ -0,0 +1,104 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// Assignment lists the topics, queues or streams a consumer receives from,
+// with their partitions on partitioned brokers such as Kafka.
+type Assignment map[string][]int32
+
+// Hooks are called by the consumers as their assignment changes: on Kafka at
+// every rebalance, on the other brokers when consuming starts and stops.
+type Hooks struct {
+	// OnAssign is called before the first message of an assignment is
+	// handled.
+	OnAssign func(ctx context.Context, assignment Assignment)
+	// OnRevoke is called once the handlers of a revoked assignment have
+	// finished, before the messages of the assignment are consumed
+	// elsewhere.
+	OnRevoke func(ctx context.Context, assignment Assignment)
+}
+
+// Assign calls OnAssign, if set.
+func (h Hooks) Assign(ctx context.Context, assignment Assignment) {
+	if h.OnAssign != nil {
+		h.OnAssign(ctx, assignment)
+	}
+}
+
+// Revoke calls OnRevoke, if set.
+func (h Hooks) Revoke(ctx context.Context, assignment Assignment) {
+	if h.OnRevoke != nil {
+		h.OnRevoke(ctx, assignment)
+	}
+}
+
+// Lifecycle tracks whether a consumer is running and asks it to drain. It is
+// the shared implementation of the Drain method of the consumers.
+type Lifecycle struct {
+	mu        sync.Mutex
+	running   bool
+	done      chan struct{}
+	drainOnce sync.Once
+	draining  chan struct{}
+}
+
+// NewLifecycle creates a Lifecycle.
+func NewLifecycle() *Lifecycle {
+	return &Lifecycle{draining: make(chan struct{})}
+}
+
+// Start marks the consumer as running. It returns false once the consumer
+// was asked to drain, and the consumer must not run.
+func (l *Lifecycle) Start() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	select {
+	case <-l.draining:
+		return false
+	default:
+	}
+	l.running = true
+	l.done = make(chan struct{})
+	return true
+}
+
+// Stop marks the consumer as stopped, releasing the Drain calls.
+func (l *Lifecycle) Stop() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.running {
+		l.running = false
+		close(l.done)
+	}
+}
+
+// Draining is closed once the consumer is asked to drain: it must stop
+// fetching, finish the messages being handled and stop.
+func (l *Lifecycle) Draining() <-chan struct{} {
+	return l.draining
+}
+
+// Drain asks the consumer to drain and waits until it stopped, or ctx is
+// done.
+func (l *Lifecycle) Drain(ctx context.Context) error {
+	l.drainOnce.Do(func() { close(l.draining) })
+
+	l.mu.Lock()
+	running, done := l.running, l.done
+	l.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,54 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLifecycleDrain(t *testing.T) {
+	l := NewLifecycle()
+	assert.True(t, l.Start())
+
+	drained := make(chan error)
+	go func() { drained <- l.Drain(context.Background()) }()
+
+	<-l.Draining()
+	select {
+	case <-drained:
+		t.Fatal("Drain returned before the consumer stopped")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	l.Stop()
+	assert.NoError(t, <-drained)
+	assert.False(t, l.Start(), "a drained consumer does not start again")
+}
+
+func TestLifecycleDrainNotRunning(t *testing.T) {
+	assert.NoError(t, NewLifecycle().Drain(context.Background()))
+}
+
+func TestLifecycleDrainTimeout(t *testing.T) {
+	l := NewLifecycle()
+	l.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, l.Drain(ctx), context.DeadlineExceeded)
+}
+
+func TestHooks(t *testing.T) {
+	var got []string
+	hooks := Hooks{OnAssign: func(_ context.Context, a Assignment) {
+		for topic := range a {
+			got = append(got, topic)
+		}
+	}}
+
+	hooks.Assign(context.Background(), Assignment{"orders": {0}})
+	hooks.Revoke(context.Background(), Assignment{"orders": {0}})
+	assert.Equal(t, []string{"orders"}, got, "unset hooks are skipped")
+}
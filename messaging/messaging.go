@@ -0,0 +1,62 @@
+// Package messaging holds the broker-agnostic types shared by the broker
+// integrations in its sub packages.
+package messaging
+
+import (
+	"context"
+	"time"
+)
+
+// HeaderMessageID is the header carrying the unique ID of a message, the
+// same across redeliveries.
+const HeaderMessageID = "x-message-id"
+
+// HeaderDeduplicationID is the header carrying the deduplication ID of a
+// message, for the brokers deduplicating on the producer side such as SQS
+// and SNS FIFO queues and topics.
+const HeaderDeduplicationID = "x-deduplication-id"
+
+// Message is a message received from or published to a broker.
+type Message struct {
+	// Topic is the topic, queue or subject of the message.
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+
+	// Partition and Offset locate a received message on partitioned
+	// brokers.
+	Partition int32
+	Offset    int64
+	Timestamp time.Time
+}
+
+// Header returns the value of a header, or an empty string.
+func (m *Message) Header(key string) string {
+	return m.Headers[key]
+}
+
+// SetHeader sets a header, allocating the headers when needed.
+func (m *Message) SetHeader(key, value string) {
+	if m.Headers == nil {
+		m.Headers = map[string]string{}
+	}
+	m.Headers[key] = value
+}
+
+// Handler processes a received message. Returning an error leaves the
+// message unacknowledged, so the broker delivers it again.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Publisher publishes messages to the topic set on them.
+type Publisher interface {
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// DelayedPublisher publishes messages delivered once a delay has elapsed,
+// for retry-later and reminder use cases. It is implemented natively by the
+// brokers supporting delays, and by the Redis scheduler of the scheduler
+// package for the others.
+type DelayedPublisher interface {
+	PublishAfter(ctx context.Context, msg *Message, delay time.Duration) error
+}
@@ -0,0 +1,213 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Middleware wraps a Handler, the same way for every broker.
+type Middleware func(Handler) Handler
+
+// Chain wraps handler with middlewares, the first one being the outermost:
+//
+//	handler := messaging.Chain(handleOrder,
+//		messaging.Recover(log),
+//		messaging.Logging(log),
+//		messaging.Retry(messaging.RetryConfig{}),
+//		messaging.Timeout(10*time.Second),
+//	)
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// RetryConfig configures Retry.
+type RetryConfig struct {
+	// MaxAttempts is the number of times a message is handled before the
+	// error is returned, 3 by default.
+	MaxAttempts int
+	// Backoff returns the delay before the given attempt, starting at 2.
+	// Defaults to an exponential backoff from 100ms up to 10s.
+	Backoff func(attempt int) time.Duration
+	// Retryable reports whether an error is worth retrying. All errors are
+	// by default.
+	Retryable func(err error) bool
+}
+
+// Retry handles a failed message again, in place, before giving it back to
+// the broker. The retries stop early when ctx is done.
+func Retry(config RetryConfig) Middleware {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff == nil {
+		config.Backoff = platigo.ExponentialBackoff(100*time.Millisecond, 10*time.Second)
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			var err error
+			for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
+				if attempt > 1 {
+					select {
+					case <-ctx.Done():
+						return errors.Join(err, ctx.Err())
+					case <-time.After(config.Backoff(attempt)):
+					}
+				}
+
+				if err = next(ctx, msg); err == nil {
+					return nil
+				}
+				if config.Retryable != nil && !config.Retryable(err) {
+					return err
+				}
+			}
+			return err
+		}
+	}
+}
+
+// Recover turns a panicking handler into an error, logged with its stack
+// trace, instead of crashing the consumer.
+func Recover(log logger.Logger) Middleware {
+	if log == nil {
+		log = logger.Nop()
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("messaging: handler panic: %v", r)
+					log.With(logger.Fields{
+						"topic": msg.Topic,
+						"stack": string(debug.Stack()),
+					}).Error(err.Error())
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Timeout cancels the context of a handler running longer than timeout.
+// Handlers must honor ctx for it to have any effect.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging logs every message handled at debug level and the failures at
+// error level.
+func Logging(log logger.Logger) Middleware {
+	if log == nil {
+		log = logger.Nop()
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			started := time.Now()
+			err := next(ctx, msg)
+
+			fields := logger.Fields{
+				"topic":    msg.Topic,
+				"duration": time.Since(started).String(),
+			}
+			if id := msg.Header(HeaderMessageID); id != "" {
+				fields["messageID"] = id
+			}
+			if err != nil {
+				log.With(fields).Error(err.Error())
+				return err
+			}
+			log.With(fields).Debug("Message handled")
+			return nil
+		}
+	}
+}
+
+// HandlerMetrics holds the Prometheus metrics of message handlers. It
+// implements prometheus.Collector, so it can be registered on any registry:
+//
+//	metrics := messaging.NewHandlerMetrics("myservice")
+//	prometheus.MustRegister(metrics)
+//	handler := messaging.Chain(handleOrder, messaging.Metrics(metrics))
+type HandlerMetrics struct {
+	messages *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+}
+
+// NewHandlerMetrics creates the handler metrics under the given namespace.
+func NewHandlerMetrics(namespace string) *HandlerMetrics {
+	labels := []string{"topic"}
+
+	return &HandlerMetrics{
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "messaging",
+			Name:      "messages_handled_total",
+			Help:      "Total number of messages handled.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "messaging",
+			Name:      "handler_errors_total",
+			Help:      "Total number of messages whose handler failed.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "messaging",
+			Name:      "handler_duration_seconds",
+			Help:      "Latency of message handlers.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *HandlerMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.messages.Describe(ch)
+	m.errors.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *HandlerMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.messages.Collect(ch)
+	m.errors.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// Metrics records the count, failures and latency of the messages handled,
+// by topic.
+func Metrics(metrics *HandlerMetrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *Message) error {
+			started := time.Now()
+			err := next(ctx, msg)
+
+			metrics.messages.WithLabelValues(msg.Topic).Inc()
+			if err != nil {
+				metrics.errors.WithLabelValues(msg.Topic).Inc()
+			}
+			metrics.latency.WithLabelValues(msg.Topic).Observe(time.Since(started).Seconds())
+			return err
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *Message) error {
+				order = append(order, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	handler := Chain(func(context.Context, *Message) error {
+		order = append(order, "handler")
+		return nil
+	}, trace("outer"), trace("inner"))
+
+	require.NoError(t, handler(context.Background(), &Message{}))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestRetry(t *testing.T) {
+	errTransient := errors.New("transient")
+	errPermanent := errors.New("permanent")
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "succeeds first", wantCalls: 1},
+		{name: "succeeds on retry", errs: []error{errTransient, errTransient}, wantCalls: 3},
+		{name: "gives up", errs: []error{errTransient, errTransient, errTransient, errTransient}, wantCalls: 3, wantErr: errTransient},
+		{name: "not retryable", errs: []error{errPermanent}, wantCalls: 1, wantErr: errPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			handler := Chain(func(context.Context, *Message) error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			}, Retry(RetryConfig{
+				Backoff:   func(int) time.Duration { return time.Millisecond },
+				Retryable: func(err error) bool { return !errors.Is(err, errPermanent) },
+			}))
+
+			err := handler(context.Background(), &Message{})
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	handler := Chain(func(context.Context, *Message) error {
+		cancel()
+		return errors.New("transient")
+	}, Retry(RetryConfig{Backoff: func(int) time.Duration { return time.Hour }}))
+
+	assert.ErrorIs(t, handler(ctx, &Message{}), context.Canceled)
+}
+
+func TestRecover(t *testing.T) {
+	handler := Chain(func(context.Context, *Message) error {
+		panic("nil map")
+	}, Recover(nil))
+
+	err := handler(context.Background(), &Message{Topic: "orders"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "handler panic: nil map")
+}
+
+func TestTimeout(t *testing.T) {
+	handler := Chain(func(ctx context.Context, _ *Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, Timeout(time.Millisecond))
+
+	assert.ErrorIs(t, handler(context.Background(), &Message{}), context.DeadlineExceeded)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := NewHandlerMetrics("test")
+	fail := false
+	handler := Chain(func(context.Context, *Message) error {
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	}, Logging(nil), Metrics(metrics))
+
+	msg := &Message{Topic: "orders"}
+	require.NoError(t, handler(context.Background(), msg))
+	fail = true
+	require.Error(t, handler(context.Background(), msg))
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.messages.WithLabelValues("orders")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.errors.WithLabelValues("orders")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.latency))
+}
@@ -0,0 +1,81 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics holds the Prometheus metrics of a Relay. It implements
+// prometheus.Collector, so it can be registered on any registry:
+//
+//	metrics := outbox.NewMetrics("myservice")
+//	prometheus.MustRegister(metrics)
+//	relay, err := outbox.NewRelay(db, producer, outbox.Config{Metrics: metrics})
+type Metrics struct {
+	published *prometheus.CounterVec
+	errors    *prometheus.CounterVec
+	pending   prometheus.Gauge
+	lag       prometheus.Gauge
+}
+
+// NewMetrics creates the relay metrics under the given namespace.
+func NewMetrics(namespace string) *Metrics {
+	labels := []string{"topic"}
+
+	return &Metrics{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "messages_published_total",
+			Help:      "Total number of outbox messages published.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "publish_errors_total",
+			Help:      "Total number of outbox messages whose publish failed.",
+		}, labels),
+		pending: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "pending_messages",
+			Help:      "Number of outbox messages not published yet.",
+		}),
+		lag: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "lag_seconds",
+			Help:      "Age of the oldest outbox message not published yet.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.published.Describe(ch)
+	m.errors.Describe(ch)
+	m.pending.Describe(ch)
+	m.lag.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.published.Collect(ch)
+	m.errors.Collect(ch)
+	m.pending.Collect(ch)
+	m.lag.Collect(ch)
+}
+
+func (m *Metrics) observePublish(topic string, err error) {
+	if err != nil {
+		m.errors.WithLabelValues(topic).Inc()
+		return
+	}
+	m.published.WithLabelValues(topic).Inc()
+}
+
+func (m *Metrics) observeLag(pending int64, lag time.Duration) {
+	m.pending.Set(float64(pending))
+	m.lag.Set(lag.Seconds())
+}
@@ -0,0 +1,104 @@
+// Package outbox publishes messages written in the database transaction of
+// the change they announce, so the change and its messages are never
+// committed one without the other.
+//
+// Writer adds the messages to the outbox table within the transaction, and
+// Relay publishes them afterwards with any messaging.Publisher, such as the
+// Kafka or SQS producers:
+//
+//	err := txm.RunInTx(ctx, func(ctx context.Context) error {
+//		if _, err := tx.ExecContext(ctx, "UPDATE orders SET status = 'paid' WHERE id = $1", id); err != nil {
+//			return err
+//		}
+//		return writer.Add(ctx, tx, &messaging.Message{Topic: "orders.paid", Value: payload})
+//	})
+//
+//	relay, err := outbox.NewRelay(db, producer, outbox.Config{})
+//	go relay.Run(ctx)
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+)
+
+const defaultTable = "outbox_messages"
+
+var errInvalidTable = errors.New("outbox: invalid table name")
+
+// Execer runs statements, *sql.Tx and *sqlx.Tx among others.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Writer adds messages to the outbox table.
+type Writer struct {
+	insertQuery string
+	now         func() time.Time
+}
+
+// NewWriter creates a Writer on table, "outbox_messages" by default, using
+// the PostgreSQL dialect:
+//
+//	CREATE TABLE outbox_messages (
+//		id         BIGSERIAL PRIMARY KEY,
+//		topic      TEXT NOT NULL,
+//		key        BYTEA,
+//		value      BYTEA NOT NULL,
+//		headers    TEXT,
+//		created_at TIMESTAMPTZ NOT NULL,
+//		sent_at    TIMESTAMPTZ
+//	);
+//	CREATE INDEX outbox_messages_unsent ON outbox_messages (id) WHERE sent_at IS NULL;
+func NewWriter(table string) (*Writer, error) {
+	if table == "" {
+		table = defaultTable
+	}
+	if !validIdentifier(table) {
+		return nil, errInvalidTable
+	}
+	return &Writer{
+		insertQuery: fmt.Sprintf(`INSERT INTO %s (topic, key, value, headers, created_at) VALUES ($1, $2, $3, $4, $5)`, table),
+		now:         time.Now,
+	}, nil
+}
+
+// Add adds msgs to the outbox with tx, the transaction of the change they
+// announce. The trace context of ctx is stored with them and continued by
+// the relay.
+func (w *Writer) Add(ctx context.Context, tx Execer, msgs ...*messaging.Message) error {
+	for _, msg := range msgs {
+		messaging.InjectTraceContext(ctx, msg)
+		var headers []byte
+		if len(msg.Headers) > 0 {
+			var err error
+			if headers, err = json.Marshal(msg.Headers); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, w.insertQuery, msg.Topic, msg.Key, msg.Value, headers, w.now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validIdentifier reports whether name is safe to use as table name, with an
+// optional schema.
+func validIdentifier(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == '.':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
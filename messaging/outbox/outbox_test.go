@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterAdd(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	writer, err := NewWriter("")
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	writer.now = func() time.Time { return now }
+
+	insert := regexp.QuoteMeta("INSERT INTO outbox_messages (topic, key, value, headers, created_at) VALUES ($1, $2, $3, $4, $5)")
+	mock.ExpectBegin()
+	mock.ExpectExec(insert).WithArgs("orders.paid", []byte("order-1"), []byte(`{"id":1}`), []byte(`{"x-message-id":"msg-1"}`), now).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(insert).WithArgs("orders.paid", []byte(nil), []byte(`{"id":2}`), []byte(nil), now).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	err = writer.Add(context.Background(), tx,
+		&messaging.Message{Topic: "orders.paid", Key: []byte("order-1"), Value: []byte(`{"id":1}`), Headers: map[string]string{messaging.HeaderMessageID: "msg-1"}},
+		&messaging.Message{Topic: "orders.paid", Value: []byte(`{"id":2}`)},
+	)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewWriterInvalidTable(t *testing.T) {
+	_, err := NewWriter("outbox; DROP TABLE users")
+	assert.ErrorIs(t, err, errInvalidTable)
+}
@@ -0,0 +1,241 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+)
+
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+)
+
+var errPublisherRequired = errors.New("outbox: publisher is required")
+
+// Config configures a Relay.
+type Config struct {
+	// Table is the outbox table, "outbox_messages" by default.
+	Table string
+	// PollInterval is how often unsent messages are looked up, one second
+	// by default. It bounds how late messages are published.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of messages published per
+	// transaction, 100 by default.
+	BatchSize int
+
+	// Metrics records the published messages and the outbox lag when set.
+	Metrics *Metrics
+	// Logger receives the relay logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Relay publishes the messages of the outbox table in insertion order and
+// marks them sent.
+//
+// Messages are published at least once: a relay stopping between the
+// publish and the commit publishes them again. Any number of relays may run
+// on the same table, the rows being locked with SKIP LOCKED, but messages
+// are then only ordered within a batch.
+type Relay struct {
+	db        *sql.DB
+	publisher messaging.Publisher
+	config    Config
+	metrics   *Metrics
+	log       logger.Logger
+	now       func() time.Time
+
+	selectQuery string
+	sentQuery   string
+	statsQuery  string
+	purgeQuery  string
+}
+
+// NewRelay creates a Relay publishing the messages of the outbox on db with
+// publisher.
+func NewRelay(db *sql.DB, publisher messaging.Publisher, config Config) (*Relay, error) {
+	if publisher == nil {
+		return nil, errPublisherRequired
+	}
+	if config.Table == "" {
+		config.Table = defaultTable
+	}
+	if !validIdentifier(config.Table) {
+		return nil, errInvalidTable
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+
+	table := config.Table
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+		metrics:   config.Metrics,
+		log:       logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"table": table}),
+		now:       time.Now,
+
+		selectQuery: fmt.Sprintf(`SELECT id, topic, key, value, headers FROM %s WHERE sent_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`, table),
+		sentQuery:   fmt.Sprintf(`UPDATE %s SET sent_at = $1 WHERE id = $2`, table),
+		statsQuery:  fmt.Sprintf(`SELECT COUNT(*), MIN(created_at) FROM %s WHERE sent_at IS NULL`, table),
+		purgeQuery:  fmt.Sprintf(`DELETE FROM %s WHERE sent_at < $1`, table),
+	}, nil
+}
+
+// Run publishes the unsent messages until ctx is done.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.relay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// relay publishes the unsent messages, batch after batch, then records the
+// lag.
+func (r *Relay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.relayBatch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.log.Error(err.Error())
+			}
+			break
+		}
+		if n < r.config.BatchSize {
+			break
+		}
+	}
+
+	if r.metrics != nil && ctx.Err() == nil {
+		if _, _, err := r.Lag(ctx); err != nil {
+			r.log.Error(err.Error())
+		}
+	}
+}
+
+type row struct {
+	id      int64
+	topic   string
+	key     []byte
+	value   []byte
+	headers []byte
+}
+
+// relayBatch publishes a batch in a transaction holding the row locks. The
+// batch stops at the first failure so the messages stay in order, and the
+// ones published before it are marked sent.
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := r.selectBatch(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var publishErr error
+	sentAt := r.now()
+	for _, row := range rows {
+		if publishErr = r.publish(ctx, row); publishErr != nil {
+			break
+		}
+		if _, err := tx.ExecContext(ctx, r.sentQuery, sentAt, row.id); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rows), publishErr
+}
+
+func (r *Relay) selectBatch(ctx context.Context, tx *sql.Tx) ([]row, error) {
+	result, err := tx.QueryContext(ctx, r.selectQuery, r.config.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var rows []row
+	for result.Next() {
+		var row row
+		if err := result.Scan(&row.id, &row.topic, &row.key, &row.value, &row.headers); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, result.Err()
+}
+
+func (r *Relay) publish(ctx context.Context, row row) error {
+	msg := &messaging.Message{
+		Topic:     row.topic,
+		Key:       row.key,
+		Value:     row.value,
+		Timestamp: r.now(),
+	}
+	if len(row.headers) > 0 {
+		if err := json.Unmarshal(row.headers, &msg.Headers); err != nil {
+			return fmt.Errorf("outbox: message %d: %w", row.id, err)
+		}
+	}
+
+	err := r.publisher.Publish(messaging.ExtractTraceContext(ctx, msg), msg)
+	if r.metrics != nil {
+		r.metrics.observePublish(row.topic, err)
+	}
+	if err != nil {
+		r.log.With(logger.Fields{"topic": row.topic, "id": row.id}).Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// Lag returns the number of unsent messages and the age of the oldest one,
+// zero when there is none, and records them when the relay has metrics.
+func (r *Relay) Lag(ctx context.Context) (int64, time.Duration, error) {
+	var pending int64
+	var oldest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, r.statsQuery).Scan(&pending, &oldest); err != nil {
+		return 0, 0, err
+	}
+	var lag time.Duration
+	if oldest.Valid {
+		lag = max(r.now().Sub(oldest.Time), 0)
+	}
+	if r.metrics != nil {
+		r.metrics.observeLag(pending, lag)
+	}
+	return pending, lag, nil
+}
+
+// Purge deletes the messages sent before olderThan and returns how many
+// there were. Run it periodically, the table otherwise grows forever.
+func (r *Relay) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	res, err := r.db.ExecContext(ctx, r.purgeQuery, r.now().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,165 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	selectQuery = regexp.QuoteMeta("SELECT id, topic, key, value, headers FROM outbox_messages WHERE sent_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED")
+	sentQuery   = regexp.QuoteMeta("UPDATE outbox_messages SET sent_at = $1 WHERE id = $2")
+	statsQuery  = regexp.QuoteMeta("SELECT COUNT(*), MIN(created_at) FROM outbox_messages WHERE sent_at IS NULL")
+)
+
+type fakePublisher struct {
+	published []*messaging.Message
+	failTopic string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, msg *messaging.Message) error {
+	if msg.Topic == p.failTopic {
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func newTestRelay(t *testing.T, publisher messaging.Publisher, config Config) (*Relay, sqlmock.Sqlmock, time.Time) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, mock.ExpectationsWereMet())
+		_ = db.Close()
+	})
+
+	relay, err := NewRelay(db, publisher, config)
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+	return relay, mock, now
+}
+
+func outboxRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "topic", "key", "value", "headers"}).
+		AddRow(1, "orders.created", []byte("order-1"), []byte(`{"id":1}`), []byte(`{"x-message-id":"msg-1"}`)).
+		AddRow(2, "payments.captured", nil, []byte(`{"id":2}`), nil).
+		AddRow(3, "orders.paid", nil, []byte(`{"id":1}`), nil)
+}
+
+func TestRelayBatch(t *testing.T) {
+	publisher := &fakePublisher{}
+	relay, mock, now := newTestRelay(t, publisher, Config{})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(defaultBatchSize).WillReturnRows(outboxRows())
+	for id := 1; id <= 3; id++ {
+		mock.ExpectExec(sentQuery).WithArgs(now, id).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	n, err := relay.relayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	require.Len(t, publisher.published, 3)
+	assert.Equal(t, "orders.created", publisher.published[0].Topic)
+	assert.Equal(t, []byte("order-1"), publisher.published[0].Key)
+	assert.Equal(t, "msg-1", publisher.published[0].Header(messaging.HeaderMessageID))
+	assert.Equal(t, "orders.paid", publisher.published[2].Topic)
+}
+
+func TestRelayBatchPublishFailure(t *testing.T) {
+	metrics := NewMetrics("test")
+	publisher := &fakePublisher{failTopic: "payments.captured"}
+	relay, mock, now := newTestRelay(t, publisher, Config{Metrics: metrics})
+
+	// The batch stops at the failure, keeping the next message behind it.
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WillReturnRows(outboxRows())
+	mock.ExpectExec(sentQuery).WithArgs(now, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := relay.relayBatch(context.Background())
+	assert.EqualError(t, err, "broker unavailable")
+	require.Len(t, publisher.published, 1)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.published.WithLabelValues("orders.created")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.errors.WithLabelValues("payments.captured")))
+}
+
+func TestRelayLag(t *testing.T) {
+	metrics := NewMetrics("test")
+	relay, mock, now := newTestRelay(t, &fakePublisher{}, Config{Metrics: metrics})
+
+	mock.ExpectQuery(statsQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(4, now.Add(-90*time.Second)))
+	mock.ExpectQuery(statsQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "min"}).AddRow(0, sql.NullTime{}))
+
+	pending, lag, err := relay.Lag(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), pending)
+	assert.Equal(t, 90*time.Second, lag)
+	assert.Equal(t, float64(90), testutil.ToFloat64(metrics.lag))
+	assert.Equal(t, float64(4), testutil.ToFloat64(metrics.pending))
+
+	pending, lag, err = relay.Lag(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+	assert.Zero(t, lag)
+}
+
+func TestRelayRun(t *testing.T) {
+	publisher := &fakePublisher{}
+	relay, mock, _ := newTestRelay(t, publisher, Config{BatchSize: 2, PollInterval: time.Hour})
+
+	// A full batch is followed by another one right away.
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "key", "value", "headers"}).
+		AddRow(1, "orders", nil, []byte("1"), nil).
+		AddRow(2, "orders", nil, []byte("2"), nil))
+	mock.ExpectExec(sentQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(sentQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectQuery).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "topic", "key", "value", "headers"}))
+	mock.ExpectCommit()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+	require.Eventually(t, func() bool { return mock.ExpectationsWereMet() == nil }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Len(t, publisher.published, 2)
+}
+
+func TestRelayPurge(t *testing.T) {
+	relay, mock, now := newTestRelay(t, &fakePublisher{}, Config{})
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM outbox_messages WHERE sent_at < $1")).
+		WithArgs(now.Add(-24 * time.Hour)).
+		WillReturnResult(sqlmock.NewResult(0, 12))
+
+	n, err := relay.Purge(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), n)
+}
+
+func TestNewRelay(t *testing.T) {
+	_, err := NewRelay(nil, nil, Config{})
+	assert.ErrorIs(t, err, errPublisherRequired)
+
+	_, err = NewRelay(nil, &fakePublisher{}, Config{Table: "outbox; DROP TABLE users"})
+	assert.ErrorIs(t, err, errInvalidTable)
+}
@@ -0,0 +1,208 @@
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is an in-memory connection whose channels record their calls.
+type fakeConn struct {
+	mu       sync.Mutex
+	channels []*fakeChannel
+	notify   []chan *amqp.Error
+	closed   bool
+
+	// newChannel customizes the channels opened on the connection.
+	newChannel func(ch *fakeChannel)
+}
+
+func (c *fakeConn) channel() (channel, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return nil, amqp.ErrClosed
+	}
+	ch := &fakeChannel{}
+	if c.newChannel != nil {
+		c.newChannel(ch)
+	}
+	c.channels = append(c.channels, ch)
+	return ch, nil
+}
+
+func (c *fakeConn) NotifyClose(receiver chan *amqp.Error) chan *amqp.Error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.notify = append(c.notify, receiver)
+	return receiver
+}
+
+func (c *fakeConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	return nil
+}
+
+// drop simulates the broker closing the connection.
+func (c *fakeConn) drop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for _, receiver := range c.notify {
+		receiver <- &amqp.Error{Code: amqp.ConnectionForced, Reason: "broker restarted"}
+	}
+}
+
+func (c *fakeConn) declarations() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var declared []string
+	for _, ch := range c.channels {
+		declared = append(declared, ch.declared...)
+	}
+	return declared
+}
+
+type fakeChannel struct {
+	declared   []string
+	confirms   chan amqp.Confirmation
+	published  []amqp.Publishing
+	deliveries chan amqp.Delivery
+	prefetch   int
+	closed     bool
+	consumeErr error
+
+	// confirm is the ack of the confirmation sent for every publish.
+	confirm bool
+}
+
+func (ch *fakeChannel) ExchangeDeclare(name, kind string, _, _, _, _ bool, _ amqp.Table) error {
+	ch.declared = append(ch.declared, "exchange "+name+" "+kind)
+	return nil
+}
+
+func (ch *fakeChannel) QueueDeclare(name string, _, _, _, _ bool, _ amqp.Table) (amqp.Queue, error) {
+	ch.declared = append(ch.declared, "queue "+name)
+	return amqp.Queue{Name: name}, nil
+}
+
+func (ch *fakeChannel) QueueBind(name, key, exchange string, _ bool, _ amqp.Table) error {
+	ch.declared = append(ch.declared, "bind "+name+" "+exchange+" "+key)
+	return nil
+}
+
+func (ch *fakeChannel) Qos(prefetchCount, _ int, _ bool) error {
+	ch.prefetch = prefetchCount
+	return nil
+}
+
+func (ch *fakeChannel) Confirm(bool) error { return nil }
+
+func (ch *fakeChannel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	ch.confirms = confirm
+	return confirm
+}
+
+func (ch *fakeChannel) PublishWithContext(_ context.Context, _, _ string, _, _ bool, msg amqp.Publishing) error {
+	ch.published = append(ch.published, msg)
+	ch.confirms <- amqp.Confirmation{DeliveryTag: uint64(len(ch.published)), Ack: ch.confirm}
+	return nil
+}
+
+func (ch *fakeChannel) ConsumeWithContext(context.Context, string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return ch.deliveries, ch.consumeErr
+}
+
+func (ch *fakeChannel) Close() error {
+	ch.closed = true
+	return nil
+}
+
+// fakeDialer hands out the given connections in order.
+func fakeDialer(conns ...*fakeConn) func(string) (connection, error) {
+	var mu sync.Mutex
+	return func(string) (connection, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		conn := conns[0]
+		if len(conns) > 1 {
+			conns = conns[1:]
+		}
+		return conn, nil
+	}
+}
+
+var testTopology = Config{
+	URL:       "amqp://localhost",
+	Exchanges: []Exchange{{Name: "orders", Durable: true}},
+	Queues: []Queue{{
+		Name:     "billing.orders",
+		Durable:  true,
+		Bindings: []Binding{{Exchange: "orders", RoutingKey: "order.*"}},
+	}},
+	ReconnectDelay: time.Millisecond,
+}
+
+func TestDialValidation(t *testing.T) {
+	_, err := Dial(Config{})
+	assert.ErrorIs(t, err, errURLRequired)
+}
+
+func TestDialDeclaresTopology(t *testing.T) {
+	conn := &fakeConn{}
+	c, err := dial(testTopology, fakeDialer(conn))
+	require.NoError(t, err)
+	defer c.Close()
+
+	assert.Equal(t, []string{
+		"exchange orders topic",
+		"queue billing.orders",
+		"bind billing.orders orders order.*",
+	}, conn.declarations())
+	assert.True(t, conn.channels[0].closed)
+}
+
+func TestConnectionReconnects(t *testing.T) {
+	first, second := &fakeConn{}, &fakeConn{}
+	c, err := dial(testTopology, fakeDialer(first, second))
+	require.NoError(t, err)
+	defer c.Close()
+
+	first.drop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	ch, err := c.channel(ctx)
+	require.NoError(t, err)
+
+	second.mu.Lock()
+	defer second.mu.Unlock()
+	assert.Contains(t, second.channels, ch)
+	assert.Len(t, second.channels, 2, "the topology is declared again before use")
+}
+
+func TestConnectionClose(t *testing.T) {
+	conn := &fakeConn{}
+	c, err := dial(testTopology, fakeDialer(conn))
+	require.NoError(t, err)
+
+	require.NoError(t, c.Close())
+	require.NoError(t, c.Close())
+	assert.True(t, conn.closed)
+
+	_, err = c.channel(context.Background())
+	assert.Error(t, err)
+}
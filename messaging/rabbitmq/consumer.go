@@ -0,0 +1,231 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const defaultPrefetch = 10
+
+var errQueueRequired = errors.New("rabbitmq: queue is required")
+
+// RequeuePolicy decides what happens to a message whose handler failed.
+type RequeuePolicy int
+
+const (
+	// RequeueOnce requeues a message the first time it fails and rejects it
+	// when it fails again after being redelivered.
+	RequeueOnce RequeuePolicy = iota
+	// RequeueAlways requeues failed messages until they succeed.
+	RequeueAlways
+	// RequeueNever rejects failed messages right away.
+	RequeueNever
+)
+
+// requeue reports whether the failed delivery d goes back to the queue.
+// Rejected messages are dropped, or dead-lettered if the queue has a
+// dead-letter exchange.
+func (p RequeuePolicy) requeue(d *amqp.Delivery) bool {
+	switch p {
+	case RequeueAlways:
+		return true
+	case RequeueNever:
+		return false
+	default:
+		return !d.Redelivered
+	}
+}
+
+// ConsumerConfig configures a Consumer.
+type ConsumerConfig struct {
+	Queue string
+
+	// Prefetch is the number of unacknowledged messages the broker sends
+	// ahead, 10 by default.
+	Prefetch int
+
+	// Concurrency is the number of messages handled at once, 1 by default.
+	// It is capped by Prefetch.
+	Concurrency int
+
+	Requeue RequeuePolicy
+
+	// Hooks are called with the queue whenever consuming starts on a new
+	// channel and stops on it.
+	Hooks messaging.Hooks
+}
+
+// Consumer consumes a queue.
+type Consumer struct {
+	conn      *Connection
+	config    ConsumerConfig
+	log       logger.Logger
+	lifecycle *messaging.Lifecycle
+}
+
+// NewConsumer creates a Consumer of config.Queue on conn.
+func NewConsumer(conn *Connection, config ConsumerConfig) (*Consumer, error) {
+	if config.Queue == "" {
+		return nil, errQueueRequired
+	}
+	if config.Prefetch <= 0 {
+		config.Prefetch = defaultPrefetch
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	config.Concurrency = min(config.Concurrency, config.Prefetch)
+
+	return &Consumer{
+		conn:      conn,
+		config:    config,
+		log:       conn.log.With(logger.Fields{"queue": config.Queue}),
+		lifecycle: messaging.NewLifecycle(),
+	}, nil
+}
+
+// Run passes the messages of the queue to handler until ctx is done,
+// resuming on a new channel whenever the current one is lost. A channel
+// failing to consume, e.g. because the queue is missing, is retried every
+// ReconnectDelay. A message is
+// acknowledged once handler succeeds, and requeued or rejected according to
+// the RequeuePolicy otherwise. Run waits for the messages being handled
+// before returning, and returns once Drain is called.
+func (c *Consumer) Run(ctx context.Context, handler messaging.Handler) error {
+	if !c.lifecycle.Start() {
+		return nil
+	}
+	defer c.lifecycle.Stop()
+
+	for {
+		err := c.consume(ctx, handler)
+		if ctx.Err() != nil || c.draining() || errors.Is(err, errConnectionClosed) {
+			return nil
+		}
+		if err == nil {
+			continue
+		}
+		c.log.Error(err.Error())
+		// The connection is up, so the failure is likely to repeat.
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-c.lifecycle.Draining():
+			return nil
+		case <-time.After(c.conn.config.ReconnectDelay):
+		}
+	}
+}
+
+// Drain stops taking deliveries, waits for the messages being handled and
+// returns once Run has returned or ctx is done. The deliveries prefetched
+// but not handled yet go back to the queue as the channel closes.
+func (c *Consumer) Drain(ctx context.Context) error {
+	return c.lifecycle.Drain(ctx)
+}
+
+func (c *Consumer) draining() bool {
+	select {
+	case <-c.lifecycle.Draining():
+		return true
+	default:
+		return false
+	}
+}
+
+// consume consumes on a single channel until it is closed or the consumer
+// drains.
+func (c *Consumer) consume(ctx context.Context, handler messaging.Handler) error {
+	ch, err := c.conn.channel(ctx)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(c.config.Prefetch, 0, false); err != nil {
+		return err
+	}
+
+	deliveries, err := ch.ConsumeWithContext(ctx, c.config.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	assignment := messaging.Assignment{c.config.Queue: nil}
+	c.config.Hooks.Assign(ctx, assignment)
+	defer c.config.Hooks.Revoke(context.WithoutCancel(ctx), assignment)
+
+	sem := make(chan struct{}, c.config.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for !c.draining() {
+		var d amqp.Delivery
+		var ok bool
+		select {
+		case <-c.lifecycle.Draining():
+			return nil
+		case d, ok = <-deliveries:
+			if !ok {
+				return nil
+			}
+		}
+
+		select {
+		case <-c.lifecycle.Draining():
+			// Left unacknowledged, the delivery is requeued on close.
+			return nil
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(d amqp.Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c.handle(ctx, handler, &d)
+		}(d)
+	}
+	return nil
+}
+
+func (c *Consumer) handle(ctx context.Context, handler messaging.Handler, d *amqp.Delivery) {
+	msg := fromDelivery(c.config.Queue, d)
+	msgCtx := messaging.ExtractTraceContext(ctx, msg)
+	err := handler(msgCtx, msg)
+	if err == nil {
+		if err := d.Ack(false); err != nil {
+			c.log.Error(err.Error())
+		}
+		return
+	}
+
+	requeue := c.config.Requeue.requeue(d)
+	logger.WithContext(msgCtx, c.log).With(logger.Fields{
+		"deliveryTag": d.DeliveryTag,
+		"requeue":     requeue,
+	}).Error(fmt.Sprintf("rabbitmq: handle message: %s", err))
+
+	if err := d.Reject(requeue); err != nil {
+		c.log.Error(err.Error())
+	}
+}
+
+func fromDelivery(queue string, d *amqp.Delivery) *messaging.Message {
+	msg := &messaging.Message{
+		Topic:     queue,
+		Value:     d.Body,
+		Timestamp: d.Timestamp,
+	}
+	for key, value := range d.Headers {
+		msg.SetHeader(key, fmt.Sprint(value))
+	}
+	return msg
+}
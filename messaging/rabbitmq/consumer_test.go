@@ -0,0 +1,183 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	acked    []uint64
+	rejected map[uint64]bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, _ bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked = append(a.acked, tag)
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(tag uint64, _, requeue bool) error {
+	return a.Reject(tag, requeue)
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.rejected == nil {
+		a.rejected = map[uint64]bool{}
+	}
+	a.rejected[tag] = requeue
+	return nil
+}
+
+func TestNewConsumerValidation(t *testing.T) {
+	c, err := dial(Config{URL: "amqp://localhost"}, fakeDialer(&fakeConn{}))
+	require.NoError(t, err)
+	defer c.Close()
+
+	_, err = NewConsumer(c, ConsumerConfig{})
+	assert.ErrorIs(t, err, errQueueRequired)
+}
+
+func TestConsumerRun(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name         string
+		policy       RequeuePolicy
+		redelivered  bool
+		wantRequeued bool
+	}{
+		{name: "requeue once, first failure", policy: RequeueOnce, wantRequeued: true},
+		{name: "requeue once, redelivered", policy: RequeueOnce, redelivered: true},
+		{name: "requeue always", policy: RequeueAlways, redelivered: true, wantRequeued: true},
+		{name: "requeue never", policy: RequeueNever},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ack := &fakeAcknowledger{}
+			deliveries := make(chan amqp.Delivery, 2)
+			deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: []byte("ok"), Headers: amqp.Table{"tenant": "acme"}}
+			deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: []byte("fail"), Redelivered: tt.redelivered}
+
+			conn := &fakeConn{newChannel: func(ch *fakeChannel) { ch.deliveries = deliveries }}
+			c, err := dial(Config{URL: "amqp://localhost"}, fakeDialer(conn))
+			require.NoError(t, err)
+
+			consumer, err := NewConsumer(c, ConsumerConfig{Queue: "billing.orders", Concurrency: 2, Requeue: tt.policy})
+			require.NoError(t, err)
+
+			var mu sync.Mutex
+			var received []*messaging.Message
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- consumer.Run(ctx, func(_ context.Context, msg *messaging.Message) error {
+					mu.Lock()
+					received = append(received, msg)
+					mu.Unlock()
+					if string(msg.Value) == "fail" {
+						return errHandler
+					}
+					return nil
+				})
+			}()
+
+			assert.Eventually(t, func() bool {
+				ack.mu.Lock()
+				defer ack.mu.Unlock()
+				return len(ack.acked)+len(ack.rejected) == 2
+			}, time.Second, time.Millisecond)
+
+			cancel()
+			close(deliveries)
+			require.NoError(t, <-done)
+			require.NoError(t, c.Close())
+
+			assert.Equal(t, []uint64{1}, ack.acked)
+			assert.Equal(t, map[uint64]bool{2: tt.wantRequeued}, ack.rejected)
+			assert.Equal(t, 10, conn.channels[1].prefetch)
+			for _, msg := range received {
+				assert.Equal(t, "billing.orders", msg.Topic)
+			}
+		})
+	}
+}
+
+func TestConsumerDrain(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	deliveries := make(chan amqp.Delivery, 2)
+	deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 1, Body: []byte("slow")}
+	deliveries <- amqp.Delivery{Acknowledger: ack, DeliveryTag: 2, Body: []byte("next")}
+
+	conn := &fakeConn{newChannel: func(ch *fakeChannel) { ch.deliveries = deliveries }}
+	c, err := dial(Config{URL: "amqp://localhost"}, fakeDialer(conn))
+	require.NoError(t, err)
+	defer c.Close()
+
+	var hooks []string
+	consumer, err := NewConsumer(c, ConsumerConfig{Queue: "billing.orders", Hooks: messaging.Hooks{
+		OnAssign: func(_ context.Context, a messaging.Assignment) { hooks = append(hooks, "assign") },
+		OnRevoke: func(_ context.Context, a messaging.Assignment) { hooks = append(hooks, "revoke") },
+	}})
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(context.Background(), func(context.Context, *messaging.Message) error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+
+	<-started
+	drained := make(chan error)
+	go func() { drained <- consumer.Drain(context.Background()) }()
+	require.Eventually(t, consumer.draining, time.Second, time.Millisecond)
+	close(release)
+
+	require.NoError(t, <-done)
+	require.NoError(t, <-drained)
+	assert.Equal(t, []uint64{1}, ack.acked, "the in-flight message is acknowledged, the next one is left for the broker to requeue")
+	assert.Empty(t, ack.rejected)
+	assert.Equal(t, []string{"assign", "revoke"}, hooks)
+	assert.True(t, conn.channels[1].closed)
+}
+
+func TestConsumerRunRetriesFailedConsumeWithDelay(t *testing.T) {
+	errNotFound := &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no queue 'billing.orders'"}
+	conn := &fakeConn{newChannel: func(ch *fakeChannel) { ch.consumeErr = errNotFound }}
+	c, err := dial(Config{URL: "amqp://localhost", ReconnectDelay: 50 * time.Millisecond}, fakeDialer(conn))
+	require.NoError(t, err)
+	defer c.Close()
+
+	consumer, err := NewConsumer(c, ConsumerConfig{Queue: "billing.orders"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 220*time.Millisecond)
+	defer cancel()
+	require.NoError(t, consumer.Run(ctx, func(context.Context, *messaging.Message) error { return nil }))
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	// The first channel declares the topology.
+	attempts := len(conn.channels) - 1
+	assert.GreaterOrEqual(t, attempts, 2)
+	assert.LessOrEqual(t, attempts, 5, "consuming is retried every ReconnectDelay")
+	for _, ch := range conn.channels[1:] {
+		assert.True(t, ch.closed)
+	}
+}
@@ -0,0 +1,166 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	errPublishNacked      = errors.New("rabbitmq: message nacked by the broker")
+	errConfirmChannelLost = errors.New("rabbitmq: channel closed before the publish was confirmed")
+)
+
+// HeaderDelay is the header read by the delayed message exchange plugin,
+// holding the delay in milliseconds.
+const HeaderDelay = "x-delay"
+
+// Publisher publishes persistent messages to an exchange and waits for the
+// broker to confirm each of them. It implements messaging.Publisher and
+// messaging.DelayedPublisher.
+type Publisher struct {
+	conn     *Connection
+	exchange string
+	log      logger.Logger
+
+	mu       sync.Mutex
+	ch       channel
+	confirms chan amqp.Confirmation
+}
+
+// NewPublisher creates a Publisher to exchange. The empty exchange is the
+// default one, routing messages to the queue named by their routing key.
+func NewPublisher(conn *Connection, exchange string) *Publisher {
+	return &Publisher{
+		conn:     conn,
+		exchange: exchange,
+		log:      conn.log.With(logger.Fields{"exchange": exchange}),
+	}
+}
+
+// Publish publishes msg with msg.Topic as routing key, and returns once the
+// broker has taken responsibility for it. Message keys have no AMQP
+// equivalent and are not sent.
+func (p *Publisher) Publish(ctx context.Context, msg *messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messaging.InjectTraceContext(ctx, msg)
+	err := p.publish(ctx, toPublishing(msg), msg.Topic)
+	if err != nil {
+		p.log.With(logger.Fields{"routingKey": msg.Topic}).Error(err.Error())
+	}
+	return err
+}
+
+// PublishAfter publishes msg with a delay, through the delayed message
+// exchange plugin of RabbitMQ. The exchange of the publisher must be of type "x-delayed-message",
+// with the type used for routing in its "x-delayed-type" argument:
+//
+//	rabbitmq.Exchange{
+//		Name: "reminders",
+//		Kind: "x-delayed-message",
+//		Durable: true,
+//		Args: amqp.Table{"x-delayed-type": "direct"},
+//	}
+func (p *Publisher) PublishAfter(ctx context.Context, msg *messaging.Message, delay time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	messaging.InjectTraceContext(ctx, msg)
+	publishing := toPublishing(msg)
+	if publishing.Headers == nil {
+		publishing.Headers = amqp.Table{}
+	}
+	publishing.Headers[HeaderDelay] = delay.Milliseconds()
+
+	err := p.publish(ctx, publishing, msg.Topic)
+	if err != nil {
+		p.log.With(logger.Fields{"routingKey": msg.Topic}).Error(err.Error())
+	}
+	return err
+}
+
+func (p *Publisher) publish(ctx context.Context, publishing amqp.Publishing, routingKey string) error {
+	if p.ch == nil {
+		if err := p.open(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.ch.PublishWithContext(ctx, p.exchange, routingKey, false, false, publishing); err != nil {
+		p.reset()
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		// The confirmation would be read by the next publish.
+		p.reset()
+		return ctx.Err()
+	case confirm, ok := <-p.confirms:
+		if !ok {
+			p.reset()
+			return errConfirmChannelLost
+		}
+		if !confirm.Ack {
+			return errPublishNacked
+		}
+		return nil
+	}
+}
+
+// open opens a channel in confirm mode.
+func (p *Publisher) open(ctx context.Context) error {
+	ch, err := p.conn.channel(ctx)
+	if err != nil {
+		return err
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return err
+	}
+	p.ch = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	return nil
+}
+
+// reset drops the channel, which is open again by the next publish.
+func (p *Publisher) reset() {
+	_ = p.ch.Close()
+	p.ch = nil
+	p.confirms = nil
+}
+
+// Close closes the channel of the publisher.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ch == nil {
+		return nil
+	}
+	err := p.ch.Close()
+	p.ch = nil
+	return err
+}
+
+func toPublishing(msg *messaging.Message) amqp.Publishing {
+	publishing := amqp.Publishing{
+		DeliveryMode: amqp.Persistent,
+		Body:         msg.Value,
+		Timestamp:    msg.Timestamp,
+	}
+	if len(msg.Headers) > 0 {
+		publishing.Headers = make(amqp.Table, len(msg.Headers))
+		for key, value := range msg.Headers {
+			publishing.Headers[key] = value
+		}
+	}
+	return publishing
+}
@@ -0,0 +1,68 @@
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisherPublish(t *testing.T) {
+	tests := []struct {
+		name    string
+		ack     bool
+		wantErr error
+	}{
+		{name: "confirmed", ack: true},
+		{name: "nacked", ack: false, wantErr: errPublishNacked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &fakeConn{newChannel: func(ch *fakeChannel) { ch.confirm = tt.ack }}
+			c, err := dial(Config{URL: "amqp://localhost"}, fakeDialer(conn))
+			require.NoError(t, err)
+			defer c.Close()
+
+			publisher := NewPublisher(c, "orders")
+			defer publisher.Close()
+
+			msg := &messaging.Message{Topic: "order.created", Value: []byte(`{"id":1}`)}
+			msg.SetHeader("tenant", "acme")
+			err = publisher.Publish(context.Background(), msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			ch := publisher.ch.(*fakeChannel)
+			require.Len(t, ch.published, 1)
+			assert.Equal(t, amqp.Persistent, ch.published[0].DeliveryMode)
+			assert.Equal(t, []byte(`{"id":1}`), ch.published[0].Body)
+			assert.Equal(t, amqp.Table{"tenant": "acme"}, ch.published[0].Headers)
+		})
+	}
+}
+
+func TestPublisherPublishAfter(t *testing.T) {
+	conn := &fakeConn{newChannel: func(ch *fakeChannel) { ch.confirm = true }}
+	c, err := dial(Config{URL: "amqp://localhost"}, fakeDialer(conn))
+	require.NoError(t, err)
+	defer c.Close()
+
+	publisher := NewPublisher(c, "reminders")
+	defer publisher.Close()
+
+	msg := &messaging.Message{Topic: "user.reminded", Value: []byte(`{"id":1}`)}
+	require.NoError(t, publisher.PublishAfter(context.Background(), msg, 90*time.Second))
+
+	ch := publisher.ch.(*fakeChannel)
+	require.Len(t, ch.published, 1)
+	assert.Equal(t, amqp.Table{HeaderDelay: int64(90000)}, ch.published[0].Headers)
+	assert.NotContains(t, msg.Headers, HeaderDelay, "the message is left untouched")
+}
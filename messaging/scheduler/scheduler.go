@@ -0,0 +1,223 @@
+// Package scheduler delays messages for the brokers without native delays:
+// scheduled messages are kept in a Redis sorted set, scored by their due
+// time, and published once due.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultKey          = "scheduler:messages"
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 100
+	defaultClaimTimeout = 30 * time.Second
+)
+
+var errPublisherRequired = errors.New("scheduler: publisher is required")
+
+// claimScript returns the due members and pushes their score back by the
+// claim timeout, so that a scheduler dying before publishing them leaves
+// them to be claimed again.
+var claimScript = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, ARGV[2])
+for _, member in ipairs(due) do
+	redis.call("ZADD", KEYS[1], ARGV[3], member)
+end
+return due
+`)
+
+// Config configures a Scheduler.
+type Config struct {
+	// Key is the Redis key of the sorted set, "scheduler:messages" by
+	// default. Schedulers sharing a key share the scheduled messages.
+	Key string
+	// PollInterval is how often due messages are looked up, one second by
+	// default. It bounds how late messages are published.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of messages claimed at once, 100 by
+	// default.
+	BatchSize int
+	// ClaimTimeout is how long claimed messages have to be published before
+	// another scheduler claims them again, 30 seconds by default.
+	ClaimTimeout time.Duration
+
+	// Logger receives the scheduler logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Scheduler publishes messages once their delay elapsed. It implements
+// messaging.DelayedPublisher.
+//
+// Messages are published at least once: a message whose publish fails, or
+// whose scheduler stops before removing it, is published again after the
+// claim timeout. Any number of schedulers may run on the same key.
+type Scheduler struct {
+	client    redis.UniversalClient
+	publisher messaging.Publisher
+	config    Config
+	log       logger.Logger
+	now       func() time.Time
+}
+
+// scheduled is a scheduled message as stored in the sorted set. The ID keeps
+// identical messages apart.
+type scheduled struct {
+	ID      string            `json:"id"`
+	Topic   string            `json:"topic"`
+	Key     []byte            `json:"key,omitempty"`
+	Value   []byte            `json:"value"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// New creates a Scheduler publishing the due messages with publisher.
+func New(client redis.UniversalClient, publisher messaging.Publisher, config Config) (*Scheduler, error) {
+	if publisher == nil {
+		return nil, errPublisherRequired
+	}
+	if config.Key == "" {
+		config.Key = defaultKey
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaultBatchSize
+	}
+	if config.ClaimTimeout <= 0 {
+		config.ClaimTimeout = defaultClaimTimeout
+	}
+	return &Scheduler{
+		client:    client,
+		publisher: publisher,
+		config:    config,
+		log:       logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"key": config.Key}),
+		now:       time.Now,
+	}, nil
+}
+
+// PublishAfter schedules msg to be published once delay elapsed. The trace
+// context of ctx is stored with the message and continued by the publish.
+func (s *Scheduler) PublishAfter(ctx context.Context, msg *messaging.Message, delay time.Duration) error {
+	return s.PublishAt(ctx, msg, s.now().Add(delay))
+}
+
+// PublishAt schedules msg to be published at the given time.
+func (s *Scheduler) PublishAt(ctx context.Context, msg *messaging.Message, at time.Time) error {
+	messaging.InjectTraceContext(ctx, msg)
+	member, err := json.Marshal(scheduled{
+		ID:      uuid.NewString(),
+		Topic:   msg.Topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.client.ZAdd(ctx, s.config.Key, redis.Z{
+		Score:  float64(at.UnixMilli()),
+		Member: member,
+	}).Err()
+}
+
+// Run publishes the due messages until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.dispatch(ctx)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch publishes the due messages, batch after batch.
+func (s *Scheduler) dispatch(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := s.dispatchBatch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				s.log.Error(err.Error())
+			}
+			return
+		}
+		if n < s.config.BatchSize {
+			return
+		}
+	}
+}
+
+func (s *Scheduler) dispatchBatch(ctx context.Context) (int, error) {
+	now := s.now()
+	members, err := claimScript.Run(ctx, s.client, []string{s.config.Key},
+		now.UnixMilli(),
+		s.config.BatchSize,
+		now.Add(s.config.ClaimTimeout).UnixMilli(),
+	).StringSlice()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range members {
+		s.publish(ctx, member)
+	}
+	return len(members), nil
+}
+
+// publish publishes a claimed member and removes it. Members failing to
+// publish are retried once their claim expires; those failing to decode are
+// dropped.
+func (s *Scheduler) publish(ctx context.Context, member string) {
+	var m scheduled
+	if err := json.Unmarshal([]byte(member), &m); err != nil {
+		s.log.Error(err.Error())
+		s.remove(ctx, member)
+		return
+	}
+
+	msg := &messaging.Message{
+		Topic:     m.Topic,
+		Key:       m.Key,
+		Value:     m.Value,
+		Headers:   m.Headers,
+		Timestamp: s.now(),
+	}
+	if err := s.publisher.Publish(messaging.ExtractTraceContext(ctx, msg), msg); err != nil {
+		s.log.With(logger.Fields{"topic": m.Topic, "id": m.ID}).Error(err.Error())
+		return
+	}
+	s.remove(ctx, member)
+}
+
+func (s *Scheduler) remove(ctx context.Context, member string) {
+	if err := s.client.ZRem(ctx, s.config.Key, member).Err(); err != nil {
+		s.log.Error(err.Error())
+	}
+}
+
+// Pending returns the number of scheduled messages, due or not.
+func (s *Scheduler) Pending(ctx context.Context) (int64, error) {
+	return s.client.ZCard(ctx, s.config.Key).Result()
+}
+
+// Due returns the number of messages due but not published yet, a measure of
+// how far behind the schedulers are.
+func (s *Scheduler) Due(ctx context.Context) (int64, error) {
+	return s.client.ZCount(ctx, s.config.Key, "-inf", strconv.FormatInt(s.now().UnixMilli(), 10)).Result()
+}
@@ -0,0 +1,121 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordPublisher struct {
+	mu        sync.Mutex
+	published []*messaging.Message
+	err       error
+}
+
+func (p *recordPublisher) Publish(_ context.Context, msg *messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func newTestScheduler(t *testing.T, publisher messaging.Publisher) (*Scheduler, *time.Time) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	s, err := New(client, publisher, Config{BatchSize: 2})
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, &now
+}
+
+func TestNewValidation(t *testing.T) {
+	_, err := New(nil, nil, Config{})
+	assert.ErrorIs(t, err, errPublisherRequired)
+}
+
+func TestSchedulerPublishesDueMessages(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordPublisher{}
+	s, now := newTestScheduler(t, publisher)
+
+	for i, delay := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute, time.Hour} {
+		msg := &messaging.Message{Topic: "reminders", Key: []byte("user-1"), Value: []byte{byte('a' + i)}}
+		require.NoError(t, s.PublishAfter(ctx, msg, delay))
+	}
+	// Identical messages are scheduled separately.
+	require.NoError(t, s.PublishAfter(ctx, &messaging.Message{Topic: "reminders", Value: []byte("a")}, time.Minute))
+
+	s.dispatch(ctx)
+	assert.Empty(t, publisher.published, "nothing is due yet")
+
+	*now = now.Add(5 * time.Minute)
+	due, err := s.Due(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), due)
+
+	s.dispatch(ctx)
+	require.Len(t, publisher.published, 4, "due messages are published across batches")
+	values := make([]string, len(publisher.published))
+	for i, msg := range publisher.published {
+		values[i] = string(msg.Value)
+	}
+	assert.ElementsMatch(t, []string{"a", "a", "b", "c"}, values)
+	assert.Equal(t, "reminders", publisher.published[0].Topic)
+
+	pending, err := s.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending)
+}
+
+func TestSchedulerRetriesFailedPublishes(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordPublisher{err: errors.New("broker down")}
+	s, now := newTestScheduler(t, publisher)
+
+	require.NoError(t, s.PublishAt(ctx, &messaging.Message{Topic: "reminders", Value: []byte("a")}, *now))
+
+	s.dispatch(ctx)
+	pending, err := s.Pending(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), pending, "failed messages are kept")
+
+	publisher.err = nil
+	s.dispatch(ctx)
+	assert.Empty(t, publisher.published, "the claim has not expired yet")
+
+	*now = now.Add(s.config.ClaimTimeout)
+	s.dispatch(ctx)
+	assert.Len(t, publisher.published, 1)
+}
+
+func TestSchedulerRunStopsWithContext(t *testing.T) {
+	s, _ := newTestScheduler(t, &recordPublisher{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return")
+	}
+}
@@ -0,0 +1,132 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+// AvroSerializer encodes values of T with an Avro schema, T being a struct
+// with avro tags.
+type AvroSerializer[T any] struct {
+	client *Client
+	config SerializerConfig
+	schema avro.Schema
+	raw    Schema
+	name   string
+}
+
+// NewAvroSerializer creates a serializer of the Avro schema.
+func NewAvroSerializer[T any](client *Client, schema string, config SerializerConfig) (*AvroSerializer[T], error) {
+	parsed, err := avro.Parse(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	name := string(parsed.Type())
+	if named, ok := parsed.(avro.NamedSchema); ok {
+		name = named.FullName()
+	}
+
+	return &AvroSerializer[T]{
+		client: client,
+		config: config,
+		schema: parsed,
+		raw:    Schema{Schema: parsed.String()},
+		name:   name,
+	}, nil
+}
+
+// Serialize encodes v for topic in the Confluent wire format.
+func (s *AvroSerializer[T]) Serialize(ctx context.Context, topic string, v T) ([]byte, error) {
+	id, err := s.config.schemaID(ctx, s.client, topic, s.name, s.raw)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := avro.Marshal(s.schema, v)
+	if err != nil {
+		return nil, err
+	}
+	return encodeWire(id, nil, payload), nil
+}
+
+// AvroDeserializer decodes values of T written with any schema of the
+// registry. With a reader schema, values written with an older or newer
+// compatible schema are resolved to it, following the Avro schema
+// resolution rules.
+type AvroDeserializer[T any] struct {
+	client *Client
+	reader avro.Schema
+	compat *avro.SchemaCompatibility
+
+	mu      sync.Mutex
+	schemas map[int]avro.Schema
+}
+
+// NewAvroDeserializer creates a deserializer. The reader schema may be
+// empty, values are then decoded with the schema they were written with.
+func NewAvroDeserializer[T any](client *Client, readerSchema string) (*AvroDeserializer[T], error) {
+	d := &AvroDeserializer[T]{
+		client:  client,
+		compat:  avro.NewSchemaCompatibility(),
+		schemas: map[int]avro.Schema{},
+	}
+	if readerSchema != "" {
+		reader, err := avro.Parse(readerSchema)
+		if err != nil {
+			return nil, err
+		}
+		d.reader = reader
+	}
+	return d, nil
+}
+
+// Deserialize decodes data in the Confluent wire format.
+func (d *AvroDeserializer[T]) Deserialize(ctx context.Context, data []byte) (T, error) {
+	var v T
+
+	id, payload, err := decodeWire(data)
+	if err != nil {
+		return v, err
+	}
+
+	schema, err := d.schema(ctx, id)
+	if err != nil {
+		return v, err
+	}
+
+	err = avro.Unmarshal(schema, payload, &v)
+	return v, err
+}
+
+// schema returns the schema decoding the payloads written with schema id.
+func (d *AvroDeserializer[T]) schema(ctx context.Context, id int) (avro.Schema, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if schema, ok := d.schemas[id]; ok {
+		return schema, nil
+	}
+
+	registered, err := d.client.SchemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := avro.Parse(registered.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("schemaregistry: parse schema %d: %w", id, err)
+	}
+
+	schema := writer
+	if d.reader != nil {
+		if schema, err = d.compat.Resolve(d.reader, writer); err != nil {
+			return nil, fmt.Errorf("schemaregistry: resolve schema %d: %w", id, err)
+		}
+	}
+
+	d.schemas[id] = schema
+	return schema, nil
+}
@@ -0,0 +1,100 @@
+package schemaregistry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderSchemaV1 = `{
+	"type": "record",
+	"name": "OrderCreated",
+	"namespace": "com.acme",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "total", "type": "string"}
+	]
+}`
+
+const orderSchemaV2 = `{
+	"type": "record",
+	"name": "OrderCreated",
+	"namespace": "com.acme",
+	"fields": [
+		{"name": "id", "type": "long"},
+		{"name": "total", "type": "string"},
+		{"name": "currency", "type": "string", "default": "IDR"}
+	]
+}`
+
+type orderV1 struct {
+	ID    int64  `avro:"id"`
+	Total string `avro:"total"`
+}
+
+type orderV2 struct {
+	ID       int64  `avro:"id"`
+	Total    string `avro:"total"`
+	Currency string `avro:"currency"`
+}
+
+func TestAvroRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, client := newFakeRegistry(t)
+
+	serializer, err := NewAvroSerializer[orderV1](client, orderSchemaV1, SerializerConfig{AutoRegister: true})
+	require.NoError(t, err)
+	data, err := serializer.Serialize(ctx, "orders", orderV1{ID: 1, Total: "10.00"})
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 1}, data[:5])
+
+	deserializer, err := NewAvroDeserializer[orderV1](client, "")
+	require.NoError(t, err)
+	got, err := deserializer.Deserialize(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, orderV1{ID: 1, Total: "10.00"}, got)
+
+	// A consumer on the newer schema reads the older payloads, with the
+	// default of the new field.
+	upgraded, err := NewAvroDeserializer[orderV2](client, orderSchemaV2)
+	require.NoError(t, err)
+	gotV2, err := upgraded.Deserialize(ctx, data)
+	require.NoError(t, err)
+	assert.Equal(t, orderV2{ID: 1, Total: "10.00", Currency: "IDR"}, gotV2)
+}
+
+func TestAvroSerializerRequiresRegisteredSchema(t *testing.T) {
+	_, client := newFakeRegistry(t)
+
+	serializer, err := NewAvroSerializer[orderV1](client, orderSchemaV1, SerializerConfig{SubjectNameStrategy: RecordNameStrategy})
+	require.NoError(t, err)
+	_, err = serializer.Serialize(context.Background(), "orders", orderV1{ID: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestAvroSerializerIncompatibleSchema(t *testing.T) {
+	ctx := context.Background()
+	registry, client := newFakeRegistry(t)
+
+	v1, err := NewAvroSerializer[orderV1](client, orderSchemaV1, SerializerConfig{AutoRegister: true})
+	require.NoError(t, err)
+	_, err = v1.Serialize(ctx, "orders", orderV1{ID: 1})
+	require.NoError(t, err)
+
+	registry.incompatible = true
+	v2, err := NewAvroSerializer[orderV2](client, orderSchemaV2, SerializerConfig{AutoRegister: true})
+	require.NoError(t, err)
+	_, err = v2.Serialize(ctx, "orders", orderV2{ID: 1})
+	assert.ErrorIs(t, err, ErrIncompatible)
+}
+
+func TestDeserializeInvalidPayload(t *testing.T) {
+	_, client := newFakeRegistry(t)
+	deserializer, err := NewAvroDeserializer[orderV1](client, "")
+	require.NoError(t, err)
+
+	_, err = deserializer.Deserialize(context.Background(), []byte(`{"id":1}`))
+	assert.ErrorIs(t, err, errInvalidPayload)
+}
@@ -0,0 +1,256 @@
+// Package schemaregistry serializes Kafka messages against a Confluent
+// Schema Registry compatible server, in Avro or Protobuf, so producers can
+// only publish payloads of a schema the registry accepted.
+package schemaregistry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+// Schema types of the registry. An empty type is Avro.
+const (
+	SchemaTypeAvro     = "AVRO"
+	SchemaTypeProtobuf = "PROTOBUF"
+	SchemaTypeJSON     = "JSON"
+)
+
+const (
+	contentType    = "application/vnd.schemaregistry.v1+json"
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrNotFound is returned for unknown subjects, versions and schemas.
+	ErrNotFound = errors.New("schemaregistry: not found")
+	// ErrIncompatible is returned when registering a schema incompatible
+	// with the previous versions of its subject.
+	ErrIncompatible = errors.New("schemaregistry: incompatible schema")
+
+	errURLRequired = errors.New("schemaregistry: URL is required")
+)
+
+// Schema is a schema as stored by the registry.
+type Schema struct {
+	Schema     string      `json:"schema"`
+	SchemaType string      `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+// Reference is a schema imported by another one, e.g. a Protobuf import.
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// RegisteredSchema is a schema with its registry coordinates.
+type RegisteredSchema struct {
+	Schema
+	ID      int    `json:"id"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Config configures a Client.
+type Config struct {
+	URL string
+
+	// Username and Password enable basic authentication, e.g. with the API
+	// key and secret of Confluent Cloud.
+	Username string
+	Password string
+
+	// HTTPClient sends the requests. Defaults to a client with a 10s
+	// timeout.
+	HTTPClient *http.Client
+}
+
+// Client is a Schema Registry client. Schemas are immutable once
+// registered, so they and their IDs are cached for the life of the client.
+type Client struct {
+	config Config
+	http   *http.Client
+
+	mu   sync.RWMutex
+	ids  map[string]int
+	byID map[int]*Schema
+}
+
+// NewClient creates a Client.
+func NewClient(config Config) (*Client, error) {
+	if config.URL == "" {
+		return nil, errURLRequired
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return &Client{
+		config: config,
+		http:   httpClient,
+		ids:    map[string]int{},
+		byID:   map[int]*Schema{},
+	}, nil
+}
+
+// Register registers schema under subject, unless registered already, and
+// returns its ID. It fails with ErrIncompatible when the registry rejects
+// the schema as incompatible with the previous versions.
+func (c *Client) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	if id, ok := c.cachedID(subject, schema); ok {
+		return id, nil
+	}
+
+	var res struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schema, &res); err != nil {
+		return 0, err
+	}
+
+	c.cache(subject, schema, res.ID)
+	return res.ID, nil
+}
+
+// Lookup returns the ID of schema if it is registered under subject, and
+// ErrNotFound otherwise.
+func (c *Client) Lookup(ctx context.Context, subject string, schema Schema) (int, error) {
+	if id, ok := c.cachedID(subject, schema); ok {
+		return id, nil
+	}
+
+	var res RegisteredSchema
+	if err := c.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), schema, &res); err != nil {
+		return 0, err
+	}
+
+	c.cache(subject, schema, res.ID)
+	return res.ID, nil
+}
+
+// SchemaByID returns the schema with the given ID.
+func (c *Client) SchemaByID(ctx context.Context, id int) (*Schema, error) {
+	c.mu.RLock()
+	schema, ok := c.byID[id]
+	c.mu.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var res Schema
+	if err := c.do(ctx, http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &res); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.byID[id] = &res
+	c.mu.Unlock()
+	return &res, nil
+}
+
+// LatestSchema returns the latest version of subject. It is never cached.
+func (c *Client) LatestSchema(ctx context.Context, subject string) (*RegisteredSchema, error) {
+	var res RegisteredSchema
+	if err := c.do(ctx, http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/latest", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// IsCompatible reports whether schema is compatible with the latest version
+// of subject, according to the compatibility level of the subject.
+func (c *Client) IsCompatible(ctx context.Context, subject string, schema Schema) (bool, error) {
+	var res struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	err := c.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest", schema, &res)
+	return res.IsCompatible, err
+}
+
+func (c *Client) cachedID(subject string, schema Schema) (int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	id, ok := c.ids[cacheKey(subject, schema)]
+	return id, ok
+}
+
+func (c *Client) cache(subject string, schema Schema, id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ids[cacheKey(subject, schema)] = id
+	if _, ok := c.byID[id]; !ok {
+		c.byID[id] = &schema
+	}
+}
+
+func cacheKey(subject string, schema Schema) string {
+	return subject + "\x00" + schema.SchemaType + "\x00" + schema.Schema
+}
+
+// apiError is the error body of the registry.
+type apiError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var apiErr apiError
+		_ = json.NewDecoder(res.Body).Decode(&apiErr)
+
+		err := fmt.Errorf("schemaregistry: %s %s: %d %s", method, path, apiErr.ErrorCode, apiErr.Message)
+		switch res.StatusCode {
+		case http.StatusNotFound:
+			return fmt.Errorf("%w: %w", ErrNotFound, err)
+		case http.StatusConflict:
+			return fmt.Errorf("%w: %w", ErrIncompatible, err)
+		}
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,186 @@
+package schemaregistry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry is an in-memory registry serving the endpoints used by the
+// client.
+type fakeRegistry struct {
+	mu       sync.Mutex
+	schemas  []Schema
+	subjects map[string][]int
+	requests int
+	// incompatible rejects the registration of new versions.
+	incompatible bool
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *Client) {
+	t.Helper()
+
+	registry := &fakeRegistry{subjects: map[string][]int{}}
+	srv := httptest.NewServer(registry)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(Config{URL: srv.URL + "/", Username: "key", Password: "secret"})
+	require.NoError(t, err)
+	return registry, client
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != "key" || pass != "secret" {
+		writeError(w, http.StatusUnauthorized, 40101, "unauthorized")
+		return
+	}
+
+	var body Schema
+	if r.Method == http.MethodPost {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions" && r.Method == http.MethodPost:
+		if id, ok := f.find(parts[1], body); ok {
+			writeJSON(w, map[string]int{"id": id})
+			return
+		}
+		if f.incompatible && len(f.subjects[parts[1]]) > 0 {
+			writeError(w, http.StatusConflict, 409, "Schema being registered is incompatible with an earlier schema")
+			return
+		}
+		f.schemas = append(f.schemas, body)
+		id := len(f.schemas)
+		f.subjects[parts[1]] = append(f.subjects[parts[1]], id)
+		writeJSON(w, map[string]int{"id": id})
+
+	case len(parts) == 2 && parts[0] == "subjects" && r.Method == http.MethodPost:
+		id, ok := f.find(parts[1], body)
+		if !ok {
+			writeError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		writeJSON(w, RegisteredSchema{Schema: body, ID: id, Subject: parts[1], Version: 1})
+
+	case len(parts) == 4 && parts[0] == "subjects" && parts[3] == "latest":
+		versions := f.subjects[parts[1]]
+		if len(versions) == 0 {
+			writeError(w, http.StatusNotFound, 40401, "Subject not found")
+			return
+		}
+		id := versions[len(versions)-1]
+		writeJSON(w, RegisteredSchema{Schema: f.schemas[id-1], ID: id, Subject: parts[1], Version: len(versions)})
+
+	case len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		id, _ := strconv.Atoi(parts[2])
+		if id < 1 || id > len(f.schemas) {
+			writeError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		writeJSON(w, f.schemas[id-1])
+
+	case len(parts) == 5 && parts[0] == "compatibility":
+		writeJSON(w, map[string]bool{"is_compatible": !f.incompatible})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (f *fakeRegistry) find(subject string, schema Schema) (int, bool) {
+	for _, id := range f.subjects[subject] {
+		if f.schemas[id-1].Schema == schema.Schema {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func (f *fakeRegistry) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", contentType)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(apiError{ErrorCode: code, Message: message})
+}
+
+func TestNewClientValidation(t *testing.T) {
+	_, err := NewClient(Config{})
+	assert.ErrorIs(t, err, errURLRequired)
+}
+
+func TestClientRegisterAndLookup(t *testing.T) {
+	ctx := context.Background()
+	registry, client := newFakeRegistry(t)
+	schema := Schema{Schema: `"string"`}
+
+	_, err := client.Lookup(ctx, "orders-value", schema)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	id, err := client.Register(ctx, "orders-value", schema)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	requests := registry.requestCount()
+	id, err = client.Lookup(ctx, "orders-value", schema)
+	require.NoError(t, err)
+	assert.Equal(t, 1, id)
+	got, err := client.SchemaByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Schema, got.Schema)
+	assert.Equal(t, requests, registry.requestCount(), "registered schemas are cached")
+
+	latest, err := client.LatestSchema(ctx, "orders-value")
+	require.NoError(t, err)
+	assert.Equal(t, 1, latest.Version)
+
+	registry.incompatible = true
+	compatible, err := client.IsCompatible(ctx, "orders-value", Schema{Schema: `"int"`})
+	require.NoError(t, err)
+	assert.False(t, compatible)
+	_, err = client.Register(ctx, "orders-value", Schema{Schema: `"int"`})
+	assert.ErrorIs(t, err, ErrIncompatible)
+}
+
+func TestSubjectNameStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy SubjectNameStrategy
+		isKey    bool
+		want     string
+	}{
+		{name: "topic value", strategy: TopicNameStrategy, want: "orders-value"},
+		{name: "topic key", strategy: TopicNameStrategy, isKey: true, want: "orders-key"},
+		{name: "record", strategy: RecordNameStrategy, want: "com.acme.OrderCreated"},
+		{name: "topic record", strategy: TopicRecordNameStrategy, want: "orders-com.acme.OrderCreated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.strategy("orders", tt.isKey, "com.acme.OrderCreated"))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var errInvalidMessageIndexes = errors.New("schemaregistry: invalid Protobuf message indexes")
+
+// ProtobufSerializer encodes messages of type T, a generated Protobuf
+// message.
+type ProtobufSerializer[T proto.Message] struct {
+	client  *Client
+	config  SerializerConfig
+	schema  Schema
+	name    string
+	indexes []byte
+}
+
+// NewProtobufSerializer creates a serializer of T whose .proto file, the
+// schema registered, is schema. Imports of the file are given as references.
+func NewProtobufSerializer[T proto.Message](client *Client, schema string, references []Reference, config SerializerConfig) *ProtobufSerializer[T] {
+	var zero T
+	desc := zero.ProtoReflect().Descriptor()
+
+	return &ProtobufSerializer[T]{
+		client: client,
+		config: config,
+		schema: Schema{
+			Schema:     schema,
+			SchemaType: SchemaTypeProtobuf,
+			References: references,
+		},
+		name:    string(desc.FullName()),
+		indexes: messageIndexes(desc),
+	}
+}
+
+// Serialize encodes msg for topic in the Confluent wire format.
+func (s *ProtobufSerializer[T]) Serialize(ctx context.Context, topic string, msg T) ([]byte, error) {
+	id, err := s.config.schemaID(ctx, s.client, topic, s.name, s.schema)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return encodeWire(id, s.indexes, payload), nil
+}
+
+// ProtobufDeserializer decodes messages of type T. T is known at compile
+// time, so the schema of the payload is not fetched.
+type ProtobufDeserializer[T proto.Message] struct{}
+
+// NewProtobufDeserializer creates a deserializer of T.
+func NewProtobufDeserializer[T proto.Message]() *ProtobufDeserializer[T] {
+	return &ProtobufDeserializer[T]{}
+}
+
+// Deserialize decodes data in the Confluent wire format.
+func (d *ProtobufDeserializer[T]) Deserialize(data []byte) (T, error) {
+	var zero T
+
+	_, rest, err := decodeWire(data)
+	if err != nil {
+		return zero, err
+	}
+	payload, err := skipMessageIndexes(rest)
+	if err != nil {
+		return zero, err
+	}
+
+	msg := zero.ProtoReflect().New().Interface().(T)
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		return zero, err
+	}
+	return msg, nil
+}
+
+// messageIndexes encodes the path of desc in its file, as zigzag varints
+// prefixed by their count. The first message of the file, the most common
+// case, is encoded as a single 0.
+func messageIndexes(desc protoreflect.MessageDescriptor) []byte {
+	var path []int
+	for d := protoreflect.Descriptor(desc); d != nil; d = d.Parent() {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		path = append([]int{d.Index()}, path...)
+	}
+
+	if len(path) == 1 && path[0] == 0 {
+		return []byte{0}
+	}
+
+	out := binary.AppendVarint(nil, int64(len(path)))
+	for _, index := range path {
+		out = binary.AppendVarint(out, int64(index))
+	}
+	return out
+}
+
+// skipMessageIndexes returns data without its leading message indexes.
+func skipMessageIndexes(data []byte) ([]byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 {
+		return nil, errInvalidMessageIndexes
+	}
+	data = data[n:]
+
+	for range count {
+		if _, n = binary.Varint(data); n <= 0 {
+			return nil, errInvalidMessageIndexes
+		}
+		data = data[n:]
+	}
+	return data, nil
+}
@@ -0,0 +1,61 @@
+package schemaregistry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const timestampProto = `syntax = "proto3";
+package google.protobuf;
+message Timestamp {
+  int64 seconds = 1;
+  int32 nanos = 2;
+}`
+
+func TestProtobufRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, client := newFakeRegistry(t)
+
+	serializer := NewProtobufSerializer[*timestamppb.Timestamp](client, timestampProto, nil, SerializerConfig{AutoRegister: true})
+	want := timestamppb.New(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+
+	data, err := serializer.Serialize(ctx, "orders", want)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 1, 0}, data[:6], "first message of the file has index 0")
+
+	registered, err := client.SchemaByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaTypeProtobuf, registered.SchemaType)
+
+	got, err := NewProtobufDeserializer[*timestamppb.Timestamp]().Deserialize(data)
+	require.NoError(t, err)
+	assert.True(t, want.AsTime().Equal(got.AsTime()))
+}
+
+func TestMessageIndexes(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		// Struct, Value and ListValue are the messages of struct.proto.
+		{name: "first message", got: messageIndexes((&structpb.Struct{}).ProtoReflect().Descriptor()), want: []byte{0}},
+		{name: "third message", got: messageIndexes((&structpb.ListValue{}).ProtoReflect().Descriptor()), want: []byte{2, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+
+			rest, err := skipMessageIndexes(append(tt.got, 0xff))
+			require.NoError(t, err)
+			assert.Equal(t, []byte{0xff}, rest)
+		})
+	}
+}
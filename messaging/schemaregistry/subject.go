@@ -0,0 +1,27 @@
+package schemaregistry
+
+// SubjectNameStrategy returns the subject of the schemas of a topic. The
+// record name is the fully qualified name of the Avro record or Protobuf
+// message.
+type SubjectNameStrategy func(topic string, isKey bool, recordName string) string
+
+// TopicNameStrategy uses <topic>-key or <topic>-value: a topic has a single
+// schema. It is the default of the Confluent serializers.
+func TopicNameStrategy(topic string, isKey bool, _ string) string {
+	if isKey {
+		return topic + "-key"
+	}
+	return topic + "-value"
+}
+
+// RecordNameStrategy uses the record name: a record has the same schema
+// across topics, and topics may mix records.
+func RecordNameStrategy(_ string, _ bool, recordName string) string {
+	return recordName
+}
+
+// TopicRecordNameStrategy uses <topic>-<record name>: topics may mix
+// records, each with its own schema per topic.
+func TopicRecordNameStrategy(topic string, _ bool, recordName string) string {
+	return topic + "-" + recordName
+}
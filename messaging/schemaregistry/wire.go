@@ -0,0 +1,64 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+)
+
+// magicByte starts the payloads of the Confluent wire format, followed by the
+// schema ID as a big endian uint32.
+const magicByte = 0
+
+var errInvalidPayload = errors.New("schemaregistry: payload is not in the Confluent wire format")
+
+// SerializerConfig configures the serializers.
+type SerializerConfig struct {
+	// SubjectNameStrategy derives the subject of the schema, TopicNameStrategy
+	// by default.
+	SubjectNameStrategy SubjectNameStrategy
+
+	// AutoRegister registers the schema when it is not yet, if compatible
+	// with the subject. Otherwise the schema must have been registered, e.g.
+	// by CI, and serializing fails with ErrNotFound until then.
+	AutoRegister bool
+
+	// IsKey serializes message keys instead of values.
+	IsKey bool
+}
+
+func (c *SerializerConfig) subject(topic, recordName string) string {
+	strategy := c.SubjectNameStrategy
+	if strategy == nil {
+		strategy = TopicNameStrategy
+	}
+	return strategy(topic, c.IsKey, recordName)
+}
+
+// schemaID returns the ID of schema for the subject of topic, registering it
+// if so configured.
+func (c *SerializerConfig) schemaID(ctx context.Context, client *Client, topic, recordName string, schema Schema) (int, error) {
+	subject := c.subject(topic, recordName)
+	if c.AutoRegister {
+		return client.Register(ctx, subject, schema)
+	}
+	return client.Lookup(ctx, subject, schema)
+}
+
+// encodeWire prepends the wire format header to payload.
+func encodeWire(id int, indexes []byte, payload []byte) []byte {
+	out := make([]byte, 5, 5+len(indexes)+len(payload))
+	out[0] = magicByte
+	binary.BigEndian.PutUint32(out[1:5], uint32(id))
+	out = append(out, indexes...)
+	return append(out, payload...)
+}
+
+// decodeWire splits a payload in the wire format into its schema ID and the
+// rest.
+func decodeWire(data []byte) (int, []byte, error) {
+	if len(data) < 5 || data[0] != magicByte {
+		return 0, nil, errInvalidPayload
+	}
+	return int(binary.BigEndian.Uint32(data[1:5])), data[5:], nil
+}
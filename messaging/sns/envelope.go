@@ -0,0 +1,60 @@
+package sns
+
+import (
+	"context"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+)
+
+// HeaderTopicARN is set by Unwrap to the topic the notification was
+// published to.
+const HeaderTopicARN = "sns-topic-arn"
+
+// notification is the JSON envelope of the SNS messages delivered to SQS
+// queues whose subscription does not enable raw message delivery.
+type notification struct {
+	Type              string    `json:"Type"`
+	MessageID         string    `json:"MessageId"`
+	TopicArn          string    `json:"TopicArn"`
+	Message           string    `json:"Message"`
+	Timestamp         time.Time `json:"Timestamp"`
+	MessageAttributes map[string]struct {
+		Type  string `json:"Type"`
+		Value string `json:"Value"`
+	} `json:"MessageAttributes"`
+}
+
+// Unwrap wraps a handler of SQS messages coming from an SNS subscription
+// without raw message delivery. It replaces the value of the message by the
+// published one and adds its attributes to the headers, so handler sees the
+// message as it was published, like with raw delivery. Messages that are not
+// SNS notifications are passed as they are.
+//
+//	consumer.Run(ctx, sns.Unwrap(handleOrder))
+func Unwrap(handler messaging.Handler) messaging.Handler {
+	return func(ctx context.Context, msg *messaging.Message) error {
+		var n notification
+		if err := json.Unmarshal(msg.Value, &n); err != nil || n.Type != "Notification" || n.TopicArn == "" {
+			return handler(ctx, msg)
+		}
+
+		unwrapped := &messaging.Message{
+			Topic:     msg.Topic,
+			Key:       msg.Key,
+			Value:     []byte(n.Message),
+			Partition: msg.Partition,
+			Offset:    msg.Offset,
+			Timestamp: n.Timestamp,
+		}
+		for key, value := range msg.Headers {
+			unwrapped.SetHeader(key, value)
+		}
+		for key, attribute := range n.MessageAttributes {
+			unwrapped.SetHeader(key, attribute.Value)
+		}
+		unwrapped.SetHeader(HeaderTopicARN, n.TopicArn)
+		return handler(messaging.ExtractTraceContext(ctx, unwrapped), unwrapped)
+	}
+}
@@ -0,0 +1,71 @@
+package sns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnwrap(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantValue   string
+		wantHeaders map[string]string
+		wantTime    time.Time
+	}{
+		{
+			name: "notification",
+			value: `{
+				"Type": "Notification",
+				"MessageId": "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+				"TopicArn": "arn:aws:sns:ap-southeast-1:123456789012:orders",
+				"Message": "{\"id\":1}",
+				"Timestamp": "2024-05-01T10:00:00.000Z",
+				"MessageAttributes": {"tenant": {"Type": "String", "Value": "acme"}}
+			}`,
+			wantValue: `{"id":1}`,
+			wantHeaders: map[string]string{
+				"receive-count": "1",
+				"tenant":        "acme",
+				HeaderTopicARN:  "arn:aws:sns:ap-southeast-1:123456789012:orders",
+			},
+			wantTime: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "raw message",
+			value:       `{"id":1}`,
+			wantValue:   `{"id":1}`,
+			wantHeaders: map[string]string{"receive-count": "1"},
+		},
+		{
+			name:        "not json",
+			value:       "hello",
+			wantValue:   "hello",
+			wantHeaders: map[string]string{"receive-count": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *messaging.Message
+			handler := Unwrap(func(_ context.Context, msg *messaging.Message) error {
+				got = msg
+				return nil
+			})
+
+			msg := &messaging.Message{Topic: "billing-orders", Value: []byte(tt.value)}
+			msg.SetHeader("receive-count", "1")
+			require.NoError(t, handler(context.Background(), msg))
+
+			assert.Equal(t, "billing-orders", got.Topic)
+			assert.Equal(t, tt.wantValue, string(got.Value))
+			assert.Equal(t, tt.wantHeaders, got.Headers)
+			assert.True(t, tt.wantTime.Equal(got.Timestamp))
+		})
+	}
+}
@@ -0,0 +1,162 @@
+// Package sns publishes to Amazon SNS topics through aws-sdk-go-v2, and
+// unwraps the SNS notifications delivered to SQS queues.
+package sns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// maxAttributes is the maximum number of message attributes of an SNS
+// message.
+const maxAttributes = 10
+
+var (
+	errTopicRequired  = errors.New("sns: message topic is required")
+	errTopicNotFound  = errors.New("sns: topic not found")
+	errGroupRequired  = errors.New("sns: messages to a FIFO topic need a key, used as message group ID")
+	errTooManyHeaders = fmt.Errorf("sns: messages have at most %d attributes", maxAttributes)
+)
+
+// API is the part of *sns.Client used by the package.
+type API interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+	ListTopics(ctx context.Context, params *sns.ListTopicsInput, optFns ...func(*sns.Options)) (*sns.ListTopicsOutput, error)
+}
+
+// PublisherConfig configures a Publisher.
+type PublisherConfig struct {
+	// Logger receives the publisher logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Publisher publishes messages to SNS topics. It implements
+// messaging.Publisher.
+//
+// The topic of a message is either a topic ARN or a topic name, resolved
+// once to its ARN. Headers are sent as string message attributes. On FIFO
+// topics the key of a message is its message group ID and
+// messaging.HeaderDeduplicationID its deduplication ID, required unless the
+// topic has content-based deduplication enabled.
+//
+// SNS accepts only text payloads: compressed ones must use
+// messaging.EncodingGzipBase64 or messaging.EncodingZstdBase64.
+type Publisher struct {
+	client API
+	log    logger.Logger
+
+	mu   sync.Mutex
+	arns map[string]string
+}
+
+// NewPublisher creates a Publisher.
+func NewPublisher(client API, config PublisherConfig) *Publisher {
+	return &Publisher{
+		client: client,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel),
+		arns:   map[string]string{},
+	}
+}
+
+// Publish publishes msg to msg.Topic.
+func (p *Publisher) Publish(ctx context.Context, msg *messaging.Message) error {
+	err := p.publish(ctx, msg)
+	if err != nil {
+		p.log.With(logger.Fields{"topic": msg.Topic}).Error(err.Error())
+	}
+	return err
+}
+
+func (p *Publisher) publish(ctx context.Context, msg *messaging.Message) error {
+	topicARN, err := p.TopicARN(ctx, msg.Topic)
+	if err != nil {
+		return err
+	}
+
+	messaging.InjectTraceContext(ctx, msg)
+	input := &sns.PublishInput{
+		TopicArn: aws.String(topicARN),
+		Message:  aws.String(string(msg.Value)),
+	}
+	if input.MessageAttributes, err = messageAttributes(msg.Headers); err != nil {
+		return err
+	}
+	if strings.HasSuffix(topicARN, ".fifo") {
+		if len(msg.Key) == 0 {
+			return errGroupRequired
+		}
+		input.MessageGroupId = aws.String(string(msg.Key))
+		if id := msg.Header(messaging.HeaderDeduplicationID); id != "" {
+			input.MessageDeduplicationId = aws.String(id)
+		}
+	}
+
+	_, err = p.client.Publish(ctx, input)
+	return err
+}
+
+// TopicARN returns the ARN of topic, which is either an ARN already or the
+// name of a topic of the account. Resolved names are cached.
+func (p *Publisher) TopicARN(ctx context.Context, topic string) (string, error) {
+	if topic == "" {
+		return "", errTopicRequired
+	}
+	if strings.HasPrefix(topic, "arn:") {
+		return topic, nil
+	}
+
+	p.mu.Lock()
+	arn, ok := p.arns[topic]
+	p.mu.Unlock()
+	if ok {
+		return arn, nil
+	}
+
+	paginator := sns.NewListTopicsPaginator(p.client, &sns.ListTopicsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range page.Topics {
+			arn := aws.ToString(t.TopicArn)
+			if arn[strings.LastIndex(arn, ":")+1:] != topic {
+				continue
+			}
+			p.mu.Lock()
+			p.arns[topic] = arn
+			p.mu.Unlock()
+			return arn, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", errTopicNotFound, topic)
+}
+
+func messageAttributes(headers map[string]string) (map[string]types.MessageAttributeValue, error) {
+	var attributes map[string]types.MessageAttributeValue
+	for key, value := range headers {
+		if key == messaging.HeaderDeduplicationID {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string]types.MessageAttributeValue, len(headers))
+		}
+		attributes[key] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	if len(attributes) > maxAttributes {
+		return nil, errTooManyHeaders
+	}
+	return attributes, nil
+}
@@ -0,0 +1,140 @@
+package sns
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTopicARN     = "arn:aws:sns:ap-southeast-1:123456789012:orders"
+	testFIFOTopicARN = "arn:aws:sns:ap-southeast-1:123456789012:orders.fifo"
+)
+
+type fakeAPI struct {
+	published  []*sns.PublishInput
+	listCalls  int
+	topicPages [][]string
+}
+
+func (f *fakeAPI) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.published = append(f.published, params)
+	return &sns.PublishOutput{MessageId: aws.String("id")}, nil
+}
+
+func (f *fakeAPI) ListTopics(_ context.Context, params *sns.ListTopicsInput, _ ...func(*sns.Options)) (*sns.ListTopicsOutput, error) {
+	f.listCalls++
+	page := 0
+	if params.NextToken != nil {
+		fmt.Sscan(*params.NextToken, &page)
+	}
+
+	out := &sns.ListTopicsOutput{}
+	for _, arn := range f.topicPages[page] {
+		out.Topics = append(out.Topics, types.Topic{TopicArn: aws.String(arn)})
+	}
+	if page+1 < len(f.topicPages) {
+		out.NextToken = aws.String(fmt.Sprint(page + 1))
+	}
+	return out, nil
+}
+
+func TestPublisherPublish(t *testing.T) {
+	tests := []struct {
+		name      string
+		msg       *messaging.Message
+		wantTopic string
+		wantGroup *string
+		wantDedup *string
+		wantErr   error
+	}{
+		{
+			name:      "by ARN",
+			msg:       &messaging.Message{Topic: testTopicARN, Value: []byte(`{"id":1}`), Headers: map[string]string{"tenant": "acme"}},
+			wantTopic: testTopicARN,
+		},
+		{
+			name:      "by name",
+			msg:       &messaging.Message{Topic: "orders", Value: []byte(`{"id":1}`), Headers: map[string]string{"tenant": "acme"}},
+			wantTopic: testTopicARN,
+		},
+		{
+			name: "fifo topic",
+			msg: &messaging.Message{
+				Topic:   "orders.fifo",
+				Key:     []byte("order-1"),
+				Value:   []byte(`{"id":1}`),
+				Headers: map[string]string{"tenant": "acme", messaging.HeaderDeduplicationID: "event-1"},
+			},
+			wantTopic: testFIFOTopicARN,
+			wantGroup: aws.String("order-1"),
+			wantDedup: aws.String("event-1"),
+		},
+		{
+			name:    "fifo topic without key",
+			msg:     &messaging.Message{Topic: testFIFOTopicARN},
+			wantErr: errGroupRequired,
+		},
+		{
+			name:    "unknown topic",
+			msg:     &messaging.Message{Topic: "payments"},
+			wantErr: errTopicNotFound,
+		},
+		{
+			name:    "too many headers",
+			msg:     &messaging.Message{Topic: testTopicARN, Headers: manyHeaders(11)},
+			wantErr: errTooManyHeaders,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{topicPages: [][]string{{"arn:aws:sns:ap-southeast-1:123456789012:users"}, {testTopicARN, testFIFOTopicARN}}}
+			publisher := NewPublisher(api, PublisherConfig{})
+
+			err := publisher.Publish(context.Background(), tt.msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, api.published, 1)
+			published := api.published[0]
+			assert.Equal(t, tt.wantTopic, aws.ToString(published.TopicArn))
+			assert.Equal(t, `{"id":1}`, aws.ToString(published.Message))
+			assert.Equal(t, tt.wantGroup, published.MessageGroupId)
+			assert.Equal(t, tt.wantDedup, published.MessageDeduplicationId)
+			assert.Equal(t, map[string]types.MessageAttributeValue{
+				"tenant": {DataType: aws.String("String"), StringValue: aws.String("acme")},
+			}, published.MessageAttributes)
+		})
+	}
+}
+
+func TestPublisherTopicARNCached(t *testing.T) {
+	api := &fakeAPI{topicPages: [][]string{{testTopicARN}}}
+	publisher := NewPublisher(api, PublisherConfig{})
+
+	for range 3 {
+		arn, err := publisher.TopicARN(context.Background(), "orders")
+		require.NoError(t, err)
+		assert.Equal(t, testTopicARN, arn)
+	}
+	assert.Equal(t, 1, api.listCalls)
+}
+
+func manyHeaders(n int) map[string]string {
+	headers := make(map[string]string, n)
+	for i := range n {
+		headers[fmt.Sprint("header-", i)] = "value"
+	}
+	return headers
+}
@@ -0,0 +1,299 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+const (
+	defaultWaitTime          = 20 * time.Second
+	defaultVisibilityTimeout = 30 * time.Second
+	receiveErrorDelay        = time.Second
+)
+
+// ConsumerConfig configures a Consumer.
+type ConsumerConfig struct {
+	QueueURL string
+
+	// MaxMessages is the number of messages received at once, at most and
+	// by default 10.
+	MaxMessages int
+
+	// WaitTime is the long polling duration of a receive, at most and by
+	// default 20s.
+	WaitTime time.Duration
+
+	// VisibilityTimeout is the time the messages received are hidden from
+	// other consumers, 30s by default. It is extended for as long as the
+	// handlers run, so it only needs to cover a crashed consumer.
+	VisibilityTimeout time.Duration
+
+	// Concurrency is the number of messages handled at once, 1 by default.
+	// On FIFO queues the messages of a group are always handled in order.
+	Concurrency int
+
+	// Logger receives the consumer logs. Defaults to a no-op logger.
+	Logger logger.Logger
+
+	// Hooks are called with the queue name when Run starts and stops.
+	Hooks messaging.Hooks
+}
+
+// Consumer receives the messages of a queue.
+type Consumer struct {
+	client    API
+	config    ConsumerConfig
+	log       logger.Logger
+	lifecycle *messaging.Lifecycle
+}
+
+// NewConsumer creates a Consumer of config.QueueURL.
+func NewConsumer(client API, config ConsumerConfig) (*Consumer, error) {
+	if config.QueueURL == "" {
+		return nil, errQueueURLRequired
+	}
+	if config.MaxMessages <= 0 || config.MaxMessages > maxBatchSize {
+		config.MaxMessages = maxBatchSize
+	}
+	if config.WaitTime <= 0 || config.WaitTime > defaultWaitTime {
+		config.WaitTime = defaultWaitTime
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = defaultVisibilityTimeout
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+
+	return &Consumer{
+		client:    client,
+		config:    config,
+		log:       logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"queue": queueName(config.QueueURL)}),
+		lifecycle: messaging.NewLifecycle(),
+	}, nil
+}
+
+// Run passes the messages of the queue to handler until ctx is done. The
+// messages handled successfully are deleted, the others become visible again
+// once their visibility timeout expires. Run waits for the messages being
+// handled before returning, and returns once Drain is called.
+func (c *Consumer) Run(ctx context.Context, handler messaging.Handler) error {
+	if !c.lifecycle.Start() {
+		return nil
+	}
+	defer c.lifecycle.Stop()
+
+	// Draining interrupts the long poll, but not the batch being handled.
+	receiveCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-c.lifecycle.Draining():
+			cancel()
+		case <-receiveCtx.Done():
+		}
+	}()
+
+	assignment := messaging.Assignment{queueName(c.config.QueueURL): nil}
+	c.config.Hooks.Assign(ctx, assignment)
+	defer c.config.Hooks.Revoke(context.WithoutCancel(ctx), assignment)
+
+	for receiveCtx.Err() == nil {
+		out, err := c.client.ReceiveMessage(receiveCtx, &sqs.ReceiveMessageInput{
+			QueueUrl:                    aws.String(c.config.QueueURL),
+			MaxNumberOfMessages:         int32(c.config.MaxMessages),
+			WaitTimeSeconds:             int32(c.config.WaitTime / time.Second),
+			VisibilityTimeout:           int32(c.config.VisibilityTimeout / time.Second),
+			MessageAttributeNames:       []string{"All"},
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+		})
+		if receiveCtx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			c.log.Error(err.Error())
+			select {
+			case <-receiveCtx.Done():
+			case <-time.After(receiveErrorDelay):
+			}
+			continue
+		}
+
+		if len(out.Messages) > 0 {
+			c.handleBatch(ctx, handler, out.Messages)
+		}
+	}
+	return nil
+}
+
+// Drain stops receiving, waits for the batch being handled to be deleted and
+// returns once Run has returned or ctx is done.
+func (c *Consumer) Drain(ctx context.Context) error {
+	return c.lifecycle.Drain(ctx)
+}
+
+// handleBatch handles a received batch, extending the visibility of the
+// messages not done yet until the whole batch is, then deletes the messages
+// handled successfully.
+func (c *Consumer) handleBatch(ctx context.Context, handler messaging.Handler, messages []types.Message) {
+	pending := newPendingSet(messages)
+
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		c.heartbeat(ctx, pending, stopHeartbeat)
+	}()
+
+	// The messages of a FIFO group are handled one after the other, in
+	// order. Messages of standard queues have no group and are handled on
+	// their own.
+	groups := map[string][]int{}
+	var order []string
+	for i := range messages {
+		group := messages[i].Attributes[string(types.MessageSystemAttributeNameMessageGroupId)]
+		if group == "" {
+			group = aws.ToString(messages[i].MessageId)
+		}
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], i)
+	}
+
+	succeeded := make([]bool, len(messages))
+	sem := make(chan struct{}, c.config.Concurrency)
+	var wg sync.WaitGroup
+	for _, group := range order {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(indexes []int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			for _, i := range indexes {
+				msg := fromSQS(c.config.QueueURL, &messages[i])
+				msgCtx := messaging.ExtractTraceContext(ctx, msg)
+				err := handler(msgCtx, msg)
+				pending.done(i)
+				if err != nil {
+					logger.WithContext(msgCtx, c.log).With(logger.Fields{"messageID": aws.ToString(messages[i].MessageId)}).Error(fmt.Sprintf("sqs: handle message: %s", err))
+					// The following messages of the group must not
+					// overtake it.
+					return
+				}
+				succeeded[i] = true
+			}
+		}(groups[group])
+	}
+	wg.Wait()
+
+	close(stopHeartbeat)
+	<-heartbeatDone
+
+	c.delete(context.WithoutCancel(ctx), messages, succeeded)
+}
+
+// heartbeat extends the visibility of the pending messages every half
+// visibility timeout until stop is closed.
+func (c *Consumer) heartbeat(ctx context.Context, pending *pendingSet, stop <-chan struct{}) {
+	ticker := time.NewTicker(c.config.VisibilityTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		entries := pending.visibilityEntries(int32(c.config.VisibilityTimeout / time.Second))
+		if len(entries) == 0 {
+			continue
+		}
+		out, err := c.client.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+			QueueUrl: aws.String(c.config.QueueURL),
+			Entries:  entries,
+		})
+		if err == nil {
+			err = messaging.NewBatchError(batchErrors(out.Failed))
+		}
+		if err != nil {
+			c.log.Error(err.Error())
+		}
+	}
+}
+
+func (c *Consumer) delete(ctx context.Context, messages []types.Message, succeeded []bool) {
+	var entries []types.DeleteMessageBatchRequestEntry
+	for i, ok := range succeeded {
+		if ok {
+			entries = append(entries, types.DeleteMessageBatchRequestEntry{
+				Id:            aws.String(strconv.Itoa(i)),
+				ReceiptHandle: messages[i].ReceiptHandle,
+			})
+		}
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	out, err := c.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.config.QueueURL),
+		Entries:  entries,
+	})
+	if err == nil {
+		err = messaging.NewBatchError(batchErrors(out.Failed))
+	}
+	if err != nil {
+		c.log.Error(err.Error())
+	}
+}
+
+// pendingSet tracks the messages of a batch not handled yet.
+type pendingSet struct {
+	mu       sync.Mutex
+	messages []types.Message
+	pending  []bool
+}
+
+func newPendingSet(messages []types.Message) *pendingSet {
+	pending := make([]bool, len(messages))
+	for i := range pending {
+		pending[i] = true
+	}
+	return &pendingSet{messages: messages, pending: pending}
+}
+
+func (s *pendingSet) done(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[i] = false
+}
+
+func (s *pendingSet) visibilityEntries(timeout int32) []types.ChangeMessageVisibilityBatchRequestEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []types.ChangeMessageVisibilityBatchRequestEntry
+	for i, pending := range s.pending {
+		if pending {
+			entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				ReceiptHandle:     s.messages[i].ReceiptHandle,
+				VisibilityTimeout: timeout,
+			})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,134 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage(id, group, body string) types.Message {
+	msg := types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("receipt-" + id),
+		Body:          aws.String(body),
+		Attributes:    map[string]string{"SentTimestamp": "1714557600000"},
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"tenant": {DataType: aws.String("String"), StringValue: aws.String("acme")},
+		},
+	}
+	if group != "" {
+		msg.Attributes["MessageGroupId"] = group
+	}
+	return msg
+}
+
+func TestNewConsumerDefaults(t *testing.T) {
+	_, err := NewConsumer(&fakeAPI{}, ConsumerConfig{})
+	assert.ErrorIs(t, err, errQueueURLRequired)
+
+	consumer, err := NewConsumer(&fakeAPI{}, ConsumerConfig{QueueURL: testQueueURL, MaxMessages: 50, WaitTime: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, 10, consumer.config.MaxMessages)
+	assert.Equal(t, 20*time.Second, consumer.config.WaitTime)
+	assert.Equal(t, 30*time.Second, consumer.config.VisibilityTimeout)
+	assert.Equal(t, 1, consumer.config.Concurrency)
+}
+
+func TestConsumerRun(t *testing.T) {
+	api := &fakeAPI{receives: [][]types.Message{{
+		testMessage("1", "order-1", "ok"),
+		testMessage("2", "order-1", "fail"),
+		testMessage("3", "order-1", "ok"),
+		testMessage("4", "order-2", "ok"),
+	}}}
+	consumer, err := NewConsumer(api, ConsumerConfig{QueueURL: testFIFOQueueURL, Concurrency: 2})
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var handled []string
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx, func(_ context.Context, msg *messaging.Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, "orders.fifo", msg.Topic)
+			assert.Equal(t, "acme", msg.Header("tenant"))
+			assert.Equal(t, time.UnixMilli(1714557600000), msg.Timestamp)
+
+			handled = append(handled, string(msg.Key)+":"+string(msg.Value))
+			if string(msg.Value) == "fail" {
+				return errors.New("handler failed")
+			}
+			return nil
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return len(api.deleted) > 0
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.ElementsMatch(t, []string{"order-1:ok", "order-1:fail", "order-2:ok"}, handled,
+		"a failed message stops its group")
+	assert.ElementsMatch(t, []string{"receipt-1", "receipt-4"}, api.deleted)
+}
+
+func TestConsumerExtendsVisibility(t *testing.T) {
+	api := &fakeAPI{receives: [][]types.Message{{testMessage("1", "", "slow")}}}
+	consumer, err := NewConsumer(api, ConsumerConfig{QueueURL: testQueueURL, VisibilityTimeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx, func(context.Context, *messaging.Message) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+	}()
+
+	assert.Eventually(t, func() bool {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		return len(api.deleted) == 1
+	}, time.Second, time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.NotEmpty(t, api.visibility)
+	assert.Equal(t, "receipt-1", api.visibility[0])
+}
+
+func TestConsumerDrain(t *testing.T) {
+	api := &fakeAPI{}
+	assigned := make(chan messaging.Assignment, 1)
+	consumer, err := NewConsumer(api, ConsumerConfig{QueueURL: testQueueURL, Hooks: messaging.Hooks{
+		OnAssign: func(_ context.Context, a messaging.Assignment) { assigned <- a },
+	}})
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(context.Background(), func(context.Context, *messaging.Message) error { return nil })
+	}()
+	assert.Equal(t, messaging.Assignment{"orders": nil}, <-assigned)
+
+	// Draining interrupts the long poll of the empty queue.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, consumer.Drain(ctx))
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,177 @@
+package sqs
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+// MaxDelay is the longest delay of PublishAfter, set by SQS.
+const MaxDelay = 15 * time.Minute
+
+var (
+	errGroupRequired = errors.New("sqs: messages to a FIFO queue need a key, used as message group ID")
+	errDelayTooLong  = errors.New("sqs: delay exceeds 15 minutes")
+	errFIFODelay     = errors.New("sqs: FIFO queues do not support per-message delays")
+)
+
+// Producer sends messages to a queue. It implements messaging.Publisher and
+// messaging.DelayedPublisher.
+//
+// Headers are sent as string message attributes, at most 10 of them besides
+// messaging.HeaderDeduplicationID. On FIFO queues the key of a message is
+// its message group ID and messaging.HeaderDeduplicationID its deduplication
+// ID, required unless the queue has content-based deduplication enabled.
+//
+// SQS accepts only text payloads: compressed ones must use
+// messaging.EncodingGzipBase64 or messaging.EncodingZstdBase64.
+type Producer struct {
+	client   API
+	queueURL string
+	fifo     bool
+	log      logger.Logger
+}
+
+// ProducerConfig configures a Producer.
+type ProducerConfig struct {
+	QueueURL string
+
+	// Logger receives the producer logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// NewProducer creates a Producer sending to config.QueueURL.
+func NewProducer(client API, config ProducerConfig) (*Producer, error) {
+	if config.QueueURL == "" {
+		return nil, errQueueURLRequired
+	}
+	return &Producer{
+		client:   client,
+		queueURL: config.QueueURL,
+		fifo:     isFIFO(config.QueueURL),
+		log:      logger.WithLevel(config.Logger, logger.InfoLevel).With(logger.Fields{"queue": queueName(config.QueueURL)}),
+	}, nil
+}
+
+// Publish sends msg. msg.Topic is ignored, the message always goes to the
+// queue of the Producer.
+func (p *Producer) Publish(ctx context.Context, msg *messaging.Message) error {
+	return p.publish(ctx, msg, 0)
+}
+
+// PublishAfter sends msg with a delivery delay, rounded up to the second. SQS
+// delays messages up to MaxDelay and only on standard queues; use the
+// scheduler package for longer delays.
+func (p *Producer) PublishAfter(ctx context.Context, msg *messaging.Message, delay time.Duration) error {
+	if delay > MaxDelay {
+		return errDelayTooLong
+	}
+	if p.fifo && delay > 0 {
+		return errFIFODelay
+	}
+	return p.publish(ctx, msg, delay)
+}
+
+func (p *Producer) publish(ctx context.Context, msg *messaging.Message, delay time.Duration) error {
+	if p.fifo && len(msg.Key) == 0 {
+		return errGroupRequired
+	}
+	messaging.InjectTraceContext(ctx, msg)
+	attributes, err := messageAttributes(msg.Headers)
+	if err != nil {
+		return err
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(p.queueURL),
+		MessageBody:       aws.String(string(msg.Value)),
+		MessageAttributes: attributes,
+		DelaySeconds:      int32((delay + time.Second - 1) / time.Second),
+	}
+	if p.fifo {
+		input.MessageGroupId = aws.String(string(msg.Key))
+		input.MessageDeduplicationId = deduplicationID(msg)
+	}
+
+	if _, err := p.client.SendMessage(ctx, input); err != nil {
+		p.log.Error(err.Error())
+		return err
+	}
+	return nil
+}
+
+// PublishBatch sends msgs in batches of 10, the SQS maximum. It implements
+// messaging.BatchPublisher: the failed messages are reported with a
+// *messaging.BatchError.
+func (p *Producer) PublishBatch(ctx context.Context, msgs []*messaging.Message) error {
+	var errs []messaging.MessageError
+	for start := 0; start < len(msgs); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(msgs))
+		errs = append(errs, p.sendBatch(ctx, start, msgs[start:end])...)
+	}
+
+	err := messaging.NewBatchError(errs)
+	if err != nil {
+		p.log.Error(err.Error())
+	}
+	return err
+}
+
+// sendBatch sends msgs, starting at offset in the PublishBatch messages, and
+// returns the failed ones.
+func (p *Producer) sendBatch(ctx context.Context, offset int, msgs []*messaging.Message) []messaging.MessageError {
+	var errs []messaging.MessageError
+	entries := make([]types.SendMessageBatchRequestEntry, 0, len(msgs))
+	for i, msg := range msgs {
+		if p.fifo && len(msg.Key) == 0 {
+			errs = append(errs, messaging.MessageError{Index: offset + i, Err: errGroupRequired})
+			continue
+		}
+		messaging.InjectTraceContext(ctx, msg)
+		attributes, err := messageAttributes(msg.Headers)
+		if err != nil {
+			errs = append(errs, messaging.MessageError{Index: offset + i, Err: err})
+			continue
+		}
+		entry := types.SendMessageBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(offset + i)),
+			MessageBody:       aws.String(string(msg.Value)),
+			MessageAttributes: attributes,
+		}
+		if p.fifo {
+			entry.MessageGroupId = aws.String(string(msg.Key))
+			entry.MessageDeduplicationId = deduplicationID(msg)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return errs
+	}
+
+	out, err := p.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(p.queueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		for _, entry := range entries {
+			index, _ := strconv.Atoi(aws.ToString(entry.Id))
+			errs = append(errs, messaging.MessageError{Index: index, Err: err})
+		}
+		return errs
+	}
+	return append(errs, batchErrors(out.Failed)...)
+}
+
+func deduplicationID(msg *messaging.Message) *string {
+	if id := msg.Header(messaging.HeaderDeduplicationID); id != "" {
+		return aws.String(id)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package sqs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProducerValidation(t *testing.T) {
+	_, err := NewProducer(&fakeAPI{}, ProducerConfig{})
+	assert.ErrorIs(t, err, errQueueURLRequired)
+}
+
+func TestProducerPublish(t *testing.T) {
+	tests := []struct {
+		name      string
+		queueURL  string
+		msg       *messaging.Message
+		wantGroup *string
+		wantDedup *string
+		wantErr   error
+	}{
+		{
+			name:     "standard queue",
+			queueURL: testQueueURL,
+			msg:      &messaging.Message{Value: []byte(`{"id":1}`), Headers: map[string]string{"tenant": "acme"}},
+		},
+		{
+			name:      "fifo queue",
+			queueURL:  testFIFOQueueURL,
+			msg:       &messaging.Message{Key: []byte("order-1"), Value: []byte(`{"id":1}`), Headers: map[string]string{"tenant": "acme", messaging.HeaderDeduplicationID: "event-1"}},
+			wantGroup: aws.String("order-1"),
+			wantDedup: aws.String("event-1"),
+		},
+		{
+			name:     "fifo queue without key",
+			queueURL: testFIFOQueueURL,
+			msg:      &messaging.Message{Value: []byte(`{"id":1}`)},
+			wantErr:  errGroupRequired,
+		},
+		{
+			name:     "too many headers",
+			queueURL: testQueueURL,
+			msg:      &messaging.Message{Value: []byte(`{"id":1}`), Headers: manyHeaders(11)},
+			wantErr:  errTooManyHeaders,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{}
+			producer, err := NewProducer(api, ProducerConfig{QueueURL: tt.queueURL})
+			require.NoError(t, err)
+
+			err = producer.Publish(context.Background(), tt.msg)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, api.sent, 1)
+			sent := api.sent[0]
+			assert.Equal(t, `{"id":1}`, aws.ToString(sent.MessageBody))
+			assert.Equal(t, tt.wantGroup, sent.MessageGroupId)
+			assert.Equal(t, tt.wantDedup, sent.MessageDeduplicationId)
+			assert.Equal(t, map[string]types.MessageAttributeValue{
+				"tenant": {DataType: aws.String("String"), StringValue: aws.String("acme")},
+			}, sent.MessageAttributes)
+		})
+	}
+}
+
+func TestProducerPublishCompressed(t *testing.T) {
+	value := []byte(strings.Repeat(`{"id":1,"name":"order"}`, 100))
+
+	for _, encoding := range []string{messaging.EncodingGzipBase64, messaging.EncodingZstdBase64} {
+		t.Run(encoding, func(t *testing.T) {
+			api := &fakeAPI{}
+			producer, err := NewProducer(api, ProducerConfig{QueueURL: testQueueURL})
+			require.NoError(t, err)
+
+			publisher := messaging.Compress(producer, messaging.CompressionConfig{Encoding: encoding})
+			require.NoError(t, publisher.Publish(context.Background(), &messaging.Message{Value: value}))
+
+			require.Len(t, api.sent, 1)
+			sent := api.sent[0]
+			assert.True(t, utf8.ValidString(aws.ToString(sent.MessageBody)), "SQS accepts only text bodies")
+
+			var got []byte
+			handler := messaging.Chain(func(_ context.Context, msg *messaging.Message) error {
+				got = msg.Value
+				return nil
+			}, messaging.Decompress())
+			received := &types.Message{Body: sent.MessageBody, MessageAttributes: sent.MessageAttributes}
+			require.NoError(t, handler(context.Background(), fromSQS(testQueueURL, received)))
+			assert.Equal(t, value, got)
+		})
+	}
+}
+
+func TestProducerPublishBatch(t *testing.T) {
+	api := &fakeAPI{failIDs: map[string]bool{"3": true, "12": true}}
+	producer, err := NewProducer(api, ProducerConfig{QueueURL: testQueueURL})
+	require.NoError(t, err)
+
+	msgs := make([]*messaging.Message, 15)
+	for i := range msgs {
+		msgs[i] = &messaging.Message{Value: []byte(fmt.Sprint(i))}
+	}
+	msgs[7].Headers = manyHeaders(11)
+
+	err = producer.PublishBatch(context.Background(), msgs)
+	var batchErr *messaging.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	assert.ElementsMatch(t, []*messaging.Message{msgs[3], msgs[7], msgs[12]}, batchErr.Failed(msgs))
+	assert.Contains(t, err.Error(), "message 3: sqs: InvalidParameterValue")
+	assert.ErrorIs(t, err, errTooManyHeaders)
+
+	require.Len(t, api.batches, 2)
+	assert.Len(t, api.batches[0].Entries, 9, "the message with too many headers is not sent")
+	assert.Len(t, api.batches[1].Entries, 5)
+	assert.Equal(t, "14", aws.ToString(api.batches[1].Entries[4].MessageBody))
+}
+
+func TestProducerPublishAfter(t *testing.T) {
+	tests := []struct {
+		name      string
+		queueURL  string
+		delay     time.Duration
+		wantDelay int32
+		wantErr   error
+	}{
+		{name: "rounded up to the second", queueURL: testQueueURL, delay: 1500 * time.Millisecond, wantDelay: 2},
+		{name: "max delay", queueURL: testQueueURL, delay: MaxDelay, wantDelay: 900},
+		{name: "too long", queueURL: testQueueURL, delay: MaxDelay + time.Second, wantErr: errDelayTooLong},
+		{name: "fifo queue", queueURL: testFIFOQueueURL, delay: time.Second, wantErr: errFIFODelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeAPI{}
+			producer, err := NewProducer(api, ProducerConfig{QueueURL: tt.queueURL})
+			require.NoError(t, err)
+
+			err = producer.PublishAfter(context.Background(), &messaging.Message{Key: []byte("k"), Value: []byte("v")}, tt.delay)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, api.sent, 1)
+			assert.Equal(t, tt.wantDelay, api.sent[0].DelaySeconds)
+		})
+	}
+}
+
+func manyHeaders(n int) map[string]string {
+	headers := make(map[string]string, n)
+	for i := range n {
+		headers[fmt.Sprint("header-", i)] = "value"
+	}
+	// The deduplication ID is not an attribute.
+	headers[messaging.HeaderDeduplicationID] = "event-1"
+	return headers
+}
@@ -0,0 +1,105 @@
+// Package sqs integrates Amazon SQS through aws-sdk-go-v2, for standard and
+// FIFO queues.
+package sqs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/bagastri07/platigo/messaging"
+)
+
+const (
+	// maxBatchSize is the maximum number of entries of an SQS batch request.
+	maxBatchSize = 10
+	// maxAttributes is the maximum number of message attributes of an SQS
+	// message.
+	maxAttributes = 10
+)
+
+var (
+	errQueueURLRequired = errors.New("sqs: queue URL is required")
+	errTooManyHeaders   = fmt.Errorf("sqs: messages have at most %d attributes", maxAttributes)
+)
+
+// API is the part of *sqs.Client used by the package.
+type API interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+	ChangeMessageVisibilityBatch(ctx context.Context, params *sqs.ChangeMessageVisibilityBatchInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityBatchOutput, error)
+}
+
+// isFIFO reports whether queueURL is a FIFO queue, whose name ends in .fifo.
+func isFIFO(queueURL string) bool {
+	return strings.HasSuffix(queueURL, ".fifo")
+}
+
+// queueName returns the name of the queue at queueURL.
+func queueName(queueURL string) string {
+	return queueURL[strings.LastIndex(queueURL, "/")+1:]
+}
+
+func messageAttributes(headers map[string]string) (map[string]types.MessageAttributeValue, error) {
+	var attributes map[string]types.MessageAttributeValue
+	for key, value := range headers {
+		if key == messaging.HeaderDeduplicationID {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string]types.MessageAttributeValue, len(headers))
+		}
+		attributes[key] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+	if len(attributes) > maxAttributes {
+		return nil, errTooManyHeaders
+	}
+	return attributes, nil
+}
+
+func fromSQS(queueURL string, msg *types.Message) *messaging.Message {
+	out := &messaging.Message{
+		Topic: queueName(queueURL),
+		Value: []byte(aws.ToString(msg.Body)),
+	}
+	if group := msg.Attributes[string(types.MessageSystemAttributeNameMessageGroupId)]; group != "" {
+		out.Key = []byte(group)
+	}
+	if sent, err := strconv.ParseInt(msg.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		out.Timestamp = time.UnixMilli(sent)
+	}
+	if seq, err := strconv.ParseInt(msg.Attributes[string(types.MessageSystemAttributeNameSequenceNumber)], 10, 64); err == nil {
+		out.Offset = seq
+	}
+	for key, value := range msg.MessageAttributes {
+		if value.StringValue != nil {
+			out.SetHeader(key, *value.StringValue)
+		}
+	}
+	return out
+}
+
+// batchErrors turns the failed entries of a batch request, whose IDs are
+// their index, into message errors.
+func batchErrors(failed []types.BatchResultErrorEntry) []messaging.MessageError {
+	errs := make([]messaging.MessageError, 0, len(failed))
+	for _, entry := range failed {
+		index, _ := strconv.Atoi(aws.ToString(entry.Id))
+		errs = append(errs, messaging.MessageError{
+			Index: index,
+			Err:   fmt.Errorf("sqs: %s: %s", aws.ToString(entry.Code), aws.ToString(entry.Message)),
+		})
+	}
+	return errs
+}
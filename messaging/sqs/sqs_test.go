@@ -0,0 +1,82 @@
+package sqs
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	testQueueURL     = "https://sqs.ap-southeast-1.amazonaws.com/123456789012/orders"
+	testFIFOQueueURL = "https://sqs.ap-southeast-1.amazonaws.com/123456789012/orders.fifo"
+)
+
+// fakeAPI records the requests and serves the receives from a list of
+// batches.
+type fakeAPI struct {
+	mu sync.Mutex
+
+	sent       []*sqs.SendMessageInput
+	batches    []*sqs.SendMessageBatchInput
+	failIDs    map[string]bool
+	receives   [][]types.Message
+	deleted    []string
+	visibility []string
+}
+
+func (f *fakeAPI) SendMessage(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, params)
+	return &sqs.SendMessageOutput{MessageId: aws.String("id")}, nil
+}
+
+func (f *fakeAPI) SendMessageBatch(_ context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = append(f.batches, params)
+
+	out := &sqs.SendMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		if f.failIDs[aws.ToString(entry.Id)] {
+			out.Failed = append(out.Failed, types.BatchResultErrorEntry{Id: entry.Id, Code: aws.String("InvalidParameterValue"), Message: aws.String("invalid")})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeAPI) ReceiveMessage(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	f.mu.Lock()
+	if len(f.receives) > 0 {
+		messages := f.receives[0]
+		f.receives = f.receives[1:]
+		f.mu.Unlock()
+		return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+	}
+	f.mu.Unlock()
+
+	// Long polling an empty queue.
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (f *fakeAPI) DeleteMessageBatch(_ context.Context, params *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range params.Entries {
+		f.deleted = append(f.deleted, aws.ToString(entry.ReceiptHandle))
+	}
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (f *fakeAPI) ChangeMessageVisibilityBatch(_ context.Context, params *sqs.ChangeMessageVisibilityBatchInput, _ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, entry := range params.Entries {
+		f.visibility = append(f.visibility, aws.ToString(entry.ReceiptHandle))
+	}
+	return &sqs.ChangeMessageVisibilityBatchOutput{}, nil
+}
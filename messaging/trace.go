@@ -0,0 +1,46 @@
+package messaging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerCarrier exposes the headers of a message to OTel propagators.
+type headerCarrier struct {
+	msg *Message
+}
+
+func (c headerCarrier) Get(key string) string { return c.msg.Header(key) }
+
+func (c headerCarrier) Set(key, value string) { c.msg.SetHeader(key, value) }
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.msg.Headers))
+	for key := range c.msg.Headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectTraceContext writes the trace context of ctx into the headers of
+// msg, with the global OTel propagator. Every producer of platigo calls it
+// before publishing.
+func InjectTraceContext(ctx context.Context, msg *Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg})
+}
+
+// ExtractTraceContext returns ctx with the trace context read from the
+// headers of msg, with the global OTel propagator. Every consumer of platigo
+// calls it before running the handler, so its spans continue the trace of
+// the producer.
+//
+// Both are no-ops until a propagator is installed, e.g.
+//
+//	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+func ExtractTraceContext(ctx context.Context, msg *Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{msg})
+}
+
+var _ propagation.TextMapCarrier = headerCarrier{}
@@ -0,0 +1,42 @@
+package messaging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// useTraceContextPropagator installs the W3C propagator for the duration of
+// the test.
+func useTraceContextPropagator(t *testing.T) {
+	t.Helper()
+
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+}
+
+func testSpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestTraceContextRoundTrip(t *testing.T) {
+	useTraceContextPropagator(t)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), testSpanContext())
+	msg := &Message{Topic: "orders"}
+	InjectTraceContext(ctx, msg)
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Header("traceparent"))
+
+	got := trace.SpanContextFromContext(ExtractTraceContext(context.Background(), msg))
+	assert.Equal(t, testSpanContext().TraceID(), got.TraceID())
+	assert.True(t, got.IsRemote())
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+// AccessLog logs every request once handled, with its method, path,
+// status, response size, duration and request ID. Requests are logged at
+// info level, and at error level on 5xx responses.
+func AccessLog(log logger.Logger) Middleware {
+	if log == nil {
+		log = logger.Nop()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started := time.Now()
+			rw := wrapResponseWriter(w)
+			defer func() {
+				fields := logger.Fields{
+					"method":   r.Method,
+					"path":     r.URL.Path,
+					"status":   rw.Status(),
+					"bytes":    rw.size,
+					"duration": time.Since(started).String(),
+					"remoteIP": remoteIP(r),
+				}
+				// The ID set by an inner RequestID middleware is only
+				// found on the response.
+				if id := rw.Header().Get(HeaderRequestID); id != "" && RequestIDFromContext(r.Context()) == "" {
+					fields["requestID"] = id
+				}
+
+				entry := logger.WithContext(r.Context(), log).With(fields)
+				if rw.Status() >= http.StatusInternalServerError {
+					entry.Error("HTTP request handled")
+				} else {
+					entry.Info("HTTP request handled")
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// responseWriter records the status and size of a response.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// wrapResponseWriter returns w when it is already a responseWriter, so
+// that stacked middlewares share it.
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+// Status returns the status written, or 200 which net/http sends when the
+// handler wrote nothing.
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Written reports whether the handler started the response.
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("middleware: response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
@@ -0,0 +1,67 @@
+// Package echomw adapts the platigo middlewares to Echo:
+//
+//	e := echo.New()
+//	e.Use(echomw.RequestID(), echomw.AccessLog(log), echomw.Recover(log))
+package echomw
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// Wrap adapts a net/http middleware to Echo. Errors returned by the next
+// handlers are passed to the error handler of Echo right away, so that
+// the middlewares see the response they produce.
+func Wrap(m middleware.Middleware) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				c.SetRequest(r)
+				c.Response().Writer = w
+				if err := next(c); err != nil {
+					c.Error(err)
+				}
+			})).ServeHTTP(c.Response().Writer, c.Request())
+			return nil
+		}
+	}
+}
+
+// RequestID adapts middleware.RequestID.
+func RequestID() echo.MiddlewareFunc {
+	return Wrap(middleware.RequestID())
+}
+
+// AccessLog adapts middleware.AccessLog.
+func AccessLog(log logger.Logger) echo.MiddlewareFunc {
+	return Wrap(middleware.AccessLog(log))
+}
+
+// Recover adapts middleware.Recover.
+func Recover(log logger.Logger) echo.MiddlewareFunc {
+	return Wrap(middleware.Recover(log))
+}
+
+// Timeout adapts middleware.Timeout.
+func Timeout(timeout time.Duration) echo.MiddlewareFunc {
+	return Wrap(middleware.Timeout(timeout))
+}
+
+// BodyLimit adapts middleware.BodyLimit.
+func BodyLimit(limit int64) echo.MiddlewareFunc {
+	return Wrap(middleware.BodyLimit(limit))
+}
+
+// RateLimit adapts middleware.RateLimit.
+func RateLimit(config middleware.RateLimitConfig) echo.MiddlewareFunc {
+	return Wrap(middleware.RateLimit(config))
+}
+
+// Idempotency adapts middleware.Idempotency.
+func Idempotency(config middleware.IdempotencyConfig) echo.MiddlewareFunc {
+	return Wrap(middleware.Idempotency(config))
+}
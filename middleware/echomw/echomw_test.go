@@ -0,0 +1,61 @@
+package echomw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/middleware"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusLogger keeps the status of the access log entries.
+type statusLogger struct {
+	statuses *[]any
+	fields   logger.Fields
+}
+
+func (l statusLogger) With(fields logger.Fields) logger.Logger {
+	return statusLogger{statuses: l.statuses, fields: fields}
+}
+func (l statusLogger) Debug(string) {}
+func (l statusLogger) Info(string)  { *l.statuses = append(*l.statuses, l.fields["status"]) }
+func (l statusLogger) Warn(string)  {}
+func (l statusLogger) Error(string) { *l.statuses = append(*l.statuses, l.fields["status"]) }
+
+func TestEchoMiddlewares(t *testing.T) {
+	var statuses []any
+	log := statusLogger{statuses: &statuses}
+
+	e := echo.New()
+	e.Use(RequestID(), AccessLog(log), Recover(log), BodyLimit(4))
+	e.GET("/orders/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, middleware.RequestIDFromContext(c.Request().Context()))
+	})
+	e.GET("/missing", func(echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+	e.GET("/panic", func(echo.Context) error {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(middleware.HeaderRequestID, "req-1")
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req-1", rec.Body.String())
+	assert.Equal(t, "req-1", rec.Header().Get(middleware.HeaderRequestID))
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	assert.Equal(t, []any{http.StatusOK, http.StatusNotFound, nil, http.StatusInternalServerError}, statuses)
+}
@@ -0,0 +1,97 @@
+// Package ginmw adapts the platigo middlewares to Gin:
+//
+//	r := gin.New()
+//	r.Use(ginmw.RequestID(), ginmw.AccessLog(log), ginmw.Recover(log))
+package ginmw
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+// Wrap adapts a net/http middleware to Gin. The chain is aborted when the
+// middleware answers the request itself.
+func Wrap(m middleware.Middleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		called := false
+		original := c.Writer
+		m(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			c.Writer = &responseWriter{ResponseWriter: original, w: w}
+			c.Next()
+		})).ServeHTTP(original, c.Request)
+		c.Writer = original
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// RequestID adapts middleware.RequestID.
+func RequestID() gin.HandlerFunc {
+	return Wrap(middleware.RequestID())
+}
+
+// AccessLog adapts middleware.AccessLog.
+func AccessLog(log logger.Logger) gin.HandlerFunc {
+	return Wrap(middleware.AccessLog(log))
+}
+
+// Recover adapts middleware.Recover.
+func Recover(log logger.Logger) gin.HandlerFunc {
+	return Wrap(middleware.Recover(log))
+}
+
+// Timeout adapts middleware.Timeout.
+func Timeout(timeout time.Duration) gin.HandlerFunc {
+	return Wrap(middleware.Timeout(timeout))
+}
+
+// BodyLimit adapts middleware.BodyLimit.
+func BodyLimit(limit int64) gin.HandlerFunc {
+	return Wrap(middleware.BodyLimit(limit))
+}
+
+// RateLimit adapts middleware.RateLimit.
+func RateLimit(config middleware.RateLimitConfig) gin.HandlerFunc {
+	return Wrap(middleware.RateLimit(config))
+}
+
+// Idempotency adapts middleware.Idempotency.
+func Idempotency(config middleware.IdempotencyConfig) gin.HandlerFunc {
+	return Wrap(middleware.Idempotency(config))
+}
+
+// responseWriter writes through the writer given by the middleware, and
+// keeps the Gin writer for the rest of gin.ResponseWriter.
+type responseWriter struct {
+	gin.ResponseWriter
+	w http.ResponseWriter
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.w.Header()
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.w.WriteHeader(status)
+}
+
+func (w *responseWriter) WriteHeaderNow() {
+	if !w.ResponseWriter.Written() {
+		w.w.WriteHeader(w.ResponseWriter.Status())
+	}
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	return w.w.Write(b)
+}
+
+func (w *responseWriter) WriteString(s string) (int, error) {
+	return w.w.Write([]byte(s))
+}
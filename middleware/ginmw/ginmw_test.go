@@ -0,0 +1,63 @@
+package ginmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// statusLogger keeps the status of the access log entries.
+type statusLogger struct {
+	statuses *[]any
+	fields   logger.Fields
+}
+
+func (l statusLogger) With(fields logger.Fields) logger.Logger {
+	return statusLogger{statuses: l.statuses, fields: fields}
+}
+func (l statusLogger) Debug(string) {}
+func (l statusLogger) Info(string)  { *l.statuses = append(*l.statuses, l.fields["status"]) }
+func (l statusLogger) Warn(string)  {}
+func (l statusLogger) Error(string) { *l.statuses = append(*l.statuses, l.fields["status"]) }
+
+func TestGinMiddlewares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var statuses []any
+	log := statusLogger{statuses: &statuses}
+
+	r := gin.New()
+	r.Use(RequestID(), AccessLog(log), Recover(log), BodyLimit(4))
+	r.GET("/orders/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, middleware.RequestIDFromContext(c.Request.Context()))
+	})
+	r.POST("/orders", func(c *gin.Context) {
+		t.Error("the handler runs despite the body limit")
+	})
+	r.GET("/panic", func(*gin.Context) {
+		panic("boom")
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(middleware.HeaderRequestID, "req-1")
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "req-1", rec.Body.String())
+	assert.Equal(t, "req-1", rec.Header().Get(middleware.HeaderRequestID))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("too large")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	assert.Equal(t, []any{http.StatusOK, http.StatusRequestEntityTooLarge, nil, http.StatusInternalServerError}, statuses)
+}
@@ -0,0 +1,255 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+
+	"github.com/bagastri07/platigo/idempotency"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+)
+
+const (
+	// HeaderIdempotencyKey carries the key identifying the attempts of the
+	// same operation, such as a UUID generated by the client.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is set on the responses replayed from a
+	// previous attempt.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore records the responses of the idempotency keys. It is
+// implemented by *idempotency.Store.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key string) (response []byte, token string, err error)
+	Complete(ctx context.Context, key, token string, response []byte) error
+	Release(ctx context.Context, key, token string) error
+}
+
+type IdempotencyConfig struct {
+	Store IdempotencyStore
+
+	// Methods are the methods honoring the header, POST and PATCH by
+	// default. Required rejects their requests without the header with a
+	// 400, instead of handling them as usual.
+	Methods  []string
+	Required bool
+
+	// Scope namespaces the keys, so that clients cannot replay the
+	// responses of each other or of other endpoints. It returns the
+	// caller, such as the tenant or user ID, and defaults to the method and
+	// path of the request.
+	Scope func(r *http.Request) string
+
+	// Logger receives the store failures. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// volatileHeaders are set anew on every response, replays included.
+var volatileHeaders = []string{"Date", HeaderRequestID, HeaderRateLimitLimit, HeaderRateLimitRemaining}
+
+// storedResponse is a response recorded for its idempotency key.
+type storedResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// Idempotency runs a request once per Idempotency-Key header and replays
+// its response to the retries, with the Idempotent-Replayed header:
+//
+//	store := idempotency.NewStore(redisClient, idempotency.Config{})
+//	handler = middleware.Idempotency(middleware.IdempotencyConfig{Store: store})(handler)
+//
+// A retry arriving while the first attempt runs gets a 409, and a key
+// reused with another request body a 422. 5xx responses are not recorded,
+// so the operation can be retried. Since a duplicate operation is worse
+// than a failed request, it answers 503 when the store fails.
+func Idempotency(config IdempotencyConfig) Middleware {
+	if config.Methods == nil {
+		config.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if config.Scope == nil {
+		config.Scope = func(r *http.Request) string {
+			return r.Method + " " + r.URL.Path
+		}
+	}
+	log := config.Logger
+	if log == nil {
+		log = logger.Nop()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(config.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			idempotencyKey := r.Header.Get(HeaderIdempotencyKey)
+			if idempotencyKey == "" && !config.Required {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if idempotencyKey == "" || len(idempotencyKey) > maxIdempotencyKeyLength {
+				http.Error(w, "invalid "+HeaderIdempotencyKey+" header", http.StatusBadRequest)
+				return
+			}
+
+			fingerprint, err := requestFingerprint(r)
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+					return
+				}
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+
+			key := hashKey(config.Scope(r) + "\x00" + idempotencyKey)
+			log := logger.WithContext(r.Context(), log)
+			fields := logger.Fields{"method": r.Method, "path": r.URL.Path}
+			// The response is recorded even if the client went away.
+			ctx := context.WithoutCancel(r.Context())
+
+			stored, token, err := config.Store.Begin(ctx, key)
+			switch {
+			case errors.Is(err, idempotency.ErrInProgress):
+				w.Header().Set("Retry-After", "1")
+				http.Error(w, "request with the same "+HeaderIdempotencyKey+" in progress", http.StatusConflict)
+				return
+			case err != nil:
+				fields["error"] = err.Error()
+				log.With(fields).Error("Failed to begin idempotent request")
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			case stored != nil:
+				replay(w, stored, fingerprint)
+				return
+			}
+
+			rec := &recordingWriter{ResponseWriter: w}
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				// A panic or a 5xx: let the client retry.
+				if err := config.Store.Release(ctx, key, token); err != nil {
+					fields["error"] = err.Error()
+					log.With(fields).Error("Failed to release idempotency key")
+				}
+			}()
+			next.ServeHTTP(rec, r)
+
+			if rec.Status() >= http.StatusInternalServerError {
+				return
+			}
+			header := rec.Header().Clone()
+			for _, name := range volatileHeaders {
+				header.Del(name)
+			}
+			response, err := json.Marshal(storedResponse{
+				Fingerprint: fingerprint,
+				Status:      rec.Status(),
+				Header:      header,
+				Body:        rec.body.Bytes(),
+			})
+			if err == nil {
+				err = config.Store.Complete(ctx, key, token, response)
+			}
+			if err != nil {
+				fields["error"] = err.Error()
+				log.With(fields).Error("Failed to record idempotent response")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// replay writes a stored response, unless it belongs to another request.
+func replay(w http.ResponseWriter, stored []byte, fingerprint string) {
+	var res storedResponse
+	if err := json.Unmarshal(stored, &res); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if res.Fingerprint != fingerprint {
+		http.Error(w, HeaderIdempotencyKey+" reused with another request", http.StatusUnprocessableEntity)
+		return
+	}
+
+	for name, values := range res.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(res.Status)
+	_, _ = w.Write(res.Body)
+}
+
+// requestFingerprint hashes the method, URL and body of r, whose body is
+// put back for the handler.
+func requestFingerprint(r *http.Request) (string, error) {
+	hash := sha256.New()
+	_, _ = io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	if r.Body != nil && r.Body != http.NoBody {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", err
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write(body)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// hashKey keeps the keys of the store short and free of client data.
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordingWriter keeps a copy of the response written through it.
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *recordingWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/idempotency"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyStore(t *testing.T) *idempotency.Store {
+	t.Helper()
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return idempotency.NewStore(client, idempotency.Config{})
+}
+
+func TestIdempotency(t *testing.T) {
+	var calls atomic.Int32
+	status := http.StatusCreated
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/payments/1")
+		w.WriteHeader(status)
+		_, _ = w.Write(append([]byte("paid "), body...))
+	}), RequestID(), Idempotency(IdempotencyConfig{Store: newIdempotencyStore(t)}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("key-1", "10 EUR")
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, "paid 10 EUR", first.Body.String())
+	assert.Empty(t, first.Header().Get(HeaderIdempotentReplayed))
+
+	replayed := send("key-1", "10 EUR")
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "paid 10 EUR", replayed.Body.String())
+	assert.Equal(t, "/payments/1", replayed.Header().Get("Location"))
+	assert.Equal(t, "true", replayed.Header().Get(HeaderIdempotentReplayed))
+	assert.NotEqual(t, first.Header().Get(HeaderRequestID), replayed.Header().Get(HeaderRequestID))
+	assert.Equal(t, int32(1), calls.Load())
+
+	assert.Equal(t, http.StatusUnprocessableEntity, send("key-1", "99 EUR").Code)
+	assert.Equal(t, int32(1), calls.Load())
+
+	send("", "10 EUR")
+	send("", "10 EUR")
+	assert.Equal(t, int32(3), calls.Load())
+
+	status = http.StatusBadGateway
+	assert.Equal(t, http.StatusBadGateway, send("key-2", "10 EUR").Code)
+	status = http.StatusOK
+	assert.Equal(t, http.StatusOK, send("key-2", "10 EUR").Code, "5xx responses are not replayed")
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	store := newIdempotencyStore(t)
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := Idempotency(IdempotencyConfig{Store: store})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		return req
+	}
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, newRequest())
+		done <- rec.Code
+	}()
+
+	<-entered
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, http.StatusCreated, <-done)
+}
+
+// failingStore fails every call.
+type failingStore struct{}
+
+func (failingStore) Begin(context.Context, string) ([]byte, string, error) {
+	return nil, "", errors.New("redis down")
+}
+func (failingStore) Complete(context.Context, string, string, []byte) error { return nil }
+func (failingStore) Release(context.Context, string, string) error          { return nil }
+
+func TestIdempotencyErrors(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name       string
+		config     IdempotencyConfig
+		method     string
+		key        string
+		wantStatus int
+	}{
+		{name: "key required", config: IdempotencyConfig{Store: failingStore{}, Required: true}, method: http.MethodPost, wantStatus: http.StatusBadRequest},
+		{name: "key too long", config: IdempotencyConfig{Store: failingStore{}}, method: http.MethodPost, key: strings.Repeat("k", 256), wantStatus: http.StatusBadRequest},
+		{name: "store failure", config: IdempotencyConfig{Store: failingStore{}}, method: http.MethodPost, key: "key-1", wantStatus: http.StatusServiceUnavailable},
+		{name: "other method", config: IdempotencyConfig{Store: failingStore{}, Required: true}, method: http.MethodGet, key: "key-1", wantStatus: http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/payments", nil)
+			if tt.key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.key)
+			}
+			rec := httptest.NewRecorder()
+			Idempotency(tt.config)(ok).ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+func TestIdempotencyReleasesOnPanic(t *testing.T) {
+	store := newIdempotencyStore(t)
+	panicking := true
+	handler := Idempotency(IdempotencyConfig{Store: store})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if panicking {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		return req
+	}
+
+	require.Panics(t, func() { handler.ServeHTTP(httptest.NewRecorder(), newRequest()) })
+	panicking = false
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, newRequest())
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Timeout sets a deadline of timeout on the request context. Handlers and
+// the clients they call must honor the context; the ones returning after
+// the deadline without writing a response get a 503.
+func Timeout(timeout time.Duration) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			rw := wrapResponseWriter(w)
+			next.ServeHTTP(rw, r.WithContext(ctx))
+			if !rw.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+		})
+	}
+}
+
+// BodyLimit rejects the request bodies larger than limit bytes with a 413.
+// Bodies without a Content-Length fail with an *http.MaxBytesError once
+// the handler reads past the limit.
+func BodyLimit(limit int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
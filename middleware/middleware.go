@@ -0,0 +1,47 @@
+// Package middleware provides the standard HTTP middlewares of a service:
+// request IDs, access logging, panic recovery, timeouts, body size limits,
+// rate limiting and idempotency keys. They are plain net/http middlewares;
+// the echomw and ginmw sub packages adapt them to Echo and Gin.
+//
+//	handler := middleware.Chain(mux,
+//		middleware.RequestID(),
+//		middleware.AccessLog(log),
+//		middleware.Recover(log),
+//		middleware.Timeout(10*time.Second),
+//		middleware.BodyLimit(1<<20),
+//	)
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bagastri07/platigo"
+)
+
+// HeaderRequestID carries the ID of a request, generated unless set by the
+// caller, and is echoed in the response.
+const HeaderRequestID = platigo.HeaderRequestID
+
+// Middleware wraps an http.Handler.
+type Middleware func(next http.Handler) http.Handler
+
+// Chain wraps handler with middlewares, the first one being the outermost.
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// ContextWithRequestID returns a context carrying the request ID id. It is
+// the same as platigo.ContextWithRequestID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return platigo.ContextWithRequestID(ctx, id)
+}
+
+// RequestIDFromContext returns the request ID set by the RequestID
+// middleware or ContextWithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	return platigo.RequestIDFromContext(ctx)
+}
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordLogger keeps the fields of every entry logged through it.
+type recordLogger struct {
+	mu      *sync.Mutex
+	entries *[]logger.Fields
+	fields  logger.Fields
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{mu: &sync.Mutex{}, entries: &[]logger.Fields{}}
+}
+
+func (r *recordLogger) With(fields logger.Fields) logger.Logger {
+	merged := logger.Fields{}
+	for k, v := range r.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &recordLogger{mu: r.mu, entries: r.entries, fields: merged}
+}
+
+func (r *recordLogger) record(level, msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry := logger.Fields{"level": level, "msg": msg}
+	for k, v := range r.fields {
+		entry[k] = v
+	}
+	*r.entries = append(*r.entries, entry)
+}
+
+func (r *recordLogger) Entries() []logger.Fields {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]logger.Fields(nil), *r.entries...)
+}
+
+func (r *recordLogger) Debug(msg string) { r.record("debug", msg) }
+func (r *recordLogger) Info(msg string)  { r.record("info", msg) }
+func (r *recordLogger) Warn(msg string)  { r.record("warn", msg) }
+func (r *recordLogger) Error(msg string) { r.record("error", msg) }
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "kept from the caller", header: "req-123", want: "req-123"},
+		{name: "generated when missing"},
+		{name: "generated when invalid", header: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID, gotOpaqueID string
+			handler := RequestID()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				gotID = RequestIDFromContext(r.Context())
+				gotOpaqueID = platigo.OpaqueIDFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestID, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.want != "" {
+				assert.Equal(t, tt.want, gotID)
+			} else {
+				assert.Len(t, gotID, 36)
+			}
+			assert.Equal(t, gotID, gotOpaqueID)
+			assert.Equal(t, gotID, rec.Header().Get(HeaderRequestID))
+		})
+	}
+}
+
+func TestAccessLogAndRecover(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantLevels []string
+		wantBytes  int64
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = io.WriteString(w, "created")
+			},
+			wantStatus: http.StatusCreated,
+			wantLevels: []string{"info"},
+			wantBytes:  7,
+		},
+		{
+			name:       "empty response",
+			handler:    func(http.ResponseWriter, *http.Request) {},
+			wantStatus: http.StatusOK,
+			wantLevels: []string{"info"},
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantStatus: http.StatusBadGateway,
+			wantLevels: []string{"error"},
+		},
+		{
+			name: "panic",
+			handler: func(http.ResponseWriter, *http.Request) {
+				panic("boom")
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantLevels: []string{"error", "error"},
+			wantBytes:  int64(len("Internal Server Error\n")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newRecordLogger()
+			handler := Chain(tt.handler, RequestID(), AccessLog(log), Recover(log))
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+			req.Header.Set(HeaderRequestID, "req-1")
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			entries := log.Entries()
+			require.Len(t, entries, len(tt.wantLevels))
+			for i, level := range tt.wantLevels {
+				assert.Equal(t, level, entries[i]["level"])
+			}
+			access := entries[len(entries)-1]
+			assert.Equal(t, "HTTP request handled", access["msg"])
+			assert.Equal(t, tt.wantStatus, access["status"])
+			assert.Equal(t, tt.wantBytes, access["bytes"])
+			assert.Equal(t, "/orders", access["path"])
+			assert.Equal(t, "req-1", access["requestID"])
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	handler := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	handler = Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(context.Background()))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestBodyLimit(t *testing.T) {
+	var readErr error
+	handler := BodyLimit(8)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("0123456789")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/", io.NopCloser(strings.NewReader("0123456789")))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	var maxBytesErr *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &maxBytesErr)
+
+	readErr = nil
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("01234567")))
+	assert.NoError(t, readErr)
+}
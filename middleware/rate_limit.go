@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/ratelimit"
+)
+
+// The headers set by RateLimit with the quota of the client.
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+)
+
+type RateLimitConfig struct {
+	// Limiter enforces the quota of every client, typically
+	// ratelimit.NewTokenBucket so that the replicas share the quotas
+	// through Redis.
+	Limiter ratelimit.Limiter
+	// Key identifies the client of a request, KeyByIP by default. The
+	// requests with an empty key are not limited.
+	Key func(r *http.Request) string
+
+	// FailClosed rejects the requests with a 503 when the limiter fails,
+	// such as when Redis is unreachable. They are let through by default.
+	FailClosed bool
+	// Logger receives the limiter failures. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// RateLimit rejects the requests exceeding the quota of their client with
+// a 429 and a Retry-After header. Every response carries the
+// X-RateLimit-Limit and X-RateLimit-Remaining headers.
+func RateLimit(config RateLimitConfig) Middleware {
+	if config.Key == nil {
+		config.Key = KeyByIP
+	}
+	log := config.Logger
+	if log == nil {
+		log = logger.Nop()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := config.Key(r)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := config.Limiter.Allow(r.Context(), key)
+			if err != nil {
+				log.With(logger.Fields{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"error":  err.Error(),
+				}).Warn("Rate limiter failed")
+				if config.FailClosed {
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+			w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+			if !res.Allowed {
+				retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// KeyByIP limits the requests per client IP address. The address is the
+// one of the connection: behind a proxy, use a key reading the header the
+// proxy sets instead.
+func KeyByIP(r *http.Request) string {
+	return "ip:" + remoteIP(r)
+}
+
+// KeyByHeader limits the requests per value of the header, such as
+// X-Api-Key, and per client IP address without the header. Values are
+// hashed so that the keys of the limiter do not hold credentials.
+func KeyByHeader(name string) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		value := r.Header.Get(name)
+		if value == "" {
+			return KeyByIP(r)
+		}
+		sum := sha256.Sum256([]byte(value))
+		return "key:" + hex.EncodeToString(sum[:16])
+	}
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+// limiterFunc adapts a function to ratelimit.Limiter.
+type limiterFunc func(ctx context.Context, key string) (ratelimit.Result, error)
+
+func (f limiterFunc) Allow(ctx context.Context, key string) (ratelimit.Result, error) {
+	return f(ctx, key)
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("per client", func(t *testing.T) {
+		handler := RateLimit(RateLimitConfig{
+			Limiter: ratelimit.NewLocalTokenBucket(ratelimit.Limit{Rate: 2, Period: time.Minute}),
+		})(ok)
+
+		statuses := make([]int, 0, 4)
+		var last *httptest.ResponseRecorder
+		for _, addr := range []string{"10.0.0.1:1234", "10.0.0.1:5678", "10.0.0.1:1234", "10.0.0.2:1234"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = addr
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			statuses = append(statuses, rec.Code)
+			if addr == "10.0.0.1:1234" {
+				last = rec
+			}
+		}
+
+		assert.Equal(t, []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests, http.StatusNoContent}, statuses)
+		assert.Equal(t, "2", last.Header().Get(HeaderRateLimitLimit))
+		assert.Equal(t, "0", last.Header().Get(HeaderRateLimitRemaining))
+		assert.Equal(t, "30", last.Header().Get("Retry-After"))
+	})
+
+	t.Run("per api key", func(t *testing.T) {
+		var keys []string
+		handler := RateLimit(RateLimitConfig{
+			Limiter: limiterFunc(func(_ context.Context, key string) (ratelimit.Result, error) {
+				keys = append(keys, key)
+				return ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9}, nil
+			}),
+			Key: KeyByHeader("X-Api-Key"),
+		})(ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Api-Key", "secret-key")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Len(t, keys, 2)
+		assert.Regexp(t, `^key:[0-9a-f]{32}$`, keys[0])
+		assert.NotContains(t, keys[0], "secret-key")
+		assert.Equal(t, "ip:192.0.2.1", keys[1])
+	})
+
+	failing := limiterFunc(func(context.Context, string) (ratelimit.Result, error) {
+		return ratelimit.Result{}, errors.New("redis down")
+	})
+	tests := []struct {
+		name       string
+		failClosed bool
+		wantStatus int
+	}{
+		{name: "fail open", wantStatus: http.StatusNoContent},
+		{name: "fail closed", failClosed: true, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newRecordLogger()
+			handler := RateLimit(RateLimitConfig{Limiter: failing, FailClosed: tt.failClosed, Logger: log})(ok)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if assert.Len(t, log.Entries(), 1) {
+				assert.Equal(t, "warn", log.Entries()[0]["level"])
+			}
+		})
+	}
+}
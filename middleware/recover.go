@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+// Recover turns a panic of the handler into a 500 response, logged with its
+// stack at error level, instead of dropping the connection. The
+// http.ErrAbortHandler panics used to abort a response are left alone.
+func Recover(log logger.Logger) Middleware {
+	if log == nil {
+		log = logger.Nop()
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+
+				logger.WithContext(r.Context(), log).With(logger.Fields{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"panic":  fmt.Sprint(v),
+					"stack":  string(debug.Stack()),
+				}).Error("Recovered panic in HTTP handler")
+				if !rw.Written() {
+					http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/bagastri07/platigo"
+)
+
+// RequestID reads the X-Request-Id header of the request, or generates a
+// UUID, and sets it on the request context and the response. The platigo
+// clients called with the context send the ID along, as the X-Opaque-Id of
+// the OpenSearch calls.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := platigo.EnsureRequestID(r.Header.Get(HeaderRequestID))
+			w.Header().Set(HeaderRequestID, id)
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+		})
+	}
+}
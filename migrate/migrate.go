@@ -0,0 +1,460 @@
+// Package migrate applies the versioned SQL migrations embedded in a
+// service, so it migrates its own database at startup without a separate
+// binary.
+//
+// Migrations are pairs of files named VERSION_NAME.up.sql and
+// VERSION_NAME.down.sql, the down file being optional:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	m, err := migrate.New(db.DB, migrations, migrate.Config{Dir: "migrations"})
+//	applied, err := m.Up(ctx)
+//
+// Every migration runs in a transaction along with the update of the
+// migrations table. MySQL commits implicitly around DDL statements such as
+// CREATE TABLE or ALTER TABLE, so a MySQL migration failing halfway leaves
+// its earlier statements applied and must be fixed by hand; keep MySQL
+// migrations to one DDL statement each. On MySQL the files are split into
+// statements on the semicolons outside strings and comments, so routine
+// bodies holding semicolons are not supported.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+const defaultTable = "schema_migrations"
+
+var (
+	errUnsupportedDriver = errors.New("migrate: driver must be postgres or mysql")
+	errInvalidTable      = errors.New("migrate: invalid table name")
+	errNoDown            = errors.New("migrate: migration has no down file")
+	errLockTimeout       = errors.New("migrate: timed out waiting for the migration lock")
+
+	fileName   = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Config configures a Migrator.
+type Config struct {
+	// Dir is the directory of the migrations in the file system, its root
+	// by default.
+	Dir string
+	// Table records the applied versions, "schema_migrations" by default.
+	// It is created when missing.
+	Table string
+	// LockTimeout bounds the wait for the migration lock held by another
+	// instance, one minute by default.
+	LockTimeout time.Duration
+
+	// Logger receives the migrator logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Migration is a versioned change of the schema.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the state of a migration.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Applied reports whether the migration is applied.
+func (s Status) Applied() bool {
+	return s.AppliedAt != nil
+}
+
+// Migrator applies and reverts migrations.
+type Migrator struct {
+	db          *sqlx.DB
+	migrations  []Migration
+	table       string
+	lockTimeout time.Duration
+	dialect     dialect
+	log         logger.Logger
+	now         func() time.Time
+}
+
+// New reads the migrations of fsys for db, opened with the pgx, postgres or
+// mysql driver.
+func New(db *sqlx.DB, fsys fs.FS, config Config) (*Migrator, error) {
+	if config.Table == "" {
+		config.Table = defaultTable
+	}
+	if !identifier.MatchString(config.Table) {
+		return nil, errInvalidTable
+	}
+	if config.Dir == "" {
+		config.Dir = "."
+	}
+	if config.LockTimeout <= 0 {
+		config.LockTimeout = time.Minute
+	}
+
+	d, err := dialectOf(db.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := readMigrations(fsys, config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		migrations:  migrations,
+		table:       config.Table,
+		lockTimeout: config.LockTimeout,
+		dialect:     d,
+		log:         logger.WithLevel(config.Logger, logger.InfoLevel),
+		now:         time.Now,
+	}, nil
+}
+
+// Migrations returns the migrations, by increasing version.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies the pending migrations by increasing version, each in its own
+// transaction, which does not cover the DDL statements on MySQL, and
+// returns how many were applied. Instances migrating
+// concurrently wait for each other.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sqlx.Conn, done map[int64]time.Time) error {
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			insert := conn.Rebind(fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", m.table))
+			if err := m.apply(ctx, conn, migration, migration.Up, insert, migration.Version, migration.Name, m.now().UTC()); err != nil {
+				return err
+			}
+			applied++
+			m.log.With(logger.Fields{"version": migration.Version, "name": migration.Name}).Info("Migration applied")
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, most recent first, and
+// returns how many were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *sqlx.Conn, done map[int64]time.Time) error {
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%w: %d_%s", errNoDown, migration.Version, migration.Name)
+			}
+			remove := conn.Rebind(fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.table))
+			if err := m.apply(ctx, conn, migration, migration.Down, remove, migration.Version); err != nil {
+				return err
+			}
+			reverted++
+			m.log.With(logger.Fields{"version": migration.Version, "name": migration.Name}).Info("Migration reverted")
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status returns the state of every migration, by increasing version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := m.createTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Version: migration.Version, Name: migration.Name}
+		if at, ok := done[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// locked runs fn on a connection holding the migration lock, with the
+// applied versions.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sqlx.Conn, done map[int64]time.Time) error) (err error) {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	key := lockKey(m.table)
+	if err := m.dialect.lock(ctx, conn, key, m.lockTimeout); err != nil {
+		return err
+	}
+	defer func() {
+		// The lock is released with the connection anyway, but the pool
+		// keeps the connection open.
+		if unlockErr := m.dialect.unlock(context.WithoutCancel(ctx), conn, key); unlockErr != nil {
+			err = errors.Join(err, unlockErr)
+		}
+	}()
+
+	if err := m.createTable(ctx, conn); err != nil {
+		return err
+	}
+	done, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, done)
+}
+
+// apply runs the statements of a migration and records it in a transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration, statements, record string, args ...any) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, statement := range m.dialect.split(statements) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate: %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) createTable(ctx context.Context, conn *sqlx.Conn) error {
+	_, err := conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version    BIGINT PRIMARY KEY,
+	name       VARCHAR(255) NOT NULL,
+	applied_at TIMESTAMP NOT NULL
+)`, m.table))
+	return err
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf("SELECT version, applied_at FROM %s", m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		done[version] = at
+	}
+	return done, rows.Err()
+}
+
+// readMigrations reads the migrations of dir in fsys, by increasing version.
+func readMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid migration version %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d used by %s and %s", version, migration.Name, match[2])
+		}
+
+		target := &migration.Up
+		if match[3] == "down" {
+			target = &migration.Down
+		}
+		*target = string(content)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migrate: %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// lockKey derives the advisory lock key from the table, so databases
+// migrated with different tables do not lock each other.
+func lockKey(table string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("platigo-migrate:" + table))
+	return int64(h.Sum64())
+}
+
+// dialect takes the database-wide migration lock and splits the migrations
+// into the statements run one at a time.
+type dialect interface {
+	lock(ctx context.Context, conn *sqlx.Conn, key int64, timeout time.Duration) error
+	unlock(ctx context.Context, conn *sqlx.Conn, key int64) error
+	split(statements string) []string
+}
+
+func dialectOf(driverName string) (dialect, error) {
+	switch driverName {
+	case "pgx", "postgres":
+		return postgres{}, nil
+	case "mysql":
+		return mysql{}, nil
+	default:
+		return nil, errUnsupportedDriver
+	}
+}
+
+type postgres struct{}
+
+func (postgres) lock(ctx context.Context, conn *sqlx.Conn, key int64, timeout time.Duration) error {
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", key)
+	if err != nil && ctx.Err() == nil && errors.Is(lockCtx.Err(), context.DeadlineExceeded) {
+		return errLockTimeout
+	}
+	return err
+}
+
+func (postgres) unlock(ctx context.Context, conn *sqlx.Conn, key int64) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", key)
+	return err
+}
+
+// split keeps the migration whole: Postgres runs several statements in a
+// single call.
+func (postgres) split(statements string) []string {
+	return []string{statements}
+}
+
+type mysql struct{}
+
+func (mysql) lock(ctx context.Context, conn *sqlx.Conn, key int64, timeout time.Duration) error {
+	var acquired sql.NullInt64
+	err := conn.QueryRowxContext(ctx, "SELECT GET_LOCK(?, ?)", mysqlLockName(key), int(timeout.Seconds())).Scan(&acquired)
+	if err != nil {
+		return err
+	}
+	if acquired.Int64 != 1 {
+		return errLockTimeout
+	}
+	return nil
+}
+
+func (mysql) unlock(ctx context.Context, conn *sqlx.Conn, key int64) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", mysqlLockName(key))
+	return err
+}
+
+// split splits the migration into statements, as the MySQL driver runs one
+// statement per call without the multiStatements parameter.
+func (mysql) split(statements string) []string {
+	return splitStatements(statements)
+}
+
+func mysqlLockName(key int64) string {
+	return "platigo-migrate-" + strconv.FormatInt(key, 16)
+}
+
+// splitStatements splits sql on the semicolons outside quoted strings and
+// identifiers and comments, dropping the empty statements.
+func splitStatements(sql string) []string {
+	var statements []string
+	start := 0
+	add := func(end int) {
+		if statement := strings.TrimSpace(sql[start:end]); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			for i++; i < len(sql) && sql[i] != c; i++ {
+				if sql[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case c == '#' || c == '-' && strings.HasPrefix(sql[i:], "-- "):
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end + 3
+			}
+		case c == ';':
+			add(i)
+			start = i + 1
+		}
+	}
+	add(len(sql))
+	return statements
+}
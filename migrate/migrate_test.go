@@ -0,0 +1,257 @@
+package migrate
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = fstest.MapFS{
+	"migrations/0001_create_orders.up.sql":   {Data: []byte("CREATE TABLE orders (id BIGINT PRIMARY KEY)")},
+	"migrations/0001_create_orders.down.sql": {Data: []byte("DROP TABLE orders")},
+	"migrations/0002_add_total.up.sql":       {Data: []byte("ALTER TABLE orders ADD total NUMERIC")},
+	"migrations/README.md":                   {Data: []byte("ignored")},
+}
+
+func newTestMigrator(t *testing.T, fsys fstest.MapFS) (*Migrator, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	m, err := New(sqlx.NewDb(conn, "pgx"), fsys, Config{Dir: "migrations"})
+	require.NoError(t, err)
+	return m, mock
+}
+
+func expectLocked(mock sqlmock.Sqlmock, applied *sqlmock.Rows) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(applied)
+}
+
+func TestReadMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name: "sorted by version",
+			fsys: testMigrations,
+			want: []Migration{
+				{Version: 1, Name: "create_orders", Up: "CREATE TABLE orders (id BIGINT PRIMARY KEY)", Down: "DROP TABLE orders"},
+				{Version: 2, Name: "add_total", Up: "ALTER TABLE orders ADD total NUMERIC"},
+			},
+		},
+		{
+			name:    "invalid name",
+			fsys:    fstest.MapFS{"migrations/create_orders.sql": {}},
+			wantErr: "invalid migration file name",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"migrations/1_create_orders.up.sql": {Data: []byte("SELECT 1")},
+				"migrations/1_create_users.up.sql":  {Data: []byte("SELECT 1")},
+			},
+			wantErr: "version 1 used by",
+		},
+		{
+			name:    "down without up",
+			fsys:    fstest.MapFS{"migrations/1_create_orders.down.sql": {Data: []byte("DROP TABLE orders")}},
+			wantErr: "has no up file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMigrations(tt.fsys, "migrations")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewUnsupportedDriver(t *testing.T) {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = New(sqlx.NewDb(conn, "sqlite3"), testMigrations, Config{Dir: "migrations"})
+	assert.ErrorIs(t, err, errUnsupportedDriver)
+}
+
+func TestMigratorUp(t *testing.T) {
+	m, mock := newTestMigrator(t, testMigrations)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	expectLocked(mock, sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, now))
+	mock.ExpectBegin()
+	mock.ExpectExec("ALTER TABLE orders ADD total NUMERIC").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)")).
+		WithArgs(2, "add_total", now).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorUpFailure(t *testing.T) {
+	m, mock := newTestMigrator(t, testMigrations)
+
+	expectLocked(mock, sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE orders").WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up(context.Background())
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.ErrorContains(t, err, "1_create_orders")
+	assert.Zero(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorDownWithoutDownFile(t *testing.T) {
+	m, mock := newTestMigrator(t, testMigrations)
+	now := time.Now()
+
+	expectLocked(mock, sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, now).AddRow(2, now))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reverted, err := m.Down(context.Background(), 1)
+	assert.ErrorIs(t, err, errNoDown)
+	assert.Zero(t, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorDownReverts(t *testing.T) {
+	m, mock := newTestMigrator(t, testMigrations)
+
+	// Only the first migration is applied, so it is the one reverted.
+	expectLocked(mock, sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec("DROP TABLE orders").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM schema_migrations WHERE version = $1")).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reverted, err := m.Down(context.Background(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorStatus(t *testing.T) {
+	m, mock := newTestMigrator(t, testMigrations)
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, at))
+
+	statuses, err := m.Status(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Status{
+		{Version: 1, Name: "create_orders", AppliedAt: &at},
+		{Version: 2, Name: "add_total"},
+	}, statuses)
+	assert.True(t, statuses[0].Applied())
+	assert.False(t, statuses[1].Applied())
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		want []string
+	}{
+		{
+			name: "single",
+			sql:  "CREATE TABLE orders (id BIGINT PRIMARY KEY)",
+			want: []string{"CREATE TABLE orders (id BIGINT PRIMARY KEY)"},
+		},
+		{
+			name: "several",
+			sql:  "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want: []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name: "quoted semicolons",
+			sql:  `INSERT INTO a VALUES ('x;y', "it\"s;", 'o''k;'); UPDATE ` + "`a;b`" + ` SET c = 1`,
+			want: []string{`INSERT INTO a VALUES ('x;y', "it\"s;", 'o''k;')`, "UPDATE `a;b` SET c = 1"},
+		},
+		{
+			name: "comments",
+			sql:  "-- drop; the table\nDROP TABLE a; # really;\n/* and; b */ DROP TABLE b;",
+			want: []string{"-- drop; the table\nDROP TABLE a", "# really;\n/* and; b */ DROP TABLE b"},
+		},
+		{
+			name: "empty",
+			sql:  " ; \n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitStatements(tt.sql))
+		})
+	}
+}
+
+func TestMigratorUpMySQLSplitsStatements(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	fsys := fstest.MapFS{"migrations/1_init.up.sql": {Data: []byte("CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);")}}
+	m, err := New(sqlx.NewDb(conn, "mysql"), fsys, Config{Dir: "migrations"})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT GET_LOCK(?, ?)")).WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE a (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE b (id INT)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations")).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(regexp.QuoteMeta("SELECT RELEASE_LOCK(?)")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	applied, err := m.Up(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorPostgresLockTimeout(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	m, err := New(sqlx.NewDb(conn, "pgx"), testMigrations, Config{Dir: "migrations", LockTimeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = m.Up(context.Background())
+	assert.ErrorIs(t, err, errLockTimeout)
+}
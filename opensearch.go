@@ -3,15 +3,22 @@ package platigo
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"github.com/bagastri07/platigo/health"
+	"github.com/bagastri07/platigo/logger"
 	"github.com/bagastri07/platigo/utils"
 	"github.com/goccy/go-json"
 	"github.com/opensearch-project/opensearch-go"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"github.com/opensearch-project/opensearch-go/opensearchtransport"
 	"github.com/opensearch-project/opensearch-go/opensearchutil"
-	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type OSConfig struct {
@@ -19,55 +26,246 @@ type OSConfig struct {
 	InsecureSkipVerify bool // Set to true only if SSL certificate verification is intentionally skipped for specific use cases (e.g., testing or development).
 	Username           string
 	Password           string
+
+	// CACert is a PEM encoded CA bundle used to verify the cluster
+	// certificate. CACertFile reads the bundle from disk instead.
+	CACert     []byte
+	CACertFile string
+	// ClientCert and ClientKey are a PEM encoded key pair for mutual TLS.
+	ClientCert []byte
+	ClientKey  []byte
+	// MinTLSVersion defaults to tls.VersionTLS12.
+	MinTLSVersion uint16
+	// TLSConfig, when set, is used as is and the TLS fields above are ignored.
+	TLSConfig *tls.Config
+
+	// Header is sent with every request. Per call headers are set on the
+	// context with ContextWithHeaders and ContextWithOpaqueID.
+	Header http.Header
+
+	// EnableCompression gzips request bodies, which pays off for large bulk
+	// payloads. Responses are always requested with Accept-Encoding: gzip and
+	// decompressed transparently by the transport.
+	EnableCompression bool
+
+	// DiscoverNodesOnStart sniffs the cluster nodes from the given addresses
+	// when the client is created, and DiscoverNodesInterval keeps doing so
+	// periodically, so traffic is spread across every node instead of only
+	// the configured ones.
+	DiscoverNodesOnStart  bool
+	DiscoverNodesInterval time.Duration
+	// MaxRetries is the number of retries on another node, 3 by default.
+	MaxRetries int
+	// RetryOnStatus lists the statuses retried, 502, 503 and 504 by default.
+	RetryOnStatus        []int
+	DisableRetry         bool
+	EnableRetryOnTimeout bool
+	// RetryBackoff returns the delay before a retry, see ExponentialBackoff.
+	RetryBackoff func(attempt int) time.Duration
+	// Selector picks the node of each request, round-robin by default. Nodes
+	// failing requests are taken out of the rotation and resurrected with an
+	// increasing timeout; ConnectionPoolFunc replaces that pool entirely.
+	Selector           opensearchtransport.Selector
+	ConnectionPoolFunc func([]*opensearchtransport.Connection, opensearchtransport.Selector) opensearchtransport.ConnectionPool
+	// MaxIdleConnsPerHost is the number of keep-alive connections per node.
+	MaxIdleConnsPerHost int
+
+	// AWSSigV4 signs requests for Amazon OpenSearch Service instead of using
+	// Username and Password.
+	AWSSigV4 *AWSSigV4Config
+
+	// TracerProvider creates the spans of every operation. Defaults to the
+	// global provider.
+	TracerProvider trace.TracerProvider
+	// Metrics records request, error and latency metrics when set.
+	Metrics *OpenSearchMetrics
+
+	// SlowThreshold logs a warning with the details of every operation
+	// lasting longer, whatever the sampling of its trace. Not set by
+	// default.
+	SlowThreshold time.Duration
+
+	// Health registers a readiness check pinging the cluster, named
+	// HealthName, "opensearch" by default.
+	Health     *health.Registry
+	HealthName string
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+	// LogLevel is the minimum level passed to Logger. Full responses are
+	// logged at DebugLevel, so the default InfoLevel keeps them out.
+	LogLevel logger.Level
 }
 
 type IndexModel interface {
 	GetID() string
 }
 
+// VersionedModel is an IndexModel carrying its own version, typically the
+// version of the source database row. Index and BulkIndex write it with
+// external versioning so stale writes are rejected.
+type VersionedModel interface {
+	IndexModel
+	GetVersion() int64
+}
+
 type OpenSearchClient interface {
 	// Index indexes a document in OpenSearch.
-	Index(ctx context.Context, indexName string, model IndexModel) (*opensearchapi.Response, error)
+	Index(ctx context.Context, indexName string, model IndexModel, opts ...RequestOption) (*opensearchapi.Response, error)
 
 	// CreateIndices creates an index in OpenSearch.
-	CreateIndices(ctx context.Context, indexName string, body *strings.Reader) (*opensearchapi.Response, error)
+	//
+	// Bodies accept any io.Reader, so a *strings.Reader, *bytes.Buffer or a
+	// streaming encoder can be passed as is.
+	CreateIndices(ctx context.Context, indexName string, body io.Reader) (*opensearchapi.Response, error)
 
 	// PutIndicesMapping updates the mapping for one or more indices in OpenSearch.
-	PutIndicesMapping(ctx context.Context, indexNames []string, body *strings.Reader) (*opensearchapi.Response, error)
+	PutIndicesMapping(ctx context.Context, indexNames []string, body io.Reader) (*opensearchapi.Response, error)
 
 	// Search performs a search query in OpenSearch.
-	Search(ctx context.Context, indexNames []string, body *strings.Reader) (*opensearchapi.Response, error)
+	Search(ctx context.Context, indexNames []string, body io.Reader, opts ...RequestOption) (*opensearchapi.Response, error)
+
+	// Get retrieves a document by ID, including its sequence number and
+	// primary term for optimistic concurrency control.
+	Get(ctx context.Context, indexName string, docID string, opts ...RequestOption) (*GetResult, error)
+
+	// Update partially updates a document by merging doc into its source.
+	Update(ctx context.Context, indexName string, docID string, doc any, opts ...RequestOption) (*opensearchapi.Response, error)
+
+	// UpdateWithScript updates a document with a stored script, so changes
+	// such as counter increments are applied atomically on the server. params
+	// are exposed to the script as params. Error responses fail with a
+	// *ResponseError, matching ErrDocumentNotFound for a missing document
+	// without WithUpsert.
+	UpdateWithScript(ctx context.Context, indexName string, docID string, scriptID string, params map[string]any, opts ...RequestOption) (*opensearchapi.Response, error)
+
+	// PutScript creates or replaces a stored script.
+	PutScript(ctx context.Context, scriptID string, script StoredScript) error
+
+	// GetScript returns a stored script. Missing scripts return an error
+	// matching ErrDocumentNotFound.
+	GetScript(ctx context.Context, scriptID string) (*StoredScript, error)
+
+	// DeleteScript deletes a stored script.
+	DeleteScript(ctx context.Context, scriptID string) error
+
+	// Suggest returns completion suggestions for prefix from a field mapped
+	// with the completion type.
+	Suggest(ctx context.Context, indexName string, field string, prefix string, opts ...RequestOption) ([]Suggestion, error)
+
+	// PutPercolatorQuery stores a query under PercolatorQueryField so documents
+	// can be matched against it with PercolateDocument.
+	PutPercolatorQuery(ctx context.Context, indexName string, queryID string, query any, opts ...RequestOption) (*opensearchapi.Response, error)
+
+	// PercolateDocument returns the IDs of the stored queries matching doc.
+	PercolateDocument(ctx context.Context, indexName string, doc any, opts ...RequestOption) ([]string, error)
+
+	// Delete deletes a document by ID from an index in OpenSearch.
+	Delete(ctx context.Context, indexName string, docID string, opts ...RequestOption) (*opensearchapi.Response, error)
 
 	// BulkIndex indexes multiple documents in OpenSearch.
-	BulkIndex(ctx context.Context, indexName string, models []IndexModel) error
+	BulkIndex(ctx context.Context, indexName string, models []IndexModel, opts ...RequestOption) (*BulkStats, error)
+
+	// CatIndices lists the indices matching indexNames, or all of them.
+	CatIndices(ctx context.Context, indexNames ...string) ([]CatIndex, error)
+
+	// CatShards lists the shards and their allocation of the indices matching
+	// indexNames, or of all of them.
+	CatShards(ctx context.Context, indexNames ...string) ([]CatShard, error)
+
+	// CatNodes lists the nodes of the cluster.
+	CatNodes(ctx context.Context) ([]CatNode, error)
+
+	// ListTasks lists the tasks running on the cluster, e.g. reindex or
+	// delete-by-query operations.
+	ListTasks(ctx context.Context, filter TaskFilter) ([]Task, error)
+
+	// GetTask returns a task by ID, including its result once completed.
+	GetTask(ctx context.Context, taskID string) (*TaskInfo, error)
+
+	// CancelTask cancels a running task by ID.
+	CancelTask(ctx context.Context, taskID string) error
 
 	// Ping pings the OpenSearch cluster to check its availability.
 	Ping(ctx context.Context) (*opensearchapi.Response, error)
 }
 
 type openSearchClient struct {
-	client *opensearch.Client
+	client   *opensearch.Client
+	log      logger.Logger
+	logLevel logger.Level
+	tracer   trace.Tracer
+	metrics  *OpenSearchMetrics
+	slow     slowLog
 }
 
 // NewOpenSearchClient creates a new OpenSearchClient instance.
 func NewOpenSearchClient(config *OSConfig) (OpenSearchClient, error) {
-	client, err := opensearch.NewClient(opensearch.Config{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}, // #nosec G402
+	tlsConfig, err := buildTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	osConfig := opensearch.Config{
+		Transport: &headerTransport{
+			next: &http.Transport{
+				TLSClientConfig:     tlsConfig,
+				MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+			},
 		},
-		Addresses: config.Addresses,
-		Username:  config.Username,
-		Password:  config.Password,
-	})
+		Addresses:           config.Addresses,
+		Username:            config.Username,
+		Password:            config.Password,
+		Header:              config.Header,
+		CompressRequestBody: config.EnableCompression,
+
+		DiscoverNodesOnStart:  config.DiscoverNodesOnStart,
+		DiscoverNodesInterval: config.DiscoverNodesInterval,
+		MaxRetries:            config.MaxRetries,
+		RetryOnStatus:         config.RetryOnStatus,
+		DisableRetry:          config.DisableRetry,
+		EnableRetryOnTimeout:  config.EnableRetryOnTimeout,
+		RetryBackoff:          config.RetryBackoff,
+		Selector:              config.Selector,
+		ConnectionPoolFunc:    config.ConnectionPoolFunc,
+	}
+
+	if config.AWSSigV4 != nil {
+		signer, err := newAWSSigV4Signer(context.Background(), config.AWSSigV4)
+		if err != nil {
+			return nil, err
+		}
+		osConfig.Signer = signer
+		osConfig.Username = ""
+		osConfig.Password = ""
+	}
+
+	client, err := opensearch.NewClient(osConfig)
+	log := logger.WithLevel(config.Logger, config.LogLevel)
 	platigoOSClient := &openSearchClient{
-		client: client,
+		client:   client,
+		log:      log,
+		logLevel: config.LogLevel,
+		tracer:   newTracer(config.TracerProvider),
+		metrics:  config.Metrics,
+		slow:     newSlowLog(log, config.SlowThreshold),
+	}
+	if err == nil && config.Health != nil {
+		name := config.HealthName
+		if name == "" {
+			name = "opensearch"
+		}
+		config.Health.Register(name, OpenSearchPingCheck(platigoOSClient))
 	}
 
 	return platigoOSClient, err
 }
 
-func (k *openSearchClient) CreateIndices(ctx context.Context, indexName string, body *strings.Reader) (*opensearchapi.Response, error) {
-	logger := logrus.WithFields(logrus.Fields{
+func (k *openSearchClient) CreateIndices(ctx context.Context, indexName string, body io.Reader) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "create_indices", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
 		"indexName": indexName,
 	})
 	req := opensearchapi.IndicesCreateRequest{
@@ -75,19 +273,22 @@ func (k *openSearchClient) CreateIndices(ctx context.Context, indexName string,
 		Body:  body,
 	}
 
-	res, err := req.Do(ctx, k.client)
+	res, err = req.Do(ctx, k.client)
 	if err != nil {
-		logger.Error(err.Error())
+		log.Error(err.Error())
 		return nil, err
 	}
 
-	logger.Info(res)
+	k.logResponse(log, res)
 
 	return res, err
 }
 
-func (k *openSearchClient) PutIndicesMapping(ctx context.Context, indexNames []string, body *strings.Reader) (*opensearchapi.Response, error) {
-	logger := logrus.WithFields(logrus.Fields{
+func (k *openSearchClient) PutIndicesMapping(ctx context.Context, indexNames []string, body io.Reader) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "put_indices_mapping", indexNames)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
 		"indexNames": indexNames,
 	})
 
@@ -96,93 +297,257 @@ func (k *openSearchClient) PutIndicesMapping(ctx context.Context, indexNames []s
 		Body:  body,
 	}
 
-	res, err := req.Do(ctx, k.client)
+	res, err = req.Do(ctx, k.client)
 	if err != nil {
-		logger.Error(err.Error())
+		log.Error(err.Error())
 		return nil, err
 	}
 
-	logger.Info(res)
+	k.logResponse(log, res)
 
 	return res, nil
 
 }
 
-func (k *openSearchClient) Index(ctx context.Context, indexName string, model IndexModel) (*opensearchapi.Response, error) {
-	logger := logrus.WithFields(logrus.Fields{
+func (k *openSearchClient) Index(ctx context.Context, indexName string, model IndexModel, opts ...RequestOption) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "index", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
 		"indexName": indexName,
 		"docID":     model.GetID(),
 	})
 
 	docData, err := json.Marshal(model)
 	if err != nil {
-		logger.Error(err.Error())
+		log.Error(err.Error())
 		return nil, err
 	}
 
 	body := strings.NewReader(string(docData))
+	options := newRequestOptions(opts)
+	version, versionType := options.documentVersion(model)
 
 	req := opensearchapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: model.GetID(),
-		Body:       body,
-		Pretty:     true,
+		Index:         indexName,
+		DocumentID:    model.GetID(),
+		Body:          body,
+		Routing:       options.routing,
+		Refresh:       options.refresh,
+		IfSeqNo:       options.ifSeqNo,
+		IfPrimaryTerm: options.ifPrimaryTerm,
+		Version:       toIntPtr(version),
+		VersionType:   versionType,
+		Pretty:        true,
 	}
 
-	res, err := req.Do(ctx, k.client)
+	res, err = req.Do(ctx, k.client)
 	if err != nil {
-		logger.Error(err.Error())
+		log.Error(err.Error())
 		return nil, err
 	}
 
-	logger.Info(res)
+	if err = conflictError(res); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	k.logResponse(log, res)
 
 	return res, nil
 }
 
-func (k *openSearchClient) Search(ctx context.Context, indexNames []string, body *strings.Reader) (*opensearchapi.Response, error) {
-	logger := logrus.WithFields(logrus.Fields{
+func (k *openSearchClient) Search(ctx context.Context, indexNames []string, body io.Reader, opts ...RequestOption) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "search", indexNames)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
 		"indexNames": indexNames,
 	})
 
+	options := newRequestOptions(opts)
+
 	req := opensearchapi.SearchRequest{
-		Index:  indexNames,
-		Body:   body,
-		Pretty: true,
+		Index:   indexNames,
+		Body:    body,
+		Routing: options.searchRouting(),
+		Pretty:  true,
+
+		Timeout:                   options.searchTimeout,
+		TerminateAfter:            options.terminateAfter,
+		AllowPartialSearchResults: options.allowPartialSearchResults,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	k.logResponse(log, res)
+
+	return res, nil
+}
+
+func (k *openSearchClient) Get(ctx context.Context, indexName string, docID string, opts ...RequestOption) (result *GetResult, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "get", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexName": indexName,
+		"docID":     docID,
+	})
+
+	options := newRequestOptions(opts)
+
+	req := opensearchapi.GetRequest{
+		Index:      indexName,
+		DocumentID: docID,
+		Routing:    options.routing,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		err = newResponseError(res)
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	result = &GetResult{}
+	if err = json.NewDecoder(res.Body).Decode(result); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (k *openSearchClient) Update(ctx context.Context, indexName string, docID string, doc any, opts ...RequestOption) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "update", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexName": indexName,
+		"docID":     docID,
+	})
+
+	body, err := json.Marshal(map[string]any{"doc": doc})
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	options := newRequestOptions(opts)
+
+	req := opensearchapi.UpdateRequest{
+		Index:         indexName,
+		DocumentID:    docID,
+		Body:          strings.NewReader(string(body)),
+		Routing:       options.routing,
+		Refresh:       options.refresh,
+		IfSeqNo:       options.ifSeqNo,
+		IfPrimaryTerm: options.ifPrimaryTerm,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if err = conflictError(res); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	k.logResponse(log, res)
+
+	return res, nil
+}
+
+func (k *openSearchClient) Delete(ctx context.Context, indexName string, docID string, opts ...RequestOption) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "delete", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexName": indexName,
+		"docID":     docID,
+	})
+
+	options := newRequestOptions(opts)
+
+	req := opensearchapi.DeleteRequest{
+		Index:         indexName,
+		DocumentID:    docID,
+		Routing:       options.routing,
+		Refresh:       options.refresh,
+		IfSeqNo:       options.ifSeqNo,
+		IfPrimaryTerm: options.ifPrimaryTerm,
 	}
 
-	res, err := req.Do(ctx, k.client)
+	res, err = req.Do(ctx, k.client)
 	if err != nil {
-		logger.Error(err.Error())
+		log.Error(err.Error())
 		return nil, err
 	}
 
-	logger.Info(res)
+	if err = conflictError(res); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	k.logResponse(log, res)
 
 	return res, nil
 }
 
-func (k *openSearchClient) BulkIndex(ctx context.Context, indexName string, models []IndexModel) error {
-	logger := logrus.WithFields(logrus.Fields{
+func (k *openSearchClient) BulkIndex(ctx context.Context, indexName string, models []IndexModel, opts ...RequestOption) (stats *BulkStats, err error) {
+	ctx, op := k.startOperation(ctx, "bulk_index", []string{indexName}, attrDocCount.Int(len(models)))
+	defer func() { op.end(nil, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
 		"indexName": indexName,
 	})
 
+	options := newRequestOptions(opts)
+
 	bulkIndexer, err := opensearchutil.NewBulkIndexer(opensearchutil.BulkIndexerConfig{
 		Index:      indexName,
 		Client:     k.client,
 		NumWorkers: 10,
+		Routing:    options.routing,
+		Refresh:    options.refresh,
 	})
 
 	if err != nil {
-		logger.Errorf("Failed to create bulk indexer: %s", err)
-		return err
+		log.Error(fmt.Sprintf("Failed to create bulk indexer: %s", err))
+		return nil, err
+	}
+
+	started := time.Now()
+	var numSkipped uint64
+	// queued counts the documents of the call still in the queue gauge.
+	// The items of a failed flush get no callback, so what is left is
+	// removed once the indexer is closed.
+	var queued atomic.Int64
+	dequeue := func() {
+		queued.Add(-1)
+		k.metrics.addBulkQueued(-1)
 	}
 
 	for _, model := range models {
 		docID := model.GetID()
 		jsonData, err := json.Marshal(model)
 		if err != nil {
-			logger.Error(err)
+			log.Error(err.Error())
+			numSkipped++
 			continue
 		}
 
@@ -190,35 +555,81 @@ func (k *openSearchClient) BulkIndex(ctx context.Context, indexName string, mode
 			Action:     "index",
 			DocumentID: docID,
 			Body:       strings.NewReader(string(jsonData)),
+			OnSuccess: func(context.Context, opensearchutil.BulkIndexerItem, opensearchutil.BulkIndexerResponseItem) {
+				dequeue()
+			},
+			OnFailure: func(context.Context, opensearchutil.BulkIndexerItem, opensearchutil.BulkIndexerResponseItem, error) {
+				dequeue()
+			},
+		}
+		if version, versionType := options.modelVersion(model); version != nil {
+			item.Version = version
+			item.VersionType = &versionType
 		}
 
+		queued.Add(1)
+		k.metrics.addBulkQueued(1)
 		err = bulkIndexer.Add(ctx, item)
 		if err != nil {
-			logger.Errorf("Failed to add document ID %s to bulk indexer: %s", docID, err)
+			dequeue()
+			log.Error(fmt.Sprintf("Failed to add document ID %s to bulk indexer: %s", docID, err))
+			numSkipped++
 		}
 	}
 
 	err = bulkIndexer.Close(ctx)
+	k.metrics.addBulkQueued(-float64(queued.Swap(0)))
+	stats = newBulkStats(bulkIndexer.Stats(), numSkipped, time.Since(started))
 	if err != nil {
-		logger.Errorf("Failed to close bulk indexer: %s", err)
-		return err
+		log.Error(fmt.Sprintf("Failed to close bulk indexer: %s", err))
+		return stats, err
 	}
 
-	stat := bulkIndexer.Stats()
-	logger.Info("Bulk Indexer Stat: ", utils.Dump(stat))
-
-	return nil
+	log.Info("Bulk Indexer Stat: " + utils.Dump(stats))
 
+	return stats, nil
 }
 
-func (k *openSearchClient) Ping(ctx context.Context) (*opensearchapi.Response, error) {
+func (k *openSearchClient) Ping(ctx context.Context) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "ping", nil)
+	defer func() { op.end(res, err) }()
+
 	req := opensearchapi.PingRequest{}
 
-	res, err := req.Do(ctx, k.client)
+	res, err = req.Do(ctx, k.client)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to ping OpenSearch cluster")
+		logger.WithContext(ctx, k.log).With(logger.Fields{"error": err.Error()}).Error("Failed to ping OpenSearch cluster")
 		return nil, err
 	}
 
 	return res, nil
 }
+
+// logResponse logs the full response at debug level. The body is only
+// rendered when debug logging is enabled since it has to be buffered.
+func (k *openSearchClient) logResponse(log logger.Logger, res *opensearchapi.Response) {
+	if k.logLevel > logger.DebugLevel {
+		return
+	}
+	log.Debug(res.String())
+}
+
+func toIntPtr(v *int64) *int {
+	if v == nil {
+		return nil
+	}
+	i := int(*v)
+	return &i
+}
+
+// decodeResponse decodes the body of a successful response into v and closes
+// it. Error responses are returned as a *ResponseError.
+func decodeResponse(res *opensearchapi.Response, v any) error {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return newResponseError(res)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
@@ -0,0 +1,88 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientAWSSigV4(t *testing.T) {
+	var (
+		gotAuth string
+		gotHash string
+		gotBody string
+	)
+
+	client := newTestClient(t, &OSConfig{
+		Username: "ignored",
+		Password: "ignored",
+		AWSSigV4: &AWSSigV4Config{
+			Region:      "ap-southeast-1",
+			Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth = r.Header.Get("Authorization")
+		gotHash = r.Header.Get("X-Amz-Content-Sha256")
+		gotBody = string(body)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, err := client.Search(context.Background(), []string{"products"}, strings.NewReader(`{"query":{}}`))
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, gotAuth, "/ap-southeast-1/es/aws4_request")
+	assert.NotEmpty(t, gotHash)
+	assert.Equal(t, `{"query":{}}`, gotBody)
+}
+
+func TestNewAWSSigV4Signer(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *AWSSigV4Config
+		wantService string
+		wantErr     error
+	}{
+		{
+			name:    "missing region",
+			config:  &AWSSigV4Config{},
+			wantErr: errAWSRegionRequired,
+		},
+		{
+			name: "default service",
+			config: &AWSSigV4Config{
+				Region:      "us-east-1",
+				Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			},
+			wantService: AWSServiceOpenSearch,
+		},
+		{
+			name: "serverless",
+			config: &AWSSigV4Config{
+				Region:      "us-east-1",
+				Service:     AWSServiceOpenSearchServerless,
+				Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			},
+			wantService: AWSServiceOpenSearchServerless,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := newAWSSigV4Signer(context.Background(), tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantService, signer.service)
+		})
+	}
+}
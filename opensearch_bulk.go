@@ -0,0 +1,40 @@
+package platigo
+
+import (
+	"time"
+
+	"github.com/opensearch-project/opensearch-go/opensearchutil"
+)
+
+// BulkStats summarizes a BulkIndex call.
+type BulkStats struct {
+	// NumAdded is the number of documents queued to the bulk indexer.
+	NumAdded uint64 `json:"num_added"`
+	// NumFlushed is the number of documents sent and acknowledged.
+	NumFlushed uint64 `json:"num_flushed"`
+	// NumFailed counts documents rejected by the cluster as well as the ones
+	// that could not be encoded or queued.
+	NumFailed   uint64 `json:"num_failed"`
+	NumIndexed  uint64 `json:"num_indexed"`
+	NumCreated  uint64 `json:"num_created"`
+	NumUpdated  uint64 `json:"num_updated"`
+	NumDeleted  uint64 `json:"num_deleted"`
+	NumRequests uint64 `json:"num_requests"`
+	// Duration is the wall time from the first document queued to the last
+	// flush.
+	Duration time.Duration `json:"duration"`
+}
+
+func newBulkStats(stats opensearchutil.BulkIndexerStats, numSkipped uint64, elapsed time.Duration) *BulkStats {
+	return &BulkStats{
+		NumAdded:    stats.NumAdded,
+		NumFlushed:  stats.NumFlushed,
+		NumFailed:   stats.NumFailed + numSkipped,
+		NumIndexed:  stats.NumIndexed,
+		NumCreated:  stats.NumCreated,
+		NumUpdated:  stats.NumUpdated,
+		NumDeleted:  stats.NumDeleted,
+		NumRequests: stats.NumRequests,
+		Duration:    elapsed,
+	}
+}
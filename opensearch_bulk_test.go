@@ -0,0 +1,43 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type unencodableDoc struct {
+	testDoc
+}
+
+func (unencodableDoc) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("cannot encode")
+}
+
+func TestOpenSearchClientBulkIndexStats(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(bulkResponse(b)))
+	})
+
+	models := []IndexModel{
+		testDoc{ID: "1"},
+		testDoc{ID: "2"},
+		unencodableDoc{testDoc{ID: "3"}},
+	}
+
+	stats, err := client.BulkIndex(context.Background(), "docs", models)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), stats.NumAdded)
+	assert.Equal(t, uint64(2), stats.NumFlushed)
+	assert.Equal(t, uint64(1), stats.NumFailed)
+	assert.Equal(t, uint64(2), stats.NumIndexed)
+	assert.NotZero(t, stats.NumRequests)
+	assert.NotZero(t, stats.Duration)
+}
@@ -0,0 +1,232 @@
+package platigo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+var (
+	catIndicesColumns = []string{"health", "status", "index", "uuid", "pri", "rep", "docs.count", "docs.deleted", "store.size", "pri.store.size"}
+	catShardsColumns  = []string{"index", "shard", "prirep", "state", "docs", "store", "ip", "node"}
+	catNodesColumns   = []string{"name", "ip", "heap.percent", "ram.percent", "cpu", "load_1m", "node.role", "disk.used_percent"}
+)
+
+// CatIndex is a row of _cat/indices. Sizes are in bytes.
+type CatIndex struct {
+	Health       string
+	Status       string
+	Index        string
+	UUID         string
+	Primaries    int
+	Replicas     int
+	DocsCount    int64
+	DocsDeleted  int64
+	StoreSize    int64
+	PriStoreSize int64
+}
+
+// CatShard is a row of _cat/shards. Store is in bytes.
+type CatShard struct {
+	Index   string
+	Shard   int
+	Primary bool
+	State   string
+	Docs    int64
+	Store   int64
+	IP      string
+	Node    string
+}
+
+// CatNode is a row of _cat/nodes.
+type CatNode struct {
+	Name            string
+	IP              string
+	HeapPercent     int
+	RAMPercent      int
+	CPU             int
+	Load1m          float64
+	Roles           string
+	DiskUsedPercent float64
+}
+
+// The _cat API returns every value as a string, null for closed indices.
+type catIndexRow struct {
+	Health       string `json:"health"`
+	Status       string `json:"status"`
+	Index        string `json:"index"`
+	UUID         string `json:"uuid"`
+	Pri          string `json:"pri"`
+	Rep          string `json:"rep"`
+	DocsCount    string `json:"docs.count"`
+	DocsDeleted  string `json:"docs.deleted"`
+	StoreSize    string `json:"store.size"`
+	PriStoreSize string `json:"pri.store.size"`
+}
+
+type catShardRow struct {
+	Index  string `json:"index"`
+	Shard  string `json:"shard"`
+	PriRep string `json:"prirep"`
+	State  string `json:"state"`
+	Docs   string `json:"docs"`
+	Store  string `json:"store"`
+	IP     string `json:"ip"`
+	Node   string `json:"node"`
+}
+
+type catNodeRow struct {
+	Name            string `json:"name"`
+	IP              string `json:"ip"`
+	HeapPercent     string `json:"heap.percent"`
+	RAMPercent      string `json:"ram.percent"`
+	CPU             string `json:"cpu"`
+	Load1m          string `json:"load_1m"`
+	NodeRole        string `json:"node.role"`
+	DiskUsedPercent string `json:"disk.used_percent"`
+}
+
+func (k *openSearchClient) CatIndices(ctx context.Context, indexNames ...string) (indices []CatIndex, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "cat_indices", indexNames)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexNames": indexNames,
+	})
+
+	req := opensearchapi.CatIndicesRequest{
+		Index:  indexNames,
+		Format: "json",
+		Bytes:  "b",
+		H:      catIndicesColumns,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var rows []catIndexRow
+	if err = decodeResponse(res, &rows); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	indices = make([]CatIndex, 0, len(rows))
+	for _, row := range rows {
+		indices = append(indices, CatIndex{
+			Health:       row.Health,
+			Status:       row.Status,
+			Index:        row.Index,
+			UUID:         row.UUID,
+			Primaries:    int(parseCatInt(row.Pri)),
+			Replicas:     int(parseCatInt(row.Rep)),
+			DocsCount:    parseCatInt(row.DocsCount),
+			DocsDeleted:  parseCatInt(row.DocsDeleted),
+			StoreSize:    parseCatInt(row.StoreSize),
+			PriStoreSize: parseCatInt(row.PriStoreSize),
+		})
+	}
+
+	return indices, nil
+}
+
+func (k *openSearchClient) CatShards(ctx context.Context, indexNames ...string) (shards []CatShard, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "cat_shards", indexNames)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexNames": indexNames,
+	})
+
+	req := opensearchapi.CatShardsRequest{
+		Index:  indexNames,
+		Format: "json",
+		Bytes:  "b",
+		H:      catShardsColumns,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var rows []catShardRow
+	if err = decodeResponse(res, &rows); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	shards = make([]CatShard, 0, len(rows))
+	for _, row := range rows {
+		shards = append(shards, CatShard{
+			Index:   row.Index,
+			Shard:   int(parseCatInt(row.Shard)),
+			Primary: row.PriRep == "p",
+			State:   row.State,
+			Docs:    parseCatInt(row.Docs),
+			Store:   parseCatInt(row.Store),
+			IP:      row.IP,
+			Node:    row.Node,
+		})
+	}
+
+	return shards, nil
+}
+
+func (k *openSearchClient) CatNodes(ctx context.Context) (nodes []CatNode, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "cat_nodes", nil)
+	defer func() { op.end(res, err) }()
+
+	req := opensearchapi.CatNodesRequest{
+		Format: "json",
+		H:      catNodesColumns,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		logger.WithContext(ctx, k.log).Error(err.Error())
+		return nil, err
+	}
+
+	var rows []catNodeRow
+	if err = decodeResponse(res, &rows); err != nil {
+		logger.WithContext(ctx, k.log).Error(err.Error())
+		return nil, err
+	}
+
+	nodes = make([]CatNode, 0, len(rows))
+	for _, row := range rows {
+		nodes = append(nodes, CatNode{
+			Name:            row.Name,
+			IP:              row.IP,
+			HeapPercent:     int(parseCatInt(row.HeapPercent)),
+			RAMPercent:      int(parseCatInt(row.RAMPercent)),
+			CPU:             int(parseCatInt(row.CPU)),
+			Load1m:          parseCatFloat(row.Load1m),
+			Roles:           row.NodeRole,
+			DiskUsedPercent: parseCatFloat(row.DiskUsedPercent),
+		})
+	}
+
+	return nodes, nil
+}
+
+// parseCatInt parses a _cat value, returning 0 for missing values.
+func parseCatInt(value string) int64 {
+	i, _ := strconv.ParseInt(value, 10, 64)
+	return i
+}
+
+// parseCatFloat parses a _cat value, returning 0 for missing values.
+func parseCatFloat(value string) float64 {
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
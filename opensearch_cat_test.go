@@ -0,0 +1,77 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientCatIndices(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/indices/logs-*", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "b", r.URL.Query().Get("bytes"))
+		_, _ = w.Write([]byte(`[
+			{"health":"green","status":"open","index":"logs-1","uuid":"u1","pri":"3","rep":"1","docs.count":"1200","docs.deleted":"4","store.size":"20480","pri.store.size":"10240"},
+			{"health":null,"status":"close","index":"logs-0","uuid":"u0","pri":"1","rep":"1","docs.count":null,"docs.deleted":null,"store.size":null,"pri.store.size":null}
+		]`))
+	})
+
+	got, err := client.CatIndices(context.Background(), "logs-*")
+	require.NoError(t, err)
+
+	assert.Equal(t, []CatIndex{
+		{Health: "green", Status: "open", Index: "logs-1", UUID: "u1", Primaries: 3, Replicas: 1, DocsCount: 1200, DocsDeleted: 4, StoreSize: 20480, PriStoreSize: 10240},
+		{Status: "close", Index: "logs-0", UUID: "u0", Primaries: 1, Replicas: 1},
+	}, got)
+}
+
+func TestOpenSearchClientCatShards(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_cat/shards", r.URL.Path)
+		_, _ = w.Write([]byte(`[
+			{"index":"logs-1","shard":"0","prirep":"p","state":"STARTED","docs":"600","store":"5120","ip":"10.0.0.1","node":"node-1"},
+			{"index":"logs-1","shard":"0","prirep":"r","state":"UNASSIGNED","docs":null,"store":null,"ip":null,"node":null}
+		]`))
+	})
+
+	got, err := client.CatShards(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []CatShard{
+		{Index: "logs-1", Shard: 0, Primary: true, State: "STARTED", Docs: 600, Store: 5120, IP: "10.0.0.1", Node: "node-1"},
+		{Index: "logs-1", Shard: 0, Primary: false, State: "UNASSIGNED"},
+	}, got)
+}
+
+func TestOpenSearchClientCatNodes(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`[{"name":"node-1","ip":"10.0.0.1","heap.percent":"41","ram.percent":"87","cpu":"5","load_1m":"0.42","node.role":"dimr","disk.used_percent":"61.5"}]`))
+		})
+
+		got, err := client.CatNodes(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []CatNode{
+			{Name: "node-1", IP: "10.0.0.1", HeapPercent: 41, RAMPercent: 87, CPU: 5, Load1m: 0.42, Roles: "dimr", DiskUsedPercent: 61.5},
+		}, got)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"type":"security_exception","reason":"no permissions"}}`))
+		})
+
+		got, err := client.CatNodes(context.Background())
+		assert.Nil(t, got)
+
+		var resErr *ResponseError
+		require.ErrorAs(t, err, &resErr)
+		assert.Equal(t, http.StatusForbidden, resErr.StatusCode)
+	})
+}
@@ -0,0 +1,41 @@
+package platigo
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientCompression(t *testing.T) {
+	var gotBody, gotContentEncoding string
+	client := newTestClient(t, &OSConfig{EnableCompression: true}, func(w http.ResponseWriter, r *http.Request) {
+		gotContentEncoding = r.Header.Get("Content-Encoding")
+
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		b, _ := io.ReadAll(zr)
+		gotBody = string(b)
+
+		require.Contains(t, r.Header.Get("Accept-Encoding"), "gzip")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		_, _ = zw.Write([]byte(`{"hits":{"total":{"value":0},"hits":[]}}`))
+		_ = zw.Close()
+	})
+
+	res, err := client.Search(context.Background(), []string{"docs"}, strings.NewReader(`{"query":{"match_all":{}}}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "gzip", gotContentEncoding)
+	assert.Equal(t, `{"query":{"match_all":{}}}`, gotBody)
+
+	result, err := ParseSearchResult(res)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.Total)
+}
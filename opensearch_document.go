@@ -0,0 +1,20 @@
+package platigo
+
+import "github.com/goccy/go-json"
+
+// GetResult is a document returned by Get.
+type GetResult struct {
+	Index       string          `json:"_index"`
+	ID          string          `json:"_id"`
+	Version     int64           `json:"_version"`
+	SeqNo       int64           `json:"_seq_no"`
+	PrimaryTerm int64           `json:"_primary_term"`
+	Routing     string          `json:"_routing,omitempty"`
+	Found       bool            `json:"found"`
+	Source      json.RawMessage `json:"_source"`
+}
+
+// Decode unmarshals the document source into v.
+func (r *GetResult) Decode(v any) error {
+	return json.Unmarshal(r.Source, v)
+}
@@ -0,0 +1,146 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const versionConflictBody = `{"error":{"type":"version_conflict_engine_exception","reason":"[1]: version conflict"},"status":409}`
+
+func TestOpenSearchClientGet(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/docs/_doc/1", r.URL.Path)
+			_, _ = w.Write([]byte(`{"_index":"docs","_id":"1","_version":3,"_seq_no":7,"_primary_term":2,"found":true,"_source":{"id":"1","title":"hello"}}`))
+		})
+
+		got, err := client.Get(context.Background(), "docs", "1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), got.SeqNo)
+		assert.Equal(t, int64(2), got.PrimaryTerm)
+
+		var doc testDoc
+		require.NoError(t, got.Decode(&doc))
+		assert.Equal(t, "hello", doc.Title)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"_index":"docs","_id":"1","found":false}`))
+		})
+
+		got, err := client.Get(context.Background(), "docs", "1")
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+	})
+}
+
+func TestWithIfSeqNo(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(client *openSearchClient, opt RequestOption) error
+	}{
+		{
+			name: "index",
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.Index(context.Background(), "docs", testDoc{ID: "1"}, opt)
+				return err
+			},
+		},
+		{
+			name: "update",
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.Update(context.Background(), "docs", "1", map[string]any{"title": "new"}, opt)
+				return err
+			},
+		},
+		{
+			name: "delete",
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.Delete(context.Background(), "docs", "1", opt)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" conflict", func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "7", r.URL.Query().Get("if_seq_no"))
+				assert.Equal(t, "2", r.URL.Query().Get("if_primary_term"))
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(versionConflictBody))
+			})
+
+			err := tt.call(client, WithIfSeqNo(7, 2))
+			assert.ErrorIs(t, err, ErrVersionConflict)
+			assert.ErrorIs(t, err, ErrStaleObject)
+
+			var resErr *ResponseError
+			require.True(t, errors.As(err, &resErr))
+			assert.Equal(t, "version_conflict_engine_exception", resErr.Type)
+		})
+
+		t.Run(tt.name+" success", func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"result":"updated"}`))
+			})
+
+			assert.NoError(t, tt.call(client, WithIfSeqNo(7, 2)))
+		})
+	}
+}
+
+func TestNewResponseError(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantType   string
+		wantReason string
+	}{
+		{
+			name:       "structured error",
+			status:     http.StatusConflict,
+			body:       versionConflictBody,
+			wantType:   "version_conflict_engine_exception",
+			wantReason: "[1]: version conflict",
+		},
+		{
+			name:       "string error",
+			status:     http.StatusBadRequest,
+			body:       `{"error":"bad request"}`,
+			wantReason: "bad request",
+		},
+		{
+			name:       "no error body",
+			status:     http.StatusNotFound,
+			body:       `{"found":false}`,
+			wantReason: "Not Found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			res, err := client.Search(context.Background(), []string{"docs"}, strings.NewReader(`{}`))
+			require.NoError(t, err)
+
+			got := newResponseError(res)
+			assert.Equal(t, tt.status, got.StatusCode)
+			assert.Equal(t, tt.wantType, got.Type)
+			assert.Equal(t, tt.wantReason, got.Reason)
+		})
+	}
+}
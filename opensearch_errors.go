@@ -0,0 +1,99 @@
+package platigo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+var (
+	// ErrVersionConflict is matched by errors.Is when a write was rejected
+	// because the document changed since it was read. These errors also
+	// match ErrStaleObject, like the SQL optimistic locking conflicts.
+	ErrVersionConflict = errors.New("opensearch: version conflict")
+	// ErrDocumentNotFound is matched by errors.Is when the document does not exist.
+	ErrDocumentNotFound = errors.New("opensearch: document not found")
+)
+
+// ResponseError is an error response returned by OpenSearch.
+type ResponseError struct {
+	StatusCode int
+	Type       string
+	Reason     string
+}
+
+func (e *ResponseError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("opensearch: [%d] %s", e.StatusCode, e.Reason)
+	}
+	return fmt.Sprintf("opensearch: [%d] %s: %s", e.StatusCode, e.Type, e.Reason)
+}
+
+// Is reports whether the error matches one of the sentinel errors.
+func (e *ResponseError) Is(target error) bool {
+	switch target {
+	case ErrVersionConflict, ErrStaleObject:
+		return e.StatusCode == http.StatusConflict
+	case ErrDocumentNotFound:
+		return e.StatusCode == http.StatusNotFound
+	default:
+		return false
+	}
+}
+
+// newResponseError builds a ResponseError from an error response. The body
+// is consumed.
+func newResponseError(res *opensearchapi.Response) *ResponseError {
+	resErr := &ResponseError{
+		StatusCode: res.StatusCode,
+		Reason:     http.StatusText(res.StatusCode),
+	}
+	if res.Body == nil {
+		return resErr
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return resErr
+	}
+
+	var payload struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) != nil || len(payload.Error) == 0 {
+		return resErr
+	}
+
+	var cause struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	}
+	if json.Unmarshal(payload.Error, &cause) == nil {
+		resErr.Type = cause.Type
+		resErr.Reason = cause.Reason
+		return resErr
+	}
+
+	// Some errors are returned as a plain string.
+	var reason string
+	if json.Unmarshal(payload.Error, &reason) == nil {
+		resErr.Reason = reason
+	}
+
+	return resErr
+}
+
+// conflictError returns a ResponseError when a write was rejected with a
+// version conflict, so callers do not have to inspect the status themselves.
+func conflictError(res *opensearchapi.Response) error {
+	if res.StatusCode != http.StatusConflict {
+		return nil
+	}
+	defer res.Body.Close()
+
+	return newResponseError(res)
+}
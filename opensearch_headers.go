@@ -0,0 +1,76 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+)
+
+// HeaderOpaqueID is the header OpenSearch copies into its slow logs and task
+// list, correlating cluster side entries with the caller.
+const HeaderOpaqueID = "X-Opaque-Id"
+
+type contextKey int
+
+const (
+	opaqueIDContextKey contextKey = iota
+	headersContextKey
+	requestIDContextKey
+)
+
+// ContextWithOpaqueID returns a context whose OpenSearch calls carry id in the
+// X-Opaque-Id header.
+func ContextWithOpaqueID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, opaqueIDContextKey, id)
+}
+
+// OpaqueIDFromContext returns the opaque ID set with ContextWithOpaqueID,
+// or else the request ID of ctx.
+func OpaqueIDFromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(opaqueIDContextKey).(string); ok {
+		return id
+	}
+	return RequestIDFromContext(ctx)
+}
+
+// ContextWithHeaders returns a context whose OpenSearch calls carry header.
+// Headers already set on ctx are kept unless overridden.
+func ContextWithHeaders(ctx context.Context, header http.Header) context.Context {
+	merged := headersFromContext(ctx).Clone()
+	if merged == nil {
+		merged = http.Header{}
+	}
+	for key, values := range header {
+		merged[http.CanonicalHeaderKey(key)] = values
+	}
+	return context.WithValue(ctx, headersContextKey, merged)
+}
+
+func headersFromContext(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headersContextKey).(http.Header)
+	return header
+}
+
+// headerTransport adds the headers carried by the request context.
+type headerTransport struct {
+	next http.RoundTripper
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	header := headersFromContext(ctx)
+	opaqueID := OpaqueIDFromContext(ctx)
+
+	if len(header) == 0 && opaqueID == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	req = req.Clone(ctx)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if opaqueID != "" {
+		req.Header.Set(HeaderOpaqueID, opaqueID)
+	}
+
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,52 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientContextHeaders(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, &OSConfig{
+		Header: http.Header{"X-Service": []string{"catalog"}},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errors":false,"items":[{"index":{"status":201}}]}`))
+	})
+
+	ctx := ContextWithOpaqueID(context.Background(), "req-123")
+	ctx = ContextWithHeaders(ctx, http.Header{"x-tenant": []string{"a"}})
+	ctx = ContextWithHeaders(ctx, http.Header{"X-Feature": []string{"search-v2"}})
+
+	calls := []func() error{
+		func() error {
+			_, err := client.Search(ctx, []string{"docs"}, strings.NewReader(`{}`))
+			return err
+		},
+		func() error {
+			_, err := client.BulkIndex(ctx, "docs", []IndexModel{testDoc{ID: "1"}})
+			return err
+		},
+	}
+
+	for _, call := range calls {
+		got = nil
+		require.NoError(t, call())
+
+		assert.Equal(t, "req-123", got.Get(HeaderOpaqueID))
+		assert.Equal(t, "a", got.Get("X-Tenant"))
+		assert.Equal(t, "search-v2", got.Get("X-Feature"))
+		assert.Equal(t, "catalog", got.Get("X-Service"))
+	}
+}
+
+func TestOpaqueIDFromContext(t *testing.T) {
+	assert.Empty(t, OpaqueIDFromContext(context.Background()))
+	assert.Equal(t, "id", OpaqueIDFromContext(ContextWithOpaqueID(context.Background(), "id")))
+}
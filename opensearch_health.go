@@ -0,0 +1,27 @@
+package platigo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bagastri07/platigo/health"
+)
+
+// OpenSearchPingCheck returns a check pinging the cluster of client,
+// reporting its latency.
+func OpenSearchPingCheck(client OpenSearchClient) health.Check {
+	return func(ctx context.Context) health.Result {
+		started := time.Now()
+		res, err := client.Ping(ctx)
+		details := map[string]any{"latency_ms": time.Since(started).Milliseconds()}
+		if err != nil {
+			return health.Down(err, details)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			return health.Down(fmt.Errorf("opensearch: ping: %s", res.Status()), details)
+		}
+		return health.Up(details)
+	}
+}
@@ -0,0 +1,34 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/bagastri07/platigo/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenSearchPingCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   health.Status
+	}{
+		{name: "up", status: http.StatusOK, want: health.StatusUp},
+		{name: "down", status: http.StatusServiceUnavailable, want: health.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodHead, r.Method)
+				w.WriteHeader(tt.status)
+			})
+
+			result := OpenSearchPingCheck(client)(context.Background())
+			assert.Equal(t, tt.want, result.Status)
+			assert.Contains(t, result.Details, "latency_ms")
+		})
+	}
+}
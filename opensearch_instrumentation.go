@@ -0,0 +1,98 @@
+package platigo
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/bagastri07/platigo"
+
+var (
+	attrDBSystem        = attribute.Key("db.system")
+	attrDBOperation     = attribute.Key("db.operation")
+	attrIndexNames      = attribute.Key("opensearch.index")
+	attrDocCount        = attribute.Key("opensearch.doc_count")
+	attrHTTPStatusCode  = attribute.Key("http.status_code")
+	openSearchDBSystem  = attrDBSystem.String("opensearch")
+	defaultSpanStartOpt = trace.WithSpanKind(trace.SpanKindClient)
+)
+
+// newTracer returns the tracer used by the OpenSearch client. A nil provider
+// falls back to the global one, which is a no-op unless configured.
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+// operation tracks a single OpenSearch call for tracing and metrics.
+type operation struct {
+	ctx     context.Context
+	client  *openSearchClient
+	name    string
+	index   string
+	span    trace.Span
+	started time.Time
+}
+
+// startOperation starts the instrumentation of an OpenSearch call. It must be
+// finished with end, usually from a deferred func capturing named results.
+func (k *openSearchClient) startOperation(ctx context.Context, name string, indexNames []string, attrs ...attribute.KeyValue) (context.Context, *operation) {
+	attrs = append(attrs,
+		openSearchDBSystem,
+		attrDBOperation.String(name),
+		attrIndexNames.StringSlice(indexNames),
+	)
+
+	ctx, span := k.tracer.Start(ctx, "opensearch."+name, defaultSpanStartOpt, trace.WithAttributes(attrs...))
+
+	return ctx, &operation{
+		ctx:     ctx,
+		client:  k,
+		name:    name,
+		index:   strings.Join(indexNames, ","),
+		span:    span,
+		started: time.Now(),
+	}
+}
+
+// end records the outcome of the call. A response with an error status
+// counts as a failure as well.
+func (o *operation) end(res *opensearchapi.Response, err error) {
+	defer o.span.End()
+
+	failed := err != nil
+	if res != nil {
+		o.span.SetAttributes(attrHTTPStatusCode.Int(res.StatusCode))
+		if res.IsError() {
+			failed = true
+			o.span.SetStatus(codes.Error, res.Status())
+		}
+	}
+
+	if err != nil {
+		o.span.RecordError(err)
+		o.span.SetStatus(codes.Error, err.Error())
+	}
+
+	elapsed := time.Since(o.started)
+	o.client.metrics.observe(o.name, o.index, elapsed, failed)
+
+	fields := logger.Fields{"operation": o.name, "index": o.index}
+	if res != nil {
+		fields["status"] = res.StatusCode
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	o.client.slow.observe(o.ctx, elapsed, "Slow OpenSearch operation", fields)
+}
@@ -0,0 +1,58 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestOpenSearchClientTracing(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantStatus codes.Code
+	}{
+		{
+			name:       "success",
+			status:     http.StatusOK,
+			wantStatus: codes.Unset,
+		},
+		{
+			name:       "error response",
+			status:     http.StatusBadRequest,
+			wantStatus: codes.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			client := newTestClient(t, &OSConfig{TracerProvider: tp}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{}`))
+			})
+
+			_, err := client.Search(context.Background(), []string{"products"}, strings.NewReader(`{}`))
+			require.NoError(t, err)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+
+			span := spans[0]
+			assert.Equal(t, "opensearch.search", span.Name())
+			assert.Equal(t, tt.wantStatus, span.Status().Code)
+			assert.Contains(t, span.Attributes(), attribute.StringSlice("opensearch.index", []string{"products"}))
+			assert.Contains(t, span.Attributes(), attribute.Int("http.status_code", tt.status))
+		})
+	}
+}
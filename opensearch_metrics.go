@@ -0,0 +1,95 @@
+package platigo
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// OpenSearchMetrics holds the Prometheus metrics of the OpenSearch client.
+// It implements prometheus.Collector, so it can be registered on any
+// registry:
+//
+//	metrics := platigo.NewOpenSearchMetrics("myservice")
+//	prometheus.MustRegister(metrics)
+//	client, err := platigo.NewOpenSearchClient(&platigo.OSConfig{Metrics: metrics})
+type OpenSearchMetrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	// bulkQueue is the number of documents queued by BulkIndex and not
+	// yet acknowledged or failed.
+	bulkQueue prometheus.Gauge
+}
+
+// NewOpenSearchMetrics creates the client metrics under the given namespace.
+func NewOpenSearchMetrics(namespace string) *OpenSearchMetrics {
+	labels := []string{"operation", "index"}
+
+	return &OpenSearchMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "opensearch",
+			Name:      "requests_total",
+			Help:      "Total number of OpenSearch requests.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "opensearch",
+			Name:      "errors_total",
+			Help:      "Total number of failed OpenSearch requests.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "opensearch",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of OpenSearch requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		bulkQueue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "opensearch",
+			Name:      "bulk_queue_depth",
+			Help:      "Number of documents queued by the bulk indexers and not yet flushed.",
+		}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *OpenSearchMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.requests.Describe(ch)
+	m.errors.Describe(ch)
+	m.latency.Describe(ch)
+	m.bulkQueue.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *OpenSearchMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.requests.Collect(ch)
+	m.errors.Collect(ch)
+	m.latency.Collect(ch)
+	m.bulkQueue.Collect(ch)
+}
+
+// observe records one request. It is a no-op on a nil receiver so the client
+// works without metrics configured.
+func (m *OpenSearchMetrics) observe(operation, index string, elapsed time.Duration, failed bool) {
+	if m == nil {
+		return
+	}
+
+	m.requests.WithLabelValues(operation, index).Inc()
+	if failed {
+		m.errors.WithLabelValues(operation, index).Inc()
+	}
+	m.latency.WithLabelValues(operation, index).Observe(elapsed.Seconds())
+}
+
+// addBulkQueued records delta documents entering or leaving the bulk
+// indexer queues. It is a no-op on a nil receiver.
+func (m *OpenSearchMetrics) addBulkQueued(delta float64) {
+	if m == nil {
+		return
+	}
+	m.bulkQueue.Add(delta)
+}
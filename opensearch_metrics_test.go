@@ -0,0 +1,71 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchMetrics(t *testing.T) {
+	metrics := NewOpenSearchMetrics("test")
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	status := http.StatusOK
+	client := newTestClient(t, &OSConfig{Metrics: metrics}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, err := client.Search(context.Background(), []string{"a", "b"}, strings.NewReader(`{}`))
+	require.NoError(t, err)
+
+	status = http.StatusInternalServerError
+	_, err = client.Search(context.Background(), []string{"a", "b"}, strings.NewReader(`{}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.requests.WithLabelValues("search", "a,b")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(metrics.errors.WithLabelValues("search", "a,b")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.latency))
+}
+
+func TestOpenSearchMetricsNil(t *testing.T) {
+	var metrics *OpenSearchMetrics
+	assert.NotPanics(t, func() { metrics.observe("search", "a", 0, true) })
+}
+
+func TestOpenSearchMetricsBulkQueue(t *testing.T) {
+	metrics := NewOpenSearchMetrics("test")
+	var queued atomic.Int64
+	client := newTestClient(t, &OSConfig{Metrics: metrics}, func(w http.ResponseWriter, r *http.Request) {
+		queued.Store(int64(testutil.ToFloat64(metrics.bulkQueue)))
+		b, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(bulkResponse(b)))
+	})
+
+	_, err := client.BulkIndex(context.Background(), "docs", []IndexModel{testDoc{ID: "1"}, testDoc{ID: "2"}})
+	require.NoError(t, err)
+
+	assert.Positive(t, queued.Load())
+	assert.Zero(t, testutil.ToFloat64(metrics.bulkQueue))
+}
+
+func TestOpenSearchMetricsBulkQueueFailedFlush(t *testing.T) {
+	metrics := NewOpenSearchMetrics("test")
+	client := newTestClient(t, &OSConfig{Metrics: metrics, DisableRetry: true}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"error":"boom"}`))
+	})
+
+	_, _ = client.BulkIndex(context.Background(), "docs", []IndexModel{testDoc{ID: "1"}, testDoc{ID: "2"}})
+
+	assert.Zero(t, testutil.ToFloat64(metrics.bulkQueue))
+}
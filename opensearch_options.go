@@ -0,0 +1,165 @@
+package platigo
+
+import "time"
+
+// Refresh policies accepted by WithRefresh.
+const (
+	RefreshTrue    = "true"
+	RefreshWaitFor = "wait_for"
+	RefreshFalse   = "false"
+)
+
+// Version types accepted by WithVersionType.
+const (
+	VersionTypeInternal    = "internal"
+	VersionTypeExternal    = "external"
+	VersionTypeExternalGTE = "external_gte"
+)
+
+// RequestOption customizes a single OpenSearch request. Options that do not
+// apply to an operation are ignored by it.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	routing       string
+	refresh       string
+	ifSeqNo       *int
+	ifPrimaryTerm *int
+	version       *int64
+	versionType   string
+
+	upsert          any
+	retryOnConflict *int
+
+	searchTimeout             time.Duration
+	terminateAfter            *int
+	allowPartialSearchResults *bool
+}
+
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithRouting routes the request to the shard owning the routing key instead
+// of the one derived from the document ID. Searches with a routing key only
+// hit that shard.
+func WithRouting(routing string) RequestOption {
+	return func(o *requestOptions) {
+		o.routing = routing
+	}
+}
+
+// WithRefresh sets the refresh policy of a write: RefreshTrue makes the change
+// visible immediately, RefreshWaitFor blocks until the next refresh and
+// RefreshFalse leaves it to the index refresh interval.
+func WithRefresh(policy string) RequestOption {
+	return func(o *requestOptions) {
+		o.refresh = policy
+	}
+}
+
+// WithIfSeqNo only applies a write when the document still has the given
+// sequence number and primary term, as returned by Get. Otherwise the write
+// fails with an error matching ErrVersionConflict.
+func WithIfSeqNo(seqNo, primaryTerm int64) RequestOption {
+	return func(o *requestOptions) {
+		seq, term := int(seqNo), int(primaryTerm)
+		o.ifSeqNo = &seq
+		o.ifPrimaryTerm = &term
+	}
+}
+
+// WithVersion sets the document version of an Index request. Unless
+// WithVersionType says otherwise, the version is treated as external.
+func WithVersion(version int64) RequestOption {
+	return func(o *requestOptions) {
+		o.version = &version
+	}
+}
+
+// WithVersionType sets how versions given with WithVersion or by a
+// VersionedModel are compared with the stored one.
+func WithVersionType(versionType string) RequestOption {
+	return func(o *requestOptions) {
+		o.versionType = versionType
+	}
+}
+
+// WithUpsert indexes doc when the document of an UpdateWithScript does not
+// exist yet, instead of failing with ErrDocumentNotFound.
+func WithUpsert(doc any) RequestOption {
+	return func(o *requestOptions) {
+		o.upsert = doc
+	}
+}
+
+// WithRetryOnConflict retries a scripted update up to n times when the
+// document is changed concurrently between its read and write.
+func WithRetryOnConflict(n int) RequestOption {
+	return func(o *requestOptions) {
+		o.retryOnConflict = &n
+	}
+}
+
+// WithSearchTimeout bounds the time each shard spends on a search. Shards
+// running out of time return the hits collected so far and the result is
+// flagged as timed out.
+func WithSearchTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.searchTimeout = timeout
+	}
+}
+
+// WithTerminateAfter stops collecting hits on a shard once it found n.
+func WithTerminateAfter(n int) RequestOption {
+	return func(o *requestOptions) {
+		o.terminateAfter = &n
+	}
+}
+
+// WithAllowPartialSearchResults controls whether a search with failed or
+// timed out shards returns partial results or fails as a whole.
+func WithAllowPartialSearchResults(allow bool) RequestOption {
+	return func(o *requestOptions) {
+		o.allowPartialSearchResults = &allow
+	}
+}
+
+// documentVersion returns the version and version type of an Index request.
+// An explicit WithVersion wins over the version carried by the model.
+func (o *requestOptions) documentVersion(model IndexModel) (*int64, string) {
+	if o.version != nil {
+		return o.version, o.externalVersionType()
+	}
+	return o.modelVersion(model)
+}
+
+// modelVersion returns the version carried by a VersionedModel. Bulk writes
+// only use this one since a single WithVersion cannot apply to every item.
+func (o *requestOptions) modelVersion(model IndexModel) (*int64, string) {
+	versioned, ok := model.(VersionedModel)
+	if !ok {
+		return nil, ""
+	}
+	version := versioned.GetVersion()
+	return &version, o.externalVersionType()
+}
+
+func (o *requestOptions) externalVersionType() string {
+	if o.versionType == "" {
+		return VersionTypeExternal
+	}
+	return o.versionType
+}
+
+// searchRouting returns the routing of a search request, which takes a list.
+func (o *requestOptions) searchRouting() []string {
+	if o.routing == "" {
+		return nil
+	}
+	return []string{o.routing}
+}
@@ -0,0 +1,233 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRouting(t *testing.T) {
+	doc := testDoc{ID: "1", Title: "routed"}
+
+	tests := []struct {
+		name string
+		call func(client *openSearchClient) error
+	}{
+		{
+			name: "index",
+			call: func(client *openSearchClient) error {
+				_, err := client.Index(context.Background(), "docs", doc, WithRouting("tenant-a"))
+				return err
+			},
+		},
+		{
+			name: "search",
+			call: func(client *openSearchClient) error {
+				_, err := client.Search(context.Background(), []string{"docs"}, strings.NewReader(`{}`), WithRouting("tenant-a"))
+				return err
+			},
+		},
+		{
+			name: "delete",
+			call: func(client *openSearchClient) error {
+				_, err := client.Delete(context.Background(), "docs", "1", WithRouting("tenant-a"))
+				return err
+			},
+		},
+		{
+			name: "bulk index",
+			call: func(client *openSearchClient) error {
+				_, err := client.BulkIndex(context.Background(), "docs", []IndexModel{doc}, WithRouting("tenant-a"))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRouting string
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				gotRouting = r.URL.Query().Get("routing")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"errors":false,"items":[{"index":{"_id":"1","status":201}}]}`))
+			})
+
+			require.NoError(t, tt.call(client))
+			assert.Equal(t, "tenant-a", gotRouting)
+		})
+	}
+}
+
+func TestNewRequestOptions(t *testing.T) {
+	assert.Nil(t, newRequestOptions(nil).searchRouting())
+	assert.Equal(t, []string{"r"}, newRequestOptions([]RequestOption{WithRouting("r")}).searchRouting())
+}
+
+func TestWithRefresh(t *testing.T) {
+	doc := testDoc{ID: "1"}
+
+	tests := []struct {
+		name   string
+		policy string
+		call   func(client *openSearchClient, opt RequestOption) error
+	}{
+		{
+			name:   "index",
+			policy: RefreshWaitFor,
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.Index(context.Background(), "docs", doc, opt)
+				return err
+			},
+		},
+		{
+			name:   "delete",
+			policy: RefreshTrue,
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.Delete(context.Background(), "docs", "1", opt)
+				return err
+			},
+		},
+		{
+			name:   "bulk index",
+			policy: RefreshFalse,
+			call: func(client *openSearchClient, opt RequestOption) error {
+				_, err := client.BulkIndex(context.Background(), "docs", []IndexModel{doc}, opt)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotRefresh string
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				gotRefresh = r.URL.Query().Get("refresh")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"errors":false,"items":[{"index":{"_id":"1","status":201}}]}`))
+			})
+
+			require.NoError(t, tt.call(client, WithRefresh(tt.policy)))
+			assert.Equal(t, tt.policy, gotRefresh)
+		})
+	}
+}
+
+type versionedDoc struct {
+	testDoc
+	Version int64 `json:"version"`
+}
+
+func (d versionedDoc) GetVersion() int64 {
+	return d.Version
+}
+
+func TestWithVersion(t *testing.T) {
+	tests := []struct {
+		name            string
+		model           IndexModel
+		opts            []RequestOption
+		wantVersion     string
+		wantVersionType string
+	}{
+		{
+			name:  "no version",
+			model: testDoc{ID: "1"},
+		},
+		{
+			name:            "explicit version",
+			model:           testDoc{ID: "1"},
+			opts:            []RequestOption{WithVersion(42)},
+			wantVersion:     "42",
+			wantVersionType: VersionTypeExternal,
+		},
+		{
+			name:            "versioned model",
+			model:           versionedDoc{testDoc: testDoc{ID: "1"}, Version: 5},
+			opts:            []RequestOption{WithVersionType(VersionTypeExternalGTE)},
+			wantVersion:     "5",
+			wantVersionType: VersionTypeExternalGTE,
+		},
+		{
+			name:            "explicit version wins",
+			model:           versionedDoc{testDoc: testDoc{ID: "1"}, Version: 5},
+			opts:            []RequestOption{WithVersion(6)},
+			wantVersion:     "6",
+			wantVersionType: VersionTypeExternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotVersion, gotVersionType string
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				gotVersion = r.URL.Query().Get("version")
+				gotVersionType = r.URL.Query().Get("version_type")
+				_, _ = w.Write([]byte(`{"result":"created"}`))
+			})
+
+			_, err := client.Index(context.Background(), "docs", tt.model, tt.opts...)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVersion, gotVersion)
+			assert.Equal(t, tt.wantVersionType, gotVersionType)
+		})
+	}
+}
+
+func TestBulkIndexModelVersion(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		lines []string
+	)
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		lines = append(lines, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+		mu.Unlock()
+		_, _ = w.Write([]byte(bulkResponse(b)))
+	})
+
+	models := []IndexModel{
+		versionedDoc{testDoc: testDoc{ID: "1"}, Version: 9},
+		testDoc{ID: "2"},
+	}
+	_, err := client.BulkIndex(context.Background(), "docs", models, WithVersion(100))
+	require.NoError(t, err)
+
+	require.Len(t, lines, 4)
+	for i := 0; i < len(lines); i += 2 {
+		switch {
+		case strings.Contains(lines[i], `"_id":"1"`):
+			assert.Contains(t, lines[i], `"version":9`)
+			assert.Contains(t, lines[i], `"version_type":"external"`)
+		default:
+			assert.NotContains(t, lines[i], `"version"`)
+		}
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	var got url.Values
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	_, err := client.Search(context.Background(), []string{"docs"}, strings.NewReader(`{}`),
+		WithSearchTimeout(500*time.Millisecond),
+		WithTerminateAfter(1000),
+		WithAllowPartialSearchResults(false),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, "500ms", got.Get("timeout"))
+	assert.Equal(t, "1000", got.Get("terminate_after"))
+	assert.Equal(t, "false", got.Get("allow_partial_search_results"))
+}
@@ -0,0 +1,75 @@
+package platigo
+
+import (
+	"context"
+
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+const (
+	// PercolatorQueryField is the field holding stored queries. The index
+	// must map it with the percolator type.
+	PercolatorQueryField = "query"
+
+	// percolateMaxMatches is the default index.max_result_window, the most
+	// matches a single percolate search can return.
+	percolateMaxMatches = 10000
+)
+
+// PercolateQuery returns a percolate query matching the stored queries of
+// field against document.
+func PercolateQuery(field string, document any) map[string]any {
+	return map[string]any{
+		"percolate": map[string]any{
+			"field":    field,
+			"document": document,
+		},
+	}
+}
+
+// percolatorQuery is the document storing a query under PercolatorQueryField.
+type percolatorQuery struct {
+	id    string
+	query any
+}
+
+func (q percolatorQuery) GetID() string {
+	return q.id
+}
+
+func (q percolatorQuery) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{PercolatorQueryField: q.query})
+}
+
+func (k *openSearchClient) PutPercolatorQuery(ctx context.Context, indexName string, queryID string, query any, opts ...RequestOption) (*opensearchapi.Response, error) {
+	return k.Index(ctx, indexName, percolatorQuery{id: queryID, query: query}, opts...)
+}
+
+func (k *openSearchClient) PercolateDocument(ctx context.Context, indexName string, doc any, opts ...RequestOption) ([]string, error) {
+	body, err := NewSearchSource().
+		Query(PercolateQuery(PercolatorQueryField, doc)).
+		Size(percolateMaxMatches).
+		FetchSource(false).
+		Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := k.Search(ctx, []string{indexName}, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ParseSearchResult(res)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids, nil
+}
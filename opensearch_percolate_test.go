@@ -0,0 +1,46 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientPutPercolatorQuery(t *testing.T) {
+	var gotPath, gotBody string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.URL.Path, string(b)
+		_, _ = w.Write([]byte(`{"result":"created"}`))
+	})
+
+	query := map[string]any{"match": map[string]any{"title": "golang"}}
+	_, err := client.PutPercolatorQuery(context.Background(), "saved-searches", "alert-1", query)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/saved-searches/_doc/alert-1", gotPath)
+	assert.JSONEq(t, `{"query":{"match":{"title":"golang"}}}`, gotBody)
+}
+
+func TestOpenSearchClientPercolateDocument(t *testing.T) {
+	var gotBody string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		_, _ = w.Write([]byte(`{"hits":{"total":{"value":2},"hits":[{"_id":"alert-1"},{"_id":"alert-7"}]}}`))
+	})
+
+	got, err := client.PercolateDocument(context.Background(), "saved-searches", testDoc{ID: "1", Title: "golang tips"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"alert-1", "alert-7"}, got)
+	assert.JSONEq(t, `{
+		"query": {"percolate": {"field": "query", "document": {"id": "1", "title": "golang tips"}}},
+		"size": 10000,
+		"_source": false
+	}`, gotBody)
+}
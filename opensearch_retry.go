@@ -0,0 +1,24 @@
+package platigo
+
+import (
+	"math/rand"
+	"time"
+)
+
+// ExponentialBackoff returns an OSConfig.RetryBackoff doubling the delay
+// from initial up to max, with up to 20% jitter so retries of concurrent
+// requests do not hit the cluster at the same time.
+func ExponentialBackoff(initial, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < max; i++ {
+			delay *= 2
+		}
+		if delay > max {
+			delay = max
+		}
+
+		jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1)) // #nosec G404
+		return delay - jitter
+	}
+}
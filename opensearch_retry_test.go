@@ -0,0 +1,72 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientRetryOnStatus(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, &OSConfig{
+		MaxRetries:    2,
+		RetryOnStatus: []int{http.StatusTooManyRequests},
+		RetryBackoff:  ExponentialBackoff(time.Millisecond, 2*time.Millisecond),
+	}, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	res, err := client.Search(context.Background(), []string{"docs"}, strings.NewReader(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestOpenSearchClientDiscoverNodesOnStart(t *testing.T) {
+	discovered := make(chan struct{}, 1)
+	newTestClient(t, &OSConfig{DiscoverNodesOnStart: true}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_nodes/http" {
+			select {
+			case discovered <- struct{}{}:
+			default:
+			}
+		}
+		_, _ = w.Write([]byte(`{"nodes":{}}`))
+	})
+
+	select {
+	case <-discovered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("nodes were not discovered on start")
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := ExponentialBackoff(100*time.Millisecond, time.Second)
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 100 * time.Millisecond},
+		{attempt: 2, max: 200 * time.Millisecond},
+		{attempt: 3, max: 400 * time.Millisecond},
+		{attempt: 10, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		got := backoff(tt.attempt)
+		assert.LessOrEqual(t, got, tt.max)
+		assert.GreaterOrEqual(t, got, tt.max*4/5)
+	}
+}
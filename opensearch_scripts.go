@@ -0,0 +1,174 @@
+package platigo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// ScriptLangPainless is the default scripting language of OpenSearch.
+const ScriptLangPainless = "painless"
+
+// StoredScript is a script stored in the cluster state and referenced by ID.
+//
+//	client.PutScript(ctx, "increment-views", platigo.StoredScript{
+//		Source: "ctx._source.views += params.count",
+//	})
+type StoredScript struct {
+	// Lang defaults to ScriptLangPainless.
+	Lang   string `json:"lang"`
+	Source string `json:"source"`
+}
+
+func (k *openSearchClient) PutScript(ctx context.Context, scriptID string, script StoredScript) (err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "put_script", nil)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"scriptID": scriptID,
+	})
+
+	if script.Lang == "" {
+		script.Lang = ScriptLangPainless
+	}
+	body, err := json.Marshal(map[string]any{"script": script})
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	req := opensearchapi.PutScriptRequest{
+		ScriptID: scriptID,
+		Body:     strings.NewReader(string(body)),
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if err = decodeResponse(res, &struct{}{}); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (k *openSearchClient) GetScript(ctx context.Context, scriptID string) (script *StoredScript, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "get_script", nil)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"scriptID": scriptID,
+	})
+
+	req := opensearchapi.GetScriptRequest{
+		ScriptID: scriptID,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var body struct {
+		Script StoredScript `json:"script"`
+	}
+	if err = decodeResponse(res, &body); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &body.Script, nil
+}
+
+func (k *openSearchClient) DeleteScript(ctx context.Context, scriptID string) (err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "delete_script", nil)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"scriptID": scriptID,
+	})
+
+	req := opensearchapi.DeleteScriptRequest{
+		ScriptID: scriptID,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	if err = decodeResponse(res, &struct{}{}); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (k *openSearchClient) UpdateWithScript(ctx context.Context, indexName string, docID string, scriptID string, params map[string]any, opts ...RequestOption) (res *opensearchapi.Response, err error) {
+	ctx, op := k.startOperation(ctx, "update_with_script", []string{indexName})
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"indexName": indexName,
+		"docID":     docID,
+		"scriptID":  scriptID,
+	})
+
+	options := newRequestOptions(opts)
+
+	script := map[string]any{"id": scriptID}
+	if len(params) > 0 {
+		script["params"] = params
+	}
+	payload := map[string]any{"script": script}
+	if options.upsert != nil {
+		payload["upsert"] = options.upsert
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	req := opensearchapi.UpdateRequest{
+		Index:           indexName,
+		DocumentID:      docID,
+		Body:            strings.NewReader(string(body)),
+		Routing:         options.routing,
+		Refresh:         options.refresh,
+		IfSeqNo:         options.ifSeqNo,
+		IfPrimaryTerm:   options.ifPrimaryTerm,
+		RetryOnConflict: options.retryOnConflict,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	if res.IsError() {
+		defer res.Body.Close()
+		err = newResponseError(res)
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	k.logResponse(log, res)
+
+	return res, nil
+}
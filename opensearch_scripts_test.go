@@ -0,0 +1,148 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientPutScript(t *testing.T) {
+	var gotMethod, gotPath, gotBody string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotMethod, gotPath, gotBody = r.Method, r.URL.Path, string(b)
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	})
+
+	err := client.PutScript(context.Background(), "increment-views", StoredScript{Source: "ctx._source.views += params.count"})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPut, gotMethod)
+	assert.Equal(t, "/_scripts/increment-views", gotPath)
+	assert.JSONEq(t, `{"script":{"lang":"painless","source":"ctx._source.views += params.count"}}`, gotBody)
+}
+
+func TestOpenSearchClientGetScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    *StoredScript
+		wantErr error
+	}{
+		{
+			name:   "found",
+			status: http.StatusOK,
+			body:   `{"_id":"increment-views","found":true,"script":{"lang":"painless","source":"ctx._source.views++"}}`,
+			want:   &StoredScript{Lang: "painless", Source: "ctx._source.views++"},
+		},
+		{
+			name:    "not found",
+			status:  http.StatusNotFound,
+			body:    `{"_id":"increment-views","found":false}`,
+			wantErr: ErrDocumentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/_scripts/increment-views", r.URL.Path)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			got, err := client.GetScript(context.Background(), "increment-views")
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOpenSearchClientDeleteScript(t *testing.T) {
+	var gotMethod, gotPath string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	})
+
+	require.NoError(t, client.DeleteScript(context.Background(), "increment-views"))
+	assert.Equal(t, http.MethodDelete, gotMethod)
+	assert.Equal(t, "/_scripts/increment-views", gotPath)
+}
+
+func TestOpenSearchClientUpdateWithScript(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]any
+		opts      []RequestOption
+		status    int
+		wantBody  string
+		wantQuery map[string]string
+		wantErr   error
+	}{
+		{
+			name:     "stored script with params",
+			params:   map[string]any{"count": 1},
+			status:   http.StatusOK,
+			wantBody: `{"script":{"id":"increment-views","params":{"count":1}}}`,
+		},
+		{
+			name:      "upsert and retry on conflict",
+			params:    map[string]any{"count": 1},
+			opts:      []RequestOption{WithUpsert(map[string]any{"views": 1}), WithRetryOnConflict(3)},
+			status:    http.StatusOK,
+			wantBody:  `{"script":{"id":"increment-views","params":{"count":1}},"upsert":{"views":1}}`,
+			wantQuery: map[string]string{"retry_on_conflict": "3"},
+		},
+		{
+			name:     "conflict",
+			opts:     []RequestOption{WithIfSeqNo(3, 1)},
+			status:   http.StatusConflict,
+			wantBody: `{"script":{"id":"increment-views"}}`,
+			wantErr:  ErrVersionConflict,
+		},
+		{
+			name:     "missing document without upsert",
+			status:   http.StatusNotFound,
+			wantBody: `{"script":{"id":"increment-views"}}`,
+			wantErr:  ErrDocumentNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotBody string
+			var gotQuery url.Values
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				gotPath, gotBody, gotQuery = r.URL.Path, string(b), r.URL.Query()
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"result":"updated"}`))
+			})
+
+			_, err := client.UpdateWithScript(context.Background(), "articles", "1", "increment-views", tt.params, tt.opts...)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr))
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, "/articles/_doc/1/_update", gotPath)
+			assert.JSONEq(t, tt.wantBody, gotBody)
+			for key, want := range tt.wantQuery {
+				assert.Equal(t, want, gotQuery.Get(key), key)
+			}
+		})
+	}
+}
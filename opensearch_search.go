@@ -0,0 +1,298 @@
+package platigo
+
+import (
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// SearchSource builds the body of a search request.
+//
+//	source := platigo.NewSearchSource().
+//		Query(map[string]any{"match": map[string]any{"content": "sample"}}).
+//		Highlight(platigo.NewHighlight().Field("content"))
+//	body, err := source.Reader()
+type SearchSource struct {
+	query     any
+	from      *int
+	size      *int
+	sort      []any
+	after     []any
+	highlight *Highlight
+	suggest   map[string]Suggester
+	source    *bool
+}
+
+// NewSearchSource creates an empty search body.
+func NewSearchSource() *SearchSource {
+	return &SearchSource{}
+}
+
+// Query sets the query clause. Any value marshaling to a valid query DSL
+// object is accepted.
+func (s *SearchSource) Query(query any) *SearchSource {
+	s.query = query
+	return s
+}
+
+// From sets the offset of the first hit.
+func (s *SearchSource) From(from int) *SearchSource {
+	s.from = &from
+	return s
+}
+
+// Size sets the number of hits to return.
+func (s *SearchSource) Size(size int) *SearchSource {
+	s.size = &size
+	return s
+}
+
+// Sort appends a sort clause, e.g. map[string]any{"created_at": "desc"}.
+func (s *SearchSource) Sort(sort ...any) *SearchSource {
+	s.sort = append(s.sort, sort...)
+	return s
+}
+
+// SearchAfter starts the hits after the given sort values, as returned by
+// SearchResult.NextCursor and DecodeCursor. Unlike From, deep pages cost
+// the same as the first one. The sort must end with a unique field.
+func (s *SearchSource) SearchAfter(values ...any) *SearchSource {
+	s.after = values
+	return s
+}
+
+// Highlight sets the highlight clause.
+func (s *SearchSource) Highlight(highlight *Highlight) *SearchSource {
+	s.highlight = highlight
+	return s
+}
+
+// FetchSource controls whether hits carry their _source.
+func (s *SearchSource) FetchSource(fetch bool) *SearchSource {
+	s.source = &fetch
+	return s
+}
+
+// Suggest adds a named suggester. Its results are read with
+// SearchResult.Suggestions.
+func (s *SearchSource) Suggest(name string, suggester Suggester) *SearchSource {
+	if s.suggest == nil {
+		s.suggest = map[string]Suggester{}
+	}
+	s.suggest[name] = suggester
+	return s
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *SearchSource) MarshalJSON() ([]byte, error) {
+	body := map[string]any{}
+	if s.query != nil {
+		body["query"] = s.query
+	}
+	if s.from != nil {
+		body["from"] = *s.from
+	}
+	if s.size != nil {
+		body["size"] = *s.size
+	}
+	if len(s.sort) > 0 {
+		body["sort"] = s.sort
+	}
+	if len(s.after) > 0 {
+		body["search_after"] = s.after
+	}
+	if s.highlight != nil {
+		body["highlight"] = s.highlight
+	}
+	if len(s.suggest) > 0 {
+		body["suggest"] = s.suggest
+	}
+	if s.source != nil {
+		body["_source"] = *s.source
+	}
+	return json.Marshal(body)
+}
+
+// Reader returns the body ready to be passed to Search.
+func (s *SearchSource) Reader() (*strings.Reader, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(string(data)), nil
+}
+
+// Highlight builds the highlight clause of a search.
+type Highlight struct {
+	fields            map[string]*HighlightField
+	order             []string
+	preTags           []string
+	postTags          []string
+	fragmentSize      *int
+	numberOfFragments *int
+	highlighterType   string
+	requireFieldMatch *bool
+}
+
+// HighlightField holds the per-field highlight settings, overriding the
+// ones of the Highlight it belongs to.
+type HighlightField struct {
+	FragmentSize      *int   `json:"fragment_size,omitempty"`
+	NumberOfFragments *int   `json:"number_of_fragments,omitempty"`
+	Type              string `json:"type,omitempty"`
+	HighlightQuery    any    `json:"highlight_query,omitempty"`
+}
+
+// NewHighlight creates an empty highlight clause.
+func NewHighlight() *Highlight {
+	return &Highlight{fields: map[string]*HighlightField{}}
+}
+
+// Field highlights a field with the default settings.
+func (h *Highlight) Field(name string) *Highlight {
+	return h.FieldWith(name, &HighlightField{})
+}
+
+// FieldWith highlights a field with its own settings.
+func (h *Highlight) FieldWith(name string, field *HighlightField) *Highlight {
+	if _, ok := h.fields[name]; !ok {
+		h.order = append(h.order, name)
+	}
+	h.fields[name] = field
+	return h
+}
+
+// Tags sets the markup wrapped around highlighted terms, <em></em> by default.
+func (h *Highlight) Tags(pre, post string) *Highlight {
+	h.preTags = []string{pre}
+	h.postTags = []string{post}
+	return h
+}
+
+// FragmentSize sets the size of a fragment in characters.
+func (h *Highlight) FragmentSize(size int) *Highlight {
+	h.fragmentSize = &size
+	return h
+}
+
+// NumberOfFragments sets the maximum number of fragments per field.
+func (h *Highlight) NumberOfFragments(n int) *Highlight {
+	h.numberOfFragments = &n
+	return h
+}
+
+// Type selects the highlighter: unified, plain or fvh.
+func (h *Highlight) Type(highlighterType string) *Highlight {
+	h.highlighterType = highlighterType
+	return h
+}
+
+// RequireFieldMatch only highlights fields that matched the query.
+func (h *Highlight) RequireFieldMatch(require bool) *Highlight {
+	h.requireFieldMatch = &require
+	return h
+}
+
+// MarshalJSON implements json.Marshaler. Fields are written as an array to
+// keep their order, which OpenSearch uses as the highlight order.
+func (h *Highlight) MarshalJSON() ([]byte, error) {
+	fields := make([]map[string]*HighlightField, 0, len(h.order))
+	for _, name := range h.order {
+		fields = append(fields, map[string]*HighlightField{name: h.fields[name]})
+	}
+
+	body := map[string]any{"fields": fields}
+	if len(h.preTags) > 0 {
+		body["pre_tags"] = h.preTags
+		body["post_tags"] = h.postTags
+	}
+	if h.fragmentSize != nil {
+		body["fragment_size"] = *h.fragmentSize
+	}
+	if h.numberOfFragments != nil {
+		body["number_of_fragments"] = *h.numberOfFragments
+	}
+	if h.highlighterType != "" {
+		body["type"] = h.highlighterType
+	}
+	if h.requireFieldMatch != nil {
+		body["require_field_match"] = *h.requireFieldMatch
+	}
+	return json.Marshal(body)
+}
+
+// SearchResult is the typed form of a search response.
+type SearchResult struct {
+	Took     int64
+	TimedOut bool
+	Total    int64
+	MaxScore *float64
+	Hits     []SearchHit
+	Suggest  map[string][]SuggestEntry
+}
+
+// NextCursor returns the cursor of the page following a search of size hits
+// sorted for SearchAfter, or an empty one when this page is the last.
+func (r *SearchResult) NextCursor(size int) (string, error) {
+	if size <= 0 || len(r.Hits) < size {
+		return "", nil
+	}
+	last := r.Hits[len(r.Hits)-1]
+	if len(last.Sort) == 0 {
+		return "", nil
+	}
+	return EncodeCursor(last.Sort...)
+}
+
+// SearchHit is a single document of a SearchResult.
+type SearchHit struct {
+	Index     string              `json:"_index"`
+	ID        string              `json:"_id"`
+	Score     *float64            `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight,omitempty"`
+	Sort      []any               `json:"sort,omitempty"`
+}
+
+// Decode unmarshals the hit source into v.
+func (h *SearchHit) Decode(v any) error {
+	return json.Unmarshal(h.Source, v)
+}
+
+type searchResponse struct {
+	Took     int64 `json:"took"`
+	TimedOut bool  `json:"timed_out"`
+	Hits     struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		MaxScore *float64    `json:"max_score"`
+		Hits     []SearchHit `json:"hits"`
+	} `json:"hits"`
+	Suggest map[string][]SuggestEntry `json:"suggest"`
+}
+
+// ParseSearchResult decodes and closes the response of Search. Error
+// responses are returned as a *ResponseError.
+func ParseSearchResult(res *opensearchapi.Response) (*SearchResult, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, newResponseError(res)
+	}
+
+	var raw searchResponse
+	if err := json.NewDecoder(res.Body).Decode(&raw); err != nil {
+		return nil, err
+	}
+
+	return &SearchResult{
+		Took:     raw.Took,
+		TimedOut: raw.TimedOut,
+		Total:    raw.Hits.Total.Value,
+		MaxScore: raw.Hits.MaxScore,
+		Hits:     raw.Hits.Hits,
+		Suggest:  raw.Suggest,
+	}, nil
+}
@@ -0,0 +1,159 @@
+package platigo
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchSourceMarshal(t *testing.T) {
+	fragments := 1
+	source := NewSearchSource().
+		Query(map[string]any{"match": map[string]any{"content": "sample"}}).
+		Size(10).
+		From(20).
+		Sort(map[string]any{"created_at": "desc"}).
+		Highlight(NewHighlight().
+			Field("title").
+			FieldWith("content", &HighlightField{NumberOfFragments: &fragments}).
+			Tags("<mark>", "</mark>").
+			FragmentSize(100))
+
+	got, err := json.Marshal(source)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"query": {"match": {"content": "sample"}},
+		"size": 10,
+		"from": 20,
+		"sort": [{"created_at": "desc"}],
+		"highlight": {
+			"fields": [{"title": {}}, {"content": {"number_of_fragments": 1}}],
+			"pre_tags": ["<mark>"],
+			"post_tags": ["</mark>"],
+			"fragment_size": 100
+		}
+	}`, string(got))
+}
+
+func TestSearchSourceEmpty(t *testing.T) {
+	reader, err := NewSearchSource().Reader()
+	require.NoError(t, err)
+
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(got))
+}
+
+func TestParseSearchResult(t *testing.T) {
+	t.Run("hits with highlight", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{
+				"took": 3,
+				"timed_out": false,
+				"hits": {
+					"total": {"value": 1, "relation": "eq"},
+					"max_score": 1.5,
+					"hits": [{
+						"_index": "docs",
+						"_id": "1",
+						"_score": 1.5,
+						"_source": {"id": "1", "title": "a sample"},
+						"highlight": {"title": ["a <em>sample</em>"]}
+					}]
+				}
+			}`))
+		})
+
+		body, err := NewSearchSource().Highlight(NewHighlight().Field("title")).Reader()
+		require.NoError(t, err)
+
+		res, err := client.Search(context.Background(), []string{"docs"}, body)
+		require.NoError(t, err)
+
+		result, err := ParseSearchResult(res)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.Total)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, []string{"a <em>sample</em>"}, result.Hits[0].Highlight["title"])
+
+		var doc testDoc
+		require.NoError(t, result.Hits[0].Decode(&doc))
+		assert.Equal(t, "a sample", doc.Title)
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"parsing_exception","reason":"unknown query"}}`))
+		})
+
+		body, err := NewSearchSource().Reader()
+		require.NoError(t, err)
+
+		res, err := client.Search(context.Background(), []string{"docs"}, body)
+		require.NoError(t, err)
+
+		result, err := ParseSearchResult(res)
+		assert.Nil(t, result)
+
+		var resErr *ResponseError
+		require.ErrorAs(t, err, &resErr)
+		assert.Equal(t, "parsing_exception", resErr.Type)
+	})
+}
+
+func TestSearchStreamingBody(t *testing.T) {
+	var gotBody string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(map[string]any{"size": 1}))
+
+	_, err := client.Search(context.Background(), []string{"docs"}, &buf)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size":1}`, gotBody)
+
+	pr, pw := io.Pipe()
+	go func() {
+		_ = pw.CloseWithError(json.NewEncoder(pw).Encode(map[string]any{"size": 2}))
+	}()
+
+	_, err = client.Search(context.Background(), []string{"docs"}, pr)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"size":2}`, gotBody)
+}
+
+func TestSearchSourceSearchAfter(t *testing.T) {
+	result := &SearchResult{Hits: []SearchHit{
+		{ID: "1", Sort: []any{float64(1767323045000), "1"}},
+		{ID: "2", Sort: []any{float64(1767323046000), "2"}},
+	}}
+
+	cursor, err := result.NextCursor(2)
+	require.NoError(t, err)
+	values, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+
+	got, err := json.Marshal(NewSearchSource().Size(2).Sort(map[string]any{"created_at": "asc"}, map[string]any{"id": "asc"}).SearchAfter(values...))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"size": 2,
+		"sort": [{"created_at": "asc"}, {"id": "asc"}],
+		"search_after": [1767323046000, "2"]
+	}`, string(got))
+
+	last, err := result.NextCursor(10)
+	require.NoError(t, err)
+	assert.Empty(t, last, "fewer hits than the size is the last page")
+}
@@ -0,0 +1,212 @@
+package platigo
+
+import (
+	"context"
+
+	"github.com/goccy/go-json"
+)
+
+// suggestName is the name of the suggester sent by Suggest.
+const suggestName = "suggestions"
+
+// Suggester is a suggester clause added with SearchSource.Suggest.
+type Suggester interface {
+	json.Marshaler
+}
+
+// CompletionSuggester suggests completions of a prefix from a field mapped
+// with the completion type.
+type CompletionSuggester struct {
+	field          string
+	prefix         string
+	size           *int
+	skipDuplicates bool
+	fuzziness      string
+}
+
+// NewCompletionSuggester creates a completion suggester for prefix on field.
+func NewCompletionSuggester(field, prefix string) *CompletionSuggester {
+	return &CompletionSuggester{field: field, prefix: prefix}
+}
+
+// Size sets the number of suggestions to return.
+func (s *CompletionSuggester) Size(size int) *CompletionSuggester {
+	s.size = &size
+	return s
+}
+
+// SkipDuplicates drops suggestions with the same text.
+func (s *CompletionSuggester) SkipDuplicates(skip bool) *CompletionSuggester {
+	s.skipDuplicates = skip
+	return s
+}
+
+// Fuzzy tolerates typos in the prefix, e.g. "AUTO" or "1".
+func (s *CompletionSuggester) Fuzzy(fuzziness string) *CompletionSuggester {
+	s.fuzziness = fuzziness
+	return s
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *CompletionSuggester) MarshalJSON() ([]byte, error) {
+	completion := map[string]any{"field": s.field}
+	if s.size != nil {
+		completion["size"] = *s.size
+	}
+	if s.skipDuplicates {
+		completion["skip_duplicates"] = true
+	}
+	if s.fuzziness != "" {
+		completion["fuzzy"] = map[string]any{"fuzziness": s.fuzziness}
+	}
+	return json.Marshal(map[string]any{
+		"prefix":     s.prefix,
+		"completion": completion,
+	})
+}
+
+// PhraseSuggester suggests corrected phrases for a text, e.g. "did you mean".
+type PhraseSuggester struct {
+	field      string
+	text       string
+	size       *int
+	gramSize   *int
+	maxErrors  *float64
+	confidence *float64
+	preTag     string
+	postTag    string
+}
+
+// NewPhraseSuggester creates a phrase suggester for text on field.
+func NewPhraseSuggester(field, text string) *PhraseSuggester {
+	return &PhraseSuggester{field: field, text: text}
+}
+
+// Size sets the number of suggestions to return.
+func (s *PhraseSuggester) Size(size int) *PhraseSuggester {
+	s.size = &size
+	return s
+}
+
+// GramSize sets the maximum shingle size of the field.
+func (s *PhraseSuggester) GramSize(size int) *PhraseSuggester {
+	s.gramSize = &size
+	return s
+}
+
+// MaxErrors sets the maximum number (or ratio when < 1) of misspelled terms.
+func (s *PhraseSuggester) MaxErrors(maxErrors float64) *PhraseSuggester {
+	s.maxErrors = &maxErrors
+	return s
+}
+
+// Confidence sets the score threshold a suggestion must reach.
+func (s *PhraseSuggester) Confidence(confidence float64) *PhraseSuggester {
+	s.confidence = &confidence
+	return s
+}
+
+// Highlight wraps the corrected terms in the given tags.
+func (s *PhraseSuggester) Highlight(pre, post string) *PhraseSuggester {
+	s.preTag = pre
+	s.postTag = post
+	return s
+}
+
+// MarshalJSON implements json.Marshaler.
+func (s *PhraseSuggester) MarshalJSON() ([]byte, error) {
+	phrase := map[string]any{"field": s.field}
+	if s.size != nil {
+		phrase["size"] = *s.size
+	}
+	if s.gramSize != nil {
+		phrase["gram_size"] = *s.gramSize
+	}
+	if s.maxErrors != nil {
+		phrase["max_errors"] = *s.maxErrors
+	}
+	if s.confidence != nil {
+		phrase["confidence"] = *s.confidence
+	}
+	if s.preTag != "" || s.postTag != "" {
+		phrase["highlight"] = map[string]any{"pre_tag": s.preTag, "post_tag": s.postTag}
+	}
+	return json.Marshal(map[string]any{
+		"text":   s.text,
+		"phrase": phrase,
+	})
+}
+
+// SuggestEntry is the result of a suggester for one token of the input.
+type SuggestEntry struct {
+	Text    string       `json:"text"`
+	Offset  int          `json:"offset"`
+	Length  int          `json:"length"`
+	Options []Suggestion `json:"options"`
+}
+
+// Suggestion is a single suggested text. Completion suggestions also carry
+// the document they come from.
+type Suggestion struct {
+	Text        string          `json:"text"`
+	Score       float64         `json:"score"`
+	Highlighted string          `json:"highlighted,omitempty"`
+	Index       string          `json:"_index,omitempty"`
+	ID          string          `json:"_id,omitempty"`
+	Source      json.RawMessage `json:"_source,omitempty"`
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Completion suggestions report
+// their score as _score while phrase suggestions use score.
+func (s *Suggestion) UnmarshalJSON(data []byte) error {
+	type suggestion Suggestion
+	var raw struct {
+		suggestion
+		DocScore *float64 `json:"_score"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*s = Suggestion(raw.suggestion)
+	if raw.DocScore != nil {
+		s.Score = *raw.DocScore
+	}
+	return nil
+}
+
+// Decode unmarshals the source of the suggested document into v.
+func (s *Suggestion) Decode(v any) error {
+	return json.Unmarshal(s.Source, v)
+}
+
+// Suggestions returns the options of every entry of the named suggester.
+func (r *SearchResult) Suggestions(name string) []Suggestion {
+	var suggestions []Suggestion
+	for _, entry := range r.Suggest[name] {
+		suggestions = append(suggestions, entry.Options...)
+	}
+	return suggestions
+}
+
+func (k *openSearchClient) Suggest(ctx context.Context, indexName string, field string, prefix string, opts ...RequestOption) ([]Suggestion, error) {
+	body, err := NewSearchSource().
+		Size(0).
+		Suggest(suggestName, NewCompletionSuggester(field, prefix).SkipDuplicates(true)).
+		Reader()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := k.Search(ctx, []string{indexName}, body, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := ParseSearchResult(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Suggestions(suggestName), nil
+}
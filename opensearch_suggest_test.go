@@ -0,0 +1,74 @@
+package platigo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggesterMarshal(t *testing.T) {
+	tests := []struct {
+		name      string
+		suggester Suggester
+		want      string
+	}{
+		{
+			name:      "completion",
+			suggester: NewCompletionSuggester("title.suggest", "sam").Size(5).SkipDuplicates(true).Fuzzy("AUTO"),
+			want:      `{"prefix":"sam","completion":{"field":"title.suggest","size":5,"skip_duplicates":true,"fuzzy":{"fuzziness":"AUTO"}}}`,
+		},
+		{
+			name:      "phrase",
+			suggester: NewPhraseSuggester("title.trigram", "smaple docment").Size(1).GramSize(3).MaxErrors(2).Highlight("<em>", "</em>"),
+			want:      `{"text":"smaple docment","phrase":{"field":"title.trigram","size":1,"gram_size":3,"max_errors":2,"highlight":{"pre_tag":"<em>","post_tag":"</em>"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.suggester)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestOpenSearchClientSuggest(t *testing.T) {
+	var gotBody string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		_, _ = w.Write([]byte(`{
+			"hits": {"total": {"value": 0}, "hits": []},
+			"suggest": {
+				"suggestions": [{
+					"text": "sam",
+					"offset": 0,
+					"length": 3,
+					"options": [
+						{"text": "sample", "_index": "docs", "_id": "1", "_score": 2, "_source": {"id": "1", "title": "sample"}},
+						{"text": "samurai", "_index": "docs", "_id": "2", "_score": 1}
+					]
+				}]
+			}
+		}`))
+	})
+
+	got, err := client.Suggest(context.Background(), "docs", "title.suggest", "sam")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"size":0,"suggest":{"suggestions":{"prefix":"sam","completion":{"field":"title.suggest","skip_duplicates":true}}}}`, gotBody)
+	require.Len(t, got, 2)
+	assert.Equal(t, "sample", got[0].Text)
+	assert.Equal(t, float64(2), got[0].Score)
+	assert.Equal(t, "2", got[1].ID)
+
+	var doc testDoc
+	require.NoError(t, got[0].Decode(&doc))
+	assert.Equal(t, "sample", doc.Title)
+}
@@ -0,0 +1,196 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/goccy/go-json"
+	"github.com/opensearch-project/opensearch-go/opensearchapi"
+)
+
+// TaskFilter narrows down the tasks returned by ListTasks.
+type TaskFilter struct {
+	// Actions filters by action, wildcards allowed, e.g. "*reindex".
+	Actions      []string
+	Nodes        []string
+	ParentTaskID string
+	// Detailed includes the description and status of each task.
+	Detailed bool
+}
+
+// Task is a task running on the cluster.
+type Task struct {
+	ID           string
+	Node         string
+	Type         string
+	Action       string
+	Description  string
+	StartTime    time.Time
+	RunningTime  time.Duration
+	Cancellable  bool
+	Cancelled    bool
+	ParentTaskID string
+	// Status is the action specific progress, e.g. created/updated/total
+	// counts of a reindex.
+	Status json.RawMessage
+}
+
+// TaskInfo is the result of GetTask.
+type TaskInfo struct {
+	Completed bool
+	Task      Task
+	// Response is the result of a completed task.
+	Response json.RawMessage
+	// Error is set when a completed task failed.
+	Error json.RawMessage
+}
+
+type taskRow struct {
+	Node               string          `json:"node"`
+	ID                 int64           `json:"id"`
+	Type               string          `json:"type"`
+	Action             string          `json:"action"`
+	Description        string          `json:"description"`
+	StartTimeInMillis  int64           `json:"start_time_in_millis"`
+	RunningTimeInNanos int64           `json:"running_time_in_nanos"`
+	Cancellable        bool            `json:"cancellable"`
+	Cancelled          bool            `json:"cancelled"`
+	ParentTaskID       string          `json:"parent_task_id"`
+	Status             json.RawMessage `json:"status"`
+}
+
+func (r taskRow) toTask() Task {
+	return Task{
+		ID:           r.Node + ":" + strconv.FormatInt(r.ID, 10),
+		Node:         r.Node,
+		Type:         r.Type,
+		Action:       r.Action,
+		Description:  r.Description,
+		StartTime:    time.UnixMilli(r.StartTimeInMillis),
+		RunningTime:  time.Duration(r.RunningTimeInNanos),
+		Cancellable:  r.Cancellable,
+		Cancelled:    r.Cancelled,
+		ParentTaskID: r.ParentTaskID,
+		Status:       r.Status,
+	}
+}
+
+type taskFailure struct {
+	Reason string `json:"reason"`
+	Type   string `json:"type"`
+}
+
+func (k *openSearchClient) ListTasks(ctx context.Context, filter TaskFilter) (tasks []Task, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "list_tasks", nil)
+	defer func() { op.end(res, err) }()
+
+	req := opensearchapi.TasksListRequest{
+		Actions:      filter.Actions,
+		Nodes:        filter.Nodes,
+		ParentTaskID: filter.ParentTaskID,
+		Detailed:     &filter.Detailed,
+		GroupBy:      "none",
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		logger.WithContext(ctx, k.log).Error(err.Error())
+		return nil, err
+	}
+
+	var body struct {
+		Tasks []taskRow `json:"tasks"`
+	}
+	if err = decodeResponse(res, &body); err != nil {
+		logger.WithContext(ctx, k.log).Error(err.Error())
+		return nil, err
+	}
+
+	tasks = make([]Task, 0, len(body.Tasks))
+	for _, row := range body.Tasks {
+		tasks = append(tasks, row.toTask())
+	}
+
+	return tasks, nil
+}
+
+func (k *openSearchClient) GetTask(ctx context.Context, taskID string) (info *TaskInfo, err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "get_task", nil)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"taskID": taskID,
+	})
+
+	req := opensearchapi.TasksGetRequest{
+		TaskID: taskID,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	var body struct {
+		Completed bool            `json:"completed"`
+		Task      taskRow         `json:"task"`
+		Response  json.RawMessage `json:"response"`
+		Error     json.RawMessage `json:"error"`
+	}
+	if err = decodeResponse(res, &body); err != nil {
+		log.Error(err.Error())
+		return nil, err
+	}
+
+	return &TaskInfo{
+		Completed: body.Completed,
+		Task:      body.Task.toTask(),
+		Response:  body.Response,
+		Error:     body.Error,
+	}, nil
+}
+
+func (k *openSearchClient) CancelTask(ctx context.Context, taskID string) (err error) {
+	var res *opensearchapi.Response
+	ctx, op := k.startOperation(ctx, "cancel_task", nil)
+	defer func() { op.end(res, err) }()
+
+	log := logger.WithContext(ctx, k.log).With(logger.Fields{
+		"taskID": taskID,
+	})
+
+	req := opensearchapi.TasksCancelRequest{
+		TaskID: taskID,
+	}
+
+	res, err = req.Do(ctx, k.client)
+	if err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	var body struct {
+		NodeFailures []taskFailure `json:"node_failures"`
+		TaskFailures []taskFailure `json:"task_failures"`
+	}
+	if err = decodeResponse(res, &body); err != nil {
+		log.Error(err.Error())
+		return err
+	}
+
+	// Failures are reported with a 200 status, e.g. for unknown task IDs.
+	failures := append(body.NodeFailures, body.TaskFailures...)
+	if len(failures) > 0 {
+		err = errors.New("opensearch: failed to cancel task: " + failures[0].Reason)
+		log.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
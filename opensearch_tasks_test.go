@@ -0,0 +1,81 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientListTasks(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_tasks", r.URL.Path)
+		assert.Equal(t, "*reindex", r.URL.Query().Get("actions"))
+		assert.Equal(t, "none", r.URL.Query().Get("group_by"))
+		assert.Equal(t, "true", r.URL.Query().Get("detailed"))
+		_, _ = w.Write([]byte(`{"tasks":[{
+			"node":"n1","id":42,"type":"transport","action":"indices:data/write/reindex",
+			"description":"reindex from [a] to [b]","start_time_in_millis":1700000000000,
+			"running_time_in_nanos":2000000000,"cancellable":true,
+			"status":{"total":100,"created":40}
+		}]}`))
+	})
+
+	got, err := client.ListTasks(context.Background(), TaskFilter{Actions: []string{"*reindex"}, Detailed: true})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	assert.Equal(t, "n1:42", got[0].ID)
+	assert.Equal(t, 2*time.Second, got[0].RunningTime)
+	assert.Equal(t, int64(1700000000000), got[0].StartTime.UnixMilli())
+	assert.True(t, got[0].Cancellable)
+	assert.JSONEq(t, `{"total":100,"created":40}`, string(got[0].Status))
+}
+
+func TestOpenSearchClientGetTask(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/_tasks/n1:42", r.URL.Path)
+		_, _ = w.Write([]byte(`{"completed":true,"task":{"node":"n1","id":42,"action":"indices:data/write/reindex"},"response":{"created":100}}`))
+	})
+
+	got, err := client.GetTask(context.Background(), "n1:42")
+	require.NoError(t, err)
+
+	assert.True(t, got.Completed)
+	assert.Equal(t, "n1:42", got.Task.ID)
+	assert.JSONEq(t, `{"created":100}`, string(got.Response))
+}
+
+func TestOpenSearchClientCancelTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name: "cancelled",
+			body: `{"nodes":{"n1":{"tasks":{"n1:42":{"cancelled":true}}}}}`,
+		},
+		{
+			name:    "node failure",
+			body:    `{"node_failures":[{"type":"failed_node_exception","reason":"task [n1:42] is missing"}]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/_tasks/n1:42/_cancel", r.URL.Path)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			err := client.CancelTask(context.Background(), "n1:42")
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
@@ -1,11 +1,49 @@
 package platigo
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// newTestServer starts a fake cluster that answers the product check and
+// hands every other request to handler.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"version":{"number":"2.5.0","distribution":"opensearch"}}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// newTestClient creates a client pointed at a fake cluster.
+func newTestClient(t *testing.T, config *OSConfig, handler http.HandlerFunc) *openSearchClient {
+	t.Helper()
+
+	srv := newTestServer(t, handler)
+	if config == nil {
+		config = &OSConfig{}
+	}
+	config.Addresses = []string{srv.URL}
+
+	client, err := NewOpenSearchClient(config)
+	require.NoError(t, err)
+
+	return client.(*openSearchClient)
+}
+
 func TestNewOpensearchClient(t *testing.T) {
 	type args struct {
 		config *OSConfig
@@ -34,3 +72,25 @@ func TestNewOpensearchClient(t *testing.T) {
 		})
 	}
 }
+
+// bulkResponse answers a bulk request body with one successful item per
+// action, as the bulk indexer expects as many items as it sent.
+func bulkResponse(body []byte) string {
+	actions := len(strings.Split(strings.TrimSpace(string(body)), "\n")) / 2
+
+	items := make([]string, 0, actions)
+	for i := 0; i < actions; i++ {
+		items = append(items, `{"index":{"status":201}}`)
+	}
+
+	return `{"errors":false,"items":[` + strings.Join(items, ",") + `]}`
+}
+
+type testDoc struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+func (d testDoc) GetID() string {
+	return d.ID
+}
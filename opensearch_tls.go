@@ -0,0 +1,70 @@
+package platigo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+const defaultMinTLSVersion = tls.VersionTLS12
+
+var (
+	errInvalidCACert      = errors.New("no valid certificate found in the CA bundle")
+	errIncompleteKeyPair  = errors.New("client certificate and key must be provided together")
+	errConflictingCACerts = errors.New("only one of CACert and CACertFile can be set")
+)
+
+// buildTLSConfig creates the TLS configuration of the OpenSearch transport.
+// An explicit config.TLSConfig is used as is.
+func buildTLSConfig(config *OSConfig) (*tls.Config, error) {
+	if config.TLSConfig != nil {
+		return config.TLSConfig.Clone(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.InsecureSkipVerify, // #nosec G402
+		MinVersion:         config.MinTLSVersion,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = defaultMinTLSVersion
+	}
+
+	caCert, err := loadCACert(config)
+	if err != nil {
+		return nil, err
+	}
+	if caCert != nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errInvalidCACert
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(config.ClientCert) > 0 || len(config.ClientKey) > 0 {
+		if len(config.ClientCert) == 0 || len(config.ClientKey) == 0 {
+			return nil, errIncompleteKeyPair
+		}
+		cert, err := tls.X509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func loadCACert(config *OSConfig) ([]byte, error) {
+	switch {
+	case len(config.CACert) > 0 && config.CACertFile != "":
+		return nil, errConflictingCACerts
+	case config.CACertFile != "":
+		return os.ReadFile(config.CACertFile)
+	case len(config.CACert) > 0:
+		return config.CACert, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,89 @@
+package platigo
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchClientCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":{"number":"2.5.0","distribution":"opensearch"}}`))
+	}))
+	defer srv.Close()
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	client, err := NewOpenSearchClient(&OSConfig{
+		Addresses: []string{srv.URL},
+		CACert:    caCert,
+	})
+	require.NoError(t, err)
+
+	res, err := client.Ping(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	custom := &tls.Config{MinVersion: tls.VersionTLS13, ServerName: "custom"}
+
+	tests := []struct {
+		name    string
+		config  *OSConfig
+		check   func(t *testing.T, got *tls.Config)
+		wantErr error
+	}{
+		{
+			name:   "defaults",
+			config: &OSConfig{},
+			check: func(t *testing.T, got *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), got.MinVersion)
+				assert.Nil(t, got.RootCAs)
+				assert.False(t, got.InsecureSkipVerify)
+			},
+		},
+		{
+			name:   "custom tls config",
+			config: &OSConfig{TLSConfig: custom, MinTLSVersion: tls.VersionTLS10},
+			check: func(t *testing.T, got *tls.Config) {
+				assert.Equal(t, "custom", got.ServerName)
+				assert.Equal(t, uint16(tls.VersionTLS13), got.MinVersion)
+			},
+		},
+		{
+			name:    "invalid ca",
+			config:  &OSConfig{CACert: []byte("not a cert")},
+			wantErr: errInvalidCACert,
+		},
+		{
+			name:    "both ca sources",
+			config:  &OSConfig{CACert: []byte("x"), CACertFile: "ca.pem"},
+			wantErr: errConflictingCACerts,
+		},
+		{
+			name:    "client cert without key",
+			config:  &OSConfig{ClientCert: []byte("cert")},
+			wantErr: errIncompleteKeyPair,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTLSConfig(tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			tt.check(t, got)
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package platigo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+// ErrInvalidCursor is returned for a cursor not created by EncodeCursor,
+// typically a client error.
+var ErrInvalidCursor = errors.New("pagination: invalid cursor")
+
+var errKeysetColumns = errors.New("pagination: keyset needs valid column names")
+
+var columnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// EncodeCursor returns an opaque cursor holding the sort values of the last
+// item of a page. The same cursors are used for SQL keysets and OpenSearch
+// search_after, so APIs paginate alike whatever the store.
+func EncodeCursor(values ...any) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the sort values of a cursor. Integers are decoded as
+// int64 and other numbers as float64, times come back as strings.
+func DecodeCursor(cursor string) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var values []any
+	if err := decoder.Decode(&values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	for i, value := range values {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if n, err := number.Int64(); err == nil {
+			values[i] = n
+		} else if f, err := number.Float64(); err == nil {
+			values[i] = f
+		}
+	}
+	return values, nil
+}
+
+// Keyset paginates a SQL query over Columns, the last of which must be
+// unique, such as the ID. Unlike OFFSET, a page costs the same however deep
+// it is and does not skip or repeat rows inserted meanwhile.
+//
+//	keyset := platigo.Keyset{Columns: []string{"created_at", "id"}, Desc: true}
+//	where, args, err := keyset.Where(cursor)
+//	query := "SELECT * FROM orders WHERE tenant = ?"
+//	if where != "" {
+//		query += " AND " + where
+//	}
+//	query += " ORDER BY " + keyset.OrderBy() + " LIMIT ?"
+//	err = db.SelectContext(ctx, &orders, db.Rebind(query), append(append([]any{tenant}, args...), limit+1)...)
+//	page, err := platigo.NewPage(orders, limit, func(o Order) []any { return []any{o.CreatedAt, o.ID} })
+type Keyset struct {
+	Columns []string
+	// Desc sorts every column in descending order.
+	Desc bool
+}
+
+// Where returns the condition selecting the rows after cursor, as
+// "(created_at, id) > (?, ?)" with its arguments, or an empty condition
+// for the first page. Placeholders are question marks, to be rebound for
+// Postgres.
+func (k Keyset) Where(cursor string) (string, []any, error) {
+	if err := k.validate(); err != nil {
+		return "", nil, err
+	}
+	if cursor == "" {
+		return "", nil, nil
+	}
+	values, err := DecodeCursor(cursor)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(values) != len(k.Columns) {
+		return "", nil, ErrInvalidCursor
+	}
+
+	operator := ">"
+	if k.Desc {
+		operator = "<"
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	return "(" + strings.Join(k.Columns, ", ") + ") " + operator + " (" + placeholders + ")", values, nil
+}
+
+// OrderBy returns the ORDER BY list of the keyset, e.g. "created_at DESC,
+// id DESC".
+func (k Keyset) OrderBy() string {
+	direction := " ASC"
+	if k.Desc {
+		direction = " DESC"
+	}
+	return strings.Join(k.Columns, direction+", ") + direction
+}
+
+func (k Keyset) validate() error {
+	if len(k.Columns) == 0 {
+		return errKeysetColumns
+	}
+	for _, column := range k.Columns {
+		if !columnName.MatchString(column) {
+			return errKeysetColumns
+		}
+	}
+	return nil
+}
+
+// Page is a page of items and the cursor of the next one, empty on the last
+// page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// NewPage returns the page of items queried with a limit of limit+1: the
+// extra item only tells there is a next page, whose cursor holds the sort
+// values returned by key for the last item kept.
+func NewPage[T any](items []T, limit int, key func(T) []any) (Page[T], error) {
+	if len(items) <= limit {
+		return Page[T]{Items: items}, nil
+	}
+	items = items[:limit]
+	if limit == 0 {
+		return Page[T]{Items: items}, nil
+	}
+	cursor, err := EncodeCursor(key(items[limit-1])...)
+	if err != nil {
+		return Page[T]{}, err
+	}
+	return Page[T]{Items: items, NextCursor: cursor}, nil
+}
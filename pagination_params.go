@@ -0,0 +1,208 @@
+package platigo
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageLimit    = 20
+	defaultPageMaxLimit = 100
+)
+
+// ErrInvalidPageParams is matched by errors.Is for the invalid page, limit,
+// cursor and sort query parameters, typically a client error.
+var ErrInvalidPageParams = errors.New("pagination: invalid parameters")
+
+var errMixedSortDirections = errors.New("pagination: keyset needs a single sort direction")
+
+// PageParamError is returned by PageParams.Parse for an invalid query
+// parameter.
+type PageParamError struct {
+	Param  string
+	Reason string
+}
+
+func (e *PageParamError) Error() string {
+	return fmt.Sprintf("pagination: invalid %s: %s", e.Param, e.Reason)
+}
+
+// Is makes the error match ErrInvalidPageParams.
+func (e *PageParamError) Is(target error) bool {
+	return target == ErrInvalidPageParams
+}
+
+// PageParams parses the page, limit, cursor and sort query parameters of
+// the list endpoints:
+//
+//	params := platigo.PageParams{
+//		SortFields:  map[string]string{"created": "created_at", "total": "total_amount"},
+//		DefaultSort: "-created",
+//		TieBreaker:  "id",
+//	}
+//	page, err := params.Parse(r.URL.Query()) // ?limit=50&sort=-total,created
+//	keyset, err := page.Keyset()
+//	where, args, err := keyset.Where(page.Cursor)
+type PageParams struct {
+	// DefaultLimit is the limit without the limit parameter, 20 by default,
+	// and MaxLimit caps the limit, 100 by default.
+	DefaultLimit int
+	MaxLimit     int
+
+	// SortFields allowlists the values of the sort parameter, a comma
+	// separated list of names prefixed with - for a descending order. They
+	// map to the column or field sorted on. DefaultSort is the sort without
+	// the parameter, in the same syntax.
+	SortFields  map[string]string
+	DefaultSort string
+	// TieBreaker is a unique column or field appended to every sort, in the
+	// direction of the last one, so that keysets and search_after never
+	// skip or repeat items.
+	TieBreaker string
+}
+
+// SortField is a column or field sorted on.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// PageRequest is the page requested by a client, either by Page number or
+// by the Cursor of the previous page.
+type PageRequest struct {
+	// Page is the page number, from 1.
+	Page   int
+	Limit  int
+	Cursor string
+	Sort   []SortField
+}
+
+// Parse returns the page requested by query. Limits above MaxLimit are
+// capped, the other invalid parameters fail with a PageParamError.
+func (p PageParams) Parse(query url.Values) (PageRequest, error) {
+	if p.DefaultLimit <= 0 {
+		p.DefaultLimit = defaultPageLimit
+	}
+	if p.MaxLimit <= 0 {
+		p.MaxLimit = defaultPageMaxLimit
+	}
+
+	req := PageRequest{Page: 1, Limit: min(p.DefaultLimit, p.MaxLimit), Cursor: query.Get("cursor")}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return PageRequest{}, &PageParamError{Param: "limit", Reason: "must be a positive integer"}
+		}
+		req.Limit = min(limit, p.MaxLimit)
+	}
+	if value := query.Get("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return PageRequest{}, &PageParamError{Param: "page", Reason: "must be a positive integer"}
+		}
+		if req.Cursor != "" {
+			return PageRequest{}, &PageParamError{Param: "page", Reason: "cannot be combined with cursor"}
+		}
+		req.Page = page
+	}
+	if req.Cursor != "" {
+		if _, err := DecodeCursor(req.Cursor); err != nil {
+			return PageRequest{}, &PageParamError{Param: "cursor", Reason: "malformed"}
+		}
+	}
+
+	sort := p.DefaultSort
+	if value := query.Get("sort"); value != "" {
+		sort = value
+	}
+	var err error
+	if req.Sort, err = p.parseSort(sort); err != nil {
+		return PageRequest{}, err
+	}
+	return req, nil
+}
+
+func (p PageParams) parseSort(sort string) ([]SortField, error) {
+	var fields []SortField
+	seen := map[string]bool{}
+	for name := range strings.SplitSeq(sort, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+		field, ok := p.SortFields[name]
+		if !ok {
+			return nil, &PageParamError{Param: "sort", Reason: fmt.Sprintf("unknown field %q", name)}
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		fields = append(fields, SortField{Field: field, Desc: desc})
+	}
+
+	if p.TieBreaker != "" && !seen[p.TieBreaker] {
+		desc := len(fields) > 0 && fields[len(fields)-1].Desc
+		fields = append(fields, SortField{Field: p.TieBreaker, Desc: desc})
+	}
+	return fields, nil
+}
+
+// Offset returns the number of items before the page, for OFFSET and from.
+func (r PageRequest) Offset() int {
+	return (r.Page - 1) * r.Limit
+}
+
+// OrderBy returns the SQL ORDER BY list of the sort, e.g. "total_amount
+// DESC, id DESC".
+func (r PageRequest) OrderBy() string {
+	clauses := make([]string, len(r.Sort))
+	for i, field := range r.Sort {
+		clauses[i] = field.Field + " ASC"
+		if field.Desc {
+			clauses[i] = field.Field + " DESC"
+		}
+	}
+	return strings.Join(clauses, ", ")
+}
+
+// Keyset returns the keyset paginating a SQL query in the order of the
+// sort, whose fields must share the same direction.
+func (r PageRequest) Keyset() (Keyset, error) {
+	keyset := Keyset{Columns: make([]string, len(r.Sort))}
+	for i, field := range r.Sort {
+		if i > 0 && field.Desc != keyset.Desc {
+			return Keyset{}, errMixedSortDirections
+		}
+		keyset.Columns[i] = field.Field
+		keyset.Desc = field.Desc
+	}
+	return keyset, keyset.validate()
+}
+
+// SearchSource sets the size, sort and starting hit of the page on source:
+// search_after with a cursor, from otherwise.
+func (r PageRequest) SearchSource(source *SearchSource) (*SearchSource, error) {
+	for _, field := range r.Sort {
+		order := "asc"
+		if field.Desc {
+			order = "desc"
+		}
+		source.Sort(map[string]any{field.Field: order})
+	}
+	source.Size(r.Limit)
+
+	if r.Cursor == "" {
+		return source.From(r.Offset()), nil
+	}
+	values, err := DecodeCursor(r.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return source.SearchAfter(values...), nil
+}
@@ -0,0 +1,120 @@
+package platigo
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageParamsParse(t *testing.T) {
+	cursor, err := EncodeCursor(100, 7)
+	require.NoError(t, err)
+
+	params := PageParams{
+		MaxLimit:    50,
+		SortFields:  map[string]string{"created": "created_at", "total": "total_amount"},
+		DefaultSort: "-created",
+		TieBreaker:  "id",
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		want      PageRequest
+		wantOrder string
+		wantErr   string
+	}{
+		{
+			name:      "defaults",
+			want:      PageRequest{Page: 1, Limit: 20, Sort: []SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}},
+			wantOrder: "created_at DESC, id DESC",
+		},
+		{
+			name:      "page, limit and sort",
+			query:     "page=3&limit=10&sort=total,-created,total",
+			want:      PageRequest{Page: 3, Limit: 10, Sort: []SortField{{Field: "total_amount"}, {Field: "created_at", Desc: true}, {Field: "id", Desc: true}}},
+			wantOrder: "total_amount ASC, created_at DESC, id DESC",
+		},
+		{
+			name:      "limit capped and cursor",
+			query:     "limit=1000&sort=total&cursor=" + cursor,
+			want:      PageRequest{Page: 1, Limit: 50, Cursor: cursor, Sort: []SortField{{Field: "total_amount"}, {Field: "id"}}},
+			wantOrder: "total_amount ASC, id ASC",
+		},
+		{name: "invalid limit", query: "limit=0", wantErr: "pagination: invalid limit: must be a positive integer"},
+		{name: "invalid page", query: "page=abc", wantErr: "pagination: invalid page: must be a positive integer"},
+		{name: "page and cursor", query: "page=2&cursor=" + cursor, wantErr: "pagination: invalid page: cannot be combined with cursor"},
+		{name: "malformed cursor", query: "cursor=!!", wantErr: "pagination: invalid cursor: malformed"},
+		{name: "unknown sort field", query: "sort=-password", wantErr: `pagination: invalid sort: unknown field "password"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			got, err := params.Parse(query)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.ErrorIs(t, err, ErrInvalidPageParams)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOrder, got.OrderBy())
+		})
+	}
+}
+
+func TestPageRequestKeyset(t *testing.T) {
+	cursor, err := EncodeCursor("2026-01-02T03:04:05Z", 42)
+	require.NoError(t, err)
+
+	req := PageRequest{Limit: 20, Cursor: cursor, Sort: []SortField{{Field: "created_at", Desc: true}, {Field: "id", Desc: true}}}
+	keyset, err := req.Keyset()
+	require.NoError(t, err)
+	where, args, err := keyset.Where(req.Cursor)
+	require.NoError(t, err)
+	assert.Equal(t, "(created_at, id) < (?, ?)", where)
+	assert.Equal(t, []any{"2026-01-02T03:04:05Z", int64(42)}, args)
+
+	req.Sort[0].Desc = false
+	_, err = req.Keyset()
+	assert.ErrorIs(t, err, errMixedSortDirections)
+}
+
+func TestPageRequestSearchSource(t *testing.T) {
+	cursor, err := EncodeCursor(100, "doc-7")
+	require.NoError(t, err)
+	sort := []SortField{{Field: "total_amount", Desc: true}, {Field: "id"}}
+
+	tests := []struct {
+		name string
+		req  PageRequest
+		want string
+	}{
+		{
+			name: "offset",
+			req:  PageRequest{Page: 3, Limit: 10, Sort: sort},
+			want: `{"from":20,"size":10,"sort":[{"total_amount":"desc"},{"id":"asc"}]}`,
+		},
+		{
+			name: "search after",
+			req:  PageRequest{Page: 1, Limit: 10, Cursor: cursor, Sort: sort},
+			want: `{"size":10,"sort":[{"total_amount":"desc"},{"id":"asc"}],"search_after":[100,"doc-7"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := tt.req.SearchSource(NewSearchSource())
+			require.NoError(t, err)
+			body, err := json.Marshal(source)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(body))
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package platigo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cursor, err := EncodeCursor(createdAt, int64(9007199254740993), 1.5, "abc")
+	require.NoError(t, err)
+
+	values, err := DecodeCursor(cursor)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"2026-01-02T03:04:05Z", int64(9007199254740993), 1.5, "abc"}, values)
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "e30", "W10"} {
+		_, err := DecodeCursor(cursor)
+		assert.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+}
+
+func TestKeysetWhere(t *testing.T) {
+	cursor, err := EncodeCursor("2026-01-02T03:04:05Z", 42)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		keyset    Keyset
+		cursor    string
+		wantWhere string
+		wantArgs  []any
+		wantOrder string
+		wantErr   error
+	}{
+		{
+			name:      "first page",
+			keyset:    Keyset{Columns: []string{"created_at", "id"}},
+			wantOrder: "created_at ASC, id ASC",
+		},
+		{
+			name:      "ascending",
+			keyset:    Keyset{Columns: []string{"created_at", "id"}},
+			cursor:    cursor,
+			wantWhere: "(created_at, id) > (?, ?)",
+			wantArgs:  []any{"2026-01-02T03:04:05Z", int64(42)},
+			wantOrder: "created_at ASC, id ASC",
+		},
+		{
+			name:      "descending",
+			keyset:    Keyset{Columns: []string{"o.created_at", "o.id"}, Desc: true},
+			cursor:    cursor,
+			wantWhere: "(o.created_at, o.id) < (?, ?)",
+			wantArgs:  []any{"2026-01-02T03:04:05Z", int64(42)},
+			wantOrder: "o.created_at DESC, o.id DESC",
+		},
+		{
+			name:    "cursor of another keyset",
+			keyset:  Keyset{Columns: []string{"id"}},
+			cursor:  cursor,
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "invalid column",
+			keyset:  Keyset{Columns: []string{"id; DROP TABLE orders"}},
+			wantErr: errKeysetColumns,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args, err := tt.keyset.Where(tt.cursor)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWhere, where)
+			assert.Equal(t, tt.wantArgs, args)
+			assert.Equal(t, tt.wantOrder, tt.keyset.OrderBy())
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	type order struct{ ID int64 }
+	key := func(o order) []any { return []any{o.ID} }
+
+	t.Run("last page", func(t *testing.T) {
+		page, err := NewPage([]order{{1}, {2}}, 2, key)
+		require.NoError(t, err)
+		assert.Len(t, page.Items, 2)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("more pages", func(t *testing.T) {
+		page, err := NewPage([]order{{1}, {2}, {3}}, 2, key)
+		require.NoError(t, err)
+		assert.Equal(t, []order{{1}, {2}}, page.Items)
+
+		values, err := DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, []any{int64(2)}, values)
+	})
+}
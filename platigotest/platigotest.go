@@ -0,0 +1,176 @@
+// Package platigotest starts throwaway containers for integration tests and
+// returns the platigo configuration connecting to them:
+//
+//	func TestOrderRepository(t *testing.T) {
+//		db, err := platigo.NewSQLDB(platigotest.Postgres(t))
+//		require.NoError(t, err)
+//		...
+//	}
+//
+// The tests are skipped when Docker is not available, and the containers
+// are removed when they end. Every call starts its own container, which
+// takes seconds, so prefer subtests sharing one to a container per test.
+package platigotest
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo"
+	"github.com/docker/go-connections/nat"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/kafka"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Images of the containers, to be changed before starting them to test
+// against other versions.
+var (
+	PostgresImage   = "postgres:16-alpine"
+	RedisImage      = "redis:7-alpine"
+	KafkaImage      = "confluentinc/confluent-local:7.5.0"
+	OpenSearchImage = "opensearchproject/opensearch:2.11.1"
+)
+
+const (
+	postgresUser     = "platigo"
+	postgresPassword = "platigo"
+	postgresDatabase = "platigo"
+
+	startupTimeout = 2 * time.Minute
+)
+
+// Postgres starts a Postgres server and returns the configuration of a
+// SQLDB connecting to its database.
+func Postgres(t *testing.T) *platigo.DBConfig {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	ctx := context.Background()
+	container, err := postgres.Run(ctx, PostgresImage,
+		postgres.WithUsername(postgresUser),
+		postgres.WithPassword(postgresPassword),
+		postgres.WithDatabase(postgresDatabase),
+		postgres.BasicWaitStrategies(),
+	)
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("platigotest: start postgres: %v", err)
+	}
+
+	host, port := endpoint(t, container, "5432/tcp")
+	return &platigo.DBConfig{
+		Driver:   platigo.DriverPostgres,
+		Host:     host,
+		Port:     port,
+		User:     postgresUser,
+		Password: postgresPassword,
+		Database: postgresDatabase,
+		Params:   map[string]string{"sslmode": "disable"},
+	}
+}
+
+// Redis starts a Redis server and returns the configuration of a
+// RedisClient connecting to it.
+func Redis(t *testing.T) *platigo.RedisConfig {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	container, err := redis.Run(context.Background(), RedisImage)
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("platigotest: start redis: %v", err)
+	}
+
+	host, port := endpoint(t, container, "6379/tcp")
+	return &platigo.RedisConfig{
+		Addresses: []string{net.JoinHostPort(host, strconv.Itoa(port))},
+	}
+}
+
+// Kafka starts a single node Kafka cluster and returns its brokers, for the
+// Brokers of a kafka.ProducerConfig or kafka.ConsumerConfig.
+func Kafka(t *testing.T) []string {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	ctx := context.Background()
+	container, err := kafka.Run(ctx, KafkaImage, kafka.WithClusterID("platigotest"))
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("platigotest: start kafka: %v", err)
+	}
+
+	brokers, err := container.Brokers(ctx)
+	if err != nil {
+		t.Fatalf("platigotest: kafka brokers: %v", err)
+	}
+	return brokers
+}
+
+// OpenSearch starts a single node OpenSearch cluster, without the security
+// plugin, and returns the configuration of an OpenSearchClient connecting
+// to it.
+func OpenSearch(t *testing.T) *platigo.OSConfig {
+	t.Helper()
+	skipWithoutDocker(t)
+
+	container, err := testcontainers.GenericContainer(context.Background(), testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        OpenSearchImage,
+			ExposedPorts: []string{"9200/tcp"},
+			Env: map[string]string{
+				"discovery.type":              "single-node",
+				"DISABLE_SECURITY_PLUGIN":     "true",
+				"DISABLE_INSTALL_DEMO_CONFIG": "true",
+				"OPENSEARCH_JAVA_OPTS":        "-Xms512m -Xmx512m",
+			},
+			WaitingFor: wait.ForHTTP("/_cluster/health?wait_for_status=yellow").
+				WithPort("9200/tcp").
+				WithStartupTimeout(startupTimeout),
+		},
+		Started: true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	if err != nil {
+		t.Fatalf("platigotest: start opensearch: %v", err)
+	}
+
+	host, port := endpoint(t, container, "9200/tcp")
+	return &platigo.OSConfig{
+		Addresses: []string{"http://" + net.JoinHostPort(host, strconv.Itoa(port))},
+	}
+}
+
+// skipWithoutDocker skips the test when no Docker daemon is reachable,
+// which testcontainers reports with a panic when it finds no socket at all.
+func skipWithoutDocker(t *testing.T) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			t.Skipf("platigotest: Docker is not available: %v", r)
+		}
+	}()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+}
+
+// endpoint returns the host and mapped port of a container port.
+func endpoint(t *testing.T, container testcontainers.Container, port string) (string, int) {
+	t.Helper()
+	ctx := context.Background()
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("platigotest: container host: %v", err)
+	}
+	mapped, err := container.MappedPort(ctx, nat.Port(port))
+	if err != nil {
+		t.Fatalf("platigotest: container port %s: %v", port, err)
+	}
+	return host, mapped.Int()
+}
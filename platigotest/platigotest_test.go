@@ -0,0 +1,40 @@
+package platigotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/bagastri07/platigo"
+	"github.com/stretchr/testify/require"
+)
+
+// The tests are skipped without Docker.
+
+func TestPostgres(t *testing.T) {
+	db, err := platigo.NewSQLDB(Postgres(t))
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, db.Ping(context.Background()))
+}
+
+func TestRedis(t *testing.T) {
+	client, err := platigo.NewRedisClient(Redis(t))
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, client.Ping(context.Background()))
+}
+
+func TestKafka(t *testing.T) {
+	client, err := sarama.NewClient(Kafka(t), sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+	require.NotEmpty(t, client.Brokers())
+}
+
+func TestOpenSearch(t *testing.T) {
+	client, err := platigo.NewOpenSearchClient(OpenSearch(t))
+	require.NoError(t, err)
+	_, err = client.Ping(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,204 @@
+// Package probabilistic provides approximate set membership (Bloom filters)
+// and cardinality (HyperLogLog) helpers, backed by Redis to be shared across
+// instances or kept in memory.
+package probabilistic
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var errInvalidBloomConfig = errors.New("probabilistic: capacity must be positive and false positive rate within (0, 1)")
+
+// Filter is a Bloom filter: MightContain never misses an added item, but may
+// report items that were not added with the configured false positive rate.
+type Filter interface {
+	// Add adds items to the filter.
+	Add(ctx context.Context, items ...string) error
+
+	// TestAndAdd adds item and reports whether it was probably present
+	// already, which is what deduplication needs in a single round trip.
+	TestAndAdd(ctx context.Context, item string) (bool, error)
+
+	// MightContain reports whether item was probably added.
+	MightContain(ctx context.Context, item string) (bool, error)
+}
+
+// BloomConfig sizes a Bloom filter.
+type BloomConfig struct {
+	// Capacity is the number of items expected. The false positive rate
+	// rises above FalsePositiveRate once it is exceeded.
+	Capacity uint64
+	// FalsePositiveRate is the target rate, e.g. 0.01.
+	FalsePositiveRate float64
+}
+
+// bloomParams returns the optimal number of bits and hash functions.
+func (c BloomConfig) bloomParams() (bits uint64, hashes int, err error) {
+	if c.Capacity == 0 || c.FalsePositiveRate <= 0 || c.FalsePositiveRate >= 1 {
+		return 0, 0, errInvalidBloomConfig
+	}
+
+	n := float64(c.Capacity)
+	m := math.Ceil(-n * math.Log(c.FalsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := math.Max(1, math.Round(m/n*math.Ln2))
+
+	return uint64(m), int(k), nil
+}
+
+// bitPositions returns the bits of item using double hashing.
+func bitPositions(item string, bits uint64, hashes int) []uint64 {
+	sum := xxhash.Sum64String(item)
+	h1, h2 := sum&math.MaxUint32, sum>>32
+
+	positions := make([]uint64, hashes)
+	for i := range positions {
+		positions[i] = (h1 + uint64(i)*h2) % bits
+	}
+	return positions
+}
+
+// bloomAddScript sets the given bits and returns how many were unset.
+var bloomAddScript = redis.NewScript(`
+local added = 0
+for i = 1, #ARGV do
+	if redis.call("SETBIT", KEYS[1], ARGV[i], 1) == 0 then
+		added = added + 1
+	end
+end
+return added
+`)
+
+type redisBloom struct {
+	client redis.UniversalClient
+	key    string
+	bits   uint64
+	hashes int
+}
+
+// NewRedisBloom creates a Filter stored as a Redis bitmap under key. Every
+// instance must use the same config for a key.
+func NewRedisBloom(client redis.UniversalClient, key string, config BloomConfig) (Filter, error) {
+	bits, hashes, err := config.bloomParams()
+	if err != nil {
+		return nil, err
+	}
+	return &redisBloom{client: client, key: key, bits: bits, hashes: hashes}, nil
+}
+
+func (f *redisBloom) Add(ctx context.Context, items ...string) error {
+	var args []any
+	for _, item := range items {
+		for _, pos := range bitPositions(item, f.bits, f.hashes) {
+			args = append(args, strconv.FormatUint(pos, 10))
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	return bloomAddScript.Run(ctx, f.client, []string{f.key}, args...).Err()
+}
+
+func (f *redisBloom) TestAndAdd(ctx context.Context, item string) (bool, error) {
+	positions := bitPositions(item, f.bits, f.hashes)
+	args := make([]any, len(positions))
+	for i, pos := range positions {
+		args[i] = strconv.FormatUint(pos, 10)
+	}
+
+	added, err := bloomAddScript.Run(ctx, f.client, []string{f.key}, args...).Int()
+	if err != nil {
+		return false, err
+	}
+	return added == 0, nil
+}
+
+func (f *redisBloom) MightContain(ctx context.Context, item string) (bool, error) {
+	positions := bitPositions(item, f.bits, f.hashes)
+	cmds := make([]*redis.IntCmd, len(positions))
+	_, err := f.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, pos := range positions {
+			cmds[i] = pipe.GetBit(ctx, f.key, int64(pos))
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, cmd := range cmds {
+		if cmd.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+type memoryBloom struct {
+	mu     sync.RWMutex
+	words  []uint64
+	bits   uint64
+	hashes int
+}
+
+// NewMemoryBloom creates a process-local Filter.
+func NewMemoryBloom(config BloomConfig) (Filter, error) {
+	bits, hashes, err := config.bloomParams()
+	if err != nil {
+		return nil, err
+	}
+	return &memoryBloom{
+		words:  make([]uint64, (bits+63)/64),
+		bits:   bits,
+		hashes: hashes,
+	}, nil
+}
+
+func (f *memoryBloom) Add(_ context.Context, items ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, item := range items {
+		f.set(item)
+	}
+	return nil
+}
+
+func (f *memoryBloom) TestAndAdd(_ context.Context, item string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.set(item) == 0, nil
+}
+
+func (f *memoryBloom) MightContain(_ context.Context, item string) (bool, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, pos := range bitPositions(item, f.bits, f.hashes) {
+		if f.words[pos/64]&(1<<(pos%64)) == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// set sets the bits of item and returns how many were unset. The lock must
+// be held.
+func (f *memoryBloom) set(item string) int {
+	added := 0
+	for _, pos := range bitPositions(item, f.bits, f.hashes) {
+		mask := uint64(1) << (pos % 64)
+		if f.words[pos/64]&mask == 0 {
+			f.words[pos/64] |= mask
+			added++
+		}
+	}
+	return added
+}
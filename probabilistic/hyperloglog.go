@@ -0,0 +1,100 @@
+package probabilistic
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// hllPrecision matches Redis: 2^14 registers for a standard error of 0.81%.
+const hllPrecision = 14
+
+// Counter counts distinct items approximately, in constant memory.
+type Counter interface {
+	// Add adds items to the counter.
+	Add(ctx context.Context, items ...string) error
+
+	// Count returns the approximate number of distinct items added.
+	Count(ctx context.Context) (uint64, error)
+}
+
+type redisHyperLogLog struct {
+	client redis.UniversalClient
+	key    string
+}
+
+// NewRedisHyperLogLog creates a Counter stored as a Redis HyperLogLog under
+// key.
+func NewRedisHyperLogLog(client redis.UniversalClient, key string) Counter {
+	return &redisHyperLogLog{client: client, key: key}
+}
+
+func (c *redisHyperLogLog) Add(ctx context.Context, items ...string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	args := make([]any, len(items))
+	for i, item := range items {
+		args[i] = item
+	}
+	return c.client.PFAdd(ctx, c.key, args...).Err()
+}
+
+func (c *redisHyperLogLog) Count(ctx context.Context) (uint64, error) {
+	n, err := c.client.PFCount(ctx, c.key).Result()
+	return uint64(n), err
+}
+
+type memoryHyperLogLog struct {
+	mu        sync.Mutex
+	registers [1 << hllPrecision]uint8
+}
+
+// NewMemoryHyperLogLog creates a process-local Counter using 16KB.
+func NewMemoryHyperLogLog() Counter {
+	return &memoryHyperLogLog{}
+}
+
+func (c *memoryHyperLogLog) Add(_ context.Context, items ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, item := range items {
+		hash := xxhash.Sum64String(item)
+		index := hash >> (64 - hllPrecision)
+		// The sentinel bit caps the rank when the remaining bits are zero.
+		rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1)) + 1)
+		if rank > c.registers[index] {
+			c.registers[index] = rank
+		}
+	}
+	return nil
+}
+
+func (c *memoryHyperLogLog) Count(context.Context) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := float64(len(c.registers))
+	sum := 0.0
+	zeros := 0
+	for _, r := range c.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	// Small ranges are estimated by linear counting.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(math.Round(estimate)), nil
+}
@@ -0,0 +1,107 @@
+package probabilistic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestBloomConfig(t *testing.T) {
+	bits, hashes, err := BloomConfig{Capacity: 1000, FalsePositiveRate: 0.01}.bloomParams()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9586), bits)
+	assert.Equal(t, 7, hashes)
+
+	for _, config := range []BloomConfig{
+		{Capacity: 0, FalsePositiveRate: 0.01},
+		{Capacity: 1000, FalsePositiveRate: 0},
+		{Capacity: 1000, FalsePositiveRate: 1},
+	} {
+		_, _, err = config.bloomParams()
+		assert.ErrorIs(t, err, errInvalidBloomConfig, "%+v", config)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	ctx := context.Background()
+	config := BloomConfig{Capacity: 1000, FalsePositiveRate: 0.01}
+
+	redisBloom, err := NewRedisBloom(newTestRedis(t), "seen-events", config)
+	require.NoError(t, err)
+	memoryBloom, err := NewMemoryBloom(config)
+	require.NoError(t, err)
+
+	for name, filter := range map[string]Filter{"redis": redisBloom, "memory": memoryBloom} {
+		t.Run(name, func(t *testing.T) {
+			var items []string
+			for i := 0; i < 1000; i++ {
+				items = append(items, fmt.Sprintf("event-%d", i))
+			}
+			require.NoError(t, filter.Add(ctx, items...))
+
+			for _, item := range items {
+				ok, err := filter.MightContain(ctx, item)
+				require.NoError(t, err)
+				require.True(t, ok, item)
+			}
+
+			falsePositives := 0
+			for i := 0; i < 1000; i++ {
+				ok, err := filter.MightContain(ctx, fmt.Sprintf("other-%d", i))
+				require.NoError(t, err)
+				if ok {
+					falsePositives++
+				}
+			}
+			assert.Less(t, falsePositives, 30)
+
+			seen, err := filter.TestAndAdd(ctx, "fresh")
+			require.NoError(t, err)
+			assert.False(t, seen)
+			seen, err = filter.TestAndAdd(ctx, "fresh")
+			require.NoError(t, err)
+			assert.True(t, seen)
+		})
+	}
+}
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	counters := map[string]Counter{
+		"redis":  NewRedisHyperLogLog(newTestRedis(t), "visitors"),
+		"memory": NewMemoryHyperLogLog(),
+	}
+
+	for name, counter := range counters {
+		t.Run(name, func(t *testing.T) {
+			n, err := counter.Count(ctx)
+			require.NoError(t, err)
+			assert.Zero(t, n)
+
+			for i := 0; i < 20000; i++ {
+				// Every visitor shows up twice.
+				visitor := fmt.Sprintf("visitor-%d", i%10000)
+				require.NoError(t, counter.Add(ctx, visitor))
+			}
+
+			n, err = counter.Count(ctx)
+			require.NoError(t, err)
+			assert.InEpsilon(t, 10000, float64(n), 0.03)
+		})
+	}
+}
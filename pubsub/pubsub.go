@@ -0,0 +1,189 @@
+// Package pubsub is a thin typed layer over Redis pub/sub for lightweight
+// fan-out notifications. Messages are JSON encoded and delivered at most
+// once: subscribers that are down miss them.
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	errNoHandlers     = errors.New("pubsub: no handlers registered")
+	errAlreadyRunning = errors.New("pubsub: subscriber is already running")
+)
+
+// Handler processes a message published on channel.
+type Handler[T any] func(ctx context.Context, channel string, msg T) error
+
+// Publisher publishes JSON encoded messages.
+type Publisher struct {
+	client redis.UniversalClient
+}
+
+// NewPublisher creates a Publisher.
+func NewPublisher(client redis.UniversalClient) *Publisher {
+	return &Publisher{client: client}
+}
+
+// Publish encodes msg and publishes it on channel.
+func (p *Publisher) Publish(ctx context.Context, channel string, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, channel, data).Err()
+}
+
+// PublishBatch encodes msgs and publishes them on channel, in order, in a
+// single pipeline. The messages failing to encode or publish are reported
+// with a *messaging.BatchError, indexed in msgs.
+func (p *Publisher) PublishBatch(ctx context.Context, channel string, msgs []any) error {
+	var errs []messaging.MessageError
+	indexes := make([]int, 0, len(msgs))
+	pipe := p.client.Pipeline()
+	for i, msg := range msgs {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			errs = append(errs, messaging.MessageError{Index: i, Err: err})
+			continue
+		}
+		pipe.Publish(ctx, channel, data)
+		indexes = append(indexes, i)
+	}
+
+	if len(indexes) > 0 {
+		cmds, err := pipe.Exec(ctx)
+		if err != nil && len(cmds) == 0 {
+			for _, i := range indexes {
+				errs = append(errs, messaging.MessageError{Index: i, Err: err})
+			}
+		}
+		for j, cmd := range cmds {
+			if err := cmd.Err(); err != nil {
+				errs = append(errs, messaging.MessageError{Index: indexes[j], Err: err})
+			}
+		}
+	}
+	return messaging.NewBatchError(errs)
+}
+
+// Option customizes a Subscriber.
+type Option func(*Subscriber)
+
+// WithLogger sets the logger receiving handler and decoding failures.
+func WithLogger(log logger.Logger) Option {
+	return func(s *Subscriber) {
+		s.log = log
+	}
+}
+
+type rawHandler func(ctx context.Context, channel string, payload []byte) error
+
+// Subscriber dispatches the messages of its channels to their handlers, one
+// message at a time in publication order. The connection is re-established
+// and channels resubscribed automatically when it drops.
+type Subscriber struct {
+	client   redis.UniversalClient
+	log      logger.Logger
+	mu       sync.Mutex
+	handlers map[string]rawHandler
+	running  bool
+}
+
+// NewSubscriber creates a Subscriber. Register handlers with Handle before
+// calling Run.
+func NewSubscriber(client redis.UniversalClient, opts ...Option) *Subscriber {
+	s := &Subscriber{
+		client:   client,
+		log:      logger.Nop(),
+		handlers: map[string]rawHandler{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Handle registers handler for the messages of channel, decoded into T.
+// Registering a channel again replaces its handler.
+func Handle[T any](s *Subscriber, channel string, handler Handler[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[channel] = func(ctx context.Context, channel string, payload []byte) error {
+		var msg T
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			return err
+		}
+		return handler(ctx, channel, msg)
+	}
+}
+
+// Run subscribes to the registered channels and dispatches messages until ctx
+// is done. It returns once the message being handled, if any, is done.
+func (s *Subscriber) Run(ctx context.Context) error {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return errAlreadyRunning
+	}
+	if len(s.handlers) == 0 {
+		s.mu.Unlock()
+		return errNoHandlers
+	}
+	s.running = true
+	handlers := make(map[string]rawHandler, len(s.handlers))
+	channels := make([]string, 0, len(s.handlers))
+	for channel, handler := range s.handlers {
+		handlers[channel] = handler
+		channels = append(channels, channel)
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	sub := s.client.Subscribe(ctx, channels...)
+	defer sub.Close()
+
+	// Wait for the subscription to be confirmed so messages published once
+	// Run is up are not lost.
+	if _, err := sub.Receive(ctx); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	messages := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			s.dispatch(ctx, handlers[msg.Channel], msg)
+		}
+	}
+}
+
+func (s *Subscriber) dispatch(ctx context.Context, handler rawHandler, msg *redis.Message) {
+	if handler == nil {
+		return
+	}
+	if err := handler(ctx, msg.Channel, []byte(msg.Payload)); err != nil {
+		s.log.With(logger.Fields{"channel": msg.Channel}).Error(err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type orderCreated struct {
+	OrderID string `json:"order_id"`
+}
+
+// errorLogger records the messages logged at error level.
+type errorLogger struct {
+	logger.Logger
+	mu     sync.Mutex
+	errors []string
+}
+
+func (l *errorLogger) With(logger.Fields) logger.Logger { return l }
+
+func (l *errorLogger) Error(msg string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, msg)
+}
+
+func (l *errorLogger) Errors() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.errors...)
+}
+
+func newTestRedis(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// waitSubscribed blocks until channel has a subscriber.
+func waitSubscribed(t *testing.T, client redis.UniversalClient, channel string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		counts, err := client.PubSubNumSub(context.Background(), channel).Result()
+		return err == nil && counts[channel] > 0
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSubscriber(t *testing.T) {
+	client := newTestRedis(t)
+	log := &errorLogger{Logger: logger.Nop()}
+
+	received := make(chan orderCreated, 1)
+	sub := NewSubscriber(client, WithLogger(log))
+	Handle(sub, "orders", func(_ context.Context, channel string, msg orderCreated) error {
+		assert.Equal(t, "orders", channel)
+		if msg.OrderID == "fail" {
+			return errors.New("handler failed")
+		}
+		received <- msg
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sub.Run(ctx) }()
+	waitSubscribed(t, client, "orders")
+
+	assert.ErrorIs(t, sub.Run(ctx), errAlreadyRunning)
+
+	pub := NewPublisher(client)
+	require.NoError(t, client.Publish(context.Background(), "orders", "not json").Err())
+	require.NoError(t, pub.Publish(context.Background(), "orders", orderCreated{OrderID: "fail"}))
+	require.NoError(t, pub.Publish(context.Background(), "orders", orderCreated{OrderID: "42"}))
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, orderCreated{OrderID: "42"}, msg)
+	case <-time.After(time.Second):
+		t.Fatal("message not received")
+	}
+	assert.Len(t, log.Errors(), 2)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not stop")
+	}
+}
+
+func TestSubscriberWithoutHandlers(t *testing.T) {
+	sub := NewSubscriber(newTestRedis(t))
+	assert.ErrorIs(t, sub.Run(context.Background()), errNoHandlers)
+}
+
+func TestPublisherPublishBatch(t *testing.T) {
+	client := newTestRedis(t)
+	sub := client.Subscribe(context.Background(), "orders")
+	defer sub.Close()
+	_, err := sub.Receive(context.Background())
+	require.NoError(t, err)
+
+	pub := NewPublisher(client)
+	err = pub.PublishBatch(context.Background(), "orders", []any{
+		orderCreated{OrderID: "1"},
+		make(chan int),
+		orderCreated{OrderID: "2"},
+	})
+	var batchErr *messaging.BatchError
+	require.ErrorAs(t, err, &batchErr)
+	require.Len(t, batchErr.Errors, 1)
+	assert.Equal(t, 1, batchErr.Errors[0].Index)
+
+	for _, want := range []string{`{"order_id":"1"}`, `{"order_id":"2"}`} {
+		select {
+		case msg := <-sub.Channel():
+			assert.Equal(t, want, msg.Payload)
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// maxLocalBuckets is the number of buckets above which the full ones, which
+// behave like missing ones, are dropped.
+const maxLocalBuckets = 10000
+
+type localBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+type localTokenBucket struct {
+	limit Limit
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*localBucket
+}
+
+// NewLocalTokenBucket creates an in-memory Limiter with the semantics of
+// NewTokenBucket, for quotas enforced by a single process. It panics when
+// limit.Rate or limit.Period is not positive.
+func NewLocalTokenBucket(limit Limit) Limiter {
+	limit.mustValidate()
+	return &localTokenBucket{
+		limit:   limit,
+		now:     time.Now,
+		buckets: map[string]*localBucket{},
+	}
+}
+
+func (b *localTokenBucket) Allow(_ context.Context, key string) (Result, error) {
+	burst := float64(b.limit.burst())
+	rate := float64(b.limit.Rate) / float64(b.limit.Period)
+	now := b.now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		if len(b.buckets) >= maxLocalBuckets {
+			b.dropFull(now, rate, burst)
+		}
+		bucket = &localBucket{tokens: burst, ts: now}
+		b.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+float64(max(now.Sub(bucket.ts), 0))*rate)
+	bucket.ts = now
+
+	result := Result{Limit: int(burst)}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((1 - bucket.tokens) / rate))
+	}
+	result.Remaining = int(bucket.tokens)
+	return result, nil
+}
+
+func (b *localTokenBucket) dropFull(now time.Time, rate, burst float64) {
+	for key, bucket := range b.buckets {
+		if bucket.tokens+float64(now.Sub(bucket.ts))*rate >= burst {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalTokenBucket(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	limiter := NewLocalTokenBucket(Limit{Rate: 1, Period: time.Second, Burst: 2})
+	limiter.(*localTokenBucket).now = c.Now
+
+	runSteps(t, limiter, c, []step{
+		{want: Result{Allowed: true, Limit: 2, Remaining: 1}},
+		{want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{want: Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: time.Second}},
+		{advance: 500 * time.Millisecond, want: Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 500 * time.Millisecond}},
+		{advance: 500 * time.Millisecond, want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{advance: 10 * time.Second, want: Result{Allowed: true, Limit: 2, Remaining: 1}},
+	})
+}
+
+func TestLocalTokenBucketDropsFullBuckets(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	limiter := NewLocalTokenBucket(PerSecond(10)).(*localTokenBucket)
+	limiter.now = c.Now
+
+	for i := range maxLocalBuckets {
+		_, err := limiter.Allow(context.Background(), time.Duration(i).String())
+		require.NoError(t, err)
+	}
+	c.Advance(time.Second)
+	_, err := limiter.Allow(context.Background(), "new")
+	require.NoError(t, err)
+	assert.Len(t, limiter.buckets, 1)
+}
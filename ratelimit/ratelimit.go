@@ -0,0 +1,84 @@
+// Package ratelimit implements Redis-backed rate limiters shared by every
+// replica of a service, and an in-memory one for a single process.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Limit is the quota enforced per key: Rate requests every Period. Both must
+// be positive; the constructors of the limiters panic otherwise.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	// Burst is the bucket capacity of a token bucket, Rate by default. The
+	// sliding window ignores it.
+	Burst int
+}
+
+// PerSecond returns a Limit of rate requests per second.
+func PerSecond(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Second}
+}
+
+// PerMinute returns a Limit of rate requests per minute.
+func PerMinute(rate int) Limit {
+	return Limit{Rate: rate, Period: time.Minute}
+}
+
+// mustValidate panics when l cannot be enforced: a zero Rate or Period
+// makes the refill rate infinite or not a number.
+func (l Limit) mustValidate() {
+	if l.Rate <= 0 || l.Period <= 0 {
+		panic(fmt.Sprintf("ratelimit: invalid limit of %d requests every %s", l.Rate, l.Period))
+	}
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// Result is the outcome of Allow.
+type Result struct {
+	Allowed bool
+	// Limit is the maximum number of requests allowed at once.
+	Limit int
+	// Remaining is the number of requests still allowed right now.
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed. It
+	// is zero when the request was allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a request identified by key is allowed.
+type Limiter interface {
+	// Allow consumes one request of the quota of key.
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// Option customizes a Limiter.
+type Option func(*options)
+
+type options struct {
+	prefix string
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{prefix: "ratelimit:"}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPrefix sets the prefix of the Redis keys, "ratelimit:" by default.
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) redis.UniversalClient {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// clock is a manually advanced time source.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+type step struct {
+	advance time.Duration
+	want    Result
+}
+
+func runSteps(t *testing.T, limiter Limiter, c *clock, steps []step) {
+	t.Helper()
+
+	for i, s := range steps {
+		c.Advance(s.advance)
+		got, err := limiter.Allow(context.Background(), "client-1")
+		require.NoError(t, err)
+		assert.Equal(t, s.want, got, "step %d", i)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	limiter := NewTokenBucket(newTestRedis(t), Limit{Rate: 1, Period: time.Second, Burst: 2})
+	limiter.(*tokenBucket).now = c.Now
+
+	runSteps(t, limiter, c, []step{
+		{want: Result{Allowed: true, Limit: 2, Remaining: 1}},
+		{want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{want: Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: time.Second}},
+		{advance: 500 * time.Millisecond, want: Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 500 * time.Millisecond}},
+		{advance: 500 * time.Millisecond, want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{advance: 10 * time.Second, want: Result{Allowed: true, Limit: 2, Remaining: 1}},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	c := &clock{now: time.Unix(1700000000, 0)}
+	limiter := NewSlidingWindow(newTestRedis(t), PerMinute(2))
+	limiter.(*slidingWindow).now = c.Now
+
+	runSteps(t, limiter, c, []step{
+		{want: Result{Allowed: true, Limit: 2, Remaining: 1}},
+		{advance: 20 * time.Second, want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{advance: 20 * time.Second, want: Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 20 * time.Second}},
+		{advance: 20 * time.Second, want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+		{advance: 40 * time.Second, want: Result{Allowed: true, Limit: 2, Remaining: 0}},
+	})
+}
+
+func TestLimiterKeysAreIsolated(t *testing.T) {
+	ctx := context.Background()
+	limiter := NewSlidingWindow(newTestRedis(t), PerSecond(1), WithPrefix("api:"))
+
+	first, err := limiter.Allow(ctx, "a")
+	require.NoError(t, err)
+	second, err := limiter.Allow(ctx, "b")
+	require.NoError(t, err)
+
+	assert.True(t, first.Allowed)
+	assert.True(t, second.Allowed)
+}
+
+func TestLimiterInvalidLimit(t *testing.T) {
+	client := newTestRedis(t)
+
+	tests := []struct {
+		name  string
+		limit Limit
+	}{
+		{name: "zero rate", limit: Limit{Period: time.Second}},
+		{name: "negative rate", limit: Limit{Rate: -1, Period: time.Second}},
+		{name: "zero period", limit: Limit{Rate: 1}},
+		{name: "negative period", limit: Limit{Rate: 1, Period: -time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Panics(t, func() { NewTokenBucket(client, tt.limit) })
+			assert.Panics(t, func() { NewSlidingWindow(client, tt.limit) })
+			assert.Panics(t, func() { NewLocalTokenBucket(tt.limit) })
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript keeps one sorted set member per allowed request, scored
+// by its time in microseconds, and drops the ones older than the window.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+
+local count = redis.call("ZCARD", KEYS[1])
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], math.ceil(window / 1000))
+	return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {0, 0, tonumber(oldest[2]) + window - now}
+`)
+
+type slidingWindow struct {
+	client redis.UniversalClient
+	limit  Limit
+	prefix string
+	now    func() time.Time
+}
+
+// NewSlidingWindow creates a Limiter allowing at most limit.Rate requests in
+// any window of limit.Period. It is exact, at the cost of storing one entry
+// per allowed request. It panics when limit.Rate or limit.Period is not
+// positive.
+func NewSlidingWindow(client redis.UniversalClient, limit Limit, opts ...Option) Limiter {
+	limit.mustValidate()
+	o := newOptions(opts)
+	return &slidingWindow{
+		client: client,
+		limit:  limit,
+		prefix: o.prefix,
+		now:    time.Now,
+	}
+}
+
+func (w *slidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	now := w.now().UnixMicro()
+	// Members must be unique even for requests in the same microsecond.
+	member := strconv.FormatInt(now, 10) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	values, err := slidingWindowScript.Run(ctx, w.client, []string{w.prefix + key},
+		w.limit.Rate,
+		w.limit.Period.Microseconds(),
+		now,
+		member,
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      w.limit.Rate,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the bucket for the time elapsed since the last
+// call and takes a token from it. Times are in microseconds.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or burst
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate / 1000) + 1000)
+
+return {allowed, math.floor(tokens), retry}
+`)
+
+type tokenBucket struct {
+	client redis.UniversalClient
+	limit  Limit
+	prefix string
+	now    func() time.Time
+}
+
+// NewTokenBucket creates a Limiter refilling limit.Rate tokens every
+// limit.Period into a bucket holding up to limit.Burst tokens, so short
+// bursts are allowed while the average rate is enforced. It panics when
+// limit.Rate or limit.Period is not positive.
+func NewTokenBucket(client redis.UniversalClient, limit Limit, opts ...Option) Limiter {
+	limit.mustValidate()
+	o := newOptions(opts)
+	return &tokenBucket{
+		client: client,
+		limit:  limit,
+		prefix: o.prefix,
+		now:    time.Now,
+	}
+}
+
+func (b *tokenBucket) Allow(ctx context.Context, key string) (Result, error) {
+	burst := b.limit.burst()
+
+	values, err := tokenBucketScript.Run(ctx, b.client, []string{b.prefix + key},
+		b.limit.Rate,
+		b.limit.Period.Microseconds(),
+		burst,
+		b.now().UnixMicro(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,267 @@
+package platigo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/bagastri07/platigo/health"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrKeyNotFound is returned by RedisClient.Get when the key does not exist.
+var ErrKeyNotFound = errors.New("redis: key not found")
+
+var (
+	errRedisAddressRequired = errors.New("redis: at least one address is required")
+	errRedisClusterDB       = errors.New("redis: cluster mode only supports DB 0")
+)
+
+type RedisConfig struct {
+	// Addresses lists the host:port of the server. With more than one
+	// address or ClusterMode they are the seed nodes of a Redis Cluster, and
+	// with MasterName the sentinels.
+	Addresses []string
+	Username  string
+	Password  string
+	// DB selects the database. Redis Cluster only has database 0.
+	DB int
+
+	// ClusterMode connects to a Redis Cluster through a single address, such
+	// as the configuration endpoint of ElastiCache.
+	ClusterMode bool
+	// MaxRedirects is the number of MOVED/ASK redirects followed in cluster
+	// mode, 3 by default.
+	MaxRedirects int
+
+	// MasterName enables sentinel failover: the sentinels at Addresses are
+	// asked for the current master of MasterName.
+	MasterName       string
+	SentinelUsername string
+	SentinelPassword string
+
+	// ReadOnly sends read-only commands to replicas, in cluster and sentinel
+	// mode. RouteByLatency and RouteRandomly pick the replica by latency or
+	// at random instead of the first available one.
+	ReadOnly       bool
+	RouteByLatency bool
+	RouteRandomly  bool
+
+	// TLSConfig enables TLS when set.
+	TLSConfig *tls.Config
+
+	// PoolSize is the maximum number of connections, 10 per CPU by default.
+	PoolSize     int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// SlowThreshold logs a warning for every command or pipeline lasting
+	// longer, with its command names and key. Not set by default.
+	SlowThreshold time.Duration
+
+	// Health registers a readiness check pinging the server, named
+	// HealthName, "redis" by default.
+	Health     *health.Registry
+	HealthName string
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+	// LogLevel is the minimum level passed to Logger. Every command is logged
+	// at DebugLevel, so the default InfoLevel only keeps failures.
+	LogLevel logger.Level
+}
+
+type RedisClient interface {
+	// Get returns the value of key, or ErrKeyNotFound.
+	Get(ctx context.Context, key string) (string, error)
+
+	// Set sets the value of key. A zero ttl keeps the key forever.
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+
+	// SetNX sets the value of key only if it does not exist yet, and reports
+	// whether it was set.
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+
+	// Delete deletes keys and returns how many existed.
+	Delete(ctx context.Context, keys ...string) (int64, error)
+
+	// Exists returns how many of keys exist.
+	Exists(ctx context.Context, keys ...string) (int64, error)
+
+	// Expire sets the time to live of key and reports whether it exists.
+	Expire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+
+	// Ping pings the Redis server to check its availability.
+	Ping(ctx context.Context) error
+
+	// Client returns the underlying go-redis client for the commands not
+	// covered by RedisClient.
+	Client() redis.UniversalClient
+
+	// Close closes the connections.
+	Close() error
+}
+
+type redisClient struct {
+	client redis.UniversalClient
+}
+
+// NewRedisClient creates a new RedisClient instance.
+func NewRedisClient(config *RedisConfig) (RedisClient, error) {
+	if len(config.Addresses) == 0 {
+		return nil, errRedisAddressRequired
+	}
+	if config.DB != 0 && config.MasterName == "" && (config.ClusterMode || len(config.Addresses) > 1) {
+		return nil, errRedisClusterDB
+	}
+
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:        config.Addresses,
+		Username:     config.Username,
+		Password:     config.Password,
+		DB:           config.DB,
+		TLSConfig:    config.TLSConfig,
+		PoolSize:     config.PoolSize,
+		DialTimeout:  config.DialTimeout,
+		ReadTimeout:  config.ReadTimeout,
+		WriteTimeout: config.WriteTimeout,
+
+		IsClusterMode: config.ClusterMode,
+		MaxRedirects:  config.MaxRedirects,
+
+		MasterName:       config.MasterName,
+		SentinelUsername: config.SentinelUsername,
+		SentinelPassword: config.SentinelPassword,
+
+		ReadOnly:       config.ReadOnly,
+		RouteByLatency: config.RouteByLatency,
+		RouteRandomly:  config.RouteRandomly,
+	})
+
+	log := logger.WithLevel(config.Logger, config.LogLevel)
+	client.AddHook(&redisLogHook{log: log, logLevel: config.LogLevel, slow: newSlowLog(log, config.SlowThreshold)})
+
+	c := &redisClient{client: client}
+	if config.Health != nil {
+		name := config.HealthName
+		if name == "" {
+			name = "redis"
+		}
+		config.Health.Register(name, RedisPingCheck(c))
+	}
+	return c, nil
+}
+
+func (r *redisClient) Get(ctx context.Context, key string) (string, error) {
+	value, err := r.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", ErrKeyNotFound
+	}
+	return value, err
+}
+
+func (r *redisClient) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+func (r *redisClient) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	return r.client.SetNX(ctx, key, value, ttl).Result()
+}
+
+func (r *redisClient) Delete(ctx context.Context, keys ...string) (int64, error) {
+	return r.client.Del(ctx, keys...).Result()
+}
+
+func (r *redisClient) Exists(ctx context.Context, keys ...string) (int64, error) {
+	return r.client.Exists(ctx, keys...).Result()
+}
+
+func (r *redisClient) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.client.Expire(ctx, key, ttl).Result()
+}
+
+func (r *redisClient) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *redisClient) Client() redis.UniversalClient {
+	return r.client
+}
+
+func (r *redisClient) Close() error {
+	return r.client.Close()
+}
+
+// redisLogHook logs every command at debug level and failed ones at error
+// level. Missing keys are not failures. Only the command name and key are
+// logged, never the values.
+type redisLogHook struct {
+	log      logger.Logger
+	logLevel logger.Level
+	slow     slowLog
+}
+
+func (h *redisLogHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			h.log.With(logger.Fields{"addr": addr}).Error(err.Error())
+		}
+		return conn, err
+	}
+}
+
+func (h *redisLogHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		started := time.Now()
+		err := next(ctx, cmd)
+		elapsed := time.Since(started)
+		h.logCmd(ctx, cmd, err, elapsed)
+		h.slow.observe(ctx, elapsed, "Slow Redis command", redisCmdFields(cmd))
+		return err
+	}
+}
+
+func (h *redisLogHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		started := time.Now()
+		err := next(ctx, cmds)
+		elapsed := time.Since(started)
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			h.logCmd(ctx, cmd, cmd.Err(), elapsed)
+			names[i] = cmd.Name()
+		}
+		h.slow.observe(ctx, elapsed, "Slow Redis pipeline", logger.Fields{"commands": names})
+		return err
+	}
+}
+
+func (h *redisLogHook) logCmd(ctx context.Context, cmd redis.Cmder, err error, elapsed time.Duration) {
+	log := logger.WithContext(ctx, h.log)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		log.With(logger.Fields{"command": cmd.Name()}).Error(err.Error())
+		return
+	}
+	if h.logLevel > logger.DebugLevel {
+		return
+	}
+	fields := redisCmdFields(cmd)
+	fields["duration"] = elapsed.String()
+	log.With(fields).Debug("Redis command executed")
+}
+
+// redisCmdFields returns the name and key of a command, leaving its values
+// out of the logs.
+func redisCmdFields(cmd redis.Cmder) logger.Fields {
+	fields := logger.Fields{"command": cmd.Name()}
+	if args := cmd.Args(); len(args) > 1 {
+		fields["key"] = fmt.Sprint(args[1])
+	}
+	return fields
+}
@@ -0,0 +1,22 @@
+package platigo
+
+import (
+	"context"
+	"time"
+
+	"github.com/bagastri07/platigo/health"
+)
+
+// RedisPingCheck returns a check pinging the server of client, reporting
+// its latency.
+func RedisPingCheck(client RedisClient) health.Check {
+	return func(ctx context.Context) health.Result {
+		started := time.Now()
+		err := client.Ping(ctx)
+		details := map[string]any{"latency_ms": time.Since(started).Milliseconds()}
+		if err != nil {
+			return health.Down(err, details)
+		}
+		return health.Up(details)
+	}
+}
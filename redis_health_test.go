@@ -0,0 +1,32 @@
+package platigo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bagastri07/platigo/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisPingCheck(t *testing.T) {
+	client, srv := newTestRedisClient(t, nil)
+	check := RedisPingCheck(client)
+
+	result := check(context.Background())
+	assert.Equal(t, health.StatusUp, result.Status)
+	assert.Contains(t, result.Details, "latency_ms")
+
+	srv.SetError("LOADING Redis is loading the dataset in memory")
+	result = check(context.Background())
+	assert.Equal(t, health.StatusDown, result.Status)
+	assert.Contains(t, result.Error, "LOADING")
+}
+
+func TestRedisConfigHealth(t *testing.T) {
+	registry := health.NewRegistry(health.RegistryConfig{})
+	newTestRedisClient(t, &RedisConfig{Health: registry, HealthName: "sessions"})
+
+	report := registry.Report(context.Background())
+	assert.Equal(t, health.StatusUp, report.Status)
+	assert.Contains(t, report.Checks, "sessions")
+}
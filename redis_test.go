@@ -0,0 +1,216 @@
+package platigo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordLogger keeps the entries logged through it and its children.
+type recordLogger struct {
+	mu      *sync.Mutex
+	entries *[]string
+	fields  logger.Fields
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{mu: &sync.Mutex{}, entries: &[]string{}}
+}
+
+func (r *recordLogger) With(fields logger.Fields) logger.Logger {
+	merged := logger.Fields{}
+	for k, v := range r.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &recordLogger{mu: r.mu, entries: r.entries, fields: merged}
+}
+
+func (r *recordLogger) record(level, msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*r.entries = append(*r.entries, level+":"+msg)
+}
+
+func (r *recordLogger) Entries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), *r.entries...)
+}
+
+func (r *recordLogger) Debug(msg string) { r.record("debug", msg) }
+func (r *recordLogger) Info(msg string)  { r.record("info", msg) }
+func (r *recordLogger) Warn(msg string)  { r.record("warn", msg) }
+func (r *recordLogger) Error(msg string) { r.record("error", msg) }
+
+// newTestRedisClient creates a client pointed at an in-memory Redis server.
+func newTestRedisClient(t *testing.T, config *RedisConfig) (*redisClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	if config == nil {
+		config = &RedisConfig{}
+	}
+	config.Addresses = []string{srv.Addr()}
+
+	client, err := NewRedisClient(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client.(*redisClient), srv
+}
+
+func TestNewRedisClient(t *testing.T) {
+	_, err := NewRedisClient(&RedisConfig{})
+	assert.ErrorIs(t, err, errRedisAddressRequired)
+
+	client, _ := newTestRedisClient(t, nil)
+	assert.NoError(t, client.Ping(context.Background()))
+	assert.NotNil(t, client.Client())
+}
+
+func TestRedisClientCommands(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newTestRedisClient(t, nil)
+
+	_, err := client.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+
+	require.NoError(t, client.Set(ctx, "greeting", "hello", time.Minute))
+	got, err := client.Get(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", got)
+	assert.Equal(t, time.Minute, srv.TTL("greeting"))
+
+	set, err := client.SetNX(ctx, "greeting", "again", 0)
+	require.NoError(t, err)
+	assert.False(t, set)
+
+	ok, err := client.Expire(ctx, "greeting", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Hour, srv.TTL("greeting"))
+
+	n, err := client.Exists(ctx, "greeting", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = client.Delete(ctx, "greeting", "missing")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestRedisClientLogging(t *testing.T) {
+	tests := []struct {
+		name     string
+		level    logger.Level
+		fail     bool
+		expected []string
+	}{
+		{
+			name:     "info level skips commands",
+			level:    logger.InfoLevel,
+			expected: nil,
+		},
+		{
+			name:     "debug level logs commands",
+			level:    logger.DebugLevel,
+			expected: []string{"debug:Redis command executed"},
+		},
+		{
+			name:     "failures are logged",
+			level:    logger.InfoLevel,
+			fail:     true,
+			expected: []string{"error:server error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newRecordLogger()
+			client, srv := newTestRedisClient(t, &RedisConfig{Logger: log, LogLevel: tt.level})
+			require.NoError(t, client.Ping(context.Background()))
+			*log.entries = nil
+
+			if tt.fail {
+				srv.SetError("server error")
+			}
+			_, _ = client.Get(context.Background(), "missing")
+
+			assert.Equal(t, tt.expected, log.Entries())
+		})
+	}
+}
+
+func TestNewRedisClientTopology(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     *RedisConfig
+		wantClient any
+		wantErr    error
+	}{
+		{
+			name:       "standalone",
+			config:     &RedisConfig{Addresses: []string{"localhost:6379"}, DB: 2},
+			wantClient: &redis.Client{},
+		},
+		{
+			name:       "cluster from several addresses",
+			config:     &RedisConfig{Addresses: []string{"node-1:6379", "node-2:6379"}},
+			wantClient: &redis.ClusterClient{},
+		},
+		{
+			name:       "cluster from a configuration endpoint",
+			config:     &RedisConfig{Addresses: []string{"cluster.cache.amazonaws.com:6379"}, ClusterMode: true},
+			wantClient: &redis.ClusterClient{},
+		},
+		{
+			name:       "sentinel",
+			config:     &RedisConfig{Addresses: []string{"sentinel-1:26379", "sentinel-2:26379"}, MasterName: "mymaster", DB: 1},
+			wantClient: &redis.Client{},
+		},
+		{
+			name:       "sentinel with replica routing",
+			config:     &RedisConfig{Addresses: []string{"sentinel-1:26379"}, MasterName: "mymaster", ReadOnly: true, RouteByLatency: true},
+			wantClient: &redis.ClusterClient{},
+		},
+		{
+			name:    "cluster with a database",
+			config:  &RedisConfig{Addresses: []string{"node-1:6379", "node-2:6379"}, DB: 1},
+			wantErr: errRedisClusterDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRedisClient(tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = client.Close() })
+
+			assert.IsType(t, tt.wantClient, client.Client())
+		})
+	}
+}
+
+func TestRedisClientClusterMode(t *testing.T) {
+	client, _ := newTestRedisClient(t, &RedisConfig{ClusterMode: true})
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, "greeting", "hello", 0))
+	got, err := client.Get(ctx, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", got)
+}
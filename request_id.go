@@ -0,0 +1,44 @@
+package platigo
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID carries the ID correlating the logs of a request across
+// services. The gRPC calls carry it in the x-request-id metadata.
+const HeaderRequestID = "X-Request-Id"
+
+const grpcRequestIDKey = "x-request-id"
+
+// validRequestID bounds the request IDs accepted from callers, so that they
+// cannot inject arbitrary content in the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ContextWithRequestID returns a context carrying the request ID id. The
+// HTTP and gRPC clients of platigo send it to the services they call, the
+// OpenSearch client as its X-Opaque-Id, and the loggers of
+// logger.FromContext and logger.WithContext attach it as the requestID
+// field.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	ctx = logger.ContextWithFields(ctx, logger.Fields{"requestID": id})
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// RequestIDFromContext returns the request ID set with ContextWithRequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// EnsureRequestID returns id when it is a valid request ID received from a
+// caller, and a new UUID otherwise.
+func EnsureRequestID(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+	return uuid.NewString()
+}
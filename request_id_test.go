@@ -0,0 +1,65 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureRequestID(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		wantID string
+	}{
+		{name: "valid", id: "req-1.a:b_c", wantID: "req-1.a:b_c"},
+		{name: "empty", id: ""},
+		{name: "invalid characters", id: "req 1\n"},
+		{name: "too long", id: string(make([]byte, 129))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnsureRequestID(tt.id)
+			if tt.wantID != "" {
+				assert.Equal(t, tt.wantID, got)
+			} else {
+				assert.Len(t, got, 36, "a new UUID")
+			}
+		})
+	}
+}
+
+func TestOpaqueIDFromRequestID(t *testing.T) {
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	assert.Equal(t, "req-1", OpaqueIDFromContext(ctx))
+	assert.Equal(t, "opaque-1", OpaqueIDFromContext(ContextWithOpaqueID(ctx, "opaque-1")))
+}
+
+func TestHTTPClientRequestID(t *testing.T) {
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(HeaderRequestID))
+	}))
+	defer server.Close()
+	client := NewHTTPClient(HTTPConfig{})
+
+	ctx := ContextWithRequestID(context.Background(), "req-1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderRequestID, "own-1")
+	res, err = client.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, []string{"req-1", "own-1"}, got)
+}
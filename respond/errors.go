@@ -0,0 +1,181 @@
+package respond
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/cache"
+	"github.com/bagastri07/platigo/dynamodb"
+	"github.com/bagastri07/platigo/httpclient"
+	"github.com/bagastri07/platigo/idempotency"
+	"github.com/bagastri07/platigo/leaderboard"
+	"github.com/bagastri07/platigo/semaphore"
+	"github.com/bagastri07/platigo/session"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The codes of the errors mapped by FromError.
+const (
+	CodeBadRequest      = "bad_request"
+	CodeInvalidCursor   = "invalid_cursor"
+	CodeUnauthorized    = "unauthorized"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeValidation      = "validation_failed"
+	CodeConflict        = "conflict"
+	CodeAlreadyExists   = "already_exists"
+	CodeLocked          = "locked"
+	CodeInProgress      = "in_progress"
+	CodeTooLarge        = "request_too_large"
+	CodeTooManyRequests = "too_many_requests"
+	CodeInternal        = "internal_error"
+	CodeUpstream        = "upstream_error"
+	CodeTimeout         = "timeout"
+	CodeUnavailable     = "unavailable"
+)
+
+// APIError is an error carrying its response. Handlers return it for the
+// failures specific to their domain:
+//
+//	return respond.Error(w, respond.NewError(http.StatusUnprocessableEntity, "out_of_stock", "item is out of stock"))
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	// Fields lists the invalid fields of a validation failure.
+	Fields []FieldError
+	// Err is the cause, never sent to the client.
+	Err error
+}
+
+// NewError creates an APIError.
+func NewError(status int, code, message string) *APIError {
+	return &APIError{Status: status, Code: code, Message: message}
+}
+
+func (e *APIError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError describes an invalid field of a request, named by its JSON
+// path such as items[0].quantity.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned for a request with invalid fields. It is
+// answered with a 422 validation_failed listing the fields.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return "validation failed"
+	}
+	msg := "validation failed: " + e.Fields[0].Field + ": " + e.Fields[0].Message
+	if len(e.Fields) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.Fields)-1)
+	}
+	return msg
+}
+
+// errorMappings map the errors of platigo to their responses, the first
+// match winning.
+var errorMappings = []struct {
+	target  error
+	status  int
+	code    string
+	message string
+}{
+	{platigo.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor, "invalid cursor"},
+	{platigo.ErrInvalidPageParams, http.StatusBadRequest, CodeBadRequest, "invalid pagination parameters"},
+	{platigo.ErrInvalidRequestSignature, http.StatusUnauthorized, CodeUnauthorized, "invalid request signature"},
+	{platigo.ErrExpiredRequestSignature, http.StatusUnauthorized, CodeUnauthorized, "expired request signature"},
+	{session.ErrInvalidToken, http.StatusUnauthorized, CodeUnauthorized, "invalid session"},
+	{session.ErrNotFound, http.StatusUnauthorized, CodeUnauthorized, "invalid session"},
+	{platigo.ErrDocumentNotFound, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{platigo.ErrKeyNotFound, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{dynamodb.ErrNotFound, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{cache.ErrNotFound, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{leaderboard.ErrMemberNotFound, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{sql.ErrNoRows, http.StatusNotFound, CodeNotFound, "resource not found"},
+	{platigo.ErrStaleObject, http.StatusConflict, CodeConflict, "resource modified since it was read"},
+	{dynamodb.ErrConditionFailed, http.StatusConflict, CodeConflict, "resource modified since it was read"},
+	{platigo.ErrLockNotAvailable, http.StatusConflict, CodeLocked, "resource locked by another request"},
+	{idempotency.ErrInProgress, http.StatusConflict, CodeInProgress, "request already in progress"},
+	{semaphore.ErrNoPermit, http.StatusTooManyRequests, CodeTooManyRequests, "too many concurrent requests"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeTimeout, "request timed out"},
+}
+
+// FromError returns the response of err: the APIError it wraps, a 422 for
+// a ValidationError, the mapping of a platigo error such as
+// ErrDocumentNotFound to 404 or ErrStaleObject to 409, a 409 for unique
+// violations of Postgres and MySQL, or a 500 internal_error.
+func FromError(err error) *APIError {
+	var e *APIError
+	if errors.As(err, &e) {
+		return e
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return &APIError{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    CodeValidation,
+			Message: "request validation failed",
+			Fields:  validationErr.Fields,
+			Err:     err,
+		}
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.target) {
+			return &APIError{Status: m.status, Code: m.code, Message: m.message, Err: err}
+		}
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &APIError{Status: http.StatusRequestEntityTooLarge, Code: CodeTooLarge, Message: "request body too large", Err: err}
+	}
+	if isUniqueViolation(err) {
+		return &APIError{Status: http.StatusConflict, Code: CodeAlreadyExists, Message: "resource already exists", Err: err}
+	}
+	var searchErr *platigo.ResponseError
+	if errors.As(err, &searchErr) && (searchErr.StatusCode == http.StatusTooManyRequests || searchErr.StatusCode == http.StatusServiceUnavailable) {
+		return &APIError{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: "service temporarily unavailable", Err: err}
+	}
+	var statusErr *httpclient.StatusError
+	if errors.As(err, &statusErr) {
+		return &APIError{Status: http.StatusBadGateway, Code: CodeUpstream, Message: "upstream service error", Err: err}
+	}
+	return &APIError{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation of
+// Postgres or MySQL.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// unique_violation
+		return pgErr.Code == "23505"
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1062 // ER_DUP_ENTRY
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package respond
+
+import (
+	"maps"
+	"net/http"
+
+	"github.com/goccy/go-json"
+)
+
+// ContentTypeProblem is the media type of the problem documents.
+const ContentTypeProblem = "application/problem+json"
+
+// ProblemDetails is a problem document of RFC 7807. Code and Errors extend
+// it with the code of the error and the invalid fields of a validation
+// failure, and Extensions with any other member.
+type ProblemDetails struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+
+	Code       string
+	Errors     []FieldError
+	Extensions map[string]any
+}
+
+// MarshalJSON flattens Extensions next to the standard members.
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	doc := make(map[string]any, len(p.Extensions)+7)
+	maps.Copy(doc, p.Extensions)
+	doc["type"] = p.Type
+	doc["title"] = p.Title
+	doc["status"] = p.Status
+	if p.Detail != "" {
+		doc["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		doc["instance"] = p.Instance
+	}
+	if p.Code != "" {
+		doc["code"] = p.Code
+	}
+	if len(p.Errors) > 0 {
+		doc["errors"] = p.Errors
+	}
+	return json.Marshal(doc)
+}
+
+// Problems creates the problem documents of an API. The type of a problem
+// is TypeBaseURL followed by its code, such as
+// https://errors.example.com/not_found, or about:blank without TypeBaseURL.
+//
+//	problems := respond.Problems{TypeBaseURL: "https://errors.example.com/"}
+//	return problems.Write(c.Response(), c.Request(), err)
+type Problems struct {
+	TypeBaseURL string
+}
+
+// New returns the problem of err, with the status, code and message of
+// FromError, for the request r.
+func (p Problems) New(r *http.Request, err error) *ProblemDetails {
+	e := FromError(err)
+	problem := &ProblemDetails{
+		Type:   "about:blank",
+		Title:  http.StatusText(e.Status),
+		Status: e.Status,
+		Detail: e.Message,
+		Code:   e.Code,
+		Errors: e.Fields,
+	}
+	if p.TypeBaseURL != "" && e.Code != "" {
+		problem.Type = p.TypeBaseURL + e.Code
+	}
+	if r != nil && r.URL != nil {
+		problem.Instance = r.URL.Path
+	}
+	return problem
+}
+
+// Write writes the problem of err.
+func (p Problems) Write(w http.ResponseWriter, r *http.Request, err error) error {
+	return WriteProblem(w, p.New(r, err))
+}
+
+// Problem writes the problem of err, typed about:blank.
+func Problem(w http.ResponseWriter, r *http.Request, err error) error {
+	return Problems{}.Write(w, r, err)
+}
+
+// WriteProblem writes problem with its status.
+func WriteProblem(w http.ResponseWriter, problem *ProblemDetails) error {
+	data, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,108 @@
+package respond
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bagastri07/platigo"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblems(t *testing.T) {
+	validationErr := &ValidationError{Fields: []FieldError{
+		{Field: "email", Code: "required", Message: "is required"},
+		{Field: "items[0].quantity", Code: "min", Message: "must be at least 1"},
+	}}
+
+	tests := []struct {
+		name        string
+		typeBaseURL string
+		err         error
+		wantStatus  int
+		wantBody    string
+	}{
+		{
+			name:       "document not found",
+			err:        fmt.Errorf("get order: %w", platigo.ErrDocumentNotFound),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"type":"about:blank","title":"Not Found","status":404,"detail":"resource not found","instance":"/orders/1","code":"not_found"}`,
+		},
+		{
+			name:        "type base url",
+			typeBaseURL: "https://errors.example.com/",
+			err:         &platigo.ResponseError{StatusCode: http.StatusConflict, Type: "version_conflict_engine_exception"},
+			wantStatus:  http.StatusConflict,
+			wantBody:    `{"type":"https://errors.example.com/conflict","title":"Conflict","status":409,"detail":"resource modified since it was read","instance":"/orders/1","code":"conflict"}`,
+		},
+		{
+			name:       "opensearch overloaded",
+			err:        &platigo.ResponseError{StatusCode: http.StatusTooManyRequests, Type: "es_rejected_execution_exception"},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"service temporarily unavailable","instance":"/orders/1","code":"unavailable"}`,
+		},
+		{
+			name:       "row lock",
+			err:        &platigo.LockError{Err: errors.New("lock timeout")},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"about:blank","title":"Conflict","status":409,"detail":"resource locked by another request","instance":"/orders/1","code":"locked"}`,
+		},
+		{
+			name:       "postgres unique violation",
+			err:        &pgconn.PgError{Code: "23505"},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"about:blank","title":"Conflict","status":409,"detail":"resource already exists","instance":"/orders/1","code":"already_exists"}`,
+		},
+		{
+			name:       "mysql unique violation",
+			err:        &mysql.MySQLError{Number: 1062},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"type":"about:blank","title":"Conflict","status":409,"detail":"resource already exists","instance":"/orders/1","code":"already_exists"}`,
+		},
+		{
+			name:       "validation error",
+			err:        validationErr,
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody: `{"type":"about:blank","title":"Unprocessable Entity","status":422,"detail":"request validation failed","instance":"/orders/1","code":"validation_failed",
+				"errors":[{"field":"email","code":"required","message":"is required"},{"field":"items[0].quantity","code":"min","message":"must be at least 1"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/orders/1?dry_run=true", nil)
+			require.NoError(t, Problems{TypeBaseURL: tt.typeBaseURL}.Write(rec, req, tt.err))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, ContentTypeProblem, rec.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestProblemExtensions(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, WriteProblem(rec, &ProblemDetails{
+		Type:       "https://errors.example.com/out_of_credit",
+		Title:      "You do not have enough credit.",
+		Status:     http.StatusForbidden,
+		Extensions: map[string]any{"balance": 30, "status": "ignored"},
+	}))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"type":"https://errors.example.com/out_of_credit","title":"You do not have enough credit.","status":403,"balance":30}`, rec.Body.String())
+}
+
+func TestValidationErrorEnvelope(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, Error(rec, &ValidationError{Fields: []FieldError{{Field: "email", Code: "required", Message: "is required"}}}))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{"error":{"code":"validation_failed","message":"request validation failed","fields":[{"field":"email","code":"required","message":"is required"}]}}`, rec.Body.String())
+}
@@ -0,0 +1,85 @@
+// Package respond writes the standard JSON envelope of the API responses:
+//
+//	{"data": {...}, "meta": {"limit": 20, "next_cursor": "..."}}
+//	{"error": {"code": "not_found", "message": "resource not found"}}
+//
+// The helpers take the http.ResponseWriter of any framework, such as
+// c.Response() with Echo or c.Writer with Gin:
+//
+//	order, err := orders.Get(ctx, id)
+//	if err != nil {
+//		return respond.Error(c.Response(), err)
+//	}
+//	return respond.OK(c.Response(), order, nil)
+//
+// External facing APIs may answer errors with RFC 7807 problem documents
+// instead, written by Problem and Problems.
+package respond
+
+import (
+	"net/http"
+
+	"github.com/bagastri07/platigo"
+	"github.com/goccy/go-json"
+)
+
+// Envelope is the body of every response, holding either Data or Error.
+type Envelope struct {
+	Data  any        `json:"data,omitempty"`
+	Error *ErrorBody `json:"error,omitempty"`
+	Meta  *Meta      `json:"meta,omitempty"`
+}
+
+// ErrorBody describes a failure with a stable machine readable code, such
+// as not_found, and a message for humans.
+type ErrorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// Meta holds the pagination of a list. Total is omitted when zero, as
+// cursor paginated lists usually do not count their items.
+type Meta struct {
+	Limit      int    `json:"limit,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total,omitempty"`
+}
+
+// OK writes data with the 200 status.
+func OK(w http.ResponseWriter, data any, meta *Meta) error {
+	return JSON(w, http.StatusOK, Envelope{Data: data, Meta: meta})
+}
+
+// Created writes data with the 201 status.
+func Created(w http.ResponseWriter, data any) error {
+	return JSON(w, http.StatusCreated, Envelope{Data: data})
+}
+
+// Page writes the items of page with its limit and next cursor in the meta.
+func Page[T any](w http.ResponseWriter, page platigo.Page[T], limit int) error {
+	items := page.Items
+	if items == nil {
+		items = []T{}
+	}
+	return OK(w, items, &Meta{Limit: limit, NextCursor: page.NextCursor})
+}
+
+// Error writes the error envelope of err, with the status and code of
+// FromError. The messages of unexpected errors are not disclosed.
+func Error(w http.ResponseWriter, err error) error {
+	e := FromError(err)
+	return JSON(w, e.Status, Envelope{Error: &ErrorBody{Code: e.Code, Message: e.Message, Fields: e.Fields}})
+}
+
+// JSON writes v as the JSON body of a response with the given status.
+func JSON(w http.ResponseWriter, status int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,115 @@
+package respond
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOK(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, OK(rec, map[string]string{"id": "1"}, &Meta{Limit: 20, NextCursor: "abc"}))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"data":{"id":"1"},"meta":{"limit":20,"next_cursor":"abc"}}`, rec.Body.String())
+}
+
+func TestPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	require.NoError(t, Page(rec, platigo.Page[int]{}, 10))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data":[],"meta":{"limit":10}}`, rec.Body.String())
+}
+
+func TestError(t *testing.T) {
+	maxBytesErr := &http.MaxBytesError{Limit: 8}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "api error",
+			err:        fmt.Errorf("reserve: %w", NewError(http.StatusUnprocessableEntity, "out_of_stock", "item is out of stock")),
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"error":{"code":"out_of_stock","message":"item is out of stock"}}`,
+		},
+		{
+			name:       "document not found",
+			err:        fmt.Errorf("get order: %w", platigo.ErrDocumentNotFound),
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":{"code":"not_found","message":"resource not found"}}`,
+		},
+		{
+			name:       "stale object",
+			err:        &platigo.StaleObjectError{Table: "orders", Key: 1, Version: 2},
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":{"code":"conflict","message":"resource modified since it was read"}}`,
+		},
+		{
+			name:       "lock not available",
+			err:        platigo.ErrLockNotAvailable,
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":{"code":"locked","message":"resource locked by another request"}}`,
+		},
+		{
+			name:       "invalid cursor",
+			err:        platigo.ErrInvalidCursor,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"invalid_cursor","message":"invalid cursor"}}`,
+		},
+		{
+			name:       "body too large",
+			err:        maxBytesErr,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantBody:   `{"error":{"code":"request_too_large","message":"request body too large"}}`,
+		},
+		{
+			name:       "upstream error",
+			err:        &httpclient.StatusError{StatusCode: http.StatusServiceUnavailable},
+			wantStatus: http.StatusBadGateway,
+			wantBody:   `{"error":{"code":"upstream_error","message":"upstream service error"}}`,
+		},
+		{
+			name:       "deadline exceeded",
+			err:        context.DeadlineExceeded,
+			wantStatus: http.StatusGatewayTimeout,
+			wantBody:   `{"error":{"code":"timeout","message":"request timed out"}}`,
+		},
+		{
+			name:       "unexpected error",
+			err:        errors.New("connection refused to 10.0.0.1"),
+			wantStatus: http.StatusInternalServerError,
+			wantBody:   `{"error":{"code":"internal_error","message":"internal server error"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			require.NoError(t, Error(rec, tt.err))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.JSONEq(t, tt.wantBody, rec.Body.String())
+		})
+	}
+}
+
+func TestFromErrorKeepsCause(t *testing.T) {
+	err := FromError(platigo.ErrKeyNotFound)
+
+	assert.ErrorIs(t, err, platigo.ErrKeyNotFound)
+	assert.Equal(t, "resource not found: redis: key not found", err.Error())
+}
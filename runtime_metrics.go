@@ -0,0 +1,76 @@
+package platigo
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// RuntimeMetrics exports the metrics of the process: goroutines, GC pauses
+// and heap size from the Go runtime, open file descriptors, CPU and memory
+// from the operating system where available, and the gauges added with
+// AddGaugeFunc. It implements prometheus.Collector:
+//
+//	metrics := platigo.NewRuntimeMetrics("myservice")
+//	metrics.AddGaugeFunc("workers_busy", "Number of busy workers.", pool.Busy)
+//	registry := prometheus.NewRegistry()
+//	registry.MustRegister(metrics, osMetrics)
+//
+// The Go and process collectors are already registered on the default
+// Prometheus registry, so RuntimeMetrics is meant for the other registries.
+// The depth of the bulk indexer queues is exported by OpenSearchMetrics.
+type RuntimeMetrics struct {
+	namespace        string
+	goCollector      prometheus.Collector
+	processCollector prometheus.Collector
+
+	mu     sync.RWMutex
+	gauges []prometheus.Collector
+}
+
+// NewRuntimeMetrics creates the runtime metrics, with the gauges added with
+// AddGaugeFunc under the given namespace.
+func NewRuntimeMetrics(namespace string) *RuntimeMetrics {
+	return &RuntimeMetrics{
+		namespace:        namespace,
+		goCollector:      collectors.NewGoCollector(),
+		processCollector: collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}
+}
+
+// AddGaugeFunc exports the value returned by value, read on every scrape,
+// as the namespace_name gauge. It is meant for the state of long-lived
+// components, such as the depth of a queue. Gauges are added before the
+// metrics are registered, which fails for duplicate names.
+func (m *RuntimeMetrics) AddGaugeFunc(name, help string, value func() float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges = append(m.gauges, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// Describe implements prometheus.Collector.
+func (m *RuntimeMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.goCollector.Describe(ch)
+	m.processCollector.Describe(ch)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, gauge := range m.gauges {
+		gauge.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *RuntimeMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.goCollector.Collect(ch)
+	m.processCollector.Collect(ch)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, gauge := range m.gauges {
+		gauge.Collect(ch)
+	}
+}
@@ -0,0 +1,29 @@
+package platigo
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	metrics := NewRuntimeMetrics("test")
+	depth := 3.0
+	metrics.AddGaugeFunc("queue_depth", "Depth of the queue.", func() float64 { return depth })
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(metrics))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	values := map[string]float64{}
+	for _, family := range families {
+		values[family.GetName()] = family.GetMetric()[0].GetGauge().GetValue()
+	}
+	assert.Contains(t, values, "go_goroutines")
+	assert.Contains(t, values, "go_gc_duration_seconds")
+	assert.Contains(t, values, "go_memstats_heap_alloc_bytes")
+	assert.Equal(t, depth, values["test_queue_depth"])
+}
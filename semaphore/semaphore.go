@@ -0,0 +1,163 @@
+// Package semaphore implements a Redis-backed counting semaphore capping the
+// concurrency of an operation across every instance of a service.
+package semaphore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bagastri07/platigo/crypto"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPrefix       = "semaphore:"
+	defaultPollInterval = 100 * time.Millisecond
+)
+
+var (
+	// ErrNoPermit is returned by TryAcquire when every permit is held.
+	ErrNoPermit = errors.New("semaphore: no permit available")
+	// ErrLeaseLost is returned by Extend when the lease expired and its
+	// permit may have been handed to someone else.
+	ErrLeaseLost = errors.New("semaphore: lease lost")
+)
+
+// Holders are sorted set members scored by the expiry of their lease in
+// milliseconds, so crashed holders free their permit once it expires.
+var acquireScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[3], ARGV[4])
+redis.call("PEXPIREAT", KEYS[1], redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")[2])
+return 1
+`)
+
+var extendScript = redis.NewScript(`
+local score = redis.call("ZSCORE", KEYS[1], ARGV[3])
+if not score or tonumber(score) <= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[3])
+redis.call("PEXPIREAT", KEYS[1], redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")[2])
+return 1
+`)
+
+// Option customizes a Semaphore.
+type Option func(*Semaphore)
+
+// WithPrefix sets the prefix of the Redis keys, "semaphore:" by default.
+func WithPrefix(prefix string) Option {
+	return func(s *Semaphore) {
+		s.prefix = prefix
+	}
+}
+
+// WithPollInterval sets how often Acquire retries while every permit is
+// held, 100ms by default.
+func WithPollInterval(interval time.Duration) Option {
+	return func(s *Semaphore) {
+		s.pollInterval = interval
+	}
+}
+
+// Semaphore hands out permits of named semaphores.
+type Semaphore struct {
+	client       redis.UniversalClient
+	prefix       string
+	pollInterval time.Duration
+	now          func() time.Time
+}
+
+// New creates a Semaphore.
+func New(client redis.UniversalClient, opts ...Option) *Semaphore {
+	s := &Semaphore{
+		client:       client,
+		prefix:       defaultPrefix,
+		pollInterval: defaultPollInterval,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Lease is a held permit. It is released by Release or once its ttl elapses,
+// whichever comes first.
+type Lease struct {
+	sem   *Semaphore
+	key   string
+	token string
+}
+
+// Acquire waits for one of the max permits of key, until ctx is done. Every
+// caller of a key must use the same max.
+func (s *Semaphore) Acquire(ctx context.Context, key string, max int, ttl time.Duration) (*Lease, error) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		lease, err := s.TryAcquire(ctx, key, max, ttl)
+		if !errors.Is(err, ErrNoPermit) {
+			return lease, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TryAcquire takes one of the max permits of key, or returns ErrNoPermit.
+func (s *Semaphore) TryAcquire(ctx context.Context, key string, max int, ttl time.Duration) (*Lease, error) {
+	token, err := crypto.GenerateToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	acquired, err := acquireScript.Run(ctx, s.client, []string{s.prefix + key},
+		now.UnixMilli(),
+		max,
+		now.Add(ttl).UnixMilli(),
+		token,
+	).Int()
+	if err != nil {
+		return nil, err
+	}
+	if acquired == 0 {
+		return nil, ErrNoPermit
+	}
+
+	return &Lease{sem: s, key: s.prefix + key, token: token}, nil
+}
+
+// Release gives the permit back. Releasing an expired lease is not an error.
+func (l *Lease) Release(ctx context.Context) error {
+	return l.sem.client.ZRem(ctx, l.key, l.token).Err()
+}
+
+// Extend pushes the expiry of the lease to ttl from now, for operations
+// running longer than planned.
+func (l *Lease) Extend(ctx context.Context, ttl time.Duration) error {
+	now := l.sem.now()
+	extended, err := extendScript.Run(ctx, l.sem.client, []string{l.key},
+		now.UnixMilli(),
+		now.Add(ttl).UnixMilli(),
+		l.token,
+	).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
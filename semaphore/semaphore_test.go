@@ -0,0 +1,91 @@
+package semaphore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSemaphore(t *testing.T, opts ...Option) (*Semaphore, *time.Time) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	now := time.Now()
+	sem := New(client, opts...)
+	sem.now = func() time.Time { return now }
+
+	return sem, &now
+}
+
+func TestTryAcquire(t *testing.T) {
+	ctx := context.Background()
+	sem, _ := newTestSemaphore(t)
+
+	first, err := sem.TryAcquire(ctx, "reindex", 2, time.Minute)
+	require.NoError(t, err)
+	_, err = sem.TryAcquire(ctx, "reindex", 2, time.Minute)
+	require.NoError(t, err)
+
+	_, err = sem.TryAcquire(ctx, "reindex", 2, time.Minute)
+	assert.ErrorIs(t, err, ErrNoPermit)
+
+	_, err = sem.TryAcquire(ctx, "other", 2, time.Minute)
+	assert.NoError(t, err, "keys are independent")
+
+	require.NoError(t, first.Release(ctx))
+	_, err = sem.TryAcquire(ctx, "reindex", 2, time.Minute)
+	assert.NoError(t, err)
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	ctx := context.Background()
+	sem, now := newTestSemaphore(t)
+
+	lease, err := sem.TryAcquire(ctx, "reindex", 1, time.Minute)
+	require.NoError(t, err)
+
+	*now = now.Add(30 * time.Second)
+	require.NoError(t, lease.Extend(ctx, time.Minute))
+
+	*now = now.Add(45 * time.Second)
+	_, err = sem.TryAcquire(ctx, "reindex", 1, time.Minute)
+	assert.ErrorIs(t, err, ErrNoPermit, "the extended lease still holds the permit")
+
+	*now = now.Add(time.Minute)
+	_, err = sem.TryAcquire(ctx, "reindex", 1, time.Minute)
+	require.NoError(t, err, "expired leases free their permit")
+
+	assert.ErrorIs(t, lease.Extend(ctx, time.Minute), ErrLeaseLost)
+	assert.NoError(t, lease.Release(ctx))
+}
+
+func TestAcquireWaits(t *testing.T) {
+	ctx := context.Background()
+	sem, _ := newTestSemaphore(t, WithPollInterval(5*time.Millisecond))
+
+	lease, err := sem.TryAcquire(ctx, "reindex", 1, time.Minute)
+	require.NoError(t, err)
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		_ = lease.Release(ctx)
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = sem.Acquire(waitCtx, "reindex", 1, time.Minute)
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = sem.Acquire(timeoutCtx, "reindex", 1, time.Minute)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
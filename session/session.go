@@ -0,0 +1,232 @@
+// Package session stores user sessions in Redis. Clients hold a signed token
+// made of the session ID and its HMAC, so forged IDs are rejected without a
+// Redis round trip.
+package session
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/crypto"
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultTTL    = 24 * time.Hour
+	defaultPrefix = "session:"
+	idLength      = 32
+)
+
+var (
+	// ErrNotFound is returned when the session expired or was revoked.
+	ErrNotFound = errors.New("session: not found")
+	// ErrInvalidToken is returned when a token is malformed or its signature
+	// does not match.
+	ErrInvalidToken = errors.New("session: invalid token")
+
+	errSecretRequired = errors.New("session: secret is required")
+)
+
+// Config configures a Store.
+type Config struct {
+	// Secret signs the session tokens. Changing it invalidates every token.
+	Secret []byte
+	// TTL is the lifetime of a session since its creation or last Touch,
+	// 24 hours by default.
+	TTL time.Duration
+	// Prefix is the prefix of the Redis keys, "session:" by default.
+	Prefix string
+}
+
+// Session is a stored session with its payload.
+type Session[T any] struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Data      T         `json:"data"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Store creates and resolves sessions carrying a payload of type T.
+type Store[T any] struct {
+	client redis.UniversalClient
+	secret []byte
+	ttl    time.Duration
+	prefix string
+	now    func() time.Time
+}
+
+// NewStore creates a Store.
+func NewStore[T any](client redis.UniversalClient, config Config) (*Store[T], error) {
+	if len(config.Secret) == 0 {
+		return nil, errSecretRequired
+	}
+
+	s := &Store[T]{
+		client: client,
+		secret: config.Secret,
+		ttl:    config.TTL,
+		prefix: config.Prefix,
+		now:    time.Now,
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultTTL
+	}
+	if s.prefix == "" {
+		s.prefix = defaultPrefix
+	}
+
+	return s, nil
+}
+
+// Create starts a session for userID and returns the token to hand to the
+// client.
+func (s *Store[T]) Create(ctx context.Context, userID string, data T) (string, *Session[T], error) {
+	id, err := crypto.GenerateToken(idLength)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := s.now()
+	sess := &Session[T]{
+		ID:        id,
+		UserID:    userID,
+		Data:      data,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+	}
+	if err = s.save(ctx, sess); err != nil {
+		return "", nil, err
+	}
+
+	return id + "." + crypto.SignPayload(s.secret, []byte(id)), sess, nil
+}
+
+// Get returns the session of token.
+func (s *Store[T]) Get(ctx context.Context, token string) (*Session[T], error) {
+	id, err := s.parseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.load(ctx, id)
+}
+
+// Touch extends the session of token by the configured TTL.
+func (s *Store[T]) Touch(ctx context.Context, token string) (*Session[T], error) {
+	sess, err := s.Get(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	sess.ExpiresAt = s.now().Add(s.ttl)
+	if err = s.save(ctx, sess); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+// Revoke ends the session of token. Revoking an expired session is not an
+// error.
+func (s *Store[T]) Revoke(ctx context.Context, token string) error {
+	id, err := s.parseToken(token)
+	if err != nil {
+		return err
+	}
+
+	sess, err := s.load(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.sessionKey(id))
+		pipe.SRem(ctx, s.userKey(sess.UserID), id)
+		return nil
+	})
+	return err
+}
+
+// RevokeAll ends every session of userID, e.g. after a password change, and
+// returns how many were still active.
+func (s *Store[T]) RevokeAll(ctx context.Context, userID string) (int, error) {
+	ids, err := s.client.SMembers(ctx, s.userKey(userID)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		keys = append(keys, s.sessionKey(id))
+	}
+
+	var deleted *redis.IntCmd
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(keys) > 0 {
+			deleted = pipe.Del(ctx, keys...)
+		}
+		pipe.Del(ctx, s.userKey(userID))
+		return nil
+	})
+	if err != nil || deleted == nil {
+		return 0, err
+	}
+
+	return int(deleted.Val()), nil
+}
+
+func (s *Store[T]) save(ctx context.Context, sess *Session[T]) error {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.sessionKey(sess.ID), data, s.ttl)
+		// The user index lives as long as its most recent session. IDs of
+		// expired sessions left in it are harmless.
+		pipe.SAdd(ctx, s.userKey(sess.UserID), sess.ID)
+		pipe.Expire(ctx, s.userKey(sess.UserID), s.ttl)
+		return nil
+	})
+	return err
+}
+
+func (s *Store[T]) load(ctx context.Context, id string) (*Session[T], error) {
+	data, err := s.client.Get(ctx, s.sessionKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sess := &Session[T]{}
+	if err = json.Unmarshal(data, sess); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+func (s *Store[T]) parseToken(token string) (string, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || id == "" || !crypto.VerifyPayload(s.secret, []byte(id), signature) {
+		return "", ErrInvalidToken
+	}
+	return id, nil
+}
+
+func (s *Store[T]) sessionKey(id string) string {
+	return s.prefix + "id:" + id
+}
+
+func (s *Store[T]) userKey(userID string) string {
+	return s.prefix + "user:" + userID
+}
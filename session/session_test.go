@@ -0,0 +1,124 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type profile struct {
+	Role string `json:"role"`
+}
+
+func newTestStore(t *testing.T) (*Store[profile], *miniredis.Miniredis) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	store, err := NewStore[profile](client, Config{Secret: []byte("secret"), TTL: time.Hour})
+	require.NoError(t, err)
+
+	return store, srv
+}
+
+func TestNewStore(t *testing.T) {
+	_, err := NewStore[profile](nil, Config{})
+	assert.ErrorIs(t, err, errSecretRequired)
+}
+
+func TestStoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	token, created, err := store.Create(ctx, "user-1", profile{Role: "admin"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, created.ExpiresAt.Sub(created.CreatedAt))
+
+	got, err := store.Get(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", got.UserID)
+	assert.Equal(t, profile{Role: "admin"}, got.Data)
+
+	srv.FastForward(30 * time.Minute)
+	_, err = store.Touch(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, srv.TTL(store.sessionKey(created.ID)))
+
+	require.NoError(t, store.Revoke(ctx, token))
+	_, err = store.Get(ctx, token)
+	assert.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, store.Revoke(ctx, token), "revoking twice is not an error")
+}
+
+func TestStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store, srv := newTestStore(t)
+
+	token, _, err := store.Create(ctx, "user-1", profile{})
+	require.NoError(t, err)
+
+	srv.FastForward(2 * time.Hour)
+	_, err = store.Get(ctx, token)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStoreRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	var tokens []string
+	for i := 0; i < 3; i++ {
+		token, _, err := store.Create(ctx, "user-1", profile{})
+		require.NoError(t, err)
+		tokens = append(tokens, token)
+	}
+	other, _, err := store.Create(ctx, "user-2", profile{})
+	require.NoError(t, err)
+
+	n, err := store.RevokeAll(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	for _, token := range tokens {
+		_, err = store.Get(ctx, token)
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+	_, err = store.Get(ctx, other)
+	assert.NoError(t, err)
+
+	n, err = store.RevokeAll(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestStoreInvalidToken(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestStore(t)
+
+	token, created, err := store.Create(ctx, "user-1", profile{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "no signature", token: created.ID},
+		{name: "forged signature", token: created.ID + ".deadbeef"},
+		{name: "signature of another id", token: "other" + token[len(created.ID):]},
+		{name: "empty", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := store.Get(ctx, tt.token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
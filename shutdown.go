@@ -0,0 +1,122 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// ShutdownHook releases a resource on shutdown, within the deadline of ctx.
+// The Drain and Close methods of the messaging consumers are hooks:
+//
+//	shutdown.Register("orders consumer", consumer.Drain)
+type ShutdownHook func(ctx context.Context) error
+
+// ShutdownConfig configures a ShutdownManager.
+type ShutdownConfig struct {
+	// Timeout bounds the whole shutdown, 30 seconds by default. It must be
+	// shorter than the grace period of the orchestrator, after which the
+	// process is killed.
+	Timeout time.Duration
+	// Signals trigger Wait, SIGTERM and SIGINT by default.
+	Signals []os.Signal
+
+	// Logger receives the shutdown logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// ShutdownManager runs the shutdown hooks of a process, so that consumers
+// stop fetching and finish their in-flight messages before the connections
+// they depend on close.
+//
+// Hooks run one at a time, in the reverse order of their registration: the
+// resources created last, which depend on those created before them, are
+// released first.
+type ShutdownManager struct {
+	config ShutdownConfig
+	log    logger.Logger
+
+	mu    sync.Mutex
+	hooks []namedHook
+	once  sync.Once
+	err   error
+}
+
+type namedHook struct {
+	name string
+	hook ShutdownHook
+}
+
+// NewShutdownManager creates a ShutdownManager.
+func NewShutdownManager(config ShutdownConfig) *ShutdownManager {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultShutdownTimeout
+	}
+	if len(config.Signals) == 0 {
+		config.Signals = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	return &ShutdownManager{
+		config: config,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel),
+	}
+}
+
+// Register adds a hook, named in the logs and errors.
+func (m *ShutdownManager) Register(name string, hook ShutdownHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, namedHook{name: name, hook: hook})
+}
+
+// Wait blocks until the process receives one of the signals or ctx is done,
+// then shuts down and returns the error of Shutdown.
+func (m *ShutdownManager) Wait(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, m.config.Signals...)
+	defer stop()
+
+	<-ctx.Done()
+	m.log.Info("Shutting down")
+	return m.Shutdown(context.WithoutCancel(ctx))
+}
+
+// Shutdown runs the hooks within the timeout. Every hook runs even when one
+// fails, and the returned error joins their errors. Hooks run once: later
+// calls return the result of the first one.
+func (m *ShutdownManager) Shutdown(ctx context.Context) error {
+	m.once.Do(func() {
+		ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+		defer cancel()
+		m.err = m.run(ctx)
+	})
+	return m.err
+}
+
+func (m *ShutdownManager) run(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := append([]namedHook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		log := m.log.With(logger.Fields{"hook": h.name})
+
+		started := time.Now()
+		if err := h.hook(ctx); err != nil {
+			log.Error(err.Error())
+			errs = append(errs, fmt.Errorf("shutdown %s: %w", h.name, err))
+			continue
+		}
+		log.With(logger.Fields{"duration": time.Since(started).String()}).Debug("Shutdown hook done")
+	}
+	return errors.Join(errs...)
+}
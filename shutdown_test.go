@@ -0,0 +1,68 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownManagerShutdown(t *testing.T) {
+	errClose := errors.New("close failed")
+	manager := NewShutdownManager(ShutdownConfig{Timeout: time.Minute})
+
+	var order []string
+	hook := func(name string, err error) ShutdownHook {
+		return func(ctx context.Context) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok, "hooks get the shutdown deadline")
+			order = append(order, name)
+			return err
+		}
+	}
+	manager.Register("database", hook("database", nil))
+	manager.Register("producer", hook("producer", errClose))
+	manager.Register("consumer", hook("consumer", nil))
+
+	err := manager.Shutdown(context.Background())
+	assert.ErrorIs(t, err, errClose)
+	assert.EqualError(t, err, "shutdown producer: close failed")
+	assert.Equal(t, []string{"consumer", "producer", "database"}, order)
+
+	assert.Equal(t, err, manager.Shutdown(context.Background()))
+	assert.Len(t, order, 3, "hooks run once")
+}
+
+func TestShutdownManagerTimeout(t *testing.T) {
+	manager := NewShutdownManager(ShutdownConfig{Timeout: 10 * time.Millisecond})
+	manager.Register("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	assert.ErrorIs(t, manager.Shutdown(context.Background()), context.DeadlineExceeded)
+}
+
+func TestShutdownManagerWait(t *testing.T) {
+	manager := NewShutdownManager(ShutdownConfig{})
+	assert.Equal(t, []os.Signal{syscall.SIGTERM, os.Interrupt}, manager.config.Signals)
+
+	drained := false
+	manager.Register("consumer", func(ctx context.Context) error {
+		drained = ctx.Err() == nil
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- manager.Wait(ctx) }()
+	cancel()
+
+	require.NoError(t, <-done)
+	assert.True(t, drained, "hooks do not inherit the cancellation that triggered Wait")
+}
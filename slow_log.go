@@ -0,0 +1,30 @@
+package platigo
+
+import (
+	"context"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+)
+
+// slowLog warns about the operations lasting longer than a threshold, with
+// the request and trace IDs of their context so that they can be found
+// even when their trace was not sampled. The zero value is disabled.
+type slowLog struct {
+	log       logger.Logger
+	threshold time.Duration
+}
+
+func newSlowLog(log logger.Logger, threshold time.Duration) slowLog {
+	return slowLog{log: log, threshold: threshold}
+}
+
+// observe logs msg with fields when elapsed exceeds the threshold.
+func (s slowLog) observe(ctx context.Context, elapsed time.Duration, msg string, fields logger.Fields) {
+	if s.threshold <= 0 || elapsed < s.threshold {
+		return
+	}
+	fields["duration"] = elapsed.String()
+	fields["threshold"] = s.threshold.String()
+	logger.WithContext(ctx, s.log).With(fields).Warn(msg)
+}
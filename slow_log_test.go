@@ -0,0 +1,105 @@
+package platigo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSlowLogObserve(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	unsampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		ctx       context.Context
+		elapsed   time.Duration
+		want      []logger.Fields
+	}{
+		{name: "disabled", elapsed: time.Hour},
+		{name: "fast", threshold: time.Second, ctx: context.Background(), elapsed: time.Millisecond},
+		{
+			name:      "slow",
+			threshold: time.Second,
+			ctx:       context.Background(),
+			elapsed:   2 * time.Second,
+			want:      []logger.Fields{{"level": "warn", "msg": "Slow call", "call": "get", "duration": "2s", "threshold": "1s"}},
+		},
+		{
+			name:      "slow with unsampled trace and request ID",
+			threshold: time.Second,
+			ctx:       ContextWithRequestID(unsampled, "req-1"),
+			elapsed:   time.Second,
+			want: []logger.Fields{{
+				"level": "warn", "msg": "Slow call", "call": "get", "duration": "1s", "threshold": "1s",
+				"traceID": "4bf92f3577b34da6a3ce929d0e0e4736", "spanID": "00f067aa0ba902b7", "requestID": "req-1",
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newFieldsLogger()
+			newSlowLog(log, tt.threshold).observe(tt.ctx, tt.elapsed, "Slow call", logger.Fields{"call": "get"})
+			assert.Equal(t, tt.want, log.Entries())
+		})
+	}
+}
+
+func TestSQLSlowStatement(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN(t.Name())
+	require.NoError(t, err)
+	defer mockDB.Close()
+	log := newFieldsLogger()
+	db, err := openInstrumentedDB(mockDB.Driver(), t.Name(), &sqlInstrumentation{
+		tracer:   newTracer(nil),
+		dbSystem: attrDBSystem.String("postgresql"),
+		slow:     newSlowLog(log, 10*time.Millisecond),
+	})
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE").WillDelayFor(20 * time.Millisecond).WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = db.ExecContext(context.Background(), "UPDATE users SET name = 'alice' WHERE id = 42")
+	require.NoError(t, err)
+
+	entries := log.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Slow SQL statement", entries[0]["msg"])
+	assert.Equal(t, "update", entries[0]["operation"])
+	assert.Equal(t, "UPDATE users SET name = ? WHERE id = ?", entries[0]["statement"])
+}
+
+func TestHTTPSlowRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	log := newFieldsLogger()
+	client := NewHTTPClient(HTTPConfig{Logger: log, SlowThreshold: time.Second})
+	transport := client.Transport.(*retryTransport)
+	now := time.Now()
+	transport.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	res, err := client.Get(server.URL + "/orders?token=abc")
+	require.NoError(t, err)
+	_ = res.Body.Close()
+
+	assert.Equal(t, []logger.Fields{{
+		"level": "warn", "msg": "Slow HTTP request", "method": http.MethodGet, "url": server.URL + "/orders",
+		"status": http.StatusAccepted, "duration": "1s", "threshold": "1s",
+	}}, log.Entries())
+}
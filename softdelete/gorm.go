@@ -0,0 +1,36 @@
+package softdelete
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GormScope filters out the soft-deleted rows of a GORM query, unless the
+// context of the query is Unscoped:
+//
+//	db.WithContext(ctx).Scopes(orders.GormScope).Find(&list)
+//
+// Models with a gorm.DeletedAt field are filtered by GORM itself and only
+// need GormPurge.
+func (t *Table) GormScope(db *gorm.DB) *gorm.DB {
+	if IsUnscoped(db.Statement.Context) {
+		return db
+	}
+	return db.Where(t.Condition(db.Statement.Context))
+}
+
+// GormDelete soft-deletes the rows matching query and returns how many were
+// deleted.
+func (t *Table) GormDelete(db *gorm.DB, query any, args ...any) (int64, error) {
+	res := db.Table(t.name).Where(query, args...).Where(t.name+"."+t.column+" IS NULL").
+		Update(t.column, t.now().UTC())
+	return res.RowsAffected, res.Error
+}
+
+// GormPurge removes for good the rows soft-deleted before before and
+// returns how many were removed.
+func (t *Table) GormPurge(db *gorm.DB, before time.Time) (int64, error) {
+	res := db.Unscoped().Table(t.name).Where(t.column+" < ?", before.UTC()).Delete(nil)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,110 @@
+// Package softdelete implements soft deletion on tables with a nullable
+// deleted_at column: deleting sets the column, queries skip the rows where
+// it is set unless their context is Unscoped, and Purge eventually removes
+// them.
+//
+//	orders, err := softdelete.NewTable("orders", "")
+//
+//	query := "SELECT * FROM orders WHERE tenant = ? AND " + orders.Condition(ctx)
+//	_, err = orders.Delete(ctx, db, "id = ?", orderID)
+//
+// The same Table filters GORM queries with GormScope.
+package softdelete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DefaultColumn is the column of the deletion time.
+const DefaultColumn = "deleted_at"
+
+var (
+	errInvalidIdentifier = errors.New("softdelete: invalid table or column name")
+
+	identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+type unscopedKey struct{}
+
+// Unscoped returns a context whose queries include the soft-deleted rows,
+// e.g. for admin tools or audits.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedKey{}, true)
+}
+
+// IsUnscoped reports whether the queries of ctx include the soft-deleted
+// rows.
+func IsUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(unscopedKey{}).(bool)
+	return unscoped
+}
+
+// Table is a table with soft deletion.
+type Table struct {
+	name   string
+	column string
+	now    func() time.Time
+}
+
+// NewTable returns the Table name whose deletion time is in column,
+// DefaultColumn when empty.
+func NewTable(name, column string) (*Table, error) {
+	if column == "" {
+		column = DefaultColumn
+	}
+	if !identifier.MatchString(name) || !identifier.MatchString(column) {
+		return nil, errInvalidIdentifier
+	}
+	return &Table{name: name, column: column, now: time.Now}, nil
+}
+
+// Name returns the name of the table.
+func (t *Table) Name() string {
+	return t.name
+}
+
+// Condition returns the condition skipping the soft-deleted rows, to be
+// ANDed to the WHERE clause of the queries, or one always true when ctx is
+// Unscoped.
+func (t *Table) Condition(ctx context.Context) string {
+	if IsUnscoped(ctx) {
+		return "1 = 1"
+	}
+	return fmt.Sprintf("%s.%s IS NULL", t.name, t.column)
+}
+
+// Delete soft-deletes the rows matching where and returns how many were
+// deleted. Rows already deleted keep their deletion time. Placeholders are
+// question marks, rebound for the driver of db.
+func (t *Table) Delete(ctx context.Context, db sqlx.ExtContext, where string, args ...any) (int64, error) {
+	query := fmt.Sprintf("UPDATE %[1]s SET %[2]s = ? WHERE (%[3]s) AND %[2]s IS NULL", t.name, t.column, where)
+	return exec(ctx, db, query, append([]any{t.now().UTC()}, args...)...)
+}
+
+// Restore undeletes the soft-deleted rows matching where and returns how
+// many were restored.
+func (t *Table) Restore(ctx context.Context, db sqlx.ExtContext, where string, args ...any) (int64, error) {
+	query := fmt.Sprintf("UPDATE %[1]s SET %[2]s = NULL WHERE (%[3]s) AND %[2]s IS NOT NULL", t.name, t.column, where)
+	return exec(ctx, db, query, args...)
+}
+
+// Purge removes for good the rows soft-deleted before before and returns
+// how many were removed.
+func (t *Table) Purge(ctx context.Context, db sqlx.ExtContext, before time.Time) (int64, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s < ?", t.name, t.column)
+	return exec(ctx, db, query, before.UTC())
+}
+
+func exec(ctx context.Context, db sqlx.ExtContext, query string, args ...any) (int64, error) {
+	res, err := db.ExecContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
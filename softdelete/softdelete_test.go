@@ -0,0 +1,169 @@
+package softdelete
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormtests "gorm.io/gorm/utils/tests"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestTable(t *testing.T) *Table {
+	t.Helper()
+
+	table, err := NewTable("orders", "")
+	require.NoError(t, err)
+	table.now = func() time.Time { return testNow }
+	return table
+}
+
+func TestNewTableInvalid(t *testing.T) {
+	_, err := NewTable("orders; DROP TABLE orders", "")
+	assert.ErrorIs(t, err, errInvalidIdentifier)
+}
+
+func TestTableCondition(t *testing.T) {
+	table := newTestTable(t)
+	ctx := context.Background()
+
+	assert.Equal(t, "orders.deleted_at IS NULL", table.Condition(ctx))
+	assert.Equal(t, "1 = 1", table.Condition(Unscoped(ctx)))
+}
+
+func TestTableWrites(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(table *Table, db *sqlx.DB) (int64, error)
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			name: "delete",
+			run: func(table *Table, db *sqlx.DB) (int64, error) {
+				return table.Delete(context.Background(), db, "id = ?", 1)
+			},
+			wantSQL:  "UPDATE orders SET deleted_at = $1 WHERE (id = $2) AND deleted_at IS NULL",
+			wantArgs: []driver.Value{testNow, int64(1)},
+		},
+		{
+			name: "restore",
+			run: func(table *Table, db *sqlx.DB) (int64, error) {
+				return table.Restore(context.Background(), db, "id = ?", 1)
+			},
+			wantSQL:  "UPDATE orders SET deleted_at = NULL WHERE (id = $1) AND deleted_at IS NOT NULL",
+			wantArgs: []driver.Value{int64(1)},
+		},
+		{
+			name: "purge",
+			run: func(table *Table, db *sqlx.DB) (int64, error) {
+				return table.Purge(context.Background(), db, testNow)
+			},
+			wantSQL:  "DELETE FROM orders WHERE deleted_at < $1",
+			wantArgs: []driver.Value{testNow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			mock.ExpectExec(tt.wantSQL).WithArgs(tt.wantArgs...).WillReturnResult(sqlmock.NewResult(0, 2))
+
+			affected, err := tt.run(newTestTable(t), sqlx.NewDb(conn, "pgx"))
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), affected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func newTestGorm(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{DryRun: true})
+	require.NoError(t, err)
+	return db
+}
+
+type order struct {
+	ID     int64
+	Tenant string
+}
+
+func TestGormScope(t *testing.T) {
+	table := newTestTable(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantSQL string
+	}{
+		{name: "filtered", ctx: ctx, wantSQL: "SELECT * FROM `orders` WHERE tenant = ? AND orders.deleted_at IS NULL"},
+		{name: "unscoped", ctx: Unscoped(ctx), wantSQL: "SELECT * FROM `orders` WHERE tenant = ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var orders []order
+			stmt := newTestGorm(t).WithContext(tt.ctx).Table("orders").Where("tenant = ?", "acme").
+				Scopes(table.GormScope).Find(&orders).Statement
+			assert.Equal(t, tt.wantSQL, stmt.SQL.String())
+		})
+	}
+}
+
+func TestGormWrites(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      func(table *Table, db *gorm.DB) (int64, error)
+		wantSQL  string
+		wantArgs []driver.Value
+	}{
+		{
+			name: "delete",
+			run: func(table *Table, db *gorm.DB) (int64, error) {
+				return table.GormDelete(db, "id = ?", 1)
+			},
+			wantSQL:  "UPDATE `orders` SET `deleted_at`=? WHERE id = ? AND orders.deleted_at IS NULL",
+			wantArgs: []driver.Value{testNow, int64(1)},
+		},
+		{
+			name: "purge",
+			run: func(table *Table, db *gorm.DB) (int64, error) {
+				return table.GormPurge(db, testNow)
+			},
+			wantSQL:  "DELETE FROM `orders` WHERE deleted_at < ?",
+			wantArgs: []driver.Value{testNow},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer conn.Close()
+			mock.ExpectBegin()
+			mock.ExpectExec(tt.wantSQL).WithArgs(tt.wantArgs...).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectCommit()
+
+			db, err := gorm.Open(gormtests.DummyDialector{}, &gorm.Config{ConnPool: conn})
+			require.NoError(t, err)
+
+			affected, err := tt.run(newTestTable(t), db)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), affected)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
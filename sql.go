@@ -0,0 +1,339 @@
+package platigo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bagastri07/platigo/health"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Drivers supported by NewSQLDB.
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+)
+
+const (
+	defaultPostgresPort    = 5432
+	defaultMySQLPort       = 3306
+	defaultMaxOpenConns    = 25
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+	defaultConnectTimeout  = 5 * time.Second
+
+	defaultHealthCheckInterval = 10 * time.Second
+)
+
+var errSQLDriver = errors.New("sql: driver must be postgres or mysql")
+
+type DBConfig struct {
+	// Driver is DriverPostgres or DriverMySQL.
+	Driver string
+
+	// DSN is used as is when set. Otherwise it is built from the fields
+	// below, with Port defaulting to the one of the driver and Params added
+	// to the driver parameters, e.g. "sslmode" for Postgres or "tls" for
+	// MySQL.
+	DSN      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	Params   map[string]string
+
+	// ConnectTimeout bounds the dial of a connection, 5 seconds by default.
+	// StatementTimeout, when set, makes the server abort longer statements:
+	// every statement on Postgres, only SELECTs on MySQL.
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+
+	// MaxOpenConns is the maximum number of connections, 25 by default, and
+	// MaxIdleConns the number kept idle, MaxOpenConns by default.
+	// Connections are closed after ConnMaxLifetime, 30 minutes by default,
+	// or ConnMaxIdleTime unused, 5 minutes by default, so the pool follows
+	// failovers and load balancers.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// ReplicaHosts are read replicas sharing the configuration of the
+	// primary, as "host" or "host:port", and ReplicaDSNs replicas with their
+	// own DSN. They are pinged every HealthCheckInterval, 10 seconds by
+	// default, and skipped while unavailable.
+	ReplicaHosts        []string
+	ReplicaDSNs         []string
+	HealthCheckInterval time.Duration
+
+	// TracerProvider creates a span for every statement, with its
+	// literals masked. Defaults to the global provider, a no-op unless
+	// configured.
+	TracerProvider trace.TracerProvider
+	// Metrics records statement, error and latency metrics when set.
+	Metrics *SQLMetrics
+	// SlowThreshold logs a warning for every statement lasting longer,
+	// with its literals masked, whatever the sampling of its trace. Not set
+	// by default.
+	SlowThreshold time.Duration
+
+	// Health registers a readiness check pinging the primary, named
+	// HealthName, "sql" by default.
+	Health     *health.Registry
+	HealthName string
+
+	// Logger receives the client logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// SQLDB is a pool of connections to a SQL database. The embedded sqlx.DB
+// also gives the plain database/sql API.
+//
+// With replicas, the Query, QueryRow, Queryx, QueryRowx, Get and Select
+// methods of SQLDB, and their Context variants, run on a healthy replica,
+// and on the primary when none is or the context comes from WithPrimaryDB.
+// Everything else, named queries and transactions included, runs on the
+// primary.
+type SQLDB struct {
+	*sqlx.DB
+	log logger.Logger
+
+	replicas  []*replica
+	next      atomic.Uint64
+	stop      chan struct{}
+	done      sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewSQLDB opens a pool of connections to a Postgres or MySQL database.
+// Connections are only established on use, so call Ping to check the
+// configuration at startup.
+func NewSQLDB(config *DBConfig) (*SQLDB, error) {
+	driverName, dsn, err := config.driverDSN()
+	if err != nil {
+		return nil, err
+	}
+
+	instr := config.instrumentation()
+	db, err := openPool(driverName, dsn, config, instr)
+	if err != nil {
+		return nil, err
+	}
+	d := &SQLDB{
+		DB:   db,
+		log:  logger.WithLevel(config.Logger, logger.InfoLevel),
+		stop: make(chan struct{}),
+	}
+
+	replicaDSNs, err := config.replicaDSNs()
+	if err != nil {
+		_ = d.Close()
+		return nil, err
+	}
+	for _, dsn := range replicaDSNs {
+		replicaDB, err := openPool(driverName, dsn, config, instr)
+		if err != nil {
+			_ = d.Close()
+			return nil, err
+		}
+		r := &replica{db: replicaDB}
+		r.healthy.Store(true)
+		d.replicas = append(d.replicas, r)
+	}
+
+	if len(d.replicas) > 0 {
+		interval := config.HealthCheckInterval
+		if interval <= 0 {
+			interval = defaultHealthCheckInterval
+		}
+		d.done.Add(1)
+		go d.checkReplicasEvery(interval)
+	}
+	if config.Health != nil {
+		name := config.HealthName
+		if name == "" {
+			name = "sql"
+		}
+		config.Health.Register(name, d.PingCheck())
+	}
+	return d, nil
+}
+
+func openPool(driverName, dsn string, config *DBConfig, instr *sqlInstrumentation) (*sqlx.DB, error) {
+	var d driver.Driver = &mysql.MySQLDriver{}
+	if config.Driver == DriverPostgres {
+		d = stdlib.GetDefaultDriver()
+	}
+	sqlDB, err := openInstrumentedDB(d, dsn, instr)
+	if err != nil {
+		return nil, err
+	}
+	db := sqlx.NewDb(sqlDB, driverName)
+
+	maxOpen := config.MaxOpenConns
+	if maxOpen <= 0 {
+		maxOpen = defaultMaxOpenConns
+	}
+	maxIdle := config.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = maxOpen
+	}
+	lifetime := config.ConnMaxLifetime
+	if lifetime <= 0 {
+		lifetime = defaultConnMaxLifetime
+	}
+	idleTime := config.ConnMaxIdleTime
+	if idleTime <= 0 {
+		idleTime = defaultConnMaxIdleTime
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(lifetime)
+	db.SetConnMaxIdleTime(idleTime)
+	return db, nil
+}
+
+// Ping pings the database to check its availability.
+func (d *SQLDB) Ping(ctx context.Context) error {
+	if err := d.PingContext(ctx); err != nil {
+		d.log.With(logger.Fields{"error": err.Error()}).Error("Failed to ping SQL database")
+		return err
+	}
+	return nil
+}
+
+func (c *DBConfig) instrumentation() *sqlInstrumentation {
+	system := "mysql"
+	if c.Driver == DriverPostgres {
+		system = "postgresql"
+	}
+	return &sqlInstrumentation{
+		tracer:   newTracer(c.TracerProvider),
+		metrics:  c.Metrics,
+		dbSystem: attrDBSystem.String(system),
+		slow:     newSlowLog(logger.WithLevel(c.Logger, logger.InfoLevel), c.SlowThreshold),
+	}
+}
+
+// replicaDSNs returns the DSNs of the replicas.
+func (c *DBConfig) replicaDSNs() ([]string, error) {
+	dsns := append([]string(nil), c.ReplicaDSNs...)
+	for _, hostPort := range c.ReplicaHosts {
+		replica := *c
+		replica.DSN = ""
+		replica.Host = hostPort
+		if host, port, err := net.SplitHostPort(hostPort); err == nil {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				return nil, fmt.Errorf("sql: invalid replica port %q", hostPort)
+			}
+			replica.Host, replica.Port = host, p
+		}
+		_, dsn, err := replica.driverDSN()
+		if err != nil {
+			return nil, err
+		}
+		dsns = append(dsns, dsn)
+	}
+	return dsns, nil
+}
+
+// driverDSN returns the name of the registered driver and the DSN.
+func (c *DBConfig) driverDSN() (string, string, error) {
+	switch c.Driver {
+	case DriverPostgres:
+		if c.DSN != "" {
+			return "pgx", c.DSN, nil
+		}
+		return "pgx", c.postgresDSN(), nil
+	case DriverMySQL:
+		if c.DSN != "" {
+			return DriverMySQL, c.DSN, nil
+		}
+		return DriverMySQL, c.mysqlDSN(), nil
+	default:
+		return "", "", errSQLDriver
+	}
+}
+
+func (c *DBConfig) connectTimeout() time.Duration {
+	if c.ConnectTimeout <= 0 {
+		return defaultConnectTimeout
+	}
+	return c.ConnectTimeout
+}
+
+func (c *DBConfig) address(defaultPort int) string {
+	host := c.Host
+	if host == "" {
+		host = "localhost"
+	}
+	port := c.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// postgresDSN returns a postgres:// URL, understood by pgx and libpq.
+func (c *DBConfig) postgresDSN() string {
+	query := url.Values{}
+	for key, value := range c.Params {
+		query.Set(key, value)
+	}
+	// connect_timeout is in seconds and statement_timeout in milliseconds.
+	query.Set("connect_timeout", strconv.Itoa(max(1, int(c.connectTimeout().Seconds()))))
+	if c.StatementTimeout > 0 {
+		query.Set("statement_timeout", strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10))
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		Host:     c.address(defaultPostgresPort),
+		Path:     "/" + c.Database,
+		RawQuery: query.Encode(),
+	}
+	if c.User != "" {
+		u.User = url.UserPassword(c.User, c.Password)
+	}
+	return u.String()
+}
+
+func (c *DBConfig) mysqlDSN() string {
+	cfg := mysql.NewConfig()
+	cfg.User = c.User
+	cfg.Passwd = c.Password
+	cfg.Net = "tcp"
+	cfg.Addr = c.address(defaultMySQLPort)
+	cfg.DBName = c.Database
+	cfg.Timeout = c.connectTimeout()
+	// Scans DATETIME and TIMESTAMP columns into time.Time.
+	cfg.ParseTime = true
+
+	cfg.Params = map[string]string{}
+	for key, value := range c.Params {
+		// tls is a driver option, the other parameters are system variables.
+		if key == "tls" {
+			cfg.TLSConfig = value
+			continue
+		}
+		cfg.Params[key] = value
+	}
+	if c.StatementTimeout > 0 {
+		cfg.Params["max_execution_time"] = strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	}
+	return cfg.FormatDSN()
+}
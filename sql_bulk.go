@@ -0,0 +1,176 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// maxPlaceholders is the number of parameters of a statement supported
+	// by both Postgres and MySQL.
+	maxPlaceholders = 65535
+	maxBulkRows     = 1000
+)
+
+var errBulkColumns = errors.New("sql: bulk insert rows must be structs with exported fields")
+
+// BulkInsert inserts rows into table and returns how many were inserted.
+// The columns are the fields of T, named by their db tag like sqlx does,
+// fields tagged "-" being skipped.
+//
+// On Postgres, outside of a transaction, the rows are streamed with COPY.
+// Otherwise they are inserted by multi-row INSERTs of up to 1000 rows, and
+// fewer for wide rows so a statement stays within the parameter limit.
+// Run it in a transaction to insert all the rows or none.
+func BulkInsert[T any](ctx context.Context, db sqlx.ExtContext, table string, rows []T) (int64, error) {
+	return bulkInsert(ctx, db, table, rows, maxPlaceholders)
+}
+
+func bulkInsert[T any](ctx context.Context, db sqlx.ExtContext, table string, rows []T, placeholders int) (int64, error) {
+	if !columnName.MatchString(table) {
+		return 0, errInvalidIdentifier
+	}
+	columns, fields := structColumns(reflect.TypeFor[T]())
+	if len(columns) == 0 {
+		return 0, errBulkColumns
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	values := fieldValues[T](fields)
+
+	if copier, ok := db.(interface {
+		Connx(ctx context.Context) (*sqlx.Conn, error)
+	}); ok && isPostgres(db.DriverName()) {
+		return copyRows(ctx, copier, table, columns, rows, values)
+	}
+	return insertBatches(ctx, db, table, columns, rows, values, "", placeholders)
+}
+
+// insertBatches inserts the rows with multi-row INSERTs ending with suffix,
+// each within the given number of placeholders.
+func insertBatches[T any](ctx context.Context, db sqlx.ExtContext, table string, columns []string, rows []T, values func(T) []any, suffix string, placeholders int) (int64, error) {
+	batch := min(maxBulkRows, placeholders/len(columns))
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(columns, ", "))
+
+	var inserted int64
+	for start := 0; start < len(rows); start += batch {
+		chunk := rows[start:min(start+batch, len(rows))]
+		args := make([]any, 0, len(chunk)*len(columns))
+		for _, r := range chunk {
+			args = append(args, values(r)...)
+		}
+		query := prefix + strings.TrimSuffix(strings.Repeat(row+", ", len(chunk)), ", ") + suffix
+
+		res, err := db.ExecContext(ctx, db.Rebind(query), args...)
+		if err != nil {
+			return inserted, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += n
+	}
+	return inserted, nil
+}
+
+// fieldValues returns a function extracting the values of fields from a row.
+func fieldValues[T any](fields [][]int) func(T) []any {
+	return func(row T) []any {
+		v := reflect.ValueOf(row)
+		for v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		out := make([]any, len(fields))
+		for i, index := range fields {
+			out[i] = v.FieldByIndex(index).Interface()
+		}
+		return out
+	}
+}
+
+// copyRows streams the rows with the COPY protocol of pgx.
+func copyRows[T any](ctx context.Context, db interface {
+	Connx(ctx context.Context) (*sqlx.Conn, error)
+}, table string, columns []string, rows []T, values func(T) []any) (int64, error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var copied int64
+	err = conn.Raw(func(driverConn any) error {
+		if instrumented, ok := driverConn.(*instrumentedConn); ok {
+			driverConn = instrumented.Conn
+		}
+		pgConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("sql: COPY needs a pgx connection, got %T", driverConn)
+		}
+		rowsLeft := rows
+		copied, err = pgConn.Conn().CopyFrom(ctx, pgx.Identifier(strings.Split(table, ".")), columns,
+			pgx.CopyFromFunc(func() ([]any, error) {
+				if len(rowsLeft) == 0 {
+					return nil, nil
+				}
+				row := rowsLeft[0]
+				rowsLeft = rowsLeft[1:]
+				return values(row), nil
+			}))
+		return err
+	})
+	return copied, err
+}
+
+func isPostgres(driverName string) bool {
+	return driverName == "pgx" || driverName == "postgres"
+}
+
+// structColumns returns the column names and field indexes of the struct
+// t, flattening embedded structs.
+func structColumns(t reflect.Type) ([]string, [][]int) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var columns []string
+	var fields [][]int
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			embedded, indexes := structColumns(field.Type)
+			columns = append(columns, embedded...)
+			for _, index := range indexes {
+				fields = append(fields, append([]int{i}, index...))
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if tag == "" {
+			tag = sqlx.NameMapper(field.Name)
+		}
+		columns = append(columns, tag)
+		fields = append(fields, []int{i})
+	}
+	return columns, fields
+}
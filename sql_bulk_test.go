@@ -0,0 +1,87 @@
+package platigo
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type bulkAudit struct {
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type bulkRow struct {
+	ID    int    `db:"id"`
+	Email string `db:"email"`
+	Note  string `db:"-"`
+	bulkAudit
+}
+
+func TestBulkInsert(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []bulkRow{
+		{ID: 1, Email: "a@example.com", bulkAudit: bulkAudit{created}},
+		{ID: 2, Email: "b@example.com", bulkAudit: bulkAudit{created}},
+		{ID: 3, Email: "c@example.com", bulkAudit: bulkAudit{created}},
+	}
+
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Six placeholders fit two rows of three columns per statement.
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (id, email, created_at) VALUES (?, ?, ?), (?, ?, ?)")).
+		WithArgs(1, "a@example.com", created, 2, "b@example.com", created).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO users (id, email, created_at) VALUES (?, ?, ?)")).
+		WithArgs(3, "c@example.com", created).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := bulkInsert(context.Background(), sqlx.NewDb(conn, DriverMySQL), "users", rows, 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsertPostgresTx(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Transactions cannot COPY through database/sql and use INSERT instead.
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO app.users (id, email, created_at) VALUES ($1, $2, $3)")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	tx, err := sqlx.NewDb(conn, "pgx").Beginx()
+	require.NoError(t, err)
+	n, err := BulkInsert(context.Background(), tx, "app.users", []bulkRow{{ID: 1}})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, int64(1), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBulkInsertInvalid(t *testing.T) {
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db := sqlx.NewDb(conn, DriverMySQL)
+
+	_, err = BulkInsert(context.Background(), db, "users; DROP TABLE users", []bulkRow{{ID: 1}})
+	assert.ErrorIs(t, err, errInvalidIdentifier)
+
+	_, err = BulkInsert(context.Background(), db, "users", []int{1})
+	assert.ErrorIs(t, err, errBulkColumns)
+
+	n, err := BulkInsert(context.Background(), db, "users", []bulkRow(nil))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
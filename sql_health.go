@@ -0,0 +1,146 @@
+package platigo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bagastri07/platigo/health"
+)
+
+const defaultPoolSaturation = 0.9
+
+var errReplicaLagUnknown = errors.New("sql: replica does not report its lag")
+
+// PingCheck returns a check pinging the primary, reporting its latency.
+func (d *SQLDB) PingCheck() health.Check {
+	return func(ctx context.Context) health.Result {
+		started := time.Now()
+		err := d.DB.PingContext(ctx)
+		details := map[string]any{"latency_ms": time.Since(started).Milliseconds()}
+		if err != nil {
+			return health.Down(err, details)
+		}
+		return health.Up(details)
+	}
+}
+
+// PoolCheck returns a check reporting the usage of the connection pool of
+// the primary, degraded once the share of the maximum connections in use
+// reaches saturation, 0.9 when zero. Requests then start waiting for a
+// connection.
+func (d *SQLDB) PoolCheck(saturation float64) health.Check {
+	if saturation <= 0 {
+		saturation = defaultPoolSaturation
+	}
+	return func(ctx context.Context) health.Result {
+		stats := d.Stats()
+		details := map[string]any{
+			"open":             stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+			"max_open":         stats.MaxOpenConnections,
+			"wait_count":       stats.WaitCount,
+			"wait_duration_ms": stats.WaitDuration.Milliseconds(),
+		}
+		if stats.MaxOpenConnections > 0 && float64(stats.InUse)/float64(stats.MaxOpenConnections) >= saturation {
+			return health.Degraded(fmt.Sprintf("%d of %d connections in use", stats.InUse, stats.MaxOpenConnections), details)
+		}
+		return health.Up(details)
+	}
+}
+
+// ReplicationCheck returns a check reporting the replication lag of every
+// replica, degraded when one lags more than maxLag or is unavailable.
+// Reads are then served by the other databases, so losing replicas never
+// makes the check down.
+func (d *SQLDB) ReplicationCheck(maxLag time.Duration) health.Check {
+	return func(ctx context.Context) health.Result {
+		details := map[string]any{}
+		var reason string
+		for i, r := range d.replicas {
+			name := "replica_" + strconv.Itoa(i)
+			lag, err := replicationLag(ctx, r.db.DB, d.DriverName())
+			switch {
+			case err != nil:
+				details[name] = map[string]any{"error": err.Error()}
+				reason = name + " unavailable"
+			case lag > maxLag:
+				details[name] = map[string]any{"lag_ms": lag.Milliseconds()}
+				reason = fmt.Sprintf("%s lags %s", name, lag)
+			default:
+				details[name] = map[string]any{"lag_ms": lag.Milliseconds()}
+			}
+		}
+		if reason != "" {
+			return health.Degraded(reason, details)
+		}
+		return health.Up(details)
+	}
+}
+
+// replicationLag returns how far behind its primary a replica is.
+func replicationLag(ctx context.Context, db *sql.DB, driverName string) (time.Duration, error) {
+	switch driverName {
+	case DriverMySQL:
+		return mysqlReplicationLag(ctx, db)
+	default:
+		// A replica having replayed everything it received is up to date,
+		// however old its last transaction is.
+		var seconds sql.NullFloat64
+		err := db.QueryRowContext(ctx, `SELECT CASE
+	WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+END`).Scan(&seconds)
+		if err != nil {
+			return 0, err
+		}
+		if !seconds.Valid {
+			return 0, errReplicaLagUnknown
+		}
+		return time.Duration(seconds.Float64 * float64(time.Second)), nil
+	}
+}
+
+func mysqlReplicationLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errReplicaLagUnknown
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		// NULL when the replication threads are stopped.
+		seconds, err := strconv.ParseInt(string(values[i]), 10, 64)
+		if err != nil {
+			return 0, errReplicaLagUnknown
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, errReplicaLagUnknown
+}
@@ -0,0 +1,105 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bagastri07/platigo/health"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLDBPingCheck(t *testing.T) {
+	db, primary, _ := newTestReplicatedDB(t, 0)
+
+	primary.ExpectPing()
+	result := db.PingCheck()(context.Background())
+	assert.Equal(t, health.StatusUp, result.Status)
+	assert.Contains(t, result.Details, "latency_ms")
+
+	primary.ExpectPing().WillReturnError(errors.New("connection refused"))
+	result = db.PingCheck()(context.Background())
+	assert.Equal(t, health.StatusDown, result.Status)
+	assert.Equal(t, "connection refused", result.Error)
+}
+
+func TestSQLDBPoolCheck(t *testing.T) {
+	db, _, _ := newTestReplicatedDB(t, 0)
+	db.SetMaxOpenConns(10)
+
+	result := db.PoolCheck(0)(context.Background())
+	assert.Equal(t, health.StatusUp, result.Status)
+	assert.Equal(t, 10, result.Details["max_open"])
+}
+
+func TestSQLDBReplicationCheck(t *testing.T) {
+	const lagQuery = "SELECT CASE"
+
+	tests := []struct {
+		name       string
+		expect     func(mock sqlmock.Sqlmock)
+		wantStatus health.Status
+		wantLag    any
+	}{
+		{
+			name: "in sync",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lagQuery).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(0.25))
+			},
+			wantStatus: health.StatusUp,
+			wantLag:    int64(250),
+		},
+		{
+			name: "lagging",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lagQuery).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(12.0))
+			},
+			wantStatus: health.StatusDegraded,
+			wantLag:    int64(12000),
+		},
+		{
+			name: "not a replica",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lagQuery).WillReturnRows(sqlmock.NewRows([]string{"lag"}).AddRow(nil))
+			},
+			wantStatus: health.StatusDegraded,
+		},
+		{
+			name: "unavailable",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(lagQuery).WillReturnError(errors.New("connection refused"))
+			},
+			wantStatus: health.StatusDegraded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _, replicas := newTestReplicatedDB(t, 1)
+			tt.expect(replicas[0])
+
+			result := db.ReplicationCheck(5 * time.Second)(context.Background())
+			assert.Equal(t, tt.wantStatus, result.Status)
+			if tt.wantLag != nil {
+				assert.Equal(t, map[string]any{"lag_ms": tt.wantLag}, result.Details["replica_0"])
+			}
+			assert.NoError(t, replicas[0].ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMySQLReplicationLag(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(
+		sqlmock.NewRows([]string{"Replica_IO_State", "Seconds_Behind_Source"}).AddRow("Waiting for source", "3"))
+
+	lag, err := replicationLag(context.Background(), conn, DriverMySQL)
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, lag)
+}
@@ -0,0 +1,316 @@
+package platigo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxStatementLength = 2048
+
+var (
+	attrDBStatement = attribute.Key("db.statement")
+
+	// Literals are replaced by ? in the traced statements, since they may
+	// carry personal data. Numbers inside identifiers and $1 placeholders
+	// are kept.
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumberLiteral  = regexp.MustCompile(`(^|[^\w$.])-?\d+(?:\.\d+)?`)
+	sqlWhitespaceRuns = regexp.MustCompile(`\s+`)
+)
+
+// sqlInstrumentation traces and measures the statements of a database.
+type sqlInstrumentation struct {
+	tracer   trace.Tracer
+	metrics  *SQLMetrics
+	dbSystem attribute.KeyValue
+	slow     slowLog
+}
+
+// openInstrumentedDB opens a pool whose connections are instrumented.
+func openInstrumentedDB(d driver.Driver, dsn string, instr *sqlInstrumentation) (*sql.DB, error) {
+	var connector driver.Connector
+	if dc, ok := d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		connector = c
+	} else {
+		connector = dsnConnector{driver: d, dsn: dsn}
+	}
+	return sql.OpenDB(&instrumentedConnector{base: connector, instr: instr}), nil
+}
+
+// record instruments a statement run from started. Statements the driver
+// skipped, to be run again as prepared statements, are not recorded.
+func (i *sqlInstrumentation) record(ctx context.Context, operation, query string, started time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	statement := sanitizeStatement(query)
+	attrs := []attribute.KeyValue{i.dbSystem, attrDBOperation.String(operation)}
+	if statement != "" {
+		attrs = append(attrs, attrDBStatement.String(statement))
+	}
+	_, span := i.tracer.Start(ctx, "sql."+operation, defaultSpanStartOpt,
+		trace.WithTimestamp(started), trace.WithAttributes(attrs...))
+	failed := err != nil
+	if failed {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	now := time.Now()
+	span.End(trace.WithTimestamp(now))
+
+	i.metrics.observe(operation, now.Sub(started), failed)
+
+	fields := logger.Fields{"operation": operation, "statement": statement}
+	if failed {
+		fields["error"] = err.Error()
+	}
+	i.slow.observe(ctx, now.Sub(started), "Slow SQL statement", fields)
+}
+
+// statementOperation returns the lowercase first keyword of a statement.
+func statementOperation(query string) string {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z')
+	})
+	if end >= 0 {
+		query = query[:end]
+	}
+	if query == "" {
+		return "other"
+	}
+	return strings.ToLower(query)
+}
+
+// sanitizeStatement replaces the literals of a statement by ? and collapses
+// its whitespace.
+func sanitizeStatement(query string) string {
+	query = sqlStringLiteral.ReplaceAllString(query, "?")
+	query = sqlNumberLiteral.ReplaceAllString(query, "${1}?")
+	query = strings.TrimSpace(sqlWhitespaceRuns.ReplaceAllString(query, " "))
+	if len(query) > maxStatementLength {
+		query = query[:maxStatementLength]
+	}
+	return query
+}
+
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type instrumentedConnector struct {
+	base  driver.Connector
+	instr *sqlInstrumentation
+}
+
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, instr: c.instr}, nil
+}
+
+func (c *instrumentedConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// instrumentedConn forwards to the driver connection, falling back to
+// driver.ErrSkip, or to what database/sql does, for the optional
+// interfaces the driver does not implement.
+type instrumentedConn struct {
+	driver.Conn
+	instr *sqlInstrumentation
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	started := time.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	c.instr.record(ctx, statementOperation(query), query, started, err)
+	return res, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	started := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.instr.record(ctx, statementOperation(query), query, started, err)
+	return rows, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, instr: c.instr}, nil
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	started := time.Now()
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin() //nolint:staticcheck // fallback of drivers without BeginTx
+	}
+	c.instr.record(ctx, "begin", "", started, err)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedTx{Tx: tx, ctx: ctx, instr: c.instr}, nil
+}
+
+func (c *instrumentedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue keeps the argument types of drivers converting them
+// themselves, such as pgx.
+func (c *instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type instrumentedStmt struct {
+	driver.Stmt
+	query string
+	instr *sqlInstrumentation
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	started := time.Now()
+	var res driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			res, err = s.Stmt.Exec(values) //nolint:staticcheck // fallback of drivers without ExecContext
+		}
+	}
+	s.instr.record(ctx, statementOperation(s.query), s.query, started, err)
+	return res, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	started := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValues(args); err == nil {
+			rows, err = s.Stmt.Query(values) //nolint:staticcheck // fallback of drivers without QueryContext
+		}
+	}
+	s.instr.record(ctx, statementOperation(s.query), s.query, started, err)
+	return rows, err
+}
+
+func (s *instrumentedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type instrumentedTx struct {
+	driver.Tx
+	ctx   context.Context
+	instr *sqlInstrumentation
+}
+
+func (t *instrumentedTx) Commit() error {
+	started := time.Now()
+	err := t.Tx.Commit()
+	t.instr.record(t.ctx, "commit", "", started, err)
+	return err
+}
+
+func (t *instrumentedTx) Rollback() error {
+	started := time.Now()
+	err := t.Tx.Rollback()
+	t.instr.record(t.ctx, "rollback", "", started, err)
+	return err
+}
+
+var errNamedArgs = errors.New("sql: driver does not support named arguments")
+
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,112 @@
+package platigo
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestInstrumentedDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *tracetest.SpanRecorder, *SQLMetrics) {
+	t.Helper()
+
+	dsn := t.Name()
+	mockDB, mock, err := sqlmock.NewWithDSN(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mockDB.Close() })
+
+	recorder := tracetest.NewSpanRecorder()
+	metrics := NewSQLMetrics("test")
+	instr := &sqlInstrumentation{
+		tracer:   newTracer(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		metrics:  metrics,
+		dbSystem: attrDBSystem.String("postgresql"),
+	}
+
+	db, err := openInstrumentedDB(mockDB.Driver(), dsn, instr)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return db, mock, recorder, metrics
+}
+
+func TestSQLInstrumentationStatements(t *testing.T) {
+	db, mock, recorder, metrics := newTestInstrumentedDB(t)
+	ctx := context.Background()
+
+	mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("UPDATE").WillReturnError(assert.AnError)
+
+	rows, err := db.QueryContext(ctx, "SELECT id FROM users WHERE email = 'jane@example.com' AND age > 30 AND id = $1", 7)
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+	_, err = db.ExecContext(ctx, "UPDATE users SET name = $1", "Jane")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "sql.select", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.system", "postgresql"))
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", "SELECT id FROM users WHERE email = ? AND age > ? AND id = $1"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "sql.update", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.queries.WithLabelValues("select")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.errors.WithLabelValues("update")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.errors.WithLabelValues("select")))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLInstrumentationTransaction(t *testing.T) {
+	db, mock, recorder, _ := newTestInstrumentedDB(t)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "INSERT INTO orders (id) VALUES ($1)", 1)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"sql.begin", "sql.insert", "sql.commit"}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "SELECT * FROM t WHERE name = 'O''Brien'", want: "SELECT * FROM t WHERE name = ?"},
+		{query: "SELECT  *\n\tFROM t2 WHERE x = -1.5 LIMIT 10", want: "SELECT * FROM t2 WHERE x = ? LIMIT ?"},
+		{query: "INSERT INTO t (a) VALUES ($1), (?)", want: "INSERT INTO t (a) VALUES ($1), (?)"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, sanitizeStatement(tt.query))
+	}
+}
+
+func TestStatementOperation(t *testing.T) {
+	assert.Equal(t, "select", statementOperation("  SELECT 1"))
+	assert.Equal(t, "with", statementOperation("WITH x AS (SELECT 1) SELECT * FROM x"))
+	assert.Equal(t, "select", statementOperation("(SELECT 1) UNION (SELECT 2)"))
+	assert.Equal(t, "other", statementOperation(""))
+}
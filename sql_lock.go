@@ -0,0 +1,149 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrLockNotAvailable is matched by errors.Is when a row lock could not be
+// taken: with LockNoWait as soon as another transaction holds it, otherwise
+// once the lock timeout elapsed.
+var ErrLockNotAvailable = errors.New("sql: row lock not available")
+
+var errLockQuery = errors.New("sql: lock query must be a SELECT")
+
+// LockWait is how a locking read handles rows locked by other transactions.
+type LockWait int
+
+const (
+	// LockBlock waits for the locks, up to the lock timeout.
+	LockBlock LockWait = iota
+	// LockNoWait fails with ErrLockNotAvailable instead of waiting.
+	LockNoWait
+	// LockSkipLocked leaves the locked rows out of the result, so workers
+	// claiming jobs with the same query each get different rows.
+	LockSkipLocked
+)
+
+// LockError is returned by the locking reads failing to take a lock.
+type LockError struct {
+	Err error
+}
+
+func (e *LockError) Error() string {
+	return "sql: row lock not available: " + e.Err.Error()
+}
+
+func (e *LockError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes the error match ErrLockNotAvailable.
+func (e *LockError) Is(target error) bool {
+	return target == ErrLockNotAvailable
+}
+
+// RowLock runs SELECT ... FOR UPDATE and FOR SHARE queries, in the
+// transaction holding the locks:
+//
+//	claim := platigo.RowLock{Wait: platigo.LockSkipLocked}
+//	err := claim.Get(ctx, tx, &job, "SELECT * FROM jobs WHERE status = 'queued' ORDER BY id LIMIT 1")
+//	if errors.Is(err, sql.ErrNoRows) {
+//		// No job, or all of them claimed by other workers.
+//	}
+type RowLock struct {
+	Wait LockWait
+	// Share takes shared locks, FOR SHARE, instead of exclusive ones.
+	Share bool
+	// Timeout bounds the wait for the locks with LockBlock, the server setting
+	// by default. It is set for the statement on MySQL, and for the rest of
+	// the transaction on Postgres.
+	Timeout time.Duration
+}
+
+// LockRow locks the row of query for update, waiting for it, and scans it
+// into dest like sqlx.Get.
+func LockRow(ctx context.Context, tx sqlx.ExtContext, dest any, query string, args ...any) error {
+	return RowLock{}.Get(ctx, tx, dest, query, args...)
+}
+
+// Get locks the row of query and scans it into dest like sqlx.Get.
+func (l RowLock) Get(ctx context.Context, tx sqlx.ExtContext, dest any, query string, args ...any) error {
+	query, err := l.prepare(ctx, tx, query)
+	if err != nil {
+		return err
+	}
+	return lockError(sqlx.GetContext(ctx, tx, dest, query, args...))
+}
+
+// Select locks the rows of query and scans them into dest like sqlx.Select.
+func (l RowLock) Select(ctx context.Context, tx sqlx.ExtContext, dest any, query string, args ...any) error {
+	query, err := l.prepare(ctx, tx, query)
+	if err != nil {
+		return err
+	}
+	return lockError(sqlx.SelectContext(ctx, tx, dest, query, args...))
+}
+
+// prepare appends the locking clause to query and applies the timeout.
+func (l RowLock) prepare(ctx context.Context, tx sqlx.ExtContext, query string) (string, error) {
+	query = strings.TrimRight(strings.TrimSpace(query), ";")
+	if len(query) < 6 || !strings.EqualFold(query[:6], "SELECT") {
+		return "", errLockQuery
+	}
+
+	if l.Timeout > 0 && l.Wait == LockBlock {
+		if isPostgres(tx.DriverName()) {
+			// SET does not take parameters.
+			setTimeout := fmt.Sprintf("SET LOCAL lock_timeout = %d", l.Timeout.Milliseconds())
+			if _, err := tx.ExecContext(ctx, setTimeout); err != nil {
+				return "", err
+			}
+		} else {
+			seconds := int(math.Ceil(l.Timeout.Seconds()))
+			query = fmt.Sprintf("SELECT /*+ SET_VAR(innodb_lock_wait_timeout=%d) */%s", seconds, query[6:])
+		}
+	}
+
+	clause := " FOR UPDATE"
+	if l.Share {
+		clause = " FOR SHARE"
+	}
+	switch l.Wait {
+	case LockNoWait:
+		clause += " NOWAIT"
+	case LockSkipLocked:
+		clause += " SKIP LOCKED"
+	}
+	return query + clause, nil
+}
+
+// lockError wraps the errors of locks not taken in a LockError.
+func lockError(err error) error {
+	if isLockNotAvailable(err) {
+		return &LockError{Err: err}
+	}
+	return err
+}
+
+func isLockNotAvailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// lock_not_available, for NOWAIT and lock_timeout alike.
+		return pgErr.Code == "55P03"
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1205 || // ER_LOCK_WAIT_TIMEOUT
+			myErr.Number == 3572 // ER_LOCK_NOWAIT
+	}
+	return false
+}
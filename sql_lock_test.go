@@ -0,0 +1,136 @@
+package platigo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowLock(t *testing.T) {
+	const query = "SELECT id FROM jobs WHERE status = 'queued' ORDER BY id LIMIT 1"
+
+	tests := []struct {
+		name       string
+		driver     string
+		lock       RowLock
+		wantSetSQL string
+		wantSQL    string
+		queryErr   error
+		wantErr    error
+	}{
+		{
+			name:    "wait",
+			driver:  "pgx",
+			wantSQL: query + " FOR UPDATE",
+		},
+		{
+			name:    "skip locked shared",
+			driver:  "pgx",
+			lock:    RowLock{Wait: LockSkipLocked, Share: true},
+			wantSQL: query + " FOR SHARE SKIP LOCKED",
+		},
+		{
+			name:     "nowait on a locked row",
+			driver:   "pgx",
+			lock:     RowLock{Wait: LockNoWait},
+			wantSQL:  query + " FOR UPDATE NOWAIT",
+			queryErr: &pgconn.PgError{Code: "55P03"},
+			wantErr:  ErrLockNotAvailable,
+		},
+		{
+			name:       "postgres timeout",
+			driver:     "pgx",
+			lock:       RowLock{Timeout: 2 * time.Second},
+			wantSetSQL: "SET LOCAL lock_timeout = 2000",
+			wantSQL:    query + " FOR UPDATE",
+		},
+		{
+			name:     "mysql timeout",
+			driver:   DriverMySQL,
+			lock:     RowLock{Timeout: 1500 * time.Millisecond},
+			wantSQL:  "SELECT /*+ SET_VAR(innodb_lock_wait_timeout=2) */ id FROM jobs WHERE status = 'queued' ORDER BY id LIMIT 1 FOR UPDATE",
+			queryErr: &mysql.MySQLError{Number: 1205},
+			wantErr:  ErrLockNotAvailable,
+		},
+		{
+			name:     "no row",
+			driver:   "pgx",
+			lock:     RowLock{Wait: LockSkipLocked},
+			wantSQL:  query + " FOR UPDATE SKIP LOCKED",
+			queryErr: sql.ErrNoRows,
+			wantErr:  sql.ErrNoRows,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer conn.Close()
+
+			mock.ExpectBegin()
+			if tt.wantSetSQL != "" {
+				mock.ExpectExec(regexp.QuoteMeta(tt.wantSetSQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			expect := mock.ExpectQuery("^" + regexp.QuoteMeta(tt.wantSQL) + "$")
+			if tt.queryErr != nil {
+				expect.WillReturnError(tt.queryErr)
+			} else {
+				expect.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			}
+
+			tx, err := sqlx.NewDb(conn, tt.driver).Beginx()
+			require.NoError(t, err)
+
+			var id int
+			err = tt.lock.Get(context.Background(), tx, &id, query+";")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, 7, id)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestLockRowsSelect(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id FROM jobs LIMIT 2 FOR UPDATE SKIP LOCKED")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+
+	tx, err := sqlx.NewDb(conn, "pgx").Beginx()
+	require.NoError(t, err)
+
+	var ids []int
+	require.NoError(t, RowLock{Wait: LockSkipLocked}.Select(context.Background(), tx, &ids, "SELECT id FROM jobs LIMIT 2"))
+	assert.Equal(t, []int{1, 2}, ids)
+
+	var id int
+	assert.ErrorIs(t, LockRow(context.Background(), tx, &id, "UPDATE jobs SET status = 'running'"), errLockQuery)
+}
+
+func TestLockError(t *testing.T) {
+	err := lockError(&mysql.MySQLError{Number: 3572, Message: "Statement aborted because lock(s) could not be acquired immediately and NOWAIT is set."})
+	var lockErr *LockError
+	require.True(t, errors.As(err, &lockErr))
+	assert.ErrorIs(t, err, ErrLockNotAvailable)
+
+	other := errors.New("boom")
+	assert.Same(t, other, lockError(other))
+}
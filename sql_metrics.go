@@ -0,0 +1,74 @@
+package platigo
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SQLMetrics holds the Prometheus metrics of SQLDB, labeled by statement
+// operation such as select or insert. It implements prometheus.Collector:
+//
+//	metrics := platigo.NewSQLMetrics("myservice")
+//	prometheus.MustRegister(metrics)
+//	db, err := platigo.NewSQLDB(&platigo.DBConfig{Metrics: metrics})
+type SQLMetrics struct {
+	queries *prometheus.CounterVec
+	errors  *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewSQLMetrics creates the database metrics under the given namespace.
+func NewSQLMetrics(namespace string) *SQLMetrics {
+	labels := []string{"operation"}
+
+	return &SQLMetrics{
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sql",
+			Name:      "queries_total",
+			Help:      "Total number of SQL statements.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "sql",
+			Name:      "errors_total",
+			Help:      "Total number of failed SQL statements.",
+		}, labels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "sql",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of SQL statements.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, labels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *SQLMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.queries.Describe(ch)
+	m.errors.Describe(ch)
+	m.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *SQLMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.queries.Collect(ch)
+	m.errors.Collect(ch)
+	m.latency.Collect(ch)
+}
+
+// observe records one statement. It is a no-op on a nil receiver so the
+// database works without metrics configured.
+func (m *SQLMetrics) observe(operation string, elapsed time.Duration, failed bool) {
+	if m == nil {
+		return
+	}
+
+	m.queries.WithLabelValues(operation).Inc()
+	if failed {
+		m.errors.WithLabelValues(operation).Inc()
+	}
+	m.latency.WithLabelValues(operation).Observe(elapsed.Seconds())
+}
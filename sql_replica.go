@@ -0,0 +1,142 @@
+package platigo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/jmoiron/sqlx"
+)
+
+// replica is a read replica and its last known health.
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// WithPrimaryDB makes the reads of SQLDB with the returned context run on
+// the primary, e.g. to read a row right after writing it, before it reached
+// the replicas.
+func WithPrimaryDB(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ReadDB returns the database to read from: a healthy replica picked in
+// turn, or the primary without replicas, when none is healthy or ctx comes
+// from WithPrimaryDB.
+func (d *SQLDB) ReadDB(ctx context.Context) *sqlx.DB {
+	if len(d.replicas) == 0 || ctx.Value(primaryKey{}) != nil {
+		return d.DB
+	}
+	start := d.next.Add(1)
+	for i := range len(d.replicas) {
+		r := d.replicas[(start+uint64(i))%uint64(len(d.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return d.DB
+}
+
+func (d *SQLDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.ReadDB(ctx).QueryContext(ctx, query, args...)
+}
+
+func (d *SQLDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.ReadDB(ctx).QueryRowContext(ctx, query, args...)
+}
+
+func (d *SQLDB) QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error) {
+	return d.ReadDB(ctx).QueryxContext(ctx, query, args...)
+}
+
+func (d *SQLDB) QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row {
+	return d.ReadDB(ctx).QueryRowxContext(ctx, query, args...)
+}
+
+func (d *SQLDB) GetContext(ctx context.Context, dest any, query string, args ...any) error {
+	return d.ReadDB(ctx).GetContext(ctx, dest, query, args...)
+}
+
+func (d *SQLDB) SelectContext(ctx context.Context, dest any, query string, args ...any) error {
+	return d.ReadDB(ctx).SelectContext(ctx, dest, query, args...)
+}
+
+func (d *SQLDB) Query(query string, args ...any) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+func (d *SQLDB) QueryRow(query string, args ...any) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+func (d *SQLDB) Queryx(query string, args ...any) (*sqlx.Rows, error) {
+	return d.QueryxContext(context.Background(), query, args...)
+}
+
+func (d *SQLDB) QueryRowx(query string, args ...any) *sqlx.Row {
+	return d.QueryRowxContext(context.Background(), query, args...)
+}
+
+func (d *SQLDB) Get(dest any, query string, args ...any) error {
+	return d.GetContext(context.Background(), dest, query, args...)
+}
+
+func (d *SQLDB) Select(dest any, query string, args ...any) error {
+	return d.SelectContext(context.Background(), dest, query, args...)
+}
+
+// Close stops the health checks and closes the primary and the replicas.
+func (d *SQLDB) Close() error {
+	var errs []error
+	d.closeOnce.Do(func() {
+		if d.stop != nil {
+			close(d.stop)
+		}
+		d.done.Wait()
+		for _, r := range d.replicas {
+			errs = append(errs, r.db.Close())
+		}
+		errs = append(errs, d.DB.Close())
+	})
+	return errors.Join(errs...)
+}
+
+func (d *SQLDB) checkReplicasEvery(interval time.Duration) {
+	defer d.done.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			d.checkReplicas(ctx)
+			cancel()
+		}
+	}
+}
+
+// checkReplicas pings every replica and records which ones answered.
+func (d *SQLDB) checkReplicas(ctx context.Context) {
+	for i, r := range d.replicas {
+		err := r.db.PingContext(ctx)
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		log := d.log.With(logger.Fields{"replica": i})
+		if healthy {
+			log.Info("SQL replica available again")
+		} else {
+			log.With(logger.Fields{"error": err.Error()}).Warn("SQL replica unavailable, reading from the other databases")
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package platigo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// StatementClass selects the SQLRetryPolicy of a statement.
+type StatementClass int
+
+const (
+	// StatementRead is a query without side effects.
+	StatementRead StatementClass = iota
+	// StatementWrite is a single statement with side effects, outside of a
+	// transaction.
+	StatementWrite
+	// StatementTx is a whole transaction, e.g. a txmanager RunInTx.
+	StatementTx
+)
+
+// SQLRetryPolicy configures the retries of a class of statements.
+type SQLRetryPolicy struct {
+	// MaxAttempts is the number of runs of the statement, 1 disabling the
+	// retries. Backoff returns the pause before the given attempt.
+	MaxAttempts int
+	Backoff     func(attempt int) time.Duration
+
+	// RetryConnectionErrors also retries when the connection broke while
+	// the statement ran, which the database may have applied anyway.
+	// Serialization failures, deadlocks, lock timeouts and connections
+	// broken before sending the statement are always retried.
+	RetryConnectionErrors bool
+}
+
+// SQLRetryConfig configures a SQLRetrier. Nil policies default to 3
+// attempts for reads and writes and 5 for transactions, with a backoff from
+// 50 milliseconds up to a second, and connection errors only retried for
+// reads.
+type SQLRetryConfig struct {
+	Read  *SQLRetryPolicy
+	Write *SQLRetryPolicy
+	Tx    *SQLRetryPolicy
+
+	// Logger receives the retries. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// SQLRetrier runs statements again when they fail with a transient error.
+//
+// Retrying a single statement of a transaction does not help, the database
+// aborted the whole transaction: retry the transaction with StatementTx.
+//
+//	err := retrier.Do(ctx, platigo.StatementTx, func(ctx context.Context) error {
+//		return manager.RunInTx(ctx, chargePayment, txmanager.WithIsolation(sql.LevelSerializable))
+//	})
+type SQLRetrier struct {
+	policies map[StatementClass]SQLRetryPolicy
+	log      logger.Logger
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// NewSQLRetrier creates a SQLRetrier.
+func NewSQLRetrier(config SQLRetryConfig) *SQLRetrier {
+	backoff := ExponentialBackoff(50*time.Millisecond, time.Second)
+	policy := func(p *SQLRetryPolicy, def SQLRetryPolicy) SQLRetryPolicy {
+		if p == nil {
+			return def
+		}
+		if p.MaxAttempts <= 0 {
+			p.MaxAttempts = 1
+		}
+		if p.Backoff == nil {
+			p.Backoff = backoff
+		}
+		return *p
+	}
+
+	return &SQLRetrier{
+		policies: map[StatementClass]SQLRetryPolicy{
+			StatementRead:  policy(config.Read, SQLRetryPolicy{MaxAttempts: 3, Backoff: backoff, RetryConnectionErrors: true}),
+			StatementWrite: policy(config.Write, SQLRetryPolicy{MaxAttempts: 3, Backoff: backoff}),
+			StatementTx:    policy(config.Tx, SQLRetryPolicy{MaxAttempts: 5, Backoff: backoff}),
+		},
+		log:   logger.WithLevel(config.Logger, logger.InfoLevel),
+		sleep: sleepContext,
+	}
+}
+
+// Do runs fn until it succeeds, fails with an error not retried by the
+// policy of class, or runs out of attempts.
+func (r *SQLRetrier) Do(ctx context.Context, class StatementClass, fn func(ctx context.Context) error) error {
+	policy := r.policies[class]
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts || !policy.retryable(err) {
+			return err
+		}
+
+		logger.WithContext(ctx, r.log).With(logger.Fields{"attempt": attempt, "error": err.Error()}).Warn("Retrying SQL statement after transient error")
+		if sleepErr := r.sleep(ctx, policy.Backoff(attempt)); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// Get runs a read into dest.
+func (r *SQLRetrier) Get(ctx context.Context, db sqlx.QueryerContext, dest any, query string, args ...any) error {
+	return r.Do(ctx, StatementRead, func(ctx context.Context) error {
+		return sqlx.GetContext(ctx, db, dest, query, args...)
+	})
+}
+
+// Select runs a read into the slice dest.
+func (r *SQLRetrier) Select(ctx context.Context, db sqlx.QueryerContext, dest any, query string, args ...any) error {
+	return r.Do(ctx, StatementRead, func(ctx context.Context) error {
+		return sqlx.SelectContext(ctx, db, dest, query, args...)
+	})
+}
+
+// Exec runs a write.
+func (r *SQLRetrier) Exec(ctx context.Context, db sqlx.ExecerContext, query string, args ...any) (rowsAffected int64, err error) {
+	err = r.Do(ctx, StatementWrite, func(ctx context.Context) error {
+		res, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err = res.RowsAffected()
+		return err
+	})
+	return rowsAffected, err
+}
+
+func (p SQLRetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return isRolledBackSQLError(err) || (p.RetryConnectionErrors && isConnectionSQLError(err))
+}
+
+// IsTransientSQLError reports whether err may go away by running the
+// statement again: a serialization failure, deadlock, lock timeout or
+// broken connection.
+func IsTransientSQLError(err error) bool {
+	return isRolledBackSQLError(err) || isConnectionSQLError(err)
+}
+
+// isRolledBackSQLError reports whether the statement surely had no effect:
+// the database rolled it back, or it was never sent.
+func isRolledBackSQLError(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"55P03": // lock_not_available
+			return true
+		}
+		return false
+	}
+
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case 1205, // ER_LOCK_WAIT_TIMEOUT
+			1213: // ER_LOCK_DEADLOCK
+			return true
+		}
+	}
+	return false
+}
+
+// isConnectionSQLError reports whether the connection broke, possibly after
+// the statement was applied.
+func isConnectionSQLError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Class 08 is connection_exception.
+		return strings.HasPrefix(pgErr.Code, "08")
+	}
+	if errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,108 @@
+package platigo
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransientSQLError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "postgres deadlock", err: fmt.Errorf("charge: %w", &pgconn.PgError{Code: "40P01"}), want: true},
+		{name: "postgres connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: false},
+		{name: "mysql deadlock", err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "mysql duplicate entry", err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "bad connection", err: driver.ErrBadConn, want: true},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "other", err: errors.New("syntax error"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransientSQLError(tt.err))
+		})
+	}
+}
+
+func TestSQLRetrierDo(t *testing.T) {
+	connReset := fmt.Errorf("read: %w", syscall.ECONNRESET)
+	deadlock := &pgconn.PgError{Code: "40P01"}
+
+	tests := []struct {
+		name      string
+		class     StatementClass
+		errs      []error
+		wantErr   error
+		wantCalls int
+	}{
+		{name: "read retried on connection reset", class: StatementRead, errs: []error{connReset, nil}, wantCalls: 2},
+		{name: "write not retried on connection reset", class: StatementWrite, errs: []error{connReset}, wantErr: connReset, wantCalls: 1},
+		{name: "write retried on deadlock", class: StatementWrite, errs: []error{deadlock, nil}, wantCalls: 2},
+		{name: "transaction gives up", class: StatementTx, errs: []error{deadlock}, wantErr: deadlock, wantCalls: 5},
+		{name: "permanent error", class: StatementRead, errs: []error{assert.AnError}, wantErr: assert.AnError, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSQLRetrier(SQLRetryConfig{})
+			r.sleep = func(context.Context, time.Duration) error { return nil }
+
+			calls := 0
+			err := r.Do(context.Background(), tt.class, func(context.Context) error {
+				err := tt.errs[min(calls, len(tt.errs)-1)]
+				calls++
+				return err
+			})
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestSQLRetrierPolicy(t *testing.T) {
+	r := NewSQLRetrier(SQLRetryConfig{Write: &SQLRetryPolicy{MaxAttempts: 2, RetryConnectionErrors: true}})
+	r.sleep = func(context.Context, time.Duration) error { return nil }
+
+	calls := 0
+	err := r.Do(context.Background(), StatementWrite, func(context.Context) error {
+		calls++
+		return syscall.ECONNRESET
+	})
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+	assert.Equal(t, 2, calls)
+}
+
+func TestSQLRetrierExec(t *testing.T) {
+	conn, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db := sqlx.NewDb(conn, "sqlmock")
+
+	mock.ExpectExec("UPDATE payments").WillReturnError(&mysql.MySQLError{Number: 1213})
+	mock.ExpectExec("UPDATE payments").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	r := NewSQLRetrier(SQLRetryConfig{})
+	r.sleep = func(context.Context, time.Duration) error { return nil }
+
+	affected, err := r.Exec(context.Background(), db, "UPDATE payments SET status = 'paid' WHERE id = ?", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
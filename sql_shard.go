@@ -0,0 +1,147 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+const defaultVirtualNodes = 128
+
+var errShardsRequired = errors.New("sql: at least one shard is required")
+
+type ShardConfig struct {
+	// Shards maps the name of each shard to the configuration of its pool.
+	// Keys are placed on the shards by hashing the names, so renaming a
+	// shard moves its keys while adding one only moves about 1/N of them.
+	Shards map[string]*DBConfig
+
+	// VirtualNodes is the number of points of each shard on the hash ring,
+	// 128 by default. More points spread the keys more evenly.
+	VirtualNodes int
+}
+
+// ShardRouter routes tenant or shard keys to one of several databases with
+// consistent hashing. It owns the pools and closes them with Close.
+//
+//	db := router.DB(tenantID)
+//	err := db.GetContext(ctx, &order, "SELECT * FROM orders WHERE id = $1", id)
+type ShardRouter struct {
+	shards map[string]*SQLDB
+	ring   *hashRing
+}
+
+// NewShardRouter opens the pool of every shard. Like NewSQLDB it does not
+// connect, call Ping to check the shards at startup.
+func NewShardRouter(config *ShardConfig) (*ShardRouter, error) {
+	if len(config.Shards) == 0 {
+		return nil, errShardsRequired
+	}
+
+	r := &ShardRouter{shards: make(map[string]*SQLDB, len(config.Shards))}
+	names := make([]string, 0, len(config.Shards))
+	for name, dbConfig := range config.Shards {
+		db, err := NewSQLDB(dbConfig)
+		if err != nil {
+			_ = r.Close()
+			return nil, fmt.Errorf("sql: shard %s: %w", name, err)
+		}
+		r.shards[name] = db
+		names = append(names, name)
+	}
+	r.ring = newHashRing(names, config.VirtualNodes)
+	return r, nil
+}
+
+// Shard returns the name of the shard owning key.
+func (r *ShardRouter) Shard(key string) string {
+	return r.ring.get(key)
+}
+
+// DB returns the database of the shard owning key.
+func (r *ShardRouter) DB(key string) *SQLDB {
+	return r.shards[r.ring.get(key)]
+}
+
+// Shards returns the names of the shards, sorted.
+func (r *ShardRouter) Shards() []string {
+	names := make([]string, 0, len(r.shards))
+	for name := range r.shards {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Each calls fn with every shard in name order, e.g. to run a migration or
+// a query spanning all tenants, and returns the errors joined.
+func (r *ShardRouter) Each(ctx context.Context, fn func(ctx context.Context, shard string, db *SQLDB) error) error {
+	var errs []error
+	for _, name := range r.Shards() {
+		if err := fn(ctx, name, r.shards[name]); err != nil {
+			errs = append(errs, fmt.Errorf("sql: shard %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ping pings every shard.
+func (r *ShardRouter) Ping(ctx context.Context) error {
+	return r.Each(ctx, func(ctx context.Context, _ string, db *SQLDB) error {
+		return db.Ping(ctx)
+	})
+}
+
+// Close closes the pools of every shard.
+func (r *ShardRouter) Close() error {
+	var errs []error
+	for _, db := range r.shards {
+		if err := db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hashRing is a consistent hash ring with virtual nodes.
+type hashRing struct {
+	points []uint64
+	owners map[uint64]string
+}
+
+func newHashRing(names []string, virtualNodes int) *hashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = defaultVirtualNodes
+	}
+	ring := &hashRing{owners: make(map[uint64]string, len(names)*virtualNodes)}
+	for _, name := range names {
+		for i := range virtualNodes {
+			point := xxhash.Sum64String(name + "#" + strconv.Itoa(i))
+			// On the unlikely collision the smallest name wins, so the
+			// ring does not depend on the order of names.
+			if owner, ok := ring.owners[point]; !ok {
+				ring.points = append(ring.points, point)
+			} else if owner < name {
+				continue
+			}
+			ring.owners[point] = name
+		}
+	}
+	slices.Sort(ring.points)
+	return ring
+}
+
+// get returns the owner of the first point following the hash of key.
+func (h *hashRing) get(key string) string {
+	sum := xxhash.Sum64String(key)
+	i := sort.Search(len(h.points), func(i int) bool { return h.points[i] >= sum })
+	if i == len(h.points) {
+		i = 0
+	}
+	return h.owners[h.points[i]]
+}
@@ -0,0 +1,68 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashRing(t *testing.T) {
+	ring := newHashRing([]string{"shard-a", "shard-b", "shard-c"}, 0)
+
+	counts := map[string]int{}
+	owners := map[string]string{}
+	for i := range 3000 {
+		key := fmt.Sprintf("tenant-%d", i)
+		owners[key] = ring.get(key)
+		counts[owners[key]]++
+	}
+	for shard, n := range counts {
+		assert.InDelta(t, 1000, n, 250, shard)
+	}
+
+	// Adding a shard only moves keys to it.
+	grown := newHashRing([]string{"shard-c", "shard-a", "shard-b", "shard-d"}, 0)
+	moved := 0
+	for key, owner := range owners {
+		if got := grown.get(key); got != owner {
+			assert.Equal(t, "shard-d", got)
+			moved++
+		}
+	}
+	assert.InDelta(t, 750, moved, 250)
+}
+
+func TestShardRouter(t *testing.T) {
+	_, err := NewShardRouter(&ShardConfig{})
+	assert.ErrorIs(t, err, errShardsRequired)
+
+	_, err = NewShardRouter(&ShardConfig{Shards: map[string]*DBConfig{"eu": {Driver: "sqlite"}}})
+	assert.ErrorIs(t, err, errSQLDriver)
+
+	router, err := NewShardRouter(&ShardConfig{Shards: map[string]*DBConfig{
+		"eu": {Driver: DriverPostgres, Host: "eu.db.internal", Database: "orders"},
+		"us": {Driver: DriverPostgres, Host: "us.db.internal", Database: "orders"},
+	}})
+	require.NoError(t, err)
+	defer router.Close()
+
+	assert.Equal(t, []string{"eu", "us"}, router.Shards())
+	shard := router.Shard("tenant-42")
+	assert.Equal(t, shard, router.Shard("tenant-42"))
+	assert.Same(t, router.shards[shard], router.DB("tenant-42"))
+
+	var visited []string
+	err = router.Each(context.Background(), func(_ context.Context, shard string, _ *SQLDB) error {
+		visited = append(visited, shard)
+		if shard == "us" {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	assert.Equal(t, []string{"eu", "us"}, visited)
+	assert.EqualError(t, err, "sql: shard us: boom")
+}
@@ -0,0 +1,74 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var errUpsertConflict = errors.New("sql: upsert needs the conflict columns")
+
+// Upsert inserts rows into table, updating the existing rows conflicting on
+// the conflict columns instead, so the write can be repeated safely. The
+// columns come from the db tags of T like with BulkInsert, and every column
+// but the conflict ones is overwritten.
+//
+// Postgres uses ON CONFLICT (conflict) DO UPDATE and MySQL ON DUPLICATE KEY
+// UPDATE, where conflict has to match the primary key or a unique index. The
+// returned count is the driver's one: MySQL counts an updated row twice.
+func Upsert[T any](ctx context.Context, db sqlx.ExtContext, table string, conflict []string, rows []T) (int64, error) {
+	if !columnName.MatchString(table) {
+		return 0, errInvalidIdentifier
+	}
+	if len(conflict) == 0 {
+		return 0, errUpsertConflict
+	}
+	columns, fields := structColumns(reflect.TypeFor[T]())
+	if len(columns) == 0 {
+		return 0, errBulkColumns
+	}
+	for _, column := range conflict {
+		if !slices.Contains(columns, column) {
+			return 0, fmt.Errorf("sql: conflict column %q is not a field of %s", column, reflect.TypeFor[T]())
+		}
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+
+	suffix := upsertClause(db.DriverName(), columns, conflict)
+	return insertBatches(ctx, db, table, columns, rows, fieldValues[T](fields), suffix, maxPlaceholders)
+}
+
+// upsertClause returns the conflict clause of the dialect of driverName.
+func upsertClause(driverName string, columns, conflict []string) string {
+	var updates []string
+	for _, column := range columns {
+		if slices.Contains(conflict, column) {
+			continue
+		}
+		if isPostgres(driverName) {
+			updates = append(updates, fmt.Sprintf("%s = EXCLUDED.%s", column, column))
+		} else {
+			updates = append(updates, fmt.Sprintf("%s = VALUES(%s)", column, column))
+		}
+	}
+
+	if isPostgres(driverName) {
+		target := strings.Join(conflict, ", ")
+		if len(updates) == 0 {
+			return fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", target)
+		}
+		return fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s", target, strings.Join(updates, ", "))
+	}
+	if len(updates) == 0 {
+		// MySQL has no DO NOTHING, a no-op assignment ignores the duplicate.
+		return fmt.Sprintf(" ON DUPLICATE KEY UPDATE %s = %s", conflict[0], conflict[0])
+	}
+	return " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
@@ -0,0 +1,105 @@
+package platigo
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsert(t *testing.T) {
+	type balance struct {
+		AccountID int    `db:"account_id"`
+		Currency  string `db:"currency"`
+		Amount    int64  `db:"amount"`
+	}
+	type membership struct {
+		UserID  int `db:"user_id"`
+		GroupID int `db:"group_id"`
+	}
+
+	tests := []struct {
+		name     string
+		driver   string
+		run      func(db *sqlx.DB) (int64, error)
+		wantSQL  string
+		wantArgs int
+	}{
+		{
+			name:   "postgres",
+			driver: "pgx",
+			run: func(db *sqlx.DB) (int64, error) {
+				return Upsert(context.Background(), db, "balances", []string{"account_id", "currency"}, []balance{{1, "EUR", 10}, {2, "EUR", 20}})
+			},
+			wantSQL: "INSERT INTO balances (account_id, currency, amount) VALUES ($1, $2, $3), ($4, $5, $6) " +
+				"ON CONFLICT (account_id, currency) DO UPDATE SET amount = EXCLUDED.amount",
+			wantArgs: 6,
+		},
+		{
+			name:   "mysql",
+			driver: DriverMySQL,
+			run: func(db *sqlx.DB) (int64, error) {
+				return Upsert(context.Background(), db, "balances", []string{"account_id", "currency"}, []balance{{1, "EUR", 10}})
+			},
+			wantSQL:  "INSERT INTO balances (account_id, currency, amount) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE amount = VALUES(amount)",
+			wantArgs: 3,
+		},
+		{
+			name:   "postgres nothing to update",
+			driver: "pgx",
+			run: func(db *sqlx.DB) (int64, error) {
+				return Upsert(context.Background(), db, "memberships", []string{"user_id", "group_id"}, []membership{{1, 2}})
+			},
+			wantSQL:  "INSERT INTO memberships (user_id, group_id) VALUES ($1, $2) ON CONFLICT (user_id, group_id) DO NOTHING",
+			wantArgs: 2,
+		},
+		{
+			name:   "mysql nothing to update",
+			driver: DriverMySQL,
+			run: func(db *sqlx.DB) (int64, error) {
+				return Upsert(context.Background(), db, "memberships", []string{"user_id", "group_id"}, []membership{{1, 2}})
+			},
+			wantSQL:  "INSERT INTO memberships (user_id, group_id) VALUES (?, ?) ON DUPLICATE KEY UPDATE user_id = user_id",
+			wantArgs: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer conn.Close()
+
+			args := make([]driver.Value, tt.wantArgs)
+			for i := range args {
+				args[i] = sqlmock.AnyArg()
+			}
+			mock.ExpectExec(regexp.QuoteMeta(tt.wantSQL)).WithArgs(args...).WillReturnResult(sqlmock.NewResult(0, 1))
+
+			_, err = tt.run(sqlx.NewDb(conn, tt.driver))
+			require.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpsertInvalid(t *testing.T) {
+	type row struct {
+		ID int `db:"id"`
+	}
+	conn, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer conn.Close()
+	db := sqlx.NewDb(conn, "pgx")
+
+	_, err = Upsert(context.Background(), db, "rows", nil, []row{{1}})
+	assert.ErrorIs(t, err, errUpsertConflict)
+
+	_, err = Upsert(context.Background(), db, "rows", []string{"missing"}, []row{{1}})
+	assert.ErrorContains(t, err, `conflict column "missing"`)
+}
@@ -0,0 +1,118 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrStaleObject is matched by errors.Is when a write was rejected because
+// the row or document changed since it was read: by UpdateWithVersion, and
+// by the OpenSearch writes conditioned with WithIfSeqNo or a version, so
+// both stores are handled alike.
+var ErrStaleObject = errors.New("stale object: modified since it was read")
+
+var (
+	errVersionedUpdateEmpty = errors.New("sql: versioned update sets no column")
+	errInvalidIdentifier    = errors.New("sql: invalid table or column name")
+
+	sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// StaleObjectError is returned by UpdateWithVersion when the row does not
+// have the version it was read with anymore, or was deleted.
+type StaleObjectError struct {
+	Table   string
+	Key     any
+	Version int64
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("sql: %s %v is not at version %d anymore", e.Table, e.Key, e.Version)
+}
+
+// Is makes the error match ErrStaleObject.
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// VersionedUpdate is an update applied only if the row still has the
+// version it was read with.
+type VersionedUpdate struct {
+	Table string
+	// KeyColumn is the primary key column, "id" by default, and
+	// VersionColumn the integer version column, "version" by default.
+	KeyColumn     string
+	VersionColumn string
+
+	Key     any
+	Version int64
+	// Set maps the updated columns to their value.
+	Set map[string]any
+}
+
+// UpdateWithVersion applies update and increments the version of the row,
+// returning the new version. When the row changed since it was read, it
+// returns a StaleObjectError, matching ErrStaleObject: read the row again
+// and retry, or report the conflict.
+//
+//	version, err := platigo.UpdateWithVersion(ctx, db, platigo.VersionedUpdate{
+//		Table:   "accounts",
+//		Key:     account.ID,
+//		Version: account.Version,
+//		Set:     map[string]any{"balance": account.Balance},
+//	})
+func UpdateWithVersion(ctx context.Context, db sqlx.ExtContext, update VersionedUpdate) (int64, error) {
+	keyColumn := update.KeyColumn
+	if keyColumn == "" {
+		keyColumn = "id"
+	}
+	versionColumn := update.VersionColumn
+	if versionColumn == "" {
+		versionColumn = "version"
+	}
+	if len(update.Set) == 0 {
+		return 0, errVersionedUpdateEmpty
+	}
+
+	columns := make([]string, 0, len(update.Set))
+	for column := range update.Set {
+		columns = append(columns, column)
+	}
+	// Sorted so the statement is the same for every call, and prepared once.
+	sort.Strings(columns)
+	for _, identifier := range append([]string{update.Table, keyColumn, versionColumn}, columns...) {
+		if !sqlIdentifier.MatchString(identifier) {
+			return 0, errInvalidIdentifier
+		}
+	}
+
+	assignments := make([]string, 0, len(columns)+1)
+	args := make([]any, 0, len(columns)+2)
+	for _, column := range columns {
+		assignments = append(assignments, column+" = ?")
+		args = append(args, update.Set[column])
+	}
+	assignments = append(assignments, fmt.Sprintf("%[1]s = %[1]s + 1", versionColumn))
+	args = append(args, update.Key, update.Version)
+
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = ? AND %s = ?",
+		update.Table, strings.Join(assignments, ", "), keyColumn, versionColumn)
+	res, err := db.ExecContext(ctx, db.Rebind(query), args...)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, &StaleObjectError{Table: update.Table, Key: update.Key, Version: update.Version}
+	}
+	return update.Version + 1, nil
+}
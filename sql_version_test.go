@@ -0,0 +1,81 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateWithVersion(t *testing.T) {
+	const wantSQL = "UPDATE accounts SET balance = $1, status = $2, version = version + 1 WHERE id = $3 AND version = $4"
+
+	tests := []struct {
+		name        string
+		update      VersionedUpdate
+		affected    int64
+		noExec      bool
+		wantVersion int64
+		wantErr     error
+	}{
+		{
+			name:        "applied",
+			update:      VersionedUpdate{Table: "accounts", Key: 1, Version: 3, Set: map[string]any{"status": "active", "balance": 100}},
+			affected:    1,
+			wantVersion: 4,
+		},
+		{
+			name:     "stale",
+			update:   VersionedUpdate{Table: "accounts", Key: 1, Version: 3, Set: map[string]any{"status": "active", "balance": 100}},
+			affected: 0,
+			wantErr:  ErrStaleObject,
+		},
+		{
+			name:    "nothing to set",
+			update:  VersionedUpdate{Table: "accounts", Key: 1, Version: 3},
+			noExec:  true,
+			wantErr: errVersionedUpdateEmpty,
+		},
+		{
+			name:    "invalid column",
+			update:  VersionedUpdate{Table: "accounts", Key: 1, Version: 3, Set: map[string]any{"status = 'x' --": 1}},
+			noExec:  true,
+			wantErr: errInvalidIdentifier,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer conn.Close()
+			if !tt.noExec {
+				mock.ExpectExec(regexp.QuoteMeta(wantSQL)).WithArgs(100, "active", 1, 3).
+					WillReturnResult(sqlmock.NewResult(0, tt.affected))
+			}
+
+			version, err := UpdateWithVersion(context.Background(), sqlx.NewDb(conn, "pgx"), tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantVersion, version)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestStaleObjectError(t *testing.T) {
+	err := error(&StaleObjectError{Table: "accounts", Key: 1, Version: 3})
+
+	var stale *StaleObjectError
+	require.True(t, errors.As(err, &stale))
+	assert.Equal(t, int64(3), stale.Version)
+	assert.EqualError(t, err, "sql: accounts 1 is not at version 3 anymore")
+}
@@ -0,0 +1,165 @@
+package platigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http/protobuf"
+)
+
+var (
+	errTracingServiceName = errors.New("tracing: service name is required")
+	errOTLPProtocol       = errors.New("tracing: unknown OTLP protocol")
+)
+
+type TracingConfig struct {
+	// ServiceName, ServiceVersion and Environment are the service.name,
+	// service.version and deployment.environment resource attributes.
+	// ServiceName is required. Attributes are added to the resource along
+	// with the ones of the OTEL_RESOURCE_ATTRIBUTES variable.
+	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Attributes     []attribute.KeyValue
+
+	// Endpoint is the host:port of the OTLP collector, spoken over Protocol,
+	// OTLPProtocolGRPC by default. Without Endpoint the exporter follows the
+	// OTEL_EXPORTER_OTLP_ENDPOINT variable, localhost by default. Insecure
+	// uses plaintext, for collectors running as a sidecar, and Headers are
+	// sent with every export, such as an API key.
+	Endpoint string
+	Protocol string
+	Insecure bool
+	Headers  map[string]string
+
+	// SampleRatio samples that fraction of the new traces, all of them by
+	// default, while the spans continuing a trace follow the decision of
+	// its parent. Sampler replaces that behavior when set.
+	SampleRatio float64
+	Sampler     sdktrace.Sampler
+
+	// Propagator reads and writes the trace context of the requests and
+	// messages, W3C trace context and baggage by default.
+	Propagator propagation.TextMapPropagator
+
+	// Exporter replaces the OTLP exporter, e.g. with a stdout or in-memory
+	// one.
+	Exporter sdktrace.SpanExporter
+}
+
+// InitTracing installs a global tracer provider exporting the spans over
+// OTLP, and the global propagator. Every platigo client without a
+// TracerProvider of its own uses the global one, including those created
+// before InitTracing.
+//
+// The returned function flushes the pending spans and stops the provider.
+// It is meant to be registered as the last shutdown hook:
+//
+//	shutdownTracing, err := platigo.InitTracing(ctx, platigo.TracingConfig{ServiceName: "orders"})
+//	shutdown.Register("tracing", shutdownTracing)
+func InitTracing(ctx context.Context, config TracingConfig) (func(context.Context) error, error) {
+	if config.ServiceName == "" {
+		return nil, errTracingServiceName
+	}
+
+	exporter := config.Exporter
+	if exporter == nil {
+		var err error
+		if exporter, err = newOTLPExporter(ctx, config); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := tracingResource(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sampler := config.Sampler
+	if sampler == nil {
+		if config.SampleRatio > 0 && config.SampleRatio < 1 {
+			sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))
+		} else {
+			sampler = sdktrace.ParentBased(sdktrace.AlwaysSample())
+		}
+	}
+
+	propagator := config.Propagator
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+func newOTLPExporter(ctx context.Context, config TracingConfig) (sdktrace.SpanExporter, error) {
+	switch config.Protocol {
+	case "", OTLPProtocolGRPC:
+		var opts []otlptracegrpc.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracegrpc.WithHeaders(config.Headers))
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case OTLPProtocolHTTP:
+		var opts []otlptracehttp.Option
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		if len(config.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(config.Headers))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("%w %q", errOTLPProtocol, config.Protocol)
+}
+
+// tracingResource describes the service, on top of the attributes detected
+// from the environment, the process and the host.
+func tracingResource(ctx context.Context, config TracingConfig) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceName(config.ServiceName)}
+	if config.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(config.ServiceVersion))
+	}
+	if config.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironment(config.Environment))
+	}
+	attrs = append(attrs, config.Attributes...)
+
+	return resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithProcessRuntimeName(),
+		resource.WithProcessRuntimeVersion(),
+		resource.WithHost(),
+		resource.WithAttributes(attrs...),
+	)
+}
@@ -0,0 +1,102 @@
+package platigo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// spanRecorder keeps the exported spans after Shutdown, which clears
+// those of the in-memory exporter.
+type spanRecorder struct {
+	*tracetest.InMemoryExporter
+}
+
+func (spanRecorder) Shutdown(context.Context) error { return nil }
+
+func TestInitTracing(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	// Tracers created before InitTracing follow the global provider.
+	tracer := newTracer(nil)
+
+	exporter := spanRecorder{tracetest.NewInMemoryExporter()}
+	shutdown, err := InitTracing(context.Background(), TracingConfig{
+		ServiceName:    "orders",
+		ServiceVersion: "1.2.3",
+		Environment:    "production",
+		Attributes:     []attribute.KeyValue{attribute.String("team", "payments")},
+		Exporter:       exporter,
+	})
+	require.NoError(t, err)
+
+	_, span := tracer.Start(context.Background(), "operation")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "operation", spans[0].Name)
+	attrs := map[attribute.Key]string{}
+	for _, attr := range spans[0].Resource.Attributes() {
+		attrs[attr.Key] = attr.Value.Emit()
+	}
+	assert.Equal(t, "orders", attrs["service.name"])
+	assert.Equal(t, "1.2.3", attrs["service.version"])
+	assert.Equal(t, "production", attrs["deployment.environment"])
+	assert.Equal(t, "payments", attrs["team"])
+
+	assert.ElementsMatch(t, []string{"traceparent", "tracestate", "baggage"}, otel.GetTextMapPropagator().Fields())
+}
+
+func TestInitTracingErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config TracingConfig
+		err    error
+	}{
+		{name: "missing service name", config: TracingConfig{}, err: errTracingServiceName},
+		{name: "unknown protocol", config: TracingConfig{ServiceName: "orders", Protocol: "thrift"}, err: errOTLPProtocol},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := InitTracing(context.Background(), tt.config)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestInitTracingSampleRatio(t *testing.T) {
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	exporter := spanRecorder{tracetest.NewInMemoryExporter()}
+	shutdown, err := InitTracing(context.Background(), TracingConfig{
+		ServiceName: "orders",
+		SampleRatio: 0.000001,
+		Propagator:  propagation.TraceContext{},
+		Exporter:    exporter,
+	})
+	require.NoError(t, err)
+
+	for range 100 {
+		_, span := otel.Tracer("test").Start(context.Background(), "operation")
+		span.End()
+	}
+	require.NoError(t, shutdown(context.Background()))
+	assert.Less(t, len(exporter.GetSpans()), 100)
+	assert.Equal(t, []string{"traceparent", "tracestate"}, otel.GetTextMapPropagator().Fields())
+}
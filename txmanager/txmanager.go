@@ -0,0 +1,159 @@
+// Package txmanager runs functions inside database transactions carried by
+// their context, so repositories join the transaction of their caller
+// without taking it as a parameter.
+//
+//	manager := txmanager.New(db.DB)
+//
+//	err := manager.RunInTx(ctx, func(ctx context.Context) error {
+//		if err := orders.Create(ctx, order); err != nil {
+//			return err
+//		}
+//		return payments.Reserve(ctx, order.PaymentID)
+//	})
+//
+// with repositories querying through the manager:
+//
+//	func (r *OrderRepository) Create(ctx context.Context, order *Order) error {
+//		_, err := r.manager.DB(ctx).NamedExecContext(ctx, insertOrder, order)
+//		return err
+//	}
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is the part of *sqlx.DB and *sqlx.Tx used by repositories.
+type DB interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+	SelectContext(ctx context.Context, dest any, query string, args ...any) error
+	NamedExecContext(ctx context.Context, query string, arg any) (sql.Result, error)
+	PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error)
+}
+
+// TxOption customizes the transaction started by RunInTx. Nested calls run
+// in the transaction of their caller and ignore them.
+type TxOption func(*sql.TxOptions)
+
+// WithIsolation sets the isolation level of the transaction.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *sql.TxOptions) {
+		o.Isolation = level
+	}
+}
+
+// ReadOnly starts a read-only transaction.
+func ReadOnly() TxOption {
+	return func(o *sql.TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// Manager runs functions in transactions of a database.
+type Manager struct {
+	db *sqlx.DB
+}
+
+// New creates a Manager of db.
+func New(db *sqlx.DB) *Manager {
+	return &Manager{db: db}
+}
+
+// txKey keys the transaction of a Manager in a context, so the managers of
+// different databases do not mix their transactions.
+type txKey struct {
+	db *sqlx.DB
+}
+
+// txState is the transaction of a context and its savepoint depth.
+type txState struct {
+	tx    *sqlx.Tx
+	depth int
+}
+
+// DB returns the transaction of ctx, or the database outside of RunInTx.
+func (m *Manager) DB(ctx context.Context) DB {
+	if state, ok := ctx.Value(txKey{m.db}).(*txState); ok {
+		return state.tx
+	}
+	return m.db
+}
+
+// InTx reports whether ctx carries a transaction of the manager.
+func (m *Manager) InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{m.db}).(*txState)
+	return ok
+}
+
+// RunInTx runs fn in a transaction, committed when fn returns nil and rolled
+// back when it fails or panics. Called within another RunInTx, fn runs in a
+// savepoint of the outer transaction instead, so only its own changes are
+// rolled back on failure and the outer function decides what to do.
+func (m *Manager) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if state, ok := ctx.Value(txKey{m.db}).(*txState); ok {
+		return runInSavepoint(ctx, state, fn)
+	}
+
+	var txOptions sql.TxOptions
+	for _, opt := range opts {
+		opt(&txOptions)
+	}
+	tx, err := m.db.BeginTxx(ctx, &txOptions)
+	if err != nil {
+		return err
+	}
+
+	done := false
+	defer func() {
+		if !done {
+			// fn panicked.
+			_ = tx.Rollback()
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{m.db}, &txState{tx: tx}))
+	done = true
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("txmanager: rollback: %w", rbErr))
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+func runInSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context) error) error {
+	state.depth++
+	defer func() { state.depth-- }()
+	name := fmt.Sprintf("sp_%d", state.depth)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	done := false
+	defer func() {
+		if !done {
+			// fn panicked.
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+		}
+	}()
+
+	err := fn(ctx)
+	done = true
+	if err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("txmanager: rollback to savepoint: %w", rbErr))
+		}
+		return err
+	}
+
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	return err
+}
@@ -0,0 +1,139 @@
+package txmanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) (*Manager, sqlmock.Sqlmock) {
+	t.Helper()
+
+	conn, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return New(sqlx.NewDb(conn, "sqlmock")), mock
+}
+
+const insertOrder = "INSERT INTO orders (id) VALUES (?)"
+
+func TestRunInTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		fnErr   error
+		expect  func(mock sqlmock.Sqlmock)
+		wantErr error
+	}{
+		{
+			name: "commit",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertOrder).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:  "rollback on error",
+			fnErr: errFailed,
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(insertOrder).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectRollback()
+			},
+			wantErr: errFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, mock := newTestManager(t)
+			tt.expect(mock)
+
+			err := manager.RunInTx(context.Background(), func(ctx context.Context) error {
+				assert.True(t, manager.InTx(ctx))
+				if _, err := manager.DB(ctx).ExecContext(ctx, insertOrder, 1); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRunInTxOutside(t *testing.T) {
+	manager, mock := newTestManager(t)
+	mock.ExpectExec(insertOrder).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	assert.False(t, manager.InTx(ctx))
+	_, err := manager.DB(ctx).ExecContext(ctx, insertOrder, 1)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTxNested(t *testing.T) {
+	errFailed := errors.New("failed")
+	manager, mock := newTestManager(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := manager.RunInTx(context.Background(), func(ctx context.Context) error {
+		return manager.RunInTx(ctx, func(ctx context.Context) error {
+			// The failure of the innermost call is handled by its caller,
+			// which keeps the rest of the transaction.
+			err := manager.RunInTx(ctx, func(ctx context.Context) error {
+				return errFailed
+			})
+			assert.ErrorIs(t, err, errFailed)
+			return nil
+		})
+	}, WithIsolation(sql.LevelSerializable))
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTxPanic(t *testing.T) {
+	manager, mock := newTestManager(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.Panics(t, func() {
+		_ = manager.RunInTx(context.Background(), func(ctx context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunInTxSeparateManagers(t *testing.T) {
+	orders, ordersMock := newTestManager(t)
+	payments, _ := newTestManager(t)
+	ordersMock.ExpectBegin()
+	ordersMock.ExpectCommit()
+
+	err := orders.RunInTx(context.Background(), func(ctx context.Context) error {
+		assert.False(t, payments.InTx(ctx))
+		return nil
+	})
+	require.NoError(t, err)
+}
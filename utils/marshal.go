@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/bagastri07/platigo/logger"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -13,9 +14,11 @@ func ToByte(i any) []byte {
 	return bt
 }
 
-// Dump to json using json marshal
+// Dump to json using json marshal, with the sensitive values masked by
+// logger.Mask: the fields tagged mask:"true" and the keys such as password,
+// token and secret.
 func Dump(i any) string {
-	return string(ToByte(i))
+	return string(ToByte(logger.Mask(i)))
 }
 
 // DumpIncomingContext converts the metadata from the incoming context to a string representation using json marshal.
@@ -0,0 +1,93 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/goccy/go-json"
+)
+
+// Format is the format of the body of the deliveries.
+type Format string
+
+const (
+	// FormatEnvelope delivers the messaging.Envelope as JSON.
+	FormatEnvelope Format = "envelope"
+	// FormatCloudEvents delivers a CloudEvent in structured content mode:
+	// the whole event as JSON.
+	FormatCloudEvents Format = "cloudevents"
+	// FormatCloudEventsBinary delivers a CloudEvent in binary content mode:
+	// the data as the body and the attributes as ce- headers.
+	FormatCloudEventsBinary Format = "cloudevents-binary"
+)
+
+// cloudEventsHeaderPrefix prefixes the attributes of a CloudEvent in binary
+// mode in the HTTP binding.
+const cloudEventsHeaderPrefix = "ce-"
+
+var (
+	errUnknownFormat = errors.New("webhook: unknown delivery format")
+	errNotCloudEvent = errors.New("webhook: request is not a cloudevent")
+)
+
+// encode returns the body and the content headers of the delivery of
+// envelope in format.
+func encode(format Format, envelope *messaging.Envelope, source string) ([]byte, http.Header, error) {
+	header := http.Header{}
+	switch format {
+	case FormatEnvelope:
+		body, err := json.Marshal(envelope)
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", messaging.ContentTypeJSON)
+		return body, header, nil
+
+	case FormatCloudEvents:
+		body, err := json.Marshal(envelope.CloudEvent(source))
+		if err != nil {
+			return nil, nil, err
+		}
+		header.Set("Content-Type", messaging.ContentTypeCloudEvents)
+		return body, header, nil
+
+	case FormatCloudEventsBinary:
+		event := envelope.CloudEvent(source)
+		for key, value := range event.Attributes() {
+			header.Set(cloudEventsHeaderPrefix+key, value)
+		}
+		header.Set("Content-Type", event.DataContentType)
+		return event.Data, header, nil
+
+	default:
+		return nil, nil, errUnknownFormat
+	}
+}
+
+// ParseCloudEvent decodes the CloudEvent of a received delivery, in either
+// content mode, for the receivers written in Go. Verify the signature of
+// the body first.
+func ParseCloudEvent(header http.Header, body []byte) (*messaging.CloudEvent, error) {
+	contentType := header.Get("Content-Type")
+	if messaging.IsStructuredCloudEvent(contentType) {
+		var event messaging.CloudEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			return nil, err
+		}
+		return &event, nil
+	}
+
+	attrs := map[string]string{}
+	for key, values := range header {
+		name, ok := strings.CutPrefix(strings.ToLower(key), cloudEventsHeaderPrefix)
+		if ok && len(values) > 0 {
+			attrs[name] = values[0]
+		}
+	}
+	if len(attrs) == 0 {
+		return nil, errNotCloudEvent
+	}
+	return messaging.CloudEventFromAttributes(attrs, contentType, body)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderCloudEventsFormats(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          Format
+		wantContentType string
+	}{
+		{name: "structured", format: FormatCloudEvents, wantContentType: messaging.ContentTypeCloudEvents},
+		{name: "binary", format: FormatCloudEventsBinary, wantContentType: messaging.ContentTypeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var secret string
+			received := make(chan *messaging.CloudEvent, 1)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.NoError(t, Verify(secret, r.Header, body, time.Minute))
+				assert.Equal(t, tt.wantContentType, r.Header.Get("Content-Type"))
+
+				event, err := ParseCloudEvent(r.Header, body)
+				assert.NoError(t, err)
+				received <- event
+			}))
+			defer srv.Close()
+
+			sender, _ := newTestSender(t, Config{Format: tt.format, Source: "/orders"})
+			ctx := context.Background()
+			endpoint, err := sender.Register(ctx, srv.URL)
+			require.NoError(t, err)
+			secret = endpoint.Secret
+
+			envelope := newTestEnvelope(t, "order.created")
+			require.NoError(t, sender.Send(ctx, envelope))
+
+			event := <-received
+			require.NotNil(t, event)
+			assert.Equal(t, envelope.ID, event.ID)
+			assert.Equal(t, "/orders", event.Source)
+			assert.Equal(t, "order.created", event.Type)
+			assert.JSONEq(t, `{"orderID":"o-1"}`, string(event.Data))
+		})
+	}
+}
+
+func TestSenderEndpointFormat(t *testing.T) {
+	contentType := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType <- r.Header.Get("Content-Type")
+	}))
+	defer srv.Close()
+
+	sender, store := newTestSender(t, Config{})
+	ctx := context.Background()
+	endpoint, err := sender.Register(ctx, srv.URL)
+	require.NoError(t, err)
+	endpoint.Format = FormatCloudEvents
+	require.NoError(t, store.SaveEndpoint(ctx, endpoint))
+
+	require.NoError(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+	assert.Equal(t, messaging.ContentTypeCloudEvents, <-contentType)
+}
+
+func TestParseCloudEventNotCloudEvent(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+
+	_, err := ParseCloudEvent(header, []byte(`{}`))
+	assert.ErrorIs(t, err, errNotCloudEvent)
+}
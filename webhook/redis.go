@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultPrefix  = "webhook:"
+	defaultHistory = 100
+)
+
+// RedisConfig configures a RedisStore.
+type RedisConfig struct {
+	// Prefix is the prefix of the Redis keys, "webhook:" by default.
+	Prefix string
+	// History is the number of attempts kept per endpoint, 100 by default.
+	History int
+}
+
+// RedisStore stores the endpoints in Redis hashes and their attempts in
+// capped lists.
+type RedisStore struct {
+	client  redis.UniversalClient
+	prefix  string
+	history int
+}
+
+// NewRedisStore creates a RedisStore.
+func NewRedisStore(client redis.UniversalClient, config RedisConfig) *RedisStore {
+	if config.Prefix == "" {
+		config.Prefix = defaultPrefix
+	}
+	if config.History <= 0 {
+		config.History = defaultHistory
+	}
+	return &RedisStore{client: client, prefix: config.Prefix, history: config.History}
+}
+
+func (s *RedisStore) endpointsKey() string { return s.prefix + "endpoints" }
+func (s *RedisStore) failuresKey() string  { return s.prefix + "failures" }
+func (s *RedisStore) disabledKey() string  { return s.prefix + "disabled" }
+func (s *RedisStore) attemptsKey(id string) string {
+	return s.prefix + "attempts:" + id
+}
+
+func (s *RedisStore) SaveEndpoint(ctx context.Context, endpoint *Endpoint) error {
+	data, err := json.Marshal(endpoint)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, s.endpointsKey(), endpoint.ID, data).Err()
+}
+
+func (s *RedisStore) Endpoint(ctx context.Context, id string) (*Endpoint, error) {
+	pipe := s.client.Pipeline()
+	data := pipe.HGet(ctx, s.endpointsKey(), id)
+	failures := pipe.HGet(ctx, s.failuresKey(), id)
+	disabled := pipe.HGet(ctx, s.disabledKey(), id)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	if errors.Is(data.Err(), redis.Nil) {
+		return nil, ErrEndpointNotFound
+	}
+	return decodeEndpoint(data.Val(), failures.Val(), disabled.Val())
+}
+
+func (s *RedisStore) Endpoints(ctx context.Context) ([]*Endpoint, error) {
+	pipe := s.client.Pipeline()
+	data := pipe.HGetAll(ctx, s.endpointsKey())
+	failures := pipe.HGetAll(ctx, s.failuresKey())
+	disabled := pipe.HGetAll(ctx, s.disabledKey())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	endpoints := make([]*Endpoint, 0, len(data.Val()))
+	for id, value := range data.Val() {
+		endpoint, err := decodeEndpoint(value, failures.Val()[id], disabled.Val()[id])
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+func decodeEndpoint(data, failures, disabled string) (*Endpoint, error) {
+	var endpoint Endpoint
+	if err := json.Unmarshal([]byte(data), &endpoint); err != nil {
+		return nil, err
+	}
+	endpoint.Failures, _ = strconv.Atoi(failures)
+	if disabled != "" {
+		ms, err := strconv.ParseInt(disabled, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		at := time.UnixMilli(ms).UTC()
+		endpoint.DisabledAt = &at
+	}
+	return &endpoint, nil
+}
+
+func (s *RedisStore) DeleteEndpoint(ctx context.Context, id string) error {
+	pipe := s.client.TxPipeline()
+	pipe.HDel(ctx, s.endpointsKey(), id)
+	pipe.HDel(ctx, s.failuresKey(), id)
+	pipe.HDel(ctx, s.disabledKey(), id)
+	pipe.Del(ctx, s.attemptsKey(id))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStore) AddAttempt(ctx context.Context, attempt Attempt) error {
+	data, err := json.Marshal(attempt)
+	if err != nil {
+		return err
+	}
+	key := s.attemptsKey(attempt.EndpointID)
+	pipe := s.client.TxPipeline()
+	pipe.LPush(ctx, key, data)
+	pipe.LTrim(ctx, key, 0, int64(s.history-1))
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *RedisStore) Attempts(ctx context.Context, endpointID string, limit int) ([]Attempt, error) {
+	values, err := s.client.LRange(ctx, s.attemptsKey(endpointID), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	attempts := make([]Attempt, len(values))
+	for i, value := range values {
+		if err := json.Unmarshal([]byte(value), &attempts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return attempts, nil
+}
+
+func (s *RedisStore) RecordFailure(ctx context.Context, endpointID string) (int, error) {
+	n, err := s.client.HIncrBy(ctx, s.failuresKey(), endpointID, 1).Result()
+	return int(n), err
+}
+
+func (s *RedisStore) RecordSuccess(ctx context.Context, endpointID string) error {
+	return s.client.HDel(ctx, s.failuresKey(), endpointID).Err()
+}
+
+func (s *RedisStore) SetDisabled(ctx context.Context, endpointID string, at *time.Time) error {
+	if at == nil {
+		pipe := s.client.TxPipeline()
+		pipe.HDel(ctx, s.disabledKey(), endpointID)
+		pipe.HDel(ctx, s.failuresKey(), endpointID)
+		_, err := pipe.Exec(ctx)
+		return err
+	}
+	return s.client.HSet(ctx, s.disabledKey(), endpointID, at.UnixMilli()).Err()
+}
@@ -0,0 +1,316 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	platigo "github.com/bagastri07/platigo"
+	"github.com/bagastri07/platigo/crypto"
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultMaxAttempts   = 5
+	defaultMaxRetryAfter = time.Minute
+	defaultDisableAfter  = 10
+	defaultTimeout       = 10 * time.Second
+	defaultUserAgent     = "platigo-webhook"
+	secretLength         = 32
+)
+
+var errEndpointDisabled = errors.New("webhook: endpoint is disabled")
+
+// Config configures a Sender.
+type Config struct {
+	// HTTPClient sends the deliveries. Defaults to a client with a 10 second
+	// timeout.
+	HTTPClient *http.Client
+
+	// MaxAttempts is the number of requests of a delivery, 5 by default.
+	// Backoff returns the pause before the given attempt, exponential from
+	// one second up to a minute with jitter by default. A Retry-After
+	// response header lengthens the pause, up to MaxRetryAfter, a minute by
+	// default, so that an endpoint cannot stall the deliveries.
+	MaxAttempts   int
+	Backoff       func(attempt int) time.Duration
+	MaxRetryAfter time.Duration
+
+	// DisableAfter is the number of consecutive failed deliveries after
+	// which an endpoint is disabled, 10 by default.
+	DisableAfter int
+
+	// UserAgent is the User-Agent of the requests, "platigo-webhook" by
+	// default.
+	UserAgent string
+
+	// Format is the format of the deliveries, FormatEnvelope by default.
+	// Source is the source attribute of the CloudEvents formats, the
+	// UserAgent by default.
+	Format Format
+	Source string
+
+	// Logger receives the sender logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// Sender registers endpoints and delivers events to them.
+type Sender struct {
+	store  Store
+	client *http.Client
+	config Config
+	log    logger.Logger
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewSender creates a Sender keeping the endpoints in store.
+func NewSender(store Store, config Config) *Sender {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaultMaxAttempts
+	}
+	if config.Backoff == nil {
+		config.Backoff = platigo.ExponentialBackoff(time.Second, time.Minute)
+	}
+	if config.MaxRetryAfter <= 0 {
+		config.MaxRetryAfter = defaultMaxRetryAfter
+	}
+	if config.DisableAfter <= 0 {
+		config.DisableAfter = defaultDisableAfter
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = defaultUserAgent
+	}
+	if config.Format == "" {
+		config.Format = FormatEnvelope
+	}
+	if config.Source == "" {
+		config.Source = config.UserAgent
+	}
+	return &Sender{
+		store:  store,
+		client: config.HTTPClient,
+		config: config,
+		log:    logger.WithLevel(config.Logger, logger.InfoLevel),
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// Register registers an endpoint receiving the events of eventTypes, or all
+// of them when none are given, with a new random secret.
+func (s *Sender) Register(ctx context.Context, endpointURL string, eventTypes ...string) (*Endpoint, error) {
+	u, err := url.Parse(endpointURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errInvalidURL
+	}
+	secret, err := crypto.GenerateToken(secretLength)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &Endpoint{
+		ID:         uuid.NewString(),
+		URL:        endpointURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		CreatedAt:  s.now().UTC(),
+	}
+	if err := s.store.SaveEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// Enable enables a disabled endpoint again and resets its failures.
+func (s *Sender) Enable(ctx context.Context, endpointID string) error {
+	if _, err := s.store.Endpoint(ctx, endpointID); err != nil {
+		return err
+	}
+	return s.store.SetDisabled(ctx, endpointID, nil)
+}
+
+// Send delivers envelope to every enabled endpoint subscribed to its type,
+// concurrently, and returns once every delivery succeeded or gave up. The
+// returned error joins the failed deliveries.
+func (s *Sender) Send(ctx context.Context, envelope *messaging.Envelope) error {
+	endpoints, err := s.store.Endpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for _, endpoint := range endpoints {
+		if endpoint.Disabled() || !endpoint.subscribed(envelope.Type) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Deliver(ctx, endpoint, envelope); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Deliver delivers envelope to endpoint, retrying up to MaxAttempts times.
+// Every attempt is recorded, and the endpoint is disabled once its
+// consecutive failed deliveries reach DisableAfter.
+func (s *Sender) Deliver(ctx context.Context, endpoint *Endpoint, envelope *messaging.Envelope) error {
+	if endpoint.Disabled() {
+		return errEndpointDisabled
+	}
+	format := endpoint.Format
+	if format == "" {
+		format = s.config.Format
+	}
+	body, header, err := encode(format, envelope, s.config.Source)
+	if err != nil {
+		return err
+	}
+	log := logger.WithContext(ctx, s.log).With(logger.Fields{"endpoint": endpoint.ID, "eventID": envelope.ID})
+
+	var attempt Attempt
+	for number := 1; number <= s.config.MaxAttempts; number++ {
+		var retryAfter time.Duration
+		attempt, retryAfter = s.attempt(ctx, endpoint, envelope, body, header, number)
+		if err := s.store.AddAttempt(ctx, attempt); err != nil {
+			log.Error(err.Error())
+		}
+		if attempt.Succeeded() {
+			if err := s.store.RecordSuccess(ctx, endpoint.ID); err != nil {
+				log.Error(err.Error())
+			}
+			return nil
+		}
+		if !retryable(attempt) || number == s.config.MaxAttempts {
+			break
+		}
+
+		log.With(logger.Fields{"attempt": number}).Warn(attemptError(attempt).Error())
+		if err := s.sleep(ctx, max(s.config.Backoff(number), min(retryAfter, s.config.MaxRetryAfter))); err != nil {
+			break
+		}
+	}
+
+	err = fmt.Errorf("webhook: deliver %s to %s: %w", envelope.ID, endpoint.ID, attemptError(attempt))
+	log.Error(err.Error())
+	// A delivery cut short by a shutdown says nothing of the endpoint.
+	if ctx.Err() == nil {
+		s.recordFailure(ctx, endpoint, log)
+	}
+	return err
+}
+
+// attempt sends one request, and returns its outcome with the delay asked
+// by a Retry-After header.
+func (s *Sender) attempt(ctx context.Context, endpoint *Endpoint, envelope *messaging.Envelope, body []byte, header http.Header, number int) (Attempt, time.Duration) {
+	started := s.now()
+	attempt := Attempt{
+		EndpointID: endpoint.ID,
+		EventID:    envelope.ID,
+		EventType:  envelope.Type,
+		Number:     number,
+		At:         started.UTC(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, 0
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", s.config.UserAgent)
+	req.Header.Set(HeaderID, envelope.ID)
+	req.Header.Set(HeaderEventType, envelope.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(started.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, started, body))
+
+	res, err := s.client.Do(req)
+	attempt.Duration = s.now().Sub(started).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt, 0
+	}
+	defer res.Body.Close()
+	// Draining a bit of the body lets the connection be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4096))
+
+	attempt.StatusCode = res.StatusCode
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return attempt, retryAfter
+}
+
+func (s *Sender) recordFailure(ctx context.Context, endpoint *Endpoint, log logger.Logger) {
+	failures, err := s.store.RecordFailure(ctx, endpoint.ID)
+	if err != nil {
+		log.Error(err.Error())
+		return
+	}
+	if failures < s.config.DisableAfter {
+		return
+	}
+
+	at := s.now().UTC()
+	if err := s.store.SetDisabled(ctx, endpoint.ID, &at); err != nil {
+		log.Error(err.Error())
+		return
+	}
+	log.With(logger.Fields{"failures": failures}).Warn("Webhook endpoint disabled")
+}
+
+// retryable reports whether a failed attempt may succeed later: network
+// errors, timeouts, throttling and server errors. Other client errors will
+// fail again.
+func retryable(attempt Attempt) bool {
+	switch {
+	case attempt.Error != "":
+		return true
+	case attempt.StatusCode == http.StatusRequestTimeout, attempt.StatusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return attempt.StatusCode >= 500
+	}
+}
+
+func attemptError(attempt Attempt) error {
+	if attempt.Error != "" {
+		return errors.New(attempt.Error)
+	}
+	return fmt.Errorf("status %d", attempt.StatusCode)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
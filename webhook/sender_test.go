@@ -0,0 +1,265 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bagastri07/platigo/messaging"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSender(t *testing.T, config Config) (*Sender, *RedisStore) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	store := NewRedisStore(client, RedisConfig{})
+	if config.Backoff == nil {
+		config.Backoff = func(int) time.Duration { return 0 }
+	}
+	sender := NewSender(store, config)
+	sender.sleep = func(context.Context, time.Duration) error { return nil }
+	return sender, store
+}
+
+func newTestEnvelope(t *testing.T, eventType string) *messaging.Envelope {
+	t.Helper()
+
+	envelope, err := messaging.NewEnvelope(context.Background(), eventType, map[string]string{"orderID": "o-1"})
+	require.NoError(t, err)
+	return envelope
+}
+
+func TestSenderRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "https", url: "https://example.com/hooks"},
+		{name: "http", url: "http://localhost:8080/hooks"},
+		{name: "other scheme", url: "ftp://example.com/hooks", wantErr: errInvalidURL},
+		{name: "no host", url: "https:///hooks", wantErr: errInvalidURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, store := newTestSender(t, Config{})
+
+			endpoint, err := sender.Register(context.Background(), tt.url, "order.created")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, endpoint.ID)
+			assert.NotEmpty(t, endpoint.Secret)
+
+			got, err := store.Endpoint(context.Background(), endpoint.ID)
+			require.NoError(t, err)
+			assert.Equal(t, endpoint.URL, got.URL)
+			assert.Equal(t, endpoint.Secret, got.Secret)
+			assert.Equal(t, []string{"order.created"}, got.EventTypes)
+		})
+	}
+}
+
+func TestSenderSendSignsDelivery(t *testing.T) {
+	var secret string
+	var verified atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified.Store(Verify(secret, r.Header, body, time.Minute) == nil)
+		assert.Equal(t, "order.created", r.Header.Get(HeaderEventType))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sender, store := newTestSender(t, Config{})
+	ctx := context.Background()
+	endpoint, err := sender.Register(ctx, srv.URL)
+	require.NoError(t, err)
+	secret = endpoint.Secret
+
+	envelope := newTestEnvelope(t, "order.created")
+	require.NoError(t, sender.Send(ctx, envelope))
+	assert.True(t, verified.Load())
+
+	attempts, err := store.Attempts(ctx, endpoint.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, envelope.ID, attempts[0].EventID)
+	assert.Equal(t, http.StatusNoContent, attempts[0].StatusCode)
+	assert.True(t, attempts[0].Succeeded())
+}
+
+func TestSenderSendSkipsUnsubscribed(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	sender, _ := newTestSender(t, Config{})
+	ctx := context.Background()
+	_, err := sender.Register(ctx, srv.URL, "order.paid")
+	require.NoError(t, err)
+
+	require.NoError(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+	assert.Zero(t, calls.Load())
+}
+
+func TestSenderDeliverRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "server error then success", statuses: []int{500, 503, 200}, wantAttempts: 3},
+		{name: "throttled then success", statuses: []int{429, 200}, wantAttempts: 2},
+		{name: "client error is not retried", statuses: []int{400}, wantErr: true, wantAttempts: 1},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500}, wantErr: true, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(calls.Add(1)) - 1
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses)-1)])
+			}))
+			defer srv.Close()
+
+			sender, store := newTestSender(t, Config{MaxAttempts: 3})
+			ctx := context.Background()
+			endpoint, err := sender.Register(ctx, srv.URL)
+			require.NoError(t, err)
+
+			err = sender.Deliver(ctx, endpoint, newTestEnvelope(t, "order.created"))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, int(calls.Load()))
+
+			attempts, err := store.Attempts(ctx, endpoint.ID, 10)
+			require.NoError(t, err)
+			require.Len(t, attempts, tt.wantAttempts)
+			assert.Equal(t, tt.wantAttempts, attempts[0].Number)
+		})
+	}
+}
+
+func TestSenderCapsRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sender, _ := newTestSender(t, Config{MaxAttempts: 2, MaxRetryAfter: 5 * time.Second})
+	var slept []time.Duration
+	sender.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	ctx := context.Background()
+	endpoint, err := sender.Register(ctx, srv.URL)
+	require.NoError(t, err)
+
+	assert.Error(t, sender.Deliver(ctx, endpoint, newTestEnvelope(t, "order.created")))
+	assert.Equal(t, []time.Duration{5 * time.Second}, slept)
+}
+
+func TestSenderCanceledDeliveryIsNotAFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sender, store := newTestSender(t, Config{MaxAttempts: 3})
+	endpoint, err := sender.Register(context.Background(), srv.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sender.sleep = func(ctx context.Context, _ time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	assert.Error(t, sender.Deliver(ctx, endpoint, newTestEnvelope(t, "order.created")))
+
+	got, err := store.Endpoint(context.Background(), endpoint.ID)
+	require.NoError(t, err)
+	assert.Zero(t, got.Failures)
+}
+
+func TestSenderDisablesFailingEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	sender, store := newTestSender(t, Config{MaxAttempts: 1, DisableAfter: 2})
+	ctx := context.Background()
+	endpoint, err := sender.Register(ctx, srv.URL)
+	require.NoError(t, err)
+
+	for range 2 {
+		assert.Error(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+	}
+
+	got, err := store.Endpoint(ctx, endpoint.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Disabled())
+	assert.Equal(t, 2, got.Failures)
+
+	// Disabled endpoints no longer receive events.
+	require.NoError(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+	attempts, err := store.Attempts(ctx, endpoint.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, attempts, 2)
+
+	require.NoError(t, sender.Enable(ctx, endpoint.ID))
+	got, err = store.Endpoint(ctx, endpoint.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Disabled())
+	assert.Zero(t, got.Failures)
+}
+
+func TestSenderSuccessResetsFailures(t *testing.T) {
+	var fail atomic.Bool
+	fail.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	sender, store := newTestSender(t, Config{MaxAttempts: 1, DisableAfter: 2})
+	ctx := context.Background()
+	endpoint, err := sender.Register(ctx, srv.URL)
+	require.NoError(t, err)
+
+	assert.Error(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+	fail.Store(false)
+	require.NoError(t, sender.Send(ctx, newTestEnvelope(t, "order.created")))
+
+	got, err := store.Endpoint(ctx, endpoint.ID)
+	require.NoError(t, err)
+	assert.Zero(t, got.Failures)
+	assert.False(t, got.Disabled())
+}
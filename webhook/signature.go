@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bagastri07/platigo/crypto"
+)
+
+// Headers of a delivery.
+const (
+	HeaderID        = "Webhook-Id"
+	HeaderEventType = "Webhook-Event-Type"
+	HeaderTimestamp = "Webhook-Timestamp"
+	// HeaderSignature holds "sha256=" followed by the hex HMAC-SHA256 of the
+	// timestamp, a dot and the body, keyed with the endpoint secret.
+	HeaderSignature = "Webhook-Signature"
+)
+
+const signaturePrefix = "sha256="
+
+var (
+	// ErrInvalidSignature is returned by Verify for deliveries not signed
+	// with the secret.
+	ErrInvalidSignature = errors.New("webhook: invalid signature")
+	// ErrExpiredTimestamp is returned by Verify for deliveries older than
+	// the tolerance, possibly replayed.
+	ErrExpiredTimestamp = errors.New("webhook: timestamp outside tolerance")
+)
+
+// Sign returns the HeaderSignature value of body sent at timestamp.
+// Covering the timestamp keeps a captured delivery from being replayed
+// later with a fresh one.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	return signaturePrefix + crypto.SignPayload([]byte(secret), signedPayload(timestamp.Unix(), body))
+}
+
+// Verify checks the signature of a received delivery, for the receivers
+// written in Go. Deliveries sent more than tolerance away from now fail
+// with ErrExpiredTimestamp.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	unix, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrExpiredTimestamp
+	}
+
+	signature, ok := strings.CutPrefix(header.Get(HeaderSignature), signaturePrefix)
+	if !ok || !crypto.VerifyPayload([]byte(secret), signedPayload(unix, body), signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signedPayload(unix int64, body []byte) []byte {
+	payload := strconv.AppendInt(nil, unix, 10)
+	payload = append(payload, '.')
+	return append(payload, body...)
+}
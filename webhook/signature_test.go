@@ -0,0 +1,49 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"id":"evt-1"}`)
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp time.Time
+		body      []byte
+		signature string
+		wantErr   error
+	}{
+		{name: "valid", secret: "s3cret", timestamp: now, body: body},
+		{name: "wrong secret", secret: "other", timestamp: now, body: body, wantErr: ErrInvalidSignature},
+		{name: "tampered body", secret: "s3cret", timestamp: now, body: []byte(`{"id":"evt-2"}`), wantErr: ErrInvalidSignature},
+		{name: "expired", secret: "s3cret", timestamp: now.Add(-10 * time.Minute), body: body, wantErr: ErrExpiredTimestamp},
+		{name: "missing prefix", secret: "s3cret", timestamp: now, body: body, signature: "deadbeef", wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := tt.signature
+			if signature == "" {
+				signature = Sign("s3cret", tt.timestamp, body)
+			}
+			header := http.Header{}
+			header.Set(HeaderTimestamp, strconv.FormatInt(tt.timestamp.Unix(), 10))
+			header.Set(HeaderSignature, signature)
+
+			err := Verify(tt.secret, header, tt.body, 5*time.Minute)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Package webhook delivers events to the HTTP endpoints registered by
+// customers. Deliveries are signed with the secret of the endpoint, retried
+// with exponential backoff, recorded in an attempt history, and endpoints
+// failing repeatedly are disabled.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrEndpointNotFound is returned for an unknown endpoint ID.
+	ErrEndpointNotFound = errors.New("webhook: endpoint not found")
+
+	errInvalidURL = errors.New("webhook: endpoint URL must be http or https")
+)
+
+// Endpoint is a registered receiver of events.
+type Endpoint struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries to the endpoint, and is shared with its
+	// owner to verify them.
+	Secret string `json:"secret"`
+	// EventTypes are the types delivered to the endpoint, all of them when
+	// empty.
+	EventTypes []string `json:"event_types,omitempty"`
+	// Format overrides the format of the deliveries set by the Config of
+	// the Sender.
+	Format    Format    `json:"format,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Failures is the number of consecutive failed deliveries, and
+	// DisabledAt is set once the endpoint got disabled.
+	Failures   int        `json:"-"`
+	DisabledAt *time.Time `json:"-"`
+}
+
+// Disabled reports whether deliveries to the endpoint are suspended.
+func (e *Endpoint) Disabled() bool {
+	return e.DisabledAt != nil
+}
+
+// subscribed reports whether the endpoint receives the events of eventType.
+func (e *Endpoint) subscribed(eventType string) bool {
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Attempt is one HTTP request of a delivery.
+type Attempt struct {
+	EndpointID string    `json:"endpoint_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Number     int       `json:"number"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Duration   int64     `json:"duration_ms"`
+	At         time.Time `json:"at"`
+}
+
+// Succeeded reports whether the endpoint accepted the event.
+func (a *Attempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// Store persists the endpoints and their delivery history.
+type Store interface {
+	SaveEndpoint(ctx context.Context, endpoint *Endpoint) error
+	// Endpoint returns an endpoint, or ErrEndpointNotFound.
+	Endpoint(ctx context.Context, id string) (*Endpoint, error)
+	Endpoints(ctx context.Context) ([]*Endpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+
+	// AddAttempt appends an attempt to the history of its endpoint, and
+	// Attempts returns the last ones, most recent first.
+	AddAttempt(ctx context.Context, attempt Attempt) error
+	Attempts(ctx context.Context, endpointID string, limit int) ([]Attempt, error)
+
+	// RecordFailure increments the consecutive failures of an endpoint and
+	// returns them, and RecordSuccess resets them.
+	RecordFailure(ctx context.Context, endpointID string) (int, error)
+	RecordSuccess(ctx context.Context, endpointID string) error
+	// SetDisabled disables an endpoint at the given time, or enables it
+	// again with a nil time.
+	SetDisabled(ctx context.Context, endpointID string, at *time.Time) error
+}
@@ -0,0 +1,152 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	ws "github.com/gorilla/websocket"
+)
+
+// Conn is a connection registered in a Hub.
+type Conn struct {
+	// ID identifies the connection and Key groups the connections of the
+	// same client, such as a user.
+	ID  string
+	Key string
+
+	hub    *Hub
+	ws     *ws.Conn
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	send      chan []byte
+	done      chan struct{}
+	closing   bool
+	closeCode int
+	closeOnce sync.Once
+}
+
+// Context returns a context canceled once the connection is closed,
+// carrying the values of the upgraded request.
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Send queues data for the connection. A connection whose queue is full is
+// closed.
+func (c *Conn) Send(data []byte) error {
+	c.mu.Lock()
+	if c.closing {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	select {
+	case c.send <- data:
+		c.mu.Unlock()
+		return nil
+	default:
+		c.mu.Unlock()
+	}
+
+	c.hub.log.With(logger.Fields{"connID": c.ID, "key": c.Key}).Warn("Closing slow WebSocket connection")
+	c.close(ws.ClosePolicyViolation)
+	return errSendQueueFull
+}
+
+// Close closes the connection once the queued messages are sent.
+func (c *Conn) Close() {
+	c.close(ws.CloseNormalClosure)
+}
+
+func (c *Conn) close(code int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.closing {
+		c.closing = true
+		c.closeCode = code
+		close(c.send)
+	}
+}
+
+// terminate releases the connection, whichever loop ends first. The later
+// sends fail with ErrClosed rather than queue messages nobody writes.
+func (c *Conn) terminate() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closing = true
+		c.mu.Unlock()
+		close(c.done)
+		c.cancel()
+		_ = c.ws.Close()
+		c.hub.unregister(c)
+	})
+}
+
+// readLoop handles the messages and pongs of the client until the
+// connection fails or is closed.
+func (c *Conn) readLoop() {
+	defer c.hub.wg.Done()
+	defer c.terminate()
+
+	timeout := 2 * c.hub.config.PingInterval
+	c.ws.SetReadLimit(c.hub.config.MaxMessageSize)
+	_ = c.ws.SetReadDeadline(c.hub.now().Add(timeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(c.hub.now().Add(timeout))
+	})
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			if !ws.IsCloseError(err, ws.CloseNormalClosure, ws.CloseGoingAway, ws.CloseNoStatusReceived) && !errors.Is(err, ws.ErrCloseSent) {
+				select {
+				case <-c.done:
+				default:
+					c.hub.log.With(logger.Fields{"connID": c.ID, "error": err.Error()}).Debug("WebSocket read failed")
+				}
+			}
+			return
+		}
+		if c.hub.config.OnMessage != nil {
+			c.hub.config.OnMessage(c, data)
+		}
+	}
+}
+
+// writeLoop writes the queued messages and the pings, then the close frame
+// once the connection is closed.
+func (c *Conn) writeLoop() {
+	defer c.hub.wg.Done()
+	defer c.terminate()
+
+	ticker := time.NewTicker(c.hub.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case data, ok := <-c.send:
+			deadline := c.hub.now().Add(c.hub.config.WriteTimeout)
+			if !ok {
+				c.mu.Lock()
+				code := c.closeCode
+				c.mu.Unlock()
+				_ = c.ws.WriteControl(ws.CloseMessage, ws.FormatCloseMessage(code, ""), deadline)
+				return
+			}
+			_ = c.ws.SetWriteDeadline(deadline)
+			if err := c.ws.WriteMessage(ws.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := c.ws.WriteControl(ws.PingMessage, nil, c.hub.now().Add(c.hub.config.WriteTimeout)); err != nil {
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
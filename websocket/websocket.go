@@ -0,0 +1,280 @@
+// Package websocket manages the WebSocket connections of a service: a Hub
+// registers the connections it upgrades, queues the messages sent to each
+// of them, keeps them alive with pings and closes them gracefully. With
+// Redis, broadcasts fan out to the connections of every instance:
+//
+//	hub := websocket.NewHub(websocket.Config{Redis: redisClient, Logger: log})
+//	go hub.Run(ctx)
+//	shutdown.Register("websocket hub", hub.Shutdown)
+//	mux.Handle("/ws", hub.Handler(func(r *http.Request) string { return userID(r) }))
+//
+//	err := hub.SendTo(ctx, userID, []byte(`{"type":"order.shipped"}`))
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bagastri07/platigo/logger"
+	"github.com/bagastri07/platigo/pubsub"
+	"github.com/google/uuid"
+	ws "github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultSendQueueSize  = 64
+	defaultPingInterval   = 30 * time.Second
+	defaultWriteTimeout   = 10 * time.Second
+	defaultMaxMessageSize = 64 << 10
+	defaultChannel        = "websocket:messages"
+)
+
+var (
+	// ErrClosed is returned when sending to a closed connection or hub.
+	ErrClosed = errors.New("websocket: connection closed")
+
+	errSendQueueFull = errors.New("websocket: send queue full")
+	errNoRedis       = errors.New("websocket: Run needs Redis")
+)
+
+type Config struct {
+	// SendQueueSize is the number of messages queued per connection, 64 by
+	// default. The clients too slow to keep up are disconnected instead of
+	// slowing the others down.
+	SendQueueSize int
+	// PingInterval is the interval of the pings, 30 seconds by default.
+	// Connections not answering within twice the interval are closed.
+	PingInterval time.Duration
+	// WriteTimeout bounds every write, 10 seconds by default.
+	WriteTimeout time.Duration
+	// MaxMessageSize bounds the messages received, 64KiB by default.
+	MaxMessageSize int64
+
+	// Upgrader upgrades the connections. Its CheckOrigin defaults to
+	// rejecting cross origin requests.
+	Upgrader ws.Upgrader
+
+	// OnMessage handles the messages received from the clients. They are
+	// dropped by default.
+	OnMessage func(conn *Conn, data []byte)
+
+	// Redis fans the messages out to the hubs of every instance through
+	// the pub/sub Channel, "websocket:messages" by default. Run must be
+	// running to receive them.
+	Redis   redis.UniversalClient
+	Channel string
+
+	// Logger receives the connection logs. Defaults to a no-op logger.
+	Logger logger.Logger
+}
+
+// message is a message fanned out through Redis, to every connection or to
+// the connections of Key.
+type message struct {
+	Key  string `json:"key,omitempty"`
+	Data []byte `json:"data"`
+}
+
+// Hub tracks the connections of an instance.
+type Hub struct {
+	config    Config
+	log       logger.Logger
+	publisher *pubsub.Publisher
+	now       func() time.Time
+
+	mu     sync.RWMutex
+	conns  map[*Conn]struct{}
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewHub creates a Hub.
+func NewHub(config Config) *Hub {
+	if config.SendQueueSize <= 0 {
+		config.SendQueueSize = defaultSendQueueSize
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = defaultPingInterval
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaultWriteTimeout
+	}
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = defaultMaxMessageSize
+	}
+	if config.Channel == "" {
+		config.Channel = defaultChannel
+	}
+
+	h := &Hub{
+		config: config,
+		log:    config.Logger,
+		now:    time.Now,
+		conns:  map[*Conn]struct{}{},
+	}
+	if h.log == nil {
+		h.log = logger.Nop()
+	}
+	if config.Redis != nil {
+		h.publisher = pubsub.NewPublisher(config.Redis)
+	}
+	return h
+}
+
+// Handler upgrades the requests and registers their connections under the
+// key returned by key, such as the ID of the user, or no key when nil.
+func (h *Hub) Handler(key func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connKey := ""
+		if key != nil {
+			connKey = key(r)
+		}
+		// The upgrader already answered the failed upgrades.
+		_, _ = h.Upgrade(w, r, connKey)
+	})
+}
+
+// Upgrade upgrades the request to a WebSocket connection registered under
+// key. Once the hub is shut down, requests are answered with a 503.
+func (h *Hub) Upgrade(w http.ResponseWriter, r *http.Request, key string) (*Conn, error) {
+	h.mu.RLock()
+	closed := h.closed
+	h.mu.RUnlock()
+	if closed {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return nil, ErrClosed
+	}
+
+	wsConn, err := h.config.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	c := &Conn{
+		ID:     uuid.NewString(),
+		Key:    key,
+		hub:    h,
+		ws:     wsConn,
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan []byte, h.config.SendQueueSize),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		_ = wsConn.WriteControl(ws.CloseMessage, ws.FormatCloseMessage(ws.CloseGoingAway, "shutting down"), h.now().Add(h.config.WriteTimeout))
+		_ = wsConn.Close()
+		cancel()
+		return nil, ErrClosed
+	}
+	h.conns[c] = struct{}{}
+	h.wg.Add(2)
+	h.mu.Unlock()
+
+	h.log.With(logger.Fields{"connID": c.ID, "key": key}).Debug("WebSocket connection opened")
+	go c.readLoop()
+	go c.writeLoop()
+	return c, nil
+}
+
+// Len returns the number of connections of the instance.
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// Broadcast sends data to every connection, of every instance with Redis.
+func (h *Hub) Broadcast(ctx context.Context, data []byte) error {
+	return h.dispatch(ctx, message{Data: data})
+}
+
+// SendTo sends data to the connections registered under key, of every
+// instance with Redis.
+func (h *Hub) SendTo(ctx context.Context, key string, data []byte) error {
+	return h.dispatch(ctx, message{Key: key, Data: data})
+}
+
+func (h *Hub) dispatch(ctx context.Context, msg message) error {
+	if h.publisher != nil {
+		return h.publisher.Publish(ctx, h.config.Channel, msg)
+	}
+	h.deliver(msg)
+	return nil
+}
+
+// deliver queues msg on the local connections it is meant for.
+func (h *Hub) deliver(msg message) {
+	h.mu.RLock()
+	targets := make([]*Conn, 0, len(h.conns))
+	for c := range h.conns {
+		if msg.Key == "" || c.Key == msg.Key {
+			targets = append(targets, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range targets {
+		_ = c.Send(msg.Data)
+	}
+}
+
+// Run delivers the messages fanned out through Redis to the local
+// connections until ctx is done.
+func (h *Hub) Run(ctx context.Context) error {
+	if h.config.Redis == nil {
+		return errNoRedis
+	}
+	subscriber := pubsub.NewSubscriber(h.config.Redis, pubsub.WithLogger(h.log))
+	pubsub.Handle(subscriber, h.config.Channel, func(_ context.Context, _ string, msg message) error {
+		h.deliver(msg)
+		return nil
+	})
+	return subscriber.Run(ctx)
+}
+
+// Shutdown closes every connection with a going away close frame, so that
+// the clients reconnect to another instance, and waits for them to be
+// closed until ctx is done. The hub accepts no connection afterwards.
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	conns := make([]*Conn, 0, len(h.conns))
+	for c := range h.conns {
+		conns = append(conns, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range conns {
+		c.close(ws.CloseGoingAway)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Hub) unregister(c *Conn) {
+	h.mu.Lock()
+	_, ok := h.conns[c]
+	delete(h.conns, c)
+	h.mu.Unlock()
+	if ok {
+		h.log.With(logger.Fields{"connID": c.ID, "key": c.Key}).Debug("WebSocket connection closed")
+	}
+}
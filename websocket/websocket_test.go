@@ -0,0 +1,177 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	ws "github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer serves hub, registering the connections under the key
+// query parameter.
+func newTestServer(t *testing.T, hub *Hub) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(hub.Handler(func(r *http.Request) string {
+		return r.URL.Query().Get("key")
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, key string) *ws.Conn {
+	t.Helper()
+	conn, _, err := ws.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"?key="+key, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func read(t *testing.T, conn *ws.Conn) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	return string(data)
+}
+
+func waitForConns(t *testing.T, hub *Hub, n int) {
+	t.Helper()
+	assert.Eventually(t, func() bool { return hub.Len() == n }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestHubBroadcastAndSendTo(t *testing.T) {
+	hub := NewHub(Config{})
+	srv := newTestServer(t, hub)
+	alice := dial(t, srv, "alice")
+	bob := dial(t, srv, "bob")
+	waitForConns(t, hub, 2)
+
+	require.NoError(t, hub.Broadcast(context.Background(), []byte("hello")))
+	assert.Equal(t, "hello", read(t, alice))
+	assert.Equal(t, "hello", read(t, bob))
+
+	require.NoError(t, hub.SendTo(context.Background(), "bob", []byte("hi bob")))
+	require.NoError(t, hub.Broadcast(context.Background(), []byte("bye")))
+	assert.Equal(t, "bye", read(t, alice))
+	assert.Equal(t, "hi bob", read(t, bob))
+	assert.Equal(t, "bye", read(t, bob))
+
+	require.NoError(t, alice.Close())
+	waitForConns(t, hub, 1)
+}
+
+func TestHubOnMessage(t *testing.T) {
+	hub := NewHub(Config{OnMessage: func(conn *Conn, data []byte) {
+		_ = conn.Send(append([]byte("echo: "), data...))
+	}})
+	conn := dial(t, newTestServer(t, hub), "alice")
+
+	require.NoError(t, conn.WriteMessage(ws.TextMessage, []byte("ping")))
+	assert.Equal(t, "echo: ping", read(t, conn))
+}
+
+func TestHubKeepalive(t *testing.T) {
+	hub := NewHub(Config{PingInterval: 20 * time.Millisecond})
+	srv := newTestServer(t, hub)
+
+	// The client answers the pings while it reads.
+	alive := dial(t, srv, "alive")
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// The client never reads, so it never answers the pings.
+	dial(t, srv, "dead")
+	waitForConns(t, hub, 2)
+
+	waitForConns(t, hub, 1)
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	for c := range hub.conns {
+		assert.Equal(t, "alive", c.Key)
+	}
+}
+
+func TestHubSlowConnection(t *testing.T) {
+	hub := NewHub(Config{SendQueueSize: 1})
+	hub.mu.Lock()
+	c := &Conn{hub: hub, send: make(chan []byte, 1), done: make(chan struct{})}
+	hub.mu.Unlock()
+
+	require.NoError(t, c.Send([]byte("1")))
+	assert.ErrorIs(t, c.Send([]byte("2")), errSendQueueFull)
+	assert.ErrorIs(t, c.Send([]byte("3")), ErrClosed)
+	assert.Equal(t, ws.ClosePolicyViolation, c.closeCode)
+}
+
+func TestConnSendAfterClientDisconnect(t *testing.T) {
+	conns := make(chan *Conn, 1)
+	hub := NewHub(Config{OnMessage: func(conn *Conn, _ []byte) { conns <- conn }})
+	conn := dial(t, newTestServer(t, hub), "alice")
+	require.NoError(t, conn.WriteMessage(ws.TextMessage, []byte("hello")))
+	c := <-conns
+
+	require.NoError(t, conn.Close())
+	waitForConns(t, hub, 0)
+
+	assert.ErrorIs(t, c.Send([]byte("lost")), ErrClosed)
+}
+
+func TestHubShutdown(t *testing.T) {
+	hub := NewHub(Config{})
+	srv := newTestServer(t, hub)
+	conn := dial(t, srv, "alice")
+	waitForConns(t, hub, 1)
+
+	require.NoError(t, hub.Broadcast(context.Background(), []byte("queued")))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, hub.Shutdown(ctx))
+	assert.Equal(t, 0, hub.Len())
+
+	assert.Equal(t, "queued", read(t, conn))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, ws.IsCloseError(err, ws.CloseGoingAway), err)
+
+	_, _, err = ws.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err == nil {
+		t.Fatal("connection accepted after shutdown")
+	}
+}
+
+func TestHubRedisFanOut(t *testing.T) {
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hubs := []*Hub{NewHub(Config{Redis: client}), NewHub(Config{Redis: client})}
+	conns := make([]*ws.Conn, len(hubs))
+	for i, hub := range hubs {
+		go func() { _ = hub.Run(ctx) }()
+		conns[i] = dial(t, newTestServer(t, hub), "alice")
+		waitForConns(t, hub, 1)
+	}
+	assert.Eventually(t, func() bool {
+		return len(srv.PubSubChannels("")) == 1 && srv.PubSubNumSub(defaultChannel)[defaultChannel] == 2
+	}, 2*time.Second, 5*time.Millisecond)
+
+	require.NoError(t, hubs[0].SendTo(ctx, "alice", []byte("everywhere")))
+	for _, conn := range conns {
+		assert.Equal(t, "everywhere", read(t, conn))
+	}
+
+	assert.ErrorIs(t, NewHub(Config{}).Run(ctx), errNoRedis)
+}